@@ -20,6 +20,13 @@ type K8znerClusterSpec struct {
 	// Workers defines the worker node configuration
 	Workers WorkerSpec `json:"workers"`
 
+	// WorkerPools defines named worker pools that are scaled and healed independently.
+	// When set, it replaces Workers.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	WorkerPools []WorkerPoolSpec `json:"workerPools,omitempty"`
+
 	// Backup configures automated etcd backups
 	// +optional
 	Backup *BackupSpec `json:"backup,omitempty"`
@@ -36,6 +43,15 @@ type K8znerClusterSpec struct {
 	// +optional
 	Paused bool `json:"paused,omitempty"`
 
+	// DeletionPolicy controls what happens to the cluster's cloud resources when
+	// this object is deleted. Retain leaves them in place; Delete tears the cluster
+	// down (servers, load balancers, network, firewall, DNS records and backup
+	// buckets) before the object is released.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default="Retain"
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
 	// Network configures the cluster networking
 	// +optional
 	Network NetworkSpec `json:"network,omitempty"`
@@ -48,6 +64,14 @@ type K8znerClusterSpec struct {
 	// +optional
 	PlacementGroup *PlacementGroupSpec `json:"placementGroup,omitempty"`
 
+	// RollingUpdate controls how nodes are replaced when their server size changes
+	// +optional
+	RollingUpdate *RollingUpdateSpec `json:"rollingUpdate,omitempty"`
+
+	// Maintenance restricts disruptive actions to maintenance windows
+	// +optional
+	Maintenance *MaintenanceSpec `json:"maintenance,omitempty"`
+
 	// Kubernetes specifies the Kubernetes version
 	Kubernetes KubernetesSpec `json:"kubernetes"`
 
@@ -81,6 +105,57 @@ type PlacementGroupSpec struct {
 	Type string `json:"type,omitempty"`
 }
 
+// RollingUpdateSpec controls surge-style node replacement.
+// Control planes are always replaced one at a time with a surge of one, so etcd
+// never loses a member before its replacement has joined; these limits apply to workers.
+type RollingUpdateSpec struct {
+	// MaxSurge is the number of workers that may be created above the desired count
+	// while outdated workers are replaced
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	// +optional
+	MaxSurge *int `json:"maxSurge,omitempty"`
+
+	// MaxUnavailable is the number of outdated workers that may be removed before
+	// their replacements are ready
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=0
+	// +optional
+	MaxUnavailable int `json:"maxUnavailable,omitempty"`
+}
+
+// MaintenanceSpec restricts disruptive operator actions to recurring maintenance
+// windows. Node replacement, scale-down, server type rollouts, upgrades and machine
+// config changes are queued in status until a window opens; scale-up always runs.
+type MaintenanceSpec struct {
+	// Windows are the recurring maintenance windows. Without windows, disruptive
+	// actions run as soon as they are needed.
+	// +optional
+	Windows []MaintenanceWindow `json:"windows,omitempty"`
+
+	// Timezone is the IANA time zone the window schedules are evaluated in
+	// +kubebuilder:default="UTC"
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
+	// AllowEmergencyHealing lets the operator replace unhealthy nodes outside
+	// a maintenance window
+	// +optional
+	AllowEmergencyHealing bool `json:"allowEmergencyHealing,omitempty"`
+}
+
+// MaintenanceWindow is a recurring period in which disruptive actions may run.
+type MaintenanceWindow struct {
+	// Schedule is a cron expression (minute hour day-of-month month day-of-week)
+	// for the start of the window, e.g. "0 2 * * sat" for Saturdays at 02:00
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open, e.g. "4h"
+	// +kubebuilder:validation:MinLength=1
+	Duration string `json:"duration"`
+}
+
 // NetworkSpec configures the cluster networking.
 type NetworkSpec struct {
 	// IPv4CIDR is the network CIDR for the Hetzner private network
@@ -90,17 +165,19 @@ type NetworkSpec struct {
 
 	// NodeIPv4CIDR is the CIDR range for node IPs within the private network.
 	// This is used to calculate subnets for control planes, load balancers, and workers.
-	// If not set, defaults to "10.0.0.0/17" (lower half of the network CIDR).
+	// Defaults to the lower half of the default network CIDR.
+	// +kubebuilder:default="10.0.0.0/17"
 	// +optional
 	NodeIPv4CIDR string `json:"nodeIPv4CIDR,omitempty"`
 
-	// PodCIDR is the CIDR range for pod IPs
-	// +kubebuilder:default="10.244.0.0/16"
+	// PodCIDR is the CIDR range for pod IPs. It should lie within IPv4CIDR, since
+	// Cilium routes pod traffic natively through the private network.
+	// +kubebuilder:default="10.0.128.0/17"
 	// +optional
 	PodCIDR string `json:"podCIDR,omitempty"`
 
 	// ServiceCIDR is the CIDR range for service IPs
-	// +kubebuilder:default="10.96.0.0/16"
+	// +kubebuilder:default="10.96.0.0/12"
 	// +optional
 	ServiceCIDR string `json:"serviceCIDR,omitempty"`
 }
@@ -133,6 +210,23 @@ type TalosSpec struct {
 	// Extensions is a list of Talos system extensions to include
 	// +optional
 	Extensions []string `json:"extensions,omitempty"`
+
+	// UpgradeBatchSize is the number of workers upgraded in parallel when
+	// the Talos or Kubernetes version changes. Control planes are always
+	// upgraded one at a time.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	UpgradeBatchSize int `json:"upgradeBatchSize,omitempty"`
+
+	// ConfigApplyMode controls how machine config changes are applied to existing
+	// nodes: auto lets Talos reboot only when a change requires it, no_reboot
+	// rejects changes that need a reboot, reboot always reboots and staged applies
+	// the config on the next reboot.
+	// +kubebuilder:validation:Enum=auto;no_reboot;reboot;staged
+	// +kubebuilder:default=auto
+	// +optional
+	ConfigApplyMode string `json:"configApplyMode,omitempty"`
 }
 
 // BootstrapState contains the state from CLI bootstrap.
@@ -183,6 +277,74 @@ type WorkerSpec struct {
 	Size string `json:"size"`
 }
 
+// DefaultWorkerPoolName is the pool built from spec.workers when no
+// workerPools are set. Workers created before worker pools existed carry this
+// name in their k8zner.io/pool server label.
+const DefaultWorkerPoolName = "workers"
+
+// WorkerPoolSpec defines a named group of identical worker nodes.
+type WorkerPoolSpec struct {
+	// Name identifies the pool. Nodes carry it in the k8zner.io/pool label.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=32
+	Name string `json:"name"`
+
+	// Count is the desired number of nodes in this pool.
+	// With Autoscaling set, Count is only the initial size.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Count int `json:"count"`
+
+	// Size is the Hetzner server type (e.g., cx23, cx33, cpx22, cpx32)
+	// +kubebuilder:default="cx23"
+	Size string `json:"size"`
+
+	// Autoscaling lets the operator size the pool between MinCount and MaxCount
+	// based on unschedulable pods and node utilization
+	// +optional
+	Autoscaling *WorkerPoolAutoscalingSpec `json:"autoscaling,omitempty"`
+
+	// Location is the Hetzner location for this pool's servers (defaults to spec.region)
+	// +kubebuilder:validation:Enum=fsn1;nbg1;hel1
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// Labels are Kubernetes labels applied to every node in the pool
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Taints are applied to every node in the pool
+	// +optional
+	Taints []corev1.Taint `json:"taints,omitempty"`
+}
+
+// WorkerPoolAutoscalingSpec configures the built-in autoscaler for a worker pool.
+type WorkerPoolAutoscalingSpec struct {
+	// MinCount is the smallest number of nodes the pool is scaled down to
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinCount int `json:"minCount"`
+
+	// MaxCount is the largest number of nodes the pool is scaled up to
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	MaxCount int `json:"maxCount"`
+
+	// ScaleDownUtilizationThreshold is the percentage of a node's allocatable CPU
+	// and memory requested by its pods below which the node is underutilized
+	// +kubebuilder:default=50
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ScaleDownUtilizationThreshold int `json:"scaleDownUtilizationThreshold,omitempty"`
+
+	// ScaleDownDelay is how long a node must stay underutilized before it is removed.
+	// No node is removed within this window after the pool last scaled up.
+	// +kubebuilder:default="10m"
+	// +optional
+	ScaleDownDelay string `json:"scaleDownDelay,omitempty"`
+}
+
 // BackupSpec configures automated etcd backups.
 type BackupSpec struct {
 	// Enabled turns on automated backups
@@ -198,11 +360,39 @@ type BackupSpec struct {
 	// +optional
 	Retention string `json:"retention,omitempty"`
 
+	// KeepLast is the number of most recent snapshots that are never pruned,
+	// even when older than Retention (default: 3)
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	KeepLast int `json:"keepLast,omitempty"`
+
 	// S3SecretRef references a Secret containing S3 credentials for backup storage.
 	// The Secret must contain keys: access-key, secret-key, endpoint, bucket, region
 	// If not specified, backup will be skipped.
 	// +optional
 	S3SecretRef *SecretReference `json:"s3SecretRef,omitempty"`
+
+	// Verification periodically checks in a throwaway pod that the latest
+	// snapshot can be downloaded, decrypted and read
+	// +optional
+	Verification *BackupVerificationSpec `json:"verification,omitempty"`
+}
+
+// BackupVerificationSpec configures automated backup verification.
+type BackupVerificationSpec struct {
+	// Enabled turns on periodic verification
+	Enabled bool `json:"enabled"`
+
+	// Schedule is the cron schedule for verification runs (default: daily at 04:00 UTC)
+	// +kubebuilder:default="0 4 * * *"
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// IdentitySecretRef references a Secret whose "identity" key holds the age
+	// identity that decrypts the snapshots. Required unless backup encryption is disabled.
+	// +optional
+	IdentitySecretRef *SecretReference `json:"identitySecretRef,omitempty"`
 }
 
 // SecretReference references a Secret in the same namespace as the K8znerCluster.
@@ -267,15 +457,20 @@ type AddonSpec struct {
 // K8znerClusterStatus defines the observed state of K8znerCluster.
 type K8znerClusterStatus struct {
 	// Phase is the overall cluster phase
-	// +kubebuilder:validation:Enum=Provisioning;Running;Degraded;Healing;Failed
+	// +kubebuilder:validation:Enum=Provisioning;Running;Degraded;Healing;Failed;Deleting
 	Phase ClusterPhase `json:"phase,omitempty"`
 
 	// ControlPlanes shows the status of control plane nodes
 	ControlPlanes NodeGroupStatus `json:"controlPlanes,omitempty"`
 
-	// Workers shows the status of worker nodes
+	// Workers shows the status of worker nodes across all pools
 	Workers NodeGroupStatus `json:"workers,omitempty"`
 
+	// WorkerPools shows per-pool node counts. Individual nodes are listed in
+	// Workers.Nodes with their pool set.
+	// +optional
+	WorkerPools []WorkerPoolStatus `json:"workerPools,omitempty"`
+
 	// Addons shows the status of installed addons
 	// +optional
 	Addons map[string]AddonStatus `json:"addons,omitempty"`
@@ -324,6 +519,111 @@ type K8znerClusterStatus struct {
 	// LastErrors is a ring buffer of recent errors (max 10).
 	// +optional
 	LastErrors []ErrorRecord `json:"lastErrors,omitempty"`
+
+	// Maintenance reports the maintenance window state and the disruptive
+	// actions waiting for the next window
+	// +optional
+	Maintenance *MaintenanceStatus `json:"maintenance,omitempty"`
+
+	// Backup reports the snapshots in the backup bucket
+	// +optional
+	Backup *BackupStatus `json:"backup,omitempty"`
+
+	// CARotation reports the progress of the last Talos and Kubernetes CA rotation
+	// +optional
+	CARotation *CARotationStatus `json:"caRotation,omitempty"`
+}
+
+// CARotationPhase is a step of a CA rotation.
+type CARotationPhase string
+
+const (
+	// CARotationAcceptNewCA means nodes are taught to accept the new CAs next to the current ones
+	CARotationAcceptNewCA CARotationPhase = "AcceptNewCA"
+	// CARotationSwitchCA means nodes switch to the new CAs and still accept the old ones
+	CARotationSwitchCA CARotationPhase = "SwitchCA"
+	// CARotationDropOldCA means the old CAs are removed from the nodes
+	CARotationDropOldCA CARotationPhase = "DropOldCA"
+	// CARotationComplete means the rotation finished
+	CARotationComplete CARotationPhase = "Complete"
+)
+
+// CARotationStatus reports a CA rotation requested through the k8zner.io/rotate-ca annotation.
+type CARotationStatus struct {
+	// Phase is the current step of the rotation
+	// +kubebuilder:validation:Enum=AcceptNewCA;SwitchCA;DropOldCA;Complete
+	Phase CARotationPhase `json:"phase"`
+
+	// Request is the annotation value the rotation was started for
+	Request string `json:"request"`
+
+	// StartedAt is when the rotation started
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// CompletedAt is when the old CAs were dropped from the last node
+	// +optional
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+// BackupStatus reports the etcd snapshots kept in the backup bucket.
+type BackupStatus struct {
+	// LastBackupTime is when the newest snapshot was taken
+	// +optional
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// OldestBackupTime is when the oldest retained snapshot was taken
+	// +optional
+	OldestBackupTime *metav1.Time `json:"oldestBackupTime,omitempty"`
+
+	// BackupCount is the number of snapshots in the bucket after pruning
+	BackupCount int `json:"backupCount"`
+
+	// LastPruneTime is when the operator last applied the retention policy
+	// +optional
+	LastPruneTime *metav1.Time `json:"lastPruneTime,omitempty"`
+
+	// LastVerificationTime is when the last verification run finished
+	// +optional
+	LastVerificationTime *metav1.Time `json:"lastVerificationTime,omitempty"`
+
+	// LastVerifiedSnapshot is the snapshot the last successful verification read
+	// +optional
+	LastVerifiedSnapshot string `json:"lastVerifiedSnapshot,omitempty"`
+
+	// VerificationJob is the name of the verification Job in progress
+	// +optional
+	VerificationJob string `json:"verificationJob,omitempty"`
+}
+
+// MaintenanceStatus reports maintenance window state.
+type MaintenanceStatus struct {
+	// InWindow is true while a maintenance window is open
+	InWindow bool `json:"inWindow"`
+
+	// NextWindow is when the next maintenance window opens
+	// +optional
+	NextWindow *metav1.Time `json:"nextWindow,omitempty"`
+
+	// Pending lists the disruptive actions waiting for a maintenance window
+	// +optional
+	Pending []PendingAction `json:"pending,omitempty"`
+}
+
+// PendingAction is a disruptive action deferred until the next maintenance window.
+type PendingAction struct {
+	// Action is the kind of action, e.g. ScaleDown or TalosUpgrade
+	Action string `json:"action"`
+
+	// Target is the node or pool the action applies to, empty for cluster-wide actions
+	// +optional
+	Target string `json:"target,omitempty"`
+
+	// Reason explains why the action is needed
+	Reason string `json:"reason"`
+
+	// QueuedAt is when the action was first deferred
+	QueuedAt metav1.Time `json:"queuedAt"`
 }
 
 // PhaseRecord records timing information for a provisioning phase.
@@ -502,6 +802,8 @@ const (
 	ClusterPhaseHealing ClusterPhase = "Healing"
 	// ClusterPhaseFailed means the cluster cannot self-heal
 	ClusterPhaseFailed ClusterPhase = "Failed"
+	// ClusterPhaseDeleting means the operator is tearing the cluster down
+	ClusterPhaseDeleting ClusterPhase = "Deleting"
 )
 
 // NodeGroupStatus represents the status of a group of nodes.
@@ -521,6 +823,30 @@ type NodeGroupStatus struct {
 	Nodes []NodeStatus `json:"nodes,omitempty"`
 }
 
+// WorkerPoolStatus shows the node counts of a single worker pool.
+type WorkerPoolStatus struct {
+	// Name is the pool name
+	Name string `json:"name"`
+
+	// Desired is the desired number of nodes
+	Desired int `json:"desired"`
+
+	// Ready is the number of healthy nodes
+	Ready int `json:"ready"`
+
+	// Unhealthy is the number of unhealthy nodes
+	// +optional
+	Unhealthy int `json:"unhealthy,omitempty"`
+
+	// LastScaleUpTime is when the autoscaler last raised Desired
+	// +optional
+	LastScaleUpTime *metav1.Time `json:"lastScaleUpTime,omitempty"`
+
+	// LastScaleDownTime is when the autoscaler last lowered Desired
+	// +optional
+	LastScaleDownTime *metav1.Time `json:"lastScaleDownTime,omitempty"`
+}
+
 // NodePhase represents the lifecycle phase of a node.
 type NodePhase string
 
@@ -553,6 +879,10 @@ const (
 	// NodePhaseDeletingServer means the HCloud server is being deleted
 	NodePhaseDeletingServer NodePhase = "DeletingServer"
 
+	// Maintenance phases
+	// NodePhaseUpgrading means the node is being upgraded to a new Talos version
+	NodePhaseUpgrading NodePhase = "Upgrading"
+
 	// Error phase
 	// NodePhaseFailed means provisioning failed and the node cannot be recovered automatically
 	NodePhaseFailed NodePhase = "Failed"
@@ -574,7 +904,7 @@ type NodeStatus struct {
 	PublicIP string `json:"publicIP,omitempty"`
 
 	// Phase is the lifecycle phase of this node
-	// +kubebuilder:validation:Enum=CreatingServer;WaitingForIP;WaitingForTalosAPI;ApplyingTalosConfig;RebootingWithConfig;WaitingForK8s;NodeInitializing;Ready;Unhealthy;Draining;RemovingFromEtcd;DeletingServer;Failed
+	// +kubebuilder:validation:Enum=CreatingServer;WaitingForIP;WaitingForTalosAPI;ApplyingTalosConfig;RebootingWithConfig;WaitingForK8s;NodeInitializing;Ready;Unhealthy;Draining;RemovingFromEtcd;DeletingServer;Upgrading;Failed
 	// +optional
 	Phase NodePhase `json:"phase,omitempty"`
 
@@ -597,9 +927,39 @@ type NodeStatus struct {
 	// +optional
 	UnhealthySince *metav1.Time `json:"unhealthySince,omitempty"`
 
+	// UnderutilizedSince is when the autoscaler first saw the node below its
+	// pool's scale-down utilization threshold
+	// +optional
+	UnderutilizedSince *metav1.Time `json:"underutilizedSince,omitempty"`
+
 	// LastHealthCheck is when health was last checked
 	// +optional
 	LastHealthCheck *metav1.Time `json:"lastHealthCheck,omitempty"`
+
+	// TalosVersion is the Talos version last observed on this node
+	// +optional
+	TalosVersion string `json:"talosVersion,omitempty"`
+
+	// KubernetesVersion is the kubelet version reported by this node
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// ConfigHash is the hash of the machine config the node reports it runs
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// StagedConfigHash is the hash of a machine config staged to take effect on
+	// the node's next reboot
+	// +optional
+	StagedConfigHash string `json:"stagedConfigHash,omitempty"`
+
+	// ServerType is the Hetzner server type last observed for this node
+	// +optional
+	ServerType string `json:"serverType,omitempty"`
+
+	// Pool is the worker pool this node belongs to (empty for control planes)
+	// +optional
+	Pool string `json:"pool,omitempty"`
 }
 
 // AddonPhase represents the installation phase of an addon.
@@ -706,8 +1066,46 @@ const (
 	ConditionImageReady = "ImageReady"
 	// ConditionBootstrapped indicates the cluster has been bootstrapped
 	ConditionBootstrapped = "Bootstrapped"
+	// ConditionTalosUpgrade reports progress of a rolling Talos OS upgrade
+	ConditionTalosUpgrade = "TalosUpgrade"
+	// ConditionKubernetesUpgrade reports progress of a rolling Kubernetes version upgrade
+	ConditionKubernetesUpgrade = "KubernetesUpgrade"
+	// ConditionMachineConfigSynced indicates every node runs the desired machine config
+	ConditionMachineConfigSynced = "MachineConfigSynced"
+	// ConditionBackupVerified indicates the latest verified snapshot could be decrypted and read
+	ConditionBackupVerified = "BackupVerified"
+	// ConditionCARotation reports progress of a Talos and Kubernetes CA rotation
+	ConditionCARotation = "CARotation"
+)
+
+// Deletion policies for spec.deletionPolicy
+const (
+	// DeletionPolicyRetain leaves the cloud resources in place when the object is deleted
+	DeletionPolicyRetain = "Retain"
+	// DeletionPolicyDelete tears the cluster down before the object is released
+	DeletionPolicyDelete = "Delete"
 )
 
+// ClusterFinalizer holds a K8znerCluster with deletionPolicy Delete until its
+// cloud resources have been torn down.
+const ClusterFinalizer = "k8zner.io/cluster-teardown"
+
+// ForceWithoutSnapshotAnnotation, set to "true" on a K8znerCluster, lets
+// control plane replacement, scaling and upgrades proceed when the etcd
+// snapshot taken before them fails.
+const ForceWithoutSnapshotAnnotation = "k8zner.io/force-without-snapshot"
+
+// RotateCAAnnotation, set on a K8znerCluster to a value it has not had
+// before (k8zner rotate ca uses a timestamp), makes the operator rotate the
+// Talos and Kubernetes CAs once.
+const RotateCAAnnotation = "k8zner.io/rotate-ca"
+
+// PauseMachineConfigSyncAnnotation, set to "true" on a K8znerCluster, stops
+// the operator from re-applying machine configs that drifted from the ones
+// it generates. k8zner import sets it, so an adopted cluster keeps running
+// its own configs until the annotation is removed.
+const PauseMachineConfigSyncAnnotation = "k8zner.io/pause-machine-config-sync"
+
 // Credentials Secret keys
 const (
 	// CredentialsKeyHCloudToken is the key for the HCloud API token in the credentials Secret
@@ -716,8 +1114,15 @@ const (
 	CredentialsKeyTalosSecrets = "talos-secrets"
 	// CredentialsKeyTalosConfig is the key for the talosconfig in the credentials Secret
 	CredentialsKeyTalosConfig = "talosconfig"
+	// CredentialsKeyTalosAcceptedSecrets is the key for the secrets bundle whose CAs nodes
+	// accept next to their own while a CA rotation is in progress
+	CredentialsKeyTalosAcceptedSecrets = "talos-secrets-accepted"
+	// CredentialsKeyKubeconfig is the key for the admin kubeconfig re-issued by a CA rotation
+	CredentialsKeyKubeconfig = "kubeconfig"
 	// CredentialsKeyCloudflareAPIToken is the key for the Cloudflare API token in the credentials Secret
 	CredentialsKeyCloudflareAPIToken = "cf-api-token" //nolint:gosec // This is a secret key name, not a credential value
+	// BackupIdentityKey is the key for the age identity in the backup verification Secret
+	BackupIdentityKey = "identity"
 )
 
 // Addon names used for status tracking
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"errors"
+
 	"github.com/spf13/cobra"
 
 	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
@@ -16,6 +18,8 @@ import (
 //
 //	--config, -c: Path to cluster configuration YAML file (default: auto-detect k8zner.yaml)
 //	--wait: Wait for operator to complete provisioning
+//	--plan: Show what would change instead of applying (same as 'k8zner plan')
+//	--json: Output the plan in JSON format (with --plan)
 //
 // Environment variables:
 //
@@ -24,6 +28,8 @@ func Apply() *cobra.Command {
 	var configPath string
 	var wait bool
 	var ci bool
+	var plan bool
+	var jsonOutput bool
 
 	cmd := &cobra.Command{
 		Use:   "apply",
@@ -50,8 +56,23 @@ Examples:
   k8zner apply --wait
 
   # Update cluster using specific config file
-  k8zner apply -c production.yaml`,
+  k8zner apply -c production.yaml
+
+  # Preview the changes without applying them
+  k8zner apply --plan
+
+  # Preview the changes as JSON for a CI approval gate
+  k8zner apply --plan --json > plan.json`,
+		Args: func(_ *cobra.Command, _ []string) error {
+			if jsonOutput && !plan {
+				return errors.New("--json requires --plan")
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if plan {
+				return handlers.Plan(cmd.Context(), configPath, jsonOutput)
+			}
 			return handlers.Apply(cmd.Context(), configPath, wait, ci)
 		},
 	}
@@ -59,6 +80,8 @@ Examples:
 	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to configuration file (default: k8zner.yaml)")
 	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for operator to complete provisioning")
 	cmd.Flags().BoolVar(&ci, "ci", false, "Disable TUI, use plain log output")
+	cmd.Flags().BoolVar(&plan, "plan", false, "Show what would change without applying it")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the plan in JSON format (with --plan)")
 
 	return cmd
 }
@@ -48,3 +48,41 @@ func TestApply_RunE(t *testing.T) {
 	cmd := Apply()
 	assert.NotNil(t, cmd.RunE, "Apply command should have RunE function")
 }
+
+func TestApply_PlanFlag(t *testing.T) {
+	t.Parallel()
+	cmd := Apply()
+
+	flag := cmd.Flags().Lookup("plan")
+	require.NotNil(t, flag, "plan flag should exist")
+	assert.Equal(t, "false", flag.DefValue)
+}
+
+func TestApply_JSONRequiresPlan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		flags   []string
+		wantErr bool
+	}{
+		{name: "plan", flags: []string{"plan"}},
+		{name: "plan as json", flags: []string{"plan", "json"}},
+		{name: "json without plan", flags: []string{"json"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := Apply()
+			for _, flag := range tt.flags {
+				require.NoError(t, cmd.Flags().Set(flag, "true"))
+			}
+			err := cmd.Args(cmd, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// Backup returns the command group for working with etcd backups.
+//
+// Subcommands:
+//
+//	list:    List snapshots in the backup bucket
+//	now:     Take a snapshot immediately
+//	inspect: Show revision and key count of a snapshot
+//
+// Persistent flags:
+//
+//	--config, -c: Path to cluster configuration YAML file (default: auto-detect k8zner.yaml)
+//
+// Environment variables:
+//
+//	HETZNER_S3_ACCESS_KEY, HETZNER_S3_SECRET_KEY: Object Storage credentials (list, inspect)
+func Backup() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "List, take and inspect etcd backups",
+		Long: `Work with the etcd snapshots the backup CronJob stores in Object Storage.
+
+Examples:
+  # List snapshots, newest first
+  k8zner backup list
+
+  # Take a snapshot before a risky change
+  k8zner backup now
+
+  # Check that the latest snapshot is readable
+  k8zner backup inspect latest --identity ~/.config/k8zner/backup-key.txt`,
+	}
+
+	cmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Path to configuration file (default: k8zner.yaml)")
+
+	cmd.AddCommand(backupList(&configPath))
+	cmd.AddCommand(backupNow(&configPath))
+	cmd.AddCommand(backupInspect(&configPath))
+
+	return cmd
+}
+
+func backupList(configPath *string) *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List etcd snapshots in the backup bucket",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return handlers.BackupList(cmd.Context(), *configPath, jsonOutput)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func backupNow(configPath *string) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "now",
+		Short: "Take an etcd snapshot immediately",
+		Long: `Run a one-off Job from the talos-backup CronJob and wait for it to
+upload the snapshot. Requires the kubeconfig written by 'k8zner apply'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return handlers.BackupNow(cmd.Context(), *configPath, timeout)
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "How long to wait for the backup to finish")
+
+	return cmd
+}
+
+func backupInspect(configPath *string) *cobra.Command {
+	var identityPath string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "inspect <snapshot-key|latest>",
+		Short: "Show the etcd revision and key count of a snapshot",
+		Long: `Download and decode a snapshot and read it like "etcdutl snapshot status",
+verifying its integrity hash when present.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return handlers.BackupInspect(cmd.Context(), *configPath, args[0], identityPath, jsonOutput)
+		},
+	}
+
+	cmd.Flags().StringVarP(&identityPath, "identity", "i", "", "age identity file for encrypted snapshots")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	return cmd
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackup(t *testing.T) {
+	t.Parallel()
+	cmd := Backup()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "backup", cmd.Use)
+	assert.Equal(t, "List, take and inspect etcd backups", cmd.Short)
+
+	names := make([]string, 0, len(cmd.Commands()))
+	for _, sub := range cmd.Commands() {
+		names = append(names, sub.Name())
+	}
+	assert.ElementsMatch(t, []string{"list", "now", "inspect"}, names)
+
+	config := cmd.PersistentFlags().Lookup("config")
+	require.NotNil(t, config)
+	assert.Equal(t, "c", config.Shorthand)
+}
+
+func TestBackup_SubcommandFlags(t *testing.T) {
+	t.Parallel()
+	cmd := Backup()
+
+	list, _, err := cmd.Find([]string{"list"})
+	require.NoError(t, err)
+	require.NotNil(t, list.Flags().Lookup("json"))
+
+	now, _, err := cmd.Find([]string{"now"})
+	require.NoError(t, err)
+	timeout := now.Flags().Lookup("timeout")
+	require.NotNil(t, timeout)
+	assert.Equal(t, (10 * time.Minute).String(), timeout.DefValue)
+
+	inspect, _, err := cmd.Find([]string{"inspect"})
+	require.NoError(t, err)
+	assert.Error(t, inspect.Args(inspect, nil), "inspect requires a snapshot reference")
+	assert.NoError(t, inspect.Args(inspect, []string{"latest"}))
+	identity := inspect.Flags().Lookup("identity")
+	require.NotNil(t, identity)
+	assert.Equal(t, "i", identity.Shorthand)
+	require.NotNil(t, inspect.Flags().Lookup("json"))
+}
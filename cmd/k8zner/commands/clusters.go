@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// Clusters returns the command for managing cluster workspaces.
+//
+// Subcommands:
+//
+//	list: List the clusters that have a workspace
+//
+// Environment variables:
+//
+//	K8ZNER_HOME: Directory holding the workspaces (default: ~/.k8zner)
+func Clusters() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clusters",
+		Short: "Manage cluster workspaces",
+		Long: `Each cluster can keep k8zner.yaml and its credential files in a workspace of
+its own under $K8ZNER_HOME/clusters/<cluster> (default: ~/.k8zner), so
+several clusters can be managed without changing directory.
+
+Select a workspace for a single command with --workspace, or for all
+following commands with 'k8zner use'. Without either, k8zner uses the
+working directory.`,
+	}
+
+	cmd.AddCommand(clustersList())
+
+	return cmd
+}
+
+func clustersList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the clusters that have a workspace",
+		Long: `List the cluster workspaces with the region and mode of their k8zner.yaml and
+whether a kubeconfig is present. The cluster selected with 'k8zner use' is
+marked with *.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return handlers.ClustersList()
+		},
+	}
+}
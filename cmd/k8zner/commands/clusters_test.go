@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusters(t *testing.T) {
+	t.Parallel()
+	cmd := Clusters()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "clusters", cmd.Use)
+	assert.Equal(t, "Manage cluster workspaces", cmd.Short)
+
+	list, _, err := cmd.Find([]string{"list"})
+	require.NoError(t, err)
+	assert.Equal(t, "list", list.Name())
+	assert.NotNil(t, list.RunE)
+	assert.Error(t, list.Args(list, []string{"extra"}))
+}
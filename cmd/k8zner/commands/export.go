@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// Export returns the command for writing the live cluster spec as k8zner.yaml.
+//
+// Optional flags:
+//
+//	--config, -c: Path to cluster configuration YAML file (default: auto-detect k8zner.yaml)
+//	--name: Cluster to export (default: the name in the config file)
+//	--output, -o: File to write (default: print to stdout)
+func Export() *cobra.Command {
+	var configPath string
+	var name string
+	var outputPath string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Render the running cluster as k8zner.yaml",
+		Long: `Read the K8znerCluster of a running cluster and render its spec as k8zner.yaml.
+
+The operator works from the K8znerCluster, so changes made to it with kubectl
+take effect, but k8zner.yaml does not know about them and the next apply
+would revert them. Export brings k8zner.yaml back in line with the cluster.
+
+The state backend, secrets encryption and certificate email are kept from
+the config file, since they are not part of the K8znerCluster. Live values
+k8zner.yaml cannot express, such as Talos and Kubernetes versions it does
+not pin, are listed after the export.
+
+Examples:
+  # Print the live spec of the cluster in k8zner.yaml
+  k8zner export
+
+  # Update k8zner.yaml with the live spec
+  k8zner export -o k8zner.yaml
+
+  # Export a cluster without a config file
+  k8zner export --name prod -o k8zner.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return handlers.Export(cmd.Context(), configPath, name, outputPath)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to configuration file (default: k8zner.yaml)")
+	cmd.Flags().StringVar(&name, "name", "", "Cluster to export (default: name in the config file)")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "File to write (default: stdout)")
+
+	return cmd
+}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+	cmd := Export()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "export", cmd.Use)
+	assert.Equal(t, "Render the running cluster as k8zner.yaml", cmd.Short)
+	assert.NotNil(t, cmd.RunE)
+	assert.Error(t, cmd.Args(cmd, []string{"extra"}))
+}
+
+func TestExportFlags(t *testing.T) {
+	t.Parallel()
+	cmd := Export()
+
+	config := cmd.Flags().Lookup("config")
+	require.NotNil(t, config)
+	assert.Equal(t, "c", config.Shorthand)
+
+	require.NotNil(t, cmd.Flags().Lookup("name"))
+
+	output := cmd.Flags().Lookup("output")
+	require.NotNil(t, output)
+	assert.Equal(t, "o", output.Shorthand)
+	assert.Empty(t, output.DefValue)
+}
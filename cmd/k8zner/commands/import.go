@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// Import returns the command for adopting a Talos cluster created without k8zner.
+//
+// Required flags:
+//
+//	--name: Name the cluster gets in k8zner
+//	--kubeconfig: kubeconfig of the cluster
+//	--talosconfig: talosconfig of the cluster
+//
+// Optional flags:
+//
+//	--allow-config-changes: Import even if the generated machine configs change the CNI, kube-proxy, cloud provider or cluster name
+//	--dry-run: Print what would be relabeled, the reconstructed spec and the machine config diff without changing anything
+//
+// Environment variables:
+//
+//	HCLOUD_TOKEN: Hetzner Cloud API token (required)
+func Import() *cobra.Command {
+	var name string
+	var kubeconfig string
+	var talosconfig string
+	var allowConfigChanges bool
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Adopt an existing Talos cluster on Hetzner Cloud",
+		Long: `Hand a Talos cluster that was built with Terraform or by hand over to the
+k8zner operator, without rebuilding it.
+
+This command:
+  1. Matches the Kubernetes nodes to servers in the Hetzner Cloud project
+  2. Finds the private network, firewall and Kubernetes API load balancer
+  3. Recovers the Talos secrets from a control plane's machine config
+  4. Labels the servers and renames the network, firewall and load balancer
+     the way k8zner names them
+  5. Creates the k8zner-credentials Secret, installs the operator and creates
+     a K8znerCluster describing the cluster as it runs
+
+The nodes keep their machine configs: the K8znerCluster is annotated with
+k8zner.io/pause-machine-config-sync=true. Once the --dry-run diff looks
+right, remove the annotation to let the operator roll out the configs k8zner
+generates one node at a time. Clusters whose CNI, kube-proxy, cloud provider
+or cluster name settings differ from them are refused unless
+--allow-config-changes is set.
+
+secrets.yaml, talosconfig and kubeconfig are written to the selected workspace
+(see --workspace), or the working directory without one, for later k8zner
+commands. Other load balancers, such as the ones created for
+Services, are left alone.
+
+Examples:
+  # Show what would be relabeled, the reconstructed spec and the machine config diff
+  k8zner import --name prod --kubeconfig ./kubeconfig --talosconfig ./talosconfig --dry-run
+
+  # Import the cluster
+  k8zner import --name prod --kubeconfig ./kubeconfig --talosconfig ./talosconfig`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return handlers.Import(cmd.Context(), name, kubeconfig, talosconfig, allowConfigChanges, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Cluster name in k8zner")
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "kubeconfig of the cluster")
+	cmd.Flags().StringVar(&talosconfig, "talosconfig", "", "talosconfig of the cluster")
+	cmd.Flags().BoolVar(&allowConfigChanges, "allow-config-changes", false,
+		"Import even if the generated machine configs change the CNI, kube-proxy, cloud provider or cluster name")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the changes without making them")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("kubeconfig")
+	_ = cmd.MarkFlagRequired("talosconfig")
+
+	return cmd
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+	cmd := Import()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "import", cmd.Use)
+	assert.Equal(t, "Adopt an existing Talos cluster on Hetzner Cloud", cmd.Short)
+	assert.NotNil(t, cmd.RunE)
+}
+
+func TestImport_Flags(t *testing.T) {
+	t.Parallel()
+	cmd := Import()
+
+	for _, name := range []string{"name", "kubeconfig", "talosconfig"} {
+		flag := cmd.Flags().Lookup(name)
+		require.NotNil(t, flag, name)
+		_, required := flag.Annotations["cobra_annotation_bash_completion_one_required_flag"]
+		assert.True(t, required, "%s flag should be required", name)
+	}
+
+	for _, name := range []string{"allow-config-changes", "dry-run"} {
+		flag := cmd.Flags().Lookup(name)
+		require.NotNil(t, flag, name)
+		assert.Equal(t, "false", flag.DefValue, name)
+	}
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// Migrate returns the command group for moving clusters from other tools to k8zner.
+//
+// Subcommands:
+//
+//	terraform: Convert a terraform-hcloud-kubernetes configuration
+func Migrate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move a cluster configuration from another tool to k8zner",
+		Long: `Convert the configuration of a cluster managed by another tool into k8zner.yaml.
+
+Examples:
+  # Convert the variables of a terraform-hcloud-kubernetes module call
+  k8zner migrate terraform --tfvars terraform.tfvars`,
+	}
+
+	cmd.AddCommand(migrateTerraform())
+
+	return cmd
+}
+
+// migrateTerraform returns the command converting terraform-hcloud-kubernetes variables.
+//
+// Required flags:
+//
+//	--tfvars: Variable file of the module call (.tfvars or .tfvars.json)
+//
+// Optional flags:
+//
+//	--tfstate: Terraform state of the running cluster (terraform state pull)
+//	--output, -o: Path to output file (default "k8zner.yaml")
+func migrateTerraform() *cobra.Command {
+	var tfvarsPath string
+	var tfstatePath string
+	var outputPath string
+
+	cmd := &cobra.Command{
+		Use:   "terraform",
+		Short: "Convert a terraform-hcloud-kubernetes configuration",
+		Long: `Translate the variables of a terraform-hcloud-kubernetes module call into
+k8zner.yaml. Settings k8zner has no equivalent for, or uses fixed values for,
+are listed instead of being converted. Unsupported server types, locations and
+pool sizes are kept as they are, so apply reports them before changing anything.
+
+With --tfstate, the Hetzner Cloud resources in the Terraform state are listed
+with what k8zner import adopts them as, and the kubeconfig and talosconfig
+outputs of the state are written to the current directory. The printed next
+steps hand the running cluster over with k8zner import instead of recreating
+it, and remove it from the Terraform state.
+
+Examples:
+  # Convert the variables for a new cluster
+  k8zner migrate terraform --tfvars terraform.tfvars
+
+  # Prepare taking over the running cluster
+  terraform state pull > terraform.tfstate.json
+  k8zner migrate terraform --tfvars terraform.tfvars --tfstate terraform.tfstate.json`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return handlers.MigrateTerraform(tfvarsPath, tfstatePath, outputPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&tfvarsPath, "tfvars", "", "Variable file of the module call (.tfvars or .tfvars.json)")
+	cmd.Flags().StringVar(&tfstatePath, "tfstate", "", "Terraform state of the running cluster (output of terraform state pull)")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "k8zner.yaml", "Output file path")
+	_ = cmd.MarkFlagRequired("tfvars")
+
+	return cmd
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+	cmd := Migrate()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "migrate", cmd.Use)
+
+	tf, _, err := cmd.Find([]string{"terraform"})
+	require.NoError(t, err)
+	assert.Equal(t, "terraform", tf.Name())
+	assert.Error(t, tf.Args(tf, []string{"extra"}))
+}
+
+func TestMigrateTerraform_Flags(t *testing.T) {
+	t.Parallel()
+	cmd := migrateTerraform()
+
+	tfvars := cmd.Flags().Lookup("tfvars")
+	require.NotNil(t, tfvars)
+	_, required := tfvars.Annotations["cobra_annotation_bash_completion_one_required_flag"]
+	assert.True(t, required, "tfvars flag should be required")
+
+	require.NotNil(t, cmd.Flags().Lookup("tfstate"))
+	output := cmd.Flags().Lookup("output")
+	require.NotNil(t, output)
+	assert.Equal(t, "o", output.Shorthand)
+	assert.Equal(t, "k8zner.yaml", output.DefValue)
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// Plan returns the command for previewing what apply would change.
+//
+// Optional flags:
+//
+//	--config, -c: Path to cluster configuration YAML file (default: auto-detect k8zner.yaml)
+//	--json: Output the plan in JSON format
+//
+// Environment variables:
+//
+//	HCLOUD_TOKEN: Hetzner Cloud API token (required)
+func Plan() *cobra.Command {
+	var configPath string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show what apply would change",
+		Long: `Preview the changes 'k8zner apply' would make, without making them.
+
+The plan lists:
+  - K8znerCluster spec fields that change, compared with the running cluster
+  - Hetzner Cloud resources (network, firewall, load balancer, servers) that
+    would be created, replaced or deleted
+  - The actions the operator takes as a result, such as rolling upgrades or
+    server replacements
+  - The monthly cost delta
+
+With --json the plan is printed as JSON; its "changed" field tells CI
+approval gates whether apply would change anything.
+
+Examples:
+  # Preview changes for k8zner.yaml in the current directory
+  k8zner plan
+
+  # Machine-readable plan for CI
+  k8zner plan --json > plan.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return handlers.Plan(cmd.Context(), configPath, jsonOutput)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to configuration file (default: k8zner.yaml)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+
+	return cmd
+}
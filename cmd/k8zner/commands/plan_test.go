@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+	cmd := Plan()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "plan", cmd.Use)
+	assert.Equal(t, "Show what apply would change", cmd.Short)
+	assert.NotNil(t, cmd.RunE)
+}
+
+func TestPlanFlags(t *testing.T) {
+	t.Parallel()
+	cmd := Plan()
+
+	config := cmd.Flags().Lookup("config")
+	require.NotNil(t, config)
+	assert.Equal(t, "c", config.Shorthand)
+
+	jsonFlag := cmd.Flags().Lookup("json")
+	require.NotNil(t, jsonFlag)
+	assert.Equal(t, "false", jsonFlag.DefValue)
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// Restore returns the command for rebuilding a cluster from an etcd snapshot.
+//
+// Required flags:
+//
+//	--from: Snapshot key in the backup bucket, or "latest"
+//
+// Optional flags:
+//
+//	--config, -c: Path to cluster configuration YAML file (default: auto-detect k8zner.yaml)
+//	--identity, -i: age identity file for encrypted snapshots
+//	--wait: Wait for operator to complete provisioning
+//
+// Environment variables:
+//
+//	HCLOUD_TOKEN: Hetzner Cloud API token (required)
+//	HETZNER_S3_ACCESS_KEY, HETZNER_S3_SECRET_KEY: Object Storage credentials (required)
+func Restore() *cobra.Command {
+	var configPath string
+	var from string
+	var identityPath string
+	var wait bool
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Rebuild the cluster from an etcd backup",
+		Long: `Rebuild your Kubernetes cluster on fresh infrastructure from an etcd
+snapshot taken by the backup CronJob.
+
+This command:
+  1. Downloads the snapshot from the backup bucket and decrypts it
+  2. Creates infrastructure (network, firewall, LB, placement group)
+  3. Bootstraps the first control plane, recovering etcd from the snapshot
+  4. Deploys the k8zner operator and hands the cluster over to it
+
+The cluster must not exist any more; destroy it first if it is broken but
+still running. Run the command with the secrets.yaml of the original cluster
+in the current directory: the snapshot's Kubernetes secrets are encrypted
+with its keys.
+
+Examples:
+  # Restore the most recent snapshot
+  k8zner restore --from latest --identity ~/.config/k8zner/backup-key.txt
+
+  # Restore a specific snapshot
+  k8zner restore --from etcd-backups/prod-2026-01-02T03-00-00Z.snap.zst.age -i key.txt`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return handlers.Restore(cmd.Context(), configPath, from, identityPath, wait)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to configuration file (default: k8zner.yaml)")
+	cmd.Flags().StringVar(&from, "from", "", `Snapshot key to restore, or "latest"`)
+	cmd.Flags().StringVarP(&identityPath, "identity", "i", "", "age identity file for encrypted snapshots")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for operator to complete provisioning")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
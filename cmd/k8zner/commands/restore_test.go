@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestore(t *testing.T) {
+	t.Parallel()
+	cmd := Restore()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "restore", cmd.Use)
+	assert.Equal(t, "Rebuild the cluster from an etcd backup", cmd.Short)
+	assert.Contains(t, cmd.Long, "secrets.yaml")
+	assert.NotNil(t, cmd.RunE)
+}
+
+func TestRestore_Flags(t *testing.T) {
+	t.Parallel()
+	cmd := Restore()
+
+	from := cmd.Flags().Lookup("from")
+	require.NotNil(t, from)
+	_, required := from.Annotations["cobra_annotation_bash_completion_one_required_flag"]
+	assert.True(t, required, "from flag should be required")
+
+	identity := cmd.Flags().Lookup("identity")
+	require.NotNil(t, identity)
+	assert.Equal(t, "i", identity.Shorthand)
+
+	config := cmd.Flags().Lookup("config")
+	require.NotNil(t, config)
+	assert.Equal(t, "c", config.Shorthand)
+
+	require.NotNil(t, cmd.Flags().Lookup("wait"))
+}
@@ -5,29 +5,88 @@
 // functions in the handlers package.
 package commands
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// noWorkspaceAnnotation marks commands that do not work on a cluster, so a
+// stale 'k8zner use' selection cannot break them.
+const noWorkspaceAnnotation = "k8zner/no-workspace"
 
 // Root returns the root command for the k8zner CLI.
 //
 // The root command serves as the entry point and parent for all subcommands.
 // It provides basic CLI metadata and organizes the command hierarchy.
+//
+// Global flags:
+//
+//	--workspace: Cluster workspace to use (default: the one selected with 'k8zner use')
+//
+// Environment variables:
+//
+//	K8ZNER_HOME: Directory holding the workspaces (default: ~/.k8zner)
 func Root() *cobra.Command {
+	var workspace string
+
 	cmd := &cobra.Command{
 		Use:   "k8zner",
 		Short: "Provision Kubernetes on Hetzner Cloud using Talos",
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if !usesWorkspace(cmd) {
+				return nil
+			}
+			return handlers.SelectWorkspace(workspace)
+		},
 	}
 
+	cmd.PersistentFlags().StringVar(&workspace, "workspace", "",
+		"Cluster workspace under $K8ZNER_HOME to use (default: the one selected with 'k8zner use', else the working directory)")
+
 	// Core commands
 	cmd.AddCommand(Init())
 	cmd.AddCommand(Apply())
+	cmd.AddCommand(Plan())
+	cmd.AddCommand(Import())
+	cmd.AddCommand(Migrate())
+	cmd.AddCommand(Export())
 	cmd.AddCommand(Destroy())
+	cmd.AddCommand(Restore())
+	cmd.AddCommand(Backup())
+	cmd.AddCommand(State())
 	cmd.AddCommand(Doctor())
 	cmd.AddCommand(Cost())
 	cmd.AddCommand(Secrets())
+	cmd.AddCommand(Rotate())
+
+	// Workspace commands
+	cmd.AddCommand(withoutWorkspace(Clusters()))
+	cmd.AddCommand(withoutWorkspace(Use()))
 
 	// Utility commands
-	cmd.AddCommand(Version())
-	cmd.AddCommand(Completion())
+	cmd.AddCommand(withoutWorkspace(Version()))
+	cmd.AddCommand(withoutWorkspace(Completion()))
+
+	return cmd
+}
 
+// withoutWorkspace marks cmd and its subcommands as not working on a cluster.
+func withoutWorkspace(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[noWorkspaceAnnotation] = "true"
 	return cmd
 }
+
+// usesWorkspace reports whether cmd works on a cluster and reads its files
+// from the selected workspace.
+func usesWorkspace(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if _, ok := c.Annotations[noWorkspaceAnnotation]; ok {
+			return false
+		}
+	}
+	return cmd.Name() != "help" && cmd.Name() != cobra.ShellCompRequestCmd
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,10 +22,20 @@ func TestRoot_HasSubcommands(t *testing.T) {
 	expectedSubcommands := []string{
 		"init",
 		"apply",
+		"plan",
+		"import",
+		"migrate",
+		"export",
 		"destroy",
+		"restore",
+		"backup",
+		"state",
 		"doctor",
 		"cost",
 		"secrets",
+		"rotate",
+		"clusters",
+		"use",
 		"version",
 		"completion",
 	}
@@ -41,5 +52,32 @@ func TestRoot_HasSubcommands(t *testing.T) {
 
 func TestRoot_SubcommandCount(t *testing.T) {
 	cmd := Root()
-	assert.Len(t, cmd.Commands(), 8, "Expected 8 subcommands")
+	assert.Len(t, cmd.Commands(), 18, "Expected 18 subcommands")
+}
+
+func TestRoot_WorkspaceFlag(t *testing.T) {
+	cmd := Root()
+
+	workspace := cmd.PersistentFlags().Lookup("workspace")
+	require.NotNil(t, workspace)
+	assert.Empty(t, workspace.DefValue)
+	assert.NotNil(t, cmd.PersistentPreRunE)
+}
+
+func TestUsesWorkspace(t *testing.T) {
+	cmd := Root()
+	cmd.InitDefaultHelpCmd()
+
+	for args, want := range map[string]bool{
+		"apply":         true,
+		"state export":  true,
+		"clusters list": false,
+		"use":           false,
+		"version":       false,
+		"help":          false,
+	} {
+		sub, _, err := cmd.Find(strings.Fields(args))
+		require.NoError(t, err, args)
+		assert.Equal(t, want, usesWorkspace(sub), args)
+	}
 }
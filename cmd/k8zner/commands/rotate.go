@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// Rotate returns the command group for rotating cluster credentials.
+//
+// Subcommands:
+//
+//	ca: Rotate the Talos API and Kubernetes CAs
+//	hcloud-token: Replace the Hetzner Cloud API token used inside the cluster
+//
+// Persistent flags:
+//
+//	--config, -c: Path to cluster configuration YAML file (default: auto-detect k8zner.yaml)
+//
+// Environment variables:
+//
+//	HCLOUD_TOKEN: The new Hetzner Cloud API token (hcloud-token)
+func Rotate() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotate cluster credentials",
+		Long: `Rotate the credentials of a running cluster without rebuilding it.
+
+Examples:
+  # Replace the Talos API and Kubernetes CAs
+  k8zner rotate ca
+
+  # Switch the cluster to a new Hetzner Cloud API token
+  HCLOUD_TOKEN=<new token> k8zner rotate hcloud-token`,
+	}
+
+	cmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Path to configuration file (default: k8zner.yaml)")
+
+	cmd.AddCommand(rotateCA(&configPath))
+	cmd.AddCommand(rotateHCloudToken(&configPath))
+
+	return cmd
+}
+
+func rotateCA(configPath *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ca",
+		Short: "Rotate the Talos API and Kubernetes CAs",
+		Long: `Replace the Talos API and Kubernetes CAs of the cluster. The etcd CA is kept.
+
+The operator rotates the CAs in three rolling machine config updates: nodes
+first accept the new CAs, then switch to them, then stop accepting the old ones.
+An etcd snapshot is taken first when backups are enabled. When the rotation is
+done, secrets.yaml, talosconfig and kubeconfig in the current directory are
+replaced; copies of the old files stop working.
+
+Workloads that cache the cluster CA may need a restart afterwards. If the
+command is interrupted, run it again to keep waiting for the same rotation.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return handlers.RotateCA(cmd.Context(), *configPath)
+		},
+	}
+}
+
+func rotateHCloudToken(configPath *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "hcloud-token",
+		Short: "Replace the Hetzner Cloud API token used inside the cluster",
+		Long: `Replace the Hetzner Cloud API token stored in the cluster with the one in
+HCLOUD_TOKEN. Create the new token in the Hetzner Cloud Console first.
+
+The new token is checked against the Hetzner Cloud API, then the
+k8zner-credentials, hcloud (CCM and CSI) and k8zner-operator-credentials
+Secrets are updated and the CCM, CSI controller and operator are restarted
+one after another. Once the operator reports CCM and CSI healthy, the old
+token can be deleted. If a restart or the health check fails, the previous
+token is restored.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return handlers.RotateHCloudToken(cmd.Context(), *configPath)
+		},
+	}
+}
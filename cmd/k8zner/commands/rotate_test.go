@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotate(t *testing.T) {
+	t.Parallel()
+	cmd := Rotate()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "rotate", cmd.Use)
+	require.NotNil(t, cmd.PersistentFlags().Lookup("config"))
+
+	ca, _, err := cmd.Find([]string{"ca"})
+	require.NoError(t, err)
+	assert.Equal(t, "ca", ca.Name())
+	assert.Error(t, ca.Args(ca, []string{"extra"}))
+
+	token, _, err := cmd.Find([]string{"hcloud-token"})
+	require.NoError(t, err)
+	assert.Equal(t, "hcloud-token", token.Name())
+	assert.Error(t, token.Args(token, []string{"extra"}))
+}
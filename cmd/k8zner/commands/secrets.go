@@ -7,6 +7,14 @@ import (
 )
 
 // Secrets returns the command for retrieving cluster secrets.
+//
+// Subcommands:
+//
+//	decrypt: Write plaintext copies of the encrypted credential files
+//
+// Environment variables:
+//
+//	K8ZNER_STATE_IDENTITY: age identity file to decrypt the credential files with
 func Secrets() *cobra.Command {
 	var configPath string
 	var jsonOutput bool
@@ -31,5 +39,37 @@ Requires a running cluster with a valid kubeconfig file.
 	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to configuration file (default: k8zner.yaml)")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 
+	cmd.AddCommand(secretsDecrypt())
+
+	return cmd
+}
+
+func secretsDecrypt() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "decrypt [file...]",
+		Short: "Write plaintext copies of the encrypted credential files",
+		Long: `Decrypt credential files stored encrypted with secrets_encryption, for tools
+that cannot read age files. Without arguments, every encrypted credential file
+of the selected cluster is decrypted. The files are opened with the age
+identity file in K8ZNER_STATE_IDENTITY.
+
+Do not write the plaintext copies into a directory you commit.
+
+Examples:
+  # Decrypt all files into ~/.kube/k8zner
+  k8zner secrets decrypt --out ~/.kube/k8zner
+
+  # Pipe a single file into another tool
+  k8zner secrets decrypt talosconfig --out - > /tmp/talosconfig`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return handlers.SecretsDecrypt(args, out)
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Directory to write the plaintext files to, or - for stdout")
+	_ = cmd.MarkFlagRequired("out")
+
 	return cmd
 }
@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecrets(t *testing.T) {
+	t.Parallel()
+	cmd := Secrets()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "secrets", cmd.Use)
+	assert.NotNil(t, cmd.RunE, "secrets still shows the cluster secrets itself")
+	require.NotNil(t, cmd.Flags().Lookup("json"))
+
+	decrypt, _, err := cmd.Find([]string{"decrypt"})
+	require.NoError(t, err)
+	assert.Equal(t, "decrypt", decrypt.Name())
+	out := decrypt.Flags().Lookup("out")
+	require.NotNil(t, out)
+	assert.Equal(t, "o", out.Shorthand)
+	assert.Contains(t, out.Annotations, "cobra_annotation_bash_completion_one_required_flag")
+}
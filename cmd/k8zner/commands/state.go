@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// State returns the command group for exporting, importing and unlocking cluster state.
+//
+// Subcommands:
+//
+//	export: Write an encrypted bundle of the cluster credentials
+//	import: Restore the credential files from a bundle
+//	unlock: Show or remove the lock held by apply, destroy or restore
+//
+// Persistent flags:
+//
+//	--config, -c: Path to cluster configuration YAML file (default: auto-detect k8zner.yaml)
+//
+// Environment variables:
+//
+//	K8ZNER_STATE_PASSPHRASE: Passphrase to encrypt or decrypt the bundle with
+//	K8ZNER_STATE_IDENTITY: age identity file to decrypt the s3 state backend with
+//	HETZNER_S3_ACCESS_KEY, HETZNER_S3_SECRET_KEY: Object Storage credentials (--push, --pull, s3 backend)
+func State() *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Export, import and unlock cluster state",
+		Long: `Package everything needed to regain control of a cluster into one encrypted
+file: secrets.yaml (the Talos CA and secrets bundle), talosconfig, kubeconfig,
+the k8zner-credentials Secret and the K8znerCluster resource.
+
+Bundles are encrypted with age, either for public keys (--recipient) or with
+the passphrase in K8ZNER_STATE_PASSPHRASE.
+
+Examples:
+  # Export for two age keys and keep a copy in the backup bucket
+  k8zner state export --recipient age1... --recipient age1... --push
+
+  # Export with a passphrase
+  K8ZNER_STATE_PASSPHRASE=... k8zner state export --out prod-state.age
+
+  # Restore the files on a new machine
+  k8zner state import prod-state.age --identity ~/.config/k8zner/key.txt
+  k8zner state import --pull latest --identity ~/.config/k8zner/key.txt
+
+  # Remove the lock left behind by a killed apply
+  k8zner state unlock --force`,
+	}
+
+	cmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Path to configuration file (default: k8zner.yaml)")
+
+	cmd.AddCommand(stateExport(&configPath))
+	cmd.AddCommand(stateImport(&configPath))
+	cmd.AddCommand(stateUnlock(&configPath))
+
+	return cmd
+}
+
+func stateExport(configPath *string) *cobra.Command {
+	var out string
+	var recipients []string
+	var push bool
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write an encrypted bundle of the cluster credentials",
+		Long: `Read secrets.yaml, talosconfig and kubeconfig from the current directory and,
+if the cluster is reachable, the k8zner-credentials Secret and K8znerCluster,
+and write them as one age-encrypted file.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return handlers.StateExport(cmd.Context(), *configPath, out, recipients, push)
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Bundle file to write (default: <cluster>-state.age)")
+	cmd.Flags().StringArrayVarP(&recipients, "recipient", "r", nil, "age public key to encrypt for (repeatable)")
+	cmd.Flags().BoolVar(&push, "push", false, "Also upload the bundle to the backup bucket")
+
+	return cmd
+}
+
+func stateImport(configPath *string) *cobra.Command {
+	var pull string
+	var identityPath string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "import [bundle-file]",
+		Short: "Restore the credential files from a bundle",
+		Long: `Decrypt a bundle written by 'k8zner state export' and write its files into
+the current directory. Existing files are kept unless --force is given.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var file string
+			if len(args) > 0 {
+				file = args[0]
+			}
+			return handlers.StateImport(cmd.Context(), *configPath, file, pull, identityPath, force)
+		},
+	}
+
+	cmd.Flags().StringVar(&pull, "pull", "", "Download the bundle from the backup bucket instead (key or \"latest\")")
+	cmd.Flags().StringVarP(&identityPath, "identity", "i", "", "age identity file to decrypt with")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite existing files")
+
+	return cmd
+}
+
+func stateUnlock(configPath *string) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "unlock",
+		Short: "Show or remove the lock held by apply, destroy or restore",
+		Long: `Show who holds the state lock. With --force, remove it so that apply, destroy
+and restore can run again. Only do this when the run holding the lock is gone;
+its lease otherwise expires on its own 15 minutes after the run stopped.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return handlers.StateUnlock(cmd.Context(), *configPath, force)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Remove the lock regardless of its holder")
+
+	return cmd
+}
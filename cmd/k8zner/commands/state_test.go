@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState(t *testing.T) {
+	t.Parallel()
+	cmd := State()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "state", cmd.Use)
+
+	names := make([]string, 0, len(cmd.Commands()))
+	for _, sub := range cmd.Commands() {
+		names = append(names, sub.Name())
+	}
+	assert.ElementsMatch(t, []string{"export", "import", "unlock"}, names)
+	require.NotNil(t, cmd.PersistentFlags().Lookup("config"))
+}
+
+func TestState_SubcommandFlags(t *testing.T) {
+	t.Parallel()
+	cmd := State()
+
+	export, _, err := cmd.Find([]string{"export"})
+	require.NoError(t, err)
+	assert.Error(t, export.Args(export, []string{"extra"}))
+	for _, name := range []string{"out", "recipient", "push"} {
+		assert.NotNil(t, export.Flags().Lookup(name), name)
+	}
+	assert.Equal(t, "r", export.Flags().Lookup("recipient").Shorthand)
+
+	imp, _, err := cmd.Find([]string{"import"})
+	require.NoError(t, err)
+	assert.NoError(t, imp.Args(imp, nil), "the bundle file is optional with --pull")
+	assert.NoError(t, imp.Args(imp, []string{"prod-state.age"}))
+	assert.Error(t, imp.Args(imp, []string{"a", "b"}))
+	for _, name := range []string{"pull", "identity", "force"} {
+		assert.NotNil(t, imp.Flags().Lookup(name), name)
+	}
+
+	unlock, _, err := cmd.Find([]string{"unlock"})
+	require.NoError(t, err)
+	assert.Error(t, unlock.Args(unlock, []string{"extra"}))
+	assert.NotNil(t, unlock.Flags().Lookup("force"))
+}
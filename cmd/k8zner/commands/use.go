@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/milankappen/k8zner/cmd/k8zner/handlers"
+)
+
+// Use returns the command for selecting the cluster workspace the other
+// commands work in.
+//
+// Optional flags:
+//
+//	--clear: Clear the selection and use the working directory again
+func Use() *cobra.Command {
+	var clearSelection bool
+
+	cmd := &cobra.Command{
+		Use:   "use <cluster>",
+		Short: "Select the cluster the other commands work on",
+		Long: `Select the workspace of a cluster under $K8ZNER_HOME/clusters for all
+following commands, which then read k8zner.yaml and the credential files from
+it instead of the working directory. --workspace overrides the selection for
+a single command.
+
+Examples:
+  # Create a workspace and a config for a new cluster
+  k8zner init --workspace prod
+
+  # Work on prod from any directory
+  k8zner use prod
+  k8zner doctor
+
+  # Go back to the working directory
+  k8zner use --clear`,
+		Args: func(_ *cobra.Command, args []string) error {
+			switch {
+			case clearSelection && len(args) > 0:
+				return errors.New("--clear takes no cluster")
+			case !clearSelection && len(args) != 1:
+				return errors.New("name the cluster to use, or pass --clear")
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			var cluster string
+			if len(args) > 0 {
+				cluster = args[0]
+			}
+			return handlers.Use(cluster, clearSelection)
+		},
+	}
+
+	cmd.Flags().BoolVar(&clearSelection, "clear", false, "Clear the selection and use the working directory again")
+
+	return cmd
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUse(t *testing.T) {
+	t.Parallel()
+	cmd := Use()
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "use <cluster>", cmd.Use)
+	assert.Equal(t, "Select the cluster the other commands work on", cmd.Short)
+	assert.NotNil(t, cmd.RunE)
+	require.NotNil(t, cmd.Flags().Lookup("clear"))
+}
+
+func TestUseArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		clear   bool
+		wantErr bool
+	}{
+		{name: "cluster", args: []string{"prod"}},
+		{name: "no cluster", wantErr: true},
+		{name: "two clusters", args: []string{"prod", "dev"}, wantErr: true},
+		{name: "clear", clear: true},
+		{name: "clear with cluster", args: []string{"prod"}, clear: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := Use()
+			if tt.clear {
+				require.NoError(t, cmd.Flags().Set("clear", "true"))
+			}
+			err := cmd.Args(cmd, tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
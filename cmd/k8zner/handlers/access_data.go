@@ -47,7 +47,7 @@ func persistAccessData(ctx context.Context, cfg *config.Config, kubeconfig []byt
 		return fmt.Errorf("failed to marshal access data: %w", err)
 	}
 
-	if err := writeFile(accessDataPath, content, 0600); err != nil {
+	if err := writeLocalFile(accessDataPath, content); err != nil {
 		return fmt.Errorf("failed to write access data: %w", err)
 	}
 
@@ -7,6 +7,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -15,7 +16,6 @@ import (
 
 	"github.com/mattn/go-isatty"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
@@ -61,7 +61,7 @@ var (
 	}
 
 	// getOrGenerateSecrets loads or generates Talos secrets.
-	getOrGenerateSecrets = talos.GetOrGenerateSecrets
+	getOrGenerateSecrets = loadOrGenerateSecrets
 
 	// newTalosGenerator creates a new Talos configuration generator.
 	newTalosGenerator = func(clusterName, kubernetesVersion, talosVersion, endpoint string, sb *secrets.Bundle) provisioning.TalosConfigProducer {
@@ -78,7 +78,7 @@ var (
 	expandV2Config = config.ExpandSpec
 
 	// findV2ConfigFile finds the v2 config file (for testing injection).
-	findV2ConfigFile = config.FindConfigFile
+	findV2ConfigFile = findConfigFile
 
 	newProvisioningContext = provisioning.NewContext
 )
@@ -96,6 +96,7 @@ func IsCIMode(ci bool) bool {
 
 // Apply creates or updates a Kubernetes cluster on Hetzner Cloud using Talos Linux.
 // Checks for existing operator-managed clusters to update, otherwise bootstraps from scratch.
+// The cluster state is locked for the whole run.
 func Apply(ctx context.Context, configPath string, wait, ci bool) error {
 	cfg, err := loadConfig(configPath)
 	if err != nil {
@@ -104,6 +105,13 @@ func Apply(ctx context.Context, configPath string, wait, ci bool) error {
 
 	log.Printf("Applying configuration for cluster: %s", cfg.ClusterName)
 
+	return withStateLock(ctx, cfg, "apply", func(ctx context.Context) error {
+		return applyConfig(ctx, cfg, wait, ci)
+	})
+}
+
+// applyConfig updates an operator-managed cluster or bootstraps a new one.
+func applyConfig(ctx context.Context, cfg *config.Config, wait, ci bool) error {
 	// Check if cluster already exists with operator management (short timeout to avoid slow startup)
 	checkCtx, checkCancel := context.WithTimeout(ctx, 3*time.Second)
 	isOperatorManaged, _ := checkOperatorManaged(checkCtx, cfg.ClusterName)
@@ -124,7 +132,7 @@ func Apply(ctx context.Context, configPath string, wait, ci bool) error {
 
 // updateExistingCluster updates an existing operator-managed cluster's CRD spec.
 func updateExistingCluster(ctx context.Context, cfg *config.Config) error {
-	kubecfg, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	kubecfg, err := loadRESTConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
@@ -145,13 +153,32 @@ func updateExistingCluster(ctx context.Context, cfg *config.Config) error {
 		return fmt.Errorf("failed to get K8znerCluster: %w", err)
 	}
 
+	live := k8zCluster.DeepCopy()
+	previousCPs := k8zCluster.Spec.ControlPlanes.Count
 	updateClusterSpecFromConfig(k8zCluster, cfg)
 
+	drift, err := specDrift(live, k8zCluster)
+	if err != nil {
+		log.Printf("Warning: skipping the drift check: %v", err)
+	}
+	if len(drift) > 0 {
+		log.Printf("Warning: K8znerCluster %s was edited since the last apply, k8zner.yaml overwrites these changes:", cfg.ClusterName)
+		for _, warning := range driftWarnings(drift) {
+			log.Printf("  - %s", warning)
+		}
+	}
+
 	if err := k8sClient.Update(ctx, k8zCluster); err != nil {
 		return fmt.Errorf("failed to update K8znerCluster: %w", err)
 	}
+	if err := recordAppliedSpec(ctx, k8sClient, k8zCluster); err != nil {
+		log.Printf("Warning: %v", err)
+	}
 
 	log.Printf("Updated K8znerCluster %s spec", cfg.ClusterName)
+	if desired := k8zCluster.Spec.ControlPlanes.Count; desired < previousCPs {
+		log.Printf("Control planes will be scaled down from %d to %d, one etcd member at a time.", previousCPs, desired)
+	}
 	log.Printf("\nThe operator will now reconcile the changes.")
 	log.Printf("Monitor progress with:")
 	log.Printf("  k8zner doctor --watch")
@@ -161,7 +188,7 @@ func updateExistingCluster(ctx context.Context, cfg *config.Config) error {
 
 // bootstrapNewCluster creates a new cluster from scratch (CI/non-interactive mode).
 func bootstrapNewCluster(ctx context.Context, cfg *config.Config, wait bool) error {
-	kubeconfig, err := runBootstrapPipeline(ctx, cfg, wait, nil)
+	kubeconfig, err := runBootstrapPipeline(ctx, cfg, wait, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -181,7 +208,7 @@ func bootstrapNewClusterTUI(ctx context.Context, cfg *config.Config, wait bool)
 
 	bootstrapFn := func(ch chan<- tui.BootstrapPhaseMsg) error {
 		var err error
-		kubeconfig, err = runBootstrapPipeline(ctx, cfg, wait, ch)
+		kubeconfig, err = runBootstrapPipeline(ctx, cfg, wait, ch, nil)
 		return err
 	}
 
@@ -199,7 +226,7 @@ func bootstrapNewClusterTUI(ctx context.Context, cfg *config.Config, wait bool)
 		if rehydrateErr := persistAccessData(ctx, cfg, kubeconfig, true); rehydrateErr != nil {
 			log.Printf("Warning: failed to re-hydrate access data: %v", rehydrateErr)
 		}
-		fmt.Println("Access credentials saved to: access-data.yaml")
+		fmt.Printf("Access credentials saved to: %s\n", localFiles.Path(accessDataPath))
 	}
 
 	printApplySuccess(cfg, wait)
@@ -210,7 +237,8 @@ func bootstrapNewClusterTUI(ctx context.Context, cfg *config.Config, wait bool)
 // runBootstrapPipeline executes the shared bootstrap pipeline.
 // Flow: Image -> Infrastructure -> 1 CP -> Bootstrap -> Install operator -> Create CRD.
 // When ch is non-nil, phase progress messages are sent for TUI display.
-func runBootstrapPipeline(ctx context.Context, cfg *config.Config, wait bool, ch chan<- tui.BootstrapPhaseMsg) (kubeconfig []byte, err error) {
+// When etcdSnapshot is non-nil, etcd is recovered from it instead of starting empty.
+func runBootstrapPipeline(ctx context.Context, cfg *config.Config, wait bool, ch chan<- tui.BootstrapPhaseMsg, etcdSnapshot []byte) (kubeconfig []byte, err error) {
 	phase := func(name string, done bool) {
 		if ch != nil {
 			ch <- tui.BootstrapPhaseMsg{Phase: name, Done: done}
@@ -234,6 +262,7 @@ func runBootstrapPipeline(ctx context.Context, cfg *config.Config, wait bool, ch
 	}
 
 	pCtx := newProvisioningContext(ctx, cfg, infraClient, talosGen)
+	pCtx.State.EtcdSnapshot = etcdSnapshot
 
 	var cleanupNeeded bool
 	defer func() {
@@ -318,11 +347,10 @@ func runBootstrapPipeline(ctx context.Context, cfg *config.Config, wait bool, ch
 
 // checkOperatorManaged checks if a cluster is managed by the operator.
 func checkOperatorManaged(ctx context.Context, clusterName string) (bool, error) {
-	if _, err := os.Stat(kubeconfigPath); os.IsNotExist(err) {
+	kubecfg, err := loadRESTConfig()
+	if errors.Is(err, os.ErrNotExist) {
 		return false, nil
 	}
-
-	kubecfg, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
 	if err != nil {
 		return false, err
 	}
@@ -355,20 +383,25 @@ func updateClusterSpecFromConfig(k8zCluster *k8znerv1alpha1.K8znerCluster, cfg *
 	}
 
 	if len(cfg.Workers) > 0 {
-		pool := cfg.Workers[0]
-		k8zCluster.Spec.Workers.Count = pool.Count
-		k8zCluster.Spec.Workers.Size = pool.ServerType
+		k8zCluster.Spec.Workers.Count = cfg.WorkerCount()
+		k8zCluster.Spec.Workers.Size = cfg.Workers[0].ServerType
+	}
+	if pools := buildWorkerPoolSpecs(cfg); len(pools) > 0 {
+		k8zCluster.Spec.WorkerPools = pools
 	}
 
 	k8zCluster.Spec.Talos.Version = cfg.Talos.Version
 	k8zCluster.Spec.Talos.SchematicID = cfg.Talos.SchematicID
 	k8zCluster.Spec.Talos.Extensions = cfg.Talos.Extensions
+	k8zCluster.Spec.Talos.ConfigApplyMode = cfg.Talos.Machine.ConfigApplyMode
 	k8zCluster.Spec.Kubernetes.Version = cfg.Kubernetes.Version
 
 	k8zCluster.Spec.Network.IPv4CIDR = cfg.Network.IPv4CIDR
 	k8zCluster.Spec.Network.PodCIDR = cfg.Network.PodIPv4CIDR
 	k8zCluster.Spec.Network.ServiceCIDR = cfg.Network.ServiceIPv4CIDR
 
+	k8zCluster.Spec.Maintenance = buildMaintenanceSpec(cfg)
+
 	if k8zCluster.Spec.Addons == nil {
 		k8zCluster.Spec.Addons = &k8znerv1alpha1.AddonSpec{}
 	}
@@ -406,6 +439,7 @@ func loadConfig(configPath string) (*config.Config, error) {
 		}
 		configPath = path
 	}
+	configPath = workspacePath(configPath)
 
 	v2Cfg, err := loadV2ConfigFile(configPath)
 	if err != nil {
@@ -413,5 +447,12 @@ func loadConfig(configPath string) (*config.Config, error) {
 	}
 
 	log.Printf("Using config: %s", configPath)
-	return expandV2Config(v2Cfg)
+	cfg, err := expandV2Config(v2Cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := useSecretsEncryption(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
@@ -68,7 +68,9 @@ func (m *mockTalosProducer) SetMachineConfigOptions(_ any) {}
 func (m *mockTalosProducer) GenerateControlPlaneConfig(_ []string, _ string, _ int64) ([]byte, error) {
 	return nil, nil
 }
-func (m *mockTalosProducer) GenerateWorkerConfig(_ string, _ int64) ([]byte, error) { return nil, nil }
+func (m *mockTalosProducer) GenerateWorkerConfig(_ string, _ int64, _ *config.WorkerNodePool) ([]byte, error) {
+	return nil, nil
+}
 func (m *mockTalosProducer) GetClientConfig() ([]byte, error) {
 	return m.clientConfig, m.clientConfigErr
 }
@@ -80,6 +82,9 @@ func (m *mockTalosProducer) UpgradeNode(_ context.Context, _, _ string, _ provis
 	return nil
 }
 func (m *mockTalosProducer) UpgradeKubernetes(_ context.Context, _, _ string) error { return nil }
+func (m *mockTalosProducer) ApplyMachineConfig(_ context.Context, _ string, _ []byte, _ string) (string, error) {
+	return "", nil
+}
 func (m *mockTalosProducer) WaitForNodeReady(_ context.Context, _ string, _ time.Duration) error {
 	return nil
 }
@@ -110,6 +115,7 @@ func TestUpdateClusterSpecFromConfig_FullUpdate(t *testing.T) {
 			Version:     "1.8.3",
 			SchematicID: "abc123",
 			Extensions:  []string{"siderolabs/iscsi-tools"},
+			Machine:     config.TalosMachineConfig{ConfigApplyMode: "no_reboot"},
 		},
 		Network: config.NetworkConfig{
 			IPv4CIDR:        "10.0.0.0/16",
@@ -123,6 +129,10 @@ func TestUpdateClusterSpecFromConfig_FullUpdate(t *testing.T) {
 			ArgoCD:              config.ArgoCDConfig{Enabled: true},
 			KubePrometheusStack: config.KubePrometheusStackConfig{Enabled: true},
 		},
+		Maintenance: &config.MaintenanceSpec{
+			Windows:  []config.MaintenanceWindow{{Schedule: "0 2 * * sat", Duration: "4h"}},
+			Timezone: "Europe/Berlin",
+		},
 	}
 
 	updateClusterSpecFromConfig(cluster, cfg)
@@ -135,6 +145,10 @@ func TestUpdateClusterSpecFromConfig_FullUpdate(t *testing.T) {
 	assert.Equal(t, "1.8.3", cluster.Spec.Talos.Version)
 	assert.Equal(t, "abc123", cluster.Spec.Talos.SchematicID)
 	assert.Equal(t, []string{"siderolabs/iscsi-tools"}, cluster.Spec.Talos.Extensions)
+	assert.Equal(t, "no_reboot", cluster.Spec.Talos.ConfigApplyMode)
+	require.NotNil(t, cluster.Spec.Maintenance)
+	assert.Equal(t, "Europe/Berlin", cluster.Spec.Maintenance.Timezone)
+	assert.Equal(t, []k8znerv1alpha1.MaintenanceWindow{{Schedule: "0 2 * * sat", Duration: "4h"}}, cluster.Spec.Maintenance.Windows)
 	assert.Equal(t, "10.0.0.0/16", cluster.Spec.Network.IPv4CIDR)
 	assert.Equal(t, "10.244.0.0/16", cluster.Spec.Network.PodCIDR)
 	assert.Equal(t, "10.96.0.0/16", cluster.Spec.Network.ServiceCIDR)
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"github.com/charmbracelet/lipgloss"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/milankappen/k8zner/internal/addons/k8sclient"
+	"github.com/milankappen/k8zner/internal/backup"
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+const (
+	// talosBackupNamespace and talosBackupCronJob locate the backup CronJob installed by the talos-backup addon.
+	talosBackupNamespace = "kube-system"
+	talosBackupCronJob   = "talos-backup"
+
+	// backupJobPollInterval is how often a triggered backup Job is checked.
+	backupJobPollInterval = 5 * time.Second
+)
+
+// newK8sClient creates a Kubernetes client from kubeconfig bytes (for testing injection).
+var newK8sClient = k8sclient.NewFromKubeconfig
+
+// backupListOutput is the JSON form of the backup list.
+type backupListOutput struct {
+	Bucket    string             `json:"bucket"`
+	Metadata  *s3.BucketMetadata `json:"metadata,omitempty"`
+	Snapshots []backup.Snapshot  `json:"snapshots"`
+}
+
+// backupInspectOutput is the JSON form of a snapshot inspection.
+type backupInspectOutput struct {
+	Snapshot backup.Snapshot `json:"snapshot"`
+	Status   *backup.Status  `json:"status"`
+}
+
+// BackupList prints the etcd snapshots in the cluster's backup bucket, newest first.
+func BackupList(ctx context.Context, configPath string, jsonOutput bool) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	repo, err := newSnapshotRepository(cfg.Addons.TalosBackup)
+	if err != nil {
+		return fmt.Errorf("failed to open backup bucket: %w", err)
+	}
+
+	metadata, err := repo.Metadata(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read bucket metadata: %w", err)
+	}
+	snapshots, err := repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	if jsonOutput {
+		return printJSON(backupListOutput{Bucket: repo.Bucket(), Metadata: metadata, Snapshots: snapshots})
+	}
+
+	printBackupList(repo.Bucket(), metadata, snapshots, time.Now())
+	return nil
+}
+
+// BackupNow takes an etcd snapshot immediately by running a Job from the
+// talos-backup CronJob, and waits up to timeout for it to finish.
+func BackupNow(ctx context.Context, configPath string, timeout time.Duration) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	kubeconfig, err := localFiles.ReadFile(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("kubeconfig not found. Run 'k8zner apply' first to create the cluster: %w", err)
+	}
+	k8sClient, err := newK8sClient(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	jobName := fmt.Sprintf("%s-manual-%d", talosBackupCronJob, time.Now().Unix())
+	if _, err := k8sClient.CreateJobFromCronJob(ctx, talosBackupNamespace, talosBackupCronJob, jobName); err != nil {
+		return fmt.Errorf("failed to start backup (is backup enabled for cluster %s?): %w", cfg.ClusterName, err)
+	}
+	fmt.Printf("Started backup job %s/%s, waiting for it to finish...\n", talosBackupNamespace, jobName)
+
+	if err := waitForJob(ctx, k8sClient, talosBackupNamespace, jobName, timeout); err != nil {
+		return err
+	}
+	fmt.Println("Backup complete.")
+
+	// Best effort: show where the snapshot went
+	if repo, err := newSnapshotRepository(cfg.Addons.TalosBackup); err == nil {
+		if snap, err := repo.Resolve(ctx, backup.Latest); err == nil {
+			fmt.Printf("Latest snapshot: %s (%s)\n", snap.Key, formatSize(snap.Size))
+		}
+	}
+	return nil
+}
+
+// BackupInspect downloads and decodes a snapshot and prints its etcd revision and key count.
+func BackupInspect(ctx context.Context, configPath, ref, identityPath string, jsonOutput bool) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	repo, err := newSnapshotRepository(cfg.Addons.TalosBackup)
+	if err != nil {
+		return fmt.Errorf("failed to open backup bucket: %w", err)
+	}
+	identities, err := loadAgeIdentities(identityPath)
+	if err != nil {
+		return err
+	}
+
+	snap, err := repo.Resolve(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("failed to find snapshot: %w", err)
+	}
+	data, err := fetchSnapshot(ctx, repo, snap, identities)
+	if err != nil {
+		return err
+	}
+	status, err := backup.Inspect(data)
+	if err != nil {
+		return fmt.Errorf("failed to inspect snapshot %s: %w", snap.Key, err)
+	}
+
+	if jsonOutput {
+		return printJSON(backupInspectOutput{Snapshot: snap, Status: status})
+	}
+
+	fmt.Printf("Snapshot:      %s\n", snap.Key)
+	fmt.Printf("Taken:         %s\n", snap.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Printf("Stored size:   %s\n", formatSize(snap.Size))
+	fmt.Printf("Database size: %s\n", formatSize(status.Size))
+	fmt.Printf("Revision:      %d\n", status.Revision)
+	fmt.Printf("Total keys:    %d\n", status.TotalKeys)
+	if status.HashVerified {
+		fmt.Println("Hash:          verified")
+	} else {
+		fmt.Println("Hash:          not present")
+	}
+	return nil
+}
+
+// loadAgeIdentities loads the age identities in path, or none if path is empty.
+func loadAgeIdentities(path string) ([]age.Identity, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return backup.LoadIdentities(path)
+}
+
+// fetchSnapshot downloads and decodes snap, pointing at --identity when a key is missing.
+func fetchSnapshot(ctx context.Context, repo *backup.Repository, snap backup.Snapshot, identities []age.Identity) ([]byte, error) {
+	data, err := repo.Fetch(ctx, snap.Key, identities)
+	if errors.Is(err, backup.ErrIdentityRequired) {
+		return nil, fmt.Errorf("%w: pass the age identity file with --identity", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// waitForJob polls a Job until it completes, fails or timeout elapses.
+func waitForJob(ctx context.Context, k8sClient k8sclient.Client, namespace, name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		job, err := k8sClient.GetJob(ctx, namespace, name)
+		if err != nil {
+			return err
+		}
+		for _, cond := range job.Status.Conditions {
+			if cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch cond.Type {
+			case batchv1.JobComplete:
+				return nil
+			case batchv1.JobFailed:
+				return fmt.Errorf("backup job %s/%s failed: %s; check its logs with: kubectl logs -n %s job/%s",
+					namespace, name, cond.Message, namespace, name)
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %v waiting for backup job %s/%s", timeout, namespace, name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backupJobPollInterval):
+		}
+	}
+}
+
+// printBackupList renders the bucket metadata and snapshot table.
+func printBackupList(bucket string, metadata *s3.BucketMetadata, snapshots []backup.Snapshot, now time.Time) {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#f9fafb"))
+	nameStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6b7280"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#6b7280"))
+
+	fmt.Println()
+	fmt.Println(titleStyle.Render(fmt.Sprintf("  k8zner backups: %s", bucket)))
+	fmt.Println(dimStyle.Render("  " + strings.Repeat("=", 30)))
+	if metadata != nil {
+		fmt.Printf("  %s  %s\n", nameStyle.Render(fmt.Sprintf("%-12s", "cluster")), metadata.ClusterName)
+		fmt.Printf("  %s  %s\n", nameStyle.Render(fmt.Sprintf("%-12s", "managed by")), metadata.ManagedBy)
+		fmt.Printf("  %s  %s\n", nameStyle.Render(fmt.Sprintf("%-12s", "created")), metadata.CreatedAt)
+	} else {
+		fmt.Println(dimStyle.Render("  No k8zner metadata in this bucket."))
+	}
+	fmt.Println()
+
+	if len(snapshots) == 0 {
+		fmt.Println(dimStyle.Render("  No snapshots found."))
+		fmt.Println()
+		return
+	}
+
+	fmt.Printf("  %-60s  %10s  %-20s  %s\n", "KEY", "SIZE", "TAKEN (UTC)", "AGE")
+	for _, snap := range snapshots {
+		fmt.Printf("  %-60s  %10s  %-20s  %s\n",
+			snap.Key,
+			formatSize(snap.Size),
+			snap.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+			now.Sub(snap.CreatedAt).Truncate(time.Minute))
+	}
+	fmt.Printf("\n  %d snapshot(s)\n\n", len(snapshots))
+}
+
+// formatSize formats a byte count with binary units.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// printJSON writes v as indented JSON to stdout.
+func printJSON(v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	fmt.Println(string(b))
+	return nil
+}
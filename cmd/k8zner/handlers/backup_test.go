@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/milankappen/k8zner/internal/addons/k8sclient"
+	"github.com/milankappen/k8zner/internal/backup"
+	"github.com/milankappen/k8zner/internal/platform/hcloud"
+)
+
+// fakeJobClient returns a fixed Job; other Client methods are not used.
+type fakeJobClient struct {
+	k8sclient.Client
+	job *batchv1.Job
+	err error
+}
+
+func (f *fakeJobClient) GetJob(_ context.Context, _, _ string) (*batchv1.Job, error) {
+	return f.job, f.err
+}
+
+func jobWithCondition(condType batchv1.JobConditionType, message string) *batchv1.Job {
+	return &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+		{Type: condType, Status: corev1.ConditionTrue, Message: message},
+	}}}
+}
+
+func TestWaitForJob(t *testing.T) {
+	t.Parallel()
+
+	t.Run("complete", func(t *testing.T) {
+		t.Parallel()
+		client := &fakeJobClient{job: jobWithCondition(batchv1.JobComplete, "")}
+		require.NoError(t, waitForJob(context.Background(), client, "kube-system", "backup", time.Minute))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		t.Parallel()
+		client := &fakeJobClient{job: jobWithCondition(batchv1.JobFailed, "BackoffLimitExceeded")}
+		err := waitForJob(context.Background(), client, "kube-system", "backup", time.Minute)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "BackoffLimitExceeded")
+		assert.Contains(t, err.Error(), "kubectl logs -n kube-system job/backup")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+		client := &fakeJobClient{job: &batchv1.Job{}}
+		err := waitForJob(context.Background(), client, "kube-system", "backup", 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timed out")
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		t.Parallel()
+		client := &fakeJobClient{err: errors.New("connection refused")}
+		err := waitForJob(context.Background(), client, "kube-system", "backup", time.Minute)
+		assert.ErrorContains(t, err, "connection refused")
+	})
+}
+
+func TestFormatSize(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "512 B", formatSize(512))
+	assert.Equal(t, "1.0 KiB", formatSize(1024))
+	assert.Equal(t, "1.5 MiB", formatSize(1536*1024))
+	assert.Equal(t, "2.0 GiB", formatSize(2<<30))
+}
+
+func TestBackupList(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	setupRestoreTest(t, &fakeSnapshotStore{key: "etcd-backups/test-1.snap", data: []byte("snapshot")}, &hcloud.MockClient{})
+
+	require.NoError(t, BackupList(context.Background(), "k8zner.yaml", false))
+	require.NoError(t, BackupList(context.Background(), "k8zner.yaml", true))
+}
+
+func TestBackupInspect_NotASnapshot(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	setupRestoreTest(t, &fakeSnapshotStore{key: "etcd-backups/test-1.snap", data: []byte("snapshot")}, &hcloud.MockClient{})
+
+	err := BackupInspect(context.Background(), "k8zner.yaml", backup.Latest, "", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to inspect snapshot etcd-backups/test-1.snap")
+}
+
+func TestBackupNow_RequiresKubeconfig(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	setupRestoreTest(t, &fakeSnapshotStore{}, &hcloud.MockClient{})
+
+	err := BackupNow(context.Background(), "k8zner.yaml", time.Minute)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kubeconfig not found")
+}
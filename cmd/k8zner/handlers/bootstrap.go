@@ -44,25 +44,25 @@ func provisionFirstControlPlane(cfg *config.Config, pCtx *provisioning.Context)
 	log.Println("Phase 3/6: Creating first control plane...")
 	originalCPCount := cfg.ControlPlane.NodePools[0].Count
 	cfg.ControlPlane.NodePools[0].Count = 1
-	originalWorkerCount := 0
-	if len(cfg.Workers) > 0 {
-		originalWorkerCount = cfg.Workers[0].Count
-		cfg.Workers[0].Count = 0
+	originalWorkerCounts := make([]int, len(cfg.Workers))
+	for i := range cfg.Workers {
+		originalWorkerCounts[i] = cfg.Workers[i].Count
+		cfg.Workers[i].Count = 0
 	}
-
-	if err := compute.Provision(pCtx); err != nil {
+	restore := func() {
 		cfg.ControlPlane.NodePools[0].Count = originalCPCount
-		if len(cfg.Workers) > 0 {
-			cfg.Workers[0].Count = originalWorkerCount
+		for i := range cfg.Workers {
+			cfg.Workers[i].Count = originalWorkerCounts[i]
 		}
-		return fmt.Errorf("compute provisioning failed: %w", err)
 	}
 
-	cfg.ControlPlane.NodePools[0].Count = originalCPCount
-	if len(cfg.Workers) > 0 {
-		cfg.Workers[0].Count = originalWorkerCount
+	if err := compute.Provision(pCtx); err != nil {
+		restore()
+		return fmt.Errorf("compute provisioning failed: %w", err)
 	}
 
+	restore()
+
 	return nil
 }
 
@@ -169,7 +169,7 @@ func writeTalosFiles(talosGen provisioning.TalosConfigProducer) error {
 		return fmt.Errorf("failed to generate talosconfig: %w", err)
 	}
 
-	if err := writeFile(talosConfigPath, clientCfgBytes, 0600); err != nil {
+	if err := writeLocalFile(talosConfigPath, clientCfgBytes); err != nil {
 		return fmt.Errorf("failed to write talosconfig: %w", err)
 	}
 
@@ -182,7 +182,7 @@ func writeKubeconfig(kubeconfig []byte) error {
 		return nil
 	}
 
-	if err := writeFile(kubeconfigPath, kubeconfig, 0600); err != nil {
+	if err := writeLocalFile(kubeconfigPath, kubeconfig); err != nil {
 		return fmt.Errorf("failed to write kubeconfig: %w", err)
 	}
 
@@ -281,10 +281,10 @@ func isHealthyOnPort(statuses []hcloud.LoadBalancerTargetHealthStatus, port int)
 // printApplySuccess outputs completion message and next steps.
 func printApplySuccess(cfg *config.Config, wait bool) {
 	fmt.Printf("\nBootstrap complete!\n")
-	fmt.Printf("Secrets saved to: %s\n", secretsFile)
-	fmt.Printf("Talos config saved to: %s\n", talosConfigPath)
-	fmt.Printf("Kubeconfig saved to: %s\n", kubeconfigPath)
-	fmt.Printf("Access data saved to: %s\n", accessDataPath)
+	fmt.Printf("Secrets saved to: %s\n", localFiles.Path(secretsFile))
+	fmt.Printf("Talos config saved to: %s\n", localFiles.Path(talosConfigPath))
+	fmt.Printf("Kubeconfig saved to: %s\n", localFiles.Path(kubeconfigPath))
+	fmt.Printf("Access data saved to: %s\n", localFiles.Path(accessDataPath))
 
 	if !wait {
 		fmt.Printf("\nThe operator is now provisioning:\n")
@@ -297,6 +297,11 @@ func printApplySuccess(cfg *config.Config, wait bool) {
 	}
 
 	fmt.Printf("\nAccess your cluster:\n")
-	fmt.Printf("  export KUBECONFIG=%s\n", kubeconfigPath)
+	if localFiles.Encrypted() {
+		fmt.Printf("  k8zner secrets decrypt %s --out ~/.kube/k8zner\n", kubeconfigPath)
+		fmt.Printf("  export KUBECONFIG=~/.kube/k8zner/%s\n", kubeconfigPath)
+	} else {
+		fmt.Printf("  export KUBECONFIG=%s\n", localFiles.Path(kubeconfigPath))
+	}
 	fmt.Printf("  kubectl get nodes\n")
 }
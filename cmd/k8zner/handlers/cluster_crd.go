@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 	"sort"
 	"strings"
 
@@ -51,13 +50,43 @@ func createClusterCRD(ctx context.Context, cfg *config.Config, pCtx *provisionin
 
 	bootstrapName, bootstrapID, bootstrapIP := getBootstrapNode(pCtx)
 	k8znerCluster := buildK8znerCluster(cfg, infraInfo, bootstrapName, bootstrapID, bootstrapIP)
-	if err := k8sClient.Create(ctx, k8znerCluster); err != nil && !apierrors.IsAlreadyExists(err) {
-		return fmt.Errorf("failed to create K8znerCluster: %w", err)
+	if err := createOrReplaceCluster(ctx, k8sClient, k8znerCluster); err != nil {
+		return err
 	}
 
 	return updateClusterStatus(ctx, k8sClient, k8znerCluster)
 }
 
+// createOrReplaceCluster creates the K8znerCluster, replacing the spec of an
+// existing one. A cluster restored from an etcd snapshot already contains the
+// resource, but its spec still describes the original infrastructure. The
+// stored spec is recorded as the baseline for the drift check of apply.
+func createOrReplaceCluster(ctx context.Context, k8sClient client.Client, k8znerCluster *k8znerv1alpha1.K8znerCluster) error {
+	stored := k8znerCluster
+	err := k8sClient.Create(ctx, k8znerCluster)
+	if err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create K8znerCluster: %w", err)
+		}
+
+		existing := &k8znerv1alpha1.K8znerCluster{}
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(k8znerCluster), existing); err != nil {
+			return fmt.Errorf("failed to get existing K8znerCluster: %w", err)
+		}
+		existing.Spec = k8znerCluster.Spec
+		if err := k8sClient.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to update existing K8znerCluster: %w", err)
+		}
+		stored = existing
+	}
+
+	// Patch a copy so the object of the caller is left as it is
+	if err := recordAppliedSpec(ctx, k8sClient, stored.DeepCopy()); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return nil
+}
+
 // ensureNamespace creates the k8zner-system namespace if it doesn't exist.
 func ensureNamespace(ctx context.Context, k8sClient client.Client) error {
 	ns := &corev1.Namespace{
@@ -73,11 +102,11 @@ func ensureNamespace(ctx context.Context, k8sClient client.Client) error {
 
 // createCredentialsSecret creates the Secret containing Hetzner and Talos credentials.
 func createCredentialsSecret(ctx context.Context, k8sClient client.Client, cfg *config.Config, hcloudToken string) error {
-	secretsData, err := os.ReadFile(secretsFile)
+	secretsData, err := localFiles.ReadFile(secretsFile)
 	if err != nil {
 		return fmt.Errorf("failed to read secrets.yaml: %w", err)
 	}
-	talosConfigData, err := os.ReadFile(talosConfigPath)
+	talosConfigData, err := localFiles.ReadFile(talosConfigPath)
 	if err != nil {
 		return fmt.Errorf("failed to read talosconfig: %w", err)
 	}
@@ -157,6 +186,7 @@ func buildClusterSpec(cfg *config.Config, infraInfo *InfrastructureInfo, bootstr
 			Count: cfg.WorkerCount(),
 			Size:  getWorkerSize(cfg),
 		},
+		WorkerPools: buildWorkerPoolSpecs(cfg),
 		Network: k8znerv1alpha1.NetworkSpec{
 			IPv4CIDR:     cfg.Network.IPv4CIDR,
 			NodeIPv4CIDR: cfg.Network.NodeIPv4CIDR,
@@ -170,9 +200,10 @@ func buildClusterSpec(cfg *config.Config, infraInfo *InfrastructureInfo, bootstr
 			Version: cfg.Kubernetes.Version,
 		},
 		Talos: k8znerv1alpha1.TalosSpec{
-			Version:     cfg.Talos.Version,
-			SchematicID: cfg.Talos.SchematicID,
-			Extensions:  cfg.Talos.Extensions,
+			Version:         cfg.Talos.Version,
+			SchematicID:     cfg.Talos.SchematicID,
+			Extensions:      cfg.Talos.Extensions,
+			ConfigApplyMode: cfg.Talos.Machine.ConfigApplyMode,
 		},
 		CredentialsRef: corev1.LocalObjectReference{
 			Name: credentialsSecretName,
@@ -184,8 +215,9 @@ func buildClusterSpec(cfg *config.Config, infraInfo *InfrastructureInfo, bootstr
 			BootstrapNodeID: bootstrapID,
 			PublicIP:        bootstrapIP,
 		},
-		Addons: buildAddonSpec(cfg),
-		Backup: buildBackupSpec(cfg, cfg.ClusterName),
+		Addons:      buildAddonSpec(cfg),
+		Backup:      buildBackupSpec(cfg, cfg.ClusterName),
+		Maintenance: buildMaintenanceSpec(cfg),
 	}
 }
 
@@ -341,6 +373,61 @@ func getWorkerSize(cfg *config.Config) string {
 	return cfg.Workers[0].ServerType
 }
 
+// buildWorkerPoolSpecs converts the worker pools from config into CRD worker pools.
+// Every expanded config has named pools, a spec without workerPools gets one
+// named "workers".
+func buildWorkerPoolSpecs(cfg *config.Config) []k8znerv1alpha1.WorkerPoolSpec {
+	var pools []k8znerv1alpha1.WorkerPoolSpec
+	for _, pool := range cfg.Workers {
+		var taints []corev1.Taint
+		for _, taint := range pool.NodeTaints {
+			taints = append(taints, corev1.Taint{
+				Key:    taint.Key,
+				Value:  taint.Value,
+				Effect: corev1.TaintEffect(taint.Effect),
+			})
+		}
+		var autoscaling *k8znerv1alpha1.WorkerPoolAutoscalingSpec
+		if as := pool.Autoscaling; as != nil {
+			autoscaling = &k8znerv1alpha1.WorkerPoolAutoscalingSpec{
+				MinCount:                      as.MinCount,
+				MaxCount:                      as.MaxCount,
+				ScaleDownUtilizationThreshold: as.ScaleDownUtilizationThreshold,
+				ScaleDownDelay:                as.ScaleDownDelay,
+			}
+		}
+		pools = append(pools, k8znerv1alpha1.WorkerPoolSpec{
+			Name:        pool.Name,
+			Count:       pool.Count,
+			Size:        pool.ServerType,
+			Autoscaling: autoscaling,
+			Location:    pool.Location,
+			Labels:      pool.NodeLabels,
+			Taints:      taints,
+		})
+	}
+	return pools
+}
+
+// buildMaintenanceSpec converts the maintenance windows from config into the CRD spec.
+func buildMaintenanceSpec(cfg *config.Config) *k8znerv1alpha1.MaintenanceSpec {
+	if cfg.Maintenance == nil {
+		return nil
+	}
+	windows := make([]k8znerv1alpha1.MaintenanceWindow, 0, len(cfg.Maintenance.Windows))
+	for _, window := range cfg.Maintenance.Windows {
+		windows = append(windows, k8znerv1alpha1.MaintenanceWindow{
+			Schedule: window.Schedule,
+			Duration: window.Duration,
+		})
+	}
+	return &k8znerv1alpha1.MaintenanceSpec{
+		Windows:               windows,
+		Timezone:              cfg.Maintenance.Timezone,
+		AllowEmergencyHealing: cfg.Maintenance.AllowEmergencyHealing,
+	}
+}
+
 // getBootstrapNode returns the bootstrap node info from the provisioning state.
 func getBootstrapNode(pCtx *provisioning.Context) (name string, serverID int64, ip string) {
 	if len(pCtx.State.ControlPlaneIPs) == 0 {
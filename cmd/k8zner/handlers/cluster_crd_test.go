@@ -8,6 +8,10 @@ import (
 	hcloudgo "github.com/hetznercloud/hcloud-go/v2/hcloud"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
 	"github.com/milankappen/k8zner/internal/config"
@@ -15,6 +19,23 @@ import (
 	"github.com/milankappen/k8zner/internal/provisioning"
 )
 
+// newTestCluster returns the K8znerCluster named name in the k8zner
+// namespace, with the spec apply builds from cfg. Without cfg the spec only
+// references the credentials Secret.
+func newTestCluster(name string, cfg *config.Config) *k8znerv1alpha1.K8znerCluster {
+	cluster := &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: k8znerNamespace},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			CredentialsRef: corev1.LocalObjectReference{Name: credentialsSecretName},
+		},
+	}
+	if cfg != nil {
+		cluster.Spec.Region = cfg.Location
+		updateClusterSpecFromConfig(cluster, cfg)
+	}
+	return cluster
+}
+
 func TestBuildK8znerCluster(t *testing.T) {
 	t.Parallel()
 
@@ -36,7 +57,11 @@ func TestBuildK8znerCluster(t *testing.T) {
 			{Name: "workers", Count: 2, ServerType: "cx22"},
 		},
 		Kubernetes: config.KubernetesConfig{Version: "1.30.0"},
-		Talos:      config.TalosConfig{Version: "1.7.0", SchematicID: "abc123"},
+		Talos: config.TalosConfig{
+			Version:     "1.7.0",
+			SchematicID: "abc123",
+			Machine:     config.TalosMachineConfig{ConfigApplyMode: "auto"},
+		},
 	}
 
 	infraInfo := &InfrastructureInfo{
@@ -63,6 +88,7 @@ func TestBuildK8znerCluster(t *testing.T) {
 	assert.Equal(t, "1.30.0", cluster.Spec.Kubernetes.Version)
 	assert.Equal(t, "1.7.0", cluster.Spec.Talos.Version)
 	assert.Equal(t, "abc123", cluster.Spec.Talos.SchematicID)
+	assert.Equal(t, "auto", cluster.Spec.Talos.ConfigApplyMode)
 	assert.Equal(t, credentialsSecretName, cluster.Spec.CredentialsRef.Name)
 
 	// Bootstrap state
@@ -357,6 +383,94 @@ func TestGetWorkerSize(t *testing.T) {
 	})
 }
 
+func TestBuildWorkerPoolSpecs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("converts the default pool", func(t *testing.T) {
+		t.Parallel()
+		cfg, err := config.ExpandSpec(&config.Spec{
+			Name: "test", Region: config.RegionFalkenstein, Mode: config.ModeDev,
+			Workers: config.WorkerSpec{Count: 2, Size: config.SizeCX33},
+		})
+		require.NoError(t, err)
+
+		pools := buildWorkerPoolSpecs(cfg)
+		require.Len(t, pools, 1)
+		assert.Equal(t, k8znerv1alpha1.WorkerPoolSpec{Name: "workers", Count: 2, Size: "cx33", Location: "fsn1"}, pools[0])
+	})
+
+	t.Run("converts named pools with labels and taints", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{
+			Workers: []config.WorkerNodePool{
+				{Name: "general", Count: 2, ServerType: "cx33", Location: "nbg1"},
+				{
+					Name: "gpu", Count: 1, ServerType: "cx53", Location: "hel1",
+					NodeLabels: map[string]string{"tier": "gpu"},
+					NodeTaints: []config.Taint{{Key: "gpu", Value: "true", Effect: "NoSchedule"}},
+				},
+			},
+		}
+
+		pools := buildWorkerPoolSpecs(cfg)
+		require.Len(t, pools, 2)
+		assert.Equal(t, k8znerv1alpha1.WorkerPoolSpec{Name: "general", Count: 2, Size: "cx33", Location: "nbg1"}, pools[0])
+		assert.Equal(t, "hel1", pools[1].Location)
+		assert.Equal(t, map[string]string{"tier": "gpu"}, pools[1].Labels)
+		require.Len(t, pools[1].Taints, 1)
+		assert.Equal(t, "gpu", pools[1].Taints[0].Key)
+		assert.Equal(t, "true", pools[1].Taints[0].Value)
+		assert.Equal(t, "NoSchedule", string(pools[1].Taints[0].Effect))
+		assert.Nil(t, pools[1].Autoscaling)
+	})
+
+	t.Run("converts autoscaling bounds", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{
+			Workers: []config.WorkerNodePool{{
+				Name: "general", Count: 2, ServerType: "cx33",
+				Autoscaling: &config.WorkerPoolAutoscaling{MinCount: 1, MaxCount: 8, ScaleDownDelay: "20m"},
+			}},
+		}
+
+		pools := buildWorkerPoolSpecs(cfg)
+		require.Len(t, pools, 1)
+		assert.Equal(t, &k8znerv1alpha1.WorkerPoolAutoscalingSpec{MinCount: 1, MaxCount: 8, ScaleDownDelay: "20m"}, pools[0].Autoscaling)
+	})
+}
+
+func TestBuildMaintenanceSpec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no maintenance windows", func(t *testing.T) {
+		t.Parallel()
+		assert.Nil(t, buildMaintenanceSpec(&config.Config{}))
+	})
+
+	t.Run("windows and policy", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{
+			Maintenance: &config.MaintenanceSpec{
+				Windows: []config.MaintenanceWindow{
+					{Schedule: "0 2 * * sat", Duration: "4h"},
+					{Schedule: "0 22 * * 1-5", Duration: "1h"},
+				},
+				Timezone:              "Europe/Berlin",
+				AllowEmergencyHealing: true,
+			},
+		}
+
+		spec := buildMaintenanceSpec(cfg)
+		require.NotNil(t, spec)
+		assert.Equal(t, []k8znerv1alpha1.MaintenanceWindow{
+			{Schedule: "0 2 * * sat", Duration: "4h"},
+			{Schedule: "0 22 * * 1-5", Duration: "1h"},
+		}, spec.Windows)
+		assert.Equal(t, "Europe/Berlin", spec.Timezone)
+		assert.True(t, spec.AllowEmergencyHealing)
+	})
+}
+
 func TestGetBootstrapNode(t *testing.T) {
 	t.Parallel()
 
@@ -554,3 +668,51 @@ func TestBuildInfraInfo(t *testing.T) {
 		assert.Equal(t, int64(0), info.LoadBalancerID) // Graceful - no LB info
 	})
 }
+
+func TestCreateOrReplaceCluster(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("creates a new cluster", func(t *testing.T) {
+		t.Parallel()
+		k8sClient := fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).Build()
+		cluster := &k8znerv1alpha1.K8znerCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: k8znerNamespace},
+			Spec:       k8znerv1alpha1.K8znerClusterSpec{Region: "fsn1"},
+		}
+
+		require.NoError(t, createOrReplaceCluster(ctx, k8sClient, cluster))
+
+		got := &k8znerv1alpha1.K8znerCluster{}
+		require.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), got))
+		assert.Equal(t, "fsn1", got.Spec.Region)
+		assert.Contains(t, got.Annotations[lastAppliedSpecAnnotation], `"region":"fsn1"`, "the stored spec is the baseline for drift checks")
+	})
+
+	t.Run("replaces the spec of a restored cluster", func(t *testing.T) {
+		t.Parallel()
+		restored := &k8znerv1alpha1.K8znerCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: k8znerNamespace, Labels: map[string]string{"restored": "true"}},
+			Spec: k8znerv1alpha1.K8znerClusterSpec{
+				Region:    "fsn1",
+				Bootstrap: &k8znerv1alpha1.BootstrapState{BootstrapNode: "test-cp-old"},
+			},
+		}
+		k8sClient := fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).WithObjects(restored).Build()
+
+		cluster := &k8znerv1alpha1.K8znerCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: k8znerNamespace},
+			Spec: k8znerv1alpha1.K8znerClusterSpec{
+				Region:    "fsn1",
+				Bootstrap: &k8znerv1alpha1.BootstrapState{BootstrapNode: "test-cp-new"},
+			},
+		}
+		require.NoError(t, createOrReplaceCluster(ctx, k8sClient, cluster))
+
+		got := &k8znerv1alpha1.K8znerCluster{}
+		require.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), got))
+		assert.Equal(t, "test-cp-new", got.Spec.Bootstrap.BootstrapNode)
+		assert.Equal(t, "true", got.Labels["restored"], "metadata of the restored resource is kept")
+		assert.Contains(t, got.Annotations[lastAppliedSpecAnnotation], "test-cp-new")
+	})
+}
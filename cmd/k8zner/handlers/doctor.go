@@ -3,13 +3,13 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/mattn/go-isatty"
-	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
@@ -82,16 +82,16 @@ func Doctor(ctx context.Context, configPath string, watch, jsonOutput bool) erro
 	}
 
 	// Pre-cluster mode: no kubeconfig
-	if _, err := os.Stat(kubeconfigPath); os.IsNotExist(err) {
+	kubecfg, err := loadRESTConfig()
+	if errors.Is(err, os.ErrNotExist) {
 		return doctorPreCluster(cfg, jsonOutput)
 	}
-
-	// Cluster mode: kubeconfig exists
-	kubecfg, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
 	if err != nil {
-		// Kubeconfig is corrupt or invalid — fall back to pre-cluster mode
+		// Kubeconfig is corrupt, invalid or cannot be decrypted — fall back to pre-cluster mode
 		return doctorPreCluster(cfg, jsonOutput)
 	}
+
+	// Cluster mode: kubeconfig exists
 	// Set a short timeout so doctor doesn't hang when cluster is unreachable
 	kubecfg.Timeout = 5 * time.Second
 
@@ -173,9 +173,13 @@ func doctorPreCluster(cfg *config.Config, jsonOutput bool) error {
 		pool := cfg.ControlPlane.NodePools[0]
 		fmt.Printf("    Control Planes: %d x %s\n", pool.Count, pool.ServerType)
 	}
-	if len(cfg.Workers) > 0 {
+	if len(cfg.Workers) == 1 {
 		pool := cfg.Workers[0]
 		fmt.Printf("    Workers:        %d x %s\n", pool.Count, pool.ServerType)
+	} else {
+		for _, pool := range cfg.Workers {
+			fmt.Printf("    Workers (%s): %d x %s\n", pool.Name, pool.Count, pool.ServerType)
+		}
 	}
 	fmt.Printf("    Kubernetes:     %s\n", cfg.Kubernetes.Version)
 	fmt.Printf("    Talos:          %s\n", cfg.Talos.Version)
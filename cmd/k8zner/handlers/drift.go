@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+)
+
+// lastAppliedSpecAnnotation holds the JSON of the spec as the API server
+// stored it after the last apply. Fields that differ from it were edited in
+// the cluster, e.g. with kubectl.
+const lastAppliedSpecAnnotation = "k8zner.io/last-applied-spec"
+
+// recordAppliedSpec stores the spec of cluster, as returned by the API server
+// with CRD defaults filled in, as the baseline for the next drift check.
+func recordAppliedSpec(ctx context.Context, k8sClient client.Client, cluster *k8znerv1alpha1.K8znerCluster) error {
+	data, err := json.Marshal(cluster.Spec)
+	if err != nil {
+		return fmt.Errorf("failed to encode cluster spec: %w", err)
+	}
+	patch := client.MergeFrom(cluster.DeepCopy())
+	if cluster.Annotations == nil {
+		cluster.Annotations = make(map[string]string)
+	}
+	cluster.Annotations[lastAppliedSpecAnnotation] = string(data)
+	if err := k8sClient.Patch(ctx, cluster, patch); err != nil {
+		return fmt.Errorf("failed to record the applied spec: %w", err)
+	}
+	return nil
+}
+
+// specDrift returns the fields that were edited in the live cluster since
+// the last apply and that desired would overwrite, with the live value in
+// From and the value of k8zner.yaml in To. Clusters without a recorded
+// baseline have no drift.
+func specDrift(live, desired *k8znerv1alpha1.K8znerCluster) ([]planFieldChange, error) {
+	data, ok := live.Annotations[lastAppliedSpecAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var baseline k8znerv1alpha1.K8znerClusterSpec
+	if err := json.Unmarshal([]byte(data), &baseline); err != nil {
+		return nil, fmt.Errorf("failed to decode the %s annotation: %w", lastAppliedSpecAnnotation, err)
+	}
+
+	edited, err := diffClusterSpecs(baseline, live.Spec)
+	if err != nil {
+		return nil, err
+	}
+	overwritten, err := diffClusterSpecs(live.Spec, desired.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []planFieldChange
+	for _, change := range overwritten {
+		for _, edit := range edited {
+			if fieldsOverlap(change.Field, edit.Field) {
+				drift = append(drift, change)
+				break
+			}
+		}
+	}
+	return drift, nil
+}
+
+// fieldsOverlap reports whether two field paths are the same or one
+// contains the other, like spec.workerPools[name=gpu] and
+// spec.workerPools[name=gpu].count.
+func fieldsOverlap(a, b string) bool {
+	return a == b || containsField(a, b) || containsField(b, a)
+}
+
+func containsField(parent, field string) bool {
+	rest, ok := strings.CutPrefix(field, parent)
+	return ok && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "["))
+}
+
+// driftWarnings describes the edits of the live cluster that apply reverts.
+func driftWarnings(drift []planFieldChange) []string {
+	var warnings []string
+	for _, change := range drift {
+		live := "removed in the cluster"
+		if change.From != nil {
+			live = "changed in the cluster to " + formatPlanValue(change.From)
+		}
+		local := "removes it"
+		if change.To != nil {
+			local = "sets it to " + formatPlanValue(change.To)
+		}
+		warnings = append(warnings, fmt.Sprintf("%s was %s, k8zner.yaml %s (run 'k8zner export' to keep it)", change.Field, live, local))
+	}
+	return warnings
+}
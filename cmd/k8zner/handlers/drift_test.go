@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+)
+
+func TestRecordAppliedSpec(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cluster := newTestCluster("test", newPlanTestLiveConfig())
+	k8sClient := fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).WithObjects(cluster).Build()
+
+	require.NoError(t, recordAppliedSpec(ctx, k8sClient, cluster))
+
+	got := &k8znerv1alpha1.K8znerCluster{}
+	require.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), got))
+	var recorded k8znerv1alpha1.K8znerClusterSpec
+	require.NoError(t, json.Unmarshal([]byte(got.Annotations[lastAppliedSpecAnnotation]), &recorded))
+	assert.Equal(t, cluster.Spec, recorded)
+	assert.NotEmpty(t, got.Annotations["k8zner.io/last-applied"], "other annotations are kept")
+}
+
+func TestSpecDrift(t *testing.T) {
+	t.Parallel()
+	// The plan test cluster with its spec recorded as applied
+	applied := newTestCluster("test", newPlanTestLiveConfig())
+	data, err := json.Marshal(applied.Spec)
+	require.NoError(t, err)
+	applied.Annotations = map[string]string{lastAppliedSpecAnnotation: string(data)}
+
+	t.Run("edited field overwritten by the config", func(t *testing.T) {
+		t.Parallel()
+		live := applied.DeepCopy()
+		live.Spec.WorkerPools[0].Count = 5 // kubectl edit
+		desired := live.DeepCopy()
+		updateClusterSpecFromConfig(desired, newPlanTestConfig())
+
+		drift, err := specDrift(live, desired)
+		require.NoError(t, err)
+		assert.Equal(t, []planFieldChange{
+			{Field: "spec.workerPools[name=default].count", From: float64(5), To: float64(3)},
+		}, drift, "the Talos upgrade and worker size change come from the config, not from an edit")
+	})
+
+	t.Run("pool added in the cluster", func(t *testing.T) {
+		t.Parallel()
+		live := applied.DeepCopy()
+		live.Spec.WorkerPools = append(live.Spec.WorkerPools, k8znerv1alpha1.WorkerPoolSpec{Name: "gpu", Count: 1, Size: "cx53"})
+		desired := live.DeepCopy()
+		updateClusterSpecFromConfig(desired, newPlanTestConfig())
+
+		drift, err := specDrift(live, desired)
+		require.NoError(t, err)
+		require.Len(t, drift, 1)
+		assert.Equal(t, "spec.workerPools[name=gpu]", drift[0].Field)
+		assert.Nil(t, drift[0].To)
+	})
+
+	t.Run("edited field the config leaves alone", func(t *testing.T) {
+		t.Parallel()
+		live := applied.DeepCopy()
+		live.Spec.Paused = true
+		desired := live.DeepCopy()
+		updateClusterSpecFromConfig(desired, newPlanTestConfig())
+
+		drift, err := specDrift(live, desired)
+		require.NoError(t, err)
+		assert.Empty(t, drift)
+	})
+
+	t.Run("no baseline", func(t *testing.T) {
+		t.Parallel()
+		live := newTestCluster("test", newPlanTestLiveConfig())
+		live.Spec.WorkerPools[0].Count = 5
+		desired := live.DeepCopy()
+		updateClusterSpecFromConfig(desired, newPlanTestConfig())
+
+		drift, err := specDrift(live, desired)
+		require.NoError(t, err)
+		assert.Empty(t, drift)
+	})
+
+	t.Run("broken baseline", func(t *testing.T) {
+		t.Parallel()
+		live := newTestCluster("test", newPlanTestLiveConfig())
+		live.Annotations = map[string]string{lastAppliedSpecAnnotation: "{"}
+
+		_, err := specDrift(live, live.DeepCopy())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), lastAppliedSpecAnnotation)
+	})
+}
+
+func TestFieldsOverlap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"spec.workers.count", "spec.workers.count", true},
+		{"spec.workerPools[name=gpu]", "spec.workerPools[name=gpu].count", true},
+		{"spec.workerPools[name=gpu].labels.tier", "spec.workerPools[name=gpu]", true},
+		{"spec.workerPools", "spec.workerPools[name=gpu]", true},
+		{"spec.workers.count", "spec.workers.size", false},
+		{"spec.workerPools[name=gpu]", "spec.workerPools[name=gpu2]", false},
+		{"spec.talos.version", "spec.talos.versionSkew", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, fieldsOverlap(tt.a, tt.b), "%s / %s", tt.a, tt.b)
+	}
+}
+
+func TestDriftWarnings(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{
+		"spec.workers.count was changed in the cluster to 5, k8zner.yaml sets it to 3 (run 'k8zner export' to keep it)",
+		"spec.workerPools[name=gpu] was changed in the cluster to {\"count\":1}, k8zner.yaml removes it (run 'k8zner export' to keep it)",
+		"spec.paused was removed in the cluster, k8zner.yaml sets it to true (run 'k8zner export' to keep it)",
+	}, driftWarnings([]planFieldChange{
+		{Field: "spec.workers.count", From: float64(5), To: float64(3)},
+		{Field: "spec.workerPools[name=gpu]", From: map[string]any{"count": float64(1)}},
+		{Field: "spec.paused", To: true},
+	}))
+	assert.Empty(t, driftWarnings(nil))
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+)
+
+// Export renders the spec of a running cluster as k8zner.yaml, so edits made
+// to the K8znerCluster with kubectl survive the next apply. The cluster is
+// the one named by clusterName, or by the config file when clusterName is
+// empty. Settings that only live in k8zner.yaml (state backend, secrets
+// encryption, certificate email) are taken from the config file. Without
+// outputPath, the YAML is printed to stdout.
+func Export(ctx context.Context, configPath, clusterName, outputPath string) error {
+	local, err := loadLocalSpec(configPath)
+	if err != nil {
+		return err
+	}
+	if clusterName == "" {
+		if local == nil {
+			return fmt.Errorf("no config file found, pass the cluster name with --name")
+		}
+		clusterName = local.Name
+	}
+
+	kubeconfig, err := localFiles.ReadFile(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	k8sClient, err := newClusterClient(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	cluster := &k8znerv1alpha1.K8znerCluster{}
+	err = k8sClient.Get(ctx, client.ObjectKey{Namespace: k8znerNamespace, Name: clusterName}, cluster)
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("cluster %s has no K8znerCluster in %s, only operator-managed clusters can be exported", clusterName, k8znerNamespace)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get K8znerCluster: %w", err)
+	}
+
+	spec := specFromCluster(cluster)
+	if local != nil && local.Name == clusterName {
+		spec.CertEmail = local.CertEmail
+		spec.State = local.State
+		spec.SecretsEncryption = local.SecretsEncryption
+	}
+	notes, err := exportNotes(cluster, spec)
+	if err != nil {
+		return err
+	}
+
+	// Messages go to stderr when stdout carries the YAML
+	var out io.Writer = os.Stdout
+	if outputPath == "" {
+		data, err := yaml.Marshal(spec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal config: %w", err)
+		}
+		fmt.Print(string(data))
+		out = os.Stderr
+	} else {
+		outputPath = workspacePath(outputPath)
+		if err := writeV2Config(spec, outputPath); err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+		fmt.Fprintf(out, "Wrote %s from K8znerCluster %s.\n", outputPath, clusterName)
+	}
+
+	if len(notes) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "k8zner.yaml cannot express these live values, apply would still change them:")
+		for _, note := range notes {
+			fmt.Fprintf(out, "  - %s\n", note)
+		}
+	}
+	return nil
+}
+
+// loadLocalSpec loads the config file without validation, or returns nil
+// when no configPath is given and none is found.
+func loadLocalSpec(configPath string) (*config.Spec, error) {
+	if configPath == "" {
+		path, err := findV2ConfigFile()
+		if err != nil {
+			return nil, nil
+		}
+		configPath = path
+	}
+	configPath = workspacePath(configPath)
+	spec, err := config.LoadSpecWithoutValidation(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config %s: %w", configPath, err)
+	}
+	return spec, nil
+}
+
+// specFromCluster converts a K8znerCluster back into k8zner.yaml. Apply
+// builds the same spec from the result, except for the fields exportNotes
+// reports.
+func specFromCluster(cluster *k8znerv1alpha1.K8znerCluster) *config.Spec {
+	s := &cluster.Spec
+	spec := &config.Spec{
+		Name:   cluster.Name,
+		Region: config.Region(s.Region),
+		Mode:   config.ModeDev,
+		Domain: s.Domain,
+		Backup: s.Backup != nil && s.Backup.Enabled,
+	}
+	if s.ControlPlanes.Count > 1 {
+		spec.Mode = config.ModeHA
+	}
+	if size := config.ServerSize(s.ControlPlanes.Size); size != "" && size.Normalize() != config.SizeCX23 {
+		spec.ControlPlane = &config.ControlPlaneSpec{Size: size}
+	}
+	if s.Talos.ConfigApplyMode != "auto" {
+		spec.ConfigApplyMode = s.Talos.ConfigApplyMode
+	}
+	if s.Addons != nil {
+		spec.Monitoring = s.Addons.Monitoring
+		spec.ArgoSubdomain = s.Addons.ArgoSubdomain
+		spec.GrafanaSubdomain = s.Addons.GrafanaSubdomain
+	}
+
+	switch {
+	case len(s.WorkerPools) == 1 && isDefaultWorkerPool(s.WorkerPools[0], s.Region):
+		pool := s.WorkerPools[0]
+		spec.Workers = config.WorkerSpec{Count: pool.Count, Size: config.ServerSize(pool.Size)}
+	case len(s.WorkerPools) > 0:
+		for _, pool := range s.WorkerPools {
+			spec.WorkerPools = append(spec.WorkerPools, workerPoolFromCluster(pool, s.Region))
+		}
+	default:
+		spec.Workers = config.WorkerSpec{Count: s.Workers.Count, Size: config.ServerSize(s.Workers.Size)}
+	}
+
+	if m := s.Maintenance; m != nil {
+		spec.Maintenance = &config.MaintenanceSpec{
+			Timezone:              m.Timezone,
+			AllowEmergencyHealing: m.AllowEmergencyHealing,
+		}
+		for _, window := range m.Windows {
+			spec.Maintenance.Windows = append(spec.Maintenance.Windows, config.MaintenanceWindow{
+				Schedule: window.Schedule,
+				Duration: window.Duration,
+			})
+		}
+	}
+	return spec
+}
+
+// isDefaultWorkerPool reports whether pool is the one apply builds from the
+// workers setting of k8zner.yaml.
+func isDefaultWorkerPool(pool k8znerv1alpha1.WorkerPoolSpec, region string) bool {
+	return pool.Name == k8znerv1alpha1.DefaultWorkerPoolName && pool.Autoscaling == nil &&
+		(pool.Location == "" || pool.Location == region) &&
+		len(pool.Labels) == 0 && len(pool.Taints) == 0
+}
+
+func workerPoolFromCluster(pool k8znerv1alpha1.WorkerPoolSpec, region string) config.WorkerPoolSpec {
+	out := config.WorkerPoolSpec{
+		Name:   pool.Name,
+		Count:  pool.Count,
+		Size:   config.ServerSize(pool.Size),
+		Labels: pool.Labels,
+	}
+	if pool.Location != region {
+		out.Location = config.Region(pool.Location)
+	}
+	if as := pool.Autoscaling; as != nil {
+		out.Autoscaling = &config.WorkerPoolAutoscaling{
+			MinCount:                      as.MinCount,
+			MaxCount:                      as.MaxCount,
+			ScaleDownUtilizationThreshold: as.ScaleDownUtilizationThreshold,
+			ScaleDownDelay:                as.ScaleDownDelay,
+		}
+	}
+	for _, taint := range pool.Taints {
+		out.Taints = append(out.Taints, config.Taint{
+			Key:    taint.Key,
+			Value:  taint.Value,
+			Effect: string(taint.Effect),
+		})
+	}
+	return out
+}
+
+// exportNotes applies spec to a copy of the cluster the way apply does and
+// lists the fields that would still change, such as versions k8zner.yaml
+// does not pin or addons it always enables.
+func exportNotes(cluster *k8znerv1alpha1.K8znerCluster, spec *config.Spec) ([]string, error) {
+	cfg, err := expandV2Config(spec)
+	if err != nil {
+		return nil, err
+	}
+	desired := cluster.DeepCopy()
+	updateClusterSpecFromConfig(desired, cfg)
+	changes, err := diffClusterSpecs(cluster.Spec, desired.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []string
+	for _, change := range changes {
+		// The operator ignores spec.workers while spec.workerPools is set
+		if len(cluster.Spec.WorkerPools) > 0 && strings.HasPrefix(change.Field, "spec.workers.") {
+			continue
+		}
+		live := "unset in the cluster"
+		if change.From != nil {
+			live = formatPlanValue(change.From) + " in the cluster"
+		}
+		apply := "apply removes it"
+		if change.To != nil {
+			apply = "apply sets " + formatPlanValue(change.To)
+		}
+		notes = append(notes, fmt.Sprintf("%s: %s, %s", change.Field, live, apply))
+	}
+	return notes, nil
+}
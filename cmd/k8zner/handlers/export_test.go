@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+)
+
+func TestSpecFromCluster(t *testing.T) {
+	t.Parallel()
+
+	t.Run("worker pools", func(t *testing.T) {
+		t.Parallel()
+		spec := &config.Spec{
+			Name:            "prod",
+			Region:          config.RegionFalkenstein,
+			Mode:            config.ModeHA,
+			ControlPlane:    &config.ControlPlaneSpec{Size: config.SizeCPX32},
+			ConfigApplyMode: "no_reboot",
+			Monitoring:      true,
+			WorkerPools: []config.WorkerPoolSpec{
+				{Name: "workers", Count: 2, Size: config.SizeCX33},
+				{
+					Name:     "db",
+					Count:    1,
+					Size:     config.SizeCX43,
+					Location: config.RegionNuremberg,
+					Labels:   map[string]string{"tier": "db"},
+					Taints:   []config.Taint{{Key: "dedicated", Value: "db", Effect: "NoSchedule"}},
+				},
+				{Name: "burst", Count: 1, Size: config.SizeCPX32, Autoscaling: &config.WorkerPoolAutoscaling{MinCount: 1, MaxCount: 4, ScaleDownDelay: "20m"}},
+			},
+			Maintenance: &config.MaintenanceSpec{
+				Windows:  []config.MaintenanceWindow{{Schedule: "0 2 * * sat", Duration: "4h"}},
+				Timezone: "Europe/Berlin",
+			},
+		}
+
+		cfg, err := config.ExpandSpec(spec)
+		require.NoError(t, err)
+		assert.Equal(t, spec, specFromCluster(newTestCluster(spec.Name, cfg)))
+	})
+
+	t.Run("default worker pool", func(t *testing.T) {
+		t.Parallel()
+		spec := &config.Spec{
+			Name:    "dev",
+			Region:  config.RegionHelsinki,
+			Mode:    config.ModeDev,
+			Workers: config.WorkerSpec{Count: 2, Size: config.SizeCX33},
+		}
+
+		cfg, err := config.ExpandSpec(spec)
+		require.NoError(t, err)
+		assert.Equal(t, spec, specFromCluster(newTestCluster(spec.Name, cfg)))
+	})
+
+	t.Run("fields only the cluster has", func(t *testing.T) {
+		t.Parallel()
+		spec := &config.Spec{
+			Name:    "dev",
+			Region:  config.RegionHelsinki,
+			Mode:    config.ModeDev,
+			Workers: config.WorkerSpec{Count: 1, Size: config.SizeCX23},
+		}
+		cfg, err := config.ExpandSpec(spec)
+		require.NoError(t, err)
+		cluster := newTestCluster(spec.Name, cfg)
+		cluster.Spec.Domain = "example.com"
+		cluster.Spec.Addons.ArgoSubdomain = "cd"
+		cluster.Spec.Backup = &k8znerv1alpha1.BackupSpec{Enabled: true}
+
+		exported := specFromCluster(cluster)
+		assert.Equal(t, "example.com", exported.Domain)
+		assert.Equal(t, "cd", exported.ArgoSubdomain)
+		assert.True(t, exported.Backup)
+	})
+}
+
+func TestExportNotes(t *testing.T) {
+	t.Parallel()
+	spec := &config.Spec{
+		Name:    "dev",
+		Region:  config.RegionHelsinki,
+		Mode:    config.ModeDev,
+		Workers: config.WorkerSpec{Count: 2, Size: config.SizeCX33},
+	}
+	cfg, err := config.ExpandSpec(spec)
+	require.NoError(t, err)
+	cluster := newTestCluster(spec.Name, cfg)
+
+	notes, err := exportNotes(cluster, specFromCluster(cluster))
+	require.NoError(t, err)
+	assert.Empty(t, notes, "a cluster apply built round-trips")
+
+	cluster.Spec.Talos.Version = "v1.8.0"
+	cluster.Spec.Addons.ArgoCD = false
+	cluster.Spec.WorkerPools[0].Count = 4
+	notes, err = exportNotes(cluster, specFromCluster(cluster))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"spec.addons.argocd: unset in the cluster, apply sets true",
+		"spec.talos.version: v1.8.0 in the cluster, apply sets " + config.DefaultVersionMatrix().Talos,
+	}, notes, "spec.workers follows the pools and is not reported")
+}
+
+// Serial: swaps the package-level newClusterClient and changes directory.
+func TestExport(t *testing.T) {
+	origClient := newClusterClient
+	t.Cleanup(func() { newClusterClient = origClient })
+	t.Chdir(t.TempDir())
+
+	spec := &config.Spec{
+		Name:    "prod",
+		Region:  config.RegionFalkenstein,
+		Mode:    config.ModeHA,
+		Workers: config.WorkerSpec{Count: 2, Size: config.SizeCX33},
+	}
+	cfg, err := config.ExpandSpec(spec)
+	require.NoError(t, err)
+	cluster := newTestCluster(spec.Name, cfg)
+	cluster.Spec.WorkerPools[0].Count = 4 // kubectl edit
+	newClusterClient = func([]byte) (client.Client, error) {
+		return fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).WithObjects(cluster).Build(), nil
+	}
+
+	local := &config.Spec{
+		Name:      "prod",
+		Region:    config.RegionFalkenstein,
+		Mode:      config.ModeHA,
+		Workers:   config.WorkerSpec{Count: 2, Size: config.SizeCX33},
+		CertEmail: "ops@example.com",
+		State:     &config.StateSpec{Backend: config.StateBackendS3},
+	}
+	require.NoError(t, config.SaveSpec(local, config.DefaultConfigFilename))
+
+	err = Export(context.Background(), "", "", "")
+	require.Error(t, err, "no kubeconfig")
+	assert.Contains(t, err.Error(), "failed to read kubeconfig")
+
+	require.NoError(t, writeLocalFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n")))
+	require.NoError(t, Export(context.Background(), "", "", config.DefaultConfigFilename))
+
+	exported, err := config.LoadSpecWithoutValidation(config.DefaultConfigFilename)
+	require.NoError(t, err)
+	assert.Equal(t, 4, exported.Workers.Count)
+	assert.Equal(t, "ops@example.com", exported.CertEmail, "settings outside the cluster are kept")
+	assert.Equal(t, config.StateBackendS3, exported.State.Backend)
+
+	err = Export(context.Background(), "", "staging", "staging.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only operator-managed clusters can be exported")
+	_, statErr := os.Stat("staging.yaml")
+	assert.True(t, os.IsNotExist(statErr))
+}
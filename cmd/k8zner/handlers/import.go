@@ -0,0 +1,872 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+	operatorprov "github.com/milankappen/k8zner/internal/operator/provisioning"
+	hcloudInternal "github.com/milankappen/k8zner/internal/platform/hcloud"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+	"github.com/milankappen/k8zner/internal/util/labels"
+	"github.com/milankappen/k8zner/internal/util/naming"
+)
+
+// nodeRoleControlPlane is the Kubernetes node label of control plane nodes.
+const nodeRoleControlPlane = "node-role.kubernetes.io/control-plane"
+
+var (
+	// readExistingCluster reads secrets and settings from a running control plane.
+	readExistingCluster = talos.ReadExistingCluster
+
+	// installImportOperator installs the operator into an imported cluster.
+	installImportOperator = installOperatorOnly
+)
+
+// importServer is a Hetzner server matched to a node of the imported cluster.
+type importServer struct {
+	server *hcloud.Server
+	node   *corev1.Node
+	role   string
+	pool   string
+}
+
+// importDiscovery is the infrastructure of a cluster k8zner did not create.
+type importDiscovery struct {
+	controlPlanes []importServer
+	workers       []importServer
+	network       *hcloud.Network
+	firewall      *hcloud.Firewall
+	loadBalancer  *hcloud.LoadBalancer
+	warnings      []string
+}
+
+// importChange renames or relabels one Hetzner resource.
+type importChange struct {
+	kind      string
+	id        int64
+	name      string
+	newName   string
+	oldLabels map[string]string
+	labels    map[string]string
+	selector  string
+}
+
+// Import adopts a Talos cluster on Hetzner Cloud that was created without
+// k8zner. Its servers, network, firewall and API load balancer are found
+// through the kubeconfig and HCLOUD_TOKEN, relabeled and renamed the way
+// k8zner names them, and the operator is installed with a K8znerCluster
+// describing the cluster as it runs. The Talos secrets are recovered from a
+// control plane's machine config.
+//
+// The operator keeps the machine configs the nodes run until the
+// PauseMachineConfigSyncAnnotation is removed from the K8znerCluster. Import
+// refuses clusters whose CNI, kube-proxy, cloud provider or cluster name
+// settings differ from the configs k8zner generates, unless allowConfigChanges
+// is set. With dryRun, the changes and the machine config diff are only printed.
+func Import(ctx context.Context, clusterName, kubeconfigFile, talosconfigFile string, allowConfigChanges, dryRun bool) error {
+	token := os.Getenv("HCLOUD_TOKEN")
+	if token == "" {
+		return fmt.Errorf("HCLOUD_TOKEN environment variable is required")
+	}
+
+	kubeconfig, err := os.ReadFile(kubeconfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	talosconfig, err := os.ReadFile(talosconfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read talosconfig: %w", err)
+	}
+	k8sClient, err := newClusterClient(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return importCluster(ctx, k8sClient, newInfraClient(token), clusterName, token, kubeconfig, talosconfig, allowConfigChanges, dryRun)
+}
+
+func importCluster(ctx context.Context, k8sClient client.Client, infra hcloudInternal.InfrastructureManager, clusterName, token string, kubeconfig, talosconfig []byte, allowConfigChanges, dryRun bool) error {
+	err := k8sClient.Get(ctx, client.ObjectKey{Namespace: k8znerNamespace, Name: clusterName}, &k8znerv1alpha1.K8znerCluster{})
+	if err == nil {
+		return fmt.Errorf("cluster %s is already managed by k8zner", clusterName)
+	}
+	if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("failed to check for an existing K8znerCluster: %w", err)
+	}
+
+	log.Println("Discovering servers, network, firewall and load balancers...")
+	disc, err := discoverImport(ctx, k8sClient, infra)
+	if err != nil {
+		return err
+	}
+
+	first := disc.controlPlanes[0].server
+	log.Printf("Reading the Talos machine config of %s...", first.Name)
+	existing, err := readExistingCluster(ctx, talosconfig, hcloudInternal.ServerIPv4(first))
+	if err != nil {
+		return fmt.Errorf("failed to read the Talos machine config: %w", err)
+	}
+	conflicts := importConfigConflicts(clusterName, existing)
+	if len(conflicts) > 0 && !dryRun && !allowConfigChanges {
+		return fmt.Errorf("the machine configs k8zner generates would change how the cluster runs: %s; "+
+			"inspect the difference with --dry-run and pass --allow-config-changes to import anyway",
+			strings.Join(conflicts, "; "))
+	}
+
+	secretsData, err := talos.MarshalSecrets(existing.Secrets)
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{secretsFile: secretsData, talosConfigPath: talosconfig, kubeconfigPath: kubeconfig}
+	for _, name := range stateFiles {
+		current, err := localFiles.ReadFile(name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(current, files[name]) {
+			return fmt.Errorf("%s of another cluster already exists, run import from an empty directory or workspace", localFiles.Path(name))
+		}
+	}
+
+	cluster := buildImportedCluster(clusterName, disc, existing)
+	changes := importChanges(clusterName, disc)
+
+	if dryRun {
+		configChanges, err := importMachineConfigDiff(cluster, secretsData, existing.MachineConfig)
+		if err != nil {
+			return err
+		}
+		out, err := renderImportPlan(clusterName, changes, cluster, disc.warnings, conflicts, configChanges)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+		return nil
+	}
+
+	for _, warning := range disc.warnings {
+		log.Printf("Warning: %s", warning)
+	}
+	for _, conflict := range conflicts {
+		log.Printf("Warning: %s", conflict)
+	}
+	if err := applyImportChanges(ctx, infra, changes); err != nil {
+		return err
+	}
+
+	for _, name := range stateFiles {
+		if err := writeLocalFile(name, files[name]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	cfg := &config.Config{
+		ClusterName: clusterName,
+		HCloudToken: token,
+		ControlPlane: config.ControlPlaneConfig{NodePools: []config.ControlPlaneNodePool{{
+			Count: len(disc.controlPlanes),
+		}}},
+	}
+	cfg.Addons.Operator.Enabled = true
+
+	if err := ensureNamespace(ctx, k8sClient); err != nil {
+		return fmt.Errorf("failed to ensure namespace: %w", err)
+	}
+	if err := createCredentialsSecret(ctx, k8sClient, cfg, token); err != nil {
+		return fmt.Errorf("failed to create credentials secret: %w", err)
+	}
+
+	log.Println("Installing the k8zner operator...")
+	if err := installImportOperator(ctx, cfg, kubeconfig, disc.network.ID); err != nil {
+		return fmt.Errorf("failed to install operator: %w", err)
+	}
+
+	if err := createOrReplaceCluster(ctx, k8sClient, cluster); err != nil {
+		return err
+	}
+	if err := updateClusterStatus(ctx, k8sClient, cluster); err != nil {
+		return err
+	}
+
+	log.Printf("Cluster %s is now managed by the k8zner operator", clusterName)
+	log.Printf("The nodes keep their machine configs until you resume the sync with:")
+	log.Printf("  kubectl annotate k8znercluster %s -n %s %s-", clusterName, k8znerNamespace, k8znerv1alpha1.PauseMachineConfigSyncAnnotation)
+	return nil
+}
+
+// discoverImport matches the Kubernetes nodes to Hetzner servers and finds the
+// network, firewall and API load balancer the control planes use.
+func discoverImport(ctx context.Context, k8sClient client.Client, infra hcloudInternal.InfrastructureManager) (*importDiscovery, error) {
+	nodes := &corev1.NodeList{}
+	if err := k8sClient.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	servers, err := infra.GetServersByLabel(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	disc := &importDiscovery{}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if !strings.HasPrefix(node.Status.NodeInfo.OSImage, "Talos") {
+			return nil, fmt.Errorf("node %s does not run Talos Linux (%s)", node.Name, node.Status.NodeInfo.OSImage)
+		}
+		srv := matchNodeServer(node, servers)
+		if srv == nil {
+			disc.warnings = append(disc.warnings, fmt.Sprintf("node %s matches no Hetzner server and is left out", node.Name))
+			continue
+		}
+		if _, ok := node.Labels[nodeRoleControlPlane]; ok {
+			disc.controlPlanes = append(disc.controlPlanes, importServer{server: srv, node: node, role: labels.RoleControlPlane, pool: labels.RoleControlPlane})
+		} else {
+			disc.workers = append(disc.workers, importServer{server: srv, node: node, role: labels.RoleWorker})
+		}
+	}
+	if len(disc.controlPlanes) == 0 {
+		return nil, errors.New("no control plane node matches a server, is HCLOUD_TOKEN for the cluster's project?")
+	}
+	sortImportServers(disc.controlPlanes)
+	sortImportServers(disc.workers)
+	assignImportWorkerPools(disc.workers)
+
+	if err := discoverImportNetwork(ctx, infra, disc); err != nil {
+		return nil, err
+	}
+	if err := discoverImportFirewall(ctx, infra, disc); err != nil {
+		return nil, err
+	}
+	if err := discoverImportLoadBalancer(ctx, infra, disc); err != nil {
+		return nil, err
+	}
+	return disc, nil
+}
+
+// matchNodeServer finds the server of a node by its hcloud provider ID, its
+// name, or one of its addresses.
+func matchNodeServer(node *corev1.Node, servers []*hcloud.Server) *hcloud.Server {
+	var id int64
+	if _, err := fmt.Sscanf(node.Spec.ProviderID, "hcloud://%d", &id); err == nil {
+		for _, srv := range servers {
+			if srv.ID == id {
+				return srv
+			}
+		}
+	}
+	for _, srv := range servers {
+		if srv.Name == node.Name {
+			return srv
+		}
+	}
+	for _, addr := range node.Status.Addresses {
+		if addr.Type != corev1.NodeInternalIP && addr.Type != corev1.NodeExternalIP {
+			continue
+		}
+		for _, srv := range servers {
+			if hcloudInternal.ServerIPv4(srv) == addr.Address {
+				return srv
+			}
+			for _, pn := range srv.PrivateNet {
+				if pn.IP != nil && pn.IP.String() == addr.Address {
+					return srv
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func sortImportServers(servers []importServer) {
+	sort.Slice(servers, func(i, j int) bool { return servers[i].server.Name < servers[j].server.Name })
+}
+
+// assignImportWorkerPools puts workers of one server type into the default
+// pool, and workers of several types into one pool per type.
+func assignImportWorkerPools(workers []importServer) {
+	types := make(map[string]bool)
+	for _, w := range workers {
+		types[importServerType(w.server)] = true
+	}
+	for i := range workers {
+		workers[i].pool = k8znerv1alpha1.DefaultWorkerPoolName
+		if len(types) > 1 {
+			workers[i].pool = k8znerv1alpha1.DefaultWorkerPoolName + "-" + importServerType(workers[i].server)
+		}
+	}
+}
+
+func discoverImportNetwork(ctx context.Context, infra hcloudInternal.InfrastructureManager, disc *importDiscovery) error {
+	first := disc.controlPlanes[0].server
+	if len(first.PrivateNet) == 0 || first.PrivateNet[0].Network == nil {
+		return fmt.Errorf("control plane %s is not attached to a private network, which k8zner requires", first.Name)
+	}
+	networkID := first.PrivateNet[0].Network.ID
+
+	network, err := infra.GetNetwork(ctx, strconv.FormatInt(networkID, 10))
+	if err != nil {
+		return fmt.Errorf("failed to get network %d: %w", networkID, err)
+	}
+	if network == nil {
+		return fmt.Errorf("network %d not found", networkID)
+	}
+	disc.network = network
+
+	for _, s := range append(disc.controlPlanes[1:], disc.workers...) {
+		attached := false
+		for _, pn := range s.server.PrivateNet {
+			attached = attached || (pn.Network != nil && pn.Network.ID == networkID)
+		}
+		if !attached {
+			disc.warnings = append(disc.warnings, fmt.Sprintf("server %s is not attached to network %s", s.server.Name, network.Name))
+		}
+	}
+	return nil
+}
+
+func discoverImportFirewall(ctx context.Context, infra hcloudInternal.InfrastructureManager, disc *importDiscovery) error {
+	first := disc.controlPlanes[0].server
+	if len(first.PublicNet.Firewalls) == 0 {
+		disc.warnings = append(disc.warnings, "no firewall is attached to the control planes, spec.firewall is disabled")
+		return nil
+	}
+	firewallID := first.PublicNet.Firewalls[0].Firewall.ID
+	if len(first.PublicNet.Firewalls) > 1 {
+		disc.warnings = append(disc.warnings, fmt.Sprintf("%s has several firewalls, only firewall %d is adopted", first.Name, firewallID))
+	}
+
+	fw, err := infra.GetFirewall(ctx, strconv.FormatInt(firewallID, 10))
+	if err != nil {
+		return fmt.Errorf("failed to get firewall %d: %w", firewallID, err)
+	}
+	disc.firewall = fw
+	return nil
+}
+
+// discoverImportLoadBalancer finds the load balancer serving the Kubernetes API
+// in front of the control planes. Other load balancers, such as the ones the
+// cloud controller manager creates for Services, are left alone.
+func discoverImportLoadBalancer(ctx context.Context, infra hcloudInternal.InfrastructureManager, disc *importDiscovery) error {
+	seen := make(map[int64]bool)
+	for _, cp := range disc.controlPlanes {
+		for _, ref := range cp.server.LoadBalancers {
+			if ref == nil || seen[ref.ID] {
+				continue
+			}
+			seen[ref.ID] = true
+
+			lb, err := infra.GetLoadBalancer(ctx, strconv.FormatInt(ref.ID, 10))
+			if err != nil {
+				return fmt.Errorf("failed to get load balancer %d: %w", ref.ID, err)
+			}
+			if lb == nil {
+				continue
+			}
+			for _, svc := range lb.Services {
+				if svc.ListenPort == config.KubeAPIPort {
+					disc.loadBalancer = lb
+					return nil
+				}
+			}
+		}
+	}
+	disc.warnings = append(disc.warnings, fmt.Sprintf(
+		"no load balancer serves port %d in front of the control planes, k8zner expects one", config.KubeAPIPort))
+	return nil
+}
+
+// buildImportedCluster describes the discovered cluster as a K8znerCluster
+// whose spec matches what runs. The nodes keep running machine configs
+// k8zner did not generate, so the cluster is annotated to pause the machine
+// config sync until the user opts in to the generated ones.
+func buildImportedCluster(clusterName string, disc *importDiscovery, existing *talos.ExistingCluster) *k8znerv1alpha1.K8znerCluster {
+	first := disc.controlPlanes[0]
+	now := metav1.Now()
+
+	cpSize := importServerType(first.server)
+	for _, cp := range disc.controlPlanes[1:] {
+		if t := importServerType(cp.server); t != cpSize {
+			disc.warnings = append(disc.warnings, fmt.Sprintf(
+				"control plane %s is a %s, spec.controlPlanes.size is %s; the operator will replace it", cp.server.Name, t, cpSize))
+		}
+	}
+
+	spec := k8znerv1alpha1.K8znerClusterSpec{
+		Region: importServerLocation(first.server),
+		ControlPlanes: k8znerv1alpha1.ControlPlaneSpec{
+			Count: len(disc.controlPlanes),
+			Size:  cpSize,
+		},
+		Network: k8znerv1alpha1.NetworkSpec{
+			PodCIDR:     existing.PodCIDR,
+			ServiceCIDR: existing.ServiceCIDR,
+		},
+		Firewall: k8znerv1alpha1.FirewallSpec{
+			Enabled: disc.firewall != nil,
+		},
+		Kubernetes: k8znerv1alpha1.KubernetesSpec{
+			Version: strings.TrimPrefix(first.node.Status.NodeInfo.KubeletVersion, "v"),
+		},
+		Talos: k8znerv1alpha1.TalosSpec{
+			Version:     talosVersionFromOSImage(first.node.Status.NodeInfo.OSImage),
+			SchematicID: existing.SchematicID,
+		},
+		CredentialsRef: corev1.LocalObjectReference{
+			Name: credentialsSecretName,
+		},
+		Bootstrap: &k8znerv1alpha1.BootstrapState{
+			Completed:       true,
+			CompletedAt:     &now,
+			BootstrapNode:   first.server.Name,
+			BootstrapNodeID: first.server.ID,
+			PublicIP:        hcloudInternal.ServerIPv4(first.server),
+		},
+	}
+	if disc.network.IPRange != nil {
+		spec.Network.IPv4CIDR = disc.network.IPRange.String()
+	}
+
+	pools := importWorkerPools(disc.workers)
+	switch {
+	case len(pools) == 1:
+		spec.Workers = k8znerv1alpha1.WorkerSpec{Count: pools[0].Count, Size: pools[0].Size}
+	case len(pools) > 1:
+		spec.WorkerPools = pools
+	}
+
+	status := k8znerv1alpha1.K8znerClusterStatus{
+		Phase:             k8znerv1alpha1.ClusterPhaseRunning,
+		ProvisioningPhase: k8znerv1alpha1.PhaseComplete,
+		ControlPlanes:     importNodeGroupStatus(disc.controlPlanes),
+		Workers:           importNodeGroupStatus(disc.workers),
+		Infrastructure: k8znerv1alpha1.InfrastructureStatus{
+			NetworkID:    disc.network.ID,
+			NetworkReady: true,
+		},
+		ControlPlaneEndpoint: existing.Endpoint,
+	}
+	if disc.firewall != nil {
+		status.Infrastructure.FirewallID = disc.firewall.ID
+		status.Infrastructure.FirewallReady = true
+	}
+	if lb := disc.loadBalancer; lb != nil {
+		status.Infrastructure.LoadBalancerID = lb.ID
+		status.Infrastructure.LoadBalancerIP = hcloudInternal.LoadBalancerIPv4(lb)
+		status.Infrastructure.LoadBalancerPrivateIP = hcloudInternal.LoadBalancerPrivateIP(lb)
+		status.Infrastructure.LoadBalancerReady = true
+		if status.ControlPlaneEndpoint == "" {
+			status.ControlPlaneEndpoint = status.Infrastructure.LoadBalancerIP
+		}
+	}
+
+	return &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterName,
+			Namespace: k8znerNamespace,
+			Labels: map[string]string{
+				"cluster": clusterName,
+			},
+			Annotations: map[string]string{
+				k8znerv1alpha1.PauseMachineConfigSyncAnnotation: "true",
+			},
+		},
+		Spec:   spec,
+		Status: status,
+	}
+}
+
+// importWorkerPools returns one pool per pool name assigned to the workers, in order.
+func importWorkerPools(workers []importServer) []k8znerv1alpha1.WorkerPoolSpec {
+	var pools []k8znerv1alpha1.WorkerPoolSpec
+	index := make(map[string]int)
+	for _, w := range workers {
+		i, ok := index[w.pool]
+		if !ok {
+			i = len(pools)
+			index[w.pool] = i
+			pools = append(pools, k8znerv1alpha1.WorkerPoolSpec{Name: w.pool, Size: importServerType(w.server)})
+		}
+		pools[i].Count++
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+	return pools
+}
+
+func importNodeGroupStatus(servers []importServer) k8znerv1alpha1.NodeGroupStatus {
+	group := k8znerv1alpha1.NodeGroupStatus{Desired: len(servers)}
+	for _, s := range servers {
+		node := k8znerv1alpha1.NodeStatus{
+			Name:         s.node.Name,
+			ServerID:     s.server.ID,
+			PublicIP:     hcloudInternal.ServerIPv4(s.server),
+			ServerType:   importServerType(s.server),
+			TalosVersion: talosVersionFromOSImage(s.node.Status.NodeInfo.OSImage),
+			Healthy:      importNodeReady(s.node),
+		}
+		if len(s.server.PrivateNet) > 0 && s.server.PrivateNet[0].IP != nil {
+			node.PrivateIP = s.server.PrivateNet[0].IP.String()
+		}
+		if s.role == labels.RoleWorker {
+			node.Pool = s.pool
+		}
+		if node.Healthy {
+			node.Phase = k8znerv1alpha1.NodePhaseReady
+			group.Ready++
+		} else {
+			node.Phase = k8znerv1alpha1.NodePhaseUnhealthy
+		}
+		group.Nodes = append(group.Nodes, node)
+	}
+	return group
+}
+
+func importNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func importServerType(srv *hcloud.Server) string {
+	if srv.ServerType == nil {
+		return ""
+	}
+	return srv.ServerType.Name
+}
+
+func importServerLocation(srv *hcloud.Server) string {
+	if srv.Datacenter == nil || srv.Datacenter.Location == nil {
+		return ""
+	}
+	return srv.Datacenter.Location.Name
+}
+
+// talosVersionFromOSImage returns the version in an OS image like "Talos (v1.9.0)".
+func talosVersionFromOSImage(osImage string) string {
+	_, rest, ok := strings.Cut(osImage, "(")
+	if !ok {
+		return ""
+	}
+	version, _, _ := strings.Cut(rest, ")")
+	return version
+}
+
+// importChanges lists the renames and relabels that give the discovered
+// resources the names and labels k8zner and its operator look them up by.
+// Existing labels are kept unless k8zner uses the same key.
+func importChanges(clusterName string, disc *importDiscovery) []importChange {
+	var changes []importChange
+	for _, s := range append(append([]importServer{}, disc.controlPlanes...), disc.workers...) {
+		want := labels.NewLabelBuilder(clusterName).WithRole(s.role).WithPool(s.pool).Build()
+		changes = append(changes, importChange{
+			kind:      "server",
+			id:        s.server.ID,
+			name:      s.server.Name,
+			newName:   s.server.Name,
+			oldLabels: s.server.Labels,
+			labels:    mergeImportLabels(s.server.Labels, want),
+		})
+	}
+
+	changes = append(changes, importChange{
+		kind:      "network",
+		id:        disc.network.ID,
+		name:      disc.network.Name,
+		newName:   clusterName,
+		oldLabels: disc.network.Labels,
+		labels:    mergeImportLabels(disc.network.Labels, labels.NewLabelBuilder(clusterName).Build()),
+	})
+	if fw := disc.firewall; fw != nil {
+		changes = append(changes, importChange{
+			kind:      "firewall",
+			id:        fw.ID,
+			name:      fw.Name,
+			newName:   clusterName,
+			oldLabels: fw.Labels,
+			labels:    mergeImportLabels(fw.Labels, labels.NewLabelBuilder(clusterName).Build()),
+			selector:  fmt.Sprintf("cluster=%s", clusterName),
+		})
+	}
+	if lb := disc.loadBalancer; lb != nil {
+		changes = append(changes, importChange{
+			kind:      "load balancer",
+			id:        lb.ID,
+			name:      lb.Name,
+			newName:   naming.KubeAPILoadBalancer(clusterName),
+			oldLabels: lb.Labels,
+			labels:    mergeImportLabels(lb.Labels, labels.NewLabelBuilder(clusterName).WithRole("kube-api").Build()),
+		})
+	}
+	return changes
+}
+
+func mergeImportLabels(existing, want map[string]string) map[string]string {
+	merged := make(map[string]string, len(existing)+len(want))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range want {
+		merged[k] = v
+	}
+	return merged
+}
+
+// applyImportChanges renames and relabels the discovered resources.
+func applyImportChanges(ctx context.Context, infra hcloudInternal.InfrastructureManager, changes []importChange) error {
+	for _, c := range changes {
+		var err error
+		switch c.kind {
+		case "server":
+			err = infra.UpdateServerLabels(ctx, c.id, c.labels)
+		case "network":
+			_, err = infra.UpdateNetwork(ctx, c.id, c.newName, c.labels)
+		case "firewall":
+			_, err = infra.UpdateFirewall(ctx, c.id, c.newName, c.labels, c.selector)
+		case "load balancer":
+			_, err = infra.UpdateLoadBalancer(ctx, c.id, c.newName, c.labels)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Updated %s %s", c.kind, c.newName)
+	}
+	return nil
+}
+
+// importConfigConflicts lists the settings of the running cluster that the
+// machine configs k8zner generates would change, disrupting the cluster once
+// they are rolled out.
+func importConfigConflicts(clusterName string, existing *talos.ExistingCluster) []string {
+	var conflicts []string
+	if existing.CNI != "" && existing.CNI != "none" {
+		conflicts = append(conflicts, fmt.Sprintf(
+			"Talos installs the %s CNI, k8zner configures none and installs Cilium", existing.CNI))
+	}
+	if existing.KubeProxy {
+		conflicts = append(conflicts, "Talos runs kube-proxy, k8zner disables it for Cilium's replacement")
+	}
+	if !existing.ExternalCloudProvider {
+		conflicts = append(conflicts, "the external cloud provider is disabled, k8zner enables it for the Hetzner CCM")
+	}
+	if existing.Name != "" && existing.Name != clusterName {
+		conflicts = append(conflicts, fmt.Sprintf(
+			"the Talos cluster name is %s, k8zner uses %s", existing.Name, clusterName))
+	}
+	return conflicts
+}
+
+// importMachineConfigDiff compares the running machine config of the first
+// control plane with the one the operator generates for it. Fields holding
+// keys, tokens or secrets are compared but not printed.
+func importMachineConfigDiff(cluster *k8znerv1alpha1.K8znerCluster, secretsData, running []byte) ([]planFieldChange, error) {
+	generator, err := operatorprov.NewPhaseAdapter(nil).CreateTalosGenerator(cluster,
+		&operatorprov.Credentials{TalosSecrets: secretsData})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the config generator: %w", err)
+	}
+	first := cluster.Status.ControlPlanes.Nodes[0]
+	var sans []string
+	if cluster.Status.ControlPlaneEndpoint != "" {
+		sans = append(sans, cluster.Status.ControlPlaneEndpoint)
+	}
+	for _, node := range cluster.Status.ControlPlanes.Nodes {
+		for _, ip := range []string{node.PrivateIP, node.PublicIP} {
+			if ip != "" {
+				sans = append(sans, ip)
+			}
+		}
+	}
+	generated, err := generator.GenerateControlPlaneConfig(sans, first.Name, first.ServerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the machine config of %s: %w", first.Name, err)
+	}
+
+	from, err := machineConfigDocuments(running)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the running machine config: %w", err)
+	}
+	to, err := machineConfigDocuments(generated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the generated machine config: %w", err)
+	}
+
+	var changes []planFieldChange
+	for _, kind := range sortedKeys(mergeKeys(from, to)) {
+		diffJSONValues(kind, from[kind], to[kind], &changes)
+	}
+	for i := range changes {
+		if sensitiveConfigField(changes[i].Field) {
+			changes[i].From = redactConfigValue(changes[i].From)
+			changes[i].To = redactConfigValue(changes[i].To)
+		}
+	}
+	return changes, nil
+}
+
+// machineConfigDocuments splits a multi-document machine config by kind; the
+// main document has none and is keyed by its version.
+func machineConfigDocuments(data []byte) (map[string]any, error) {
+	docs := make(map[string]any)
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var doc map[string]any
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		key, _ := doc["kind"].(string)
+		if key == "" {
+			key, _ = doc["version"].(string)
+		}
+		if name, ok := doc["name"].(string); ok && name != "" {
+			key = fmt.Sprintf("%s[name=%s]", key, name)
+		}
+		docs[key] = doc
+	}
+}
+
+// mergeKeys returns the keys of both maps.
+func mergeKeys(a, b map[string]any) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
+
+// sensitiveConfigField reports whether the last element of a machine config
+// field path names a key, token or secret.
+func sensitiveConfigField(field string) bool {
+	last := strings.ToLower(field[strings.LastIndex(field, ".")+1:])
+	for _, word := range []string{"key", "token", "secret"} {
+		if strings.Contains(last, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactConfigValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	return "(redacted)"
+}
+
+// renderImportPlan prints the changes import would make, the resulting spec
+// and the machine config changes the operator makes once the sync is resumed.
+func renderImportPlan(clusterName string, changes []importChange, cluster *k8znerv1alpha1.K8znerCluster, warnings, conflicts []string, configChanges []planFieldChange) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Import plan for cluster %s (dry run, nothing was changed)\n\n", clusterName)
+
+	b.WriteString("Hetzner Cloud resources:\n")
+	for _, c := range changes {
+		name := c.name
+		if c.newName != c.name {
+			name = fmt.Sprintf("%s → %s", c.name, c.newName)
+		}
+		fmt.Fprintf(&b, "  %-14s %-32s %s\n", c.kind, name, formatLabelChanges(c.oldLabels, c.labels))
+		if c.selector != "" {
+			fmt.Fprintf(&b, "  %-14s %-32s applied to %s\n", "", "", c.selector)
+		}
+	}
+
+	if len(warnings) > 0 {
+		b.WriteString("\nWarnings:\n")
+		for _, warning := range warnings {
+			fmt.Fprintf(&b, "  ! %s\n", warning)
+		}
+	}
+
+	spec, err := yaml.Marshal(cluster.Spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode spec: %w", err)
+	}
+	b.WriteString("\nK8znerCluster spec:\n")
+	for _, line := range strings.Split(strings.TrimRight(string(spec), "\n"), "\n") {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+
+	if len(conflicts) > 0 {
+		b.WriteString("\nSettings the generated machine configs change:\n")
+		for _, conflict := range conflicts {
+			fmt.Fprintf(&b, "  ! %s\n", conflict)
+		}
+	}
+
+	if len(configChanges) > 0 {
+		fmt.Fprintf(&b, "\nMachine config changes on %s once the sync is resumed:\n", cluster.Status.ControlPlanes.Nodes[0].Name)
+		for _, c := range configChanges {
+			switch {
+			case c.From == nil:
+				fmt.Fprintf(&b, "  + %s: %s\n", c.Field, formatPlanValue(c.To))
+			case c.To == nil:
+				fmt.Fprintf(&b, "  - %s: %s\n", c.Field, formatPlanValue(c.From))
+			default:
+				fmt.Fprintf(&b, "  ~ %s: %s → %s\n", c.Field, formatPlanValue(c.From), formatPlanValue(c.To))
+			}
+		}
+	}
+
+	b.WriteString("\nThe nodes keep their machine configs: the K8znerCluster carries the\n")
+	fmt.Fprintf(&b, "%s annotation. Remove it to let the\n", k8znerv1alpha1.PauseMachineConfigSyncAnnotation)
+	b.WriteString("operator roll out the configs k8zner generates one node at a time.\n")
+	if len(conflicts) > 0 {
+		b.WriteString("Run without --dry-run and with --allow-config-changes to import the cluster.\n")
+	} else {
+		b.WriteString("Run without --dry-run to import the cluster.\n")
+	}
+	return b.String(), nil
+}
+
+// formatLabelChanges lists the labels that are added or changed, as key=value.
+func formatLabelChanges(old, updated map[string]string) string {
+	keys := make([]string, 0, len(updated))
+	for k := range updated {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		if prev, ok := old[k]; ok && prev == updated[k] {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", k, updated[k]))
+	}
+	if len(parts) == 0 {
+		return "labels unchanged"
+	}
+	return "+" + strings.Join(parts, " +")
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	hcloudgo "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/hcloud"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+	"github.com/milankappen/k8zner/internal/util/labels"
+)
+
+// importTestUpdates records the renames and relabels made through the mock client.
+type importTestUpdates struct {
+	serverLabels map[int64]map[string]string
+	names        map[string]string
+	selector     string
+}
+
+// newImportTestInfra returns a project with a control plane and a worker of a
+// Terraform-built cluster "tf", an unrelated server, and an ingress load
+// balancer next to the Kubernetes API one.
+func newImportTestInfra(updates *importTestUpdates) *hcloud.MockClient {
+	network := &hcloudgo.Network{ID: 10, Name: "tf-net", IPRange: &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(16, 32)}}
+	firewall := &hcloudgo.Firewall{ID: 20, Name: "tf-fw"}
+	loadBalancers := map[int64]*hcloudgo.LoadBalancer{
+		30: {ID: 30, Name: "tf-ingress", Services: []hcloudgo.LoadBalancerService{{ListenPort: 443}}},
+		31: {ID: 31, Name: "tf-api", Services: []hcloudgo.LoadBalancerService{{ListenPort: 6443}},
+			PublicNet: hcloudgo.LoadBalancerPublicNet{IPv4: hcloudgo.LoadBalancerPublicNetIPv4{IP: net.ParseIP("5.5.5.5")}}},
+	}
+	servers := []*hcloudgo.Server{
+		{
+			ID: 1, Name: "tf-cp-1", Labels: map[string]string{"env": "prod", "role": "controlplane"},
+			ServerType: &hcloudgo.ServerType{Name: "cx33"},
+			Datacenter: &hcloudgo.Datacenter{Location: &hcloudgo.Location{Name: "fsn1"}},
+			PublicNet: hcloudgo.ServerPublicNet{
+				IPv4:      hcloudgo.ServerPublicNetIPv4{IP: net.ParseIP("1.1.1.1")},
+				Firewalls: []*hcloudgo.ServerFirewallStatus{{Firewall: hcloudgo.Firewall{ID: 20}}},
+			},
+			PrivateNet:    []hcloudgo.ServerPrivateNet{{Network: &hcloudgo.Network{ID: 10}, IP: net.ParseIP("10.0.1.1")}},
+			LoadBalancers: []*hcloudgo.LoadBalancer{{ID: 30}, {ID: 31}},
+		},
+		{
+			ID: 2, Name: "tf-worker-1",
+			ServerType: &hcloudgo.ServerType{Name: "cx43"},
+			Datacenter: &hcloudgo.Datacenter{Location: &hcloudgo.Location{Name: "fsn1"}},
+			PrivateNet: []hcloudgo.ServerPrivateNet{{Network: &hcloudgo.Network{ID: 10}, IP: net.ParseIP("10.0.1.2")}},
+		},
+		{ID: 3, Name: "unrelated"},
+	}
+
+	return &hcloud.MockClient{
+		GetServersByLabelFunc: func(_ context.Context, _ map[string]string) ([]*hcloudgo.Server, error) {
+			return servers, nil
+		},
+		GetNetworkFunc: func(_ context.Context, name string) (*hcloudgo.Network, error) {
+			if name == "10" {
+				return network, nil
+			}
+			return nil, nil
+		},
+		GetFirewallFunc: func(_ context.Context, name string) (*hcloudgo.Firewall, error) {
+			if name == "20" {
+				return firewall, nil
+			}
+			return nil, nil
+		},
+		GetLoadBalancerFunc: func(_ context.Context, name string) (*hcloudgo.LoadBalancer, error) {
+			id, _ := strconv.ParseInt(name, 10, 64)
+			return loadBalancers[id], nil
+		},
+		UpdateServerLabelsFunc: func(_ context.Context, id int64, l map[string]string) error {
+			updates.serverLabels[id] = l
+			return nil
+		},
+		UpdateNetworkFunc: func(_ context.Context, id int64, name string, _ map[string]string) (*hcloudgo.Network, error) {
+			updates.names["network"] = name
+			return &hcloudgo.Network{ID: id, Name: name}, nil
+		},
+		UpdateFirewallFunc: func(_ context.Context, id int64, name string, _ map[string]string, selector string) (*hcloudgo.Firewall, error) {
+			updates.names["firewall"] = name
+			updates.selector = selector
+			return &hcloudgo.Firewall{ID: id, Name: name}, nil
+		},
+		UpdateLoadBalancerFunc: func(_ context.Context, id int64, name string, _ map[string]string) (*hcloudgo.LoadBalancer, error) {
+			updates.names["load balancer "+strconv.FormatInt(id, 10)] = name
+			return &hcloudgo.LoadBalancer{ID: id, Name: name}, nil
+		},
+	}
+}
+
+func newImportTestNode(name, providerID, internalIP string, controlPlane bool) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+		Status: corev1.NodeStatus{
+			Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: internalIP}},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			NodeInfo:   corev1.NodeSystemInfo{OSImage: "Talos (v1.9.0)", KubeletVersion: "v1.32.0"},
+		},
+	}
+	if controlPlane {
+		node.Labels[nodeRoleControlPlane] = ""
+	}
+	return node
+}
+
+// setupImportTest stubs the Talos API and operator installation and returns a
+// cluster whose control plane is matched by provider ID and whose worker is
+// matched by its private IP, and the settings its machine config reads back.
+func setupImportTest(t *testing.T, objects ...client.Object) (client.Client, *talos.ExistingCluster, *int64) {
+	t.Helper()
+	origRead := readExistingCluster
+	origInstall := installImportOperator
+	t.Cleanup(func() {
+		readExistingCluster = origRead
+		installImportOperator = origInstall
+	})
+
+	_, sb := marshalTestSecrets(t)
+	existing := &talos.ExistingCluster{
+		Secrets:               sb,
+		Name:                  "prod",
+		Endpoint:              "api.example.com",
+		PodCIDR:               "10.244.0.0/16",
+		ServiceCIDR:           "10.96.0.0/12",
+		SchematicID:           "abc123",
+		CNI:                   "none",
+		ExternalCloudProvider: true,
+	}
+	readExistingCluster = func(_ context.Context, _ []byte, endpoint string) (*talos.ExistingCluster, error) {
+		require.Equal(t, "1.1.1.1", endpoint)
+		return existing, nil
+	}
+	operatorNetwork := new(int64)
+	installImportOperator = func(_ context.Context, cfg *config.Config, _ []byte, networkID int64) error {
+		require.True(t, cfg.Addons.Operator.Enabled)
+		*operatorNetwork = networkID
+		return nil
+	}
+
+	objects = append(objects,
+		newImportTestNode("tf-cp-1", "hcloud://1", "10.0.1.1", true),
+		newImportTestNode("worker-a", "", "10.0.1.2", false),
+	)
+	return fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).WithObjects(objects...).Build(), existing, operatorNetwork
+}
+
+// Serial: swaps the package-level readExistingCluster and installImportOperator and changes directory.
+func TestImportCluster(t *testing.T) {
+	t.Chdir(t.TempDir())
+	k8sClient, _, operatorNetwork := setupImportTest(t)
+	updates := &importTestUpdates{serverLabels: map[int64]map[string]string{}, names: map[string]string{}}
+
+	err := importCluster(context.Background(), k8sClient, newImportTestInfra(updates), "prod", "token",
+		[]byte("kubeconfig"), []byte("talosconfig"), false, false)
+	require.NoError(t, err)
+
+	// Servers get k8zner's labels, keeping their own
+	cpLabels := updates.serverLabels[1]
+	assert.Equal(t, "prod", cpLabels[labels.KeyCluster])
+	assert.Equal(t, labels.RoleControlPlane, cpLabels[labels.KeyRole])
+	assert.Equal(t, "prod", cpLabels["env"])
+	assert.Equal(t, labels.RoleWorker, updates.serverLabels[2][labels.KeyRole])
+	assert.Equal(t, k8znerv1alpha1.DefaultWorkerPoolName, updates.serverLabels[2][labels.KeyPool])
+	assert.NotContains(t, updates.serverLabels, int64(3), "servers outside the cluster are left alone")
+
+	// Shared resources get the names the operator looks them up by
+	assert.Equal(t, map[string]string{
+		"network":          "prod",
+		"firewall":         "prod",
+		"load balancer 31": "prod-kube",
+	}, updates.names, "the ingress load balancer is left alone")
+	assert.Equal(t, "cluster=prod", updates.selector)
+
+	assert.Equal(t, int64(10), *operatorNetwork)
+
+	secret := &corev1.Secret{}
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: k8znerNamespace, Name: credentialsSecretName}, secret))
+	assert.Equal(t, "token", string(secret.Data[k8znerv1alpha1.CredentialsKeyHCloudToken]))
+	assert.Equal(t, "talosconfig", string(secret.Data[k8znerv1alpha1.CredentialsKeyTalosConfig]))
+	assert.NotEmpty(t, secret.Data[k8znerv1alpha1.CredentialsKeyTalosSecrets])
+
+	cluster := &k8znerv1alpha1.K8znerCluster{}
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: k8znerNamespace, Name: "prod"}, cluster))
+	assert.Equal(t, "fsn1", cluster.Spec.Region)
+	assert.Equal(t, k8znerv1alpha1.ControlPlaneSpec{Count: 1, Size: "cx33"}, cluster.Spec.ControlPlanes)
+	assert.Equal(t, k8znerv1alpha1.WorkerSpec{Count: 1, Size: "cx43"}, cluster.Spec.Workers)
+	assert.Equal(t, "10.0.0.0/16", cluster.Spec.Network.IPv4CIDR)
+	assert.Equal(t, "10.244.0.0/16", cluster.Spec.Network.PodCIDR)
+	assert.Equal(t, "1.32.0", cluster.Spec.Kubernetes.Version)
+	assert.Equal(t, "v1.9.0", cluster.Spec.Talos.Version)
+	assert.Equal(t, "abc123", cluster.Spec.Talos.SchematicID)
+	assert.True(t, cluster.Spec.Firewall.Enabled)
+	assert.Equal(t, "tf-cp-1", cluster.Spec.Bootstrap.BootstrapNode)
+	assert.Equal(t, "true", cluster.Annotations[k8znerv1alpha1.PauseMachineConfigSyncAnnotation],
+		"the nodes keep their machine configs until the user opts in")
+
+	assert.Equal(t, k8znerv1alpha1.ClusterPhaseRunning, cluster.Status.Phase)
+	assert.Equal(t, k8znerv1alpha1.PhaseComplete, cluster.Status.ProvisioningPhase)
+	assert.Equal(t, "api.example.com", cluster.Status.ControlPlaneEndpoint)
+	assert.Equal(t, int64(31), cluster.Status.Infrastructure.LoadBalancerID)
+	assert.Equal(t, "5.5.5.5", cluster.Status.Infrastructure.LoadBalancerIP)
+	assert.Equal(t, 1, cluster.Status.Workers.Ready)
+	assert.Equal(t, k8znerv1alpha1.DefaultWorkerPoolName, cluster.Status.Workers.Nodes[0].Pool)
+
+	for _, name := range []string{secretsFile, talosConfigPath, kubeconfigPath} {
+		_, err := os.Stat(name)
+		assert.NoError(t, err, name)
+	}
+}
+
+// Serial: swaps the package-level readExistingCluster and installImportOperator and changes directory.
+func TestImportCluster_DryRun(t *testing.T) {
+	t.Chdir(t.TempDir())
+	k8sClient, existing, operatorNetwork := setupImportTest(t)
+	existing.CNI = "flannel"
+	existing.MachineConfig = []byte("version: v1alpha1\ncluster:\n  network:\n    cni:\n      name: flannel\n")
+	updates := &importTestUpdates{serverLabels: map[int64]map[string]string{}, names: map[string]string{}}
+
+	out := captureOutput(func() {
+		err := importCluster(context.Background(), k8sClient, newImportTestInfra(updates), "prod", "token",
+			[]byte("kubeconfig"), []byte("talosconfig"), false, true)
+		require.NoError(t, err)
+	})
+	assert.Contains(t, out, "Talos installs the flannel CNI")
+	assert.Contains(t, out, "Machine config changes on tf-cp-1")
+	assert.Contains(t, out, "~ v1alpha1.cluster.network.cni.name: flannel → none")
+	assert.Contains(t, out, "--allow-config-changes")
+
+	assert.Empty(t, updates.serverLabels)
+	assert.Empty(t, updates.names)
+	assert.Zero(t, *operatorNetwork)
+	assert.NoFileExists(t, secretsFile)
+	clusters := &k8znerv1alpha1.K8znerClusterList{}
+	require.NoError(t, k8sClient.List(context.Background(), clusters))
+	assert.Empty(t, clusters.Items)
+}
+
+// Serial: swaps the package-level readExistingCluster and installImportOperator.
+func TestImportCluster_AlreadyManaged(t *testing.T) {
+	k8sClient, _, _ := setupImportTest(t, &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: k8znerNamespace, Name: "prod"},
+	})
+	updates := &importTestUpdates{serverLabels: map[int64]map[string]string{}, names: map[string]string{}}
+
+	err := importCluster(context.Background(), k8sClient, newImportTestInfra(updates), "prod", "token", nil, nil, false, true)
+	require.ErrorContains(t, err, "already managed")
+}
+
+// Serial: swaps the package-level readExistingCluster and installImportOperator and changes directory.
+func TestImportCluster_KeepsOtherClusterFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile(secretsFile, []byte("other cluster"), 0600))
+	k8sClient, _, _ := setupImportTest(t)
+	updates := &importTestUpdates{serverLabels: map[int64]map[string]string{}, names: map[string]string{}}
+
+	err := importCluster(context.Background(), k8sClient, newImportTestInfra(updates), "prod", "token",
+		[]byte("kubeconfig"), []byte("talosconfig"), false, false)
+	require.ErrorContains(t, err, "another cluster")
+	assert.Empty(t, updates.serverLabels, "nothing is changed")
+}
+
+// Serial: swaps the package-level readExistingCluster and installImportOperator and changes directory.
+func TestImportCluster_ConfigConflicts(t *testing.T) {
+	t.Chdir(t.TempDir())
+	k8sClient, existing, _ := setupImportTest(t)
+	existing.KubeProxy = true
+	updates := &importTestUpdates{serverLabels: map[int64]map[string]string{}, names: map[string]string{}}
+
+	err := importCluster(context.Background(), k8sClient, newImportTestInfra(updates), "prod", "token",
+		[]byte("kubeconfig"), []byte("talosconfig"), false, false)
+	require.ErrorContains(t, err, "Talos runs kube-proxy")
+	assert.ErrorContains(t, err, "--allow-config-changes")
+	assert.Empty(t, updates.serverLabels, "nothing is changed")
+
+	err = importCluster(context.Background(), k8sClient, newImportTestInfra(updates), "prod", "token",
+		[]byte("kubeconfig"), []byte("talosconfig"), true, false)
+	require.NoError(t, err)
+	assert.NotEmpty(t, updates.serverLabels)
+}
+
+func TestImportConfigConflicts(t *testing.T) {
+	t.Parallel()
+	matching := &talos.ExistingCluster{Name: "prod", CNI: "none", ExternalCloudProvider: true}
+	assert.Empty(t, importConfigConflicts("prod", matching))
+
+	conflicts := importConfigConflicts("prod", &talos.ExistingCluster{Name: "tf", CNI: "flannel", KubeProxy: true})
+	require.Len(t, conflicts, 4)
+	assert.Contains(t, conflicts[0], "flannel")
+	assert.Contains(t, conflicts[1], "kube-proxy")
+	assert.Contains(t, conflicts[2], "external cloud provider")
+	assert.Contains(t, conflicts[3], "cluster name is tf")
+}
+
+func TestMachineConfigDocuments(t *testing.T) {
+	t.Parallel()
+	docs, err := machineConfigDocuments([]byte(`version: v1alpha1
+machine:
+  token: abc
+---
+apiVersion: v1alpha1
+kind: HostnameConfig
+hostname: cp-1
+---
+apiVersion: v1alpha1
+kind: UserVolumeConfig
+name: data
+`))
+	require.NoError(t, err)
+	assert.Len(t, docs, 3)
+	assert.Contains(t, docs, "v1alpha1")
+	assert.Contains(t, docs, "HostnameConfig")
+	assert.Contains(t, docs, "UserVolumeConfig[name=data]")
+
+	assert.True(t, sensitiveConfigField("v1alpha1.machine.token"))
+	assert.True(t, sensitiveConfigField("v1alpha1.cluster.secretboxEncryptionSecret"))
+	assert.False(t, sensitiveConfigField("v1alpha1.cluster.network.cni.name"))
+}
+
+func TestImportWorkerPools(t *testing.T) {
+	t.Parallel()
+	workers := []importServer{
+		{server: &hcloudgo.Server{Name: "a", ServerType: &hcloudgo.ServerType{Name: "cx43"}}},
+		{server: &hcloudgo.Server{Name: "b", ServerType: &hcloudgo.ServerType{Name: "cx33"}}},
+		{server: &hcloudgo.Server{Name: "c", ServerType: &hcloudgo.ServerType{Name: "cx43"}}},
+	}
+	assignImportWorkerPools(workers)
+
+	assert.Equal(t, []k8znerv1alpha1.WorkerPoolSpec{
+		{Name: "workers-cx33", Count: 1, Size: "cx33"},
+		{Name: "workers-cx43", Count: 2, Size: "cx43"},
+	}, importWorkerPools(workers))
+}
+
+func TestRenderImportPlan(t *testing.T) {
+	t.Parallel()
+	changes := []importChange{
+		{kind: "server", name: "cp-1", newName: "cp-1", oldLabels: map[string]string{"cluster": "prod"},
+			labels: map[string]string{"cluster": "prod", labels.KeyRole: "control-plane"}},
+		{kind: "firewall", name: "tf-fw", newName: "prod", labels: map[string]string{}, selector: "cluster=prod"},
+	}
+	cluster := &k8znerv1alpha1.K8znerCluster{Spec: k8znerv1alpha1.K8znerClusterSpec{Region: "fsn1"}}
+
+	out, err := renderImportPlan("prod", changes, cluster, []string{"node x matches no Hetzner server and is left out"}, nil, nil)
+	require.NoError(t, err)
+	assert.Contains(t, out, "+k8zner.io/role=control-plane")
+	assert.NotContains(t, out, "+cluster=prod")
+	assert.Contains(t, out, "tf-fw → prod")
+	assert.Contains(t, out, "applied to cluster=prod")
+	assert.Contains(t, out, "! node x matches no Hetzner server")
+	assert.Contains(t, out, "region: fsn1")
+}
+
+func TestTalosVersionFromOSImage(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "v1.9.0", talosVersionFromOSImage("Talos (v1.9.0)"))
+	assert.Empty(t, talosVersionFromOSImage("Talos"))
+}
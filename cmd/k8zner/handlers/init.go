@@ -29,8 +29,10 @@ var (
 	expandSpecToConfig = config.ExpandSpec
 )
 
-// Init runs the v2 configuration wizard and writes the result to a file.
+// Init runs the v2 configuration wizard and writes the result to a file,
+// in the selected workspace when outputPath is relative.
 func Init(ctx context.Context, outputPath string) error {
+	outputPath = workspacePath(outputPath)
 	if fileExists(outputPath) {
 		fmt.Printf("Warning: %s already exists and will be overwritten.\n\n", outputPath)
 	}
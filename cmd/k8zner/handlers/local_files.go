@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+	"github.com/milankappen/k8zner/internal/state"
+)
+
+// localFiles reads and writes the credential files in workDir. loadConfig
+// replaces it with one that encrypts when recipients are configured.
+var localFiles = state.NewLocalFiles(".", nil)
+
+// credentialFiles are the files in workDir that hold secrets.
+var credentialFiles = []string{secretsFile, talosConfigPath, kubeconfigPath, accessDataPath, state.FileCredentials, state.FileCluster}
+
+// useSecretsEncryption makes localFiles encrypt for the configured recipients.
+// With a remote state backend the files are always encrypted, for the state
+// recipients unless secrets encryption names its own, so the state pulled
+// from the bucket never stays in the workspace as plaintext.
+func useSecretsEncryption(cfg *config.Config) error {
+	var recipients []age.Recipient
+	switch {
+	case len(cfg.SecretsRecipients) > 0:
+		parsed, err := state.ParseRecipients(cfg.SecretsRecipients)
+		if err != nil {
+			return fmt.Errorf("invalid secrets encryption recipient: %w", err)
+		}
+		recipients = parsed
+	case cfg.State.Backend == config.StateBackendS3:
+		parsed, err := state.Recipients(cfg.State.Recipients)
+		if err != nil {
+			return err
+		}
+		recipients = parsed
+	default:
+		return nil
+	}
+	localFiles = state.NewLocalFiles(workDir, recipients)
+	return nil
+}
+
+// writeLocalFile writes a credential file to workDir, encrypted when secrets
+// encryption is configured.
+func writeLocalFile(name string, data []byte) error {
+	if !localFiles.Encrypted() {
+		return writeFile(workspacePath(name), data, 0600)
+	}
+	return localFiles.WriteFile(name, data)
+}
+
+// loadOrGenerateSecrets loads the Talos secrets bundle from the working
+// directory, decrypting it if needed, or generates and saves a new one.
+func loadOrGenerateSecrets(name, talosVersion string) (*talos.SecretsBundle, error) {
+	data, err := localFiles.ReadFile(name)
+	if err == nil {
+		return talos.ParseSecrets(data)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	sb, err := talos.NewSecrets(talosVersion)
+	if err != nil {
+		return nil, err
+	}
+	data, err = talos.MarshalSecrets(sb)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeLocalFile(name, data); err != nil {
+		return nil, fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	return sb, nil
+}
+
+// loadRESTConfig reads the kubeconfig from the working directory, decrypting
+// it if needed. A missing kubeconfig yields an error matching [os.ErrNotExist].
+func loadRESTConfig() (*rest.Config, error) {
+	kubeconfig, err := localFiles.ReadFile(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	return clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+}
+
+// SecretsDecrypt writes plaintext copies of the encrypted credential files
+// for tools that cannot read age files, such as kubectl and talosctl. With
+// out set to "-", a single file is written to stdout instead.
+func SecretsDecrypt(names []string, out string) error {
+	if len(names) == 0 {
+		for _, name := range credentialFiles {
+			if localFiles.IsEncrypted(name) {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("no encrypted credential files in %s", workDir)
+		}
+	}
+
+	if out == "-" {
+		if len(names) != 1 {
+			return errors.New("--out - writes a single file to stdout, name the file to decrypt")
+		}
+		data, err := localFiles.ReadFile(strings.TrimSuffix(names[0], state.EncryptedSuffix))
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.MkdirAll(out, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", out, err)
+	}
+	for _, name := range names {
+		name = strings.TrimSuffix(name, state.EncryptedSuffix)
+		data, err := localFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(out, name)
+		if err := writeFile(path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("Decrypted %s to %s\n", localFiles.Path(name), path)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/state"
+)
+
+// setupEncryptedFiles runs the test in an empty directory with secrets
+// encryption enabled for a new age key, which is also used to decrypt.
+func setupEncryptedFiles(t *testing.T) *age.X25519Identity {
+	t.Helper()
+	orig := localFiles
+	t.Cleanup(func() { localFiles = orig })
+
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	t.Chdir(t.TempDir())
+	require.NoError(t, useSecretsEncryption(&config.Config{SecretsRecipients: []string{id.Recipient().String()}}))
+	require.NoError(t, os.WriteFile("key.txt", []byte(id.String()+"\n"), 0600))
+	t.Setenv(state.IdentityEnv, "key.txt")
+	return id
+}
+
+func TestUseSecretsEncryption(t *testing.T) {
+	// Serial: swaps the package-global localFiles.
+	orig := localFiles
+	defer func() { localFiles = orig }()
+
+	require.NoError(t, useSecretsEncryption(&config.Config{}))
+	assert.False(t, localFiles.Encrypted())
+
+	err := useSecretsEncryption(&config.Config{SecretsRecipients: []string{"age1invalid"}})
+	assert.ErrorContains(t, err, "invalid secrets encryption recipient")
+
+	// The state pulled from a remote backend is kept encrypted for its recipients
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	remote := config.StateConfig{Backend: config.StateBackendS3, Recipients: []string{id.Recipient().String()}}
+	require.NoError(t, useSecretsEncryption(&config.Config{State: remote}))
+	assert.True(t, localFiles.Encrypted())
+
+	t.Setenv(state.PassphraseEnv, "")
+	err = useSecretsEncryption(&config.Config{State: config.StateConfig{Backend: config.StateBackendS3}})
+	assert.ErrorContains(t, err, state.PassphraseEnv)
+}
+
+func TestWriteLocalFile_Encrypted(t *testing.T) {
+	// Serial: swaps the package-global localFiles and changes the working directory.
+	setupEncryptedFiles(t)
+
+	require.NoError(t, writeLocalFile(kubeconfigPath, []byte("apiVersion: v1\n")))
+	_, err := os.Stat(kubeconfigPath)
+	assert.ErrorIs(t, err, os.ErrNotExist, "no plaintext on disk")
+	raw, err := os.ReadFile(kubeconfigPath + state.EncryptedSuffix)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "apiVersion")
+
+	data, err := localFiles.ReadFile(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\n", string(data))
+}
+
+func TestLoadOrGenerateSecrets_Encrypted(t *testing.T) {
+	// Serial: swaps the package-global localFiles and changes the working directory.
+	setupEncryptedFiles(t)
+
+	generated, err := loadOrGenerateSecrets(secretsFile, "v1.7.0")
+	require.NoError(t, err)
+	assert.True(t, localFiles.IsEncrypted(secretsFile))
+
+	loaded, err := loadOrGenerateSecrets(secretsFile, "v1.7.0")
+	require.NoError(t, err)
+	assert.Equal(t, generated.Cluster.ID, loaded.Cluster.ID, "existing secrets are reused")
+}
+
+func TestSecretsDecrypt(t *testing.T) {
+	// Serial: swaps the package-global localFiles and changes the working directory.
+	setupEncryptedFiles(t)
+	require.NoError(t, writeLocalFile(kubeconfigPath, []byte("kube")))
+	require.NoError(t, writeLocalFile(talosConfigPath, []byte("talos")))
+
+	out := filepath.Join(t.TempDir(), "plain")
+	require.NoError(t, SecretsDecrypt(nil, out))
+	data, err := os.ReadFile(filepath.Join(out, kubeconfigPath))
+	require.NoError(t, err)
+	assert.Equal(t, "kube", string(data))
+	data, err = os.ReadFile(filepath.Join(out, talosConfigPath))
+	require.NoError(t, err)
+	assert.Equal(t, "talos", string(data))
+
+	other := filepath.Join(t.TempDir(), "one")
+	require.NoError(t, SecretsDecrypt([]string{talosConfigPath + state.EncryptedSuffix}, other))
+	_, err = os.Stat(filepath.Join(other, talosConfigPath))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(other, kubeconfigPath))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.ErrorContains(t, SecretsDecrypt(nil, "-"), "single file")
+}
+
+func TestSecretsDecrypt_NothingEncrypted(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.ErrorContains(t, SecretsDecrypt(nil, "out"), "no encrypted credential files")
+}
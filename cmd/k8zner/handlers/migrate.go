@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/milankappen/k8zner/internal/config"
+)
+
+// Roles of Terraform-managed Hetzner resources that k8zner import adopts.
+const (
+	tfRoleControlPlane = "control plane"
+	tfRoleWorker       = "worker"
+	tfRoleNetwork      = "network"
+	tfRoleFirewall     = "firewall"
+	tfRoleKubeAPI      = "API load balancer"
+)
+
+// terraformState is the part of a Terraform state file (format version 4, as
+// written by terraform state pull) that migrate reads.
+type terraformState struct {
+	Version int `json:"version"`
+	Outputs map[string]struct {
+		Value any `json:"value"`
+	} `json:"outputs"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   any            `json:"index_key"`
+			Attributes map[string]any `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// terraformResource is one managed resource instance in a Terraform state.
+type terraformResource struct {
+	module  string
+	address string
+	typ     string
+	id      string
+	name    string
+	// role is what k8zner import adopts a Hetzner resource as, "" for
+	// resources it leaves alone.
+	role string
+}
+
+// MigrateTerraform converts the variables of a terraform-hcloud-kubernetes
+// module call into k8zner.yaml and lists the settings that do not carry over.
+// With a Terraform state file, it also shows which Hetzner resources k8zner
+// import adopts instead of creating new ones, writes the kubeconfig and
+// talosconfig outputs of the state to the working directory or workspace and
+// prints the commands that hand the running cluster over to k8zner. A
+// relative outputPath is written to the selected workspace.
+func MigrateTerraform(tfvarsPath, tfstatePath, outputPath string) error {
+	outputPath = workspacePath(outputPath)
+	data, err := os.ReadFile(tfvarsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read tfvars: %w", err)
+	}
+	vars, err := config.ParseTerraformVars(data)
+	if err != nil {
+		return err
+	}
+	migration, err := config.SpecFromTerraformVars(vars)
+	if err != nil {
+		return fmt.Errorf("failed to convert %s: %w", tfvarsPath, err)
+	}
+
+	var state *terraformState
+	if tfstatePath != "" {
+		data, err := os.ReadFile(tfstatePath)
+		if err != nil {
+			return fmt.Errorf("failed to read Terraform state: %w", err)
+		}
+		if state, err = parseTerraformState(data); err != nil {
+			return err
+		}
+	}
+
+	if fileExists(outputPath) {
+		fmt.Printf("Warning: %s already exists and will be overwritten.\n\n", outputPath)
+	}
+	if err := writeV2Config(migration.Spec, outputPath); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	fmt.Printf("Wrote %s for cluster %s (%s, %s).\n", outputPath, migration.Spec.Name, string(migration.Spec.Region), string(migration.Spec.Mode))
+
+	if len(migration.Unsupported) > 0 {
+		fmt.Println()
+		fmt.Println("Settings that were not carried over:")
+		for _, note := range migration.Unsupported {
+			fmt.Printf("  - %s\n", note)
+		}
+	}
+
+	if state == nil {
+		fmt.Println()
+		fmt.Println("Next steps:")
+		fmt.Printf("  1. Review %s\n", outputPath)
+		fmt.Println("  2. Preview the cluster: k8zner plan")
+		fmt.Println("  3. Create it:           k8zner apply")
+		fmt.Println()
+		fmt.Println("To take over a running cluster instead of creating a new one, pass its state with --tfstate.")
+		return nil
+	}
+
+	resources := terraformResources(state)
+	fmt.Println()
+	fmt.Print(renderTerraformResources(resources))
+
+	missing, err := writeTerraformCredentials(state)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("Next steps:")
+	fmt.Printf("  1. Review %s\n", outputPath)
+	step := 2
+	for _, name := range missing {
+		fmt.Printf("  %d. Save the %s output: terraform output -raw %s > %s\n", step, name, name, workspacePath(name))
+		step++
+	}
+	importCmd := fmt.Sprintf("k8zner import --name %s --kubeconfig %s --talosconfig %s", migration.Spec.Name, workspacePath(kubeconfigPath), workspacePath(talosConfigPath))
+	fmt.Printf("  %d. Preview the import: %s --dry-run\n", step, importCmd)
+	fmt.Printf("  %d. Import the cluster: %s\n", step+1, importCmd)
+	fmt.Printf("  %d. Stop Terraform from managing it, so terraform destroy cannot delete it:\n", step+2)
+	for _, address := range terraformStateRemovals(resources) {
+		fmt.Printf("       terraform state rm '%s'\n", address)
+	}
+	return nil
+}
+
+// parseTerraformState parses a Terraform state file.
+func parseTerraformState(data []byte) (*terraformState, error) {
+	var state terraformState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse Terraform state: %w", err)
+	}
+	if state.Version != 4 {
+		return nil, fmt.Errorf("unsupported Terraform state version %d, pass the output of terraform state pull", state.Version)
+	}
+	return &state, nil
+}
+
+// terraformResources lists the managed resource instances of a state, with
+// the role k8zner import adopts the Hetzner ones as.
+func terraformResources(state *terraformState) []terraformResource {
+	var resources []terraformResource
+	for _, r := range state.Resources {
+		if r.Mode != "managed" {
+			continue
+		}
+		address := r.Type + "." + r.Name
+		if r.Module != "" {
+			address = r.Module + "." + address
+		}
+		for _, inst := range r.Instances {
+			res := terraformResource{
+				module:  r.Module,
+				address: address + terraformIndex(inst.IndexKey),
+				typ:     r.Type,
+			}
+			if id := inst.Attributes["id"]; id != nil {
+				res.id = fmt.Sprint(id)
+			}
+			res.name, _ = inst.Attributes["name"].(string)
+			labels, _ := inst.Attributes["labels"].(map[string]any)
+			res.role = terraformResourceRole(r.Type, r.Name, labels)
+			resources = append(resources, res)
+		}
+	}
+	return resources
+}
+
+func terraformIndex(key any) string {
+	switch k := key.(type) {
+	case nil:
+		return ""
+	case string:
+		return fmt.Sprintf("[%q]", k)
+	default:
+		return fmt.Sprintf("[%v]", k)
+	}
+}
+
+// terraformResourceRole tells from the resource type, name and labels what
+// k8zner import adopts a resource as. Subnets, attachments and load balancer
+// services go with the resource they belong to.
+func terraformResourceRole(typ, name string, labels map[string]any) string {
+	role, _ := labels["role"].(string)
+	switch {
+	case strings.HasPrefix(typ, "hcloud_server"):
+		switch {
+		case role == "control-plane" || strings.Contains(name, "control"):
+			return tfRoleControlPlane
+		case role == "worker" || strings.Contains(name, "worker"):
+			return tfRoleWorker
+		}
+	case strings.HasPrefix(typ, "hcloud_network"):
+		return tfRoleNetwork
+	case strings.HasPrefix(typ, "hcloud_firewall"):
+		return tfRoleFirewall
+	case strings.HasPrefix(typ, "hcloud_load_balancer"):
+		if role == "kube-api" || strings.Contains(name, "kube_api") || strings.Contains(name, "kube-api") {
+			return tfRoleKubeAPI
+		}
+	}
+	return ""
+}
+
+// renderTerraformResources prints the Hetzner resources of the state with
+// what k8zner import does with them.
+func renderTerraformResources(resources []terraformResource) string {
+	var b bytes.Buffer
+	b.WriteString("Hetzner Cloud resources in the Terraform state:\n")
+	count := 0
+	for _, r := range resources {
+		if !strings.HasPrefix(r.typ, "hcloud_") {
+			continue
+		}
+		count++
+		role := r.role
+		if role == "" {
+			role = "left alone"
+		}
+		fmt.Fprintf(&b, "  %-18s %-10s %-32s %s\n", role, r.id, r.name, r.address)
+	}
+	if count == 0 {
+		b.WriteString("  none\n")
+	}
+	return b.String()
+}
+
+// writeTerraformCredentials writes the kubeconfig and talosconfig outputs of
+// the state to workDir for k8zner import, and returns the ones the state has
+// no output for. Existing files are kept.
+func writeTerraformCredentials(state *terraformState) ([]string, error) {
+	var missing []string
+	for _, output := range []string{kubeconfigPath, talosConfigPath} {
+		value, _ := state.Outputs[output].Value.(string)
+		if value == "" {
+			missing = append(missing, output)
+			continue
+		}
+		name := workspacePath(output)
+		current, err := os.ReadFile(name)
+		switch {
+		case err == nil:
+			if !bytes.Equal(current, []byte(value)) {
+				fmt.Printf("Warning: %s already exists and differs from the Terraform output, it was not overwritten.\n", name)
+			}
+			continue
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if err := writeFile(name, []byte(value), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		fmt.Printf("Wrote %s from the Terraform output.\n", name)
+	}
+	return missing, nil
+}
+
+// terraformStateRemovals returns the addresses to remove from the Terraform
+// state once k8zner manages the cluster: whole modules, and the adopted and
+// talos resources declared outside of modules.
+func terraformStateRemovals(resources []terraformResource) []string {
+	seen := map[string]bool{}
+	var addresses []string
+	for _, r := range resources {
+		address, _, _ := strings.Cut(r.address, "[")
+		if r.module != "" {
+			// module.kubernetes, also for resources of nested modules
+			parts := strings.SplitN(r.module, ".", 3)
+			address = parts[0] + "." + parts[1]
+		} else if r.role == "" && !strings.HasPrefix(r.typ, "talos_") {
+			continue
+		}
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+	return addresses
+}
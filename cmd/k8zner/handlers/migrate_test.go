@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milankappen/k8zner/internal/config"
+)
+
+const migrateTestTFVars = `
+cluster_name = "prod"
+hcloud_token = "secret"
+longhorn_enabled = true
+
+control_plane_nodepools = [
+  { name = "control", type = "cx22", location = "fsn1", count = 3 }
+]
+worker_nodepools = [
+  { name = "workers", type = "cx32", location = "fsn1", count = 2 }
+]
+`
+
+const migrateTestTFState = `{
+  "version": 4,
+  "outputs": {
+    "kubeconfig": {"value": "apiVersion: v1\nkind: Config\n", "type": "string", "sensitive": true}
+  },
+  "resources": [
+    {"module": "module.kubernetes", "mode": "data", "type": "hcloud_image", "name": "x86", "instances": [{"attributes": {"id": "1"}}]},
+    {"module": "module.kubernetes", "mode": "managed", "type": "hcloud_network", "name": "this", "instances": [{"attributes": {"id": "10", "name": "prod"}}]},
+    {"module": "module.kubernetes", "mode": "managed", "type": "hcloud_firewall", "name": "this", "instances": [{"attributes": {"id": "20", "name": "prod"}}]},
+    {"module": "module.kubernetes", "mode": "managed", "type": "hcloud_load_balancer", "name": "kube_api", "instances": [{"attributes": {"id": "30", "name": "prod-kube-api"}}]},
+    {"module": "module.kubernetes", "mode": "managed", "type": "hcloud_load_balancer", "name": "ingress", "instances": [{"attributes": {"id": "31", "name": "prod-ingress"}}]},
+    {"module": "module.kubernetes", "mode": "managed", "type": "hcloud_server", "name": "control_plane", "instances": [
+      {"index_key": "prod-control-1", "attributes": {"id": "1", "name": "prod-control-1", "labels": {"role": "control-plane"}}}
+    ]},
+    {"module": "module.kubernetes", "mode": "managed", "type": "hcloud_server", "name": "worker", "instances": [
+      {"index_key": "prod-workers-1", "attributes": {"id": "2", "name": "prod-workers-1", "labels": {"role": "worker"}}}
+    ]},
+    {"module": "module.kubernetes", "mode": "managed", "type": "talos_machine_secrets", "name": "this", "instances": [{"attributes": {"id": "machine_secrets"}}]},
+    {"mode": "managed", "type": "hcloud_server", "name": "bastion_worker", "instances": [{"attributes": {"id": "3", "name": "prod-workers-2"}}]},
+    {"mode": "managed", "type": "hcloud_ssh_key", "name": "admin", "instances": [{"index_key": 0, "attributes": {"id": "40", "name": "admin"}}]},
+    {"mode": "managed", "type": "local_file", "name": "kubeconfig", "instances": [{"attributes": {"id": "abc"}}]}
+  ]
+}`
+
+// Serial: changes directory.
+func TestMigrateTerraform(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("terraform.tfvars", []byte(migrateTestTFVars), 0600))
+	require.NoError(t, os.WriteFile("terraform.tfstate", []byte(migrateTestTFState), 0600))
+
+	require.NoError(t, MigrateTerraform("terraform.tfvars", "terraform.tfstate", "k8zner.yaml"))
+
+	spec, err := config.LoadSpecWithoutValidation("k8zner.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", spec.Name)
+	assert.Equal(t, config.ModeHA, spec.Mode)
+	assert.Equal(t, []config.WorkerPoolSpec{{Name: "workers", Count: 2, Size: config.SizeCX32}}, spec.WorkerPools)
+
+	kubeconfig, err := os.ReadFile(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: Config\n", string(kubeconfig))
+	assert.NoFileExists(t, talosConfigPath)
+}
+
+// Serial: changes directory.
+func TestMigrateTerraform_KeepsExistingKubeconfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("terraform.tfvars", []byte(migrateTestTFVars), 0600))
+	require.NoError(t, os.WriteFile("terraform.tfstate", []byte(migrateTestTFState), 0600))
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte("other"), 0600))
+
+	require.NoError(t, MigrateTerraform("terraform.tfvars", "terraform.tfstate", "k8zner.yaml"))
+
+	kubeconfig, err := os.ReadFile(kubeconfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, "other", string(kubeconfig))
+}
+
+// Serial: changes directory.
+func TestMigrateTerraform_Errors(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("terraform.tfvars", []byte(migrateTestTFVars), 0600))
+	require.NoError(t, os.WriteFile("old.tfstate", []byte(`{"version": 3}`), 0600))
+	require.NoError(t, os.WriteFile("broken.tfvars", []byte("cluster_name = var.name\n"), 0600))
+
+	err := MigrateTerraform("terraform.tfvars", "old.tfstate", "k8zner.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported Terraform state version 3")
+	assert.NoFileExists(t, "k8zner.yaml")
+
+	err = MigrateTerraform("broken.tfvars", "", "k8zner.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a literal value")
+
+	err = MigrateTerraform("missing.tfvars", "", "k8zner.yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read tfvars")
+}
+
+func TestTerraformResources(t *testing.T) {
+	t.Parallel()
+
+	state, err := parseTerraformState([]byte(migrateTestTFState))
+	require.NoError(t, err)
+	resources := terraformResources(state)
+
+	roles := map[string]string{}
+	for _, r := range resources {
+		roles[r.address] = r.role
+	}
+	assert.Equal(t, map[string]string{
+		"module.kubernetes.hcloud_network.this":                           tfRoleNetwork,
+		"module.kubernetes.hcloud_firewall.this":                          tfRoleFirewall,
+		"module.kubernetes.hcloud_load_balancer.kube_api":                 tfRoleKubeAPI,
+		"module.kubernetes.hcloud_load_balancer.ingress":                  "",
+		`module.kubernetes.hcloud_server.control_plane["prod-control-1"]`: tfRoleControlPlane,
+		`module.kubernetes.hcloud_server.worker["prod-workers-1"]`:        tfRoleWorker,
+		"module.kubernetes.talos_machine_secrets.this":                    "",
+		"hcloud_server.bastion_worker":                                    tfRoleWorker,
+		"hcloud_ssh_key.admin[0]":                                         "",
+		"local_file.kubeconfig":                                           "",
+	}, roles)
+
+	out := renderTerraformResources(resources)
+	assert.Contains(t, out, "API load balancer  30         prod-kube-api")
+	assert.Contains(t, out, "left alone         31         prod-ingress")
+	assert.NotContains(t, out, "talos_machine_secrets")
+}
+
+func TestTerraformStateRemovals(t *testing.T) {
+	t.Parallel()
+
+	state, err := parseTerraformState([]byte(migrateTestTFState))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"hcloud_server.bastion_worker", "module.kubernetes"}, terraformStateRemovals(terraformResources(state)))
+}
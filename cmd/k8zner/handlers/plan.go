@@ -0,0 +1,656 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+	hcloudInternal "github.com/milankappen/k8zner/internal/platform/hcloud"
+	"github.com/milankappen/k8zner/internal/provisioning/infrastructure"
+	"github.com/milankappen/k8zner/internal/util/labels"
+	"github.com/milankappen/k8zner/internal/util/naming"
+)
+
+// Plan modes, telling whether apply would bootstrap or update the cluster.
+const (
+	planModeBootstrap = "bootstrap"
+	planModeUpdate    = "update"
+)
+
+// Actions on planned infrastructure. Drift marks a difference from the
+// config that apply leaves alone.
+const (
+	planActionCreate    = "create"
+	planActionUpdate    = "update"
+	planActionReplace   = "replace"
+	planActionDelete    = "delete"
+	planActionUnchanged = "unchanged"
+	planActionDrift     = "drift"
+)
+
+// planFieldChange is a K8znerCluster spec field apply would change. From is
+// unset for added fields and To for removed ones.
+type planFieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from,omitempty"`
+	To    any    `json:"to,omitempty"`
+}
+
+// planResource is a Hetzner Cloud resource apply or the operator would touch.
+type planResource struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// applyPlan is what 'k8zner apply' would do with the current config.
+type applyPlan struct {
+	Cluster        string            `json:"cluster"`
+	Mode           string            `json:"mode"`
+	Changed        bool              `json:"changed"`
+	Changes        []planFieldChange `json:"changes"`
+	Infrastructure []planResource    `json:"infrastructure"`
+	Actions        []string          `json:"actions"`
+	Cost           *costSummary      `json:"cost,omitempty"`
+	Warnings       []string          `json:"warnings,omitempty"`
+}
+
+// planServerGroup is a set of servers the operator scales and replaces together.
+type planServerGroup struct {
+	name  string
+	role  string
+	pool  string
+	size  string
+	count int
+}
+
+// Plan shows what apply would change without changing anything: the
+// K8znerCluster spec fields, the Hetzner Cloud resources, the resulting
+// operator actions and the monthly cost delta.
+func Plan(ctx context.Context, configPath string, jsonOutput bool) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	token := os.Getenv("HCLOUD_TOKEN")
+	if token == "" {
+		return fmt.Errorf("HCLOUD_TOKEN environment variable is required")
+	}
+
+	var plan *applyPlan
+	err = withStateLock(ctx, cfg, "plan", func(ctx context.Context) error {
+		live, warning := loadLiveCluster(ctx, cfg.ClusterName)
+		plan, err = buildApplyPlan(ctx, cfg, live, newInfraClient(token))
+		if err != nil {
+			return err
+		}
+		if warning != "" {
+			plan.Warnings = append([]string{warning}, plan.Warnings...)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if summary, err := buildCostSummary(ctx, cfg, defaultS3StorageGB); err != nil {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("cost estimate unavailable: %v", err))
+	} else {
+		plan.Cost = summary
+	}
+
+	if jsonOutput {
+		b, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	fmt.Print(renderApplyPlan(plan))
+	return nil
+}
+
+// loadLiveCluster returns the K8znerCluster apply would update, or nil when
+// apply would bootstrap. Like apply, an unreachable or unmanaged cluster
+// counts as new; the returned warning says why.
+func loadLiveCluster(ctx context.Context, clusterName string) (*k8znerv1alpha1.K8znerCluster, string) {
+	kubeconfig, err := localFiles.ReadFile(kubeconfigPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ""
+	}
+	if err != nil {
+		return nil, fmt.Sprintf("failed to read kubeconfig, apply would bootstrap: %v", err)
+	}
+	k8sClient, err := newClusterClient(kubeconfig)
+	if err != nil {
+		return nil, fmt.Sprintf("failed to create kubernetes client, apply would bootstrap: %v", err)
+	}
+
+	// Same timeout apply uses to look for the cluster
+	getCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cluster := &k8znerv1alpha1.K8znerCluster{}
+	err = k8sClient.Get(getCtx, client.ObjectKey{Namespace: k8znerNamespace, Name: clusterName}, cluster)
+	if apierrors.IsNotFound(err) {
+		return nil, ""
+	}
+	if err != nil {
+		return nil, fmt.Sprintf("cluster %s is not reachable, apply would bootstrap it: %v", clusterName, err)
+	}
+	if cluster.Spec.CredentialsRef.Name == "" {
+		return nil, fmt.Sprintf("cluster %s is not operator-managed, apply would bootstrap it", clusterName)
+	}
+	return cluster, ""
+}
+
+// buildApplyPlan compares cfg with the live cluster, or with nothing when
+// live is nil, and with the resources in the Hetzner Cloud project.
+func buildApplyPlan(ctx context.Context, cfg *config.Config, live *k8znerv1alpha1.K8znerCluster, infra hcloudInternal.InfrastructureManager) (*applyPlan, error) {
+	plan := &applyPlan{
+		Cluster:        cfg.ClusterName,
+		Changes:        []planFieldChange{},
+		Infrastructure: []planResource{},
+		Actions:        []string{},
+	}
+
+	var desired *k8znerv1alpha1.K8znerCluster
+	if live == nil {
+		plan.Mode = planModeBootstrap
+		desired = &k8znerv1alpha1.K8znerCluster{}
+		updateClusterSpecFromConfig(desired, cfg)
+		plan.Actions = bootstrapActions(cfg, desired)
+	} else {
+		plan.Mode = planModeUpdate
+		desired = live.DeepCopy()
+		updateClusterSpecFromConfig(desired, cfg)
+
+		changes, err := diffClusterSpecs(live.Spec, desired.Spec)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = changes
+		plan.Actions = operatorActions(live, desired)
+		plan.Warnings = specWarnings(live, desired)
+
+		drift, err := specDrift(live, desired)
+		if err != nil {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("skipping the drift check: %v", err))
+		}
+		plan.Warnings = append(plan.Warnings, driftWarnings(drift)...)
+	}
+
+	resources, err := planInfrastructure(ctx, infra, cfg, desired, plan.Mode)
+	if err != nil {
+		return nil, err
+	}
+	plan.Infrastructure = resources
+
+	plan.Changed = len(plan.Changes) > 0 || len(plan.Actions) > 0
+	for _, r := range plan.Infrastructure {
+		if r.Action != planActionUnchanged && r.Action != planActionDrift {
+			plan.Changed = true
+		}
+	}
+	return plan, nil
+}
+
+// diffClusterSpecs lists the fields that differ between two specs by their
+// JSON path. List entries with a name are matched by name.
+func diffClusterSpecs(from, to k8znerv1alpha1.K8znerClusterSpec) ([]planFieldChange, error) {
+	fromValue, err := toJSONValue(from)
+	if err != nil {
+		return nil, err
+	}
+	toValue, err := toJSONValue(to)
+	if err != nil {
+		return nil, err
+	}
+	changes := []planFieldChange{}
+	diffJSONValues("spec", fromValue, toValue, &changes)
+	return changes, nil
+}
+
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cluster spec: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster spec: %w", err)
+	}
+	return out, nil
+}
+
+func diffJSONValues(path string, from, to any, changes *[]planFieldChange) {
+	if reflect.DeepEqual(from, to) || (isEmptyJSONValue(from) && isEmptyJSONValue(to)) {
+		return
+	}
+
+	fromMap, fromIsMap := from.(map[string]any)
+	toMap, toIsMap := to.(map[string]any)
+	if fromIsMap && toIsMap {
+		keys := make(map[string]bool)
+		for k := range fromMap {
+			keys[k] = true
+		}
+		for k := range toMap {
+			keys[k] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			diffJSONValues(path+"."+k, fromMap[k], toMap[k], changes)
+		}
+		return
+	}
+
+	fromNamed, fromOK := namedEntries(from)
+	toNamed, toOK := namedEntries(to)
+	if fromOK && toOK {
+		keys := make(map[string]bool)
+		for k := range fromNamed {
+			keys[k] = true
+		}
+		for k := range toNamed {
+			keys[k] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			diffJSONValues(fmt.Sprintf("%s[name=%s]", path, k), fromNamed[k], toNamed[k], changes)
+		}
+		return
+	}
+
+	*changes = append(*changes, planFieldChange{Field: path, From: from, To: to})
+}
+
+// isEmptyJSONValue reports whether v is missing or an empty object or list,
+// which encode the same spec.
+func isEmptyJSONValue(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// namedEntries indexes a list of objects by their name field. Missing lists
+// count as empty; other values are not indexed.
+func namedEntries(v any) (map[string]any, bool) {
+	if v == nil {
+		return map[string]any{}, true
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	entries := make(map[string]any, len(list))
+	for _, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, ok := obj["name"].(string)
+		if !ok || name == "" {
+			return nil, false
+		}
+		entries[name] = obj
+	}
+	return entries, true
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// bootstrapActions describes how apply builds a new cluster.
+func bootstrapActions(cfg *config.Config, desired *k8znerv1alpha1.K8znerCluster) []string {
+	actions := []string{
+		"Create the network, firewall and Kubernetes API load balancer",
+		fmt.Sprintf("Bootstrap the first control plane with Talos %s and Kubernetes %s", cfg.Talos.Version, cfg.Kubernetes.Version),
+		"Install the k8zner operator and create the K8znerCluster",
+	}
+	if extra := desired.Spec.ControlPlanes.Count - 1; extra > 0 {
+		actions = append(actions, fmt.Sprintf("The operator adds %s", countNoun(extra, "control plane")))
+	}
+	for _, group := range planServerGroups(desired.Spec)[1:] {
+		if group.count > 0 {
+			actions = append(actions, fmt.Sprintf("The operator creates worker pool %s with %s (%s)",
+				group.pool, countNoun(group.count, "worker"), group.size))
+		}
+	}
+	return actions
+}
+
+// operatorActions describes what the operator does to get from the live
+// spec to the desired one.
+func operatorActions(live, desired *k8znerv1alpha1.K8znerCluster) []string {
+	actions := []string{}
+	groups := planServerGroups(desired.Spec)
+	liveGroups := make(map[string]planServerGroup)
+	for _, group := range planServerGroups(live.Spec) {
+		liveGroups[group.name] = group
+	}
+
+	for _, group := range groups {
+		old, ok := liveGroups[group.name]
+		if !ok {
+			actions = append(actions, fmt.Sprintf("Worker pool %s will be created with %s (%s)",
+				group.pool, countNoun(group.count, "worker"), group.size))
+			continue
+		}
+		noun := "worker"
+		if group.role == "control-plane" {
+			noun = "control plane"
+		}
+		if replaced := min(old.count, group.count); old.size != group.size && replaced > 0 {
+			actions = append(actions, fmt.Sprintf("%s will be replaced one at a time (%s: size change %s → %s)",
+				countNoun(replaced, noun), group.name, old.size, group.size))
+		}
+		switch {
+		case group.count > old.count:
+			actions = append(actions, fmt.Sprintf("%s will be added (%s: %d → %d)",
+				countNoun(group.count-old.count, noun), group.name, old.count, group.count))
+		case group.count < old.count && group.role == "control-plane":
+			actions = append(actions, fmt.Sprintf("%s will be removed one etcd member at a time (%s: %d → %d)",
+				countNoun(old.count-group.count, noun), group.name, old.count, group.count))
+		case group.count < old.count:
+			actions = append(actions, fmt.Sprintf("%s will be drained and removed (%s: %d → %d)",
+				countNoun(old.count-group.count, noun), group.name, old.count, group.count))
+		}
+	}
+	desiredNames := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		desiredNames[group.name] = true
+	}
+	for _, old := range planServerGroups(live.Spec) {
+		if !desiredNames[old.name] && old.count > 0 {
+			actions = append(actions, fmt.Sprintf("Worker pool %s will be removed after draining its %s",
+				old.pool, countNoun(old.count, "worker")))
+		}
+	}
+
+	from, to := live.Spec, desired.Spec
+	if from.Talos.Version != to.Talos.Version {
+		nodes := 0
+		for _, group := range groups {
+			nodes += group.count
+		}
+		actions = append(actions, fmt.Sprintf("%s will be upgraded from Talos %s to %s, rebooting one at a time",
+			countNoun(nodes, "node"), from.Talos.Version, to.Talos.Version))
+	}
+	if from.Kubernetes.Version != to.Kubernetes.Version {
+		actions = append(actions, fmt.Sprintf("Kubernetes will be upgraded from %s to %s",
+			from.Kubernetes.Version, to.Kubernetes.Version))
+	}
+
+	fromBackup := from.Backup != nil && from.Backup.Enabled
+	toBackup := to.Backup != nil && to.Backup.Enabled
+	switch {
+	case toBackup && !fromBackup:
+		actions = append(actions, "etcd backups will be enabled")
+	case fromBackup && !toBackup:
+		actions = append(actions, "etcd backups will be disabled")
+	}
+	return actions
+}
+
+// specWarnings points out changes apply writes to the spec but the operator
+// does not act on.
+func specWarnings(live, desired *k8znerv1alpha1.K8znerCluster) []string {
+	var warnings []string
+	from, to := live.Spec.Network, desired.Spec.Network
+	if from.IPv4CIDR != to.IPv4CIDR || from.PodCIDR != to.PodCIDR || from.ServiceCIDR != to.ServiceCIDR {
+		warnings = append(warnings, "network ranges of an existing cluster cannot be changed")
+	}
+	if live.Spec.Talos.Version == desired.Spec.Talos.Version &&
+		(live.Spec.Talos.SchematicID != desired.Spec.Talos.SchematicID ||
+			!reflect.DeepEqual(live.Spec.Talos.Extensions, desired.Spec.Talos.Extensions)) {
+		warnings = append(warnings, "the new Talos image is only used for new nodes and the next Talos upgrade")
+	}
+	return warnings
+}
+
+// planServerGroups returns the control planes and the worker pools of spec,
+// with worker pools built the same way the operator does.
+func planServerGroups(spec k8znerv1alpha1.K8znerClusterSpec) []planServerGroup {
+	groups := []planServerGroup{{
+		name:  "control planes",
+		role:  "control-plane",
+		pool:  "control-plane",
+		size:  normalizedSize(spec.ControlPlanes.Size),
+		count: spec.ControlPlanes.Count,
+	}}
+
+	pools := spec.WorkerPools
+	if len(pools) == 0 {
+		pools = []k8znerv1alpha1.WorkerPoolSpec{{
+			Name:  k8znerv1alpha1.DefaultWorkerPoolName,
+			Count: spec.Workers.Count,
+			Size:  spec.Workers.Size,
+		}}
+	}
+	for _, pool := range pools {
+		groups = append(groups, planServerGroup{
+			name:  "worker pool " + pool.Name,
+			role:  "worker",
+			pool:  pool.Name,
+			size:  normalizedSize(pool.Size),
+			count: pool.Count,
+		})
+	}
+	return groups
+}
+
+func normalizedSize(size string) string {
+	return string(config.ServerSize(size).Normalize())
+}
+
+// countNoun returns "1 worker" or "2 workers".
+func countNoun(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// planInfrastructure compares the network, firewall, API load balancer and
+// servers of the cluster with what cfg and the desired spec ask for.
+func planInfrastructure(ctx context.Context, infra hcloudInternal.InfrastructureManager, cfg *config.Config, desired *k8znerv1alpha1.K8znerCluster, mode string) ([]planResource, error) {
+	resources := []planResource{}
+
+	network, err := infra.GetNetwork(ctx, cfg.ClusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network: %w", err)
+	}
+	switch {
+	case network == nil:
+		resources = append(resources, planResource{Kind: "network", Name: cfg.ClusterName, Action: planActionCreate, Detail: cfg.Network.IPv4CIDR})
+	case network.IPRange != nil && network.IPRange.String() != cfg.Network.IPv4CIDR:
+		resources = append(resources, planResource{Kind: "network", Name: cfg.ClusterName, Action: planActionDrift,
+			Detail: fmt.Sprintf("ip range is %s, config has %s", network.IPRange, cfg.Network.IPv4CIDR)})
+	default:
+		resources = append(resources, planResource{Kind: "network", Name: cfg.ClusterName, Action: planActionUnchanged, Detail: cfg.Network.IPv4CIDR})
+	}
+
+	firewall, err := planFirewall(ctx, infra, cfg, mode)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, firewall)
+
+	lbName := naming.KubeAPILoadBalancer(cfg.ClusterName)
+	lb, err := infra.GetLoadBalancer(ctx, lbName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get load balancer: %w", err)
+	}
+	if lb == nil {
+		resources = append(resources, planResource{Kind: "load balancer", Name: lbName, Action: planActionCreate,
+			Detail: fmt.Sprintf("%s in %s", config.LoadBalancerType, cfg.Location)})
+	} else {
+		resources = append(resources, planResource{Kind: "load balancer", Name: lbName, Action: planActionUnchanged})
+	}
+
+	servers, err := infra.GetServersByLabel(ctx, map[string]string{labels.KeyCluster: cfg.ClusterName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+	resources = append(resources, planServers(planServerGroups(desired.Spec), servers)...)
+	return resources, nil
+}
+
+// planFirewall compares the firewall rules with the ones provisioning would
+// set. Apply only sets them while bootstrapping, so on an existing cluster a
+// difference is reported as drift.
+func planFirewall(ctx context.Context, infra hcloudInternal.InfrastructureManager, cfg *config.Config, mode string) (planResource, error) {
+	publicIP := ""
+	if cfg.Firewall.UseCurrentIPv4 != nil && *cfg.Firewall.UseCurrentIPv4 {
+		ip, err := infra.GetPublicIP(ctx)
+		if err != nil {
+			return planResource{}, fmt.Errorf("failed to detect public IP: %w", err)
+		}
+		publicIP = ip
+	}
+	rules := infrastructure.FirewallRules(cfg, publicIP)
+
+	fw, err := infra.GetFirewall(ctx, cfg.ClusterName)
+	if err != nil {
+		return planResource{}, fmt.Errorf("failed to get firewall: %w", err)
+	}
+	resource := planResource{Kind: "firewall", Name: cfg.ClusterName, Detail: countNoun(len(rules), "rule")}
+	switch {
+	case fw == nil:
+		resource.Action = planActionCreate
+	case firewallRulesEqual(fw.Rules, rules):
+		resource.Action = planActionUnchanged
+	case mode == planModeBootstrap:
+		resource.Action = planActionUpdate
+		resource.Detail = fmt.Sprintf("%s → %s", countNoun(len(fw.Rules), "rule"), countNoun(len(rules), "rule"))
+	default:
+		resource.Action = planActionDrift
+		resource.Detail = "rules differ from the config; apply does not change the firewall of an existing cluster"
+	}
+	return resource, nil
+}
+
+// firewallRulesEqual compares two rule sets regardless of order.
+func firewallRulesEqual(a, b []hcloud.FirewallRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	keys := func(rules []hcloud.FirewallRule) []string {
+		out := make([]string, 0, len(rules))
+		for _, r := range rules {
+			port := ""
+			if r.Port != nil {
+				port = *r.Port
+			}
+			var sources, destinations []string
+			for _, n := range r.SourceIPs {
+				sources = append(sources, n.String())
+			}
+			for _, n := range r.DestinationIPs {
+				destinations = append(destinations, n.String())
+			}
+			sort.Strings(sources)
+			sort.Strings(destinations)
+			out = append(out, fmt.Sprintf("%s/%s/%s/%s/%s", r.Direction, r.Protocol, port,
+				strings.Join(sources, ","), strings.Join(destinations, ",")))
+		}
+		sort.Strings(out)
+		return out
+	}
+	return reflect.DeepEqual(keys(a), keys(b))
+}
+
+// planServers compares the servers of each group with its desired count and
+// server type. Servers of groups that are no longer desired are deleted.
+func planServers(groups []planServerGroup, servers []*hcloud.Server) []planResource {
+	// Servers are grouped by group name; servers k8zner did not label with a
+	// role are left out
+	existing := make(map[string][]*hcloud.Server)
+	for _, srv := range servers {
+		switch srv.Labels[labels.KeyRole] {
+		case "control-plane":
+			existing[groups[0].name] = append(existing[groups[0].name], srv)
+		case "worker":
+			name := "worker pool " + srv.Labels[labels.KeyPool]
+			existing[name] = append(existing[name], srv)
+		}
+	}
+
+	resources := []planResource{}
+	for _, group := range groups {
+		current := existing[group.name]
+		delete(existing, group.name)
+		if group.count == 0 && len(current) == 0 {
+			continue
+		}
+
+		outdated := 0
+		for _, srv := range current {
+			if srv.ServerType != nil && normalizedSize(srv.ServerType.Name) != group.size {
+				outdated++
+			}
+		}
+
+		changed := false
+		if n := group.count - len(current); n > 0 {
+			resources = append(resources, planResource{Kind: "servers", Name: group.name, Action: planActionCreate,
+				Detail: fmt.Sprintf("%d × %s", n, group.size)})
+			changed = true
+		}
+		if n := len(current) - group.count; n > 0 {
+			resources = append(resources, planResource{Kind: "servers", Name: group.name, Action: planActionDelete,
+				Detail: fmt.Sprintf("%d of %d", n, len(current))})
+			changed = true
+		}
+		if n := min(outdated, group.count); n > 0 {
+			resources = append(resources, planResource{Kind: "servers", Name: group.name, Action: planActionReplace,
+				Detail: fmt.Sprintf("%s → %s", countNoun(n, "server"), group.size)})
+			changed = true
+		}
+		if !changed {
+			resources = append(resources, planResource{Kind: "servers", Name: group.name, Action: planActionUnchanged,
+				Detail: fmt.Sprintf("%d × %s", group.count, group.size)})
+		}
+	}
+
+	for _, name := range sortedServerKeys(existing) {
+		resources = append(resources, planResource{Kind: "servers", Name: name, Action: planActionDelete,
+			Detail: countNoun(len(existing[name]), "server")})
+	}
+	return resources
+}
+
+func sortedServerKeys(m map[string][]*hcloud.Server) []string {
+	keys := make(map[string]bool, len(m))
+	for k := range m {
+		keys[k] = true
+	}
+	return sortedKeys(keys)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// renderApplyPlan produces a lipgloss-styled plan in the look of the cost summary.
+func renderApplyPlan(plan *applyPlan) string {
+	var b strings.Builder
+
+	b.WriteString("\n")
+	b.WriteString(costTitleStyle.Render(fmt.Sprintf("  k8zner plan: %s", plan.Cluster)))
+	b.WriteString("\n")
+	b.WriteString(costDimStyle.Render("  " + strings.Repeat("═", 30)))
+	b.WriteString("\n")
+	if plan.Mode == planModeBootstrap {
+		b.WriteString("  The cluster does not exist yet; apply will bootstrap it.\n")
+	} else {
+		b.WriteString("  The cluster exists; apply will update its K8znerCluster spec.\n")
+	}
+
+	if len(plan.Changes) > 0 {
+		renderPlanSection(&b, "Spec Changes")
+		for _, c := range plan.Changes {
+			switch {
+			case c.From == nil:
+				b.WriteString(costGreenStyle.Render(fmt.Sprintf("    + %s: %s", c.Field, formatPlanValue(c.To))))
+			case c.To == nil:
+				b.WriteString(costRedStyle.Render(fmt.Sprintf("    - %s: %s", c.Field, formatPlanValue(c.From))))
+			default:
+				fmt.Fprintf(&b, "    ~ %s: %s → %s", c.Field, formatPlanValue(c.From), formatPlanValue(c.To))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(plan.Infrastructure) > 0 {
+		renderPlanSection(&b, "Infrastructure")
+		for _, r := range plan.Infrastructure {
+			line := fmt.Sprintf("    %-9s %-14s %-24s %s", r.Action, r.Kind, r.Name, r.Detail)
+			switch r.Action {
+			case planActionCreate:
+				b.WriteString(costGreenStyle.Render(line))
+			case planActionDelete, planActionReplace:
+				b.WriteString(costRedStyle.Render(line))
+			case planActionUnchanged:
+				b.WriteString(costDimStyle.Render(line))
+			default:
+				b.WriteString(line)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(plan.Actions) > 0 {
+		renderPlanSection(&b, "Operator Actions")
+		for _, action := range plan.Actions {
+			fmt.Fprintf(&b, "    • %s\n", action)
+		}
+	}
+
+	if plan.Cost != nil {
+		renderPlanSection(&b, "Monthly Cost")
+		fmt.Fprintf(&b, "    Current:   %s %7.2f /mo net\n", plan.Cost.Currency, plan.Cost.CurrentTotal.MonthlyNet)
+		fmt.Fprintf(&b, "    Planned:   %s %7.2f /mo net\n", plan.Cost.Currency, plan.Cost.PlannedTotal.MonthlyNet)
+		b.WriteString("    Delta:     ")
+		b.WriteString(formatDelta(plan.Cost.DiffTotal.MonthlyNet, plan.Cost.Currency))
+		b.WriteString("\n")
+	}
+
+	if len(plan.Warnings) > 0 {
+		renderPlanSection(&b, "Warnings")
+		for _, warning := range plan.Warnings {
+			b.WriteString(costRedStyle.Render("    ! " + warning))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	if plan.Changed {
+		b.WriteString(costDimStyle.Render("  Run 'k8zner apply' to make these changes."))
+	} else {
+		b.WriteString(costDimStyle.Render("  No changes. The cluster matches the configuration."))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+func renderPlanSection(b *strings.Builder, title string) {
+	b.WriteString("\n")
+	b.WriteString(costSectionStyle.Render("  " + title))
+	b.WriteString("\n")
+	b.WriteString(costDimStyle.Render("  " + strings.Repeat("─", 50)))
+	b.WriteString("\n")
+}
+
+// formatPlanValue prints strings as they are and everything else as JSON.
+func formatPlanValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
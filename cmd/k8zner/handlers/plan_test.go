@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	hcloudgo "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/hcloud"
+	"github.com/milankappen/k8zner/internal/provisioning/infrastructure"
+	"github.com/milankappen/k8zner/internal/util/labels"
+)
+
+func newPlanTestConfig() *config.Config {
+	return &config.Config{
+		ClusterName: "test",
+		Location:    "fsn1",
+		ControlPlane: config.ControlPlaneConfig{
+			NodePools: []config.ControlPlaneNodePool{{Count: 3, ServerType: "cx33"}},
+		},
+		Workers: []config.WorkerNodePool{
+			{Name: "default", Count: 3, ServerType: "cx43"},
+		},
+		Kubernetes: config.KubernetesConfig{Version: "1.31.0"},
+		Talos:      config.TalosConfig{Version: "v1.9.0"},
+		Network:    config.NetworkConfig{IPv4CIDR: "10.0.0.0/16"},
+	}
+}
+
+// newPlanTestLiveConfig returns the config the cluster was applied with
+// before it grew a worker and moved to bigger workers and a newer Talos.
+func newPlanTestLiveConfig() *config.Config {
+	cfg := newPlanTestConfig()
+	cfg.Workers[0].Count = 2
+	cfg.Workers[0].ServerType = "cx33"
+	cfg.Talos.Version = "v1.8.3"
+	return cfg
+}
+
+func newPlanTestServer(role, pool, serverType string) *hcloudgo.Server {
+	return &hcloudgo.Server{
+		Labels:     map[string]string{labels.KeyCluster: "test", labels.KeyRole: role, labels.KeyPool: pool},
+		ServerType: &hcloudgo.ServerType{Name: serverType},
+	}
+}
+
+func TestBuildApplyPlan_Bootstrap(t *testing.T) {
+	t.Parallel()
+
+	plan, err := buildApplyPlan(context.Background(), newPlanTestConfig(), nil, &hcloud.MockClient{})
+	require.NoError(t, err)
+
+	assert.Equal(t, planModeBootstrap, plan.Mode)
+	assert.True(t, plan.Changed)
+	assert.Empty(t, plan.Changes)
+	assert.Contains(t, plan.Actions, "The operator adds 2 control planes")
+	assert.Contains(t, plan.Actions, "The operator creates worker pool default with 3 workers (cx43)")
+
+	assert.Equal(t, []planResource{
+		{Kind: "network", Name: "test", Action: planActionCreate, Detail: "10.0.0.0/16"},
+		{Kind: "firewall", Name: "test", Action: planActionCreate, Detail: "0 rules"},
+		{Kind: "load balancer", Name: "test-kube", Action: planActionCreate, Detail: "lb11 in fsn1"},
+		{Kind: "servers", Name: "control planes", Action: planActionCreate, Detail: "3 × cx33"},
+		{Kind: "servers", Name: "worker pool default", Action: planActionCreate, Detail: "3 × cx43"},
+	}, plan.Infrastructure)
+}
+
+func TestBuildApplyPlan_Update(t *testing.T) {
+	t.Parallel()
+
+	cfg := newPlanTestConfig()
+	_, ipRange, err := net.ParseCIDR("10.0.0.0/16")
+	require.NoError(t, err)
+	infra := &hcloud.MockClient{
+		GetNetworkFunc: func(_ context.Context, name string) (*hcloudgo.Network, error) {
+			return &hcloudgo.Network{Name: name, IPRange: ipRange}, nil
+		},
+		GetFirewallFunc: func(_ context.Context, name string) (*hcloudgo.Firewall, error) {
+			return &hcloudgo.Firewall{Name: name, Rules: infrastructure.FirewallRules(cfg, "")}, nil
+		},
+		GetLoadBalancerFunc: func(_ context.Context, name string) (*hcloudgo.LoadBalancer, error) {
+			return &hcloudgo.LoadBalancer{Name: name}, nil
+		},
+		GetServersByLabelFunc: func(_ context.Context, _ map[string]string) ([]*hcloudgo.Server, error) {
+			return []*hcloudgo.Server{
+				newPlanTestServer("control-plane", "control-plane", "cx33"),
+				newPlanTestServer("control-plane", "control-plane", "cx33"),
+				newPlanTestServer("control-plane", "control-plane", "cx33"),
+				newPlanTestServer("worker", "default", "cx33"),
+				newPlanTestServer("worker", "default", "cx33"),
+				newPlanTestServer("worker", "old", "cx23"),
+			}, nil
+		},
+	}
+
+	plan, err := buildApplyPlan(context.Background(), cfg, newTestCluster("test", newPlanTestLiveConfig()), infra)
+	require.NoError(t, err)
+
+	assert.Equal(t, planModeUpdate, plan.Mode)
+	assert.True(t, plan.Changed)
+	assert.Equal(t, []planFieldChange{
+		{Field: "spec.talos.version", From: "v1.8.3", To: "v1.9.0"},
+		{Field: "spec.workerPools[name=default].count", From: float64(2), To: float64(3)},
+		{Field: "spec.workerPools[name=default].size", From: "cx33", To: "cx43"},
+		{Field: "spec.workers.count", From: float64(2), To: float64(3)},
+		{Field: "spec.workers.size", From: "cx33", To: "cx43"},
+	}, plan.Changes)
+	assert.Equal(t, []string{
+		"2 workers will be replaced one at a time (worker pool default: size change cx33 → cx43)",
+		"1 worker will be added (worker pool default: 2 → 3)",
+		"6 nodes will be upgraded from Talos v1.8.3 to v1.9.0, rebooting one at a time",
+	}, plan.Actions)
+	assert.Equal(t, []planResource{
+		{Kind: "network", Name: "test", Action: planActionUnchanged, Detail: "10.0.0.0/16"},
+		{Kind: "firewall", Name: "test", Action: planActionUnchanged, Detail: "0 rules"},
+		{Kind: "load balancer", Name: "test-kube", Action: planActionUnchanged},
+		{Kind: "servers", Name: "control planes", Action: planActionUnchanged, Detail: "3 × cx33"},
+		{Kind: "servers", Name: "worker pool default", Action: planActionCreate, Detail: "1 × cx43"},
+		{Kind: "servers", Name: "worker pool default", Action: planActionReplace, Detail: "2 servers → cx43"},
+		{Kind: "servers", Name: "worker pool old", Action: planActionDelete, Detail: "1 server"},
+	}, plan.Infrastructure)
+}
+
+func TestBuildApplyPlan_NoChanges(t *testing.T) {
+	t.Parallel()
+
+	cfg := newPlanTestConfig()
+	live := &k8znerv1alpha1.K8znerCluster{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+	updateClusterSpecFromConfig(live, cfg)
+	infra := &hcloud.MockClient{
+		GetNetworkFunc: func(_ context.Context, name string) (*hcloudgo.Network, error) {
+			return &hcloudgo.Network{Name: name}, nil
+		},
+		GetFirewallFunc: func(_ context.Context, name string) (*hcloudgo.Firewall, error) {
+			return &hcloudgo.Firewall{Name: name, Rules: []hcloudgo.FirewallRule{{Direction: hcloudgo.FirewallRuleDirectionIn}}}, nil
+		},
+		GetLoadBalancerFunc: func(_ context.Context, name string) (*hcloudgo.LoadBalancer, error) {
+			return &hcloudgo.LoadBalancer{Name: name}, nil
+		},
+		GetServersByLabelFunc: func(_ context.Context, _ map[string]string) ([]*hcloudgo.Server, error) {
+			return []*hcloudgo.Server{
+				newPlanTestServer("control-plane", "control-plane", "cx33"),
+				newPlanTestServer("control-plane", "control-plane", "cx33"),
+				newPlanTestServer("control-plane", "control-plane", "cx33"),
+				newPlanTestServer("worker", "default", "cx43"),
+				newPlanTestServer("worker", "default", "cx43"),
+				newPlanTestServer("worker", "default", "cx43"),
+			}, nil
+		},
+	}
+
+	plan, err := buildApplyPlan(context.Background(), cfg, live, infra)
+	require.NoError(t, err)
+
+	assert.False(t, plan.Changed, "firewall drift is not a change apply makes")
+	assert.Empty(t, plan.Changes)
+	assert.Empty(t, plan.Actions)
+	assert.Equal(t, planActionDrift, plan.Infrastructure[1].Action)
+}
+
+func TestOperatorActions(t *testing.T) {
+	t.Parallel()
+
+	live := &k8znerv1alpha1.K8znerCluster{Spec: k8znerv1alpha1.K8znerClusterSpec{
+		ControlPlanes: k8znerv1alpha1.ControlPlaneSpec{Count: 3, Size: "cx22"},
+		WorkerPools: []k8znerv1alpha1.WorkerPoolSpec{
+			{Name: "a", Count: 2, Size: "cx33"},
+			{Name: "b", Count: 1, Size: "cx33"},
+		},
+		Kubernetes: k8znerv1alpha1.KubernetesSpec{Version: "1.30.0"},
+	}}
+	desired := live.DeepCopy()
+	desired.Spec.ControlPlanes.Count = 1
+	desired.Spec.ControlPlanes.Size = "cx23"
+	desired.Spec.WorkerPools = []k8znerv1alpha1.WorkerPoolSpec{
+		{Name: "a", Count: 1, Size: "cx33"},
+		{Name: "c", Count: 2, Size: "cx43"},
+	}
+	desired.Spec.Kubernetes.Version = "1.31.0"
+	desired.Spec.Backup = &k8znerv1alpha1.BackupSpec{Enabled: true}
+
+	assert.Equal(t, []string{
+		"2 control planes will be removed one etcd member at a time (control planes: 3 → 1)",
+		"1 worker will be drained and removed (worker pool a: 2 → 1)",
+		"Worker pool c will be created with 2 workers (cx43)",
+		"Worker pool b will be removed after draining its 1 worker",
+		"Kubernetes will be upgraded from 1.30.0 to 1.31.0",
+		"etcd backups will be enabled",
+	}, operatorActions(live, desired), "cx22 and cx23 are the same server type")
+}
+
+func TestDiffClusterSpecs_IgnoresEmptyValues(t *testing.T) {
+	t.Parallel()
+
+	from := k8znerv1alpha1.K8znerClusterSpec{}
+	to := k8znerv1alpha1.K8znerClusterSpec{Addons: &k8znerv1alpha1.AddonSpec{}}
+
+	changes, err := diffClusterSpecs(from, to)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestFirewallRulesEqual(t *testing.T) {
+	t.Parallel()
+
+	_, a, _ := net.ParseCIDR("10.0.0.0/8")
+	_, b, _ := net.ParseCIDR("192.168.0.0/16")
+	rule := func(sources ...net.IPNet) hcloudgo.FirewallRule {
+		return hcloudgo.FirewallRule{
+			Direction: hcloudgo.FirewallRuleDirectionIn,
+			Protocol:  hcloudgo.FirewallRuleProtocolTCP,
+			Port:      hcloudgo.Ptr("6443"),
+			SourceIPs: sources,
+		}
+	}
+
+	assert.True(t, firewallRulesEqual([]hcloudgo.FirewallRule{rule(*a, *b)}, []hcloudgo.FirewallRule{rule(*b, *a)}))
+	assert.False(t, firewallRulesEqual([]hcloudgo.FirewallRule{rule(*a)}, []hcloudgo.FirewallRule{rule(*b)}))
+}
+
+// Serial: swaps the package-level newClusterClient and changes directory.
+func TestLoadLiveCluster(t *testing.T) {
+	origClient := newClusterClient
+	t.Cleanup(func() { newClusterClient = origClient })
+	t.Chdir(t.TempDir())
+
+	live, warning := loadLiveCluster(context.Background(), "test")
+	assert.Nil(t, live, "no kubeconfig means a new cluster")
+	assert.Empty(t, warning)
+
+	require.NoError(t, writeLocalFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n")))
+	objects := []client.Object{}
+	newClusterClient = func([]byte) (client.Client, error) {
+		return fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).WithObjects(objects...).Build(), nil
+	}
+
+	live, warning = loadLiveCluster(context.Background(), "test")
+	assert.Nil(t, live)
+	assert.Empty(t, warning)
+
+	unmanaged := &k8znerv1alpha1.K8znerCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: k8znerNamespace}}
+	objects = []client.Object{unmanaged}
+	live, warning = loadLiveCluster(context.Background(), "test")
+	assert.Nil(t, live)
+	assert.Contains(t, warning, "not operator-managed")
+
+	objects = []client.Object{newTestCluster("test", newPlanTestLiveConfig())}
+	live, warning = loadLiveCluster(context.Background(), "test")
+	require.NotNil(t, live)
+	assert.Empty(t, warning)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/milankappen/k8zner/internal/backup"
+	"github.com/milankappen/k8zner/internal/config"
+)
+
+// newSnapshotRepository opens the backup bucket (for testing injection).
+var newSnapshotRepository = backup.NewRepositoryFromConfig
+
+// Restore rebuilds a cluster on fresh infrastructure from an etcd snapshot.
+//
+// The snapshot is fetched from the backup bucket and decrypted before any
+// infrastructure is created, so a wrong key or reference fails fast. The
+// first control plane then recovers etcd from the snapshot instead of
+// bootstrapping an empty cluster, and the operator takes over as after apply.
+func Restore(ctx context.Context, configPath, from, identityPath string, wait bool) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return withStateLock(ctx, cfg, "restore", func(ctx context.Context) error {
+		return restoreCluster(ctx, cfg, from, identityPath, wait)
+	})
+}
+
+// restoreCluster runs the restore once the state is locked, so secrets.yaml
+// may come from the state backend.
+func restoreCluster(ctx context.Context, cfg *config.Config, from, identityPath string, wait bool) error {
+	repo, err := newSnapshotRepository(cfg.Addons.TalosBackup)
+	if err != nil {
+		return fmt.Errorf("failed to open backup bucket: %w", err)
+	}
+
+	// Kubernetes secrets in the snapshot are encrypted at rest with keys from
+	// the Talos secrets bundle; freshly generated secrets could not read them
+	if !localFiles.Exists(secretsFile) {
+		return fmt.Errorf("restore requires %s of the original cluster", localFiles.Path(secretsFile))
+	}
+
+	token := os.Getenv("HCLOUD_TOKEN")
+	if token == "" {
+		return fmt.Errorf("HCLOUD_TOKEN environment variable is required")
+	}
+	if err := ensureClusterAbsent(ctx, cfg, token); err != nil {
+		return err
+	}
+
+	identities, err := loadAgeIdentities(identityPath)
+	if err != nil {
+		return err
+	}
+
+	snap, err := repo.Resolve(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to find snapshot: %w", err)
+	}
+	log.Printf("Restoring cluster %s from snapshot %s (taken %s, %d bytes)",
+		cfg.ClusterName, snap.Key, snap.CreatedAt.UTC().Format("2006-01-02 15:04:05 MST"), snap.Size)
+
+	snapshot, err := fetchSnapshot(ctx, repo, snap, identities)
+	if err != nil {
+		return err
+	}
+
+	kubeconfig, err := runBootstrapPipeline(ctx, cfg, wait, nil, snapshot)
+	if err != nil {
+		return err
+	}
+
+	printApplySuccess(cfg, wait)
+	log.Printf("Nodes of the original cluster are listed as NotReady until the operator replaces them.")
+
+	if wait {
+		return waitForOperatorComplete(ctx, cfg.ClusterName, kubeconfig)
+	}
+	return nil
+}
+
+// ensureClusterAbsent fails if the cluster has already been bootstrapped, since
+// provisioning would reuse its servers instead of building fresh ones.
+func ensureClusterAbsent(ctx context.Context, cfg *config.Config, token string) error {
+	markerName := fmt.Sprintf("%s-state", cfg.ClusterName)
+	cert, err := newInfraClient(token).GetCertificate(ctx, markerName)
+	if err != nil {
+		return fmt.Errorf("failed to check for existing cluster: %w", err)
+	}
+	if cert != nil {
+		return fmt.Errorf("cluster %s still exists; run 'k8zner destroy' first", cfg.ClusterName)
+	}
+	return nil
+}
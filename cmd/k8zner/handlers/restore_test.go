@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	hcloudgo "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milankappen/k8zner/internal/backup"
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/hcloud"
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+// fakeSnapshotStore serves a single snapshot object.
+type fakeSnapshotStore struct {
+	key  string
+	data []byte
+}
+
+func (f *fakeSnapshotStore) ListObjectInfo(_ context.Context, _, _ string) ([]s3.ObjectInfo, error) {
+	return []s3.ObjectInfo{{Key: f.key, Size: int64(len(f.data)), LastModified: time.Now()}}, nil
+}
+
+func (f *fakeSnapshotStore) GetObject(_ context.Context, _, _ string) ([]byte, error) {
+	return f.data, nil
+}
+
+func (f *fakeSnapshotStore) PutObject(_ context.Context, _, _ string, _ []byte) error {
+	return nil
+}
+
+func (f *fakeSnapshotStore) DeleteObject(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (f *fakeSnapshotStore) GetMetadata(_ context.Context, _ string) (*s3.BucketMetadata, error) {
+	return &s3.BucketMetadata{ClusterName: "test", ManagedBy: "k8zner"}, nil
+}
+
+// setupRestoreTest stubs config loading, the backup bucket and the infrastructure
+// client, and runs the test in a directory that holds a secrets.yaml.
+func setupRestoreTest(t *testing.T, store backup.ObjectStore, infra hcloud.InfrastructureManager) {
+	t.Helper()
+	origLoad := loadV2ConfigFile
+	origExpand := expandV2Config
+	origInfra := newInfraClient
+	origRepo := newSnapshotRepository
+	t.Cleanup(func() {
+		loadV2ConfigFile = origLoad
+		expandV2Config = origExpand
+		newInfraClient = origInfra
+		newSnapshotRepository = origRepo
+	})
+
+	loadV2ConfigFile = func(_ string) (*config.Spec, error) {
+		return &config.Spec{Name: "test", Region: config.RegionFalkenstein, Mode: config.ModeDev, Workers: config.WorkerSpec{Count: 1, Size: config.SizeCX22}}, nil
+	}
+	expandV2Config = func(_ *config.Spec) (*config.Config, error) {
+		return &config.Config{ClusterName: "test"}, nil
+	}
+	newInfraClient = func(_ string) hcloud.InfrastructureManager { return infra }
+	newSnapshotRepository = func(_ config.TalosBackupConfig) (*backup.Repository, error) {
+		return backup.NewRepository(store, "test-etcd-backups"), nil
+	}
+
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile(secretsFile, []byte("cluster: {}\n"), 0600))
+	t.Setenv("HCLOUD_TOKEN", "test-token")
+}
+
+func TestRestore_RequiresOriginalSecrets(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	setupRestoreTest(t, &fakeSnapshotStore{}, &hcloud.MockClient{})
+	require.NoError(t, os.Remove(secretsFile))
+
+	err := Restore(context.Background(), "k8zner.yaml", backup.Latest, "", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secrets.yaml of the original cluster")
+}
+
+func TestRestore_RefusesExistingCluster(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	var marker string
+	infra := &hcloud.MockClient{
+		GetCertificateFunc: func(_ context.Context, name string) (*hcloudgo.Certificate, error) {
+			marker = name
+			return &hcloudgo.Certificate{Name: name}, nil
+		},
+	}
+	setupRestoreTest(t, &fakeSnapshotStore{}, infra)
+
+	err := Restore(context.Background(), "k8zner.yaml", backup.Latest, "", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cluster test still exists")
+	assert.Equal(t, "test-state", marker)
+}
+
+func TestRestore_EncryptedSnapshotWithoutIdentity(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	store := &fakeSnapshotStore{
+		key:  "etcd-backups/test.snap.age",
+		data: []byte("age-encryption.org/v1\n-> X25519 ...\n"),
+	}
+	setupRestoreTest(t, store, &hcloud.MockClient{})
+
+	err := Restore(context.Background(), "k8zner.yaml", backup.Latest, "", false)
+	require.ErrorIs(t, err, backup.ErrIdentityRequired)
+	assert.Contains(t, err.Error(), "--identity")
+}
+
+func TestRestore_UnknownSnapshot(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	store := &fakeSnapshotStore{key: "etcd-backups/test.snap", data: []byte("snapshot")}
+	setupRestoreTest(t, store, &hcloud.MockClient{})
+
+	err := Restore(context.Background(), "k8zner.yaml", "etcd-backups/other.snap", "", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+)
+
+// caRotationTimeout bounds the wait for a CA rotation. Every node is
+// reconfigured three times, so it takes much longer than provisioning.
+const caRotationTimeout = 3 * time.Hour
+
+// rotatePollInterval is the interval between status checks while rotating (for testing injection).
+var rotatePollInterval = operatorPollInterval
+
+// RotateCA rotates the Talos API and Kubernetes CAs of a running cluster.
+// The operator performs the rotation in three machine config rollouts; this
+// command requests it, follows its progress and finally replaces secrets.yaml,
+// talosconfig and kubeconfig with the files issued for the new CAs.
+// Running it again while a rotation is in progress resumes waiting for it.
+func RotateCA(ctx context.Context, configPath string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return withStateLock(ctx, cfg, "rotate-ca", func(ctx context.Context) error {
+		kubeconfig, err := localFiles.ReadFile(kubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to read kubeconfig: %w", err)
+		}
+		k8sClient, err := newClusterClient(kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to create kubernetes client: %w", err)
+		}
+
+		request, err := requestCARotation(ctx, k8sClient, cfg.ClusterName)
+		if err != nil {
+			return err
+		}
+		return waitForCARotation(ctx, kubeconfig, cfg.ClusterName, request)
+	})
+}
+
+// requestCARotation asks the operator to rotate the CAs by annotating the
+// K8znerCluster and returns the request. A rotation already in progress is
+// returned instead of starting another one.
+func requestCARotation(ctx context.Context, k8sClient client.Client, clusterName string) (string, error) {
+	cluster := &k8znerv1alpha1.K8znerCluster{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: k8znerNamespace, Name: clusterName}, cluster); err != nil {
+		return "", fmt.Errorf("failed to get cluster %s: %w", clusterName, err)
+	}
+
+	if rotation := cluster.Status.CARotation; rotation != nil && rotation.Phase != k8znerv1alpha1.CARotationComplete {
+		log.Printf("CA rotation %s is in progress (%s), waiting for it", rotation.Request, rotation.Phase)
+		return rotation.Request, nil
+	}
+
+	request := time.Now().UTC().Format(time.RFC3339)
+	patch := fmt.Appendf(nil, `{"metadata":{"annotations":{%q:%q}}}`, k8znerv1alpha1.RotateCAAnnotation, request)
+	if err := k8sClient.Patch(ctx, cluster, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return "", fmt.Errorf("failed to request CA rotation: %w", err)
+	}
+	log.Printf("Requested CA rotation %s for cluster %s", request, clusterName)
+	return request, nil
+}
+
+// waitForCARotation follows the rotation until it completes. While nodes
+// switch to the new Kubernetes CA, the kubeconfig is made to trust both CAs;
+// once the old CAs are dropped, the re-issued credentials are written to the
+// working directory and used from then on.
+func waitForCARotation(ctx context.Context, kubeconfig []byte, clusterName, request string) error {
+	log.Println("Waiting for the operator to rotate the CAs. Every node receives a new machine config three times.")
+
+	ticker := time.NewTicker(rotatePollInterval)
+	defer ticker.Stop()
+
+	deadline := time.Now().Add(caRotationTimeout)
+	var lastMessage string
+	saved := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for CA rotation after %v; run 'k8zner rotate ca' again to keep waiting", caRotationTimeout)
+		}
+
+		k8sClient, err := newClusterClient(kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to create kubernetes client: %w", err)
+		}
+
+		cluster := &k8znerv1alpha1.K8znerCluster{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: k8znerNamespace, Name: clusterName}, cluster); err != nil {
+			log.Printf("Warning: failed to get cluster status: %v", err)
+			continue
+		}
+
+		if message := caRotationProgress(cluster, request); message != lastMessage {
+			log.Print(message)
+			lastMessage = message
+		}
+		rotation := cluster.Status.CARotation
+		if rotation == nil || rotation.Request != request {
+			continue
+		}
+
+		secretName := cluster.Spec.CredentialsRef.Name
+		if secretName == "" {
+			secretName = credentialsSecretName
+		}
+		secret := &corev1.Secret{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: k8znerNamespace, Name: secretName}, secret); err != nil {
+			log.Printf("Warning: failed to get secret %s: %v", secretName, err)
+			continue
+		}
+
+		if _, accepting := secret.Data[k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets]; accepting {
+			// Keep reaching the API server while its certificate changes CA
+			kubeconfig, err = trustKubernetesCAs(kubeconfig, secret)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !saved && len(secret.Data[k8znerv1alpha1.CredentialsKeyKubeconfig]) > 0 &&
+			(rotation.Phase == k8znerv1alpha1.CARotationDropOldCA || rotation.Phase == k8znerv1alpha1.CARotationComplete) {
+			if err := saveRotatedCredentials(secret); err != nil {
+				return err
+			}
+			kubeconfig = secret.Data[k8znerv1alpha1.CredentialsKeyKubeconfig]
+			saved = true
+		}
+
+		if saved && rotation.Phase == k8znerv1alpha1.CARotationComplete {
+			log.Println("CA rotation complete. Restart workloads that cache the cluster CA.")
+			return nil
+		}
+	}
+}
+
+// caRotationProgress describes the state of the rotation for the log.
+func caRotationProgress(cluster *k8znerv1alpha1.K8znerCluster, request string) string {
+	rotation := cluster.Status.CARotation
+	cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionCARotation)
+	if rotation == nil || rotation.Request != request {
+		if cond != nil {
+			return "Waiting for the operator to start the rotation: " + cond.Message
+		}
+		return "Waiting for the operator to start the rotation"
+	}
+	if cond != nil {
+		return fmt.Sprintf("CA rotation %s: %s", rotation.Phase, cond.Message)
+	}
+	return fmt.Sprintf("CA rotation %s", rotation.Phase)
+}
+
+// trustKubernetesCAs adds the Kubernetes CAs of the current and accepted
+// Talos secrets in secret to the CA data of every cluster in kubeconfig.
+func trustKubernetesCAs(kubeconfig []byte, secret *corev1.Secret) ([]byte, error) {
+	kc, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	for _, key := range []string{k8znerv1alpha1.CredentialsKeyTalosSecrets, k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets} {
+		sb, err := talos.ParseSecrets(secret.Data[key])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", key, err)
+		}
+		if sb.Certs == nil || sb.Certs.K8s == nil {
+			return nil, fmt.Errorf("%s has no Kubernetes CA", key)
+		}
+		for _, cluster := range kc.Clusters {
+			ca := cluster.CertificateAuthorityData
+			if bytes.Contains(ca, sb.Certs.K8s.Crt) {
+				continue
+			}
+			if len(ca) > 0 && ca[len(ca)-1] != '\n' {
+				ca = append(ca, '\n')
+			}
+			cluster.CertificateAuthorityData = append(ca, sb.Certs.K8s.Crt...)
+		}
+	}
+
+	return clientcmd.Write(*kc)
+}
+
+// saveRotatedCredentials writes the Talos secrets, talosconfig and kubeconfig
+// issued for the new CAs to the working directory.
+func saveRotatedCredentials(secret *corev1.Secret) error {
+	files := []struct {
+		name string
+		key  string
+	}{
+		{secretsFile, k8znerv1alpha1.CredentialsKeyTalosSecrets},
+		{talosConfigPath, k8znerv1alpha1.CredentialsKeyTalosConfig},
+		{kubeconfigPath, k8znerv1alpha1.CredentialsKeyKubeconfig},
+	}
+	for _, f := range files {
+		data := secret.Data[f.key]
+		if len(data) == 0 {
+			return fmt.Errorf("secret %s has no %s key", secret.Name, f.key)
+		}
+		if err := writeLocalFile(f.name, data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+		log.Printf("Updated %s", localFiles.Path(f.name))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+)
+
+func marshalTestSecrets(t *testing.T) ([]byte, *talos.SecretsBundle) {
+	t.Helper()
+	sb, err := talos.NewSecrets("v1.7.0")
+	require.NoError(t, err)
+	data, err := talos.MarshalSecrets(sb)
+	require.NoError(t, err)
+	return data, sb
+}
+
+func TestRequestCARotation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("annotates the cluster", func(t *testing.T) {
+		t.Parallel()
+		k8sClient := fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).
+			WithObjects(newTestCluster("test", nil)).Build()
+
+		request, err := requestCARotation(context.Background(), k8sClient, "test")
+		require.NoError(t, err)
+		_, err = time.Parse(time.RFC3339, request)
+		require.NoError(t, err)
+
+		cluster := &k8znerv1alpha1.K8znerCluster{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: k8znerNamespace, Name: "test"}, cluster))
+		assert.Equal(t, request, cluster.Annotations[k8znerv1alpha1.RotateCAAnnotation])
+	})
+
+	t.Run("resumes a rotation in progress", func(t *testing.T) {
+		t.Parallel()
+		cluster := newTestCluster("test", nil)
+		cluster.Status.CARotation = &k8znerv1alpha1.CARotationStatus{
+			Phase:   k8znerv1alpha1.CARotationSwitchCA,
+			Request: "earlier",
+		}
+		k8sClient := fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).WithObjects(cluster).Build()
+
+		request, err := requestCARotation(context.Background(), k8sClient, "test")
+		require.NoError(t, err)
+		assert.Equal(t, "earlier", request)
+
+		stored := &k8znerv1alpha1.K8znerCluster{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: k8znerNamespace, Name: "test"}, stored))
+		assert.Empty(t, stored.Annotations)
+	})
+}
+
+// Serial: swaps the package-level newClusterClient and rotatePollInterval and changes directory.
+func TestWaitForCARotation_SavesCredentials(t *testing.T) {
+	origClient := newClusterClient
+	origInterval := rotatePollInterval
+	t.Cleanup(func() {
+		newClusterClient = origClient
+		rotatePollInterval = origInterval
+	})
+	rotatePollInterval = time.Millisecond
+	t.Chdir(t.TempDir())
+
+	secretsData, _ := marshalTestSecrets(t)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: credentialsSecretName, Namespace: k8znerNamespace},
+		Data: map[string][]byte{
+			k8znerv1alpha1.CredentialsKeyTalosSecrets: secretsData,
+			k8znerv1alpha1.CredentialsKeyTalosConfig:  []byte("context: new\n"),
+			k8znerv1alpha1.CredentialsKeyKubeconfig:   []byte("apiVersion: v1\nkind: Config\n"),
+		},
+	}
+	cluster := newTestCluster("test", nil)
+	cluster.Status.CARotation = &k8znerv1alpha1.CARotationStatus{
+		Phase:   k8znerv1alpha1.CARotationComplete,
+		Request: "r1",
+	}
+	var usedKubeconfigs []string
+	newClusterClient = func(kubeconfig []byte) (client.Client, error) {
+		usedKubeconfigs = append(usedKubeconfigs, string(kubeconfig))
+		return fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).WithObjects(cluster, secret).Build(), nil
+	}
+
+	require.NoError(t, waitForCARotation(context.Background(), []byte("old"), "test", "r1"))
+	assert.Equal(t, []string{"old"}, usedKubeconfigs)
+
+	for name, want := range map[string][]byte{
+		secretsFile:     secretsData,
+		talosConfigPath: []byte("context: new\n"),
+		kubeconfigPath:  []byte("apiVersion: v1\nkind: Config\n"),
+	} {
+		data, err := os.ReadFile(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, data, name)
+	}
+}
+
+func TestTrustKubernetesCAs(t *testing.T) {
+	t.Parallel()
+
+	current, currentSB := marshalTestSecrets(t)
+	accepted, acceptedSB := marshalTestSecrets(t)
+	secret := &corev1.Secret{Data: map[string][]byte{
+		k8znerv1alpha1.CredentialsKeyTalosSecrets:         current,
+		k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets: accepted,
+	}}
+
+	kc := clientcmdapi.NewConfig()
+	kc.Clusters["test"] = &clientcmdapi.Cluster{Server: "https://1.2.3.4:6443", CertificateAuthorityData: currentSB.Certs.K8s.Crt}
+	kubeconfig, err := clientcmd.Write(*kc)
+	require.NoError(t, err)
+
+	trusted, err := trustKubernetesCAs(kubeconfig, secret)
+	require.NoError(t, err)
+	// Adding the same CAs again changes nothing
+	again, err := trustKubernetesCAs(trusted, secret)
+	require.NoError(t, err)
+	assert.Equal(t, trusted, again)
+
+	parsed, err := clientcmd.Load(trusted)
+	require.NoError(t, err)
+	ca := parsed.Clusters["test"].CertificateAuthorityData
+	assert.Equal(t, append(append([]byte{}, currentSB.Certs.K8s.Crt...), acceptedSB.Certs.K8s.Crt...), ca)
+}
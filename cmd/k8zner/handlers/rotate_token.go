@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+)
+
+const (
+	// hcloudTokenRolloutTimeout bounds the wait for a restarted deployment.
+	hcloudTokenRolloutTimeout = 5 * time.Minute
+
+	// hcloudTokenHealthTimeout bounds the wait for the operator to check CCM and CSI health.
+	hcloudTokenHealthTimeout = 10 * time.Minute
+)
+
+// hcloudTokenSecret is a Secret key that holds a copy of the Hetzner Cloud API token.
+type hcloudTokenSecret struct {
+	namespace string
+	name      string
+	key       string
+}
+
+var (
+	// hcloudTokenSecrets are the copies of the token, in the order they are updated:
+	// the operator's cluster credentials, the CCM and CSI Secret, and the
+	// operator deployment's own credentials.
+	hcloudTokenSecrets = []hcloudTokenSecret{
+		{k8znerNamespace, credentialsSecretName, k8znerv1alpha1.CredentialsKeyHCloudToken},
+		{"kube-system", "hcloud", "token"},
+		{k8znerNamespace, "k8zner-operator-credentials", "hcloud-token"},
+	}
+
+	// hcloudTokenConsumers are the deployments that read the token at startup,
+	// in the order they are restarted. The operator goes last so its first
+	// health check covers the restarted CCM and CSI.
+	hcloudTokenConsumers = []types.NamespacedName{
+		{Namespace: "kube-system", Name: "hcloud-cloud-controller-manager"},
+		{Namespace: "kube-system", Name: "hcloud-csi-controller"},
+		{Namespace: k8znerNamespace, Name: "k8zner-operator"},
+	}
+)
+
+// RotateHCloudToken replaces the Hetzner Cloud API token of a running cluster
+// with the one in HCLOUD_TOKEN. The token is checked against the API first,
+// then every Secret holding a copy is updated and the deployments using it
+// are restarted. If a restart or the following CCM and CSI health checks
+// fail, the previous token is put back.
+func RotateHCloudToken(ctx context.Context, configPath string) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	token := os.Getenv("HCLOUD_TOKEN")
+	if token == "" {
+		return fmt.Errorf("HCLOUD_TOKEN environment variable is required")
+	}
+
+	return withStateLock(ctx, cfg, "rotate-hcloud-token", func(ctx context.Context) error {
+		kubeconfig, err := localFiles.ReadFile(kubeconfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to read kubeconfig: %w", err)
+		}
+		k8sClient, err := newClusterClient(kubeconfig)
+		if err != nil {
+			return fmt.Errorf("failed to create kubernetes client: %w", err)
+		}
+		return rotateHCloudToken(ctx, k8sClient, cfg.ClusterName, token)
+	})
+}
+
+func rotateHCloudToken(ctx context.Context, k8sClient client.Client, clusterName, token string) error {
+	log.Println("Validating the new token against the Hetzner Cloud API...")
+	network, err := newInfraClient(token).GetNetwork(ctx, clusterName)
+	if err != nil {
+		return fmt.Errorf("new token rejected, nothing was changed: %w", err)
+	}
+	if network == nil {
+		return fmt.Errorf("new token rejected, nothing was changed: network %s not found, is the token for the cluster's project?", clusterName)
+	}
+
+	previous, err := readHCloudTokens(ctx, k8sClient)
+	if err != nil {
+		return err
+	}
+	if len(previous) == 0 {
+		return errors.New("no Secret holding the Hetzner Cloud token found in the cluster")
+	}
+	unchanged := true
+	for _, old := range previous {
+		unchanged = unchanged && old == token
+	}
+	if unchanged {
+		log.Println("The cluster already uses this token")
+		return nil
+	}
+
+	started := time.Now()
+	err = writeHCloudTokens(ctx, k8sClient, previous, func(hcloudTokenSecret) string { return token })
+	if err == nil {
+		err = restartHCloudTokenConsumers(ctx, k8sClient)
+	}
+	if err == nil {
+		err = waitForCloudAddonsHealthy(ctx, k8sClient, clusterName, started)
+	}
+	if err == nil {
+		log.Println("Hetzner Cloud token rotated. Delete the old token in the Hetzner Cloud Console.")
+		return nil
+	}
+
+	log.Printf("Rotation failed, restoring the previous token: %v", err)
+	rollbackErr := writeHCloudTokens(ctx, k8sClient, previous, func(s hcloudTokenSecret) string { return previous[s] })
+	if rollbackErr == nil {
+		rollbackErr = restartHCloudTokenConsumers(ctx, k8sClient)
+	}
+	if rollbackErr != nil {
+		return errors.Join(fmt.Errorf("hcloud token rotation failed: %w", err),
+			fmt.Errorf("restoring the previous token failed: %w", rollbackErr))
+	}
+	return fmt.Errorf("hcloud token rotation failed, the previous token was restored: %w", err)
+}
+
+// readHCloudTokens returns the token currently stored in each existing Secret.
+func readHCloudTokens(ctx context.Context, k8sClient client.Client) (map[hcloudTokenSecret]string, error) {
+	tokens := make(map[hcloudTokenSecret]string)
+	for _, s := range hcloudTokenSecrets {
+		secret := &corev1.Secret{}
+		err := k8sClient.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: s.name}, secret)
+		if apierrors.IsNotFound(err) {
+			log.Printf("Secret %s/%s not found, skipping", s.namespace, s.name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", s.namespace, s.name, err)
+		}
+		tokens[s] = string(secret.Data[s.key])
+	}
+	return tokens, nil
+}
+
+// writeHCloudTokens sets the token of every Secret in existing to value(secret).
+func writeHCloudTokens(ctx context.Context, k8sClient client.Client, existing map[hcloudTokenSecret]string, value func(hcloudTokenSecret) string) error {
+	for _, s := range hcloudTokenSecrets {
+		if _, ok := existing[s]; !ok {
+			continue
+		}
+		secret := &corev1.Secret{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: s.name}, secret); err != nil {
+			return fmt.Errorf("failed to get secret %s/%s: %w", s.namespace, s.name, err)
+		}
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[s.key] = []byte(value(s))
+		if err := k8sClient.Update(ctx, secret); err != nil {
+			return fmt.Errorf("failed to update secret %s/%s: %w", s.namespace, s.name, err)
+		}
+		log.Printf("Updated secret %s/%s", s.namespace, s.name)
+	}
+	return nil
+}
+
+// restartHCloudTokenConsumers restarts the deployments using the token one
+// after another, like kubectl rollout restart, and waits for each rollout.
+func restartHCloudTokenConsumers(ctx context.Context, k8sClient client.Client) error {
+	for _, key := range hcloudTokenConsumers {
+		dep := &appsv1.Deployment{}
+		err := k8sClient.Get(ctx, key, dep)
+		if apierrors.IsNotFound(err) {
+			log.Printf("Deployment %s not found, skipping", key)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get deployment %s: %w", key, err)
+		}
+
+		patch := fmt.Appendf(nil, `{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":%q}}}}}`,
+			time.Now().UTC().Format(time.RFC3339))
+		if err := k8sClient.Patch(ctx, dep, client.RawPatch(types.MergePatchType, patch)); err != nil {
+			return fmt.Errorf("failed to restart deployment %s: %w", key, err)
+		}
+		log.Printf("Restarting deployment %s...", key)
+
+		if err := waitForDeploymentRollout(ctx, k8sClient, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForDeploymentRollout waits until every replica of a deployment runs the latest template.
+func waitForDeploymentRollout(ctx context.Context, k8sClient client.Client, key types.NamespacedName) error {
+	deadline := time.Now().Add(hcloudTokenRolloutTimeout)
+	for {
+		dep := &appsv1.Deployment{}
+		if err := k8sClient.Get(ctx, key, dep); err != nil {
+			log.Printf("Warning: failed to get deployment %s: %v", key, err)
+		} else if deploymentRolledOut(dep) {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("deployment %s did not finish its rollout within %v", key, hcloudTokenRolloutTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rotatePollInterval):
+		}
+	}
+}
+
+// deploymentRolledOut reports whether all replicas are updated and available.
+func deploymentRolledOut(dep *appsv1.Deployment) bool {
+	want := int32(1)
+	if dep.Spec.Replicas != nil {
+		want = *dep.Spec.Replicas
+	}
+	return dep.Status.ObservedGeneration >= dep.Generation &&
+		dep.Status.UpdatedReplicas == want &&
+		dep.Status.AvailableReplicas == want &&
+		dep.Status.Replicas == want
+}
+
+// waitForCloudAddonsHealthy waits for the operator's addon health check to
+// report on CCM and CSI after since, and fails if either is unhealthy.
+// Addons the cluster does not run are skipped.
+func waitForCloudAddonsHealthy(ctx context.Context, k8sClient client.Client, clusterName string, since time.Time) error {
+	log.Println("Waiting for the operator to check CCM and CSI health...")
+	since = since.Truncate(time.Second)
+	deadline := time.Now().Add(hcloudTokenHealthTimeout)
+
+	for {
+		cluster := &k8znerv1alpha1.K8znerCluster{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: k8znerNamespace, Name: clusterName}, cluster); err != nil {
+			log.Printf("Warning: failed to get cluster status: %v", err)
+		} else {
+			checked := true
+			for _, name := range []string{k8znerv1alpha1.AddonNameCCM, k8znerv1alpha1.AddonNameCSI} {
+				addon, ok := cluster.Status.Addons[name]
+				if !ok || !addon.Installed {
+					continue
+				}
+				if addon.LastHealthCheck == nil || addon.LastHealthCheck.Time.Before(since) {
+					checked = false
+					continue
+				}
+				if !addon.Healthy {
+					return fmt.Errorf("%s is unhealthy after the restart: %s", name, addon.Message)
+				}
+			}
+			if checked {
+				log.Println("CCM and CSI are healthy")
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the operator did not report CCM and CSI health within %v", hcloudTokenHealthTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rotatePollInterval):
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hcloudgo "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/platform/hcloud"
+)
+
+// setupHCloudTokenTest stubs the Hetzner API and returns a cluster whose
+// Secrets hold "old-token", whose deployments are rolled out and whose CCM
+// and CSI health is as given.
+func setupHCloudTokenTest(t *testing.T, networkErr error, ccmHealthy bool) client.Client {
+	t.Helper()
+	origInfra := newInfraClient
+	origInterval := rotatePollInterval
+	t.Cleanup(func() {
+		newInfraClient = origInfra
+		rotatePollInterval = origInterval
+	})
+	rotatePollInterval = time.Millisecond
+	newInfraClient = func(_ string) hcloud.InfrastructureManager {
+		return &hcloud.MockClient{GetNetworkFunc: func(_ context.Context, name string) (*hcloudgo.Network, error) {
+			if networkErr != nil {
+				return nil, networkErr
+			}
+			return &hcloudgo.Network{Name: name}, nil
+		}}
+	}
+
+	objects := []client.Object{}
+	for _, s := range hcloudTokenSecrets {
+		objects = append(objects, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: s.namespace, Name: s.name},
+			Data:       map[string][]byte{s.key: []byte("old-token")},
+		})
+	}
+	for _, key := range hcloudTokenConsumers {
+		objects = append(objects, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+		})
+	}
+	// The health check runs after the restart
+	checked := metav1.NewTime(time.Now().Add(time.Hour))
+	objects = append(objects, &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: k8znerNamespace, Name: "test"},
+		Status: k8znerv1alpha1.K8znerClusterStatus{Addons: map[string]k8znerv1alpha1.AddonStatus{
+			k8znerv1alpha1.AddonNameCCM: {Installed: true, Healthy: ccmHealthy, Message: "0/1 ready", LastHealthCheck: &checked},
+			k8znerv1alpha1.AddonNameCSI: {Installed: true, Healthy: true, LastHealthCheck: &checked},
+		}},
+	})
+
+	return fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).WithObjects(objects...).Build()
+}
+
+func storedHCloudTokens(t *testing.T, k8sClient client.Client) []string {
+	t.Helper()
+	var tokens []string
+	for _, s := range hcloudTokenSecrets {
+		secret := &corev1.Secret{}
+		require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: s.namespace, Name: s.name}, secret))
+		tokens = append(tokens, string(secret.Data[s.key]))
+	}
+	return tokens
+}
+
+// Serial: swaps the package-level newInfraClient and rotatePollInterval.
+func TestRotateHCloudToken(t *testing.T) {
+	k8sClient := setupHCloudTokenTest(t, nil, true)
+
+	require.NoError(t, rotateHCloudToken(context.Background(), k8sClient, "test", "new-token"))
+	assert.Equal(t, []string{"new-token", "new-token", "new-token"}, storedHCloudTokens(t, k8sClient))
+
+	for _, key := range hcloudTokenConsumers {
+		dep := &appsv1.Deployment{}
+		require.NoError(t, k8sClient.Get(context.Background(), key, dep))
+		assert.NotEmpty(t, dep.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"], key.String())
+	}
+}
+
+// Serial: swaps the package-level newInfraClient and rotatePollInterval.
+func TestRotateHCloudToken_InvalidToken(t *testing.T) {
+	k8sClient := setupHCloudTokenTest(t, errors.New("unauthorized"), true)
+
+	err := rotateHCloudToken(context.Background(), k8sClient, "test", "bad-token")
+	require.ErrorContains(t, err, "nothing was changed")
+	assert.Equal(t, []string{"old-token", "old-token", "old-token"}, storedHCloudTokens(t, k8sClient))
+}
+
+// Serial: swaps the package-level newInfraClient and rotatePollInterval.
+func TestRotateHCloudToken_RollsBackWhenUnhealthy(t *testing.T) {
+	k8sClient := setupHCloudTokenTest(t, nil, false)
+
+	err := rotateHCloudToken(context.Background(), k8sClient, "test", "new-token")
+	require.ErrorContains(t, err, "previous token was restored")
+	assert.ErrorContains(t, err, k8znerv1alpha1.AddonNameCCM)
+	assert.Equal(t, []string{"old-token", "old-token", "old-token"}, storedHCloudTokens(t, k8sClient))
+}
+
+func TestDeploymentRolledOut(t *testing.T) {
+	t.Parallel()
+	two := int32(2)
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 3},
+		Spec:       appsv1.DeploymentSpec{Replicas: &two},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 3, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+	assert.False(t, deploymentRolledOut(dep), "an old replica is still running")
+
+	dep.Status.Replicas = 2
+	assert.True(t, deploymentRolledOut(dep))
+
+	dep.Status.ObservedGeneration = 2
+	assert.False(t, deploymentRolledOut(dep), "the new template was not seen yet")
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -10,7 +11,6 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -28,11 +28,10 @@ func Secrets(ctx context.Context, configPath string, jsonOutput bool) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if _, err := os.Stat(kubeconfigPath); os.IsNotExist(err) {
+	kubecfg, err := loadRESTConfig()
+	if errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("kubeconfig not found. Run 'k8zner apply' first to create the cluster")
 	}
-
-	kubecfg, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
 	if err != nil {
 		return fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
@@ -65,13 +64,13 @@ func collectSecrets(ctx context.Context, k8sClient client.Client) []secretEntry
 	entries = append(entries, secretEntry{
 		Category: "Files",
 		Name:     "kubeconfig",
-		Value:    kubeconfigPath,
+		Value:    localFiles.Path(kubeconfigPath),
 	})
-	if _, err := os.Stat(talosConfigPath); err == nil {
+	if localFiles.Exists(talosConfigPath) {
 		entries = append(entries, secretEntry{
 			Category: "Files",
 			Name:     "talosconfig",
-			Value:    talosConfigPath,
+			Value:    localFiles.Path(talosConfigPath),
 		})
 	}
 
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/state"
+)
+
+var (
+	// newStateRemote opens the bundle store in the backup bucket (for testing injection).
+	newStateRemote = state.NewRemoteFromConfig
+
+	// newClusterClient creates a client for k8zner resources from kubeconfig bytes (for testing injection).
+	newClusterClient = func(kubeconfig []byte) (client.Client, error) {
+		kubecfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+		}
+		kubecfg.Timeout = 10 * time.Second
+		return client.New(kubecfg, client.Options{Scheme: k8znerv1alpha1.Scheme})
+	}
+)
+
+// stateFiles are the working directory files packaged into a state bundle.
+var stateFiles = []string{secretsFile, talosConfigPath, kubeconfigPath}
+
+// StateExport packages the Talos secrets, talosconfig and kubeconfig together
+// with the k8zner-credentials Secret and the K8znerCluster into a bundle
+// encrypted for recipients, or for the passphrase in K8ZNER_STATE_PASSPHRASE
+// if there are none. The bundle is written to out and, with push, uploaded
+// to the backup bucket.
+func StateExport(ctx context.Context, configPath, out string, recipientKeys []string, push bool) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	recipients, err := stateRecipients(recipientKeys)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := collectState(ctx, cfg.ClusterName)
+	if err != nil {
+		return err
+	}
+	sealed, err := state.Seal(bundle, recipients...)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		out = cfg.ClusterName + "-state.age"
+	}
+	if err := writeFile(out, sealed, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+	fmt.Printf("Exported %s to %s (%s)\n", strings.Join(bundle.Names(), ", "), out, formatSize(int64(len(sealed))))
+
+	if push {
+		remote, err := newStateRemote(cfg.Addons.TalosBackup, cfg.ClusterName)
+		if err != nil {
+			return fmt.Errorf("failed to open backup bucket: %w", err)
+		}
+		key, err := remote.Push(ctx, sealed, bundle.CreatedAt)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Uploaded to s3://%s/%s\n", remote.Bucket(), key)
+	}
+	return nil
+}
+
+// StateImport decrypts a state bundle and writes its files into the working
+// directory. The bundle is read from file, or with pull set to a key or
+// "latest", downloaded from the backup bucket. Existing files are only
+// replaced with force.
+func StateImport(ctx context.Context, configPath, file, pull, identityPath string, force bool) error {
+	identities, err := stateIdentities(identityPath)
+	if err != nil {
+		return err
+	}
+
+	var sealed []byte
+	source := file
+	switch {
+	case pull != "" && file != "":
+		return errors.New("specify either a bundle file or --pull, not both")
+	case pull != "":
+		cfg, err := loadConfig(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		remote, err := newStateRemote(cfg.Addons.TalosBackup, cfg.ClusterName)
+		if err != nil {
+			return fmt.Errorf("failed to open backup bucket: %w", err)
+		}
+		var key string
+		key, sealed, err = remote.Pull(ctx, pull)
+		if err != nil {
+			return err
+		}
+		source = fmt.Sprintf("s3://%s/%s", remote.Bucket(), key)
+	case file != "":
+		sealed, err = os.ReadFile(file) //nolint:gosec // path is supplied by the user on purpose
+		if err != nil {
+			return fmt.Errorf("failed to read state bundle: %w", err)
+		}
+	default:
+		return errors.New("specify a bundle file or --pull")
+	}
+
+	bundle, err := state.Open(sealed, identities...)
+	if err != nil {
+		return err
+	}
+
+	names := bundle.Names()
+	if !force {
+		for _, name := range names {
+			if localFiles.Exists(name) {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", name)
+			}
+		}
+	}
+	for _, name := range names {
+		if err := writeLocalFile(name, bundle.Files[name]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	fmt.Printf("Imported state of cluster %s from %s (exported %s)\n",
+		bundle.Cluster, source, bundle.CreatedAt.UTC().Format(time.RFC3339))
+	fmt.Printf("Wrote %s\n", strings.Join(names, ", "))
+	if _, ok := bundle.Files[state.FileCredentials]; ok {
+		apply := "kubectl apply -f " + state.FileCredentials
+		if localFiles.Encrypted() {
+			apply = fmt.Sprintf("k8zner secrets decrypt %s --out - | kubectl apply -f -", state.FileCredentials)
+		}
+		fmt.Printf("If the operator lost its credentials, recreate them with: %s\n", apply)
+	}
+	return nil
+}
+
+// collectState reads the local credential files and, when the cluster is
+// reachable, its k8zner-credentials Secret and K8znerCluster.
+func collectState(ctx context.Context, clusterName string) (*state.Bundle, error) {
+	bundle := &state.Bundle{
+		Cluster:   clusterName,
+		CreatedAt: time.Now().UTC(),
+		Files:     make(map[string][]byte),
+	}
+
+	for _, name := range stateFiles {
+		data, err := localFiles.ReadFile(name)
+		if errors.Is(err, os.ErrNotExist) && name != secretsFile {
+			log.Printf("Warning: %s not found, it is not included in the bundle", name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		bundle.Files[name] = data
+	}
+
+	kubeconfig, ok := bundle.Files[kubeconfigPath]
+	if !ok {
+		return bundle, nil
+	}
+	// The local files alone can restore access, so an unreachable cluster is not fatal
+	if err := collectClusterState(ctx, kubeconfig, clusterName, bundle); err != nil {
+		log.Printf("Warning: cluster resources are not included in the bundle: %v", err)
+	}
+	return bundle, nil
+}
+
+// collectClusterState adds the k8zner-credentials Secret and the K8znerCluster
+// to bundle as manifests that can be re-applied with kubectl.
+func collectClusterState(ctx context.Context, kubeconfig []byte, clusterName string, bundle *state.Bundle) error {
+	k8sClient, err := newClusterClient(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: k8znerNamespace, Name: credentialsSecretName}, secret); err != nil {
+		return fmt.Errorf("failed to get secret %s: %w", credentialsSecretName, err)
+	}
+	secret.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+	if err := addManifest(bundle, state.FileCredentials, secret); err != nil {
+		return err
+	}
+
+	cluster := &k8znerv1alpha1.K8znerCluster{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: k8znerNamespace, Name: clusterName}, cluster); err != nil {
+		return fmt.Errorf("failed to get K8znerCluster %s: %w", clusterName, err)
+	}
+	cluster.TypeMeta = metav1.TypeMeta{APIVersion: k8znerv1alpha1.GroupVersion.String(), Kind: "K8znerCluster"}
+	cluster.Status = k8znerv1alpha1.K8znerClusterStatus{}
+	return addManifest(bundle, state.FileCluster, cluster)
+}
+
+// addManifest stores obj as YAML without the server-populated metadata, so it
+// can be applied to a new API server.
+func addManifest(bundle *state.Bundle, name string, obj client.Object) error {
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	obj.SetGeneration(0)
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetManagedFields(nil)
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	bundle.Files[name] = data
+	return nil
+}
+
+// stateRecipients returns the recipients a bundle is sealed for: the given
+// age public keys, or else the passphrase from K8ZNER_STATE_PASSPHRASE.
+func stateRecipients(keys []string) ([]age.Recipient, error) {
+	passphrase := os.Getenv(state.PassphraseEnv)
+	switch {
+	case len(keys) > 0 && passphrase != "":
+		return nil, fmt.Errorf("use either --recipient or %s, age cannot combine them", state.PassphraseEnv)
+	case len(keys) > 0:
+		return state.ParseRecipients(keys)
+	case passphrase != "":
+		r, err := state.PassphraseRecipient(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{r}, nil
+	default:
+		return nil, fmt.Errorf("the bundle must be encrypted: pass --recipient or set %s", state.PassphraseEnv)
+	}
+}
+
+// stateIdentities returns the identities a bundle is opened with: those in
+// identityPath and the passphrase from K8ZNER_STATE_PASSPHRASE, if set.
+func stateIdentities(identityPath string) ([]age.Identity, error) {
+	identities, err := loadAgeIdentities(identityPath)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase := os.Getenv(state.PassphraseEnv); passphrase != "" {
+		id, err := state.PassphraseIdentity(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, id)
+	}
+	return identities, nil
+}
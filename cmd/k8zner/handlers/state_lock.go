@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/state"
+)
+
+var (
+	// stateLockTTL is the lease of the state lock. It is renewed while the
+	// command runs, so it only bounds how long a crashed run blocks others.
+	stateLockTTL = 15 * time.Minute
+
+	// newStateBackend opens the configured state backend (for testing injection).
+	newStateBackend = func(cfg config.StateConfig, cluster string) (state.Backend, error) {
+		return state.NewBackend(cfg, cluster, workDir)
+	}
+
+	// backendStateFiles are the working directory files kept in the state backend.
+	backendStateFiles = []string{secretsFile, talosConfigPath, kubeconfigPath, accessDataPath}
+)
+
+// withStateLock runs fn while holding the state lock for operation. With a
+// remote backend, the stored state is written to the working directory first
+// and the files are saved back afterwards, even if fn fails, since a failed
+// bootstrap may already have generated secrets the cluster depends on. The
+// local backend keeps the files in place, so they are not read back.
+//
+// fn gets a context that is canceled if another run takes the lock over,
+// for example after this one was suspended past the lease. The run then
+// fails and its state is not saved, so it cannot overwrite the other run's.
+func withStateLock(ctx context.Context, cfg *config.Config, operation string, fn func(ctx context.Context) error) error {
+	backend, err := newStateBackend(cfg.State, cfg.ClusterName)
+	if err != nil {
+		return fmt.Errorf("failed to open state backend: %w", err)
+	}
+
+	lease, err := state.Acquire(ctx, backend, operation, stateLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to lock state: %w", err)
+	}
+	defer func() {
+		if err := lease.Release(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("Warning: failed to release state lock %s: %v", lease.Info().ID, err)
+		}
+	}()
+
+	if !backend.Remote() {
+		return leaseError(lease, fn(lease.Context()))
+	}
+
+	loaded, err := pullState(lease.Context(), backend)
+	if err != nil {
+		return err
+	}
+
+	runErr := fn(lease.Context())
+	if lease.Err() != nil {
+		return fmt.Errorf("%w, the state was not saved", leaseError(lease, runErr))
+	}
+	if err := pushState(context.WithoutCancel(ctx), backend, cfg.ClusterName, loaded, lease.Info().ID); err != nil {
+		if runErr != nil {
+			log.Printf("Warning: %v", err)
+			return runErr
+		}
+		return err
+	}
+	return runErr
+}
+
+// leaseError returns the error of a run whose lease was lost, which matters
+// more than runErr: another run may have changed the cluster meanwhile.
+func leaseError(lease *state.Lease, runErr error) error {
+	lost := lease.Err()
+	if lost == nil {
+		return runErr
+	}
+	if runErr != nil && !errors.Is(runErr, context.Canceled) {
+		log.Printf("Warning: %v", runErr)
+	}
+	return fmt.Errorf("%s stopped: %w", lease.Info().Operation, lost)
+}
+
+// pullState writes the state stored in backend to the working directory and
+// returns it, or nil if the backend keeps no state of its own.
+func pullState(ctx context.Context, backend state.Backend) (*state.Bundle, error) {
+	bundle, err := backend.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	if bundle == nil {
+		return nil, nil
+	}
+
+	for _, name := range bundle.Names() {
+		if err := writeLocalFile(name, bundle.Files[name]); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	log.Printf("Loaded state of cluster %s saved %s", bundle.Cluster, bundle.CreatedAt.UTC().Format(time.RFC3339))
+	return bundle, nil
+}
+
+// pushState saves the state files in the working directory to backend,
+// under the lock lockID, unless they are unchanged since they were loaded.
+func pushState(ctx context.Context, backend state.Backend, clusterName string, loaded *state.Bundle, lockID string) error {
+	bundle := &state.Bundle{
+		Cluster:   clusterName,
+		CreatedAt: time.Now().UTC(),
+		Files:     make(map[string][]byte),
+	}
+	for _, name := range backendStateFiles {
+		data, err := localFiles.ReadFile(name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		bundle.Files[name] = data
+	}
+
+	if len(bundle.Files) == 0 {
+		return nil
+	}
+	if loaded != nil && maps.EqualFunc(loaded.Files, bundle.Files, bytes.Equal) {
+		return nil
+	}
+	if err := backend.Save(ctx, bundle, lockID); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// StateUnlock shows who holds the state lock and, with force, removes it.
+// It is meant for locks left behind by a run that was killed.
+func StateUnlock(ctx context.Context, configPath string, force bool) error {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	backend, err := newStateBackend(cfg.State, cfg.ClusterName)
+	if err != nil {
+		return fmt.Errorf("failed to open state backend: %w", err)
+	}
+
+	held, err := backend.ReadLock(ctx)
+	if err != nil {
+		return err
+	}
+	if held == nil {
+		fmt.Printf("State of cluster %s is not locked\n", cfg.ClusterName)
+		return nil
+	}
+
+	fmt.Printf("State of cluster %s is locked by %s for %s since %s (lease expires %s, lock ID %s)\n",
+		cfg.ClusterName, held.Who, held.Operation,
+		held.Created.UTC().Format(time.RFC3339), held.Expires.UTC().Format(time.RFC3339), held.ID)
+	if !force {
+		return errors.New("make sure that run is gone, then remove the lock with --force")
+	}
+
+	if err := backend.ForceUnlock(ctx); err != nil {
+		return err
+	}
+	fmt.Println("Lock removed")
+	return nil
+}
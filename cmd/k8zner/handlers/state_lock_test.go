@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/state"
+)
+
+// memoryBackend keeps the state and lock in memory.
+type memoryBackend struct {
+	mu     sync.Mutex
+	bundle *state.Bundle
+	lock   *state.LockInfo
+	saves  int
+}
+
+func (m *memoryBackend) Load(_ context.Context) (*state.Bundle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.bundle, nil
+}
+
+func (m *memoryBackend) Save(_ context.Context, b *state.Bundle, lockID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lock == nil || m.lock.ID != lockID {
+		return state.ErrLockLost
+	}
+	m.bundle = b
+	m.saves++
+	return nil
+}
+
+func (m *memoryBackend) Lock(_ context.Context, info state.LockInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lock != nil && m.lock.ID != info.ID && !m.lock.Expired(time.Now()) {
+		return &state.LockedError{Lock: *m.lock}
+	}
+	m.lock = &info
+	return nil
+}
+
+func (m *memoryBackend) Unlock(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lock != nil && m.lock.ID == id {
+		m.lock = nil
+	}
+	return nil
+}
+
+func (m *memoryBackend) ForceUnlock(_ context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lock = nil
+	return nil
+}
+
+func (m *memoryBackend) ReadLock(_ context.Context) (*state.LockInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lock, nil
+}
+
+func (m *memoryBackend) Remote() bool {
+	return true
+}
+
+// setupStateLockTest stubs config loading and the state backend, and runs the
+// test in an empty directory.
+func setupStateLockTest(t *testing.T) *memoryBackend {
+	t.Helper()
+	origLoad := loadV2ConfigFile
+	origExpand := expandV2Config
+	origBackend := newStateBackend
+	origFiles := localFiles
+	t.Cleanup(func() {
+		loadV2ConfigFile = origLoad
+		expandV2Config = origExpand
+		newStateBackend = origBackend
+		localFiles = origFiles
+	})
+	t.Setenv(state.PassphraseEnv, "test-passphrase")
+
+	loadV2ConfigFile = func(_ string) (*config.Spec, error) {
+		return &config.Spec{Name: "test", Region: config.RegionFalkenstein, Mode: config.ModeDev, Workers: config.WorkerSpec{Count: 1, Size: config.SizeCX22}}, nil
+	}
+	expandV2Config = func(_ *config.Spec) (*config.Config, error) {
+		return &config.Config{ClusterName: "test", State: config.StateConfig{Backend: config.StateBackendS3}}, nil
+	}
+	backend := &memoryBackend{}
+	newStateBackend = func(_ config.StateConfig, _ string) (state.Backend, error) {
+		return backend, nil
+	}
+
+	t.Chdir(t.TempDir())
+	return backend
+}
+
+func TestWithStateLock_PullsAndSavesState(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	backend := setupStateLockTest(t)
+	backend.bundle = &state.Bundle{Cluster: "test", Files: map[string][]byte{secretsFile: []byte("cluster: {}\n")}}
+	cfg := &config.Config{ClusterName: "test"}
+
+	err := withStateLock(context.Background(), cfg, "apply", func(context.Context) error {
+		data, err := os.ReadFile(secretsFile)
+		require.NoError(t, err)
+		assert.Equal(t, "cluster: {}\n", string(data), "state is pulled before the run")
+		require.NotNil(t, backend.lock)
+		assert.Equal(t, "apply", backend.lock.Operation)
+		return os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\n"), 0600)
+	})
+	require.NoError(t, err)
+
+	assert.Nil(t, backend.lock, "lock is released")
+	assert.Equal(t, 1, backend.saves)
+	assert.ElementsMatch(t, []string{secretsFile, kubeconfigPath}, backend.bundle.Names())
+}
+
+func TestPullState_KeepsRemoteStateEncrypted(t *testing.T) {
+	// Serial: swaps the package-global localFiles and changes the working directory.
+	orig := localFiles
+	t.Cleanup(func() { localFiles = orig })
+	t.Chdir(t.TempDir())
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	remote := config.StateConfig{Backend: config.StateBackendS3, Recipients: []string{id.Recipient().String()}}
+	require.NoError(t, useSecretsEncryption(&config.Config{State: remote}))
+	backend := &memoryBackend{bundle: &state.Bundle{Cluster: "test", Files: map[string][]byte{secretsFile: []byte("cluster: {}\n")}}}
+
+	_, err = pullState(context.Background(), backend)
+	require.NoError(t, err)
+	assert.NoFileExists(t, secretsFile, "no plaintext copy is left in the workspace")
+	raw, err := os.ReadFile(secretsFile + state.EncryptedSuffix)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "cluster:")
+}
+
+func TestWithStateLock_SkipsUnchangedState(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	backend := setupStateLockTest(t)
+	backend.bundle = &state.Bundle{Cluster: "test", Files: map[string][]byte{secretsFile: []byte("cluster: {}\n")}}
+
+	require.NoError(t, withStateLock(context.Background(), &config.Config{ClusterName: "test"}, "apply", func(context.Context) error { return nil }))
+	assert.Zero(t, backend.saves)
+}
+
+func TestWithStateLock_SavesStateOnFailure(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	backend := setupStateLockTest(t)
+
+	err := withStateLock(context.Background(), &config.Config{ClusterName: "test"}, "apply", func(context.Context) error {
+		require.NoError(t, os.WriteFile(secretsFile, []byte("generated"), 0600))
+		return errors.New("bootstrap failed")
+	})
+	assert.EqualError(t, err, "bootstrap failed")
+	require.NotNil(t, backend.bundle)
+	assert.Equal(t, "generated", string(backend.bundle.Files[secretsFile]))
+	assert.Nil(t, backend.lock)
+}
+
+func TestWithStateLock_LocalBackendWithEncryptedFiles(t *testing.T) {
+	// Serial: swaps the package-global localFiles and changes the working directory.
+	setupEncryptedFiles(t)
+	require.NoError(t, writeLocalFile(secretsFile, []byte("cluster: {}\n")))
+
+	// Without an identity the encrypted files cannot be read, which the
+	// local backend never needs to
+	t.Setenv(state.IdentityEnv, "")
+	t.Setenv(state.PassphraseEnv, "")
+	ran := false
+	err := withStateLock(context.Background(), &config.Config{ClusterName: "test"}, "apply", func(context.Context) error {
+		ran = true
+		return writeLocalFile(kubeconfigPath, []byte("apiVersion: v1\n"))
+	})
+	require.NoError(t, err)
+	assert.True(t, ran)
+	assert.NoFileExists(t, state.LockFileName, "lock is released")
+}
+
+func TestWithStateLock_Locked(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	backend := setupStateLockTest(t)
+	held := state.NewLockInfo("destroy", time.Hour)
+	backend.lock = &held
+
+	ran := false
+	err := withStateLock(context.Background(), &config.Config{ClusterName: "test"}, "apply", func(context.Context) error {
+		ran = true
+		return nil
+	})
+	assert.ErrorContains(t, err, "k8zner state unlock --force")
+	assert.False(t, ran)
+	assert.Equal(t, held.ID, backend.lock.ID, "the other lock is kept")
+}
+
+func TestWithStateLock_LockTakenOverDuringRun(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	backend := setupStateLockTest(t)
+	origTTL := stateLockTTL
+	t.Cleanup(func() { stateLockTTL = origTTL })
+	stateLockTTL = 30 * time.Millisecond
+	other := state.NewLockInfo("apply", time.Hour)
+
+	err := withStateLock(context.Background(), &config.Config{ClusterName: "test"}, "apply", func(ctx context.Context) error {
+		// The run stalls past its lease and another run takes the lock over
+		backend.mu.Lock()
+		backend.lock = &other
+		backend.mu.Unlock()
+		require.NoError(t, os.WriteFile(secretsFile, []byte("stale"), 0600))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return errors.New("run was not canceled")
+		}
+	})
+	require.ErrorIs(t, err, state.ErrLockLost)
+	assert.ErrorContains(t, err, "the state was not saved")
+	assert.Zero(t, backend.saves, "the state of the other run is not overwritten")
+	assert.Nil(t, backend.bundle)
+	assert.Equal(t, other.ID, backend.lock.ID, "the other run keeps the lock")
+}
+
+func TestDestroy_StateLocked(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	backend := setupStateLockTest(t)
+	held := state.NewLockInfo("apply", time.Hour)
+	backend.lock = &held
+
+	err := Destroy(context.Background(), "k8zner.yaml")
+	assert.ErrorContains(t, err, "state is locked")
+}
+
+func TestStateUnlock(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	backend := setupStateLockTest(t)
+	require.NoError(t, StateUnlock(context.Background(), "k8zner.yaml", false), "nothing to unlock")
+
+	held := state.NewLockInfo("apply", time.Hour)
+	backend.lock = &held
+	err := StateUnlock(context.Background(), "k8zner.yaml", false)
+	assert.ErrorContains(t, err, "--force")
+	assert.NotNil(t, backend.lock)
+
+	require.NoError(t, StateUnlock(context.Background(), "k8zner.yaml", true))
+	assert.Nil(t, backend.lock)
+}
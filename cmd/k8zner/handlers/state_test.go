@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/s3"
+	"github.com/milankappen/k8zner/internal/state"
+)
+
+// memoryStore is an in-memory bucket.
+type memoryStore struct {
+	objects map[string][]byte
+}
+
+func (m *memoryStore) ListObjectInfo(_ context.Context, _, prefix string) ([]s3.ObjectInfo, error) {
+	var infos []s3.ObjectInfo
+	for key, data := range m.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, s3.ObjectInfo{Key: key, Size: int64(len(data))})
+		}
+	}
+	return infos, nil
+}
+
+func (m *memoryStore) GetObject(_ context.Context, _, key string) ([]byte, error) {
+	return m.objects[key], nil
+}
+
+func (m *memoryStore) PutObject(_ context.Context, _, key string, data []byte) error {
+	m.objects[key] = data
+	return nil
+}
+
+// setupStateTest stubs config loading, the bucket and the cluster client, and
+// runs the test in a directory holding the local credential files.
+func setupStateTest(t *testing.T, objects ...client.Object) *memoryStore {
+	t.Helper()
+	origLoad := loadV2ConfigFile
+	origExpand := expandV2Config
+	origRemote := newStateRemote
+	origClient := newClusterClient
+	t.Cleanup(func() {
+		loadV2ConfigFile = origLoad
+		expandV2Config = origExpand
+		newStateRemote = origRemote
+		newClusterClient = origClient
+	})
+
+	loadV2ConfigFile = func(_ string) (*config.Spec, error) {
+		return &config.Spec{Name: "test", Region: config.RegionFalkenstein, Mode: config.ModeDev, Workers: config.WorkerSpec{Count: 1, Size: config.SizeCX22}}, nil
+	}
+	expandV2Config = func(_ *config.Spec) (*config.Config, error) {
+		return &config.Config{ClusterName: "test"}, nil
+	}
+	store := &memoryStore{objects: make(map[string][]byte)}
+	newStateRemote = func(_ config.TalosBackupConfig, cluster string) (*state.Remote, error) {
+		return state.NewRemote(store, "test-etcd-backups", cluster), nil
+	}
+	newClusterClient = func(_ []byte) (client.Client, error) {
+		return fake.NewClientBuilder().WithScheme(k8znerv1alpha1.Scheme).WithObjects(objects...).Build(), nil
+	}
+
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile(secretsFile, []byte("cluster:\n  id: test\n"), 0600))
+	require.NoError(t, os.WriteFile(talosConfigPath, []byte("context: test\n"), 0600))
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte("apiVersion: v1\nkind: Config\n"), 0600))
+	return store
+}
+
+func testClusterObjects() []client.Object {
+	return []client.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: credentialsSecretName, Namespace: k8znerNamespace, ResourceVersion: "42"},
+			Data:       map[string][]byte{k8znerv1alpha1.CredentialsKeyHCloudToken: []byte("test-token")},
+		},
+		&k8znerv1alpha1.K8znerCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: k8znerNamespace},
+			Spec:       k8znerv1alpha1.K8znerClusterSpec{Region: "fsn1"},
+			Status:     k8znerv1alpha1.K8znerClusterStatus{Phase: k8znerv1alpha1.ClusterPhaseRunning},
+		},
+	}
+}
+
+func TestStateExportImport(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	setupStateTest(t, testClusterObjects()...)
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile("key.txt", []byte(id.String()+"\n"), 0600))
+
+	require.NoError(t, StateExport(context.Background(), "k8zner.yaml", "", []string{id.Recipient().String()}, false))
+	sealed, err := os.ReadFile("test-state.age")
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "test-token")
+
+	bundle, err := state.Open(sealed, id)
+	require.NoError(t, err)
+	assert.Equal(t, "test", bundle.Cluster)
+	assert.ElementsMatch(t, []string{secretsFile, talosConfigPath, kubeconfigPath, state.FileCredentials, state.FileCluster}, bundle.Names())
+	secret := string(bundle.Files[state.FileCredentials])
+	assert.Contains(t, secret, "kind: Secret")
+	assert.NotContains(t, secret, "resourceVersion")
+	cluster := string(bundle.Files[state.FileCluster])
+	assert.Contains(t, cluster, "kind: K8znerCluster")
+	assert.Contains(t, cluster, "region: fsn1")
+	assert.NotContains(t, cluster, "Running", "status is not exported")
+
+	for _, name := range []string{secretsFile, talosConfigPath, kubeconfigPath} {
+		require.NoError(t, os.Remove(name))
+	}
+	require.NoError(t, StateImport(context.Background(), "k8zner.yaml", "test-state.age", "", "key.txt", false))
+	data, err := os.ReadFile(secretsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "cluster:\n  id: test\n", string(data))
+	_, err = os.Stat(state.FileCluster)
+	assert.NoError(t, err)
+}
+
+func TestStateImport_KeepsExistingFiles(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	setupStateTest(t)
+	t.Setenv(state.PassphraseEnv, "test passphrase")
+	require.NoError(t, StateExport(context.Background(), "k8zner.yaml", "bundle.age", nil, false))
+	require.NoError(t, os.WriteFile(secretsFile, []byte("newer"), 0600))
+
+	err := StateImport(context.Background(), "k8zner.yaml", "bundle.age", "", "", false)
+	assert.ErrorContains(t, err, "secrets.yaml already exists")
+
+	require.NoError(t, StateImport(context.Background(), "k8zner.yaml", "bundle.age", "", "", true))
+	data, err := os.ReadFile(secretsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "cluster:\n  id: test\n", string(data))
+}
+
+func TestStateExport_PushAndPull(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	store := setupStateTest(t)
+	t.Setenv(state.PassphraseEnv, "test passphrase")
+
+	require.NoError(t, StateExport(context.Background(), "k8zner.yaml", "", nil, true))
+	require.Len(t, store.objects, 1)
+	for key := range store.objects {
+		assert.True(t, strings.HasPrefix(key, "k8zner-state/test/"), key)
+	}
+
+	require.NoError(t, os.Remove(secretsFile))
+	require.NoError(t, StateImport(context.Background(), "k8zner.yaml", "", state.Latest, "", true))
+	_, err := os.Stat(secretsFile)
+	assert.NoError(t, err)
+}
+
+func TestStateExport_RequiresEncryption(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	setupStateTest(t)
+	t.Setenv(state.PassphraseEnv, "")
+
+	err := StateExport(context.Background(), "k8zner.yaml", "", nil, false)
+	assert.ErrorContains(t, err, "must be encrypted")
+
+	t.Setenv(state.PassphraseEnv, "test passphrase")
+	err = StateExport(context.Background(), "k8zner.yaml", "", []string{"age1invalid"}, false)
+	assert.ErrorContains(t, err, "either --recipient or")
+}
+
+func TestStateExport_RequiresSecrets(t *testing.T) {
+	// Serial: swaps package-global factory vars and changes the working directory.
+	setupStateTest(t)
+	t.Setenv(state.PassphraseEnv, "test passphrase")
+	require.NoError(t, os.Remove(secretsFile))
+
+	err := StateExport(context.Background(), "k8zner.yaml", "", nil, false)
+	assert.ErrorContains(t, err, "secrets.yaml")
+}
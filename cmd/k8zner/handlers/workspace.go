@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/state"
+)
+
+var (
+	// workDir holds k8zner.yaml and the credential files: the workspace of
+	// the selected cluster, or the working directory without one.
+	workDir = "."
+
+	// newWorkspaces opens the workspaces under K8ZNER_HOME (for testing injection).
+	newWorkspaces = state.DefaultWorkspaces
+)
+
+// SelectWorkspace makes the following handlers use the workspace of cluster,
+// or of the cluster selected with 'k8zner use' when cluster is empty. With
+// neither, or without a home directory to hold workspaces, they keep using
+// the working directory. A workspace named explicitly is created if needed,
+// so init, import and migrate can fill it.
+func SelectWorkspace(cluster string) error {
+	workspaces, err := newWorkspaces()
+	if err != nil {
+		if cluster == "" {
+			return nil
+		}
+		return err
+	}
+
+	if cluster != "" {
+		dir, err := workspaces.Create(cluster)
+		if err != nil {
+			return err
+		}
+		setWorkDir(dir)
+		return nil
+	}
+
+	current, err := workspaces.Current()
+	if err != nil || current == "" {
+		return err
+	}
+	if !workspaces.Exists(current) {
+		return fmt.Errorf("the selected cluster %s has no workspace in %s, select another with 'k8zner use'", current, workspaces.Home())
+	}
+	setWorkDir(workspaces.Dir(current))
+	return nil
+}
+
+// setWorkDir points localFiles and the config file lookup at dir.
+func setWorkDir(dir string) {
+	workDir = dir
+	localFiles = state.NewLocalFiles(dir, nil)
+}
+
+// workspacePath resolves a relative path of a file kept with the cluster,
+// such as k8zner.yaml, in the selected workspace.
+func workspacePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(workDir, path)
+}
+
+// findConfigFile finds k8zner.yaml in the selected workspace, or from the
+// working directory upwards without one.
+func findConfigFile() (string, error) {
+	if workDir == "." {
+		return config.FindConfigFile()
+	}
+	path := filepath.Join(workDir, config.DefaultConfigFilename)
+	if !fileExists(path) {
+		return "", fmt.Errorf("%s not found in workspace %s", config.DefaultConfigFilename, workDir)
+	}
+	return path, nil
+}
+
+// ClustersList prints the clusters that have a workspace, marking the one
+// selected with 'k8zner use'.
+func ClustersList() error {
+	workspaces, err := newWorkspaces()
+	if err != nil {
+		return err
+	}
+	clusters, err := workspaces.List()
+	if err != nil {
+		return err
+	}
+	if len(clusters) == 0 {
+		fmt.Printf("No cluster workspaces in %s.\n", workspaces.Home())
+		fmt.Printf("Create one with: k8zner init --workspace <cluster>\n")
+		return nil
+	}
+	current, err := workspaces.Current()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("  %-24s  %-6s  %-4s  %s\n", "NAME", "REGION", "MODE", "KUBECONFIG")
+	for _, name := range clusters {
+		dir := workspaces.Dir(name)
+		region, mode := "-", "-"
+		if spec, err := config.LoadSpecWithoutValidation(filepath.Join(dir, config.DefaultConfigFilename)); err == nil {
+			region, mode = string(spec.Region), string(spec.Mode)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			region = "invalid config"
+		}
+		kubeconfig := "no"
+		if state.NewLocalFiles(dir, nil).Exists(kubeconfigPath) {
+			kubeconfig = "yes"
+		}
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Printf("%s %-24s  %-6s  %-4s  %s\n", marker, name, region, mode, kubeconfig)
+	}
+	return nil
+}
+
+// Use selects the workspace of cluster for the following commands, or
+// clears the selection when clear is set, so they use the working
+// directory again.
+func Use(cluster string, clear bool) error {
+	workspaces, err := newWorkspaces()
+	if err != nil {
+		return err
+	}
+	if clear {
+		if err := workspaces.SetCurrent(""); err != nil {
+			return err
+		}
+		fmt.Println("No cluster selected, commands use the working directory.")
+		return nil
+	}
+	if err := workspaces.SetCurrent(cluster); err != nil {
+		return err
+	}
+	fmt.Printf("Switched to cluster %s (%s).\n", cluster, workspaces.Dir(cluster))
+	return nil
+}
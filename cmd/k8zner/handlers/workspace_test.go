@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/state"
+)
+
+// useTestWorkspaces points newWorkspaces at a temporary K8ZNER_HOME and
+// restores workDir and localFiles afterwards.
+func useTestWorkspaces(t *testing.T) *state.Workspaces {
+	t.Helper()
+	origWorkspaces, origWorkDir, origLocalFiles := newWorkspaces, workDir, localFiles
+	t.Cleanup(func() {
+		newWorkspaces, workDir, localFiles = origWorkspaces, origWorkDir, origLocalFiles
+	})
+	workspaces := state.NewWorkspaces(t.TempDir())
+	newWorkspaces = func() (*state.Workspaces, error) { return workspaces, nil }
+	return workspaces
+}
+
+// Serial: swaps the package-level newWorkspaces, workDir and localFiles.
+func TestSelectWorkspace(t *testing.T) {
+	workspaces := useTestWorkspaces(t)
+
+	require.NoError(t, SelectWorkspace(""))
+	assert.Equal(t, ".", workDir, "without a selection the working directory is used")
+
+	require.NoError(t, SelectWorkspace("prod"))
+	assert.Equal(t, workspaces.Dir("prod"), workDir)
+	assert.True(t, workspaces.Exists("prod"), "a named workspace is created")
+
+	require.NoError(t, workspaces.SetCurrent("prod"))
+	setWorkDir(".")
+	require.NoError(t, SelectWorkspace(""))
+	assert.Equal(t, workspaces.Dir("prod"), workDir)
+
+	_, err := workspaces.Create("dev")
+	require.NoError(t, err)
+	require.NoError(t, SelectWorkspace("dev"), "--workspace overrides the selection")
+	assert.Equal(t, workspaces.Dir("dev"), workDir)
+
+	assert.Error(t, SelectWorkspace("../prod"))
+}
+
+// Serial: swaps the package-level newWorkspaces, workDir and localFiles.
+func TestSelectWorkspace_RemovedWorkspace(t *testing.T) {
+	workspaces := useTestWorkspaces(t)
+	_, err := workspaces.Create("prod")
+	require.NoError(t, err)
+	require.NoError(t, workspaces.SetCurrent("prod"))
+	require.NoError(t, os.RemoveAll(workspaces.Dir("prod")))
+
+	err = SelectWorkspace("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "select another with 'k8zner use'")
+}
+
+// Serial: swaps the package-level newWorkspaces, workDir and localFiles.
+func TestSelectWorkspace_WithoutHome(t *testing.T) {
+	useTestWorkspaces(t)
+	newWorkspaces = func() (*state.Workspaces, error) {
+		return nil, errors.New("failed to find the home directory, set K8ZNER_HOME")
+	}
+
+	require.NoError(t, SelectWorkspace(""))
+	assert.Equal(t, ".", workDir, "the working directory is used without a home directory")
+
+	err := SelectWorkspace("prod")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "set K8ZNER_HOME")
+}
+
+// Serial: swaps the package-level newWorkspaces, workDir and localFiles.
+func TestWorkspaceFiles(t *testing.T) {
+	workspaces := useTestWorkspaces(t)
+	require.NoError(t, SelectWorkspace("prod"))
+	dir := workspaces.Dir("prod")
+
+	assert.Equal(t, filepath.Join(dir, config.DefaultConfigFilename), workspacePath(config.DefaultConfigFilename))
+	assert.Equal(t, "/tmp/k8zner.yaml", workspacePath("/tmp/k8zner.yaml"))
+
+	_, err := findConfigFile()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found in workspace")
+
+	spec := &config.Spec{Name: "prod", Region: config.RegionFalkenstein, Mode: config.ModeDev, Workers: config.WorkerSpec{Count: 1, Size: config.SizeCX23}}
+	require.NoError(t, config.SaveSpec(spec, workspacePath(config.DefaultConfigFilename)))
+	path, err := findConfigFile()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, config.DefaultConfigFilename), path)
+
+	require.NoError(t, writeLocalFile(kubeconfigPath, []byte("apiVersion: v1\n")))
+	assert.FileExists(t, filepath.Join(dir, kubeconfigPath))
+	assert.Equal(t, filepath.Join(dir, kubeconfigPath), localFiles.Path(kubeconfigPath))
+}
+
+// Serial: swaps the package-level newWorkspaces, workDir and localFiles.
+func TestClustersList(t *testing.T) {
+	workspaces := useTestWorkspaces(t)
+
+	out := captureOutput(func() { require.NoError(t, ClustersList()) })
+	assert.Contains(t, out, "No cluster workspaces")
+
+	require.NoError(t, SelectWorkspace("prod"))
+	spec := &config.Spec{Name: "prod", Region: config.RegionFalkenstein, Mode: config.ModeHA, Workers: config.WorkerSpec{Count: 2, Size: config.SizeCX33}}
+	require.NoError(t, config.SaveSpec(spec, workspacePath(config.DefaultConfigFilename)))
+	require.NoError(t, writeLocalFile(kubeconfigPath, []byte("apiVersion: v1\n")))
+	_, err := workspaces.Create("dev")
+	require.NoError(t, err)
+	require.NoError(t, workspaces.SetCurrent("prod"))
+
+	out = captureOutput(func() { require.NoError(t, ClustersList()) })
+	assert.Regexp(t, `(?m)^\* prod\s+fsn1\s+ha\s+yes$`, out)
+	assert.Regexp(t, `(?m)^  dev\s+-\s+-\s+no$`, out)
+}
+
+// Serial: swaps the package-level newWorkspaces, workDir and localFiles.
+func TestUse(t *testing.T) {
+	workspaces := useTestWorkspaces(t)
+
+	err := Use("prod", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no workspace")
+
+	_, err = workspaces.Create("prod")
+	require.NoError(t, err)
+	require.NoError(t, Use("prod", false))
+	current, err := workspaces.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "prod", current)
+
+	require.NoError(t, Use("", true))
+	current, err = workspaces.Current()
+	require.NoError(t, err)
+	assert.Empty(t, current)
+}
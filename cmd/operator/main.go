@@ -12,9 +12,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
 	"github.com/milankappen/k8zner/internal/operator/controller"
+	"github.com/milankappen/k8zner/internal/operator/webhook"
 )
 
 var (
@@ -31,17 +33,27 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == verifyBackupCommand {
+		os.Exit(runVerifyBackup())
+	}
+
 	var (
 		metricsAddr          string
 		probeAddr            string
 		enableLeaderElection bool
 		leaderElectionID     string
+		enableWebhooks       bool
+		webhookPort          int
+		webhookCertDir       string
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", true, "Enable leader election for controller manager.")
 	flag.StringVar(&leaderElectionID, "leader-election-id", "k8zner-operator", "The name of the leader election resource.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Serve the K8znerCluster defaulting and validating admission webhooks.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server listens on.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "", "The directory containing tls.crt and tls.key for the webhook server.")
 
 	opts := zap.Options{
 		Development: os.Getenv("DEBUG") == "true",
@@ -58,6 +70,10 @@ func main() {
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
+		WebhookServer: ctrlwebhook.NewServer(ctrlwebhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+		}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       leaderElectionID,
@@ -86,12 +102,20 @@ func main() {
 		controller.WithHCloudToken(hcloudToken),
 		controller.WithMetrics(true),
 		controller.WithMaxConcurrentHeals(1),
+		controller.WithBackupVerifyImage(os.Getenv("OPERATOR_IMAGE")),
 	)
 	if err = reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "K8znerCluster")
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		if err = (&webhook.ClusterWebhook{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "K8znerCluster")
+			os.Exit(1)
+		}
+	}
+
 	// Add health checks
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
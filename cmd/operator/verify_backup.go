@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"filippo.io/age"
+
+	"github.com/milankappen/k8zner/internal/backup"
+	"github.com/milankappen/k8zner/internal/config"
+)
+
+// verifyBackupCommand runs the operator image as a one-shot backup
+// verification instead of the manager. The operator starts it in a Job.
+const verifyBackupCommand = "verify-backup"
+
+// terminationLogPath is where Kubernetes reads the container's termination message.
+const terminationLogPath = "/dev/termination-log"
+
+// runVerifyBackup verifies the newest snapshot in the bucket described by the
+// environment and reports the result as JSON on stdout and in the termination
+// message. It returns the process exit code.
+func runVerifyBackup() int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result := verifyBackup(ctx)
+	out, err := json.Marshal(result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode result: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(out))
+	if err := os.WriteFile(terminationLogPath, out, 0o644); err != nil { //nolint:gosec // read by the kubelet, not secret
+		fmt.Fprintf(os.Stderr, "failed to write termination message: %v\n", err)
+	}
+
+	if result.Error != "" {
+		return 1
+	}
+	return 0
+}
+
+// verifyBackup builds the repository from the talos-backup environment
+// variables and verifies its latest snapshot.
+func verifyBackup(ctx context.Context) backup.Verification {
+	repo, err := backup.NewRepositoryFromConfig(config.TalosBackupConfig{
+		S3Bucket:    os.Getenv("BUCKET"),
+		S3Region:    os.Getenv("AWS_REGION"),
+		S3Endpoint:  os.Getenv("CUSTOM_S3_ENDPOINT"),
+		S3AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		S3SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	})
+	if err != nil {
+		return backup.Verification{Error: err.Error()}
+	}
+
+	var identities []age.Identity
+	if path := os.Getenv("AGE_IDENTITY_FILE"); path != "" {
+		identities, err = backup.LoadIdentities(path)
+		if err != nil {
+			return backup.Verification{Error: err.Error()}
+		}
+	}
+
+	return backup.VerifyLatest(ctx, repo, identities)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -42,6 +43,13 @@ type Client interface {
 	// HasIngressClass checks if an IngressClass with the given name exists.
 	// This is useful for checking Traefik readiness before creating Ingress resources.
 	HasIngressClass(ctx context.Context, name string) (bool, error)
+
+	// CreateJobFromCronJob creates a one-off Job from a CronJob's job template,
+	// like "kubectl create job --from=cronjob/<name>".
+	CreateJobFromCronJob(ctx context.Context, namespace, cronJobName, jobName string) (*batchv1.Job, error)
+
+	// GetJob returns the Job with the given name.
+	GetJob(ctx context.Context, namespace, name string) (*batchv1.Job, error)
 }
 
 // client implements the Client interface using k8s.io/client-go.
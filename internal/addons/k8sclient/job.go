@@ -0,0 +1,53 @@
+package k8sclient
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateJobFromCronJob creates a one-off Job from a CronJob's job template.
+// The Job is owned by the CronJob, so it is garbage collected with it, and
+// carries the "cronjob.kubernetes.io/instantiate: manual" annotation kubectl sets.
+func (c *client) CreateJobFromCronJob(ctx context.Context, namespace, cronJobName, jobName string) (*batchv1.Job, error) {
+	cronJob, err := c.clientset.BatchV1().CronJobs(namespace).Get(ctx, cronJobName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CronJob %s/%s: %w", namespace, cronJobName, err)
+	}
+
+	template := cronJob.Spec.JobTemplate
+	annotations := map[string]string{"cronjob.kubernetes.io/instantiate": "manual"}
+	for k, v := range template.Annotations {
+		annotations[k] = v
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        jobName,
+			Namespace:   namespace,
+			Labels:      template.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob")),
+			},
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+
+	created, err := c.clientset.BatchV1().Jobs(namespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Job %s/%s: %w", namespace, jobName, err)
+	}
+	return created, nil
+}
+
+// GetJob returns the Job with the given name.
+func (c *client) GetJob(ctx context.Context, namespace, name string) (*batchv1.Job, error) {
+	job, err := c.clientset.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Job %s/%s: %w", namespace, name, err)
+	}
+	return job, nil
+}
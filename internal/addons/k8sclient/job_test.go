@@ -0,0 +1,69 @@
+package k8sclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCreateJobFromCronJob(t *testing.T) {
+	t.Parallel()
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "talos-backup", Namespace: "kube-system", UID: "cronjob-uid"},
+		Spec: batchv1.CronJobSpec{
+			Schedule: "0 * * * *",
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "talos-backup"}},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers:    []corev1.Container{{Name: "talos-backup", Image: "ghcr.io/siderolabs/talos-backup:v0.1.0"}},
+							RestartPolicy: corev1.RestartPolicyOnFailure,
+						},
+					},
+				},
+			},
+		},
+	}
+	//nolint:staticcheck // SA1019: NewSimpleClientset is sufficient for our testing needs
+	clientset := fake.NewSimpleClientset(cronJob)
+	c := &client{clientset: clientset}
+
+	job, err := c.CreateJobFromCronJob(context.Background(), "kube-system", "talos-backup", "talos-backup-manual-1")
+	require.NoError(t, err)
+	assert.Equal(t, "talos-backup-manual-1", job.Name)
+	assert.Equal(t, "talos-backup", job.Labels["app"])
+	assert.Equal(t, "manual", job.Annotations["cronjob.kubernetes.io/instantiate"])
+	require.Len(t, job.OwnerReferences, 1)
+	assert.Equal(t, "CronJob", job.OwnerReferences[0].Kind)
+	assert.Equal(t, "talos-backup", job.Spec.Template.Spec.Containers[0].Name)
+
+	got, err := c.GetJob(context.Background(), "kube-system", "talos-backup-manual-1")
+	require.NoError(t, err)
+	assert.Equal(t, job.Name, got.Name)
+}
+
+func TestCreateJobFromCronJob_MissingCronJob(t *testing.T) {
+	t.Parallel()
+	//nolint:staticcheck // SA1019: NewSimpleClientset is sufficient for our testing needs
+	c := &client{clientset: fake.NewSimpleClientset()}
+
+	_, err := c.CreateJobFromCronJob(context.Background(), "kube-system", "talos-backup", "talos-backup-manual-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get CronJob kube-system/talos-backup")
+}
+
+func TestGetJob_NotFound(t *testing.T) {
+	t.Parallel()
+	//nolint:staticcheck // SA1019: NewSimpleClientset is sufficient for our testing needs
+	c := &client{clientset: fake.NewSimpleClientset()}
+
+	_, err := c.GetJob(context.Background(), "kube-system", "missing")
+	require.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -59,6 +60,18 @@ func (m *mockK8sClient) HasIngressClass(ctx context.Context, name string) (bool,
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *mockK8sClient) CreateJobFromCronJob(ctx context.Context, namespace, cronJobName, jobName string) (*batchv1.Job, error) {
+	args := m.Called(ctx, namespace, cronJobName, jobName)
+	job, _ := args.Get(0).(*batchv1.Job)
+	return job, args.Error(1)
+}
+
+func (m *mockK8sClient) GetJob(ctx context.Context, namespace, name string) (*batchv1.Job, error) {
+	args := m.Called(ctx, namespace, name)
+	job, _ := args.Get(0).(*batchv1.Job)
+	return job, args.Error(1)
+}
+
 func TestApplyManifests(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -0,0 +1,97 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ErrIdentityRequired is returned when decoding an encrypted snapshot without identities.
+var ErrIdentityRequired = errors.New("snapshot is age-encrypted and no identity was provided")
+
+var (
+	// ageHeader starts every binary age file.
+	ageHeader = []byte("age-encryption.org/v1")
+
+	// zstdMagic starts every zstd frame.
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// LoadIdentities reads age identities from a file in the format written by age-keygen.
+func LoadIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(path) //nolint:gosec // path is supplied by the user on purpose
+	if err != nil {
+		return nil, fmt.Errorf("failed to open identity file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity file %s: %w", path, err)
+	}
+	return identities, nil
+}
+
+// Decode turns a stored snapshot into a raw etcd snapshot. Encrypted snapshots
+// are decrypted with identities and compressed snapshots are decompressed; the
+// format is detected from the content, not the key, so snapshots written with
+// any combination of talos-backup's encryption and compression settings work.
+func Decode(data []byte, identities []age.Identity) ([]byte, error) {
+	data, err := decrypt(data, identities)
+	if err != nil {
+		return nil, err
+	}
+	return decompress(data)
+}
+
+// decrypt decrypts binary or armored age data and returns other data unchanged.
+func decrypt(data []byte, identities []age.Identity) ([]byte, error) {
+	var src io.Reader
+	switch {
+	case bytes.HasPrefix(data, ageHeader):
+		src = bytes.NewReader(data)
+	case bytes.HasPrefix(data, []byte(armor.Header)):
+		src = armor.NewReader(bytes.NewReader(data))
+	default:
+		return data, nil
+	}
+
+	if len(identities) == 0 {
+		return nil, ErrIdentityRequired
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt snapshot: %w", err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt snapshot: %w", err)
+	}
+	return plain, nil
+}
+
+// decompress decompresses zstd data and returns other data unchanged.
+func decompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, zstdMagic) {
+		return data, nil
+	}
+
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+	defer dec.Close()
+
+	raw, err := dec.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+	return raw, nil
+}
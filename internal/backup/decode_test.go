@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSnapshot stands in for an etcd snapshot.
+var testSnapshot = []byte("etcd snapshot contents")
+
+// encrypt age-encrypts data to identity, optionally armored.
+func encrypt(t *testing.T, data []byte, identity *age.X25519Identity, armored bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var dst io.Writer = &buf
+	var armorWriter io.WriteCloser
+	if armored {
+		armorWriter = armor.NewWriter(&buf)
+		dst = armorWriter
+	}
+
+	w, err := age.Encrypt(dst, identity.Recipient())
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	if armorWriter != nil {
+		require.NoError(t, armorWriter.Close())
+	}
+	return buf.Bytes()
+}
+
+// compress zstd-compresses data.
+func compress(t *testing.T, data []byte) []byte {
+	t.Helper()
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer func() { _ = enc.Close() }()
+	return enc.EncodeAll(data, nil)
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data func(t *testing.T) []byte
+	}{
+		{"plain", func(*testing.T) []byte { return testSnapshot }},
+		{"compressed", func(t *testing.T) []byte { return compress(t, testSnapshot) }},
+		{"encrypted", func(t *testing.T) []byte { return encrypt(t, testSnapshot, identity, false) }},
+		{"armored", func(t *testing.T) []byte { return encrypt(t, testSnapshot, identity, true) }},
+		{"compressed and encrypted", func(t *testing.T) []byte {
+			return encrypt(t, compress(t, testSnapshot), identity, false)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Decode(tt.data(t), []age.Identity{identity})
+			require.NoError(t, err)
+			assert.Equal(t, testSnapshot, got)
+		})
+	}
+}
+
+func TestDecode_EncryptedWithoutIdentity(t *testing.T) {
+	t.Parallel()
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	_, err = Decode(encrypt(t, testSnapshot, identity, false), nil)
+	assert.ErrorIs(t, err, ErrIdentityRequired)
+}
+
+func TestDecode_WrongIdentity(t *testing.T) {
+	t.Parallel()
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	_, err = Decode(encrypt(t, testSnapshot, identity, false), []age.Identity{other})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decrypt snapshot")
+}
+
+func TestLoadIdentities(t *testing.T) {
+	t.Parallel()
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.txt")
+	content := "# created: 2026-01-01T00:00:00Z\n# public key: " + identity.Recipient().String() + "\n" + identity.String() + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	identities, err := LoadIdentities(path)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+
+	got, err := Decode(encrypt(t, testSnapshot, identity, false), identities)
+	require.NoError(t, err)
+	assert.Equal(t, testSnapshot, got)
+}
+
+func TestLoadIdentities_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := LoadIdentities(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.txt")
+	require.NoError(t, os.WriteFile(path, []byte("not a key\n"), 0600))
+	_, err = LoadIdentities(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,9 @@
+// Package backup locates, downloads and decodes the etcd snapshots that the
+// talos-backup CronJob stores in Hetzner Object Storage.
+//
+// Snapshots live under [SnapshotPrefix] in the cluster's backup bucket. They
+// are zstd-compressed and, unless encryption is disabled, age-encrypted;
+// [Decode] reverses both so the result can be handed to Talos' etcd recovery.
+// [Expired] implements the retention policy the operator prunes the bucket with,
+// and [VerifyLatest] the restore drill its verification Jobs run.
+package backup
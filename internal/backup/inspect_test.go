@@ -0,0 +1,100 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+// revisionKey encodes an etcd key bucket key: main revision, '_', sub revision.
+func revisionKey(main, sub uint64) []byte {
+	k := make([]byte, 17)
+	binary.BigEndian.PutUint64(k, main)
+	k[8] = '_'
+	binary.BigEndian.PutUint64(k[9:], sub)
+	return k
+}
+
+// newTestDatabase builds a small etcd-like bbolt database and returns its bytes.
+func newTestDatabase(t *testing.T) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "member.db")
+	db, err := bolt.Open(path, 0600, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		keys, err := tx.CreateBucket(keyBucket)
+		if err != nil {
+			return err
+		}
+		for rev := uint64(1); rev <= 42; rev++ {
+			if err := keys.Put(revisionKey(rev, 0), []byte("value")); err != nil {
+				return err
+			}
+		}
+		meta, err := tx.CreateBucket([]byte("meta"))
+		if err != nil {
+			return err
+		}
+		return meta.Put([]byte("consistent_index"), []byte{0, 0, 0, 0, 0, 0, 0, 42})
+	}))
+	require.NoError(t, db.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
+
+func TestInspect(t *testing.T) {
+	t.Parallel()
+	data := newTestDatabase(t)
+
+	status, err := Inspect(data)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), status.Revision)
+	assert.Equal(t, 43, status.TotalKeys)
+	assert.Equal(t, int64(len(data)), status.Size)
+	assert.False(t, status.HashVerified)
+}
+
+func TestInspect_WithHash(t *testing.T) {
+	t.Parallel()
+	data := newTestDatabase(t)
+	sum := sha256.Sum256(data)
+
+	status, err := Inspect(append(data, sum[:]...))
+	require.NoError(t, err)
+	assert.True(t, status.HashVerified)
+	assert.Equal(t, int64(42), status.Revision)
+}
+
+func TestInspect_HashMismatch(t *testing.T) {
+	t.Parallel()
+	data := newTestDatabase(t)
+	sum := sha256.Sum256([]byte("something else"))
+
+	_, err := Inspect(append(data, sum[:]...))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hash mismatch")
+}
+
+func TestInspect_NotADatabase(t *testing.T) {
+	t.Parallel()
+	_, err := Inspect([]byte("not an etcd snapshot"))
+	require.Error(t, err)
+}
+
+func TestInspect_Truncated(t *testing.T) {
+	t.Parallel()
+	data := newTestDatabase(t)
+
+	_, err := Inspect(data[:len(data)/2])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "truncated")
+}
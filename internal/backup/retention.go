@@ -0,0 +1,25 @@
+package backup
+
+import "time"
+
+// Expired returns the snapshots that fall outside the retention policy: those
+// older than retention at now, except for the keepLast most recent scheduled
+// backups, which are always kept so a cluster whose backups stopped still has
+// something to restore from. Pre-operation snapshots only expire by age and
+// never take the place of a scheduled backup. snapshots must be sorted newest
+// first, as returned by [Repository.List].
+func Expired(snapshots []Snapshot, retention time.Duration, keepLast int, now time.Time) []Snapshot {
+	cutoff := now.Add(-retention)
+	var expired []Snapshot
+	kept := 0
+	for _, snap := range snapshots {
+		if !IsPreOperation(snap.Key) && kept < keepLast {
+			kept++
+			continue
+		}
+		if snap.CreatedAt.Before(cutoff) {
+			expired = append(expired, snap)
+		}
+	}
+	return expired
+}
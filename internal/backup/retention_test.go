@@ -0,0 +1,103 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpired(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	// One snapshot per day, newest first: 1, 2, ... 9 days old
+	snapshots := make([]Snapshot, 9)
+	for i := range snapshots {
+		snapshots[i] = Snapshot{Key: string(rune('a' + i)), CreatedAt: now.Add(-time.Duration(i+1) * 24 * time.Hour)}
+	}
+
+	keys := func(snaps []Snapshot) []string {
+		out := make([]string, 0, len(snaps))
+		for _, s := range snaps {
+			out = append(out, s.Key)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		snapshots []Snapshot
+		retention time.Duration
+		keepLast  int
+		want      []string
+	}{
+		{"within retention", snapshots, 30 * 24 * time.Hour, 3, []string{}},
+		{"older than retention", snapshots, 7 * 24 * time.Hour, 3, []string{"h", "i"}},
+		{"keeps the latest even if expired", snapshots, time.Hour, 3, []string{"d", "e", "f", "g", "h", "i"}},
+		{"fewer than keepLast", snapshots[:2], time.Hour, 3, []string{}},
+		{"nothing kept", snapshots[:2], time.Hour, 0, []string{"a", "b"}},
+		{"no snapshots", nil, time.Hour, 3, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, keys(Expired(tt.snapshots, tt.retention, tt.keepLast, now)))
+		})
+	}
+}
+
+func TestExpired_PreOperationSnapshots(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	snapshots := []Snapshot{
+		{Key: "etcd-backups/pre-operation/cp-replace-cp-1-2026-01-09T00-00-00Z.snap", CreatedAt: now.Add(-day)},
+		{Key: "etcd-backups/pre-operation/cp-scale-up-2026-01-08T00-00-00Z.snap", CreatedAt: now.Add(-2 * day)},
+		{Key: "etcd-backups/a.snap.zst", CreatedAt: now.Add(-3 * day)},
+		{Key: "etcd-backups/b.snap.zst", CreatedAt: now.Add(-4 * day)},
+		{Key: "etcd-backups/c.snap.zst", CreatedAt: now.Add(-5 * day)},
+	}
+
+	expired := Expired(snapshots, time.Hour, 2, now)
+
+	keys := make([]string, 0, len(expired))
+	for _, s := range expired {
+		keys = append(keys, s.Key)
+	}
+	assert.Equal(t, []string{
+		"etcd-backups/pre-operation/cp-replace-cp-1-2026-01-09T00-00-00Z.snap",
+		"etcd-backups/pre-operation/cp-scale-up-2026-01-08T00-00-00Z.snap",
+		"etcd-backups/c.snap.zst",
+	}, keys, "pre-operation snapshots do not take the place of the newest backups")
+}
+
+func TestDelete(t *testing.T) {
+	t.Parallel()
+	repo := newTestRepository()
+	store := repo.store.(*fakeStore)
+
+	require.NoError(t, repo.Delete(context.Background(), "etcd-backups/prod-2026-01-02T03-00-00Z.snap.zst"))
+	assert.Equal(t, []string{"etcd-backups/prod-2026-01-02T03-00-00Z.snap.zst"}, store.deleted)
+}
+
+func TestPut(t *testing.T) {
+	t.Parallel()
+	repo := newTestRepository()
+	store := repo.store.(*fakeStore)
+
+	key := PreOperationKey("cp-replace", "prod-cp-2", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.Equal(t, "etcd-backups/pre-operation/cp-replace-prod-cp-2-2026-01-02T03-04-05Z.snap", key)
+
+	require.NoError(t, repo.Put(context.Background(), key, []byte("snapshot")))
+	assert.Equal(t, []byte("snapshot"), store.data[key])
+}
+
+func TestPreOperationKey_WithoutNode(t *testing.T) {
+	t.Parallel()
+	berlin := time.FixedZone("CET", 3600)
+	key := PreOperationKey("cp-scale-up", "", time.Date(2026, 1, 2, 4, 0, 0, 0, berlin))
+	assert.Equal(t, "etcd-backups/pre-operation/cp-scale-up-2026-01-02T03-00-00Z.snap", key)
+}
@@ -0,0 +1,182 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+const (
+	// SnapshotPrefix is the key prefix talos-backup writes snapshots under.
+	// It matches the S3_PREFIX of the talos-backup CronJob.
+	SnapshotPrefix = "etcd-backups"
+
+	// PreOperationPrefix is the key prefix of the snapshots the operator takes
+	// before disruptive operations. They are listed with the scheduled backups
+	// but are no backups of their own, see [IsPreOperation].
+	PreOperationPrefix = SnapshotPrefix + "/pre-operation"
+
+	// Latest selects the most recent snapshot.
+	Latest = "latest"
+)
+
+// preOperationTimeFormat is the UTC timestamp in pre-operation snapshot keys.
+// Colons are avoided like in talos-backup's keys.
+const preOperationTimeFormat = "2006-01-02T15-04-05Z"
+
+// ObjectStore is the subset of the S3 client needed to find, store, download and prune snapshots.
+type ObjectStore interface {
+	ListObjectInfo(ctx context.Context, bucketName, prefix string) ([]s3.ObjectInfo, error)
+	GetObject(ctx context.Context, bucketName, key string) ([]byte, error)
+	PutObject(ctx context.Context, bucketName, key string, data []byte) error
+	DeleteObject(ctx context.Context, bucketName, key string) error
+	GetMetadata(ctx context.Context, bucketName string) (*s3.BucketMetadata, error)
+}
+
+// Snapshot is an etcd snapshot stored in the backup bucket.
+type Snapshot struct {
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Repository gives access to the snapshots of one cluster.
+type Repository struct {
+	store  ObjectStore
+	bucket string
+}
+
+// NewRepository creates a repository for the snapshots in bucket.
+func NewRepository(store ObjectStore, bucket string) *Repository {
+	return &Repository{store: store, bucket: bucket}
+}
+
+// NewRepositoryFromConfig creates a repository for the bucket configured for talos-backup.
+func NewRepositoryFromConfig(cfg config.TalosBackupConfig) (*Repository, error) {
+	if cfg.S3Bucket == "" || cfg.S3Endpoint == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+		return nil, fmt.Errorf("backup storage is not configured: s3_bucket, s3_endpoint, s3_access_key and s3_secret_key are required")
+	}
+
+	client, err := s3.NewClient(cfg.S3Endpoint, cfg.S3Region, cfg.S3AccessKey, cfg.S3SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return NewRepository(client, cfg.S3Bucket), nil
+}
+
+// Bucket returns the name of the bucket holding the snapshots.
+func (r *Repository) Bucket() string {
+	return r.bucket
+}
+
+// Metadata returns the k8zner ownership metadata of the bucket, or nil if the
+// bucket has none.
+func (r *Repository) Metadata(ctx context.Context) (*s3.BucketMetadata, error) {
+	return r.store.GetMetadata(ctx, r.bucket)
+}
+
+// List returns all snapshots, including pre-operation snapshots, newest first.
+func (r *Repository) List(ctx context.Context) ([]Snapshot, error) {
+	objects, err := r.store.ListObjectInfo(ctx, r.bucket, SnapshotPrefix+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, len(objects))
+	for _, obj := range objects {
+		// Skip directory placeholders some S3 clients create
+		if strings.HasSuffix(obj.Key, "/") {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{Key: obj.Key, Size: obj.Size, CreatedAt: obj.LastModified})
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if !snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+		}
+		// talos-backup keys contain the timestamp, so the key breaks ties
+		return snapshots[i].Key > snapshots[j].Key
+	})
+	return snapshots, nil
+}
+
+// Resolve finds the snapshot referenced by ref, which is either [Latest], the
+// most recent scheduled backup, or a snapshot key. Keys may be given with or
+// without the [SnapshotPrefix].
+func (r *Repository) Resolve(ctx context.Context, ref string) (Snapshot, error) {
+	if ref == "" {
+		return Snapshot{}, fmt.Errorf("snapshot reference must not be empty")
+	}
+
+	snapshots, err := r.List(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if ref == Latest {
+		for _, snap := range snapshots {
+			if !IsPreOperation(snap.Key) {
+				return snap, nil
+			}
+		}
+		return Snapshot{}, fmt.Errorf("no snapshots found in bucket %s", r.bucket)
+	}
+
+	for _, snap := range snapshots {
+		if snap.Key == ref || snap.Key == SnapshotPrefix+"/"+ref {
+			return snap, nil
+		}
+	}
+	return Snapshot{}, fmt.Errorf("snapshot %q not found in bucket %s", ref, r.bucket)
+}
+
+// Fetch downloads the snapshot stored under key and decodes it with identities.
+func (r *Repository) Fetch(ctx context.Context, key string, identities []age.Identity) ([]byte, error) {
+	data, err := r.store.GetObject(ctx, r.bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := Decode(data, identities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", key, err)
+	}
+	return snapshot, nil
+}
+
+// Put stores a raw etcd snapshot under key. Snapshots are stored as taken;
+// [Decode] passes unencrypted, uncompressed data through unchanged.
+func (r *Repository) Put(ctx context.Context, key string, snapshot []byte) error {
+	return r.store.PutObject(ctx, r.bucket, key, snapshot)
+}
+
+// PreOperationKey returns the key for a snapshot taken before operation on
+// node at t, such as "etcd-backups/pre-operation/cp-replace-prod-cp-2-2026-01-02T03-00-00Z.snap".
+// node may be empty for operations that do not target a single node.
+func PreOperationKey(operation, node string, t time.Time) string {
+	name := operation
+	if node != "" {
+		name += "-" + node
+	}
+	return fmt.Sprintf("%s/%s-%s.snap", PreOperationPrefix, name, t.UTC().Format(preOperationTimeFormat))
+}
+
+// IsPreOperation reports whether key is a pre-operation snapshot. Those are
+// kept for restores but never count as the latest backup, are not verified
+// and do not count towards the snapshots retention always keeps.
+func IsPreOperation(key string) bool {
+	return strings.HasPrefix(key, PreOperationPrefix+"/")
+}
+
+// Delete removes the snapshot stored under key.
+func (r *Repository) Delete(ctx context.Context, key string) error {
+	return r.store.DeleteObject(ctx, r.bucket, key)
+}
@@ -0,0 +1,181 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+// fakeStore is an in-memory ObjectStore.
+type fakeStore struct {
+	objects  []s3.ObjectInfo
+	data     map[string][]byte
+	metadata *s3.BucketMetadata
+	listErr  error
+	deleted  []string
+}
+
+func (f *fakeStore) PutObject(_ context.Context, _, key string, data []byte) error {
+	if f.data == nil {
+		f.data = make(map[string][]byte)
+	}
+	f.data[key] = data
+	return nil
+}
+
+func (f *fakeStore) ListObjectInfo(_ context.Context, _, _ string) ([]s3.ObjectInfo, error) {
+	return f.objects, f.listErr
+}
+
+func (f *fakeStore) GetObject(_ context.Context, bucket, key string) ([]byte, error) {
+	data, ok := f.data[key]
+	if !ok {
+		return nil, errors.New("failed to get object " + key + " from bucket " + bucket)
+	}
+	return data, nil
+}
+
+func (f *fakeStore) DeleteObject(_ context.Context, _, key string) error {
+	f.deleted = append(f.deleted, key)
+	return nil
+}
+
+func (f *fakeStore) GetMetadata(_ context.Context, _ string) (*s3.BucketMetadata, error) {
+	return f.metadata, nil
+}
+
+func newTestRepository() *Repository {
+	base := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	return NewRepository(&fakeStore{
+		objects: []s3.ObjectInfo{
+			{Key: "etcd-backups/", Size: 0, LastModified: base},
+			{Key: "etcd-backups/prod-2026-01-02T01-00-00Z.snap.zst", Size: 100, LastModified: base.Add(time.Hour)},
+			{Key: "etcd-backups/prod-2026-01-02T03-00-00Z.snap.zst", Size: 300, LastModified: base.Add(3 * time.Hour)},
+			{Key: "etcd-backups/prod-2026-01-02T02-00-00Z.snap.zst", Size: 200, LastModified: base.Add(2 * time.Hour)},
+		},
+		data: map[string][]byte{
+			"etcd-backups/prod-2026-01-02T03-00-00Z.snap.zst": testSnapshot,
+		},
+		metadata: &s3.BucketMetadata{ClusterName: "prod", ManagedBy: "k8zner"},
+	}, "prod-etcd-backups")
+}
+
+func TestList_NewestFirst(t *testing.T) {
+	t.Parallel()
+	snapshots, err := newTestRepository().List(context.Background())
+	require.NoError(t, err)
+
+	keys := make([]string, 0, len(snapshots))
+	for _, snap := range snapshots {
+		keys = append(keys, snap.Key)
+	}
+	assert.Equal(t, []string{
+		"etcd-backups/prod-2026-01-02T03-00-00Z.snap.zst",
+		"etcd-backups/prod-2026-01-02T02-00-00Z.snap.zst",
+		"etcd-backups/prod-2026-01-02T01-00-00Z.snap.zst",
+	}, keys)
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+	metadata, err := newTestRepository().Metadata(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, metadata)
+	assert.Equal(t, "prod", metadata.ClusterName)
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+	repo := newTestRepository()
+
+	tests := []struct {
+		name    string
+		ref     string
+		wantKey string
+		wantErr string
+	}{
+		{name: "latest", ref: Latest, wantKey: "etcd-backups/prod-2026-01-02T03-00-00Z.snap.zst"},
+		{name: "full key", ref: "etcd-backups/prod-2026-01-02T01-00-00Z.snap.zst", wantKey: "etcd-backups/prod-2026-01-02T01-00-00Z.snap.zst"},
+		{name: "key without prefix", ref: "prod-2026-01-02T02-00-00Z.snap.zst", wantKey: "etcd-backups/prod-2026-01-02T02-00-00Z.snap.zst"},
+		{name: "unknown key", ref: "prod-2025-12-31T00-00-00Z.snap.zst", wantErr: "not found in bucket prod-etcd-backups"},
+		{name: "empty", ref: "", wantErr: "must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			snap, err := repo.Resolve(context.Background(), tt.ref)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKey, snap.Key)
+		})
+	}
+}
+
+func TestResolve_LatestSkipsPreOperationSnapshots(t *testing.T) {
+	t.Parallel()
+	base := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	repo := NewRepository(&fakeStore{
+		objects: []s3.ObjectInfo{
+			{Key: "etcd-backups/prod-2026-01-02T01-00-00Z.snap.zst", Size: 100, LastModified: base.Add(time.Hour)},
+			{Key: PreOperationKey("cp-replace", "prod-cp-1", base.Add(2*time.Hour)), Size: 200, LastModified: base.Add(2 * time.Hour)},
+		},
+	}, "prod-etcd-backups")
+
+	snap, err := repo.Resolve(context.Background(), Latest)
+	require.NoError(t, err)
+	assert.Equal(t, "etcd-backups/prod-2026-01-02T01-00-00Z.snap.zst", snap.Key)
+
+	preOp := PreOperationKey("cp-replace", "prod-cp-1", base.Add(2*time.Hour))
+	snap, err = repo.Resolve(context.Background(), preOp)
+	require.NoError(t, err, "pre-operation snapshots can still be restored by key")
+	assert.Equal(t, preOp, snap.Key)
+}
+
+func TestResolve_LatestWithoutSnapshots(t *testing.T) {
+	t.Parallel()
+	repo := NewRepository(&fakeStore{}, "prod-etcd-backups")
+
+	_, err := repo.Resolve(context.Background(), Latest)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no snapshots found")
+}
+
+func TestResolve_ListError(t *testing.T) {
+	t.Parallel()
+	repo := NewRepository(&fakeStore{listErr: errors.New("access denied")}, "prod-etcd-backups")
+
+	_, err := repo.Resolve(context.Background(), Latest)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+}
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+	repo := newTestRepository()
+
+	data, err := repo.Fetch(context.Background(), "etcd-backups/prod-2026-01-02T03-00-00Z.snap.zst", nil)
+	require.NoError(t, err)
+	assert.Equal(t, testSnapshot, data)
+
+	_, err = repo.Fetch(context.Background(), "etcd-backups/missing.snap", nil)
+	require.Error(t, err)
+}
+
+func TestNewRepositoryFromConfig_RequiresStorage(t *testing.T) {
+	t.Parallel()
+	_, err := NewRepositoryFromConfig(config.TalosBackupConfig{S3Bucket: "prod-etcd-backups"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backup storage is not configured")
+}
@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	"filippo.io/age"
+)
+
+// Verification is the outcome of a backup verification run. The verification
+// pod writes it as its termination message for the operator to read.
+type Verification struct {
+	Snapshot     string `json:"snapshot,omitempty"`
+	Revision     int64  `json:"revision,omitempty"`
+	TotalKeys    int    `json:"totalKeys,omitempty"`
+	HashVerified bool   `json:"hashVerified,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// VerifyLatest downloads the newest snapshot, decodes it with identities and
+// reads it like "etcdutl snapshot status". Failures are reported in the
+// result's Error rather than returned, so they reach the operator together
+// with the snapshot that failed.
+func VerifyLatest(ctx context.Context, repo *Repository, identities []age.Identity) Verification {
+	snap, err := repo.Resolve(ctx, Latest)
+	if err != nil {
+		return Verification{Error: err.Error()}
+	}
+	result := Verification{Snapshot: snap.Key}
+
+	data, err := repo.Fetch(ctx, snap.Key, identities)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	status, err := Inspect(data)
+	if err != nil {
+		result.Error = fmt.Sprintf("snapshot %s is not a readable etcd database: %v", snap.Key, err)
+		return result
+	}
+
+	result.Revision = status.Revision
+	result.TotalKeys = status.TotalKeys
+	result.HashVerified = status.HashVerified
+	return result
+}
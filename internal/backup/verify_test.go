@@ -0,0 +1,62 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+func TestVerifyLatest(t *testing.T) {
+	t.Parallel()
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	db := newTestDatabase(t)
+	now := time.Now()
+
+	newRepo := func(latest []byte) *Repository {
+		return NewRepository(&fakeStore{
+			objects: []s3.ObjectInfo{
+				{Key: "etcd-backups/old.snap.age", LastModified: now.Add(-time.Hour)},
+				{Key: "etcd-backups/new.snap.age", LastModified: now},
+			},
+			data: map[string][]byte{"etcd-backups/new.snap.age": latest},
+		}, "test-etcd-backups")
+	}
+
+	t.Run("readable snapshot", func(t *testing.T) {
+		t.Parallel()
+		result := VerifyLatest(context.Background(), newRepo(encrypt(t, compress(t, db), identity, false)), []age.Identity{identity})
+		assert.Empty(t, result.Error)
+		assert.Equal(t, "etcd-backups/new.snap.age", result.Snapshot)
+		assert.Equal(t, int64(42), result.Revision)
+		assert.Equal(t, 43, result.TotalKeys)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		t.Parallel()
+		other, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		result := VerifyLatest(context.Background(), newRepo(encrypt(t, db, identity, false)), []age.Identity{other})
+		assert.Equal(t, "etcd-backups/new.snap.age", result.Snapshot)
+		assert.Contains(t, result.Error, "failed to decode snapshot")
+	})
+
+	t.Run("truncated snapshot", func(t *testing.T) {
+		t.Parallel()
+		result := VerifyLatest(context.Background(), newRepo(db[:len(db)/2]), nil)
+		assert.Contains(t, result.Error, "not a readable etcd database")
+	})
+
+	t.Run("empty bucket", func(t *testing.T) {
+		t.Parallel()
+		result := VerifyLatest(context.Background(), NewRepository(&fakeStore{}, "test-etcd-backups"), nil)
+		assert.Empty(t, result.Snapshot)
+		assert.Contains(t, result.Error, "no snapshots found")
+	})
+}
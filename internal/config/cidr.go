@@ -114,6 +114,55 @@ func CIDRHost(prefix string, hostnum int) (string, error) {
 	return newIP.String(), nil
 }
 
+// ValidateCIDR returns an error if prefix is not a valid IPv4 CIDR.
+func ValidateCIDR(prefix string) error {
+	_, err := parseIPv4CIDR(prefix)
+	return err
+}
+
+// CIDRContains reports whether the IPv4 network inner lies entirely within outer.
+func CIDRContains(outer, inner string) (bool, error) {
+	outerNet, err := parseIPv4CIDR(outer)
+	if err != nil {
+		return false, err
+	}
+	innerNet, err := parseIPv4CIDR(inner)
+	if err != nil {
+		return false, err
+	}
+
+	outerSize, _ := outerNet.Mask.Size()
+	innerSize, _ := innerNet.Mask.Size()
+	return innerSize >= outerSize && outerNet.Contains(innerNet.IP), nil
+}
+
+// CIDROverlaps reports whether the IPv4 networks a and b share any address.
+func CIDROverlaps(a, b string) (bool, error) {
+	aNet, err := parseIPv4CIDR(a)
+	if err != nil {
+		return false, err
+	}
+	bNet, err := parseIPv4CIDR(b)
+	if err != nil {
+		return false, err
+	}
+
+	// Two CIDR blocks overlap exactly when one contains the other's network address
+	return aNet.Contains(bNet.IP) || bNet.Contains(aNet.IP), nil
+}
+
+// parseIPv4CIDR parses an IPv4 CIDR and rejects IPv6 prefixes.
+func parseIPv4CIDR(prefix string) (*net.IPNet, error) {
+	_, network, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR prefix: %w", err)
+	}
+	if network.IP.To4() == nil {
+		return nil, fmt.Errorf("only IPv4 addresses are supported, got IPv6: %s", prefix)
+	}
+	return network, nil
+}
+
 // bigIntFromIP converts an IP address to uint64.
 // Only supports IPv4 addresses.
 func bigIntFromIP(ip net.IP) uint64 {
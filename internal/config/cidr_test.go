@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBigIntFromIP(t *testing.T) {
@@ -87,3 +88,64 @@ func TestIpFromBigInt(t *testing.T) {
 		})
 	}
 }
+
+func TestCIDRContains(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		outer, inner string
+		expected     bool
+	}{
+		{"lower half", "10.0.0.0/16", "10.0.0.0/17", true},
+		{"upper half", "10.0.0.0/16", "10.0.128.0/17", true},
+		{"same network", "10.0.0.0/16", "10.0.0.0/16", true},
+		{"larger network", "10.0.0.0/17", "10.0.0.0/16", false},
+		{"disjoint", "10.0.0.0/16", "10.96.0.0/12", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := CIDRContains(tt.outer, tt.inner)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCIDROverlaps(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		a, b     string
+		expected bool
+	}{
+		{"disjoint", "10.0.0.0/16", "10.96.0.0/12", false},
+		{"adjacent halves", "10.0.0.0/17", "10.0.128.0/17", false},
+		{"nested", "10.0.0.0/16", "10.0.64.0/19", true},
+		{"nested reversed", "10.0.64.0/19", "10.0.0.0/16", true},
+		{"identical", "10.244.0.0/16", "10.244.0.0/16", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := CIDROverlaps(tt.a, tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCIDROverlaps_Invalid(t *testing.T) {
+	t.Parallel()
+	require.NoError(t, ValidateCIDR("10.0.0.0/16"))
+	require.Error(t, ValidateCIDR("10.0.0.0"))
+	require.Error(t, ValidateCIDR("fd00::/64"))
+
+	_, err := CIDROverlaps("10.0.0.0/16", "not-a-cidr")
+	require.Error(t, err)
+
+	_, err = CIDRContains("fd00::/64", "10.0.0.0/16")
+	require.Error(t, err)
+}
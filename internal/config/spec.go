@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/milankappen/k8zner/internal/util/cron"
 )
 
 // domainRegex is compiled once at package init for domain validation.
@@ -25,7 +29,12 @@ type Spec struct {
 	Mode Mode `yaml:"mode"`
 
 	// Workers defines the worker pool configuration.
-	Workers WorkerSpec `yaml:"workers"`
+	// Ignored when WorkerPools is set.
+	Workers WorkerSpec `yaml:"workers,omitempty"`
+
+	// WorkerPools defines named worker pools, each with its own size, count,
+	// location, node labels and taints. When set, it replaces Workers.
+	WorkerPools []WorkerPoolSpec `yaml:"worker_pools,omitempty"`
 
 	// ControlPlane defines optional control plane configuration.
 	// If not specified, defaults to cx23 (2 dedicated vCPU, 4GB RAM).
@@ -60,6 +69,26 @@ type Spec struct {
 	// Only used when both Monitoring and Domain are set.
 	// Example: with Domain="example.com", Grafana is at grafana.example.com
 	GrafanaSubdomain string `yaml:"grafana_subdomain,omitempty"`
+
+	// ConfigApplyMode controls how the operator applies changed Talos machine
+	// configs to existing nodes: auto, no_reboot, reboot or staged.
+	// Default: auto (Talos reboots only when a change requires it)
+	ConfigApplyMode string `yaml:"config_apply_mode,omitempty"`
+
+	// Maintenance restricts disruptive operator actions (node replacement,
+	// scale-down, server type changes, upgrades) to maintenance windows.
+	// Default: none (disruptive actions run as soon as they are needed)
+	Maintenance *MaintenanceSpec `yaml:"maintenance,omitempty"`
+
+	// State selects where the Talos secrets and access files are kept and
+	// locked while apply, destroy and restore run.
+	// Default: local (files and a lock file in the working directory)
+	State *StateSpec `yaml:"state,omitempty"`
+
+	// SecretsEncryption stores secrets.yaml, talosconfig, kubeconfig and
+	// access-data.yaml age-encrypted in the working directory.
+	// Default: none (files are written in plaintext)
+	SecretsEncryption *SecretsEncryptionSpec `yaml:"secrets_encryption,omitempty"`
 }
 
 // Region is a Hetzner datacenter location.
@@ -74,8 +103,8 @@ const (
 	RegionHelsinki Region = "hel1"
 )
 
-// validRegions returns all valid regions.
-func validRegions() []Region {
+// ValidRegions returns all valid regions.
+func ValidRegions() []Region {
 	return []Region{RegionNuremberg, RegionFalkenstein, RegionHelsinki}
 }
 
@@ -180,6 +209,119 @@ type WorkerSpec struct {
 	Size ServerSize `yaml:"size"`
 }
 
+// WorkerPoolSpec defines a named worker pool.
+type WorkerPoolSpec struct {
+	// Name identifies the pool. Must be DNS-safe and unique within the cluster.
+	Name string `yaml:"name"`
+
+	// Count is the number of worker nodes in this pool (0-5).
+	// With Autoscaling set, it is the initial size and must lie within min_count and max_count.
+	Count int `yaml:"count"`
+
+	// Size is the Hetzner server type for this pool.
+	Size ServerSize `yaml:"size"`
+
+	// Autoscaling lets the operator resize the pool based on demand.
+	Autoscaling *WorkerPoolAutoscaling `yaml:"autoscaling,omitempty"`
+
+	// Location is the Hetzner datacenter for this pool (default: cluster region).
+	Location Region `yaml:"location,omitempty"`
+
+	// Labels are Kubernetes labels applied to every node in the pool.
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	// Taints are applied to every node in the pool.
+	Taints []Taint `yaml:"taints,omitempty"`
+}
+
+// WorkerPoolAutoscaling configures the operator's built-in autoscaler for a pool.
+type WorkerPoolAutoscaling struct {
+	// MinCount is the smallest size the pool is scaled down to.
+	MinCount int `yaml:"min_count"`
+
+	// MaxCount is the largest size the pool is scaled up to (1-100).
+	MaxCount int `yaml:"max_count"`
+
+	// ScaleDownUtilizationThreshold is the requested CPU and memory, in percent of
+	// a node's allocatable resources, below which a node may be removed (default: 50).
+	ScaleDownUtilizationThreshold int `yaml:"scale_down_utilization_threshold,omitempty"`
+
+	// ScaleDownDelay is how long a node must stay underutilized before removal (default: 10m).
+	ScaleDownDelay string `yaml:"scale_down_delay,omitempty"`
+}
+
+// Taint is a Kubernetes node taint.
+type Taint struct {
+	Key    string `mapstructure:"key" yaml:"key"`
+	Value  string `mapstructure:"value" yaml:"value,omitempty"`
+	Effect string `mapstructure:"effect" yaml:"effect"`
+}
+
+// validTaintEffects returns all valid taint effects.
+func validTaintEffects() []string {
+	return []string{"NoSchedule", "PreferNoSchedule", "NoExecute"}
+}
+
+// MaintenanceSpec defines recurring maintenance windows for disruptive actions.
+type MaintenanceSpec struct {
+	// Windows are cron-scheduled periods in which disruptive actions may run.
+	Windows []MaintenanceWindow `mapstructure:"windows" yaml:"windows"`
+
+	// Timezone is the IANA time zone for the schedules (default: UTC).
+	Timezone string `mapstructure:"timezone" yaml:"timezone,omitempty"`
+
+	// AllowEmergencyHealing lets unhealthy nodes be replaced outside a window.
+	AllowEmergencyHealing bool `mapstructure:"allow_emergency_healing" yaml:"allow_emergency_healing,omitempty"`
+}
+
+// MaintenanceWindow is a recurring maintenance window.
+type MaintenanceWindow struct {
+	// Schedule is a five-field cron expression for the start of the window.
+	Schedule string `mapstructure:"schedule" yaml:"schedule"`
+
+	// Duration is how long the window stays open (e.g. "4h").
+	Duration string `mapstructure:"duration" yaml:"duration"`
+}
+
+// State backends.
+const (
+	// StateBackendLocal keeps the state in the working directory.
+	StateBackendLocal = "local"
+	// StateBackendS3 keeps the state encrypted in a Hetzner Object Storage bucket.
+	StateBackendS3 = "s3"
+)
+
+// StateSpec defines where the cluster state is kept.
+type StateSpec struct {
+	// Backend is "local" or "s3" (default: local).
+	Backend string `mapstructure:"backend" yaml:"backend,omitempty"`
+
+	// Bucket overrides the S3 bucket (default: "k8zner-state-{cluster-name}").
+	Bucket string `mapstructure:"bucket" yaml:"bucket,omitempty"`
+
+	// Recipients are the age public keys the remote state is encrypted for.
+	// Without recipients, K8ZNER_STATE_PASSPHRASE is used.
+	Recipients []string `mapstructure:"recipients" yaml:"recipients,omitempty"`
+}
+
+// SecretsRecipientsEnv is the environment variable with comma-separated age
+// public keys that enables secrets encryption without changing k8zner.yaml.
+const SecretsRecipientsEnv = "K8ZNER_SECRETS_RECIPIENTS"
+
+// SecretsEncryptionSpec defines who the local credential files are encrypted for.
+type SecretsEncryptionSpec struct {
+	// Recipients are the age public keys (age1...) that can decrypt the files.
+	Recipients []string `mapstructure:"recipients" yaml:"recipients"`
+}
+
+func validStateBackends() []string {
+	return []string{StateBackendLocal, StateBackendS3}
+}
+
+func validConfigApplyModes() []string {
+	return []string{"auto", "no_reboot", "reboot", "staged"}
+}
+
 // ControlPlaneSpec defines the optional control plane configuration.
 type ControlPlaneSpec struct {
 	// Size is the Hetzner server type for control plane nodes.
@@ -231,8 +373,8 @@ const (
 	SizeCX53 ServerSize = "cx53"
 )
 
-// validServerSizes returns all valid server sizes (current names only).
-func validServerSizes() []ServerSize {
+// ValidServerSizes returns all valid server sizes (current names only).
+func ValidServerSizes() []ServerSize {
 	return []ServerSize{
 		// CPX series (shared vCPU)
 		SizeCPX22, SizeCPX32, SizeCPX42, SizeCPX52,
@@ -327,7 +469,7 @@ func (c *Spec) Validate() error {
 
 	// Region: must be valid
 	if !c.Region.IsValid() {
-		errs = append(errs, fmt.Errorf("region must be one of: %v", validRegions()))
+		errs = append(errs, fmt.Errorf("region must be one of: %v", ValidRegions()))
 	}
 
 	// Mode: must be valid
@@ -335,12 +477,36 @@ func (c *Spec) Validate() error {
 		errs = append(errs, fmt.Errorf("mode must be one of: %v", validModes()))
 	}
 
-	// Workers: count 1-5, valid size
-	if c.Workers.Count < 1 || c.Workers.Count > 5 {
-		errs = append(errs, errors.New("workers.count must be 1-5"))
+	// Workers: count 1-5, valid size (or named pools instead)
+	if len(c.WorkerPools) > 0 {
+		errs = append(errs, c.validateWorkerPools()...)
+	} else {
+		if c.Workers.Count < 1 || c.Workers.Count > 5 {
+			errs = append(errs, errors.New("workers.count must be 1-5"))
+		}
+		if !c.Workers.Size.IsValid() {
+			errs = append(errs, fmt.Errorf("workers.size must be one of: %v", ValidServerSizes()))
+		}
+	}
+
+	// ConfigApplyMode: optional, must be a Talos apply mode
+	if c.ConfigApplyMode != "" && !slices.Contains(validConfigApplyModes(), c.ConfigApplyMode) {
+		errs = append(errs, fmt.Errorf("config_apply_mode must be one of: %v", validConfigApplyModes()))
+	}
+
+	// Maintenance: optional, windows need a valid schedule and duration
+	if c.Maintenance != nil {
+		errs = append(errs, c.Maintenance.Validate()...)
+	}
+
+	// State: optional, the s3 backend needs credentials and encryption
+	if c.State != nil {
+		errs = append(errs, c.State.Validate()...)
 	}
-	if !c.Workers.Size.IsValid() {
-		errs = append(errs, fmt.Errorf("workers.size must be one of: %v", validServerSizes()))
+
+	// SecretsEncryption: optional, needs age recipients
+	if c.SecretsEncryption != nil {
+		errs = append(errs, c.SecretsEncryption.Validate()...)
 	}
 
 	// Domain: if set, validate and check for CF_API_TOKEN
@@ -366,6 +532,171 @@ func (c *Spec) Validate() error {
 	return errors.Join(errs...)
 }
 
+// validateWorkerPools checks names, counts, sizes, locations and taints of all worker pools.
+func (c *Spec) validateWorkerPools() []error {
+	var errs []error
+
+	seen := make(map[string]bool, len(c.WorkerPools))
+	total := 0
+	for i, pool := range c.WorkerPools {
+		field := fmt.Sprintf("worker_pools[%d]", i)
+		if !isValidDNSName(pool.Name) || len(pool.Name) > 32 {
+			errs = append(errs, fmt.Errorf("%s.name must be DNS-safe and at most 32 characters", field))
+		} else if seen[pool.Name] {
+			errs = append(errs, fmt.Errorf("%s.name %q is used by more than one pool", field, pool.Name))
+		}
+		seen[pool.Name] = true
+
+		if pool.Count < 0 || pool.Count > 5 {
+			errs = append(errs, fmt.Errorf("%s.count must be 0-5", field))
+		}
+		if pool.Autoscaling != nil {
+			errs = append(errs, validateWorkerPoolAutoscaling(field, pool)...)
+			total += pool.Autoscaling.MinCount
+		} else {
+			total += pool.Count
+		}
+
+		if !pool.Size.IsValid() {
+			errs = append(errs, fmt.Errorf("%s.size must be one of: %v", field, ValidServerSizes()))
+		}
+		if pool.Location != "" && !pool.Location.IsValid() {
+			errs = append(errs, fmt.Errorf("%s.location must be one of: %v", field, ValidRegions()))
+		}
+		for j, taint := range pool.Taints {
+			if taint.Key == "" {
+				errs = append(errs, fmt.Errorf("%s.taints[%d].key is required", field, j))
+			}
+			if !slices.Contains(validTaintEffects(), taint.Effect) {
+				errs = append(errs, fmt.Errorf("%s.taints[%d].effect must be one of: %v", field, j, validTaintEffects()))
+			}
+		}
+	}
+
+	if total < 1 {
+		errs = append(errs, errors.New("worker_pools must contain at least 1 worker in total"))
+	}
+
+	return errs
+}
+
+// validateWorkerPoolAutoscaling validates the autoscaling bounds of a worker pool.
+func validateWorkerPoolAutoscaling(field string, pool WorkerPoolSpec) []error {
+	var errs []error
+
+	as := pool.Autoscaling
+	if as.MinCount < 0 {
+		errs = append(errs, fmt.Errorf("%s.autoscaling.min_count must not be negative", field))
+	}
+	if as.MaxCount < 1 || as.MaxCount > 100 {
+		errs = append(errs, fmt.Errorf("%s.autoscaling.max_count must be 1-100", field))
+	}
+	if as.MinCount > as.MaxCount {
+		errs = append(errs, fmt.Errorf("%s.autoscaling.min_count must not exceed max_count", field))
+	}
+	if pool.Count < as.MinCount || pool.Count > as.MaxCount {
+		errs = append(errs, fmt.Errorf("%s.count must be between autoscaling.min_count and max_count", field))
+	}
+	if as.ScaleDownUtilizationThreshold < 0 || as.ScaleDownUtilizationThreshold > 100 {
+		errs = append(errs, fmt.Errorf("%s.autoscaling.scale_down_utilization_threshold must be 0-100", field))
+	}
+	if as.ScaleDownDelay != "" {
+		if _, err := time.ParseDuration(as.ScaleDownDelay); err != nil {
+			errs = append(errs, fmt.Errorf("%s.autoscaling.scale_down_delay: %w", field, err))
+		}
+	}
+
+	return errs
+}
+
+// Validate checks the time zone, schedules and durations of the maintenance windows.
+func (m *MaintenanceSpec) Validate() []error {
+	var errs []error
+
+	if len(m.Windows) == 0 {
+		errs = append(errs, errors.New("maintenance.windows must contain at least 1 window"))
+	}
+	if m.Timezone != "" {
+		if _, err := time.LoadLocation(m.Timezone); err != nil {
+			errs = append(errs, fmt.Errorf("maintenance.timezone %q is not a valid IANA time zone", m.Timezone))
+		}
+	}
+	for i, window := range m.Windows {
+		field := fmt.Sprintf("maintenance.windows[%d]", i)
+		if _, err := cron.Parse(window.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("%s.schedule: %w", field, err))
+		}
+		if d, err := time.ParseDuration(window.Duration); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("%s.duration must be a positive duration such as \"4h\"", field))
+		}
+	}
+
+	return errs
+}
+
+// Validate validates the state backend and returns all errors found.
+func (s *StateSpec) Validate() []error {
+	var errs []error
+
+	backend := s.Backend
+	if backend == "" {
+		backend = StateBackendLocal
+	}
+	if !slices.Contains(validStateBackends(), backend) {
+		return append(errs, fmt.Errorf("state.backend must be one of: %v", validStateBackends()))
+	}
+	if backend == StateBackendLocal {
+		if s.Bucket != "" || len(s.Recipients) > 0 {
+			errs = append(errs, errors.New("state.bucket and state.recipients require state.backend: s3"))
+		}
+		return errs
+	}
+
+	if s.Bucket != "" && !isValidDNSName(s.Bucket) {
+		errs = append(errs, errors.New("state.bucket must be a valid bucket name (lowercase alphanumeric and hyphens, must start with letter)"))
+	}
+	for i, r := range s.Recipients {
+		if !strings.HasPrefix(r, "age1") {
+			errs = append(errs, fmt.Errorf("state.recipients[%d] must be an age public key (age1...)", i))
+		}
+	}
+	if len(s.Recipients) == 0 && os.Getenv("K8ZNER_STATE_PASSPHRASE") == "" {
+		errs = append(errs, errors.New("state.recipients or the K8ZNER_STATE_PASSPHRASE environment variable required when state.backend is s3"))
+	}
+	if os.Getenv("HETZNER_S3_ACCESS_KEY") == "" {
+		errs = append(errs, errors.New("HETZNER_S3_ACCESS_KEY environment variable required when state.backend is s3"))
+	}
+	if os.Getenv("HETZNER_S3_SECRET_KEY") == "" {
+		errs = append(errs, errors.New("HETZNER_S3_SECRET_KEY environment variable required when state.backend is s3"))
+	}
+
+	return errs
+}
+
+// Validate validates the secrets encryption recipients and returns all errors found.
+func (s *SecretsEncryptionSpec) Validate() []error {
+	var errs []error
+
+	if len(s.Recipients) == 0 {
+		errs = append(errs, errors.New("secrets_encryption.recipients must contain at least 1 age public key"))
+	}
+	for i, r := range s.Recipients {
+		if !strings.HasPrefix(r, "age1") {
+			errs = append(errs, fmt.Errorf("secrets_encryption.recipients[%d] must be an age public key (age1...)", i))
+		}
+	}
+
+	return errs
+}
+
+// WorkerPoolLocation returns the location of a worker pool, defaulting to the cluster region.
+func (c *Spec) WorkerPoolLocation(pool WorkerPoolSpec) Region {
+	if pool.Location == "" {
+		return c.Region
+	}
+	return pool.Location
+}
+
 // ControlPlaneCount returns the number of control plane nodes.
 func (c *Spec) ControlPlaneCount() int {
 	return c.Mode.ControlPlaneCount()
@@ -411,6 +742,14 @@ func (c *Spec) GetGrafanaSubdomain() string {
 	return c.GrafanaSubdomain
 }
 
+// GetConfigApplyMode returns the Talos config apply mode (default: "auto").
+func (c *Spec) GetConfigApplyMode() string {
+	if c.ConfigApplyMode == "" {
+		return "auto"
+	}
+	return c.ConfigApplyMode
+}
+
 // GrafanaHost returns the full Grafana hostname (e.g., "grafana.example.com").
 // Returns empty string if no domain is configured.
 func (c *Spec) GrafanaHost() string {
@@ -442,6 +781,15 @@ func (c *Spec) BackupBucketName() string {
 	return c.Name + "-etcd-backups"
 }
 
+// StateBucketName returns the S3 bucket name for the remote state. It does not
+// start with the cluster name, so destroy never deletes it with the cluster.
+func (c *Spec) StateBucketName() string {
+	if c.State != nil && c.State.Bucket != "" {
+		return c.State.Bucket
+	}
+	return "k8zner-state-" + c.Name
+}
+
 // S3Endpoint returns the Hetzner S3 endpoint for the configured region.
 func (c *Spec) S3Endpoint() string {
 	return "https://" + string(c.Region) + ".your-objectstorage.com"
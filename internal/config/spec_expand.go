@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"strings"
 
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
 	"github.com/milankappen/k8zner/internal/util/ptr"
 )
 
@@ -30,9 +32,9 @@ func ExpandSpec(cfg *Spec) (*Config, error) {
 		HealthcheckEnabled: ptr.Bool(true),
 		DeleteProtection:   false,
 
-		// Config output paths
-		KubeconfigPath:  "./secrets/kubeconfig",
-		TalosconfigPath: "./secrets/talosconfig",
+		// Config output paths, relative to the cluster workspace
+		KubeconfigPath:  "kubeconfig",
+		TalosconfigPath: "talosconfig",
 
 		// Talosctl settings
 		TalosctlVersionCheckEnabled: ptr.Bool(true),
@@ -58,11 +60,50 @@ func ExpandSpec(cfg *Spec) (*Config, error) {
 
 		// Addons
 		Addons: expandAddons(cfg, vm),
+
+		// Maintenance windows (enforced by the operator)
+		Maintenance: cfg.Maintenance,
+
+		// State backend
+		State: expandState(cfg),
+
+		// Local secrets encryption
+		SecretsRecipients: expandSecretsRecipients(cfg),
 	}
 
 	return internal, nil
 }
 
+func expandState(cfg *Spec) StateConfig {
+	if cfg.State == nil || cfg.State.Backend == "" || cfg.State.Backend == StateBackendLocal {
+		return StateConfig{Backend: StateBackendLocal}
+	}
+
+	return StateConfig{
+		Backend:     cfg.State.Backend,
+		S3Bucket:    cfg.StateBucketName(),
+		S3Region:    string(cfg.Region),
+		S3Endpoint:  cfg.S3Endpoint(),
+		S3AccessKey: os.Getenv("HETZNER_S3_ACCESS_KEY"),
+		S3SecretKey: os.Getenv("HETZNER_S3_SECRET_KEY"),
+		Recipients:  cfg.State.Recipients,
+	}
+}
+
+func expandSecretsRecipients(cfg *Spec) []string {
+	if cfg.SecretsEncryption != nil {
+		return cfg.SecretsEncryption.Recipients
+	}
+
+	var recipients []string
+	for _, r := range strings.Split(os.Getenv(SecretsRecipientsEnv), ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			recipients = append(recipients, r)
+		}
+	}
+	return recipients
+}
+
 func expandNetwork(cfg *Spec) NetworkConfig {
 	return NetworkConfig{
 		IPv4CIDR:           NetworkCIDR,
@@ -103,9 +144,29 @@ func expandControlPlane(cfg *Spec) ControlPlaneConfig {
 }
 
 func expandWorkers(cfg *Spec) []WorkerNodePool {
+	if len(cfg.WorkerPools) > 0 {
+		pools := make([]WorkerNodePool, 0, len(cfg.WorkerPools))
+		for _, pool := range cfg.WorkerPools {
+			pools = append(pools, WorkerNodePool{
+				Name:           pool.Name,
+				Location:       string(cfg.WorkerPoolLocation(pool)),
+				ServerType:     string(pool.Size.Normalize()),
+				Count:          pool.Count,
+				PlacementGroup: true,
+				Labels: map[string]string{
+					"node.kubernetes.io/role": "worker",
+				},
+				NodeLabels:  pool.Labels,
+				NodeTaints:  pool.Taints,
+				Autoscaling: pool.Autoscaling,
+			})
+		}
+		return pools
+	}
+
 	return []WorkerNodePool{
 		{
-			Name:           "workers",
+			Name:           k8znerv1alpha1.DefaultWorkerPoolName,
 			Location:       string(cfg.Region),
 			ServerType:     string(cfg.Workers.Size.Normalize()), // Convert old cx22 to cx23 etc.
 			Count:          cfg.Workers.Count,
@@ -138,7 +199,7 @@ func expandTalos(cfg *Spec, vm VersionMatrix) TalosConfig {
 			DiscoveryServiceEnabled:    ptr.Bool(true),
 
 			// Config apply mode
-			ConfigApplyMode: "auto",
+			ConfigApplyMode: cfg.GetConfigApplyMode(),
 		},
 	}
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -132,6 +133,64 @@ func TestExpandSpec_Workers(t *testing.T) {
 	}
 }
 
+func TestExpandSpec_WorkerPools(t *testing.T) {
+	t.Parallel()
+	cfg := &Spec{
+		Name:    "pool-test",
+		Region:  RegionFalkenstein,
+		Mode:    ModeDev,
+		Workers: WorkerSpec{Count: 3, Size: SizeCX23}, // ignored when pools are set
+		WorkerPools: []WorkerPoolSpec{
+			{Name: "general", Count: 2, Size: SizeCX32, Autoscaling: &WorkerPoolAutoscaling{MinCount: 1, MaxCount: 4}},
+			{
+				Name:     "gpu",
+				Count:    1,
+				Size:     SizeCX53,
+				Location: RegionHelsinki,
+				Labels:   map[string]string{"tier": "gpu"},
+				Taints:   []Taint{{Key: "gpu", Value: "true", Effect: "NoSchedule"}},
+			},
+		},
+	}
+
+	expanded, err := ExpandSpec(cfg)
+	if err != nil {
+		t.Fatalf("ExpandSpec() error = %v", err)
+	}
+
+	if len(expanded.Workers) != 2 {
+		t.Fatalf("Workers length = %d, want 2", len(expanded.Workers))
+	}
+	if expanded.WorkerCount() != 3 {
+		t.Errorf("WorkerCount() = %d, want 3", expanded.WorkerCount())
+	}
+
+	general := expanded.Workers[0]
+	if general.Name != "general" || general.ServerType != "cx33" || general.Location != "fsn1" {
+		t.Errorf("general pool = %s %s %s, want general cx33 fsn1", general.Name, general.ServerType, general.Location)
+	}
+	if general.Autoscaling == nil || general.Autoscaling.MaxCount != 4 {
+		t.Errorf("general pool autoscaling = %v, want max_count 4", general.Autoscaling)
+	}
+
+	gpu := expanded.Workers[1]
+	if gpu.Location != "hel1" {
+		t.Errorf("gpu pool location = %q, want %q", gpu.Location, "hel1")
+	}
+	if gpu.NodeLabels["tier"] != "gpu" {
+		t.Errorf("gpu pool node labels = %v, want tier=gpu", gpu.NodeLabels)
+	}
+	if len(gpu.NodeTaints) != 1 || gpu.NodeTaints[0].Key != "gpu" {
+		t.Errorf("gpu pool node taints = %v, want one gpu taint", gpu.NodeTaints)
+	}
+	if gpu.Autoscaling != nil {
+		t.Errorf("gpu pool autoscaling = %v, want nil", gpu.Autoscaling)
+	}
+	if !gpu.PlacementGroup {
+		t.Error("worker pools should have PlacementGroup enabled")
+	}
+}
+
 func TestExpandSpec_Network(t *testing.T) {
 	t.Parallel()
 	cfg := &Spec{
@@ -193,6 +252,109 @@ func TestExpandSpec_Talos(t *testing.T) {
 	}
 }
 
+func TestExpandSpec_Maintenance(t *testing.T) {
+	t.Parallel()
+	cfg := &Spec{
+		Name:   "maintenance-test",
+		Region: RegionFalkenstein,
+		Mode:   ModeDev,
+		Workers: WorkerSpec{
+			Count: 1,
+			Size:  SizeCX32,
+		},
+		Maintenance: &MaintenanceSpec{
+			Windows:  []MaintenanceWindow{{Schedule: "0 2 * * sat", Duration: "4h"}},
+			Timezone: "Europe/Berlin",
+		},
+	}
+
+	expanded, err := ExpandSpec(cfg)
+	if err != nil {
+		t.Fatalf("ExpandSpec() error = %v", err)
+	}
+
+	if expanded.Maintenance == nil {
+		t.Fatal("Maintenance should be carried over")
+	}
+	if len(expanded.Maintenance.Windows) != 1 || expanded.Maintenance.Windows[0].Schedule != "0 2 * * sat" {
+		t.Errorf("Maintenance.Windows = %v, want the configured window", expanded.Maintenance.Windows)
+	}
+	if expanded.Maintenance.Timezone != "Europe/Berlin" {
+		t.Errorf("Maintenance.Timezone = %q, want %q", expanded.Maintenance.Timezone, "Europe/Berlin")
+	}
+}
+
+func TestExpandSpec_State(t *testing.T) {
+	t.Setenv("HETZNER_S3_ACCESS_KEY", "access")
+	t.Setenv("HETZNER_S3_SECRET_KEY", "secret")
+	cfg := &Spec{
+		Name:   "state-test",
+		Region: RegionNuremberg,
+		Mode:   ModeDev,
+		Workers: WorkerSpec{
+			Count: 1,
+			Size:  SizeCX32,
+		},
+	}
+
+	expanded, err := ExpandSpec(cfg)
+	if err != nil {
+		t.Fatalf("ExpandSpec() error = %v", err)
+	}
+	if expanded.State.Backend != StateBackendLocal {
+		t.Errorf("State.Backend = %q, want %q", expanded.State.Backend, StateBackendLocal)
+	}
+
+	cfg.State = &StateSpec{Backend: StateBackendS3, Recipients: []string{"age1test"}}
+	expanded, err = ExpandSpec(cfg)
+	if err != nil {
+		t.Fatalf("ExpandSpec() error = %v", err)
+	}
+	want := StateConfig{
+		Backend:     StateBackendS3,
+		S3Bucket:    "k8zner-state-state-test",
+		S3Region:    "nbg1",
+		S3Endpoint:  "https://nbg1.your-objectstorage.com",
+		S3AccessKey: "access",
+		S3SecretKey: "secret",
+		Recipients:  []string{"age1test"},
+	}
+	if !reflect.DeepEqual(expanded.State, want) {
+		t.Errorf("State = %+v, want %+v", expanded.State, want)
+	}
+}
+
+func TestExpandSpec_SecretsRecipients(t *testing.T) {
+	t.Setenv(SecretsRecipientsEnv, " age1one, age1two ,")
+	cfg := &Spec{
+		Name:   "secrets-test",
+		Region: RegionFalkenstein,
+		Mode:   ModeDev,
+		Workers: WorkerSpec{
+			Count: 1,
+			Size:  SizeCX32,
+		},
+	}
+
+	expanded, err := ExpandSpec(cfg)
+	if err != nil {
+		t.Fatalf("ExpandSpec() error = %v", err)
+	}
+	if !reflect.DeepEqual(expanded.SecretsRecipients, []string{"age1one", "age1two"}) {
+		t.Errorf("SecretsRecipients = %v, want the recipients from %s", expanded.SecretsRecipients, SecretsRecipientsEnv)
+	}
+
+	// k8zner.yaml takes precedence over the environment
+	cfg.SecretsEncryption = &SecretsEncryptionSpec{Recipients: []string{"age1config"}}
+	expanded, err = ExpandSpec(cfg)
+	if err != nil {
+		t.Fatalf("ExpandSpec() error = %v", err)
+	}
+	if !reflect.DeepEqual(expanded.SecretsRecipients, []string{"age1config"}) {
+		t.Errorf("SecretsRecipients = %v, want %v", expanded.SecretsRecipients, []string{"age1config"})
+	}
+}
+
 func TestExpandSpec_Addons(t *testing.T) {
 	t.Parallel()
 	cfg := &Spec{
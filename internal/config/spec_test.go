@@ -354,6 +354,163 @@ func TestSpec_Validate(t *testing.T) {
 			wantError: true,
 			errorMsg:  "workers.size must be one of",
 		},
+		{
+			name: "worker pools replace workers",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 2, Size: SizeCX33},
+					{Name: "gpu", Count: 0, Size: SizeCX53, Location: RegionHelsinki,
+						Taints: []Taint{{Key: "gpu", Value: "true", Effect: "NoSchedule"}}},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "duplicate worker pool name",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 1, Size: SizeCX33},
+					{Name: "general", Count: 1, Size: SizeCX33},
+				},
+			},
+			wantError: true,
+			errorMsg:  "is used by more than one pool",
+		},
+		{
+			name: "invalid worker pool name",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "GPU_pool", Count: 1, Size: SizeCX33},
+				},
+			},
+			wantError: true,
+			errorMsg:  "worker_pools[0].name must be DNS-safe",
+		},
+		{
+			name: "worker pool count too high",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 6, Size: SizeCX33},
+				},
+			},
+			wantError: true,
+			errorMsg:  "worker_pools[0].count must be 0-5",
+		},
+		{
+			name: "worker pools without workers",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 0, Size: SizeCX33},
+				},
+			},
+			wantError: true,
+			errorMsg:  "at least 1 worker in total",
+		},
+		{
+			name: "invalid worker pool location",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 1, Size: SizeCX33, Location: Region("ash")},
+				},
+			},
+			wantError: true,
+			errorMsg:  "worker_pools[0].location must be one of",
+		},
+		{
+			name: "invalid worker pool taint effect",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 1, Size: SizeCX33, Taints: []Taint{{Key: "gpu", Effect: "Never"}}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "worker_pools[0].taints[0].effect must be one of",
+		},
+		{
+			name: "valid autoscaled worker pool",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 2, Size: SizeCX33, Autoscaling: &WorkerPoolAutoscaling{MinCount: 1, MaxCount: 10, ScaleDownDelay: "15m"}},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "autoscaled worker pool min above max",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 2, Size: SizeCX33, Autoscaling: &WorkerPoolAutoscaling{MinCount: 4, MaxCount: 3}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "worker_pools[0].autoscaling.min_count must not exceed max_count",
+		},
+		{
+			name: "autoscaled worker pool count outside bounds",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 1, Size: SizeCX33, Autoscaling: &WorkerPoolAutoscaling{MinCount: 2, MaxCount: 5}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "worker_pools[0].count must be between autoscaling.min_count and max_count",
+		},
+		{
+			name: "autoscaled worker pool invalid delay",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 1, Size: SizeCX33, Autoscaling: &WorkerPoolAutoscaling{MinCount: 1, MaxCount: 5, ScaleDownDelay: "soon"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "worker_pools[0].autoscaling.scale_down_delay",
+		},
+		{
+			name: "autoscaled worker pools may scale to zero workers",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeHA,
+				WorkerPools: []WorkerPoolSpec{
+					{Name: "general", Count: 1, Size: SizeCX33, Autoscaling: &WorkerPoolAutoscaling{MinCount: 0, MaxCount: 5}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "at least 1 worker in total",
+		},
 		{
 			name: "domain without CF_API_TOKEN",
 			config: Spec{
@@ -385,6 +542,270 @@ func TestSpec_Validate(t *testing.T) {
 			wantError: true,
 			errorMsg:  "domain must be a valid domain",
 		},
+		{
+			name: "valid config apply mode",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				ConfigApplyMode: "staged",
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid config apply mode",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				ConfigApplyMode: "try",
+			},
+			wantError: true,
+			errorMsg:  "config_apply_mode must be one of",
+		},
+		{
+			name: "valid maintenance windows",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				Maintenance: &MaintenanceSpec{
+					Windows: []MaintenanceWindow{
+						{Schedule: "0 2 * * sat", Duration: "4h"},
+						{Schedule: "0 22 * * 1-5", Duration: "90m"},
+					},
+					Timezone:              "Europe/Berlin",
+					AllowEmergencyHealing: true,
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "maintenance without windows",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				Maintenance: &MaintenanceSpec{
+					Timezone: "UTC",
+				},
+			},
+			wantError: true,
+			errorMsg:  "maintenance.windows must contain at least 1 window",
+		},
+		{
+			name: "invalid maintenance schedule",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				Maintenance: &MaintenanceSpec{
+					Windows: []MaintenanceWindow{{Schedule: "0 25 * * *", Duration: "4h"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "maintenance.windows[0].schedule",
+		},
+		{
+			name: "invalid maintenance duration",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				Maintenance: &MaintenanceSpec{
+					Windows: []MaintenanceWindow{{Schedule: "0 2 * * *", Duration: "-1h"}},
+				},
+			},
+			wantError: true,
+			errorMsg:  "maintenance.windows[0].duration must be a positive duration",
+		},
+		{
+			name: "invalid maintenance timezone",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				Maintenance: &MaintenanceSpec{
+					Windows:  []MaintenanceWindow{{Schedule: "0 2 * * *", Duration: "4h"}},
+					Timezone: "Mars/Olympus",
+				},
+			},
+			wantError: true,
+			errorMsg:  "maintenance.timezone",
+		},
+		{
+			name: "local state backend",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				State: &StateSpec{Backend: StateBackendLocal},
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid state backend",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				State: &StateSpec{Backend: "consul"},
+			},
+			wantError: true,
+			errorMsg:  "state.backend must be one of",
+		},
+		{
+			name: "state bucket without s3 backend",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				State: &StateSpec{Bucket: "team-state"},
+			},
+			wantError: true,
+			errorMsg:  "require state.backend: s3",
+		},
+		{
+			name: "s3 state backend with recipients",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				State: &StateSpec{
+					Backend:    StateBackendS3,
+					Recipients: []string{"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"},
+				},
+			},
+			envVars: map[string]string{
+				"HETZNER_S3_ACCESS_KEY": "access",
+				"HETZNER_S3_SECRET_KEY": "secret",
+			},
+			wantError: false,
+		},
+		{
+			name: "s3 state backend without encryption",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				State: &StateSpec{Backend: StateBackendS3},
+			},
+			envVars: map[string]string{
+				"HETZNER_S3_ACCESS_KEY": "access",
+				"HETZNER_S3_SECRET_KEY": "secret",
+			},
+			wantError: true,
+			errorMsg:  "state.recipients or the K8ZNER_STATE_PASSPHRASE environment variable required",
+		},
+		{
+			name: "s3 state backend with invalid recipient",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				State: &StateSpec{Backend: StateBackendS3, Recipients: []string{"ssh-ed25519 AAAA"}},
+			},
+			wantError: true,
+			errorMsg:  "state.recipients[0] must be an age public key",
+		},
+		{
+			name: "valid secrets encryption",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				SecretsEncryption: &SecretsEncryptionSpec{
+					Recipients: []string{"age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "secrets encryption without recipients",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				SecretsEncryption: &SecretsEncryptionSpec{},
+			},
+			wantError: true,
+			errorMsg:  "secrets_encryption.recipients must contain at least 1 age public key",
+		},
+		{
+			name: "secrets encryption with invalid recipient",
+			config: Spec{
+				Name:   "my-cluster",
+				Region: RegionFalkenstein,
+				Mode:   ModeDev,
+				Workers: WorkerSpec{
+					Count: 1,
+					Size:  SizeCX32,
+				},
+				SecretsEncryption: &SecretsEncryptionSpec{Recipients: []string{"AGE-SECRET-KEY-1..."}},
+			},
+			wantError: true,
+			errorMsg:  "secrets_encryption.recipients[0] must be an age public key",
+		},
 	}
 
 	for _, tt := range tests {
@@ -728,6 +1149,28 @@ func TestSpec_GetGrafanaSubdomain(t *testing.T) {
 	}
 }
 
+func TestSpec_GetConfigApplyMode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		mode string
+		want string
+	}{
+		{"default", "", "auto"},
+		{"custom", "no_reboot", "no_reboot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := Spec{ConfigApplyMode: tt.mode}
+			if got := c.GetConfigApplyMode(); got != tt.want {
+				t.Errorf("Spec.GetConfigApplyMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSpec_GrafanaHost(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -1,5 +1,11 @@
 package config
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // VersionMatrix contains all pinned, tested versions for the k8zner stack.
 // These versions are validated to work together and are not configurable.
 type VersionMatrix struct {
@@ -67,6 +73,76 @@ func DefaultVersionMatrix() VersionMatrix {
 	}
 }
 
+// talosKubernetesSupport maps a Talos minor release to the oldest and newest
+// Kubernetes 1.x minor versions it supports, following the Talos support matrix.
+var talosKubernetesSupport = map[string][2]int{
+	"1.8":  {26, 31},
+	"1.9":  {27, 32},
+	"1.10": {28, 33},
+	"1.11": {29, 34},
+	"1.12": {30, 35},
+}
+
+// CheckVersionCompatibility returns an error if the Talos release does not
+// support the Kubernetes version. Talos releases missing from the support
+// table are not checked.
+func CheckVersionCompatibility(talosVersion, kubernetesVersion string) error {
+	talosMajor, talosMinor, err := parseMinorVersion(talosVersion)
+	if err != nil {
+		return fmt.Errorf("invalid Talos version: %w", err)
+	}
+	k8sMajor, k8sMinor, err := parseMinorVersion(kubernetesVersion)
+	if err != nil {
+		return fmt.Errorf("invalid Kubernetes version: %w", err)
+	}
+
+	bounds, ok := talosKubernetesSupport[fmt.Sprintf("%d.%d", talosMajor, talosMinor)]
+	if !ok {
+		return nil
+	}
+	if k8sMajor != 1 || k8sMinor < bounds[0] || k8sMinor > bounds[1] {
+		return fmt.Errorf("talos %s supports Kubernetes 1.%d to 1.%d, not %s",
+			talosVersion, bounds[0], bounds[1], kubernetesVersion)
+	}
+	return nil
+}
+
+// IsTested reports whether a Talos and Kubernetes version pair has the same
+// minor versions as the pinned matrix.
+func (m VersionMatrix) IsTested(talosVersion, kubernetesVersion string) bool {
+	return sameMinorVersion(m.Talos, talosVersion) && sameMinorVersion(m.Kubernetes, kubernetesVersion)
+}
+
+// sameMinorVersion reports whether two versions share their major and minor version.
+func sameMinorVersion(a, b string) bool {
+	aMajor, aMinor, err := parseMinorVersion(a)
+	if err != nil {
+		return false
+	}
+	bMajor, bMinor, err := parseMinorVersion(b)
+	if err != nil {
+		return false
+	}
+	return aMajor == bMajor && aMinor == bMinor
+}
+
+// parseMinorVersion returns the major and minor version of "v1.9.0" or "1.32.0".
+func parseMinorVersion(version string) (int, int, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("%q is not a major.minor version", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q is not a major.minor version", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q is not a major.minor version", version)
+	}
+	return major, minor, nil
+}
+
 // Hardcoded infrastructure constants
 const (
 	// DefaultWorkerServerType is the default Hetzner server type for workers.
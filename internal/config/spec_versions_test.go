@@ -103,3 +103,50 @@ func TestNetworkCIDRs(t *testing.T) {
 		t.Error("ServiceCIDR is empty")
 	}
 }
+
+func TestCheckVersionCompatibility(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		talos      string
+		kubernetes string
+		wantErr    bool
+	}{
+		{"v1.9.0", "1.32.0", false},
+		{"v1.9.0", "1.27.4", false},
+		{"v1.9.0", "1.33.0", true},
+		{"v1.10.2", "1.26.0", true},
+		{"v1.12.6", "1.35.1", false},
+		// Releases missing from the support table are not checked
+		{"v1.13.0", "1.36.0", false},
+		{"latest", "1.32.0", true},
+		{"v1.9.0", "1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.talos+"/"+tt.kubernetes, func(t *testing.T) {
+			t.Parallel()
+			err := CheckVersionCompatibility(tt.talos, tt.kubernetes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckVersionCompatibility(%q, %q) error = %v, wantErr %v", tt.talos, tt.kubernetes, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVersionMatrix_IsTested(t *testing.T) {
+	t.Parallel()
+	vm := DefaultVersionMatrix()
+
+	if !vm.IsTested(vm.Talos, vm.Kubernetes) {
+		t.Error("pinned versions should be tested")
+	}
+	// Patch releases of the pinned minor versions count as tested
+	if !vm.IsTested("v1.9.5", "1.32.3") {
+		t.Error("patch release of the pinned Talos version should be tested")
+	}
+	if vm.IsTested("v1.10.0", vm.Kubernetes) {
+		t.Error("different Talos minor version should not be tested")
+	}
+	if vm.IsTested(vm.Talos, "1.31.0") {
+		t.Error("different Kubernetes minor version should not be tested")
+	}
+}
@@ -0,0 +1,369 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// TerraformMigration is a k8zner.yaml converted from the variables of the
+// terraform-hcloud-kubernetes module.
+type TerraformMigration struct {
+	Spec *Spec
+
+	// Unsupported describes the settings that did not carry over, one per
+	// variable or node pool field, sorted.
+	Unsupported []string
+}
+
+// terraformFixedSettings are module variables k8zner does not expose, with
+// the value k8zner always uses. Other values are reported as unsupported.
+var terraformFixedSettings = map[string]any{
+	"cluster_domain":                               "cluster.local",
+	"network_ipv4_cidr":                            NetworkCIDR,
+	"network_node_ipv4_cidr":                       NodeCIDR,
+	"network_pod_ipv4_cidr":                        PodCIDR,
+	"network_service_ipv4_cidr":                    ServiceCIDR,
+	"network_node_ipv4_subnet_mask_size":           float64(25),
+	"talos_state_partition_encryption_enabled":     true,
+	"talos_ephemeral_partition_encryption_enabled": true,
+	"talos_ipv6_enabled":                           true,
+	"talos_public_ipv4_enabled":                    false,
+	"talos_public_ipv6_enabled":                    true,
+	"talos_coredns_enabled":                        true,
+	"talos_discovery_kubernetes_enabled":           true,
+	"talos_discovery_service_enabled":              true,
+	"cilium_enabled":                               true,
+	"hcloud_ccm_enabled":                           true,
+	"hcloud_csi_enabled":                           true,
+	"cert_manager_enabled":                         true,
+	"metrics_server_enabled":                       true,
+}
+
+// terraformNodePoolFields are the node pool fields that carry over, or that
+// k8zner always sets the same way (placement groups).
+var terraformNodePoolFields = []string{"name", "location", "type", "count", "labels", "taints", "min", "max", "placement_group"}
+
+// SpecFromTerraformVars converts the variables of a terraform-hcloud-kubernetes
+// module call, as returned by ParseTerraformVars, into a Spec. Settings that
+// k8zner.yaml cannot express are listed in Unsupported instead of failing the
+// conversion; values such as server types are carried over unchanged even
+// when k8zner does not support them, so apply reports them before it changes
+// anything. The hcloud_token variable is ignored, k8zner reads HCLOUD_TOKEN.
+func SpecFromTerraformVars(vars map[string]any) (*TerraformMigration, error) {
+	c := &terraformConverter{vars: vars, used: map[string]bool{"hcloud_token": true}, spec: &Spec{}}
+
+	name, ok := c.vars["cluster_name"].(string)
+	if !ok || name == "" {
+		return nil, errors.New("cluster_name is not set")
+	}
+	c.used["cluster_name"] = true
+	c.spec.Name = name
+
+	if err := c.controlPlanes(); err != nil {
+		return nil, err
+	}
+	if err := c.workers(); err != nil {
+		return nil, err
+	}
+	c.talos()
+	c.fixedSettings()
+
+	for name, value := range c.vars {
+		if !c.used[name] && isSetTerraformValue(value) {
+			c.unsupported(name, "has no equivalent in k8zner.yaml")
+		}
+	}
+
+	sort.Strings(c.notes)
+	return &TerraformMigration{Spec: c.spec, Unsupported: c.notes}, nil
+}
+
+// terraformConverter tracks the variables that were converted and the notes
+// about the ones that were not.
+type terraformConverter struct {
+	vars  map[string]any
+	used  map[string]bool
+	spec  *Spec
+	notes []string
+}
+
+func (c *terraformConverter) unsupported(field, format string, args ...any) {
+	c.notes = append(c.notes, field+": "+fmt.Sprintf(format, args...))
+}
+
+// controlPlanes sets the region, mode and control plane size. k8zner runs 1
+// or 3 control planes of one size in one region.
+func (c *terraformConverter) controlPlanes() error {
+	c.used["control_plane_nodepools"] = true
+	pools, err := terraformObjects(c.vars["control_plane_nodepools"], "control_plane_nodepools")
+	if err != nil {
+		return err
+	}
+	if len(pools) == 0 {
+		return errors.New("control_plane_nodepools is not set")
+	}
+
+	total := 0
+	locations := map[string]bool{}
+	sizes := map[string]bool{}
+	for i, pool := range pools {
+		field := fmt.Sprintf("control_plane_nodepools[%d]", i)
+		count, err := terraformInt(pool["count"], field+".count")
+		if err != nil {
+			return err
+		}
+		total += count
+		location, _ := pool["location"].(string)
+		size, _ := pool["type"].(string)
+		if i == 0 {
+			c.spec.Region = Region(location)
+			c.spec.ControlPlane = &ControlPlaneSpec{Size: ServerSize(size)}
+		}
+		locations[location] = true
+		sizes[size] = true
+		if isSetTerraformValue(pool["labels"]) || isSetTerraformValue(pool["taints"]) {
+			c.unsupported(field, "labels and taints of control plane nodes are not supported")
+		}
+		c.unusedPoolFields(field, pool)
+	}
+
+	if !c.spec.Region.IsValid() {
+		c.unsupported("control_plane_nodepools", "location %q is not supported, k8zner runs in %v", string(c.spec.Region), ValidRegions())
+	}
+	if len(locations) > 1 {
+		c.unsupported("control_plane_nodepools", "control planes are spread over %d locations, k8zner places all of them in %s", len(locations), string(c.spec.Region))
+	}
+	if !c.spec.ControlPlane.Size.IsValid() {
+		c.unsupported("control_plane_nodepools", "server type %s is not supported, use one of %v", string(c.spec.ControlPlane.Size), ValidServerSizes())
+	}
+	if len(sizes) > 1 {
+		c.unsupported("control_plane_nodepools", "control planes have %d server types, k8zner uses one (%s)", len(sizes), string(c.spec.ControlPlane.Size))
+	}
+
+	switch {
+	case total == ModeDev.ControlPlaneCount():
+		c.spec.Mode = ModeDev
+	case total == ModeHA.ControlPlaneCount():
+		c.spec.Mode = ModeHA
+	case total > 1:
+		c.spec.Mode = ModeHA
+		c.unsupported("control_plane_nodepools", "%d control planes, k8zner runs %d (ha)", total, ModeHA.ControlPlaneCount())
+	default:
+		c.spec.Mode = ModeDev
+		c.unsupported("control_plane_nodepools", "%d control planes, k8zner runs %d (dev)", total, ModeDev.ControlPlaneCount())
+	}
+	return nil
+}
+
+// workers converts the worker and cluster autoscaler node pools into named
+// worker pools. Autoscaled pools start at their minimum size.
+func (c *terraformConverter) workers() error {
+	c.used["worker_nodepools"] = true
+	c.used["cluster_autoscaler_nodepools"] = true
+
+	for _, kind := range []string{"worker_nodepools", "cluster_autoscaler_nodepools"} {
+		pools, err := terraformObjects(c.vars[kind], kind)
+		if err != nil {
+			return err
+		}
+		for i, pool := range pools {
+			field := fmt.Sprintf("%s[%d]", kind, i)
+			spec, err := c.workerPool(field, pool, kind == "cluster_autoscaler_nodepools")
+			if err != nil {
+				return err
+			}
+			c.spec.WorkerPools = append(c.spec.WorkerPools, spec)
+		}
+	}
+
+	if len(c.spec.WorkerPools) == 0 {
+		c.unsupported("worker_nodepools", "no worker node pools, k8zner needs at least 1 worker")
+	}
+	return nil
+}
+
+func (c *terraformConverter) workerPool(field string, pool map[string]any, autoscaled bool) (WorkerPoolSpec, error) {
+	name, _ := pool["name"].(string)
+	size, _ := pool["type"].(string)
+	location, _ := pool["location"].(string)
+	spec := WorkerPoolSpec{Name: name, Size: ServerSize(size)}
+	if location != string(c.spec.Region) {
+		spec.Location = Region(location)
+	}
+
+	if autoscaled {
+		minCount, err := terraformInt(pool["min"], field+".min")
+		if err != nil {
+			return spec, err
+		}
+		maxCount, err := terraformInt(pool["max"], field+".max")
+		if err != nil {
+			return spec, err
+		}
+		spec.Count = minCount
+		spec.Autoscaling = &WorkerPoolAutoscaling{MinCount: minCount, MaxCount: maxCount}
+	} else {
+		count, err := terraformInt(pool["count"], field+".count")
+		if err != nil {
+			return spec, err
+		}
+		spec.Count = count
+		if count > 5 {
+			c.unsupported(field, "%d workers, k8zner supports at most 5 per pool (split the pool or use autoscaling)", count)
+		}
+	}
+
+	if !isValidDNSName(name) || len(name) > 32 {
+		c.unsupported(field, "pool name %q must be DNS-safe and at most 32 characters", name)
+	}
+	if !spec.Size.IsValid() {
+		c.unsupported(field, "server type %s is not supported, use one of %v", string(spec.Size), ValidServerSizes())
+	}
+	if spec.Location != "" && !spec.Location.IsValid() {
+		c.unsupported(field, "location %q is not supported, k8zner runs in %v", string(spec.Location), ValidRegions())
+	}
+
+	if labels, ok := pool["labels"].(map[string]any); ok && len(labels) > 0 {
+		spec.Labels = make(map[string]string, len(labels))
+		for k, v := range labels {
+			spec.Labels[k] = fmt.Sprint(v)
+		}
+	}
+	taints, _ := pool["taints"].([]any)
+	for _, t := range taints {
+		s, _ := t.(string)
+		taint, ok := parseTerraformTaint(s)
+		if !ok {
+			c.unsupported(field, "taint %q is not in the form key=value:Effect", s)
+			continue
+		}
+		spec.Taints = append(spec.Taints, taint)
+	}
+
+	c.unusedPoolFields(field, pool)
+	return spec, nil
+}
+
+// unusedPoolFields reports node pool fields k8zner has no setting for, such
+// as server backups or rDNS.
+func (c *terraformConverter) unusedPoolFields(field string, pool map[string]any) {
+	for key, value := range pool {
+		if !slices.Contains(terraformNodePoolFields, key) && isSetTerraformValue(value) {
+			c.unsupported(field+"."+key, "has no equivalent in k8zner.yaml")
+		}
+	}
+}
+
+// talos carries over the config apply mode and backups, and reports pinned
+// versions that differ from the k8zner version matrix.
+func (c *terraformConverter) talos() {
+	if mode, ok := c.vars["talos_machine_configuration_apply_mode"].(string); ok {
+		c.used["talos_machine_configuration_apply_mode"] = true
+		c.spec.ConfigApplyMode = mode
+		if !slices.Contains(validConfigApplyModes(), mode) {
+			c.unsupported("talos_machine_configuration_apply_mode", "%q is not supported, use one of %v", mode, validConfigApplyModes())
+		}
+	}
+
+	vm := DefaultVersionMatrix()
+	versions := map[string]string{"talos_version": vm.Talos, "kubernetes_version": vm.Kubernetes}
+	for name, pinned := range versions {
+		version, ok := c.vars[name].(string)
+		if !ok {
+			continue
+		}
+		c.used[name] = true
+		if strings.TrimPrefix(version, "v") != strings.TrimPrefix(pinned, "v") {
+			c.unsupported(name, "%s is not supported, k8zner uses its tested version %s", version, pinned)
+		}
+	}
+
+	backup := false
+	for name, value := range c.vars {
+		if strings.HasPrefix(name, "talos_backup_") {
+			c.used[name] = true
+			backup = backup || isSetTerraformValue(value)
+		}
+	}
+	if backup {
+		c.spec.Backup = true
+		c.unsupported("talos_backup_*", "etcd backups are enabled, k8zner writes them to the bucket %s with the HETZNER_S3_ACCESS_KEY and HETZNER_S3_SECRET_KEY credentials, the bucket, schedule and keys of the module are not used", c.spec.BackupBucketName())
+	}
+}
+
+// fixedSettings reports settings whose value differs from the one k8zner
+// always uses.
+func (c *terraformConverter) fixedSettings() {
+	for name, fixed := range terraformFixedSettings {
+		value, ok := c.vars[name]
+		if !ok {
+			continue
+		}
+		c.used[name] = true
+		if value != nil && !reflect.DeepEqual(value, fixed) {
+			c.unsupported(name, "%v is not supported, k8zner always uses %v", value, fixed)
+		}
+	}
+}
+
+// parseTerraformTaint parses a taint in the module's key=value:Effect form.
+func parseTerraformTaint(s string) (Taint, bool) {
+	i := strings.LastIndex(s, ":")
+	if i < 1 || !slices.Contains(validTaintEffects(), s[i+1:]) {
+		return Taint{}, false
+	}
+	keyValue, effect := s[:i], s[i+1:]
+	key, value, _ := strings.Cut(keyValue, "=")
+	return Taint{Key: key, Value: value, Effect: effect}, true
+}
+
+// terraformObjects returns a list of objects, such as a node pool list.
+func terraformObjects(value any, field string) ([]map[string]any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list", field)
+	}
+	objects := make([]map[string]any, 0, len(list))
+	for i, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] must be an object", field, i)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+func terraformInt(value any, field string) (int, error) {
+	n, ok := value.(float64)
+	if !ok || n != float64(int(n)) {
+		return 0, fmt.Errorf("%s must be a whole number", field)
+	}
+	return int(n), nil
+}
+
+// isSetTerraformValue reports whether a value differs from the empty default
+// of an optional variable: null, false, "" and empty lists and objects.
+func isSetTerraformValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
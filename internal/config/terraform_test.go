@@ -0,0 +1,129 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecFromTerraformVars(t *testing.T) {
+	t.Parallel()
+
+	vars, err := ParseTerraformVars([]byte(`
+cluster_name = "prod"
+hcloud_token = "secret"
+talos_version = "v1.9.0"
+talos_machine_configuration_apply_mode = "no_reboot"
+talos_state_partition_encryption_enabled = true
+network_ipv4_cidr = "10.0.0.0/16"
+talos_backup_s3_hcloud_url = "https://prod-backups.fsn1.your-objectstorage.com"
+
+control_plane_nodepools = [
+  { name = "control", type = "cx22", location = "fsn1", count = 3, placement_group = true }
+]
+worker_nodepools = [
+  { name = "workers", type = "cx32", location = "fsn1", count = 2 },
+  { name = "db", type = "cx42", location = "nbg1", count = 1, labels = { tier = "db" }, taints = ["dedicated=db:NoSchedule"] },
+]
+cluster_autoscaler_nodepools = [
+  { name = "burst", type = "cpx32", location = "fsn1", min = 0, max = 4 }
+]
+`))
+	require.NoError(t, err)
+
+	m, err := SpecFromTerraformVars(vars)
+	require.NoError(t, err)
+
+	assert.Equal(t, &Spec{
+		Name:            "prod",
+		Region:          RegionFalkenstein,
+		Mode:            ModeHA,
+		ControlPlane:    &ControlPlaneSpec{Size: SizeCX22},
+		ConfigApplyMode: "no_reboot",
+		Backup:          true,
+		WorkerPools: []WorkerPoolSpec{
+			{Name: "workers", Count: 2, Size: SizeCX32},
+			{
+				Name:     "db",
+				Count:    1,
+				Size:     SizeCX42,
+				Location: RegionNuremberg,
+				Labels:   map[string]string{"tier": "db"},
+				Taints:   []Taint{{Key: "dedicated", Value: "db", Effect: "NoSchedule"}},
+			},
+			{Name: "burst", Count: 0, Size: SizeCPX32, Autoscaling: &WorkerPoolAutoscaling{MinCount: 0, MaxCount: 4}},
+		},
+	}, m.Spec)
+	require.Len(t, m.Unsupported, 1)
+	assert.Contains(t, m.Unsupported[0], "talos_backup_*: etcd backups are enabled, k8zner writes them to the bucket prod-etcd-backups")
+}
+
+func TestSpecFromTerraformVars_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	vars, err := ParseTerraformVars([]byte(`
+cluster_name = "prod"
+kubernetes_version = "1.30.3"
+talos_public_ipv4_enabled = true
+cluster_domain = "cluster.local"
+longhorn_enabled = true
+ingress_nginx_enabled = false
+
+control_plane_nodepools = [
+  { name = "cp-a", type = "cax21", location = "fsn1", count = 1, labels = { a = "b" } },
+  { name = "cp-b", type = "cax21", location = "nbg1", count = 1 },
+]
+worker_nodepools = [
+  { name = "workers", type = "cx32", location = "fsn1", count = 8, backups = true, taints = ["broken"] },
+]
+`))
+	require.NoError(t, err)
+
+	m, err := SpecFromTerraformVars(vars)
+	require.NoError(t, err)
+
+	assert.Equal(t, ModeHA, m.Spec.Mode)
+	assert.Equal(t, RegionFalkenstein, m.Spec.Region)
+	assert.Equal(t, ServerSize("cax21"), m.Spec.ControlPlane.Size)
+	assert.Equal(t, 8, m.Spec.WorkerPools[0].Count)
+	assert.Empty(t, m.Spec.WorkerPools[0].Taints)
+
+	assert.Equal(t, []string{
+		"control_plane_nodepools: 2 control planes, k8zner runs 3 (ha)",
+		"control_plane_nodepools: control planes are spread over 2 locations, k8zner places all of them in fsn1",
+		"control_plane_nodepools: server type cax21 is not supported, use one of [cpx22 (2 vCPU, 4GB RAM) cpx32 (4 vCPU, 8GB RAM) cpx42 (8 vCPU, 16GB RAM) cpx52 (16 vCPU, 32GB RAM) cx23 (2 vCPU, 4GB RAM) cx33 (4 vCPU, 8GB RAM) cx43 (8 vCPU, 16GB RAM) cx53 (16 vCPU, 32GB RAM)]",
+		"control_plane_nodepools[0]: labels and taints of control plane nodes are not supported",
+		"kubernetes_version: 1.30.3 is not supported, k8zner uses its tested version 1.32.0",
+		"longhorn_enabled: has no equivalent in k8zner.yaml",
+		"talos_public_ipv4_enabled: true is not supported, k8zner always uses false",
+		"worker_nodepools[0].backups: has no equivalent in k8zner.yaml",
+		"worker_nodepools[0]: 8 workers, k8zner supports at most 5 per pool (split the pool or use autoscaling)",
+		"worker_nodepools[0]: taint \"broken\" is not in the form key=value:Effect",
+	}, m.Unsupported)
+}
+
+func TestSpecFromTerraformVars_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		vars map[string]any
+		want string
+	}{
+		{"no cluster name", map[string]any{}, "cluster_name is not set"},
+		{"no control planes", map[string]any{"cluster_name": "prod"}, "control_plane_nodepools is not set"},
+		{"bad count", map[string]any{
+			"cluster_name":            "prod",
+			"control_plane_nodepools": []any{map[string]any{"count": "three"}},
+		}, "control_plane_nodepools[0].count must be a whole number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := SpecFromTerraformVars(tt.vars)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
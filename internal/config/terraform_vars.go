@@ -0,0 +1,373 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseTerraformVars parses a Terraform variable definitions file (.tfvars or
+// .tfvars.json). Numbers are returned as float64, lists as []any and objects
+// as map[string]any, the same as encoding/json. Variable files only take
+// literal values, so expressions and interpolations are rejected.
+func ParseTerraformVars(data []byte) (map[string]any, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var vars map[string]any
+		if err := json.Unmarshal(trimmed, &vars); err != nil {
+			return nil, fmt.Errorf("failed to parse tfvars JSON: %w", err)
+		}
+		return vars, nil
+	}
+
+	p := &tfvarsParser{src: data, line: 1}
+	vars := make(map[string]any)
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return vars, nil
+		}
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := vars[name]; ok {
+			return nil, p.errorf("%s is assigned more than once", name)
+		}
+		p.skipSpace(false)
+		if p.peek() != '=' {
+			return nil, p.errorf("expected = after %s", name)
+		}
+		p.pos++
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		vars[name] = value
+		p.skipSpace(false)
+		if !p.eof() && p.peek() != '\n' {
+			return nil, p.errorf("expected a new line after the value of %s", name)
+		}
+	}
+}
+
+// tfvarsParser reads the HCL subset allowed in variable files: attributes
+// with strings, heredocs, numbers, bools, null, lists and objects.
+type tfvarsParser struct {
+	src  []byte
+	pos  int
+	line int
+}
+
+func (p *tfvarsParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tfvarsParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *tfvarsParser) hasPrefix(s string) bool {
+	return bytes.HasPrefix(p.src[p.pos:], []byte(s))
+}
+
+func (p *tfvarsParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("tfvars line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips blanks and comments, and new lines if newlines is set.
+func (p *tfvarsParser) skipSpace(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n':
+			if !newlines {
+				return
+			}
+			p.pos++
+			p.line++
+		case c == '#' || p.hasPrefix("//"):
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case p.hasPrefix("/*"):
+			end := bytes.Index(p.src[p.pos+2:], []byte("*/"))
+			if end < 0 {
+				p.pos = len(p.src)
+				return
+			}
+			p.line += bytes.Count(p.src[p.pos:p.pos+2+end], []byte("\n"))
+			p.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+func isIdentByte(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return true
+	case c >= '0' && c <= '9', c == '-':
+		return !first
+	default:
+		return false
+	}
+}
+
+func (p *tfvarsParser) ident() (string, error) {
+	start := p.pos
+	for !p.eof() && isIdentByte(p.peek(), p.pos == start) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a variable name, found %q", p.peek())
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+func (p *tfvarsParser) value() (any, error) {
+	p.skipSpace(true)
+	switch c := p.peek(); {
+	case p.eof():
+		return nil, p.errorf("expected a value, found end of file")
+	case c == '"':
+		return p.quoted()
+	case p.hasPrefix("<<"):
+		return p.heredoc()
+	case c == '[':
+		return p.list()
+	case c == '{':
+		return p.object()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.number()
+	default:
+		word, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		switch word {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		default:
+			return nil, p.errorf("%s is not a literal value, variable files cannot contain expressions", word)
+		}
+	}
+}
+
+func (p *tfvarsParser) quoted() (string, error) {
+	p.pos++ // opening quote
+	var sb strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		switch {
+		case c == '"':
+			p.pos++
+			return sb.String(), nil
+		case p.hasPrefix("$${"), p.hasPrefix("%%{"):
+			sb.WriteByte(c)
+			sb.WriteByte('{')
+			p.pos += 3
+		case p.hasPrefix("${"), p.hasPrefix("%{"):
+			return "", p.errorf("variable files cannot contain template interpolations")
+		case c == '\\':
+			r, err := p.escape()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(r)
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *tfvarsParser) escape() (string, error) {
+	if p.pos+1 >= len(p.src) {
+		return "", p.errorf("unterminated string")
+	}
+	c := p.src[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'n':
+		return "\n", nil
+	case 't':
+		return "\t", nil
+	case 'r':
+		return "\r", nil
+	case '"', '\\':
+		return string(c), nil
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return "", p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(string(p.src[p.pos:p.pos+size]), 16, 32)
+		if err != nil {
+			return "", p.errorf("invalid unicode escape")
+		}
+		p.pos += size
+		return string(rune(code)), nil
+	default:
+		return "", p.errorf("invalid escape sequence \\%c", c)
+	}
+}
+
+// heredoc reads a <<MARKER or <<-MARKER string. The indented form strips the
+// leading whitespace all lines share.
+func (p *tfvarsParser) heredoc() (string, error) {
+	p.pos += 2
+	indented := p.peek() == '-'
+	if indented {
+		p.pos++
+	}
+	marker, err := p.ident()
+	if err != nil {
+		return "", err
+	}
+	p.skipSpace(false)
+	if p.peek() != '\n' {
+		return "", p.errorf("expected a new line after <<%s", marker)
+	}
+	p.pos++
+	p.line++
+
+	var lines []string
+	for !p.eof() {
+		end := bytes.IndexByte(p.src[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.src) - p.pos
+		}
+		line := string(p.src[p.pos : p.pos+end])
+		p.pos += end
+		if strings.TrimSpace(line) == marker {
+			return joinHeredoc(lines, indented), nil
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+		if !p.eof() {
+			p.pos++
+			p.line++
+		}
+	}
+	return "", p.errorf("heredoc %s is not terminated", marker)
+}
+
+func joinHeredoc(lines []string, indented bool) string {
+	if indented {
+		indent := -1
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			n := len(line) - len(strings.TrimLeft(line, " \t"))
+			if indent < 0 || n < indent {
+				indent = n
+			}
+		}
+		for i, line := range lines {
+			if len(line) >= indent && indent > 0 {
+				lines[i] = line[indent:]
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (p *tfvarsParser) number() (float64, error) {
+	start := p.pos
+	for !p.eof() && strings.IndexByte("0123456789.eE+-", p.peek()) >= 0 {
+		p.pos++
+	}
+	n, err := strconv.ParseFloat(string(p.src[start:p.pos]), 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %q", p.src[start:p.pos])
+	}
+	return n, nil
+}
+
+func (p *tfvarsParser) list() ([]any, error) {
+	p.pos++ // [
+	items := []any{}
+	for {
+		p.skipSpace(true)
+		if p.peek() == ']' {
+			p.pos++
+			return items, nil
+		}
+		item, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		p.skipSpace(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected , or ] in list")
+		}
+	}
+}
+
+func (p *tfvarsParser) object() (map[string]any, error) {
+	p.pos++ // {
+	obj := map[string]any{}
+	for {
+		p.skipSpace(true)
+		if p.peek() == '}' {
+			p.pos++
+			return obj, nil
+		}
+		var key string
+		var err error
+		if p.peek() == '"' {
+			key, err = p.quoted()
+		} else {
+			key, err = p.ident()
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace(false)
+		if c := p.peek(); c != '=' && c != ':' {
+			return nil, p.errorf("expected = after %s", key)
+		}
+		p.pos++
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = value
+		p.skipSpace(false)
+		switch p.peek() {
+		case ',', '\n':
+			p.pos++
+			if p.src[p.pos-1] == '\n' {
+				p.line++
+			}
+		case '}':
+		default:
+			return nil, p.errorf("expected , or a new line after %s", key)
+		}
+	}
+}
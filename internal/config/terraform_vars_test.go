@@ -0,0 +1,102 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTerraformVars(t *testing.T) {
+	t.Parallel()
+
+	data := `
+# Cluster
+cluster_name = "prod" // trailing comment
+hcloud_token = "tok\"en"
+/* block
+   comment */
+cluster_delete_protection = true
+talos_public_ipv4_enabled = false
+network_node_ipv4_subnet_mask_size = 25
+kube_api_hostname = null
+talos_extra_kernel_args = ["a", "b",]
+
+control_plane_nodepools = [
+  { name = "control", type = "cx22", location = "fsn1", count = 3 }
+]
+worker_nodepools = [
+  {
+    name     = "workers"
+    type     = "cx32"
+    location = "fsn1"
+    count    = 2
+    labels   = { "node.kubernetes.io/role" = "worker" }
+    taints   = ["dedicated=db:NoSchedule"]
+  },
+]
+escaped = "$${literal}"
+talos_extra_config = <<-EOT
+    machine:
+      sysctls: {}
+  EOT
+`
+	vars, err := ParseTerraformVars([]byte(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, "prod", vars["cluster_name"])
+	assert.Equal(t, `tok"en`, vars["hcloud_token"])
+	assert.Equal(t, true, vars["cluster_delete_protection"])
+	assert.Equal(t, false, vars["talos_public_ipv4_enabled"])
+	assert.Equal(t, float64(25), vars["network_node_ipv4_subnet_mask_size"])
+	assert.Contains(t, vars, "kube_api_hostname")
+	assert.Nil(t, vars["kube_api_hostname"])
+	assert.Equal(t, []any{"a", "b"}, vars["talos_extra_kernel_args"])
+	assert.Equal(t, []any{map[string]any{"name": "control", "type": "cx22", "location": "fsn1", "count": float64(3)}}, vars["control_plane_nodepools"])
+	assert.Equal(t, []any{map[string]any{
+		"name":     "workers",
+		"type":     "cx32",
+		"location": "fsn1",
+		"count":    float64(2),
+		"labels":   map[string]any{"node.kubernetes.io/role": "worker"},
+		"taints":   []any{"dedicated=db:NoSchedule"},
+	}}, vars["worker_nodepools"])
+	assert.Equal(t, "${literal}", vars["escaped"])
+	assert.Equal(t, "machine:\n  sysctls: {}\n", vars["talos_extra_config"])
+}
+
+func TestParseTerraformVars_JSON(t *testing.T) {
+	t.Parallel()
+
+	vars, err := ParseTerraformVars([]byte(`{"cluster_name": "prod", "worker_nodepools": [{"count": 2}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "prod", vars["cluster_name"])
+	assert.Equal(t, []any{map[string]any{"count": float64(2)}}, vars["worker_nodepools"])
+}
+
+func TestParseTerraformVars_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"expression", "cluster_name = var.name\n", "line 1: var is not a literal value"},
+		{"interpolation", "cluster_name = \"${local.name}\"\n", "template interpolations"},
+		{"duplicate", "a = 1\na = 2\n", "line 2: a is assigned more than once"},
+		{"missing equals", "a 1\n", "expected = after a"},
+		{"two values on a line", "a = 1 b = 2\n", "expected a new line after the value of a"},
+		{"unterminated list", "a = [1, 2\n", "expected , or ] in list"},
+		{"unterminated string", "a = \"abc\n", "unterminated string"},
+		{"unterminated heredoc", "a = <<EOT\nabc\n", "heredoc EOT is not terminated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseTerraformVars([]byte(tt.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
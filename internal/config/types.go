@@ -26,10 +26,12 @@ type Config struct {
 	// Default: false
 	DeleteProtection bool `mapstructure:"delete_protection" yaml:"delete_protection"`
 
-	// KubeconfigPath specifies where to write the kubeconfig file.
+	// KubeconfigPath specifies where to write the kubeconfig file, relative to
+	// the cluster workspace or the working directory.
 	KubeconfigPath string `mapstructure:"kubeconfig_path" yaml:"kubeconfig_path"`
 
-	// TalosconfigPath specifies where to write the talosconfig file.
+	// TalosconfigPath specifies where to write the talosconfig file, relative
+	// to the cluster workspace or the working directory.
 	TalosconfigPath string `mapstructure:"talosconfig_path" yaml:"talosconfig_path"`
 
 	// TalosctlVersionCheckEnabled verifies talosctl version compatibility.
@@ -63,6 +65,27 @@ type Config struct {
 
 	// Addons Configuration
 	Addons AddonsConfig `mapstructure:"addons" yaml:"addons"`
+
+	// Maintenance windows for disruptive operator actions
+	Maintenance *MaintenanceSpec `mapstructure:"maintenance" yaml:"maintenance,omitempty"`
+
+	// State backend for the Talos secrets and access files
+	State StateConfig `mapstructure:"state" yaml:"state"`
+
+	// Age recipients the local credential files are encrypted for (none: plaintext)
+	SecretsRecipients []string `mapstructure:"secrets_recipients" yaml:"secrets_recipients,omitempty"`
+}
+
+// StateConfig defines where the cluster state is kept and locked.
+type StateConfig struct {
+	// Backend is "local" or "s3".
+	Backend     string   `mapstructure:"backend" yaml:"backend"`
+	S3Bucket    string   `mapstructure:"s3_bucket" yaml:"s3_bucket,omitempty"`
+	S3Region    string   `mapstructure:"s3_region" yaml:"s3_region,omitempty"`
+	S3Endpoint  string   `mapstructure:"s3_endpoint" yaml:"s3_endpoint,omitempty"`
+	S3AccessKey string   `mapstructure:"s3_access_key" yaml:"s3_access_key,omitempty"`
+	S3SecretKey string   `mapstructure:"s3_secret_key" yaml:"s3_secret_key,omitempty"`
+	Recipients  []string `mapstructure:"recipients" yaml:"recipients,omitempty"`
 }
 
 // NetworkConfig defines the network-related configuration.
@@ -122,6 +145,14 @@ type WorkerNodePool struct {
 	PlacementGroup bool              `mapstructure:"placement_group" yaml:"placement_group"`
 	Image          string            `mapstructure:"image" yaml:"image"` // Optional override
 	Backups        bool              `mapstructure:"backups" yaml:"backups"`
+
+	// NodeLabels and NodeTaints are applied to the Kubernetes nodes of this pool.
+	// Labels above are Hetzner server labels.
+	NodeLabels map[string]string `mapstructure:"node_labels" yaml:"node_labels,omitempty"`
+	NodeTaints []Taint           `mapstructure:"node_taints" yaml:"node_taints,omitempty"`
+
+	// Autoscaling is handed to the operator, which resizes the pool at runtime.
+	Autoscaling *WorkerPoolAutoscaling `mapstructure:"autoscaling" yaml:"autoscaling,omitempty"`
 }
 
 // IngressConfig defines the ingress load balancer configuration.
@@ -217,6 +248,16 @@ func (c *Config) WorkerCount() int {
 	return count
 }
 
+// WorkerPool returns the worker pool with the given name, or nil if there is none.
+func (c *Config) WorkerPool(name string) *WorkerNodePool {
+	for i := range c.Workers {
+		if c.Workers[i].Name == name {
+			return &c.Workers[i]
+		}
+	}
+	return nil
+}
+
 // IsPrivateFirst returns true if the cluster should use private-first architecture.
 func (c *Config) IsPrivateFirst() bool {
 	return c.ClusterAccess == "private"
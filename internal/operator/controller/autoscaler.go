@@ -0,0 +1,527 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/util/labels"
+)
+
+const (
+	// Defaults for spec.workerPools[].autoscaling.
+	defaultScaleDownUtilizationThreshold = 50
+	defaultScaleDownDelay                = 10 * time.Minute
+)
+
+// Autoscaler decisions, used as the metric label.
+const (
+	autoscaleDecisionScaleUp   = "scale_up"
+	autoscaleDecisionScaleDown = "scale_down"
+	autoscaleDecisionAtMax     = "at_max"
+)
+
+// podResources is the CPU (millicores) and memory (bytes) requested by pods or
+// offered by a node.
+type podResources struct {
+	cpu    int64
+	memory int64
+}
+
+func (a podResources) add(b podResources) podResources {
+	return podResources{cpu: a.cpu + b.cpu, memory: a.memory + b.memory}
+}
+
+func (a podResources) sub(b podResources) podResources {
+	return podResources{cpu: a.cpu - b.cpu, memory: a.memory - b.memory}
+}
+
+func (a podResources) fits(capacity podResources) bool {
+	return a.cpu <= capacity.cpu && a.memory <= capacity.memory
+}
+
+// workerPoolTemplate describes a new node of a pool: what it offers and how
+// it is labeled and tainted.
+type workerPoolTemplate struct {
+	capacity podResources
+	labels   map[string]string
+	taints   []corev1.Taint
+}
+
+// autoscaleWorkerPools adjusts the desired size of pools with autoscaling enabled.
+// Unschedulable pods raise the target of the first pool they fit on, sized to fit
+// them all. A node that stays underutilized for the pool's scale-down delay lowers
+// the target by one. The new target is applied by scaleWorkers like any other
+// count change, which removes the longest-underutilized node first.
+func (r *ClusterReconciler) autoscaleWorkerPools(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) {
+	logger := log.FromContext(ctx)
+
+	var pools []k8znerv1alpha1.WorkerPoolSpec
+	for _, pool := range workerPools(cluster) {
+		if pool.Autoscaling != nil {
+			pools = append(pools, pool)
+		}
+	}
+	if len(pools) == 0 {
+		return
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList); err != nil {
+		logger.Error(err, "failed to list pods for autoscaling")
+		return
+	}
+	nodeList := &corev1.NodeList{}
+	if err := r.List(ctx, nodeList); err != nil {
+		logger.Error(err, "failed to list nodes for autoscaling")
+		return
+	}
+
+	nodes := make(map[string]*corev1.Node, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodes[nodeList.Items[i].Name] = &nodeList.Items[i]
+	}
+	podsByNode := make(map[string][]*corev1.Pod)
+	var pending []*corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		switch {
+		case pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed:
+		case pod.Spec.NodeName != "":
+			podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+		case isPodUnschedulable(pod):
+			pending = append(pending, pod)
+		}
+	}
+	r.recordUnschedulablePods(cluster.Name, len(pending))
+
+	now := metav1.Now()
+	if len(pending) > 0 && r.autoscaleUp(ctx, cluster, pools, pending, nodes, podsByNode, now) {
+		// Removing capacity while pods wait for it would only delay them further.
+		for i := range cluster.Status.Workers.Nodes {
+			cluster.Status.Workers.Nodes[i].UnderutilizedSince = nil
+		}
+		return
+	}
+
+	for _, pool := range pools {
+		r.autoscaleDown(ctx, cluster, pool, nodes, podsByNode, now)
+	}
+}
+
+// autoscaleUp raises the target of autoscaled pools so that pending pods fit.
+// Each pod is assigned to the first pool, in spec order, whose nodes could run it.
+// Returns false if no pending pod fits any autoscaled pool.
+func (r *ClusterReconciler) autoscaleUp(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, pools []k8znerv1alpha1.WorkerPoolSpec, pending []*corev1.Pod, nodes map[string]*corev1.Node, podsByNode map[string][]*corev1.Pod, now metav1.Time) bool {
+	logger := log.FromContext(ctx)
+
+	templates := make(map[string]workerPoolTemplate, len(pools))
+	for _, pool := range pools {
+		templates[pool.Name] = buildWorkerPoolTemplate(pool, nodes, podsByNode)
+	}
+
+	podsByPool := make(map[string][]*corev1.Pod)
+	for _, pod := range pending {
+		fitted := false
+		for _, pool := range pools {
+			if podFitsTemplate(pod, templates[pool.Name]) {
+				podsByPool[pool.Name] = append(podsByPool[pool.Name], pod)
+				fitted = true
+				break
+			}
+		}
+		if !fitted {
+			logger.V(1).Info("no autoscaled worker pool can run pod", "pod", pod.Namespace+"/"+pod.Name)
+		}
+	}
+	if len(podsByPool) == 0 {
+		return false
+	}
+
+	for _, pool := range pools {
+		pods := podsByPool[pool.Name]
+		if len(pods) == 0 || workerPoolConverging(cluster, pool) {
+			continue
+		}
+
+		current := workerPoolDesiredCount(cluster, pool)
+		target := min(current+nodesNeededForPods(pods, templates[pool.Name].capacity), pool.Autoscaling.MaxCount)
+		if target <= current {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonAutoscaleBlocked,
+				"Pool %s is at its maximum of %d nodes, %d pods remain unschedulable",
+				pool.Name, pool.Autoscaling.MaxCount, len(pods))
+			r.recordAutoscalerDecision(cluster.Name, pool.Name, autoscaleDecisionAtMax)
+			continue
+		}
+
+		status := setWorkerPoolDesiredCount(cluster, pool.Name, target)
+		status.LastScaleUpTime = &now
+
+		logger.Info("autoscaling worker pool up", "pool", pool.Name, "from", current, "to", target, "unschedulablePods", len(pods))
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonAutoscaleUp,
+			"Scaling pool %s up: %d -> %d nodes for %d unschedulable pods", pool.Name, current, target, len(pods))
+		r.recordAutoscalerDecision(cluster.Name, pool.Name, autoscaleDecisionScaleUp)
+	}
+
+	return true
+}
+
+// autoscaleDown tracks how long each node of pool has been underutilized and lowers
+// the pool's target by one once a node has been underutilized for the scale-down delay.
+func (r *ClusterReconciler) autoscaleDown(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, pool k8znerv1alpha1.WorkerPoolSpec, nodes map[string]*corev1.Node, podsByNode map[string][]*corev1.Pod, now metav1.Time) {
+	logger := log.FromContext(ctx)
+
+	threshold := pool.Autoscaling.ScaleDownUtilizationThreshold
+	if threshold <= 0 {
+		threshold = defaultScaleDownUtilizationThreshold
+	}
+	delay := parseScaleDownDelay(pool.Autoscaling)
+	converging := workerPoolConverging(cluster, pool)
+
+	var candidate *k8znerv1alpha1.NodeStatus
+	for _, node := range workerPoolNodes(cluster, pool.Name) {
+		k8sNode := nodes[node.Name]
+		if converging || k8sNode == nil || k8sNode.Spec.Unschedulable ||
+			!podsCanMove(podsByNode[node.Name]) ||
+			nodeUtilization(k8sNode, podsByNode[node.Name]) >= threshold {
+			node.UnderutilizedSince = nil
+			continue
+		}
+		if node.UnderutilizedSince == nil {
+			since := now
+			node.UnderutilizedSince = &since
+			continue
+		}
+		if now.Sub(node.UnderutilizedSince.Time) < delay {
+			continue
+		}
+		if candidate == nil || node.UnderutilizedSince.Before(candidate.UnderutilizedSince) {
+			candidate = node
+		}
+	}
+	if candidate == nil {
+		return
+	}
+
+	current := workerPoolDesiredCount(cluster, pool)
+	if current <= pool.Autoscaling.MinCount {
+		return
+	}
+	status := findWorkerPoolStatus(cluster, pool.Name)
+	if status != nil && status.LastScaleUpTime != nil && now.Sub(status.LastScaleUpTime.Time) < delay {
+		return
+	}
+
+	if !r.disruptionAllowed(ctx, cluster, maintenanceActionScaleDown, pool.Name,
+		fmt.Sprintf("autoscaler: %s below %d%% utilization since %s",
+			candidate.Name, threshold, candidate.UnderutilizedSince.Format(time.RFC3339))) {
+		return
+	}
+
+	status = setWorkerPoolDesiredCount(cluster, pool.Name, current-1)
+	status.LastScaleDownTime = &now
+
+	logger.Info("autoscaling worker pool down", "pool", pool.Name, "from", current, "to", current-1,
+		"node", candidate.Name, "underutilizedSince", candidate.UnderutilizedSince)
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonAutoscaleDown,
+		"Scaling pool %s down: %d -> %d nodes, %s has been below %d%% utilization since %s",
+		pool.Name, current, current-1, candidate.Name, threshold, candidate.UnderutilizedSince.Format(time.RFC3339))
+	r.recordAutoscalerDecision(cluster.Name, pool.Name, autoscaleDecisionScaleDown)
+}
+
+// parseScaleDownDelay returns the pool's scale-down delay, falling back to the
+// default when unset or invalid.
+func parseScaleDownDelay(as *k8znerv1alpha1.WorkerPoolAutoscalingSpec) time.Duration {
+	if as.ScaleDownDelay == "" {
+		return defaultScaleDownDelay
+	}
+	d, err := time.ParseDuration(as.ScaleDownDelay)
+	if err != nil || d < 0 {
+		return defaultScaleDownDelay
+	}
+	return d
+}
+
+// setWorkerPoolDesiredCount records a new autoscaler target for the named pool and
+// returns its status entry.
+func setWorkerPoolDesiredCount(cluster *k8znerv1alpha1.K8znerCluster, name string, desired int) *k8znerv1alpha1.WorkerPoolStatus {
+	status := findWorkerPoolStatus(cluster, name)
+	if status == nil {
+		cluster.Status.WorkerPools = append(cluster.Status.WorkerPools, k8znerv1alpha1.WorkerPoolStatus{Name: name})
+		status = &cluster.Status.WorkerPools[len(cluster.Status.WorkerPools)-1]
+	}
+	status.Desired = desired
+	cluster.Status.Workers.Desired = desiredWorkerCount(cluster)
+	return status
+}
+
+// workerPoolConverging reports whether pool is still moving towards its desired count
+// or has unhealthy nodes. The autoscaler leaves such pools alone so nodes that are
+// still booting are not counted twice.
+func workerPoolConverging(cluster *k8znerv1alpha1.K8znerCluster, pool k8znerv1alpha1.WorkerPoolSpec) bool {
+	nodes := workerPoolNodes(cluster, pool.Name)
+	if len(nodes) != workerPoolDesiredCount(cluster, pool) {
+		return true
+	}
+	for _, node := range nodes {
+		if !node.Healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// isPodUnschedulable reports whether the scheduler found no node for pod.
+func isPodUnschedulable(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable {
+			return true
+		}
+	}
+	return false
+}
+
+// buildWorkerPoolTemplate describes a new node of pool. Capacity and labels are taken
+// from an existing node of the pool, minus what its DaemonSet pods request, since a
+// new node runs the same DaemonSets. Without one, the server type's specs are used.
+func buildWorkerPoolTemplate(pool k8znerv1alpha1.WorkerPoolSpec, nodes map[string]*corev1.Node, podsByNode map[string][]*corev1.Pod) workerPoolTemplate {
+	template := workerPoolTemplate{
+		labels: map[string]string{
+			corev1.LabelOSStable:   "linux",
+			corev1.LabelArchStable: "amd64",
+		},
+		taints: pool.Taints,
+	}
+
+	var sample *corev1.Node
+	for _, node := range nodes {
+		if node.Labels[labels.KeyPool] == pool.Name && isNodeReady(node) {
+			if sample == nil || node.Name < sample.Name {
+				sample = node
+			}
+		}
+	}
+
+	if sample != nil {
+		for key, value := range sample.Labels {
+			template.labels[key] = value
+		}
+		delete(template.labels, corev1.LabelHostname)
+		template.capacity = resourcesFromList(sample.Status.Allocatable)
+		for _, pod := range podsByNode[sample.Name] {
+			if isDaemonSetPod(pod) {
+				template.capacity = template.capacity.sub(podRequests(pod))
+			}
+		}
+	} else {
+		specs := config.ServerSize(pool.Size).Specs()
+		template.capacity = podResources{
+			cpu:    int64(specs.VCPU) * 1000,
+			memory: int64(specs.RAMGB) << 30,
+		}
+	}
+
+	template.labels[labels.KeyPool] = pool.Name
+	for key, value := range pool.Labels {
+		template.labels[key] = value
+	}
+	return template
+}
+
+// podFitsTemplate reports whether pod could be scheduled on an empty node built
+// from template.
+func podFitsTemplate(pod *corev1.Pod, template workerPoolTemplate) bool {
+	if !podRequests(pod).fits(template.capacity) {
+		return false
+	}
+	for key, value := range pod.Spec.NodeSelector {
+		if template.labels[key] != value {
+			return false
+		}
+	}
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		if required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			if !matchesNodeSelectorTerms(required.NodeSelectorTerms, template.labels) {
+				return false
+			}
+		}
+	}
+	for _, taint := range template.taints {
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !toleratesTaint(pod.Spec.Tolerations, taint) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesNodeSelectorTerms reports whether nodeLabels satisfy at least one term.
+// Only label expressions are evaluated; terms that select on fields never match.
+func matchesNodeSelectorTerms(terms []corev1.NodeSelectorTerm, nodeLabels map[string]string) bool {
+	for _, term := range terms {
+		if len(term.MatchFields) > 0 {
+			continue
+		}
+		matched := true
+		for _, expr := range term.MatchExpressions {
+			value, exists := nodeLabels[expr.Key]
+			switch expr.Operator {
+			case corev1.NodeSelectorOpIn:
+				matched = exists && slices.Contains(expr.Values, value)
+			case corev1.NodeSelectorOpNotIn:
+				matched = !exists || !slices.Contains(expr.Values, value)
+			case corev1.NodeSelectorOpExists:
+				matched = exists
+			case corev1.NodeSelectorOpDoesNotExist:
+				matched = !exists
+			default:
+				matched = false
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// toleratesTaint reports whether any of tolerations tolerates taint.
+func toleratesTaint(tolerations []corev1.Toleration, taint corev1.Taint) bool {
+	for _, toleration := range tolerations {
+		if toleration.Effect != "" && toleration.Effect != taint.Effect {
+			continue
+		}
+		if toleration.Key != "" && toleration.Key != taint.Key {
+			continue
+		}
+		switch toleration.Operator {
+		case corev1.TolerationOpExists:
+			return true
+		case corev1.TolerationOpEqual, "":
+			if toleration.Key != "" && toleration.Value == taint.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodesNeededForPods returns how many empty nodes of the given capacity are needed
+// to run pods, packing the largest pods first.
+func nodesNeededForPods(pods []*corev1.Pod, capacity podResources) int {
+	requests := make([]podResources, 0, len(pods))
+	for _, pod := range pods {
+		requests = append(requests, podRequests(pod))
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].cpu != requests[j].cpu {
+			return requests[i].cpu > requests[j].cpu
+		}
+		return requests[i].memory > requests[j].memory
+	})
+
+	var used []podResources
+	for _, request := range requests {
+		placed := false
+		for i := range used {
+			if next := used[i].add(request); next.fits(capacity) {
+				used[i] = next
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			used = append(used, request)
+		}
+	}
+	return len(used)
+}
+
+// nodeUtilization returns the share of node's allocatable CPU or memory, whichever
+// is higher, requested by its pods, in percent. DaemonSet and static pods are not
+// counted since every node runs them.
+func nodeUtilization(node *corev1.Node, pods []*corev1.Pod) int {
+	allocatable := resourcesFromList(node.Status.Allocatable)
+	if allocatable.cpu <= 0 || allocatable.memory <= 0 {
+		return 100
+	}
+
+	var requested podResources
+	for _, pod := range pods {
+		if isDaemonSetPod(pod) || isMirrorPod(pod) {
+			continue
+		}
+		requested = requested.add(podRequests(pod))
+	}
+
+	cpu := requested.cpu * 100 / allocatable.cpu
+	memory := requested.memory * 100 / allocatable.memory
+	return int(max(cpu, memory))
+}
+
+// podsCanMove reports whether every pod that would be evicted from a node is
+// managed by a controller that recreates it elsewhere.
+func podsCanMove(pods []*corev1.Pod) bool {
+	for _, pod := range pods {
+		if isDaemonSetPod(pod) || isMirrorPod(pod) {
+			continue
+		}
+		if metav1.GetControllerOf(pod) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// podRequests returns the resources a pod needs to be scheduled: the sum of its
+// containers' requests or the largest init container request, whichever is
+// higher, plus the pod overhead.
+func podRequests(pod *corev1.Pod) podResources {
+	var total podResources
+	for _, container := range pod.Spec.Containers {
+		total = total.add(resourcesFromList(container.Resources.Requests))
+	}
+	for _, container := range pod.Spec.InitContainers {
+		init := resourcesFromList(container.Resources.Requests)
+		total.cpu = max(total.cpu, init.cpu)
+		total.memory = max(total.memory, init.memory)
+	}
+	return total.add(resourcesFromList(pod.Spec.Overhead))
+}
+
+// resourcesFromList extracts CPU and memory from a resource list.
+func resourcesFromList(list corev1.ResourceList) podResources {
+	var res podResources
+	if cpu, ok := list[corev1.ResourceCPU]; ok {
+		res.cpu = cpu.MilliValue()
+	}
+	if memory, ok := list[corev1.ResourceMemory]; ok {
+		res.memory = memory.Value()
+	}
+	return res
+}
+
+// isDaemonSetPod reports whether pod is owned by a DaemonSet.
+func isDaemonSetPod(pod *corev1.Pod) bool {
+	owner := metav1.GetControllerOf(pod)
+	return owner != nil && owner.Kind == "DaemonSet"
+}
+
+// isMirrorPod reports whether pod is the API representation of a static pod.
+func isMirrorPod(pod *corev1.Pod) bool {
+	_, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]
+	return ok
+}
@@ -0,0 +1,396 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/util/labels"
+)
+
+func newAutoscalerPod(name, nodeName, cpu, owner string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				}},
+			}},
+		},
+	}
+	if owner != "" {
+		isController := true
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1", Kind: owner, Name: name + "-owner", UID: types.UID("uid-" + name), Controller: &isController,
+		}}
+	}
+	if nodeName == "" {
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:   corev1.PodScheduled,
+			Status: corev1.ConditionFalse,
+			Reason: corev1.PodReasonUnschedulable,
+		}}
+	}
+	return pod
+}
+
+func newAutoscalerNode(name, pool string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{labels.KeyPool: pool}},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func newAutoscalerReconciler(t *testing.T, objs ...client.Object) (*ClusterReconciler, *record.FakeRecorder) {
+	t.Helper()
+	scheme := setupTestScheme(t)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	recorder := record.NewFakeRecorder(20)
+	return NewClusterReconciler(fakeClient, scheme, recorder, WithMetrics(false)), recorder
+}
+
+func TestWorkerPoolDesiredCount(t *testing.T) {
+	t.Parallel()
+
+	autoscaled := k8znerv1alpha1.WorkerPoolSpec{
+		Name: "general", Count: 2,
+		Autoscaling: &k8znerv1alpha1.WorkerPoolAutoscalingSpec{MinCount: 1, MaxCount: 4},
+	}
+
+	t.Run("static pools use count", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{{Name: "general", Count: 3}})
+		cluster.Status.WorkerPools = []k8znerv1alpha1.WorkerPoolStatus{{Name: "general", Desired: 5}}
+		assert.Equal(t, 3, workerPoolDesiredCount(cluster, cluster.Spec.WorkerPools[0]))
+	})
+
+	t.Run("autoscaled pools start at count", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{autoscaled})
+		assert.Equal(t, 2, workerPoolDesiredCount(cluster, autoscaled))
+	})
+
+	t.Run("autoscaled pools follow the target within bounds", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{autoscaled})
+		cluster.Status.WorkerPools = []k8znerv1alpha1.WorkerPoolStatus{{Name: "general", Desired: 3}}
+		assert.Equal(t, 3, workerPoolDesiredCount(cluster, autoscaled))
+		assert.Equal(t, 3, desiredWorkerCount(cluster))
+
+		cluster.Status.WorkerPools[0].Desired = 9
+		assert.Equal(t, 4, workerPoolDesiredCount(cluster, autoscaled))
+
+		cluster.Status.WorkerPools[0].Desired = 0
+		assert.Equal(t, 1, workerPoolDesiredCount(cluster, autoscaled))
+	})
+}
+
+func TestPodFitsTemplate(t *testing.T) {
+	t.Parallel()
+
+	pool := k8znerv1alpha1.WorkerPoolSpec{
+		Name: "gpu", Size: "cx53",
+		Labels: map[string]string{"tier": "gpu"},
+		Taints: []corev1.Taint{{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}},
+	}
+	template := buildWorkerPoolTemplate(pool, nil, nil)
+	assert.Equal(t, podResources{cpu: 16000, memory: 32 << 30}, template.capacity)
+
+	tolerating := func(pod *corev1.Pod) *corev1.Pod {
+		pod.Spec.Tolerations = []corev1.Toleration{{Key: "gpu", Operator: corev1.TolerationOpEqual, Value: "true", Effect: corev1.TaintEffectNoSchedule}}
+		return pod
+	}
+
+	t.Run("requires the pool taints to be tolerated", func(t *testing.T) {
+		t.Parallel()
+		assert.False(t, podFitsTemplate(newAutoscalerPod("p", "", "1", ""), template))
+		assert.True(t, podFitsTemplate(tolerating(newAutoscalerPod("p", "", "1", "")), template))
+	})
+
+	t.Run("matches node selectors against pool labels", func(t *testing.T) {
+		t.Parallel()
+		pod := tolerating(newAutoscalerPod("p", "", "1", ""))
+		pod.Spec.NodeSelector = map[string]string{"tier": "gpu", labels.KeyPool: "gpu", corev1.LabelOSStable: "linux"}
+		assert.True(t, podFitsTemplate(pod, template))
+
+		pod.Spec.NodeSelector = map[string]string{"tier": "cpu"}
+		assert.False(t, podFitsTemplate(pod, template))
+	})
+
+	t.Run("evaluates required node affinity", func(t *testing.T) {
+		t.Parallel()
+		pod := tolerating(newAutoscalerPod("p", "", "1", ""))
+		pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "tier", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"gpu"}}},
+				}},
+			},
+		}}
+		assert.False(t, podFitsTemplate(pod, template))
+
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0].Operator = corev1.NodeSelectorOpIn
+		assert.True(t, podFitsTemplate(pod, template))
+	})
+
+	t.Run("rejects pods larger than a node", func(t *testing.T) {
+		t.Parallel()
+		assert.False(t, podFitsTemplate(tolerating(newAutoscalerPod("p", "", "17", "")), template))
+	})
+}
+
+func TestBuildWorkerPoolTemplate_FromExistingNode(t *testing.T) {
+	t.Parallel()
+
+	node := newAutoscalerNode("w-1", "general")
+	node.Labels[corev1.LabelHostname] = "w-1"
+	node.Labels[corev1.LabelTopologyZone] = "nbg1-dc3"
+	daemon := newAutoscalerPod("cilium", "w-1", "500m", "DaemonSet")
+	app := newAutoscalerPod("app", "w-1", "1", "ReplicaSet")
+
+	template := buildWorkerPoolTemplate(
+		k8znerv1alpha1.WorkerPoolSpec{Name: "general", Size: "cx23"},
+		map[string]*corev1.Node{"w-1": node},
+		map[string][]*corev1.Pod{"w-1": {daemon, app}},
+	)
+
+	assert.Equal(t, podResources{cpu: 1500, memory: 4<<30 - 256<<20}, template.capacity)
+	assert.Equal(t, "nbg1-dc3", template.labels[corev1.LabelTopologyZone])
+	assert.NotContains(t, template.labels, corev1.LabelHostname)
+}
+
+func TestNodesNeededForPods(t *testing.T) {
+	t.Parallel()
+
+	capacity := podResources{cpu: 2000, memory: 4 << 30}
+	pods := []*corev1.Pod{
+		newAutoscalerPod("a", "", "1500m", ""),
+		newAutoscalerPod("b", "", "500m", ""),
+		newAutoscalerPod("c", "", "1", ""),
+		newAutoscalerPod("d", "", "1", ""),
+	}
+
+	assert.Equal(t, 2, nodesNeededForPods(pods, capacity))
+	assert.Equal(t, 1, nodesNeededForPods(pods[1:2], capacity))
+}
+
+func TestNodeUtilization(t *testing.T) {
+	t.Parallel()
+
+	node := newAutoscalerNode("w-1", "general")
+
+	assert.Equal(t, 0, nodeUtilization(node, nil))
+	assert.Equal(t, 0, nodeUtilization(node, []*corev1.Pod{newAutoscalerPod("cilium", "w-1", "1", "DaemonSet")}))
+	assert.Equal(t, 75, nodeUtilization(node, []*corev1.Pod{newAutoscalerPod("app", "w-1", "1500m", "ReplicaSet")}))
+
+	assert.True(t, podsCanMove([]*corev1.Pod{newAutoscalerPod("app", "w-1", "1", "ReplicaSet")}))
+	assert.False(t, podsCanMove([]*corev1.Pod{newAutoscalerPod("bare", "w-1", "1", "")}))
+}
+
+func TestAutoscaleWorkerPools_ScaleUp(t *testing.T) {
+	t.Parallel()
+
+	newCluster := func(maxCount int) *k8znerv1alpha1.K8znerCluster {
+		return newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{{
+			Name: "general", Count: 1, Size: "cx23",
+			Autoscaling: &k8znerv1alpha1.WorkerPoolAutoscalingSpec{MinCount: 1, MaxCount: maxCount},
+		}},
+			k8znerv1alpha1.NodeStatus{Name: "w-1", Pool: "general", Healthy: true},
+		)
+	}
+
+	t.Run("raises the target to fit pending pods", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster(5)
+		r, recorder := newAutoscalerReconciler(t,
+			newAutoscalerNode("w-1", "general"),
+			newAutoscalerPod("p-1", "", "1500m", "ReplicaSet"),
+			newAutoscalerPod("p-2", "", "1500m", "ReplicaSet"),
+			newAutoscalerPod("p-3", "", "1500m", "ReplicaSet"),
+		)
+
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		require.Len(t, cluster.Status.WorkerPools, 1)
+		assert.Equal(t, 4, cluster.Status.WorkerPools[0].Desired)
+		assert.NotNil(t, cluster.Status.WorkerPools[0].LastScaleUpTime)
+		assert.Equal(t, 4, workerPoolDesiredCount(cluster, cluster.Spec.WorkerPools[0]))
+		assert.Equal(t, 4, cluster.Status.Workers.Desired)
+		assert.Contains(t, <-recorder.Events, EventReasonAutoscaleUp)
+	})
+
+	t.Run("stops at max count", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster(2)
+		r, _ := newAutoscalerReconciler(t,
+			newAutoscalerNode("w-1", "general"),
+			newAutoscalerPod("p-1", "", "1500m", "ReplicaSet"),
+			newAutoscalerPod("p-2", "", "1500m", "ReplicaSet"),
+		)
+
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		assert.Equal(t, 2, cluster.Status.WorkerPools[0].Desired)
+	})
+
+	t.Run("reports pools already at max count", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster(1)
+		r, recorder := newAutoscalerReconciler(t,
+			newAutoscalerNode("w-1", "general"),
+			newAutoscalerPod("p-1", "", "1500m", "ReplicaSet"),
+		)
+
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		assert.Empty(t, cluster.Status.WorkerPools)
+		assert.Contains(t, <-recorder.Events, EventReasonAutoscaleBlocked)
+	})
+
+	t.Run("waits for a pool that is still scaling", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster(5)
+		cluster.Status.WorkerPools = []k8znerv1alpha1.WorkerPoolStatus{{Name: "general", Desired: 2}}
+		r, _ := newAutoscalerReconciler(t,
+			newAutoscalerNode("w-1", "general"),
+			newAutoscalerPod("p-1", "", "1500m", "ReplicaSet"),
+		)
+
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		assert.Equal(t, 2, cluster.Status.WorkerPools[0].Desired)
+	})
+
+	t.Run("ignores pods that fit no pool", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster(5)
+		r, _ := newAutoscalerReconciler(t,
+			newAutoscalerNode("w-1", "general"),
+			newAutoscalerPod("huge", "", "64", "ReplicaSet"),
+		)
+
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		assert.Empty(t, cluster.Status.WorkerPools)
+	})
+}
+
+func TestAutoscaleWorkerPools_ScaleDown(t *testing.T) {
+	t.Parallel()
+
+	newCluster := func(minCount int) *k8znerv1alpha1.K8znerCluster {
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{{
+			Name: "general", Count: 2, Size: "cx23",
+			Autoscaling: &k8znerv1alpha1.WorkerPoolAutoscalingSpec{MinCount: minCount, MaxCount: 5, ScaleDownDelay: "10m"},
+		}},
+			k8znerv1alpha1.NodeStatus{Name: "w-1", Pool: "general", Healthy: true},
+			k8znerv1alpha1.NodeStatus{Name: "w-2", Pool: "general", Healthy: true},
+		)
+		cluster.Status.WorkerPools = []k8znerv1alpha1.WorkerPoolStatus{{Name: "general", Desired: 2}}
+		return cluster
+	}
+	objects := func(extra ...client.Object) []client.Object {
+		return append([]client.Object{
+			newAutoscalerNode("w-1", "general"),
+			newAutoscalerNode("w-2", "general"),
+			newAutoscalerPod("busy", "w-2", "1500m", "ReplicaSet"),
+		}, extra...)
+	}
+
+	t.Run("removes a node once underutilized past the delay", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster(1)
+		r, recorder := newAutoscalerReconciler(t, objects()...)
+
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		require.NotNil(t, cluster.Status.Workers.Nodes[0].UnderutilizedSince)
+		assert.Nil(t, cluster.Status.Workers.Nodes[1].UnderutilizedSince)
+		assert.Equal(t, 2, cluster.Status.WorkerPools[0].Desired)
+
+		since := metav1.NewTime(time.Now().Add(-11 * time.Minute))
+		cluster.Status.Workers.Nodes[0].UnderutilizedSince = &since
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		assert.Equal(t, 1, cluster.Status.WorkerPools[0].Desired)
+		assert.NotNil(t, cluster.Status.WorkerPools[0].LastScaleDownTime)
+		assert.Contains(t, <-recorder.Events, EventReasonAutoscaleDown)
+
+		selected := r.selectWorkersForRemoval(cluster, cluster.Spec.WorkerPools[0], 1)
+		require.Len(t, selected, 1)
+		assert.Equal(t, "w-1", selected[0].Name)
+	})
+
+	t.Run("keeps min count", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster(2)
+		since := metav1.NewTime(time.Now().Add(-time.Hour))
+		cluster.Status.Workers.Nodes[0].UnderutilizedSince = &since
+		r, _ := newAutoscalerReconciler(t, objects()...)
+
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		assert.Equal(t, 2, cluster.Status.WorkerPools[0].Desired)
+	})
+
+	t.Run("waits after a recent scale-up", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster(1)
+		since := metav1.NewTime(time.Now().Add(-time.Hour))
+		scaledUp := metav1.NewTime(time.Now().Add(-time.Minute))
+		cluster.Status.Workers.Nodes[0].UnderutilizedSince = &since
+		cluster.Status.WorkerPools[0].LastScaleUpTime = &scaledUp
+		r, _ := newAutoscalerReconciler(t, objects()...)
+
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		assert.Equal(t, 2, cluster.Status.WorkerPools[0].Desired)
+	})
+
+	t.Run("keeps nodes running pods without a controller", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster(1)
+		since := metav1.NewTime(time.Now().Add(-time.Hour))
+		cluster.Status.Workers.Nodes[0].UnderutilizedSince = &since
+		r, _ := newAutoscalerReconciler(t, objects(newAutoscalerPod("bare", "w-1", "100m", ""))...)
+
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		assert.Nil(t, cluster.Status.Workers.Nodes[0].UnderutilizedSince)
+		assert.Equal(t, 2, cluster.Status.WorkerPools[0].Desired)
+	})
+
+	t.Run("pending pods reset underutilization", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster(1)
+		since := metav1.NewTime(time.Now().Add(-time.Hour))
+		cluster.Status.Workers.Nodes[0].UnderutilizedSince = &since
+		r, _ := newAutoscalerReconciler(t, objects(newAutoscalerPod("pending", "", "1500m", "ReplicaSet"))...)
+
+		r.autoscaleWorkerPools(context.Background(), cluster)
+
+		assert.Nil(t, cluster.Status.Workers.Nodes[0].UnderutilizedSince)
+		assert.Equal(t, 3, cluster.Status.WorkerPools[0].Desired)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -22,8 +23,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/backup"
+	"github.com/milankappen/k8zner/internal/config"
 	operatorprov "github.com/milankappen/k8zner/internal/operator/provisioning"
 	"github.com/milankappen/k8zner/internal/platform/hcloud"
+	"github.com/milankappen/k8zner/internal/provisioning"
+	"github.com/milankappen/k8zner/internal/provisioning/destroy"
 )
 
 const (
@@ -48,6 +53,15 @@ const (
 	ciliumReadyTimeout  = 5 * time.Minute
 	ciliumCheckInterval = 10 * time.Second
 
+	// Maximum time for a node to come back after a Talos upgrade reboot.
+	talosUpgradeTimeout = 10 * time.Minute
+
+	// Maximum time for a node's kubelet to report the new version after a Kubernetes upgrade.
+	kubeletUpgradeTimeout = 5 * time.Minute
+
+	// Maximum time for a node to report a newly applied machine config (including a reboot).
+	machineConfigApplyTimeout = 10 * time.Minute
+
 	// Kubeconfig retrieval timeout.
 	kubeconfigTimeout = 2 * time.Minute
 
@@ -57,18 +71,45 @@ const (
 	serverIPRetryDelay  = 5 * time.Second
 
 	// Event reasons.
-	EventReasonReconciling         = "Reconciling"
-	EventReasonReconcileSucceeded  = "ReconcileSucceeded"
-	EventReasonReconcileFailed     = "ReconcileFailed"
-	EventReasonNodeUnhealthy       = "NodeUnhealthy"
-	EventReasonNodeReplacing       = "NodeReplacing"
-	EventReasonNodeReplaced        = "NodeReplaced"
-	EventReasonQuorumLost          = "QuorumLost"
-	EventReasonScalingUp           = "ScalingUp"
-	EventReasonScalingDown         = "ScalingDown"
-	EventReasonServerCreationError = "ServerCreationError"
-	EventReasonConfigApplyError    = "ConfigApplyError"
-	EventReasonNodeReadyTimeout    = "NodeReadyTimeout"
+	EventReasonReconciling                = "Reconciling"
+	EventReasonReconcileSucceeded         = "ReconcileSucceeded"
+	EventReasonReconcileFailed            = "ReconcileFailed"
+	EventReasonNodeUnhealthy              = "NodeUnhealthy"
+	EventReasonNodeReplacing              = "NodeReplacing"
+	EventReasonNodeReplaced               = "NodeReplaced"
+	EventReasonQuorumLost                 = "QuorumLost"
+	EventReasonScalingUp                  = "ScalingUp"
+	EventReasonScalingDown                = "ScalingDown"
+	EventReasonServerCreationError        = "ServerCreationError"
+	EventReasonConfigApplyError           = "ConfigApplyError"
+	EventReasonNodeReadyTimeout           = "NodeReadyTimeout"
+	EventReasonTalosUpgrading             = "TalosUpgrading"
+	EventReasonTalosUpgraded              = "TalosUpgraded"
+	EventReasonTalosUpgradeFailed         = "TalosUpgradeFailed"
+	EventReasonK8sUpgrading               = "KubernetesUpgrading"
+	EventReasonK8sUpgraded                = "KubernetesUpgraded"
+	EventReasonK8sUpgradeFailed           = "KubernetesUpgradeFailed"
+	EventReasonK8sUpgradeBlocked          = "KubernetesUpgradeBlocked"
+	EventReasonAutoscaleUp                = "AutoscaleUp"
+	EventReasonAutoscaleDown              = "AutoscaleDown"
+	EventReasonAutoscaleBlocked           = "AutoscaleBlocked"
+	EventReasonConfigDrift                = "MachineConfigDrift"
+	EventReasonConfigApplied              = "MachineConfigApplied"
+	EventReasonConfigSynced               = "MachineConfigSynced"
+	EventReasonMaintenanceDeferred        = "MaintenanceDeferred"
+	EventReasonMaintenanceInvalid         = "MaintenanceWindowInvalid"
+	EventReasonBackupsPruned              = "BackupsPruned"
+	EventReasonBackupPruneFailed          = "BackupPruneFailed"
+	EventReasonBackupVerified             = "BackupVerified"
+	EventReasonBackupVerifyFailed         = "BackupVerificationFailed"
+	EventReasonPreOperationSnapshot       = "PreOperationSnapshot"
+	EventReasonPreOperationSnapshotFailed = "PreOperationSnapshotFailed"
+	EventReasonTeardownStarted            = "TeardownStarted"
+	EventReasonTeardownComplete           = "TeardownComplete"
+	EventReasonTeardownFailed             = "TeardownFailed"
+	EventReasonCARotating                 = "CARotating"
+	EventReasonCARotated                  = "CARotated"
+	EventReasonCARotationFailed           = "CARotationFailed"
 
 	// Provisioning event reasons.
 	EventReasonProvisioningPhase     = "ProvisioningPhase"
@@ -110,6 +151,30 @@ type ClusterReconciler struct {
 	// Defaults to waitForK8sNodeReady. Can be overridden in tests.
 	nodeReadyWaiter func(ctx context.Context, nodeName string, timeout time.Duration) error
 
+	// kubeletVersionWaiter is called to wait for a node to report a new kubelet version.
+	// Defaults to waitForKubeletVersion. Can be overridden in tests.
+	kubeletVersionWaiter func(ctx context.Context, nodeName, version string, timeout time.Duration) error
+
+	// configHashWaiter is called to wait for a node to report a newly applied machine config.
+	// Defaults to waitForConfigHash. Can be overridden in tests.
+	configHashWaiter func(ctx context.Context, nodeName, hash string, timeout time.Duration) error
+
+	// deprecatedAPIChecker lists deprecated APIs still in use that the target
+	// Kubernetes version removes. Set from the manager config in SetupWithManager.
+	deprecatedAPIChecker deprecatedAPICheckFunc
+
+	// teardown deletes a cluster's cloud resources when the object is deleted
+	// with deletionPolicy Delete. Defaults to destroy.Destroy. Can be overridden in tests.
+	teardown func(pCtx *provisioning.Context) error
+
+	// backupRepository opens the backup bucket for retention pruning.
+	// Defaults to backup.NewRepositoryFromConfig. Can be overridden in tests.
+	backupRepository func(cfg config.TalosBackupConfig) (*backup.Repository, error)
+
+	// backupVerifyImage is the operator image run by backup verification Jobs.
+	// Verification stays unavailable while it is empty.
+	backupVerifyImage string
+
 	// Provisioning adapter for operator-driven provisioning.
 	phaseAdapter *operatorprov.PhaseAdapter
 
@@ -183,6 +248,54 @@ func WithNodeReadyWaiter(waiter func(ctx context.Context, nodeName string, timeo
 	}
 }
 
+// WithKubeletVersionWaiter sets a custom function for waiting for a node to report a kubelet version.
+// This is primarily used for testing to avoid waiting for actual Kubernetes nodes.
+func WithKubeletVersionWaiter(waiter func(ctx context.Context, nodeName, version string, timeout time.Duration) error) Option {
+	return func(r *ClusterReconciler) {
+		r.kubeletVersionWaiter = waiter
+	}
+}
+
+// WithConfigHashWaiter sets a custom function for waiting for a node to report a machine config hash.
+// This is primarily used for testing to avoid waiting for actual Kubernetes nodes.
+func WithConfigHashWaiter(waiter func(ctx context.Context, nodeName, hash string, timeout time.Duration) error) Option {
+	return func(r *ClusterReconciler) {
+		r.configHashWaiter = waiter
+	}
+}
+
+// WithDeprecatedAPIChecker sets the function used to detect deprecated API usage
+// before a Kubernetes upgrade.
+func WithDeprecatedAPIChecker(checker func(ctx context.Context, targetVersion string) ([]string, error)) Option {
+	return func(r *ClusterReconciler) {
+		r.deprecatedAPIChecker = checker
+	}
+}
+
+// WithTeardown sets the function used to delete a cluster's cloud resources
+// when the K8znerCluster is deleted with deletionPolicy Delete.
+func WithTeardown(teardown func(pCtx *provisioning.Context) error) Option {
+	return func(r *ClusterReconciler) {
+		r.teardown = teardown
+	}
+}
+
+// WithBackupRepository sets the function used to open the backup bucket when
+// pruning snapshots past their retention.
+func WithBackupRepository(open func(cfg config.TalosBackupConfig) (*backup.Repository, error)) Option {
+	return func(r *ClusterReconciler) {
+		r.backupRepository = open
+	}
+}
+
+// WithBackupVerifyImage sets the image backup verification Jobs run, normally
+// the operator's own image.
+func WithBackupVerifyImage(image string) Option {
+	return func(r *ClusterReconciler) {
+		r.backupVerifyImage = image
+	}
+}
+
 // NewClusterReconciler creates a new ClusterReconciler with the given options.
 func NewClusterReconciler(c client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, opts ...Option) *ClusterReconciler {
 	r := &ClusterReconciler{
@@ -202,6 +315,18 @@ func NewClusterReconciler(c client.Client, scheme *runtime.Scheme, recorder reco
 	if r.nodeReadyWaiter == nil {
 		r.nodeReadyWaiter = r.waitForK8sNodeReady
 	}
+	if r.kubeletVersionWaiter == nil {
+		r.kubeletVersionWaiter = r.waitForKubeletVersion
+	}
+	if r.configHashWaiter == nil {
+		r.configHashWaiter = r.waitForConfigHash
+	}
+	if r.teardown == nil {
+		r.teardown = destroy.Destroy
+	}
+	if r.backupRepository == nil {
+		r.backupRepository = backup.NewRepositoryFromConfig
+	}
 
 	return r
 }
@@ -224,13 +349,15 @@ func (r *ClusterReconciler) ensureHCloudClient() error {
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
-// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update
 // +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets;statefulsets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apiregistration.k8s.io,resources=apiservices,verbs=get;list;watch
+// +kubebuilder:rbac:urls=/metrics,verbs=get
 
 // Reconcile handles the reconciliation loop for K8znerCluster resources.
 func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -253,12 +380,23 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
-	// Check if paused
+	// Check if paused. A paused cluster is not torn down either.
 	if cluster.Spec.Paused {
 		logger.Info("cluster is paused, skipping reconciliation")
 		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
 	}
 
+	// Tear down or release a cluster that is being deleted
+	if !cluster.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, cluster)
+	}
+
+	// Keep the teardown finalizer in line with the deletion policy
+	if err := r.reconcileFinalizer(ctx, cluster); err != nil {
+		logger.Error(err, "failed to reconcile finalizer")
+		return ctrl.Result{}, err
+	}
+
 	// Ensure HCloud client is initialized
 	if err := r.ensureHCloudClient(); err != nil {
 		logger.Error(err, "failed to initialize HCloud client")
@@ -345,7 +483,7 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *k8znerv1alph
 	// Always keep status.Desired in sync with spec counts
 	// This ensures status reflects the desired state for both legacy and state-machine modes
 	cluster.Status.ControlPlanes.Desired = cluster.Spec.ControlPlanes.Count
-	cluster.Status.Workers.Desired = cluster.Spec.Workers.Count
+	cluster.Status.Workers.Desired = desiredWorkerCount(cluster)
 
 	// Step 1: Check for stuck nodes and clean them up
 	stuckNodes := r.checkStuckNodes(ctx, cluster)
@@ -558,6 +696,14 @@ func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return fmt.Errorf("failed to create pod node name index: %w", err)
 	}
 
+	if r.deprecatedAPIChecker == nil {
+		clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			return fmt.Errorf("failed to create clientset for API server metrics: %w", err)
+		}
+		r.deprecatedAPIChecker = newMetricsDeprecatedAPIChecker(clientset.Discovery().RESTClient())
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&k8znerv1alpha1.K8znerCluster{}).
 		Watches(&corev1.Node{}, &nodeEventHandler{}).
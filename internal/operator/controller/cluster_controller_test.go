@@ -11,6 +11,7 @@ import (
 	hcloudgo "github.com/hetznercloud/hcloud-go/v2/hcloud"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -18,6 +19,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -29,10 +31,34 @@ import (
 func setupTestScheme(t *testing.T) *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, batchv1.AddToScheme(scheme))
 	require.NoError(t, k8znerv1alpha1.AddToScheme(scheme))
 	return scheme
 }
 
+// newTestReconciler builds a reconciler over a fake client holding objs, with
+// mock HCloud and Talos clients, waiters that return at once and metrics off.
+// opts are applied after these defaults and override them.
+func newTestReconciler(t *testing.T, objs []client.Object, opts ...Option) *ClusterReconciler {
+	t.Helper()
+	scheme := setupTestScheme(t)
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&k8znerv1alpha1.K8znerCluster{}).
+		Build()
+	opts = append([]Option{
+		WithHCloudClient(&MockHCloudClient{}),
+		WithTalosClient(&MockTalosClient{}),
+		WithTalosConfigGenerator(&MockTalosConfigGenerator{}),
+		WithNodeReadyWaiter(func(_ context.Context, _ string, _ time.Duration) error { return nil }),
+		WithKubeletVersionWaiter(func(_ context.Context, _, _ string, _ time.Duration) error { return nil }),
+		WithConfigHashWaiter(func(_ context.Context, _, _ string, _ time.Duration) error { return nil }),
+		WithMetrics(false),
+	}, opts...)
+	return NewClusterReconciler(c, scheme, record.NewFakeRecorder(100), opts...)
+}
+
 // createTestNode is a helper function to create test nodes.
 func createTestNode(name string, isControlPlane, isReady bool) *corev1.Node {
 	labels := map[string]string{}
@@ -12,6 +12,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/platform/talos"
 	"github.com/milankappen/k8zner/internal/util/naming"
 )
 
@@ -215,3 +216,66 @@ func (r *ClusterReconciler) waitForK8sNodeReady(ctx context.Context, nodeName st
 		}
 	}
 }
+
+// waitForKubeletVersion waits until a node is Ready and its kubelet reports the given version.
+func (r *ClusterReconciler) waitForKubeletVersion(ctx context.Context, nodeName, version string, timeout time.Duration) error {
+	logger := log.FromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(k8sNodeReadyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for node %s to run kubelet %s", nodeName, version)
+		case <-ticker.C:
+			node := &corev1.Node{}
+			if err := r.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+				logger.V(1).Info("error getting node", "node", nodeName, "error", err)
+				continue
+			}
+
+			current := strings.TrimPrefix(node.Status.NodeInfo.KubeletVersion, "v")
+			if current == version && isNodeReady(node) {
+				logger.Info("node runs the new kubelet version", "node", nodeName, "version", version)
+				return nil
+			}
+			logger.V(1).Info("waiting for kubelet version", "node", nodeName, "current", current, "desired", version)
+		}
+	}
+}
+
+// waitForConfigHash waits until a node is Ready and reports the given machine config hash.
+func (r *ClusterReconciler) waitForConfigHash(ctx context.Context, nodeName, hash string, timeout time.Duration) error {
+	logger := log.FromContext(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(k8sNodeReadyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for node %s to report machine config %s", nodeName, shortConfigHash(hash))
+		case <-ticker.C:
+			node := &corev1.Node{}
+			if err := r.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+				logger.V(1).Info("error getting node", "node", nodeName, "error", err)
+				continue
+			}
+
+			current := node.Annotations[talos.ConfigHashAnnotation]
+			if current == hash && isNodeReady(node) {
+				logger.Info("node runs the new machine config", "node", nodeName, "hash", shortConfigHash(hash))
+				return nil
+			}
+			logger.V(1).Info("waiting for machine config", "node", nodeName,
+				"current", shortConfigHash(current), "desired", shortConfigHash(hash))
+		}
+	}
+}
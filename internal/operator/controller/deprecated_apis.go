@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/rest"
+)
+
+// deprecatedAPIsMetric is the API server gauge set for every deprecated API
+// group/version/resource that has been requested since the API server started.
+const deprecatedAPIsMetric = "apiserver_requested_deprecated_apis"
+
+// metricLabelPattern matches a single name="value" pair in a Prometheus sample.
+var metricLabelPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// deprecatedAPICheckFunc returns the deprecated APIs still in use that are
+// removed in or before the target Kubernetes version.
+type deprecatedAPICheckFunc func(ctx context.Context, targetVersion string) ([]string, error)
+
+// newMetricsDeprecatedAPIChecker checks deprecated API usage by scraping the API server's /metrics endpoint.
+func newMetricsDeprecatedAPIChecker(restClient rest.Interface) deprecatedAPICheckFunc {
+	return func(ctx context.Context, targetVersion string) ([]string, error) {
+		body, err := restClient.Get().AbsPath("/metrics").DoRaw(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API server metrics: %w", err)
+		}
+		return removedDeprecatedAPIs(string(body), targetVersion)
+	}
+}
+
+// removedDeprecatedAPIs parses Prometheus text output and returns the requested
+// deprecated APIs whose removed_release is at or below the target version,
+// formatted as "group/version resource" and sorted.
+func removedDeprecatedAPIs(metrics, targetVersion string) ([]string, error) {
+	target, err := utilversion.ParseGeneric(targetVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target version %q: %w", targetVersion, err)
+	}
+
+	seen := make(map[string]bool)
+	var apis []string
+	for _, line := range strings.Split(metrics, "\n") {
+		if !strings.HasPrefix(line, deprecatedAPIsMetric+"{") {
+			continue
+		}
+		end := strings.LastIndex(line, "}")
+		if end < 0 {
+			continue
+		}
+
+		labels := make(map[string]string)
+		for _, match := range metricLabelPattern.FindAllStringSubmatch(line[len(deprecatedAPIsMetric)+1:end], -1) {
+			labels[match[1]] = match[2]
+		}
+
+		removedIn, err := utilversion.ParseGeneric(labels["removed_release"])
+		if err != nil || target.LessThan(removedIn) {
+			continue
+		}
+
+		groupVersion := labels["version"]
+		if labels["group"] != "" {
+			groupVersion = labels["group"] + "/" + groupVersion
+		}
+		api := groupVersion + " " + labels["resource"]
+		if !seen[api] {
+			seen[api] = true
+			apis = append(apis, api)
+		}
+	}
+
+	sort.Strings(apis)
+	return apis, nil
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemovedDeprecatedAPIs(t *testing.T) {
+	t.Parallel()
+
+	metrics := `# HELP apiserver_requested_deprecated_apis [STABLE] Gauge of deprecated APIs that have been requested, broken out by API group, version, resource, subresource, and removed_release.
+# TYPE apiserver_requested_deprecated_apis gauge
+apiserver_requested_deprecated_apis{group="flowcontrol.apiserver.k8s.io",removed_release="1.32",resource="flowschemas",subresource="",version="v1beta3"} 1
+apiserver_requested_deprecated_apis{group="flowcontrol.apiserver.k8s.io",removed_release="1.32",resource="flowschemas",subresource="status",version="v1beta3"} 1
+apiserver_requested_deprecated_apis{group="",removed_release="1.40",resource="widgets",subresource="",version="v1"} 1
+apiserver_requested_deprecated_apis{group="extensions",removed_release="",resource="ingresses",subresource="",version="v1beta1"} 1
+apiserver_request_total{code="200",resource="pods",verb="GET",version="v1"} 42
+`
+
+	t.Run("reports APIs removed at or before target", func(t *testing.T) {
+		t.Parallel()
+		apis, err := removedDeprecatedAPIs(metrics, "1.32.2")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"flowcontrol.apiserver.k8s.io/v1beta3 flowschemas"}, apis)
+	})
+
+	t.Run("ignores APIs removed after target", func(t *testing.T) {
+		t.Parallel()
+		apis, err := removedDeprecatedAPIs(metrics, "1.31.4")
+		require.NoError(t, err)
+		assert.Empty(t, apis)
+	})
+
+	t.Run("core group has no prefix", func(t *testing.T) {
+		t.Parallel()
+		apis, err := removedDeprecatedAPIs(metrics, "1.40.0")
+		require.NoError(t, err)
+		assert.Contains(t, apis, "v1 widgets")
+	})
+
+	t.Run("invalid target version", func(t *testing.T) {
+		t.Parallel()
+		_, err := removedDeprecatedAPIs(metrics, "latest")
+		require.Error(t, err)
+	})
+}
@@ -3,10 +3,13 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	hcloudgo "github.com/hetznercloud/hcloud-go/v2/hcloud"
 
+	"github.com/milankappen/k8zner/internal/config"
 	"github.com/milankappen/k8zner/internal/platform/hcloud"
+	"github.com/milankappen/k8zner/internal/provisioning"
 )
 
 // hcloudClient defines the interface for Hetzner Cloud operations.
@@ -43,14 +46,30 @@ type talosConfigGenerator interface {
 	// GenerateControlPlaneConfig generates a Talos config for a control plane node.
 	GenerateControlPlaneConfig(sans []string, hostname string, serverID int64) ([]byte, error)
 
-	// GenerateWorkerConfig generates a Talos config for a worker node.
-	GenerateWorkerConfig(hostname string, serverID int64) ([]byte, error)
+	// GenerateWorkerConfig generates a Talos config for a worker node of pool.
+	GenerateWorkerConfig(hostname string, serverID int64, pool *config.WorkerNodePool) ([]byte, error)
 
 	// SetEndpoint updates the control plane endpoint.
 	SetEndpoint(endpoint string)
 
 	// GetClientConfig returns the Talos client configuration for cluster access.
 	GetClientConfig() ([]byte, error)
+
+	// GetNodeVersion returns the Talos version running on a node.
+	GetNodeVersion(ctx context.Context, endpoint string) (string, error)
+
+	// UpgradeNode upgrades a node to the given installer image.
+	UpgradeNode(ctx context.Context, endpoint, imageURL string, opts provisioning.UpgradeOptions) error
+
+	// WaitForNodeReady waits for the Talos API of a node to respond after a reboot.
+	WaitForNodeReady(ctx context.Context, endpoint string, timeout time.Duration) error
+
+	// UpgradeKubernetes patches a node's machine config to run the given Kubernetes version.
+	UpgradeKubernetes(ctx context.Context, endpoint, targetVersion string) error
+
+	// ApplyMachineConfig applies a machine config to a running node and returns the
+	// apply mode Talos actually used.
+	ApplyMachineConfig(ctx context.Context, endpoint string, data []byte, mode string) (string, error)
 }
 
 // talosClient defines the interface for Talos API operations.
@@ -67,6 +86,12 @@ type talosClient interface {
 	// RemoveEtcdMember removes a member from the etcd cluster.
 	RemoveEtcdMember(ctx context.Context, nodeIP string, memberID string) error
 
+	// EtcdSnapshot streams an etcd snapshot from a control plane node.
+	EtcdSnapshot(ctx context.Context, nodeIP string) ([]byte, error)
+
+	// Kubeconfig issues an admin kubeconfig from a control plane node.
+	Kubeconfig(ctx context.Context, nodeIP string) ([]byte, error)
+
 	// WaitForNodeReady waits for a node to become ready.
 	WaitForNodeReady(ctx context.Context, nodeIP string, timeout int) error
 }
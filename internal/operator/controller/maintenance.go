@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/util/cron"
+)
+
+// Disruptive actions that wait for a maintenance window.
+const (
+	maintenanceActionNodeReplacement   = "NodeReplacement"
+	maintenanceActionScaleDown         = "ScaleDown"
+	maintenanceActionServerTypeRollout = "ServerTypeRollout"
+	maintenanceActionTalosUpgrade      = "TalosUpgrade"
+	maintenanceActionKubernetesUpgrade = "KubernetesUpgrade"
+	maintenanceActionMachineConfig     = "MachineConfigApply"
+)
+
+// startMaintenancePass evaluates the maintenance windows at now and empties the
+// queue of deferred actions; the gated steps of this reconcile fill it again.
+// The previous queue is returned for finishMaintenancePass.
+func (r *ClusterReconciler) startMaintenancePass(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, now time.Time) []k8znerv1alpha1.PendingAction {
+	spec := cluster.Spec.Maintenance
+	if spec == nil || len(spec.Windows) == 0 {
+		cluster.Status.Maintenance = nil
+		return nil
+	}
+
+	status := cluster.Status.Maintenance
+	if status == nil {
+		status = &k8znerv1alpha1.MaintenanceStatus{}
+		cluster.Status.Maintenance = status
+	}
+	previous := status.Pending
+	status.Pending = nil
+
+	inWindow, next, err := maintenanceWindowState(spec, now)
+	if err != nil {
+		// A broken schedule must not let disruptive actions through
+		log.FromContext(ctx).Error(err, "invalid maintenance windows, deferring disruptive actions")
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonMaintenanceInvalid,
+			"Invalid maintenance windows, deferring disruptive actions: %v", err)
+	}
+
+	status.InWindow = inWindow
+	status.NextWindow = nil
+	if !next.IsZero() {
+		nextWindow := metav1.NewTime(next)
+		status.NextWindow = &nextWindow
+	}
+
+	return previous
+}
+
+// finishMaintenancePass keeps the original queue time of actions that are still
+// deferred and announces newly deferred ones. When the reconcile stopped before
+// reaching every gated step, queued actions it did not evaluate stay queued.
+func (r *ClusterReconciler) finishMaintenancePass(cluster *k8znerv1alpha1.K8znerCluster, previous []k8znerv1alpha1.PendingAction, complete bool) {
+	status := cluster.Status.Maintenance
+	if status == nil {
+		return
+	}
+
+	for i := range status.Pending {
+		action := &status.Pending[i]
+		if prev := findPendingAction(previous, action.Action, action.Target); prev != nil {
+			action.QueuedAt = prev.QueuedAt
+			continue
+		}
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonMaintenanceDeferred,
+			"Deferred %s until the next maintenance window: %s", describePendingAction(*action), action.Reason)
+	}
+
+	if complete || status.InWindow {
+		return
+	}
+	for _, prev := range previous {
+		if findPendingAction(status.Pending, prev.Action, prev.Target) == nil {
+			status.Pending = append(status.Pending, prev)
+		}
+	}
+}
+
+// disruptionAllowed reports whether a disruptive action may run now. Outside a
+// maintenance window the action is queued in status with its reason instead.
+func (r *ClusterReconciler) disruptionAllowed(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, action, target, reason string) bool {
+	status := cluster.Status.Maintenance
+	if status == nil || status.InWindow {
+		return true
+	}
+
+	queuePendingAction(status, action, target, reason)
+	log.FromContext(ctx).Info("deferring disruptive action until the next maintenance window",
+		"action", action,
+		"target", target,
+		"reason", reason,
+		"nextWindow", status.NextWindow,
+	)
+	return false
+}
+
+// healingAllowed reports whether an unhealthy node may be replaced now. Emergency
+// healing, when allowed, bypasses the maintenance windows.
+func (r *ClusterReconciler) healingAllowed(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, node *k8znerv1alpha1.NodeStatus) bool {
+	if m := cluster.Spec.Maintenance; m != nil && m.AllowEmergencyHealing {
+		return true
+	}
+	return r.disruptionAllowed(ctx, cluster, maintenanceActionNodeReplacement, node.Name,
+		fmt.Sprintf("node unhealthy: %s", node.UnhealthyReason))
+}
+
+// maintenanceWindowState reports whether a maintenance window is open at now and
+// when the next one opens. Schedules are evaluated in the spec's time zone.
+func maintenanceWindowState(spec *k8znerv1alpha1.MaintenanceSpec, now time.Time) (bool, time.Time, error) {
+	loc := time.UTC
+	if spec.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(spec.Timezone); err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid timezone %q: %w", spec.Timezone, err)
+		}
+	}
+	now = now.In(loc)
+
+	inWindow := false
+	var next time.Time
+	for i, window := range spec.Windows {
+		schedule, duration, err := parseMaintenanceWindow(window)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("window %d: %w", i, err)
+		}
+
+		// The window is open if it started within the last duration
+		if start := schedule.Next(now.Add(-duration)); !start.IsZero() && !start.After(now) {
+			inWindow = true
+		}
+		if start := schedule.Next(now); !start.IsZero() && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+
+	return inWindow, next, nil
+}
+
+// parseMaintenanceWindow parses a window's cron schedule and duration.
+func parseMaintenanceWindow(window k8znerv1alpha1.MaintenanceWindow) (*cron.Schedule, time.Duration, error) {
+	schedule, err := cron.Parse(window.Schedule)
+	if err != nil {
+		return nil, 0, err
+	}
+	duration, err := time.ParseDuration(window.Duration)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid duration %q: %w", window.Duration, err)
+	}
+	if duration <= 0 {
+		return nil, 0, fmt.Errorf("duration %q must be positive", window.Duration)
+	}
+	return schedule, duration, nil
+}
+
+// queuePendingAction records a deferred action, updating the reason if it is already queued.
+func queuePendingAction(status *k8znerv1alpha1.MaintenanceStatus, action, target, reason string) {
+	if existing := findPendingAction(status.Pending, action, target); existing != nil {
+		existing.Reason = reason
+		return
+	}
+	status.Pending = append(status.Pending, k8znerv1alpha1.PendingAction{
+		Action:   action,
+		Target:   target,
+		Reason:   reason,
+		QueuedAt: metav1.Now(),
+	})
+}
+
+// findPendingAction returns the queued action for action and target, or nil.
+func findPendingAction(pending []k8znerv1alpha1.PendingAction, action, target string) *k8znerv1alpha1.PendingAction {
+	for i := range pending {
+		if pending[i].Action == action && pending[i].Target == target {
+			return &pending[i]
+		}
+	}
+	return nil
+}
+
+// describePendingAction formats an action and its target for events.
+func describePendingAction(action k8znerv1alpha1.PendingAction) string {
+	if action.Target == "" {
+		return action.Action
+	}
+	return fmt.Sprintf("%s of %s", action.Action, action.Target)
+}
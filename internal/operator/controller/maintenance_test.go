@@ -0,0 +1,419 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+)
+
+// nightlyMaintenance opens a four hour window every night at 02:00.
+func nightlyMaintenance() *k8znerv1alpha1.MaintenanceSpec {
+	return &k8znerv1alpha1.MaintenanceSpec{
+		Windows: []k8znerv1alpha1.MaintenanceWindow{{Schedule: "0 2 * * *", Duration: "4h"}},
+	}
+}
+
+// outsideMaintenanceWindow marks the cluster as having maintenance windows that are closed.
+func outsideMaintenanceWindow(cluster *k8znerv1alpha1.K8znerCluster) {
+	cluster.Spec.Maintenance = nightlyMaintenance()
+	cluster.Status.Maintenance = &k8znerv1alpha1.MaintenanceStatus{InWindow: false}
+}
+
+func TestMaintenanceWindowState(t *testing.T) {
+	t.Parallel()
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		spec     *k8znerv1alpha1.MaintenanceSpec
+		now      time.Time
+		inWindow bool
+		next     time.Time
+	}{
+		{
+			name:     "inside the window",
+			spec:     nightlyMaintenance(),
+			now:      at(4, 3, 0),
+			inWindow: true,
+			next:     at(5, 2, 0),
+		},
+		{
+			name:     "window opens exactly now",
+			spec:     nightlyMaintenance(),
+			now:      at(4, 2, 0),
+			inWindow: true,
+			next:     at(5, 2, 0),
+		},
+		{
+			name:     "window closes exactly now",
+			spec:     nightlyMaintenance(),
+			now:      at(4, 6, 0),
+			inWindow: false,
+			next:     at(5, 2, 0),
+		},
+		{
+			name:     "before the window",
+			spec:     nightlyMaintenance(),
+			now:      at(4, 1, 59),
+			inWindow: false,
+			next:     at(4, 2, 0),
+		},
+		{
+			name: "earliest of several windows",
+			spec: &k8znerv1alpha1.MaintenanceSpec{
+				Windows: []k8znerv1alpha1.MaintenanceWindow{
+					{Schedule: "0 2 * * sat", Duration: "4h"},
+					{Schedule: "30 22 * * 1-5", Duration: "1h"},
+				},
+			},
+			now:      at(4, 10, 0),
+			inWindow: false,
+			next:     at(4, 22, 30),
+		},
+		{
+			name: "window spanning midnight",
+			spec: &k8znerv1alpha1.MaintenanceSpec{
+				Windows: []k8znerv1alpha1.MaintenanceWindow{{Schedule: "0 23 * * *", Duration: "3h"}},
+			},
+			now:      at(5, 1, 0),
+			inWindow: true,
+			next:     at(5, 23, 0),
+		},
+		{
+			name: "schedule in time zone",
+			spec: &k8znerv1alpha1.MaintenanceSpec{
+				Windows:  []k8znerv1alpha1.MaintenanceWindow{{Schedule: "0 2 * * *", Duration: "1h"}},
+				Timezone: "Europe/Berlin",
+			},
+			// 02:30 in Berlin (UTC+1 in early March)
+			now:      at(4, 1, 30),
+			inWindow: true,
+			next:     at(5, 1, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			inWindow, next, err := maintenanceWindowState(tt.spec, tt.now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.inWindow, inWindow)
+			assert.True(t, tt.next.Equal(next), "next window = %v, want %v", next, tt.next)
+		})
+	}
+}
+
+func TestMaintenanceWindowState_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		spec   *k8znerv1alpha1.MaintenanceSpec
+		errMsg string
+	}{
+		{
+			name: "unknown time zone",
+			spec: &k8znerv1alpha1.MaintenanceSpec{
+				Windows:  []k8znerv1alpha1.MaintenanceWindow{{Schedule: "0 2 * * *", Duration: "4h"}},
+				Timezone: "Mars/Olympus",
+			},
+			errMsg: "invalid timezone",
+		},
+		{
+			name: "bad schedule",
+			spec: &k8znerv1alpha1.MaintenanceSpec{
+				Windows: []k8znerv1alpha1.MaintenanceWindow{{Schedule: "0 2 * *", Duration: "4h"}},
+			},
+			errMsg: "window 0",
+		},
+		{
+			name: "bad duration",
+			spec: &k8znerv1alpha1.MaintenanceSpec{
+				Windows: []k8znerv1alpha1.MaintenanceWindow{{Schedule: "0 2 * * *", Duration: "four hours"}},
+			},
+			errMsg: "invalid duration",
+		},
+		{
+			name: "non-positive duration",
+			spec: &k8znerv1alpha1.MaintenanceSpec{
+				Windows: []k8znerv1alpha1.MaintenanceWindow{{Schedule: "0 2 * * *", Duration: "0s"}},
+			},
+			errMsg: "must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			inWindow, _, err := maintenanceWindowState(tt.spec, time.Now())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			assert.False(t, inWindow)
+		})
+	}
+}
+
+func TestMaintenancePass(t *testing.T) {
+	t.Parallel()
+
+	newReconciler := func(t *testing.T) (*ClusterReconciler, *record.FakeRecorder) {
+		t.Helper()
+		scheme := setupTestScheme(t)
+		recorder := record.NewFakeRecorder(20)
+		r := NewClusterReconciler(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme, recorder,
+			WithMetrics(false),
+		)
+		return r, recorder
+	}
+	outside := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	inside := time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC)
+
+	t.Run("clears status without windows", func(t *testing.T) {
+		t.Parallel()
+		r, _ := newReconciler(t)
+		cluster := &k8znerv1alpha1.K8znerCluster{}
+		cluster.Status.Maintenance = &k8znerv1alpha1.MaintenanceStatus{}
+
+		previous := r.startMaintenancePass(context.Background(), cluster, outside)
+		assert.Nil(t, previous)
+		assert.Nil(t, cluster.Status.Maintenance)
+		assert.True(t, r.disruptionAllowed(context.Background(), cluster, maintenanceActionScaleDown, "workers", "scale down"))
+	})
+
+	t.Run("defers actions outside a window and keeps their queue time", func(t *testing.T) {
+		t.Parallel()
+		r, recorder := newReconciler(t)
+		cluster := &k8znerv1alpha1.K8znerCluster{}
+		cluster.Spec.Maintenance = nightlyMaintenance()
+		queuedAt := metav1.NewTime(outside.Add(-time.Hour))
+		cluster.Status.Maintenance = &k8znerv1alpha1.MaintenanceStatus{
+			Pending: []k8znerv1alpha1.PendingAction{
+				{Action: maintenanceActionTalosUpgrade, Reason: "old reason", QueuedAt: queuedAt},
+				{Action: maintenanceActionScaleDown, Target: "gone", Reason: "no longer needed", QueuedAt: queuedAt},
+			},
+		}
+
+		previous := r.startMaintenancePass(context.Background(), cluster, outside)
+		require.Len(t, previous, 2)
+		status := cluster.Status.Maintenance
+		assert.False(t, status.InWindow)
+		require.NotNil(t, status.NextWindow)
+		assert.True(t, status.NextWindow.Equal(&metav1.Time{Time: time.Date(2026, 3, 5, 2, 0, 0, 0, time.UTC)}))
+		assert.Empty(t, status.Pending)
+
+		assert.False(t, r.disruptionAllowed(context.Background(), cluster, maintenanceActionTalosUpgrade, "", "3 nodes need an upgrade"))
+		assert.False(t, r.disruptionAllowed(context.Background(), cluster, maintenanceActionScaleDown, "workers", "scale pool workers 3 -> 2"))
+		assert.False(t, r.disruptionAllowed(context.Background(), cluster, maintenanceActionScaleDown, "workers", "scale pool workers 3 -> 1"))
+		r.finishMaintenancePass(cluster, previous, true)
+
+		require.Len(t, status.Pending, 2)
+		talos := findPendingAction(status.Pending, maintenanceActionTalosUpgrade, "")
+		require.NotNil(t, talos)
+		assert.Equal(t, "3 nodes need an upgrade", talos.Reason)
+		assert.True(t, talos.QueuedAt.Equal(&queuedAt), "queue time of a still-deferred action is kept")
+		scaleDown := findPendingAction(status.Pending, maintenanceActionScaleDown, "workers")
+		require.NotNil(t, scaleDown)
+		assert.Equal(t, "scale pool workers 3 -> 1", scaleDown.Reason)
+		assert.Nil(t, findPendingAction(status.Pending, maintenanceActionScaleDown, "gone"))
+
+		// Only the newly deferred action is announced
+		require.Len(t, recorder.Events, 1)
+		event := <-recorder.Events
+		assert.Contains(t, event, EventReasonMaintenanceDeferred)
+		assert.Contains(t, event, "ScaleDown of workers")
+	})
+
+	t.Run("keeps unevaluated actions when the reconcile stops early", func(t *testing.T) {
+		t.Parallel()
+		r, _ := newReconciler(t)
+		cluster := &k8znerv1alpha1.K8znerCluster{}
+		cluster.Spec.Maintenance = nightlyMaintenance()
+		cluster.Status.Maintenance = &k8znerv1alpha1.MaintenanceStatus{
+			Pending: []k8znerv1alpha1.PendingAction{
+				{Action: maintenanceActionMachineConfig, Reason: "2 nodes run an outdated machine config", QueuedAt: metav1.NewTime(outside)},
+			},
+		}
+
+		previous := r.startMaintenancePass(context.Background(), cluster, outside)
+		r.finishMaintenancePass(cluster, previous, false)
+
+		require.Len(t, cluster.Status.Maintenance.Pending, 1)
+		assert.Equal(t, maintenanceActionMachineConfig, cluster.Status.Maintenance.Pending[0].Action)
+	})
+
+	t.Run("runs actions and empties the queue inside a window", func(t *testing.T) {
+		t.Parallel()
+		r, _ := newReconciler(t)
+		cluster := &k8znerv1alpha1.K8znerCluster{}
+		cluster.Spec.Maintenance = nightlyMaintenance()
+		cluster.Status.Maintenance = &k8znerv1alpha1.MaintenanceStatus{
+			Pending: []k8znerv1alpha1.PendingAction{
+				{Action: maintenanceActionTalosUpgrade, Reason: "3 nodes need an upgrade", QueuedAt: metav1.NewTime(outside)},
+			},
+		}
+
+		previous := r.startMaintenancePass(context.Background(), cluster, inside)
+		assert.True(t, cluster.Status.Maintenance.InWindow)
+		assert.True(t, r.disruptionAllowed(context.Background(), cluster, maintenanceActionTalosUpgrade, "", "3 nodes need an upgrade"))
+		r.finishMaintenancePass(cluster, previous, false)
+
+		assert.Empty(t, cluster.Status.Maintenance.Pending)
+	})
+
+	t.Run("invalid windows defer everything", func(t *testing.T) {
+		t.Parallel()
+		r, recorder := newReconciler(t)
+		cluster := &k8znerv1alpha1.K8znerCluster{}
+		cluster.Spec.Maintenance = &k8znerv1alpha1.MaintenanceSpec{
+			Windows: []k8znerv1alpha1.MaintenanceWindow{{Schedule: "every night", Duration: "4h"}},
+		}
+
+		r.startMaintenancePass(context.Background(), cluster, inside)
+		assert.False(t, cluster.Status.Maintenance.InWindow)
+		assert.Nil(t, cluster.Status.Maintenance.NextWindow)
+		assert.False(t, r.disruptionAllowed(context.Background(), cluster, maintenanceActionScaleDown, "workers", "scale down"))
+
+		require.Len(t, recorder.Events, 1)
+		assert.Contains(t, <-recorder.Events, EventReasonMaintenanceInvalid)
+	})
+}
+
+func TestMaintenance_DefersHealing(t *testing.T) {
+	t.Parallel()
+	scheme := setupTestScheme(t)
+
+	newCluster := func() *k8znerv1alpha1.K8znerCluster {
+		unhealthySince := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+		cluster := &k8znerv1alpha1.K8znerCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+			Spec: k8znerv1alpha1.K8znerClusterSpec{
+				Workers: k8znerv1alpha1.WorkerSpec{Count: 2},
+			},
+			Status: k8znerv1alpha1.K8znerClusterStatus{
+				Workers: k8znerv1alpha1.NodeGroupStatus{
+					Desired: 2,
+					Ready:   1,
+					Nodes: []k8znerv1alpha1.NodeStatus{
+						{Name: "worker-1", Healthy: true},
+						{Name: "worker-2", Healthy: false, ServerID: 12345, UnhealthySince: &unhealthySince, UnhealthyReason: "NodeNotReady"},
+					},
+				},
+			},
+		}
+		outsideMaintenanceWindow(cluster)
+		return cluster
+	}
+	newReconciler := func(cluster *k8znerv1alpha1.K8znerCluster, mockHCloud *MockHCloudClient) *ClusterReconciler {
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(cluster, createTestNode("worker-2", false, false)).
+			WithStatusSubresource(cluster).
+			Build()
+		return NewClusterReconciler(c, scheme, record.NewFakeRecorder(10),
+			WithHCloudClient(mockHCloud),
+			WithMetrics(false),
+		)
+	}
+
+	t.Run("queues replacement outside a window", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster()
+		mockHCloud := &MockHCloudClient{}
+		r := newReconciler(cluster, mockHCloud)
+
+		_, err := r.reconcileWorkers(context.Background(), cluster)
+		require.NoError(t, err)
+
+		assert.Empty(t, mockHCloud.DeleteServerCalls)
+		pending := findPendingAction(cluster.Status.Maintenance.Pending, maintenanceActionNodeReplacement, "worker-2")
+		require.NotNil(t, pending)
+		assert.Contains(t, pending.Reason, "NodeNotReady")
+	})
+
+	t.Run("emergency healing bypasses the window", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCluster()
+		cluster.Spec.Maintenance.AllowEmergencyHealing = true
+		mockHCloud := &MockHCloudClient{}
+		r := newReconciler(cluster, mockHCloud)
+
+		_, err := r.reconcileWorkers(context.Background(), cluster)
+		require.NoError(t, err)
+
+		assert.Contains(t, mockHCloud.DeleteServerCalls, "worker-2")
+		assert.Empty(t, cluster.Status.Maintenance.Pending)
+	})
+}
+
+func TestMaintenance_DefersScaleDown(t *testing.T) {
+	t.Parallel()
+	scheme := setupTestScheme(t)
+	mockHCloud := &MockHCloudClient{}
+	r := NewClusterReconciler(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme, record.NewFakeRecorder(10),
+		WithHCloudClient(mockHCloud),
+		WithMetrics(false),
+	)
+
+	cluster := &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			Workers: k8znerv1alpha1.WorkerSpec{Count: 1},
+		},
+		Status: k8znerv1alpha1.K8znerClusterStatus{
+			Workers: k8znerv1alpha1.NodeGroupStatus{
+				Nodes: []k8znerv1alpha1.NodeStatus{
+					{Name: "w-1", Healthy: true},
+					{Name: "w-2", Healthy: true},
+				},
+			},
+		},
+	}
+	outsideMaintenanceWindow(cluster)
+
+	result, err := r.scaleWorkers(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	assert.Empty(t, mockHCloud.DeleteServerCalls)
+	assert.Len(t, cluster.Status.Workers.Nodes, 2)
+	assert.NotEqual(t, k8znerv1alpha1.ClusterPhaseHealing, cluster.Status.Phase)
+
+	pending := findPendingAction(cluster.Status.Maintenance.Pending, maintenanceActionScaleDown, "workers")
+	require.NotNil(t, pending)
+	assert.Equal(t, "scale pool workers 2 -> 1", pending.Reason)
+}
+
+func TestMaintenance_DefersTalosUpgrade(t *testing.T) {
+	t.Parallel()
+	cluster := newUpgradeTestCluster([]string{"v1.9.0"}, []string{"v1.9.0"})
+	outsideMaintenanceWindow(cluster)
+	versions := &fakeTalosVersions{versions: map[string]string{"10.0.1.1": "v1.9.0", "10.0.2.1": "v1.9.0"}}
+	gen := versions.generator()
+	r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+	result, err := r.reconcileTalosUpgrade(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	assert.Empty(t, gen.UpgradeNodeCalls)
+
+	cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionTalosUpgrade)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "WaitingForMaintenanceWindow", cond.Reason)
+
+	pending := findPendingAction(cluster.Status.Maintenance.Pending, maintenanceActionTalosUpgrade, "")
+	require.NotNil(t, pending)
+	assert.Equal(t, "2 nodes need an upgrade to Talos v1.10.0", pending.Reason)
+}
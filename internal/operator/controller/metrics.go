@@ -2,6 +2,8 @@
 package controller
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -82,6 +84,38 @@ var (
 		[]string{"cluster", "role"},
 	)
 
+	// Node upgrade metrics
+	nodeUpgradesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "k8zner",
+			Subsystem: "cluster",
+			Name:      "node_upgrades_total",
+			Help:      "Total number of Talos node upgrades by role and result",
+		},
+		[]string{"cluster", "role", "result"},
+	)
+
+	// Autoscaler metrics
+	autoscalerDecisionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "k8zner",
+			Subsystem: "autoscaler",
+			Name:      "decisions_total",
+			Help:      "Total number of worker pool autoscaling decisions by pool and decision",
+		},
+		[]string{"cluster", "pool", "decision"},
+	)
+
+	autoscalerUnschedulablePods = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "k8zner",
+			Subsystem: "autoscaler",
+			Name:      "unschedulable_pods",
+			Help:      "Number of pods the scheduler could not place at the last autoscaler run",
+		},
+		[]string{"cluster"},
+	)
+
 	// Etcd metrics
 	etcdMembersTotal = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -103,6 +137,27 @@ var (
 		[]string{"cluster"},
 	)
 
+	// Backup metrics
+	backupVerified = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "k8zner",
+			Subsystem: "backup",
+			Name:      "verified",
+			Help:      "Whether the last backup verification run succeeded (1) or not (0)",
+		},
+		[]string{"cluster"},
+	)
+
+	backupLastVerifiedTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "k8zner",
+			Subsystem: "backup",
+			Name:      "last_verified_timestamp_seconds",
+			Help:      "Unix time of the last successful backup verification",
+		},
+		[]string{"cluster"},
+	)
+
 	// Hetzner Cloud API metrics
 	hcloudAPICallsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -136,8 +191,13 @@ func init() {
 		nodesDesired,
 		nodeReplacementsTotal,
 		nodeReplacementDuration,
+		nodeUpgradesTotal,
+		autoscalerDecisionsTotal,
+		autoscalerUnschedulablePods,
 		etcdMembersTotal,
 		etcdHealthy,
+		backupVerified,
+		backupLastVerifiedTimestamp,
 		hcloudAPICallsTotal,
 		hcloudAPILatency,
 	)
@@ -166,6 +226,21 @@ func recordNodeReplacementDurationMetric(cluster, role string, duration float64)
 	nodeReplacementDuration.WithLabelValues(cluster, role).Observe(duration)
 }
 
+// recordNodeUpgradeMetric records a node upgrade attempt.
+func recordNodeUpgradeMetric(cluster, role, result string) {
+	nodeUpgradesTotal.WithLabelValues(cluster, role, result).Inc()
+}
+
+// recordAutoscalerDecisionMetric records a worker pool autoscaling decision.
+func recordAutoscalerDecisionMetric(cluster, pool, decision string) {
+	autoscalerDecisionsTotal.WithLabelValues(cluster, pool, decision).Inc()
+}
+
+// recordUnschedulablePodsMetric records the number of unschedulable pods.
+func recordUnschedulablePodsMetric(cluster string, count int) {
+	autoscalerUnschedulablePods.WithLabelValues(cluster).Set(float64(count))
+}
+
 // recordEtcdStatusMetric records the etcd cluster status.
 func recordEtcdStatusMetric(cluster string, members int, healthy bool) {
 	etcdMembersTotal.WithLabelValues(cluster).Set(float64(members))
@@ -176,6 +251,16 @@ func recordEtcdStatusMetric(cluster string, members int, healthy bool) {
 	}
 }
 
+// recordBackupVerificationMetric records the result of a backup verification run.
+func recordBackupVerificationMetric(cluster string, verified bool, finished time.Time) {
+	if !verified {
+		backupVerified.WithLabelValues(cluster).Set(0)
+		return
+	}
+	backupVerified.WithLabelValues(cluster).Set(1)
+	backupLastVerifiedTimestamp.WithLabelValues(cluster).Set(float64(finished.Unix()))
+}
+
 // recordHCloudAPICallMetric records a Hetzner Cloud API call.
 func recordHCloudAPICallMetric(operation, result string, latency float64) {
 	hcloudAPICallsTotal.WithLabelValues(operation, result).Inc()
@@ -209,6 +294,30 @@ func (r *ClusterReconciler) recordNodeReplacementDuration(cluster, role string,
 	}
 }
 
+func (r *ClusterReconciler) recordNodeUpgrade(cluster, role, result string) {
+	if r.enableMetrics {
+		recordNodeUpgradeMetric(cluster, role, result)
+	}
+}
+
+func (r *ClusterReconciler) recordAutoscalerDecision(cluster, pool, decision string) {
+	if r.enableMetrics {
+		recordAutoscalerDecisionMetric(cluster, pool, decision)
+	}
+}
+
+func (r *ClusterReconciler) recordUnschedulablePods(cluster string, count int) {
+	if r.enableMetrics {
+		recordUnschedulablePodsMetric(cluster, count)
+	}
+}
+
+func (r *ClusterReconciler) recordBackupVerification(cluster string, verified bool, finished time.Time) {
+	if r.enableMetrics {
+		recordBackupVerificationMetric(cluster, verified, finished)
+	}
+}
+
 func (r *ClusterReconciler) recordHCloudAPICall(operation, result string, latency float64) {
 	if r.enableMetrics {
 		recordHCloudAPICallMetric(operation, result, latency)
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
@@ -97,6 +98,44 @@ func TestRecordEtcdStatusMetric(t *testing.T) {
 	assert.Equal(t, float64(0), testutil.ToFloat64(healthyGauge))
 }
 
+func TestRecordAutoscalerMetrics(t *testing.T) {
+	autoscalerDecisionsTotal.Reset()
+	autoscalerUnschedulablePods.Reset()
+
+	recordAutoscalerDecisionMetric("test-cluster", "general", "scale_up")
+	recordAutoscalerDecisionMetric("test-cluster", "general", "scale_up")
+	recordUnschedulablePodsMetric("test-cluster", 4)
+
+	counter, err := autoscalerDecisionsTotal.GetMetricWithLabelValues("test-cluster", "general", "scale_up")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), testutil.ToFloat64(counter))
+
+	gauge, err := autoscalerUnschedulablePods.GetMetricWithLabelValues("test-cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(4), testutil.ToFloat64(gauge))
+}
+
+func TestRecordBackupVerificationMetric(t *testing.T) {
+	backupVerified.Reset()
+	backupLastVerifiedTimestamp.Reset()
+
+	finished := time.Unix(1700000000, 0)
+	recordBackupVerificationMetric("test-cluster", true, finished)
+
+	verifiedGauge, err := backupVerified.GetMetricWithLabelValues("test-cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(verifiedGauge))
+
+	timestampGauge, err := backupLastVerifiedTimestamp.GetMetricWithLabelValues("test-cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1700000000), testutil.ToFloat64(timestampGauge))
+
+	// A failed run keeps the time of the last success
+	recordBackupVerificationMetric("test-cluster", false, finished.Add(time.Hour))
+	assert.Equal(t, float64(0), testutil.ToFloat64(verifiedGauge))
+	assert.Equal(t, float64(1700000000), testutil.ToFloat64(timestampGauge))
+}
+
 // --- Wrapper method tests (test enableMetrics guard) ---
 
 func TestRecordNodeCounts_MetricsEnabled(t *testing.T) {
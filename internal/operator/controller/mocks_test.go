@@ -2,11 +2,15 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"time"
 
 	hcloudgo "github.com/hetznercloud/hcloud-go/v2/hcloud"
 
+	"github.com/milankappen/k8zner/internal/config"
 	"github.com/milankappen/k8zner/internal/platform/hcloud"
+	"github.com/milankappen/k8zner/internal/provisioning"
 )
 
 // MockHCloudClient is a mock implementation of HCloudClient for testing.
@@ -189,12 +193,16 @@ type MockTalosClient struct {
 	GetEtcdMembersFunc          func(ctx context.Context, nodeIP string) ([]etcdMember, error)
 	RemoveEtcdMemberFunc        func(ctx context.Context, nodeIP string, memberID string) error
 	WaitForNodeReadyFunc        func(ctx context.Context, nodeIP string, timeout int) error
+	EtcdSnapshotFunc            func(ctx context.Context, nodeIP string) ([]byte, error)
+	KubeconfigFunc              func(ctx context.Context, nodeIP string) ([]byte, error)
 
 	// Call tracking
 	ApplyConfigCalls      []ApplyConfigCall
 	GetEtcdMembersCalls   []string
 	RemoveEtcdMemberCalls []RemoveEtcdMemberCall
 	WaitForNodeReadyCalls []WaitForNodeReadyCall
+	EtcdSnapshotCalls     []string
+	KubeconfigCalls       []string
 }
 
 // WaitForNodeReadyCall tracks arguments to WaitForNodeReady.
@@ -279,6 +287,28 @@ func (m *MockTalosClient) WaitForNodeReady(ctx context.Context, nodeIP string, t
 	return nil
 }
 
+func (m *MockTalosClient) EtcdSnapshot(ctx context.Context, nodeIP string) ([]byte, error) {
+	m.mu.Lock()
+	m.EtcdSnapshotCalls = append(m.EtcdSnapshotCalls, nodeIP)
+	m.mu.Unlock()
+
+	if m.EtcdSnapshotFunc != nil {
+		return m.EtcdSnapshotFunc(ctx, nodeIP)
+	}
+	return nil, errors.New("etcd snapshot not configured in mock")
+}
+
+func (m *MockTalosClient) Kubeconfig(ctx context.Context, nodeIP string) ([]byte, error) {
+	m.mu.Lock()
+	m.KubeconfigCalls = append(m.KubeconfigCalls, nodeIP)
+	m.mu.Unlock()
+
+	if m.KubeconfigFunc != nil {
+		return m.KubeconfigFunc(ctx, nodeIP)
+	}
+	return []byte("mock-kubeconfig"), nil
+}
+
 // MockTalosConfigGenerator is a mock implementation of TalosConfigGenerator for testing.
 type MockTalosConfigGenerator struct {
 	mu sync.Mutex
@@ -288,11 +318,41 @@ type MockTalosConfigGenerator struct {
 	GenerateWorkerConfigFunc       func(hostname string, serverID int64) ([]byte, error)
 	SetEndpointFunc                func(endpoint string)
 	GetClientConfigFunc            func() ([]byte, error)
+	GetNodeVersionFunc             func(ctx context.Context, endpoint string) (string, error)
+	UpgradeNodeFunc                func(ctx context.Context, endpoint, imageURL string, opts provisioning.UpgradeOptions) error
+	WaitForNodeReadyFunc           func(ctx context.Context, endpoint string, timeout time.Duration) error
+	UpgradeKubernetesFunc          func(ctx context.Context, endpoint, targetVersion string) error
+	ApplyMachineConfigFunc         func(ctx context.Context, endpoint string, data []byte, mode string) (string, error)
 
 	// Call tracking
 	GenerateControlPlaneConfigCalls []GenerateControlPlaneConfigCall
 	GenerateWorkerConfigCalls       []GenerateWorkerConfigCall
 	SetEndpointCalls                []string
+	GetNodeVersionCalls             []string
+	UpgradeNodeCalls                []UpgradeNodeCall
+	WaitForNodeReadyCalls           []string
+	UpgradeKubernetesCalls          []UpgradeKubernetesCall
+	ApplyMachineConfigCalls         []ApplyMachineConfigCall
+}
+
+// ApplyMachineConfigCall tracks arguments to ApplyMachineConfig.
+type ApplyMachineConfigCall struct {
+	Endpoint string
+	Data     []byte
+	Mode     string
+}
+
+// UpgradeKubernetesCall tracks arguments to UpgradeKubernetes.
+type UpgradeKubernetesCall struct {
+	Endpoint      string
+	TargetVersion string
+}
+
+// UpgradeNodeCall tracks arguments to UpgradeNode.
+type UpgradeNodeCall struct {
+	Endpoint string
+	ImageURL string
+	Opts     provisioning.UpgradeOptions
 }
 
 // GenerateControlPlaneConfigCall tracks arguments to GenerateControlPlaneConfig.
@@ -306,6 +366,7 @@ type GenerateControlPlaneConfigCall struct {
 type GenerateWorkerConfigCall struct {
 	Hostname string
 	ServerID int64
+	Pool     *config.WorkerNodePool
 }
 
 func (m *MockTalosConfigGenerator) GenerateControlPlaneConfig(sans []string, hostname string, serverID int64) ([]byte, error) {
@@ -323,11 +384,12 @@ func (m *MockTalosConfigGenerator) GenerateControlPlaneConfig(sans []string, hos
 	return []byte("mock-control-plane-config"), nil
 }
 
-func (m *MockTalosConfigGenerator) GenerateWorkerConfig(hostname string, serverID int64) ([]byte, error) {
+func (m *MockTalosConfigGenerator) GenerateWorkerConfig(hostname string, serverID int64, pool *config.WorkerNodePool) ([]byte, error) {
 	m.mu.Lock()
 	m.GenerateWorkerConfigCalls = append(m.GenerateWorkerConfigCalls, GenerateWorkerConfigCall{
 		Hostname: hostname,
 		ServerID: serverID,
+		Pool:     pool,
 	})
 	m.mu.Unlock()
 
@@ -353,3 +415,69 @@ func (m *MockTalosConfigGenerator) GetClientConfig() ([]byte, error) {
 	}
 	return []byte("mock-talosconfig"), nil
 }
+
+func (m *MockTalosConfigGenerator) GetNodeVersion(ctx context.Context, endpoint string) (string, error) {
+	m.mu.Lock()
+	m.GetNodeVersionCalls = append(m.GetNodeVersionCalls, endpoint)
+	m.mu.Unlock()
+
+	if m.GetNodeVersionFunc != nil {
+		return m.GetNodeVersionFunc(ctx, endpoint)
+	}
+	return "", nil
+}
+
+func (m *MockTalosConfigGenerator) UpgradeNode(ctx context.Context, endpoint, imageURL string, opts provisioning.UpgradeOptions) error {
+	m.mu.Lock()
+	m.UpgradeNodeCalls = append(m.UpgradeNodeCalls, UpgradeNodeCall{
+		Endpoint: endpoint,
+		ImageURL: imageURL,
+		Opts:     opts,
+	})
+	m.mu.Unlock()
+
+	if m.UpgradeNodeFunc != nil {
+		return m.UpgradeNodeFunc(ctx, endpoint, imageURL, opts)
+	}
+	return nil
+}
+
+func (m *MockTalosConfigGenerator) WaitForNodeReady(ctx context.Context, endpoint string, timeout time.Duration) error {
+	m.mu.Lock()
+	m.WaitForNodeReadyCalls = append(m.WaitForNodeReadyCalls, endpoint)
+	m.mu.Unlock()
+
+	if m.WaitForNodeReadyFunc != nil {
+		return m.WaitForNodeReadyFunc(ctx, endpoint, timeout)
+	}
+	return nil
+}
+
+func (m *MockTalosConfigGenerator) UpgradeKubernetes(ctx context.Context, endpoint, targetVersion string) error {
+	m.mu.Lock()
+	m.UpgradeKubernetesCalls = append(m.UpgradeKubernetesCalls, UpgradeKubernetesCall{
+		Endpoint:      endpoint,
+		TargetVersion: targetVersion,
+	})
+	m.mu.Unlock()
+
+	if m.UpgradeKubernetesFunc != nil {
+		return m.UpgradeKubernetesFunc(ctx, endpoint, targetVersion)
+	}
+	return nil
+}
+
+func (m *MockTalosConfigGenerator) ApplyMachineConfig(ctx context.Context, endpoint string, data []byte, mode string) (string, error) {
+	m.mu.Lock()
+	m.ApplyMachineConfigCalls = append(m.ApplyMachineConfigCalls, ApplyMachineConfigCall{
+		Endpoint: endpoint,
+		Data:     data,
+		Mode:     mode,
+	})
+	m.mu.Unlock()
+
+	if m.ApplyMachineConfigFunc != nil {
+		return m.ApplyMachineConfigFunc(ctx, endpoint, data, mode)
+	}
+	return mode, nil
+}
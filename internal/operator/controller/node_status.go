@@ -35,6 +35,21 @@ type nodeStatusUpdate struct {
 	PrivateIP string
 	Phase     k8znerv1alpha1.NodePhase
 	Reason    string
+
+	// TalosVersion records the Talos version observed on the node, if known.
+	TalosVersion string
+
+	// KubernetesVersion records the kubelet version observed on the node, if known.
+	KubernetesVersion string
+
+	// Pool records the worker pool the node belongs to, if known.
+	Pool string
+
+	// ConfigHash records the machine config hash the node runs, if known.
+	ConfigHash string
+
+	// StagedConfigHash records a machine config hash staged for the next reboot, if any.
+	StagedConfigHash string
 }
 
 // updateNodePhase updates or adds a node's phase in the cluster status.
@@ -77,6 +92,21 @@ func (r *ClusterReconciler) updateNodePhase(ctx context.Context, cluster *k8zner
 		if update.PrivateIP != "" {
 			(*nodes)[i].PrivateIP = update.PrivateIP
 		}
+		if update.TalosVersion != "" {
+			(*nodes)[i].TalosVersion = update.TalosVersion
+		}
+		if update.KubernetesVersion != "" {
+			(*nodes)[i].KubernetesVersion = update.KubernetesVersion
+		}
+		if update.Pool != "" {
+			(*nodes)[i].Pool = update.Pool
+		}
+		if update.ConfigHash != "" {
+			(*nodes)[i].ConfigHash = update.ConfigHash
+		}
+		if update.StagedConfigHash != "" {
+			(*nodes)[i].StagedConfigHash = update.StagedConfigHash
+		}
 		// Update health based on phase
 		(*nodes)[i].Healthy = update.Phase == k8znerv1alpha1.NodePhaseReady
 		found = true
@@ -94,6 +124,11 @@ func (r *ClusterReconciler) updateNodePhase(ctx context.Context, cluster *k8zner
 			PhaseReason:         update.Reason,
 			PhaseTransitionTime: &now,
 			Healthy:             update.Phase == k8znerv1alpha1.NodePhaseReady,
+			TalosVersion:        update.TalosVersion,
+			KubernetesVersion:   update.KubernetesVersion,
+			Pool:                update.Pool,
+			ConfigHash:          update.ConfigHash,
+			StagedConfigHash:    update.StagedConfigHash,
 		}
 		*nodes = append(*nodes, newNode)
 	}
@@ -338,11 +373,12 @@ func shouldUpdatePhase(current, newPhase k8znerv1alpha1.NodePhase) bool {
 		k8znerv1alpha1.NodePhaseWaitingForK8s:       6,
 		k8znerv1alpha1.NodePhaseNodeInitializing:    7,
 		k8znerv1alpha1.NodePhaseReady:               8,
-		k8znerv1alpha1.NodePhaseUnhealthy:           9,
-		k8znerv1alpha1.NodePhaseDraining:            10,
-		k8znerv1alpha1.NodePhaseRemovingFromEtcd:    11,
-		k8znerv1alpha1.NodePhaseDeletingServer:      12,
-		k8znerv1alpha1.NodePhaseFailed:              13,
+		k8znerv1alpha1.NodePhaseUpgrading:           9,
+		k8znerv1alpha1.NodePhaseUnhealthy:           10,
+		k8znerv1alpha1.NodePhaseDraining:            11,
+		k8znerv1alpha1.NodePhaseRemovingFromEtcd:    12,
+		k8znerv1alpha1.NodePhaseDeletingServer:      13,
+		k8znerv1alpha1.NodePhaseFailed:              14,
 	}
 
 	currentOrder, currentOK := phaseOrder[current]
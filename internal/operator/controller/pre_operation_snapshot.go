@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/backup"
+)
+
+// Operations preceded by an etcd snapshot. They name the snapshot key.
+const (
+	snapshotOpCPReplace    = "cp-replace"
+	snapshotOpCPScaleUp    = "cp-scale-up"
+	snapshotOpCPScaleDown  = "cp-scale-down"
+	snapshotOpCPResize     = "cp-resize"
+	snapshotOpTalosUpgrade = "talos-upgrade"
+	snapshotOpK8sUpgrade   = "k8s-upgrade"
+	snapshotOpCARotation   = "ca-rotation"
+)
+
+// takePreOperationSnapshot saves an etcd snapshot to the backup bucket before a
+// disruptive operation on node (empty if the operation adds a node). It returns
+// an error when the operation must not proceed: the snapshot failed and the
+// cluster does not carry the force-without-snapshot annotation. Clusters
+// without backups have no bucket to write to and are not checked.
+func (r *ClusterReconciler) takePreOperationSnapshot(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, operation, node string) error {
+	logger := log.FromContext(ctx)
+
+	if spec := cluster.Spec.Backup; spec == nil || !spec.Enabled {
+		return nil
+	}
+
+	key, err := r.savePreOperationSnapshot(ctx, cluster, tc, operation, node)
+	if err == nil {
+		logger.Info("saved etcd snapshot before operation", "operation", operation, "node", node, "key", key)
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonPreOperationSnapshot,
+			"Saved etcd snapshot %s before %s", key, describeOperation(operation, node))
+		return nil
+	}
+
+	if cluster.Annotations[k8znerv1alpha1.ForceWithoutSnapshotAnnotation] == "true" {
+		r.logAndRecordError(ctx, cluster, err, EventReasonPreOperationSnapshotFailed,
+			fmt.Sprintf("Etcd snapshot failed, continuing with %s because of %s",
+				describeOperation(operation, node), k8znerv1alpha1.ForceWithoutSnapshotAnnotation))
+		return nil
+	}
+
+	r.logAndRecordError(ctx, cluster, err, EventReasonPreOperationSnapshotFailed,
+		fmt.Sprintf("Etcd snapshot failed, %s postponed", describeOperation(operation, node)))
+	return fmt.Errorf("etcd snapshot before %s failed: %w", describeOperation(operation, node), err)
+}
+
+// savePreOperationSnapshot takes an etcd snapshot from a healthy control plane
+// other than node, checks that it is readable and uploads it.
+func (r *ClusterReconciler) savePreOperationSnapshot(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, operation, node string) (string, error) {
+	if tc.client == nil {
+		return "", errors.New("no Talos credentials to take the snapshot with")
+	}
+	sourceIP := findPeerControlPlaneIP(cluster, node)
+	if sourceIP == "" {
+		return "", errors.New("no healthy control plane to take the snapshot from")
+	}
+
+	repo, err := r.openBackupRepository(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
+
+	snapshot, err := tc.client.EtcdSnapshot(ctx, sourceIP)
+	if err != nil {
+		return "", err
+	}
+	// A snapshot that cannot be restored is no recovery point
+	if _, err := backup.Inspect(snapshot); err != nil {
+		return "", fmt.Errorf("snapshot from %s is unusable: %w", sourceIP, err)
+	}
+
+	key := backup.PreOperationKey(operation, node, time.Now())
+	if err := repo.Put(ctx, key, snapshot); err != nil {
+		return "", fmt.Errorf("failed to upload snapshot %s: %w", key, err)
+	}
+	return key, nil
+}
+
+// describeOperation returns a human-readable name for operation on node, for events.
+func describeOperation(operation, node string) string {
+	if node == "" {
+		return operation
+	}
+	return operation + " of " + node
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+	"k8s.io/client-go/tools/record"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+)
+
+// newTestEtcdSnapshot builds a minimal etcd database, as returned by the Talos
+// EtcdSnapshot API.
+func newTestEtcdSnapshot(t *testing.T) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "member.db")
+	db, err := bolt.Open(path, 0600, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		keys, err := tx.CreateBucket([]byte("key"))
+		if err != nil {
+			return err
+		}
+		rev := make([]byte, 17)
+		binary.BigEndian.PutUint64(rev, 7)
+		rev[8] = '_'
+		return keys.Put(rev, []byte("value"))
+	}))
+	require.NoError(t, db.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
+
+// newSnapshotTestCluster returns a backup-enabled cluster with three healthy control planes.
+func newSnapshotTestCluster() *k8znerv1alpha1.K8znerCluster {
+	cluster := newBackupTestCluster("", 0)
+	cluster.Status.ControlPlanes.Nodes = []k8znerv1alpha1.NodeStatus{
+		{Name: "cp-1", PrivateIP: "10.0.0.1", Healthy: true},
+		{Name: "cp-2", PrivateIP: "10.0.0.2", Healthy: true},
+		{Name: "cp-3", PrivateIP: "10.0.0.3", Healthy: true},
+	}
+	return cluster
+}
+
+func TestTakePreOperationSnapshot(t *testing.T) {
+	t.Parallel()
+	store := &fakeBackupStore{}
+	r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(store))
+	recorder := r.Recorder.(*record.FakeRecorder)
+	snapshot := newTestEtcdSnapshot(t)
+	talos := &MockTalosClient{
+		EtcdSnapshotFunc: func(_ context.Context, _ string) ([]byte, error) { return snapshot, nil },
+	}
+
+	err := r.takePreOperationSnapshot(context.Background(), newSnapshotTestCluster(),
+		talosClients{client: talos}, snapshotOpCPReplace, "cp-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"10.0.0.2"}, talos.EtcdSnapshotCalls, "snapshot is taken from a peer of the replaced node")
+	require.Len(t, store.put, 1)
+	for key, data := range store.put {
+		assert.True(t, strings.HasPrefix(key, "etcd-backups/pre-operation/cp-replace-cp-1-"), key)
+		assert.Equal(t, snapshot, data)
+	}
+	assert.Contains(t, <-recorder.Events, EventReasonPreOperationSnapshot)
+}
+
+func TestTakePreOperationSnapshot_Failure(t *testing.T) {
+	t.Parallel()
+	talos := &MockTalosClient{
+		EtcdSnapshotFunc: func(_ context.Context, _ string) ([]byte, error) {
+			return nil, errors.New("etcd unavailable")
+		},
+	}
+
+	t.Run("aborts the operation", func(t *testing.T) {
+		t.Parallel()
+		store := &fakeBackupStore{}
+		r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(store))
+		recorder := r.Recorder.(*record.FakeRecorder)
+
+		err := r.takePreOperationSnapshot(context.Background(), newSnapshotTestCluster(),
+			talosClients{client: talos}, snapshotOpTalosUpgrade, "cp-1")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "etcd unavailable")
+		assert.Empty(t, store.put)
+		assert.Contains(t, <-recorder.Events, EventReasonPreOperationSnapshotFailed)
+	})
+
+	t.Run("continues when forced", func(t *testing.T) {
+		t.Parallel()
+		r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(&fakeBackupStore{}))
+		recorder := r.Recorder.(*record.FakeRecorder)
+		cluster := newSnapshotTestCluster()
+		cluster.Annotations = map[string]string{k8znerv1alpha1.ForceWithoutSnapshotAnnotation: "true"}
+
+		err := r.takePreOperationSnapshot(context.Background(), cluster,
+			talosClients{client: talos}, snapshotOpTalosUpgrade, "cp-1")
+		require.NoError(t, err)
+		assert.Contains(t, <-recorder.Events, EventReasonPreOperationSnapshotFailed)
+	})
+
+	t.Run("unusable snapshot", func(t *testing.T) {
+		t.Parallel()
+		store := &fakeBackupStore{}
+		r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(store))
+		corrupt := &MockTalosClient{
+			EtcdSnapshotFunc: func(_ context.Context, _ string) ([]byte, error) { return []byte("garbage"), nil },
+		}
+
+		err := r.takePreOperationSnapshot(context.Background(), newSnapshotTestCluster(),
+			talosClients{client: corrupt}, snapshotOpCPScaleDown, "cp-3")
+		require.Error(t, err)
+		assert.Empty(t, store.put)
+	})
+
+	t.Run("no Talos credentials", func(t *testing.T) {
+		t.Parallel()
+		r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(&fakeBackupStore{}))
+
+		err := r.takePreOperationSnapshot(context.Background(), newSnapshotTestCluster(),
+			talosClients{}, snapshotOpCPScaleUp, "")
+		require.Error(t, err)
+	})
+}
+
+func TestTakePreOperationSnapshot_BackupsDisabled(t *testing.T) {
+	t.Parallel()
+	r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(&fakeBackupStore{}))
+	cluster := newSnapshotTestCluster()
+	cluster.Spec.Backup = nil
+	talos := &MockTalosClient{}
+
+	err := r.takePreOperationSnapshot(context.Background(), cluster,
+		talosClients{client: talos}, snapshotOpK8sUpgrade, "cp-1")
+	require.NoError(t, err)
+	assert.Empty(t, talos.EtcdSnapshotCalls)
+}
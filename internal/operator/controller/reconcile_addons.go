@@ -224,19 +224,23 @@ func (r *ClusterReconciler) reconcileAddonsPhase(ctx context.Context, cluster *k
 func (r *ClusterReconciler) ensureWorkersReady(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (ctrl.Result, bool) {
 	logger := log.FromContext(ctx)
 
-	desiredWorkers := cluster.Spec.Workers.Count
+	desiredWorkers := desiredWorkerCount(cluster)
 	readyWorkers := cluster.Status.Workers.Ready
 	if desiredWorkers == 0 || readyWorkers >= desiredWorkers {
 		return ctrl.Result{}, false
 	}
 
-	currentWorkerNodes := len(cluster.Status.Workers.Nodes)
-	if currentWorkerNodes < desiredWorkers && r.hcloudClient != nil {
-		toCreate := desiredWorkers - currentWorkerNodes
-		logger.Info("creating workers before addon installation",
-			"desired", desiredWorkers, "current", currentWorkerNodes, "toCreate", toCreate)
-		if err := r.scaleUpWorkers(ctx, cluster, toCreate); err != nil {
-			logger.Error(err, "failed to create workers for addon phase")
+	for _, pool := range workerPools(cluster) {
+		currentWorkerNodes := len(workerPoolNodes(cluster, pool.Name))
+		poolDesired := workerPoolDesiredCount(cluster, pool)
+		if currentWorkerNodes >= poolDesired || r.hcloudClient == nil {
+			continue
+		}
+		toCreate := poolDesired - currentWorkerNodes
+		logger.Info("creating workers before addon installation", "pool", pool.Name,
+			"desired", poolDesired, "current", currentWorkerNodes, "toCreate", toCreate)
+		if err := r.scaleUpWorkers(ctx, cluster, pool, toCreate); err != nil {
+			logger.Error(err, "failed to create workers for addon phase", "pool", pool.Name)
 		}
 	}
 
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/backup"
+	operatorprov "github.com/milankappen/k8zner/internal/operator/provisioning"
+)
+
+const (
+	// backupPruneInterval is how often the backup bucket is listed and pruned.
+	// Snapshots are taken hourly by default, so checking more often gains nothing.
+	backupPruneInterval = 15 * time.Minute
+
+	// Retention defaults for clusters stored before the webhook defaulted them.
+	defaultBackupRetention = 168 * time.Hour
+	defaultBackupKeepLast  = 3
+)
+
+// reconcileBackupRetention deletes snapshots older than spec.backup.retention,
+// always keeping the spec.backup.keepLast most recent ones, and reports the
+// remaining snapshots in status.backup.
+// This is non-fatal — errors are logged but never returned.
+func (r *ClusterReconciler) reconcileBackupRetention(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) {
+	logger := log.FromContext(ctx)
+
+	spec := cluster.Spec.Backup
+	if spec == nil || !spec.Enabled {
+		cluster.Status.Backup = nil
+		return
+	}
+
+	now := time.Now()
+	if status := cluster.Status.Backup; status != nil && status.LastPruneTime != nil &&
+		now.Sub(status.LastPruneTime.Time) < backupPruneInterval {
+		return
+	}
+
+	repo, err := r.openBackupRepository(ctx, cluster)
+	if err != nil {
+		logger.V(1).Info("skipping backup retention", "error", err)
+		return
+	}
+
+	retention, keepLast, err := backupRetentionPolicy(spec)
+	if err != nil {
+		r.logAndRecordError(ctx, cluster, err, EventReasonBackupPruneFailed, "Invalid backup retention")
+		return
+	}
+
+	snapshots, err := repo.List(ctx)
+	if err != nil {
+		r.logAndRecordError(ctx, cluster, err, EventReasonBackupPruneFailed, "Failed to list backups")
+		return
+	}
+
+	deleted := make(map[string]bool)
+	for _, snap := range backup.Expired(snapshots, retention, keepLast, now) {
+		if err := repo.Delete(ctx, snap.Key); err != nil {
+			// Retried on the next pass
+			r.logAndRecordError(ctx, cluster, err, EventReasonBackupPruneFailed,
+				fmt.Sprintf("Failed to delete backup %s", snap.Key))
+			continue
+		}
+		deleted[snap.Key] = true
+	}
+
+	remaining := make([]backup.Snapshot, 0, len(snapshots)-len(deleted))
+	for _, snap := range snapshots {
+		if !deleted[snap.Key] {
+			remaining = append(remaining, snap)
+		}
+	}
+
+	if len(deleted) > 0 {
+		logger.Info("pruned expired backups", "deleted", len(deleted), "remaining", len(remaining))
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonBackupsPruned,
+			"Deleted %d backup(s) older than %s, %d remaining", len(deleted), retention, len(remaining))
+	}
+
+	cluster.Status.Backup = backupStatus(cluster.Status.Backup, remaining, now)
+}
+
+// openBackupRepository opens the backup bucket with the credentials from spec.backup.s3SecretRef.
+func (r *ClusterReconciler) openBackupRepository(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (*backup.Repository, error) {
+	creds, err := r.phaseAdapter.LoadCredentials(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	cfg, ok := operatorprov.BackupConfig(&cluster.Spec, creds)
+	if !ok {
+		return nil, fmt.Errorf("no backup S3 credentials configured")
+	}
+	return r.backupRepository(cfg)
+}
+
+// backupRetentionPolicy returns the retention window and the number of
+// snapshots always kept, falling back to the defaults for unset fields.
+func backupRetentionPolicy(spec *k8znerv1alpha1.BackupSpec) (time.Duration, int, error) {
+	retention := defaultBackupRetention
+	if spec.Retention != "" {
+		d, err := time.ParseDuration(spec.Retention)
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("invalid retention %q: must be a positive duration such as \"168h\"", spec.Retention)
+		}
+		retention = d
+	}
+
+	keepLast := spec.KeepLast
+	if keepLast < 1 {
+		keepLast = defaultBackupKeepLast
+	}
+	return retention, keepLast, nil
+}
+
+// backupStatus summarizes the scheduled backups among snapshots, sorted newest
+// first, for status.backup. The verification fields of prev are kept.
+func backupStatus(prev *k8znerv1alpha1.BackupStatus, snapshots []backup.Snapshot, now time.Time) *k8znerv1alpha1.BackupStatus {
+	status := &k8znerv1alpha1.BackupStatus{}
+	if prev != nil {
+		status.LastVerificationTime = prev.LastVerificationTime
+		status.LastVerifiedSnapshot = prev.LastVerifiedSnapshot
+		status.VerificationJob = prev.VerificationJob
+	}
+	backups := make([]backup.Snapshot, 0, len(snapshots))
+	for _, snap := range snapshots {
+		if !backup.IsPreOperation(snap.Key) {
+			backups = append(backups, snap)
+		}
+	}
+	pruned := metav1.NewTime(now)
+	status.BackupCount = len(backups)
+	status.LastPruneTime = &pruned
+	if len(backups) > 0 {
+		newest := metav1.NewTime(backups[0].CreatedAt)
+		oldest := metav1.NewTime(backups[len(backups)-1].CreatedAt)
+		status.LastBackupTime = &newest
+		status.OldestBackupTime = &oldest
+	}
+	return status
+}
@@ -0,0 +1,215 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/backup"
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+// fakeBackupStore is an in-memory backup bucket.
+type fakeBackupStore struct {
+	objects   []s3.ObjectInfo
+	deleted   []string
+	deleteErr error
+	put       map[string][]byte
+	putErr    error
+}
+
+func (f *fakeBackupStore) ListObjectInfo(_ context.Context, _, _ string) ([]s3.ObjectInfo, error) {
+	return f.objects, nil
+}
+
+func (f *fakeBackupStore) GetObject(_ context.Context, _, _ string) ([]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeBackupStore) PutObject(_ context.Context, _, key string, data []byte) error {
+	if f.putErr != nil {
+		return f.putErr
+	}
+	if f.put == nil {
+		f.put = make(map[string][]byte)
+	}
+	f.put[key] = data
+	return nil
+}
+
+func (f *fakeBackupStore) DeleteObject(_ context.Context, _, key string) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	f.deleted = append(f.deleted, key)
+	return nil
+}
+
+func (f *fakeBackupStore) GetMetadata(_ context.Context, _ string) (*s3.BucketMetadata, error) {
+	return nil, nil
+}
+
+// newBackupTestStore returns a bucket with one snapshot per day, 1 to 10 days old.
+func newBackupTestStore(now time.Time) *fakeBackupStore {
+	store := &fakeBackupStore{}
+	for day := 1; day <= 10; day++ {
+		store.objects = append(store.objects, s3.ObjectInfo{
+			Key:          fmt.Sprintf("etcd-backups/test-%02d.snap", day),
+			LastModified: now.Add(-time.Duration(day) * 24 * time.Hour),
+		})
+	}
+	return store
+}
+
+// newBackupTestSecrets returns the credentials and backup Secrets of a
+// cluster with backups enabled and S3 credentials in its backup Secret.
+func newBackupTestSecrets() []client.Object {
+	return []client.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-credentials", Namespace: "default"},
+			Data:       map[string][]byte{k8znerv1alpha1.CredentialsKeyHCloudToken: []byte("test-token")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-backup-s3", Namespace: "default"},
+			Data: map[string][]byte{
+				"access-key": []byte("access"),
+				"secret-key": []byte("secret"),
+				"endpoint":   []byte("https://fsn1.your-objectstorage.com"),
+				"bucket":     []byte("test-etcd-backups"),
+			},
+		},
+	}
+}
+
+// withBackupStore makes store the reconciler's backup bucket.
+func withBackupStore(store backup.ObjectStore) Option {
+	return WithBackupRepository(func(cfg config.TalosBackupConfig) (*backup.Repository, error) {
+		return backup.NewRepository(store, cfg.S3Bucket), nil
+	})
+}
+
+func newBackupTestCluster(retention string, keepLast int) *k8znerv1alpha1.K8znerCluster {
+	return &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			CredentialsRef: corev1.LocalObjectReference{Name: "test-credentials"},
+			Backup: &k8znerv1alpha1.BackupSpec{
+				Enabled:     true,
+				Retention:   retention,
+				KeepLast:    keepLast,
+				S3SecretRef: &k8znerv1alpha1.SecretReference{Name: "test-backup-s3"},
+			},
+		},
+	}
+}
+
+func TestReconcileBackupRetention(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		retention   string
+		keepLast    int
+		wantDeleted int
+	}{
+		{name: "prunes past retention", retention: "168h", keepLast: 3, wantDeleted: 3},
+		{name: "keeps the latest snapshots", retention: "1h", keepLast: 3, wantDeleted: 7},
+		{name: "defaults for unset fields", wantDeleted: 3},
+		{name: "nothing expired", retention: "720h", keepLast: 3, wantDeleted: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newBackupTestStore(now)
+			r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(store))
+			cluster := newBackupTestCluster(tt.retention, tt.keepLast)
+
+			r.reconcileBackupRetention(context.Background(), cluster)
+
+			assert.Len(t, store.deleted, tt.wantDeleted)
+			status := cluster.Status.Backup
+			require.NotNil(t, status)
+			assert.Equal(t, 10-tt.wantDeleted, status.BackupCount)
+			require.NotNil(t, status.LastBackupTime)
+			require.NotNil(t, status.OldestBackupTime)
+			assert.WithinDuration(t, now.Add(-24*time.Hour), status.LastBackupTime.Time, time.Second)
+			assert.WithinDuration(t, now.Add(-time.Duration(10-tt.wantDeleted)*24*time.Hour), status.OldestBackupTime.Time, time.Second)
+			assert.NotNil(t, status.LastPruneTime)
+		})
+	}
+}
+
+func TestReconcileBackupRetention_Throttled(t *testing.T) {
+	t.Parallel()
+	store := newBackupTestStore(time.Now())
+	r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(store))
+	cluster := newBackupTestCluster("1h", 1)
+	recent := metav1.NewTime(time.Now().Add(-time.Minute))
+	cluster.Status.Backup = &k8znerv1alpha1.BackupStatus{BackupCount: 10, LastPruneTime: &recent}
+
+	r.reconcileBackupRetention(context.Background(), cluster)
+
+	assert.Empty(t, store.deleted)
+	assert.Equal(t, 10, cluster.Status.Backup.BackupCount)
+}
+
+func TestReconcileBackupRetention_DeleteFailureKeepsSnapshotInStatus(t *testing.T) {
+	t.Parallel()
+	store := newBackupTestStore(time.Now())
+	store.deleteErr = errors.New("access denied")
+	r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(store))
+	recorder := r.Recorder.(*record.FakeRecorder)
+	cluster := newBackupTestCluster("168h", 3)
+
+	r.reconcileBackupRetention(context.Background(), cluster)
+
+	assert.Equal(t, 10, cluster.Status.Backup.BackupCount)
+	require.NotEmpty(t, recorder.Events)
+	assert.Contains(t, <-recorder.Events, EventReasonBackupPruneFailed)
+}
+
+func TestReconcileBackupRetention_Disabled(t *testing.T) {
+	t.Parallel()
+	store := newBackupTestStore(time.Now())
+	r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(store))
+	cluster := newBackupTestCluster("1h", 1)
+	cluster.Spec.Backup.Enabled = false
+	cluster.Status.Backup = &k8znerv1alpha1.BackupStatus{BackupCount: 10}
+
+	r.reconcileBackupRetention(context.Background(), cluster)
+
+	assert.Empty(t, store.deleted)
+	assert.Nil(t, cluster.Status.Backup)
+}
+
+func TestBackupStatus_IgnoresPreOperationSnapshots(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	snapshots := []backup.Snapshot{
+		{Key: backup.PreOperationKey(snapshotOpCPReplace, "cp-1", now.Add(-time.Hour)), CreatedAt: now.Add(-time.Hour)},
+		{Key: "etcd-backups/test-01.snap", CreatedAt: now.Add(-24 * time.Hour)},
+		{Key: "etcd-backups/test-02.snap", CreatedAt: now.Add(-48 * time.Hour)},
+		{Key: backup.PreOperationKey(snapshotOpCPScaleUp, "", now.Add(-72*time.Hour)), CreatedAt: now.Add(-72 * time.Hour)},
+	}
+
+	status := backupStatus(nil, snapshots, now)
+
+	assert.Equal(t, 2, status.BackupCount)
+	require.NotNil(t, status.LastBackupTime)
+	assert.True(t, status.LastBackupTime.Time.Equal(now.Add(-24*time.Hour)))
+	require.NotNil(t, status.OldestBackupTime)
+	assert.True(t, status.OldestBackupTime.Time.Equal(now.Add(-48*time.Hour)))
+}
@@ -0,0 +1,355 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/backup"
+	"github.com/milankappen/k8zner/internal/util/cron"
+	"github.com/milankappen/k8zner/internal/util/ptr"
+)
+
+const (
+	// defaultBackupVerifySchedule is used for clusters stored before the
+	// webhook defaulted spec.backup.verification.schedule.
+	defaultBackupVerifySchedule = "0 4 * * *"
+
+	// backupVerifyDeadline bounds a verification run; large snapshots take a
+	// while to download but a stuck run must not block the next one forever.
+	backupVerifyDeadline = 30 * time.Minute
+
+	// backupVerifyJobLabel marks verification Jobs with the cluster they verify.
+	backupVerifyJobLabel = "k8zner.io/backup-verification"
+
+	// backupVerifyIdentityDir is where the age identity Secret is mounted.
+	backupVerifyIdentityDir = "/var/run/secrets/k8zner.io/backup"
+
+	// backupVerifyUser is the nonroot user of the distroless operator image.
+	backupVerifyUser = 65532
+)
+
+// Reasons of the BackupVerified condition.
+const (
+	backupVerifiedReason          = "SnapshotVerified"
+	backupVerifyFailedReason      = "VerificationFailed"
+	backupVerifyUnavailableReason = "VerificationUnavailable"
+)
+
+// reconcileBackupVerification runs spec.backup.verification: on schedule it
+// starts a Job that downloads, decrypts and reads the latest snapshot, and once
+// the Job finishes it reports the result in the BackupVerified condition.
+// This is non-fatal — errors are logged but never returned.
+func (r *ClusterReconciler) reconcileBackupVerification(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) {
+	logger := log.FromContext(ctx)
+
+	spec := cluster.Spec.Backup
+	if spec == nil || !spec.Enabled || spec.Verification == nil || !spec.Verification.Enabled {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, k8znerv1alpha1.ConditionBackupVerified)
+		if cluster.Status.Backup != nil {
+			// The Job is owned by the cluster and cleaned up by its TTL
+			cluster.Status.Backup.VerificationJob = ""
+		}
+		return
+	}
+
+	if r.backupVerifyImage == "" {
+		setBackupVerifiedCondition(cluster, metav1.ConditionUnknown, backupVerifyUnavailableReason,
+			"The operator image is not configured; set OPERATOR_IMAGE on the operator Deployment")
+		return
+	}
+	if spec.S3SecretRef == nil || spec.S3SecretRef.Name == "" {
+		setBackupVerifiedCondition(cluster, metav1.ConditionUnknown, backupVerifyUnavailableReason,
+			"spec.backup.s3SecretRef is required to verify backups")
+		return
+	}
+
+	if cluster.Status.Backup == nil {
+		cluster.Status.Backup = &k8znerv1alpha1.BackupStatus{}
+	}
+	status := cluster.Status.Backup
+
+	if status.VerificationJob != "" {
+		r.collectBackupVerification(ctx, cluster)
+		return
+	}
+
+	now := time.Now()
+	due, err := backupVerificationDue(spec.Verification, status.LastVerificationTime, now)
+	if err != nil {
+		r.logAndRecordError(ctx, cluster, err, EventReasonBackupVerifyFailed, "Invalid backup verification schedule")
+		return
+	}
+	if !due {
+		return
+	}
+
+	job := r.buildBackupVerifyJob(cluster, now)
+	if err := controllerutil.SetControllerReference(cluster, job, r.Scheme); err != nil {
+		r.logAndRecordError(ctx, cluster, err, EventReasonBackupVerifyFailed, "Failed to create backup verification Job")
+		return
+	}
+	if err := r.Create(ctx, job); err != nil {
+		r.logAndRecordError(ctx, cluster, err, EventReasonBackupVerifyFailed, "Failed to create backup verification Job")
+		return
+	}
+
+	logger.Info("started backup verification", "job", job.Name)
+	status.VerificationJob = job.Name
+}
+
+// collectBackupVerification reads the result of the running verification Job
+// once it has finished, then deletes the Job.
+func (r *ClusterReconciler) collectBackupVerification(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) {
+	logger := log.FromContext(ctx)
+	status := cluster.Status.Backup
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: status.VerificationJob}, job)
+	if apierrors.IsNotFound(err) {
+		// Deleted behind our back; the next scheduled run starts a new one
+		logger.Info("backup verification Job disappeared", "job", status.VerificationJob)
+		status.VerificationJob = ""
+		return
+	}
+	if err != nil {
+		logger.Error(err, "failed to get backup verification Job", "job", status.VerificationJob)
+		return
+	}
+
+	succeeded, failed := jobFinished(job)
+	if !succeeded && !failed {
+		return
+	}
+
+	result := r.backupVerificationResult(ctx, job)
+	if failed && result.Error == "" {
+		result.Error = "verification Job failed without reporting a result"
+		if msg := jobFailureMessage(job); msg != "" {
+			result.Error += ": " + msg
+		}
+	}
+	verified := succeeded && result.Error == ""
+
+	now := time.Now()
+	finished := metav1.NewTime(now)
+	status.LastVerificationTime = &finished
+	r.recordBackupVerification(cluster.Name, verified, now)
+
+	if verified {
+		status.LastVerifiedSnapshot = result.Snapshot
+		msg := fmt.Sprintf("Snapshot %s decrypted and read: revision %d, %d keys", result.Snapshot, result.Revision, result.TotalKeys)
+		setBackupVerifiedCondition(cluster, metav1.ConditionTrue, backupVerifiedReason, msg)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, EventReasonBackupVerified, msg)
+		logger.Info("backup verified", "snapshot", result.Snapshot, "revision", result.Revision)
+	} else {
+		setBackupVerifiedCondition(cluster, metav1.ConditionFalse, backupVerifyFailedReason, result.Error)
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, EventReasonBackupVerifyFailed, result.Error)
+		logger.Info("backup verification failed", "snapshot", result.Snapshot, "error", result.Error)
+	}
+
+	if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+		// The TTL removes it eventually
+		logger.Error(err, "failed to delete backup verification Job", "job", job.Name)
+	}
+	status.VerificationJob = ""
+}
+
+// backupVerificationResult reads the result the verification pod wrote as its
+// termination message. Without a parsable message the pod's output is returned
+// as the error.
+func (r *ClusterReconciler) backupVerificationResult(ctx context.Context, job *batchv1.Job) backup.Verification {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
+		return backup.Verification{Error: fmt.Sprintf("failed to list verification pods: %v", err)}
+	}
+
+	for _, pod := range pods.Items {
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Terminated == nil || cs.State.Terminated.Message == "" {
+				continue
+			}
+			message := strings.TrimSpace(cs.State.Terminated.Message)
+			var result backup.Verification
+			if err := json.Unmarshal([]byte(message), &result); err != nil {
+				return backup.Verification{Error: message}
+			}
+			return result
+		}
+	}
+	return backup.Verification{}
+}
+
+// backupVerificationDue reports whether a verification run is due: immediately
+// if none has run yet, otherwise once the schedule fires after the last run.
+func backupVerificationDue(spec *k8znerv1alpha1.BackupVerificationSpec, last *metav1.Time, now time.Time) (bool, error) {
+	expr := spec.Schedule
+	if expr == "" {
+		expr = defaultBackupVerifySchedule
+	}
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		return false, fmt.Errorf("invalid schedule %q: %w", expr, err)
+	}
+
+	if last == nil {
+		return true, nil
+	}
+	next := schedule.Next(last.UTC())
+	return !next.IsZero() && !now.Before(next), nil
+}
+
+// buildBackupVerifyJob returns the Job that verifies cluster's latest snapshot
+// with the operator image. The S3 settings reach it in the same environment
+// variables the talos-backup CronJob uses.
+func (r *ClusterReconciler) buildBackupVerifyJob(cluster *k8znerv1alpha1.K8znerCluster, now time.Time) *batchv1.Job {
+	spec := cluster.Spec.Backup
+	s3Secret := spec.S3SecretRef.Name
+
+	env := []corev1.EnvVar{
+		secretEnv("AWS_ACCESS_KEY_ID", s3Secret, "access-key", false),
+		secretEnv("AWS_SECRET_ACCESS_KEY", s3Secret, "secret-key", false),
+		secretEnv("CUSTOM_S3_ENDPOINT", s3Secret, "endpoint", false),
+		secretEnv("BUCKET", s3Secret, "bucket", false),
+		secretEnv("AWS_REGION", s3Secret, "region", true),
+	}
+	volumes := []corev1.Volume{
+		{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	}
+	mounts := []corev1.VolumeMount{
+		{Name: "tmp", MountPath: "/tmp"},
+	}
+
+	if ref := spec.Verification.IdentitySecretRef; ref != nil && ref.Name != "" {
+		env = append(env, corev1.EnvVar{
+			Name:  "AGE_IDENTITY_FILE",
+			Value: backupVerifyIdentityDir + "/" + k8znerv1alpha1.BackupIdentityKey,
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name: "age-identity",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: ref.Name,
+				Items:      []corev1.KeyToPath{{Key: k8znerv1alpha1.BackupIdentityKey, Path: k8znerv1alpha1.BackupIdentityKey}},
+			}},
+		})
+		mounts = append(mounts, corev1.VolumeMount{Name: "age-identity", MountPath: backupVerifyIdentityDir, ReadOnly: true})
+	}
+
+	container := corev1.Container{
+		Name:                     "verify-backup",
+		Image:                    r.backupVerifyImage,
+		Args:                     []string{"verify-backup"},
+		Env:                      env,
+		VolumeMounts:             mounts,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+			// The snapshot is held in memory while it is decrypted
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:                ptr.Int64(backupVerifyUser),
+			RunAsGroup:               ptr.Int64(backupVerifyUser),
+			RunAsNonRoot:             ptr.Bool(true),
+			AllowPrivilegeEscalation: ptr.Bool(false),
+			ReadOnlyRootFilesystem:   ptr.Bool(true),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		},
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-backup-verify-%d", cluster.Name, now.Unix()),
+			Namespace: cluster.Namespace,
+			Labels:    map[string]string{backupVerifyJobLabel: cluster.Name},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.Int32(0),
+			ActiveDeadlineSeconds:   ptr.Int64(int64(backupVerifyDeadline.Seconds())),
+			TTLSecondsAfterFinished: ptr.Int32(int32(24 * time.Hour / time.Second)),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{backupVerifyJobLabel: cluster.Name},
+				},
+				Spec: corev1.PodSpec{
+					Containers:                   []corev1.Container{container},
+					Volumes:                      volumes,
+					RestartPolicy:                corev1.RestartPolicyNever,
+					AutomountServiceAccountToken: ptr.Bool(false),
+					// Same placement as the talos-backup CronJob
+					Tolerations: []corev1.Toleration{
+						{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+						{Key: "node.cloudprovider.kubernetes.io/uninitialized", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+					},
+				},
+			},
+		},
+	}
+}
+
+// secretEnv returns an environment variable read from key of the named Secret.
+func secretEnv(name, secret, key string, optional bool) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+			Key:                  key,
+			Optional:             ptr.Bool(optional),
+		}},
+	}
+}
+
+// jobFinished reports whether job has completed or failed.
+func jobFinished(job *batchv1.Job) (succeeded, failed bool) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			succeeded = true
+		case batchv1.JobFailed:
+			failed = true
+		}
+	}
+	return succeeded, failed
+}
+
+// jobFailureMessage returns the message of job's Failed condition.
+func jobFailureMessage(job *batchv1.Job) string {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return c.Message
+		}
+	}
+	return ""
+}
+
+// setBackupVerifiedCondition sets the BackupVerified condition.
+func setBackupVerifiedCondition(cluster *k8znerv1alpha1.K8znerCluster, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:    k8znerv1alpha1.ConditionBackupVerified,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+)
+
+const testVerifyImage = "ghcr.io/milankappen/k8zner-operator:test"
+
+// newVerifyTestCluster returns a backup test cluster with verification enabled.
+func newVerifyTestCluster() *k8znerv1alpha1.K8znerCluster {
+	cluster := newBackupTestCluster("168h", 3)
+	cluster.UID = "test-uid"
+	cluster.Spec.Backup.Verification = &k8znerv1alpha1.BackupVerificationSpec{
+		Enabled:           true,
+		Schedule:          "0 4 * * *",
+		IdentitySecretRef: &k8znerv1alpha1.SecretReference{Name: "test-backup-identity"},
+	}
+	return cluster
+}
+
+// finishedVerifyJob creates a finished verification Job and its pod with the given termination message.
+func finishedVerifyJob(t *testing.T, r *ClusterReconciler, name string, condition batchv1.JobConditionType, message string) {
+	t.Helper()
+	ctx := context.Background()
+	jobCondition := batchv1.JobCondition{Type: condition, Status: corev1.ConditionTrue}
+	if condition == batchv1.JobFailed {
+		jobCondition.Message = "BackoffLimitExceeded"
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{jobCondition}},
+	}
+	require.NoError(t, r.Create(ctx, job))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-abcde", Namespace: "default", Labels: map[string]string{"job-name": name}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "verify-backup",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+		}}},
+	}
+	require.NoError(t, r.Create(ctx, pod))
+}
+
+func TestReconcileBackupVerification_StartsJob(t *testing.T) {
+	t.Parallel()
+	r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(newBackupTestStore(time.Now())))
+	r.backupVerifyImage = testVerifyImage
+	cluster := newVerifyTestCluster()
+
+	r.reconcileBackupVerification(context.Background(), cluster)
+
+	require.NotNil(t, cluster.Status.Backup)
+	name := cluster.Status.Backup.VerificationJob
+	require.NotEmpty(t, name)
+
+	job := &batchv1.Job{}
+	require.NoError(t, r.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: name}, job))
+	require.Len(t, job.OwnerReferences, 1)
+	assert.Equal(t, "test-cluster", job.OwnerReferences[0].Name)
+
+	pod := job.Spec.Template.Spec
+	require.Len(t, pod.Containers, 1)
+	container := pod.Containers[0]
+	assert.Equal(t, testVerifyImage, container.Image)
+	assert.Equal(t, []string{"verify-backup"}, container.Args)
+	assert.Equal(t, corev1.RestartPolicyNever, pod.RestartPolicy)
+
+	env := make(map[string]corev1.EnvVar)
+	for _, e := range container.Env {
+		env[e.Name] = e
+	}
+	require.Contains(t, env, "BUCKET")
+	assert.Equal(t, "test-backup-s3", env["BUCKET"].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, "bucket", env["BUCKET"].ValueFrom.SecretKeyRef.Key)
+	assert.Equal(t, "/var/run/secrets/k8zner.io/backup/identity", env["AGE_IDENTITY_FILE"].Value)
+
+	// The next pass waits for the Job instead of starting another one
+	r.reconcileBackupVerification(context.Background(), cluster)
+	jobs := &batchv1.JobList{}
+	require.NoError(t, r.List(context.Background(), jobs))
+	assert.Len(t, jobs.Items, 1)
+	assert.Equal(t, name, cluster.Status.Backup.VerificationJob)
+}
+
+func TestReconcileBackupVerification_NotDue(t *testing.T) {
+	t.Parallel()
+	r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(newBackupTestStore(time.Now())))
+	r.backupVerifyImage = testVerifyImage
+	cluster := newVerifyTestCluster()
+	// Only fires at midnight on New Year's Day
+	cluster.Spec.Backup.Verification.Schedule = "0 0 1 1 *"
+	last := metav1.NewTime(time.Now().Add(-time.Hour))
+	cluster.Status.Backup = &k8znerv1alpha1.BackupStatus{LastVerificationTime: &last}
+
+	r.reconcileBackupVerification(context.Background(), cluster)
+
+	assert.Empty(t, cluster.Status.Backup.VerificationJob)
+	jobs := &batchv1.JobList{}
+	require.NoError(t, r.List(context.Background(), jobs))
+	assert.Empty(t, jobs.Items)
+}
+
+func TestReconcileBackupVerification_Verified(t *testing.T) {
+	t.Parallel()
+	r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(newBackupTestStore(time.Now())))
+	recorder := r.Recorder.(*record.FakeRecorder)
+	r.backupVerifyImage = testVerifyImage
+	cluster := newVerifyTestCluster()
+	cluster.Status.Backup = &k8znerv1alpha1.BackupStatus{VerificationJob: "test-cluster-backup-verify-1"}
+	finishedVerifyJob(t, r, "test-cluster-backup-verify-1", batchv1.JobComplete,
+		`{"snapshot":"etcd-backups/test-01.snap","revision":42,"totalKeys":43,"hashVerified":true}`)
+
+	r.reconcileBackupVerification(context.Background(), cluster)
+
+	status := cluster.Status.Backup
+	assert.Empty(t, status.VerificationJob)
+	assert.Equal(t, "etcd-backups/test-01.snap", status.LastVerifiedSnapshot)
+	assert.NotNil(t, status.LastVerificationTime)
+
+	cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionBackupVerified)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, backupVerifiedReason, cond.Reason)
+	assert.Contains(t, cond.Message, "revision 42")
+	assert.Contains(t, <-recorder.Events, EventReasonBackupVerified)
+
+	err := r.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "test-cluster-backup-verify-1"}, &batchv1.Job{})
+	assert.True(t, apierrors.IsNotFound(err), "finished Job is deleted")
+}
+
+func TestReconcileBackupVerification_Failed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message string
+		wantMsg string
+	}{
+		{
+			name:    "result reports an error",
+			message: `{"snapshot":"etcd-backups/test-01.snap","error":"snapshot etcd-backups/test-01.snap is not a readable etcd database: snapshot is truncated"}`,
+			wantMsg: "snapshot is truncated",
+		},
+		{
+			name:    "logs instead of a result",
+			message: "exec format error",
+			wantMsg: "exec format error",
+		},
+		{
+			name:    "no result",
+			wantMsg: "BackoffLimitExceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(newBackupTestStore(time.Now())))
+			recorder := r.Recorder.(*record.FakeRecorder)
+			r.backupVerifyImage = testVerifyImage
+			cluster := newVerifyTestCluster()
+			verified := metav1.NewTime(time.Now().Add(-24 * time.Hour))
+			cluster.Status.Backup = &k8znerv1alpha1.BackupStatus{
+				LastVerificationTime: &verified,
+				LastVerifiedSnapshot: "etcd-backups/test-02.snap",
+				VerificationJob:      "test-cluster-backup-verify-1",
+			}
+			finishedVerifyJob(t, r, "test-cluster-backup-verify-1", batchv1.JobFailed, tt.message)
+
+			r.reconcileBackupVerification(context.Background(), cluster)
+
+			status := cluster.Status.Backup
+			assert.Empty(t, status.VerificationJob)
+			assert.Equal(t, "etcd-backups/test-02.snap", status.LastVerifiedSnapshot, "keeps the last good snapshot")
+			assert.True(t, status.LastVerificationTime.After(verified.Time))
+
+			cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionBackupVerified)
+			require.NotNil(t, cond)
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			assert.Contains(t, cond.Message, tt.wantMsg)
+			assert.Contains(t, <-recorder.Events, EventReasonBackupVerifyFailed)
+		})
+	}
+}
+
+func TestReconcileBackupVerification_Unavailable(t *testing.T) {
+	t.Parallel()
+	r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(newBackupTestStore(time.Now())))
+	cluster := newVerifyTestCluster()
+
+	r.reconcileBackupVerification(context.Background(), cluster)
+
+	cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionBackupVerified)
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionUnknown, cond.Status)
+	assert.Contains(t, cond.Message, "OPERATOR_IMAGE")
+}
+
+func TestReconcileBackupVerification_Disabled(t *testing.T) {
+	t.Parallel()
+	r := newTestReconciler(t, newBackupTestSecrets(), withBackupStore(newBackupTestStore(time.Now())))
+	r.backupVerifyImage = testVerifyImage
+	cluster := newVerifyTestCluster()
+	cluster.Spec.Backup.Verification.Enabled = false
+	setBackupVerifiedCondition(cluster, metav1.ConditionTrue, backupVerifiedReason, "verified")
+
+	r.reconcileBackupVerification(context.Background(), cluster)
+
+	assert.Nil(t, meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionBackupVerified))
+	jobs := &batchv1.JobList{}
+	require.NoError(t, r.List(context.Background(), jobs))
+	assert.Empty(t, jobs.Items)
+}
+
+func TestBackupVerificationDue(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	at := func(tm time.Time) *metav1.Time {
+		mt := metav1.NewTime(tm)
+		return &mt
+	}
+
+	tests := []struct {
+		name     string
+		schedule string
+		last     *metav1.Time
+		want     bool
+		wantErr  bool
+	}{
+		{name: "never verified", schedule: "0 4 * * *", want: true},
+		{name: "verified today", schedule: "0 4 * * *", last: at(now.Add(-7 * time.Hour)), want: false},
+		{name: "verified yesterday", schedule: "0 4 * * *", last: at(now.Add(-30 * time.Hour)), want: true},
+		{name: "default schedule", last: at(now.Add(-7 * time.Hour)), want: false},
+		{name: "invalid schedule", schedule: "daily", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			due, err := backupVerificationDue(&k8znerv1alpha1.BackupVerificationSpec{Schedule: tt.schedule}, tt.last, now)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, due)
+		})
+	}
+}
+
+func TestBackupStatus_KeepsVerification(t *testing.T) {
+	t.Parallel()
+	verified := metav1.NewTime(time.Now().Add(-time.Hour))
+	prev := &k8znerv1alpha1.BackupStatus{
+		BackupCount:          10,
+		LastVerificationTime: &verified,
+		LastVerifiedSnapshot: "etcd-backups/test-01.snap",
+		VerificationJob:      "test-cluster-backup-verify-1",
+	}
+
+	status := backupStatus(prev, nil, time.Now())
+
+	assert.Equal(t, 0, status.BackupCount)
+	assert.Nil(t, status.LastBackupTime)
+	assert.Equal(t, &verified, status.LastVerificationTime)
+	assert.Equal(t, "etcd-backups/test-01.snap", status.LastVerifiedSnapshot)
+	assert.Equal(t, "test-cluster-backup-verify-1", status.VerificationJob)
+}
@@ -0,0 +1,301 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	operatorprov "github.com/milankappen/k8zner/internal/operator/provisioning"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+)
+
+// caRotationSecretAnnotation records on the credentials Secret which step of
+// which rotation its content belongs to, so each step rewrites it only once.
+const caRotationSecretAnnotation = "k8zner.io/ca-rotation"
+
+// caRotationSteps describes the steps of a CA rotation by phase.
+var caRotationSteps = map[k8znerv1alpha1.CARotationPhase]struct {
+	reason  string
+	message string
+	next    k8znerv1alpha1.CARotationPhase
+}{
+	k8znerv1alpha1.CARotationAcceptNewCA: {"AcceptingNewCA", "Nodes accept the new CAs next to the current ones", k8znerv1alpha1.CARotationSwitchCA},
+	k8znerv1alpha1.CARotationSwitchCA:    {"SwitchingCA", "Nodes switch to the new CAs and still accept the old ones", k8znerv1alpha1.CARotationDropOldCA},
+	k8znerv1alpha1.CARotationDropOldCA:   {"DroppingOldCA", "Nodes stop accepting the old CAs", k8znerv1alpha1.CARotationComplete},
+}
+
+// reconcileCARotation rotates the Talos API and Kubernetes CAs when the
+// k8zner.io/rotate-ca annotation changes. It follows the Talos multi-CA
+// procedure in three steps: nodes accept the new CAs next to the old ones,
+// switch to the new CAs while still accepting the old ones, and finally drop
+// the old ones. Each step rewrites the credentials Secret, which changes the
+// desired machine configs; reconcileMachineConfig rolls them out and the next
+// step starts once every node runs them. The re-issued talosconfig and admin
+// kubeconfig are left in the credentials Secret for the CLI to pick up.
+func (r *ClusterReconciler) reconcileCARotation(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	rotation := cluster.Status.CARotation
+	if rotation == nil || rotation.Phase == k8znerv1alpha1.CARotationComplete {
+		request := cluster.Annotations[k8znerv1alpha1.RotateCAAnnotation]
+		if request == "" || (rotation != nil && rotation.Request == request) {
+			return ctrl.Result{}, nil
+		}
+		return r.startCARotation(ctx, cluster, request)
+	}
+
+	step, ok := caRotationSteps[rotation.Phase]
+	if !ok {
+		setCARotationCondition(cluster, metav1.ConditionFalse, "InvalidPhase",
+			fmt.Sprintf("Unknown CA rotation phase %q", rotation.Phase))
+		return ctrl.Result{}, nil
+	}
+
+	written, err := r.writeCARotationCredentials(ctx, cluster, rotation)
+	if err != nil {
+		r.logAndRecordError(ctx, cluster, err, EventReasonCARotationFailed,
+			fmt.Sprintf("CA rotation step %s failed", rotation.Phase))
+		setCARotationCondition(cluster, metav1.ConditionFalse, "CredentialsUpdateFailed", err.Error())
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+	if written {
+		// Compare against the new configs once the cache has caught up with the Secret
+		logger.Info("updated credentials for CA rotation", "phase", rotation.Phase, "request", rotation.Request)
+		setCARotationCondition(cluster, metav1.ConditionFalse, step.reason, step.message)
+		return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+	}
+
+	tc := r.loadTalosClients(ctx, cluster)
+	if tc.configGen == nil {
+		setCARotationCondition(cluster, metav1.ConditionFalse, "NoCredentials",
+			"Cannot generate machine configs without Talos credentials")
+		return ctrl.Result{}, nil
+	}
+
+	pending, err := nodesPendingCARotation(cluster, tc)
+	if err != nil {
+		setCARotationCondition(cluster, metav1.ConditionFalse, "GenerateFailed", err.Error())
+		return ctrl.Result{}, nil
+	}
+	if pending > 0 {
+		// The machine config rollout further down this reconcile applies the step
+		setCARotationCondition(cluster, metav1.ConditionFalse, step.reason,
+			fmt.Sprintf("%s; waiting for %d nodes to run the new machine config", step.message, pending))
+		return ctrl.Result{}, nil
+	}
+
+	rotation.Phase = step.next
+	if rotation.Phase == k8znerv1alpha1.CARotationComplete {
+		now := metav1.Now()
+		rotation.CompletedAt = &now
+		setCARotationCondition(cluster, metav1.ConditionTrue, "Rotated",
+			"Talos API and Kubernetes CAs rotated; the old CAs are no longer accepted")
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, EventReasonCARotated,
+			"Talos API and Kubernetes CAs rotated")
+		logger.Info("CA rotation complete", "request", rotation.Request)
+		return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+	}
+
+	next := caRotationSteps[rotation.Phase]
+	setCARotationCondition(cluster, metav1.ConditionFalse, next.reason, next.message)
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonCARotating, "CA rotation: %s", next.message)
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// startCARotation begins a requested CA rotation once the cluster is healthy,
+// no version upgrade is running and an etcd snapshot has been taken.
+func (r *ClusterReconciler) startCARotation(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, request string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	for _, condType := range []string{k8znerv1alpha1.ConditionTalosUpgrade, k8znerv1alpha1.ConditionKubernetesUpgrade} {
+		if cond := meta.FindStatusCondition(cluster.Status.Conditions, condType); cond != nil && cond.Status == metav1.ConditionFalse {
+			setCARotationCondition(cluster, metav1.ConditionFalse, "WaitingForUpgrade",
+				"CA rotation requested; waiting for the running upgrade to finish")
+			return ctrl.Result{}, nil
+		}
+	}
+
+	if cluster.Status.ControlPlanes.Ready < cluster.Spec.ControlPlanes.Count ||
+		cluster.Status.Workers.Ready < desiredWorkerCount(cluster) {
+		setCARotationCondition(cluster, metav1.ConditionFalse, "WaitingForHealthyNodes",
+			"CA rotation requested; waiting until all nodes are healthy")
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	tc := r.loadTalosClients(ctx, cluster)
+	if tc.configGen == nil {
+		setCARotationCondition(cluster, metav1.ConditionFalse, "NoCredentials",
+			"CA rotation requested, but there are no Talos credentials")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.takePreOperationSnapshot(ctx, cluster, tc, snapshotOpCARotation, ""); err != nil {
+		setCARotationCondition(cluster, metav1.ConditionFalse, "SnapshotFailed", err.Error())
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	now := metav1.Now()
+	cluster.Status.CARotation = &k8znerv1alpha1.CARotationStatus{
+		Phase:     k8znerv1alpha1.CARotationAcceptNewCA,
+		Request:   request,
+		StartedAt: &now,
+	}
+
+	step := caRotationSteps[k8znerv1alpha1.CARotationAcceptNewCA]
+	setCARotationCondition(cluster, metav1.ConditionFalse, step.reason, step.message)
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonCARotating,
+		"Rotating Talos API and Kubernetes CAs (request %s)", request)
+	logger.Info("starting CA rotation", "request", request)
+
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// writeCARotationCredentials updates the credentials Secret for the current
+// rotation step and reports whether it changed anything:
+//   - AcceptNewCA stores a bundle with new CAs as the accepted secrets.
+//   - SwitchCA swaps the current and accepted secrets and re-issues the talosconfig.
+//   - DropOldCA fetches an admin kubeconfig signed by the new Kubernetes CA,
+//     removes the accepted secrets and re-issues the talosconfig.
+func (r *ClusterReconciler) writeCARotationCredentials(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, rotation *k8znerv1alpha1.CARotationStatus) (bool, error) {
+	if cluster.Spec.CredentialsRef.Name == "" {
+		return false, errors.New("credentialsRef.name is not set")
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.CredentialsRef.Name}
+	if err := r.Get(ctx, key, secret); err != nil {
+		return false, fmt.Errorf("failed to get credentials secret %s: %w", key.Name, err)
+	}
+
+	marker := rotation.Request + "/" + string(rotation.Phase)
+	if secret.Annotations[caRotationSecretAnnotation] == marker {
+		return false, nil
+	}
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+
+	switch rotation.Phase {
+	case k8znerv1alpha1.CARotationAcceptNewCA:
+		current, err := talos.ParseSecrets(secret.Data[k8znerv1alpha1.CredentialsKeyTalosSecrets])
+		if err != nil {
+			return false, fmt.Errorf("failed to parse talos secrets: %w", err)
+		}
+		rotated, err := talos.RotateCAs(current, cluster.Spec.Talos.Version)
+		if err != nil {
+			return false, fmt.Errorf("failed to generate new CAs: %w", err)
+		}
+		data, err := talos.MarshalSecrets(rotated)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal talos secrets: %w", err)
+		}
+		secret.Data[k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets] = data
+
+	case k8znerv1alpha1.CARotationSwitchCA:
+		accepted := secret.Data[k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets]
+		if len(accepted) == 0 {
+			return false, fmt.Errorf("credentials secret has no %s key", k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets)
+		}
+		secret.Data[k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets] = secret.Data[k8znerv1alpha1.CredentialsKeyTalosSecrets]
+		secret.Data[k8znerv1alpha1.CredentialsKeyTalosSecrets] = accepted
+		if err := r.issueTalosConfig(cluster, secret); err != nil {
+			return false, err
+		}
+
+	case k8znerv1alpha1.CARotationDropOldCA:
+		kubeconfig, err := r.issueKubeconfig(ctx, cluster)
+		if err != nil {
+			return false, err
+		}
+		secret.Data[k8znerv1alpha1.CredentialsKeyKubeconfig] = kubeconfig
+		delete(secret.Data, k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets)
+		if err := r.issueTalosConfig(cluster, secret); err != nil {
+			return false, err
+		}
+	}
+
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string)
+	}
+	secret.Annotations[caRotationSecretAnnotation] = marker
+	if err := r.Update(ctx, secret); err != nil {
+		return false, fmt.Errorf("failed to update credentials secret %s: %w", key.Name, err)
+	}
+	return true, nil
+}
+
+// issueTalosConfig replaces the talosconfig in secret with one issued from its
+// Talos secrets. While accepted secrets are present it trusts both CAs.
+func (r *ClusterReconciler) issueTalosConfig(cluster *k8znerv1alpha1.K8znerCluster, secret *corev1.Secret) error {
+	generator, err := r.phaseAdapter.CreateTalosGenerator(cluster, &operatorprov.Credentials{
+		TalosSecrets:         secret.Data[k8znerv1alpha1.CredentialsKeyTalosSecrets],
+		TalosAcceptedSecrets: secret.Data[k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets],
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create talos generator: %w", err)
+	}
+	talosconfig, err := generator.GetClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to generate talosconfig: %w", err)
+	}
+	secret.Data[k8znerv1alpha1.CredentialsKeyTalosConfig] = talosconfig
+	return nil
+}
+
+// issueKubeconfig fetches an admin kubeconfig from a healthy control plane.
+func (r *ClusterReconciler) issueKubeconfig(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) ([]byte, error) {
+	tc := r.loadTalosClients(ctx, cluster)
+	if tc.client == nil {
+		return nil, errors.New("no Talos credentials to fetch the kubeconfig with")
+	}
+	nodeIP := findPeerControlPlaneIP(cluster, "")
+	if nodeIP == "" {
+		return nil, errors.New("no healthy control plane to fetch the kubeconfig from")
+	}
+	kubeconfig, err := tc.client.Kubeconfig(ctx, nodeIP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch kubeconfig from %s: %w", nodeIP, err)
+	}
+	return kubeconfig, nil
+}
+
+// nodesPendingCARotation counts the nodes that do not run their desired
+// machine config yet. Unlike drift detection, a staged config does not count:
+// the next rotation step is only safe once nodes actually use the new CAs.
+func nodesPendingCARotation(cluster *k8znerv1alpha1.K8znerCluster, tc talosClients) (int, error) {
+	pending := 0
+	for _, role := range []string{"control-plane", "worker"} {
+		desired, err := desiredMachineConfigs(cluster, tc, role)
+		if err != nil {
+			return 0, err
+		}
+		nodes := cluster.Status.Workers.Nodes
+		if role == "control-plane" {
+			nodes = cluster.Status.ControlPlanes.Nodes
+		}
+		for _, node := range nodes {
+			if want, ok := desired[node.Name]; ok && node.ConfigHash != want.hash {
+				pending++
+			}
+		}
+	}
+	return pending, nil
+}
+
+// setCARotationCondition sets the CARotation condition.
+func setCARotationCondition(cluster *k8znerv1alpha1.K8znerCluster, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:    k8znerv1alpha1.ConditionCARotation,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
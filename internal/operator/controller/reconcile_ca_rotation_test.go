@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+)
+
+// newCARotationTestCluster returns a healthy cluster that requested CA rotation
+// r1, its credentials Secret with real Talos secrets, and a config generator
+// its nodes are in sync with.
+func newCARotationTestCluster(t *testing.T) (*k8znerv1alpha1.K8znerCluster, *corev1.Secret, *MockTalosConfigGenerator) {
+	t.Helper()
+	gen := newConfigDriftTestGen("1")
+	cluster := newConfigDriftTestCluster(t, gen, 1, 1)
+	cluster.Annotations = map[string]string{k8znerv1alpha1.RotateCAAnnotation: "r1"}
+	cluster.Spec.CredentialsRef.Name = "creds"
+	cluster.Spec.Talos.Version = "v1.7.0"
+	cluster.Spec.Kubernetes.Version = "v1.30.0"
+
+	sb, err := talos.NewSecrets("v1.7.0")
+	require.NoError(t, err)
+	secretsData, err := talos.MarshalSecrets(sb)
+	require.NoError(t, err)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "default"},
+		Data: map[string][]byte{
+			k8znerv1alpha1.CredentialsKeyHCloudToken:  []byte("token"),
+			k8znerv1alpha1.CredentialsKeyTalosSecrets: secretsData,
+			k8znerv1alpha1.CredentialsKeyTalosConfig:  []byte("old-talosconfig"),
+		},
+	}
+	return cluster, secret, gen
+}
+
+func getCredentialsSecret(t *testing.T, r *ClusterReconciler) *corev1.Secret {
+	t.Helper()
+	secret := &corev1.Secret{}
+	require.NoError(t, r.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "creds"}, secret))
+	return secret
+}
+
+func TestReconcileCARotation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("walks through all rotation steps", func(t *testing.T) {
+		t.Parallel()
+		cluster, secret, gen := newCARotationTestCluster(t)
+		r := newTestReconciler(t, []client.Object{cluster, secret}, WithTalosConfigGenerator(gen))
+		ctx := context.Background()
+		original := getCredentialsSecret(t, r).Data[k8znerv1alpha1.CredentialsKeyTalosSecrets]
+
+		step := func() {
+			t.Helper()
+			result, err := r.reconcileCARotation(ctx, cluster)
+			require.NoError(t, err)
+			assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+		}
+
+		// Start
+		step()
+		require.NotNil(t, cluster.Status.CARotation)
+		assert.Equal(t, k8znerv1alpha1.CARotationAcceptNewCA, cluster.Status.CARotation.Phase)
+		assert.Equal(t, "r1", cluster.Status.CARotation.Request)
+		assert.NotNil(t, cluster.Status.CARotation.StartedAt)
+
+		// Nodes are told to accept the new CAs
+		step()
+		secret = getCredentialsSecret(t, r)
+		newSecrets := secret.Data[k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets]
+		require.NotEmpty(t, newSecrets)
+		assert.Equal(t, original, secret.Data[k8znerv1alpha1.CredentialsKeyTalosSecrets])
+		assert.Equal(t, "r1/AcceptNewCA", secret.Annotations[caRotationSecretAnnotation])
+
+		// All nodes run their desired config, so the rotation moves on
+		step()
+		assert.Equal(t, k8znerv1alpha1.CARotationSwitchCA, cluster.Status.CARotation.Phase)
+
+		// Nodes switch to the new CAs and keep accepting the old ones
+		step()
+		secret = getCredentialsSecret(t, r)
+		assert.Equal(t, newSecrets, secret.Data[k8znerv1alpha1.CredentialsKeyTalosSecrets])
+		assert.Equal(t, original, secret.Data[k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets])
+		assert.Contains(t, string(secret.Data[k8znerv1alpha1.CredentialsKeyTalosConfig]), "contexts:")
+
+		step()
+		assert.Equal(t, k8znerv1alpha1.CARotationDropOldCA, cluster.Status.CARotation.Phase)
+
+		// The old CAs are dropped and a new kubeconfig is issued
+		step()
+		secret = getCredentialsSecret(t, r)
+		assert.Equal(t, newSecrets, secret.Data[k8znerv1alpha1.CredentialsKeyTalosSecrets])
+		assert.NotContains(t, secret.Data, k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets)
+		assert.Equal(t, "mock-kubeconfig", string(secret.Data[k8znerv1alpha1.CredentialsKeyKubeconfig]))
+		assert.Equal(t, []string{"10.0.1.1"}, r.talosClient.(*MockTalosClient).KubeconfigCalls)
+
+		step()
+		assert.Equal(t, k8znerv1alpha1.CARotationComplete, cluster.Status.CARotation.Phase)
+		assert.NotNil(t, cluster.Status.CARotation.CompletedAt)
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionCARotation)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionTrue, cond.Status)
+
+		// The same request is not handled twice
+		result, err := r.reconcileCARotation(ctx, cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Equal(t, k8znerv1alpha1.CARotationComplete, cluster.Status.CARotation.Phase)
+	})
+
+	t.Run("waits for nodes to run the new config", func(t *testing.T) {
+		t.Parallel()
+		cluster, secret, gen := newCARotationTestCluster(t)
+		r := newTestReconciler(t, []client.Object{cluster, secret}, WithTalosConfigGenerator(gen))
+		cluster.Status.CARotation = &k8znerv1alpha1.CARotationStatus{
+			Phase:   k8znerv1alpha1.CARotationAcceptNewCA,
+			Request: "r1",
+		}
+		ctx := context.Background()
+
+		_, err := r.reconcileCARotation(ctx, cluster)
+		require.NoError(t, err)
+
+		// A staged config does not count, the node has to use it
+		cluster.Status.Workers.Nodes[0].StagedConfigHash = cluster.Status.Workers.Nodes[0].ConfigHash
+		cluster.Status.Workers.Nodes[0].ConfigHash = "stale"
+		result, err := r.reconcileCARotation(ctx, cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter, "the machine config rollout runs in the same pass")
+		assert.Equal(t, k8znerv1alpha1.CARotationAcceptNewCA, cluster.Status.CARotation.Phase)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionCARotation)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Contains(t, cond.Message, "waiting for 1 nodes")
+	})
+
+	t.Run("does not start during an upgrade", func(t *testing.T) {
+		t.Parallel()
+		cluster, secret, gen := newCARotationTestCluster(t)
+		r := newTestReconciler(t, []client.Object{cluster, secret}, WithTalosConfigGenerator(gen))
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:   k8znerv1alpha1.ConditionTalosUpgrade,
+			Status: metav1.ConditionFalse,
+			Reason: "Upgrading",
+		})
+
+		_, err := r.reconcileCARotation(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Nil(t, cluster.Status.CARotation)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionCARotation)
+		require.NotNil(t, cond)
+		assert.Equal(t, "WaitingForUpgrade", cond.Reason)
+	})
+
+	t.Run("does not start on a degraded cluster", func(t *testing.T) {
+		t.Parallel()
+		cluster, secret, gen := newCARotationTestCluster(t)
+		r := newTestReconciler(t, []client.Object{cluster, secret}, WithTalosConfigGenerator(gen))
+		cluster.Status.Workers.Ready = 0
+
+		result, err := r.reconcileCARotation(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Nil(t, cluster.Status.CARotation)
+	})
+
+	t.Run("does nothing without a request", func(t *testing.T) {
+		t.Parallel()
+		cluster, secret, gen := newCARotationTestCluster(t)
+		r := newTestReconciler(t, []client.Object{cluster, secret}, WithTalosConfigGenerator(gen))
+		cluster.Annotations = nil
+
+		result, err := r.reconcileCARotation(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Nil(t, cluster.Status.CARotation)
+		assert.Empty(t, cluster.Status.Conditions)
+	})
+}
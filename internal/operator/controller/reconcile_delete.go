@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	operatorprov "github.com/milankappen/k8zner/internal/operator/provisioning"
+	"github.com/milankappen/k8zner/internal/platform/hcloud"
+)
+
+// reconcileFinalizer adds the teardown finalizer when the deletion policy is
+// Delete and removes it again when the policy is switched back to Retain.
+func (r *ClusterReconciler) reconcileFinalizer(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) error {
+	want := cluster.Spec.DeletionPolicy == k8znerv1alpha1.DeletionPolicyDelete
+	if want == controllerutil.ContainsFinalizer(cluster, k8znerv1alpha1.ClusterFinalizer) {
+		return nil
+	}
+
+	if want {
+		controllerutil.AddFinalizer(cluster, k8znerv1alpha1.ClusterFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(cluster, k8znerv1alpha1.ClusterFinalizer)
+	}
+	if err := r.Update(ctx, cluster); err != nil {
+		return fmt.Errorf("failed to update finalizers: %w", err)
+	}
+
+	log.FromContext(ctx).Info("updated teardown finalizer",
+		"deletionPolicy", cluster.Spec.DeletionPolicy,
+		"finalizer", want,
+	)
+	return nil
+}
+
+// reconcileDelete tears down the cluster's cloud resources, in the same order as
+// the CLI destroy command, and then releases the object by removing the finalizer.
+// Objects without the finalizer are left to the API server.
+func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(cluster, k8znerv1alpha1.ClusterFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// The policy may have been switched to Retain after deletion was requested
+	if cluster.Spec.DeletionPolicy != k8znerv1alpha1.DeletionPolicyDelete {
+		logger.Info("deletion policy is not Delete, leaving cloud resources in place")
+		return ctrl.Result{}, r.releaseCluster(ctx, cluster)
+	}
+
+	if cluster.Status.Phase != k8znerv1alpha1.ClusterPhaseDeleting {
+		cluster.Status.Phase = k8znerv1alpha1.ClusterPhaseDeleting
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, EventReasonTeardownStarted,
+			"Tearing down cluster resources")
+		if err := r.updateStatusWithRetry(ctx, cluster); err != nil {
+			logger.Error(err, "failed to update status before teardown")
+		}
+	}
+
+	creds, err := r.teardownCredentials(ctx, cluster)
+	if err != nil {
+		r.logAndRecordError(ctx, cluster, err, EventReasonTeardownFailed, "Failed to load credentials for teardown")
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	pCtx, err := r.phaseAdapter.BuildProvisioningContext(ctx, cluster, creds, hcloud.NewRealClient(creds.HCloudToken), nil)
+	if err != nil {
+		r.logAndRecordError(ctx, cluster, err, EventReasonTeardownFailed, "Failed to build teardown context")
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	logger.Info("tearing down cluster resources")
+	if err := r.teardown(pCtx); err != nil {
+		r.logAndRecordError(ctx, cluster, err, EventReasonTeardownFailed, "Cluster teardown failed")
+		return ctrl.Result{}, err
+	}
+
+	r.Recorder.Event(cluster, corev1.EventTypeNormal, EventReasonTeardownComplete,
+		"Cluster resources deleted")
+	return ctrl.Result{}, r.releaseCluster(ctx, cluster)
+}
+
+// teardownCredentials loads the credentials used for teardown. Clusters without
+// a credentials Secret fall back to the operator's own HCloud token.
+func (r *ClusterReconciler) teardownCredentials(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (*operatorprov.Credentials, error) {
+	if cluster.Spec.CredentialsRef.Name != "" {
+		return r.phaseAdapter.LoadCredentials(ctx, cluster)
+	}
+	if r.hcloudToken == "" {
+		return nil, fmt.Errorf("no credentialsRef set and HCloud token not configured")
+	}
+	return &operatorprov.Credentials{HCloudToken: r.hcloudToken}, nil
+}
+
+// releaseCluster removes the teardown finalizer so the API server can delete the object.
+func (r *ClusterReconciler) releaseCluster(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) error {
+	controllerutil.RemoveFinalizer(cluster, k8znerv1alpha1.ClusterFinalizer)
+	if err := r.Update(ctx, cluster); err != nil {
+		return fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/provisioning"
+)
+
+// newTeardownTestCluster returns a cluster that is being deleted and still holds
+// the teardown finalizer.
+func newTeardownTestCluster(policy string) *k8znerv1alpha1.K8znerCluster {
+	now := metav1.Now()
+	return &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-cluster",
+			Namespace:         "default",
+			Finalizers:        []string{k8znerv1alpha1.ClusterFinalizer},
+			DeletionTimestamp: &now,
+		},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			Region:         "fsn1",
+			DeletionPolicy: policy,
+			CredentialsRef: corev1.LocalObjectReference{Name: "test-credentials"},
+		},
+	}
+}
+
+func newTeardownTestCredentials() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-credentials", Namespace: "default"},
+		Data: map[string][]byte{
+			k8znerv1alpha1.CredentialsKeyHCloudToken: []byte("test-token"),
+		},
+	}
+}
+
+// recordTeardown makes the reconciler's teardown record the provisioning
+// context it was called with in calls and return err.
+func recordTeardown(calls *[]*provisioning.Context, err error) Option {
+	return WithTeardown(func(pCtx *provisioning.Context) error {
+		*calls = append(*calls, pCtx)
+		return err
+	})
+}
+
+func reconcileTestCluster(t *testing.T, r *ClusterReconciler) (ctrl.Result, error) {
+	t.Helper()
+	return r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-cluster", Namespace: "default"},
+	})
+}
+
+func TestReconcileFinalizer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		policy        string
+		finalizers    []string
+		wantFinalizer bool
+	}{
+		{name: "delete policy adds finalizer", policy: k8znerv1alpha1.DeletionPolicyDelete, wantFinalizer: true},
+		{name: "delete policy keeps finalizer", policy: k8znerv1alpha1.DeletionPolicyDelete, finalizers: []string{k8znerv1alpha1.ClusterFinalizer}, wantFinalizer: true},
+		{name: "retain policy removes finalizer", policy: k8znerv1alpha1.DeletionPolicyRetain, finalizers: []string{k8znerv1alpha1.ClusterFinalizer}},
+		{name: "empty policy retains", policy: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cluster := &k8znerv1alpha1.K8znerCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default", Finalizers: tt.finalizers},
+				Spec:       k8znerv1alpha1.K8znerClusterSpec{DeletionPolicy: tt.policy},
+			}
+			r := newTestReconciler(t, []client.Object{cluster})
+
+			require.NoError(t, r.reconcileFinalizer(context.Background(), cluster))
+
+			stored := &k8znerv1alpha1.K8znerCluster{}
+			require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(cluster), stored))
+			assert.Equal(t, tt.wantFinalizer, controllerutil.ContainsFinalizer(stored, k8znerv1alpha1.ClusterFinalizer))
+		})
+	}
+}
+
+func TestReconcileDelete_TearsDownAndReleases(t *testing.T) {
+	t.Parallel()
+	cluster := newTeardownTestCluster(k8znerv1alpha1.DeletionPolicyDelete)
+	var calls []*provisioning.Context
+	r := newTestReconciler(t, []client.Object{cluster, newTeardownTestCredentials()}, recordTeardown(&calls, nil))
+
+	result, err := reconcileTestCluster(t, r)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+
+	require.Len(t, calls, 1)
+	pCtx := calls[0]
+	assert.Equal(t, "test-cluster", pCtx.Config.ClusterName)
+	assert.Equal(t, "test-token", pCtx.Config.HCloudToken)
+
+	// With the finalizer gone the API server deletes the object
+	err = r.Get(context.Background(), client.ObjectKeyFromObject(cluster), &k8znerv1alpha1.K8znerCluster{})
+	assert.True(t, apierrors.IsNotFound(err), "cluster should be deleted, got %v", err)
+}
+
+func TestReconcileDelete_TeardownFailureKeepsFinalizer(t *testing.T) {
+	t.Parallel()
+	cluster := newTeardownTestCluster(k8znerv1alpha1.DeletionPolicyDelete)
+	var calls []*provisioning.Context
+	r := newTestReconciler(t, []client.Object{cluster, newTeardownTestCredentials()}, recordTeardown(&calls, errors.New("hcloud unavailable")))
+
+	_, err := reconcileTestCluster(t, r)
+	require.Error(t, err)
+	assert.Len(t, calls, 1)
+
+	stored := &k8znerv1alpha1.K8znerCluster{}
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(cluster), stored))
+	assert.True(t, controllerutil.ContainsFinalizer(stored, k8znerv1alpha1.ClusterFinalizer))
+	assert.Equal(t, k8znerv1alpha1.ClusterPhaseDeleting, stored.Status.Phase)
+}
+
+func TestReconcileDelete_MissingCredentialsRequeues(t *testing.T) {
+	t.Parallel()
+	cluster := newTeardownTestCluster(k8znerv1alpha1.DeletionPolicyDelete)
+	var calls []*provisioning.Context
+	r := newTestReconciler(t, []client.Object{cluster}, recordTeardown(&calls, nil))
+
+	result, err := reconcileTestCluster(t, r)
+	require.NoError(t, err)
+	assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+	assert.Empty(t, calls)
+
+	stored := &k8znerv1alpha1.K8znerCluster{}
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(cluster), stored))
+	assert.True(t, controllerutil.ContainsFinalizer(stored, k8znerv1alpha1.ClusterFinalizer))
+}
+
+func TestReconcileDelete_RetainReleasesWithoutTeardown(t *testing.T) {
+	t.Parallel()
+	cluster := newTeardownTestCluster(k8znerv1alpha1.DeletionPolicyRetain)
+	var calls []*provisioning.Context
+	r := newTestReconciler(t, []client.Object{cluster, newTeardownTestCredentials()}, recordTeardown(&calls, nil))
+
+	_, err := reconcileTestCluster(t, r)
+	require.NoError(t, err)
+	assert.Empty(t, calls)
+
+	err = r.Get(context.Background(), client.ObjectKeyFromObject(cluster), &k8znerv1alpha1.K8znerCluster{})
+	assert.True(t, apierrors.IsNotFound(err), "cluster should be deleted, got %v", err)
+}
+
+func TestReconcileDelete_PausedSkipsTeardown(t *testing.T) {
+	t.Parallel()
+	cluster := newTeardownTestCluster(k8znerv1alpha1.DeletionPolicyDelete)
+	cluster.Spec.Paused = true
+	var calls []*provisioning.Context
+	r := newTestReconciler(t, []client.Object{cluster, newTeardownTestCredentials()}, recordTeardown(&calls, nil))
+
+	_, err := reconcileTestCluster(t, r)
+	require.NoError(t, err)
+	assert.Empty(t, calls)
+
+	stored := &k8znerv1alpha1.K8znerCluster{}
+	require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(cluster), stored))
+	assert.True(t, controllerutil.ContainsFinalizer(stored, k8znerv1alpha1.ClusterFinalizer))
+}
+
+func TestTeardownCredentials_LegacyClusterUsesOperatorToken(t *testing.T) {
+	t.Parallel()
+	cluster := newTeardownTestCluster(k8znerv1alpha1.DeletionPolicyDelete)
+	cluster.Spec.CredentialsRef.Name = ""
+	r := newTestReconciler(t, []client.Object{cluster})
+
+	_, err := r.teardownCredentials(context.Background(), cluster)
+	require.Error(t, err)
+
+	r.hcloudToken = "operator-token"
+	creds, err := r.teardownCredentials(context.Background(), cluster)
+	require.NoError(t, err)
+	assert.Equal(t, "operator-token", creds.HCloudToken)
+}
@@ -18,6 +18,9 @@ import (
 // replaceControlPlane replaces an unhealthy control plane node.
 func (r *ClusterReconciler) replaceControlPlane(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, node *k8znerv1alpha1.NodeStatus) error {
 	tc := r.loadTalosClients(ctx, cluster)
+	if err := r.takePreOperationSnapshot(ctx, cluster, tc, snapshotOpCPReplace, node.Name); err != nil {
+		return err
+	}
 
 	// Remove from etcd cluster, delete K8s node and HCloud server
 	r.removeFromEtcd(ctx, cluster, tc, node)
@@ -121,16 +124,27 @@ func (r *ClusterReconciler) provisionReplacementCP(ctx context.Context, cluster
 	})
 }
 
-// replaceWorker replaces an unhealthy worker node.
+// replaceWorker replaces an unhealthy worker node with a new one in the same pool.
+// Workers of a pool that was removed from the spec are deleted without replacement.
 func (r *ClusterReconciler) replaceWorker(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, node *k8znerv1alpha1.NodeStatus) error {
+	// Resolve the pool before node points at a removed status entry
+	poolName := workerPoolName(cluster, node)
+	pool := findWorkerPool(cluster, poolName)
+
 	// Drain and delete old worker
+	nodeName := node.Name
 	if err := r.drainAndDeleteWorker(ctx, cluster, node); err != nil {
 		return err
 	}
-	r.removeNodeFromStatus(cluster, "worker", node.Name)
+	r.removeNodeFromStatus(cluster, "worker", nodeName)
+
+	if pool == nil {
+		log.FromContext(ctx).Info("not replacing worker of removed pool", "node", nodeName, "pool", poolName)
+		return nil
+	}
 
 	// Provision replacement
-	return r.provisionReplacementWorker(ctx, cluster, node)
+	return r.provisionReplacementWorker(ctx, cluster, *pool)
 }
 
 // drainAndDeleteWorker cordons, drains, and deletes a worker node and its server.
@@ -162,8 +176,8 @@ func (r *ClusterReconciler) drainAndDeleteWorker(ctx context.Context, cluster *k
 	return r.deleteNodeAndServer(ctx, cluster, node, "worker")
 }
 
-// provisionReplacementWorker creates a new worker server to replace the deleted one.
-func (r *ClusterReconciler) provisionReplacementWorker(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, oldNode *k8znerv1alpha1.NodeStatus) error {
+// provisionReplacementWorker creates a new worker server in the given pool.
+func (r *ClusterReconciler) provisionReplacementWorker(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, pool k8znerv1alpha1.WorkerPoolSpec) error {
 	prereqs, cleanup, err := r.prepareForProvisioning(ctx, cluster, "worker")
 	if err != nil {
 		return err
@@ -173,13 +187,14 @@ func (r *ClusterReconciler) provisionReplacementWorker(ctx context.Context, clus
 	return r.provisionAndConfigureNode(ctx, cluster, nodeProvisionParams{
 		Name:       naming.Worker(cluster.Name),
 		Role:       "worker",
-		Pool:       "workers",
-		ServerType: string(config.ServerSize(cluster.Spec.Workers.Size).Normalize()),
+		Pool:       pool.Name,
+		Location:   workerPoolLocation(cluster, pool),
+		ServerType: string(config.ServerSize(pool.Size).Normalize()),
 		SnapshotID: prereqs.SnapshotID,
 		SSHKeyName: prereqs.SSHKeyName,
 		NetworkID:  prereqs.ClusterState.NetworkID,
 		Configure: func(serverName string, result *serverProvisionResult) error {
-			return r.configureWorkerNode(ctx, cluster, prereqs.TC, result)
+			return r.configureWorkerNode(ctx, cluster, prereqs.TC, pool, result)
 		},
 	})
 }
@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -11,6 +12,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+	"github.com/milankappen/k8zner/internal/util/labels"
 )
 
 // reconcileHealthCheck checks the health of all nodes and updates status.
@@ -51,14 +54,27 @@ func (r *ClusterReconciler) reconcileHealthCheck(ctx context.Context, cluster *k
 	// Update control plane status
 	cluster.Status.ControlPlanes = r.buildNodeGroupStatus(ctx, cluster, cpNodes, cluster.Spec.ControlPlanes.Count, "control-plane")
 
-	// Update worker status
-	cluster.Status.Workers = r.buildNodeGroupStatus(ctx, cluster, workerNodes, cluster.Spec.Workers.Count, "worker")
+	// Update worker status, overall and per pool
+	cluster.Status.Workers = r.buildNodeGroupStatus(ctx, cluster, workerNodes, desiredWorkerCount(cluster), "worker")
+	cluster.Status.WorkerPools = buildWorkerPoolStatus(cluster)
+
+	// Nodes register with their pool's labels and taints from the machine config. Nodes
+	// that joined before the pool changed, or before a config rollout reached them, are
+	// fixed up here.
+	if len(cluster.Spec.WorkerPools) > 0 {
+		for i := range workerNodes {
+			nodeStatus := findNodeStatus(cluster.Status.Workers.Nodes, workerNodes[i].Name)
+			if pool := findWorkerPool(cluster, workerPoolName(cluster, nodeStatus)); pool != nil {
+				r.syncWorkerPoolNodeMetadata(ctx, &workerNodes[i], pool)
+			}
+		}
+	}
 
 	// Record metrics
 	r.recordNodeCounts(cluster.Name, "control-plane",
 		len(cpNodes), cluster.Status.ControlPlanes.Ready, cluster.Spec.ControlPlanes.Count)
 	r.recordNodeCounts(cluster.Name, "worker",
-		len(workerNodes), cluster.Status.Workers.Ready, cluster.Spec.Workers.Count)
+		len(workerNodes), cluster.Status.Workers.Ready, desiredWorkerCount(cluster))
 
 	logger.Info("health check complete",
 		"controlPlanes", fmt.Sprintf("%d/%d", cluster.Status.ControlPlanes.Ready, cluster.Status.ControlPlanes.Desired),
@@ -77,10 +93,33 @@ func (r *ClusterReconciler) buildNodeGroupStatus(ctx context.Context, cluster *k
 
 	now := metav1.Now()
 
+	// Observed per-node data that isn't derived from the Kubernetes Node object
+	// is carried over from the previous status.
+	previous := cluster.Status.Workers.Nodes
+	if role == "control-plane" {
+		previous = cluster.Status.ControlPlanes.Nodes
+	}
+
 	for _, node := range nodes {
 		nodeStatus := k8znerv1alpha1.NodeStatus{
 			Name:            node.Name,
 			LastHealthCheck: &now,
+			// Kubelet reports "v1.32.2"; spec.kubernetes.version has no prefix
+			KubernetesVersion: strings.TrimPrefix(node.Status.NodeInfo.KubeletVersion, "v"),
+			ConfigHash:        node.Annotations[talos.ConfigHashAnnotation],
+		}
+		if prev := findNodeStatus(previous, node.Name); prev != nil {
+			nodeStatus.TalosVersion = prev.TalosVersion
+			nodeStatus.ServerType = prev.ServerType
+			nodeStatus.Pool = prev.Pool
+			nodeStatus.UnderutilizedSince = prev.UnderutilizedSince
+			// A staged config is done once the node reports it after a reboot
+			if prev.StagedConfigHash != nodeStatus.ConfigHash {
+				nodeStatus.StagedConfigHash = prev.StagedConfigHash
+			}
+		}
+		if nodeStatus.Pool == "" && role == "worker" {
+			nodeStatus.Pool = node.Labels[labels.KeyPool]
 		}
 
 		// Extract server ID from provider ID (format: hcloud://12345)
@@ -127,6 +166,16 @@ func (r *ClusterReconciler) buildNodeGroupStatus(ctx context.Context, cluster *k
 	return status
 }
 
+// findNodeStatus returns the status entry with the given name, or nil if absent.
+func findNodeStatus(nodes []k8znerv1alpha1.NodeStatus, name string) *k8znerv1alpha1.NodeStatus {
+	for i := range nodes {
+		if nodes[i].Name == name {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
 // updateClusterPhase updates the overall cluster phase based on status.
 func (r *ClusterReconciler) updateClusterPhase(cluster *k8znerv1alpha1.K8znerCluster) {
 	cpReady := cluster.Status.ControlPlanes.Ready == cluster.Status.ControlPlanes.Desired
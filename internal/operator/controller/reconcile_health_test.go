@@ -13,6 +13,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/platform/talos"
 )
 
 func TestClusterReconciler_reconcileHealthCheck(t *testing.T) {
@@ -466,3 +467,70 @@ func TestUpdateClusterPhase_ProvisioningPhasePreserved(t *testing.T) {
 	// Since phase is not Healing, and not all ready, it should be Degraded
 	assert.Equal(t, k8znerv1alpha1.ClusterPhaseDegraded, cluster.Status.Phase)
 }
+
+func TestBuildNodeGroupStatus_ConfigHash(t *testing.T) {
+	t.Parallel()
+	scheme := setupTestScheme(t)
+
+	cluster := &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			ControlPlanes: k8znerv1alpha1.ControlPlaneSpec{Count: 0},
+			Workers:       k8znerv1alpha1.WorkerSpec{Count: 2},
+		},
+		Status: k8znerv1alpha1.K8znerClusterStatus{
+			Workers: k8znerv1alpha1.NodeGroupStatus{
+				Nodes: []k8znerv1alpha1.NodeStatus{
+					{Name: "worker-1", StagedConfigHash: "new"},
+					{Name: "worker-2", StagedConfigHash: "new"},
+				},
+			},
+		},
+	}
+
+	newWorker := func(name, hash string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{talos.ConfigHashAnnotation: hash},
+			},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				},
+			},
+		}
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(cluster, newWorker("worker-1", "old"), newWorker("worker-2", "new")).
+		WithStatusSubresource(cluster).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+
+	r := NewClusterReconciler(client, scheme, recorder,
+		WithHCloudClient(&MockHCloudClient{}),
+		WithMetrics(false),
+	)
+
+	err := r.reconcileHealthCheck(context.Background(), cluster)
+	require.NoError(t, err)
+
+	require.Len(t, cluster.Status.Workers.Nodes, 2)
+	byName := map[string]k8znerv1alpha1.NodeStatus{}
+	for _, node := range cluster.Status.Workers.Nodes {
+		byName[node.Name] = node
+	}
+
+	// Staged config not active yet: keep waiting for the reboot
+	assert.Equal(t, "old", byName["worker-1"].ConfigHash)
+	assert.Equal(t, "new", byName["worker-1"].StagedConfigHash)
+
+	// Staged config reported by the node: nothing left staged
+	assert.Equal(t, "new", byName["worker-2"].ConfigHash)
+	assert.Empty(t, byName["worker-2"].StagedConfigHash)
+}
@@ -0,0 +1,294 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/util/k8sversion"
+)
+
+// reconcileKubernetesUpgrade rolls nodes forward to spec.kubernetes.version.
+// Pre-flight checks guard against unsupported version skew and deprecated API usage,
+// then control planes are upgraded one at a time followed by workers in batches of
+// spec.talos.upgradeBatchSize. Each call performs at most one step and requeues.
+func (r *ClusterReconciler) reconcileKubernetesUpgrade(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	desired := strings.TrimPrefix(cluster.Spec.Kubernetes.Version, "v")
+	if desired == "" {
+		return ctrl.Result{}, nil
+	}
+
+	// Talos upgrades reboot nodes; let them finish before touching Kubernetes.
+	if cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionTalosUpgrade); cond != nil && cond.Status == metav1.ConditionFalse {
+		return ctrl.Result{}, nil
+	}
+
+	cpPending := nodesNeedingKubernetesUpgrade(cluster.Status.ControlPlanes.Nodes, desired)
+	workerPending := nodesNeedingKubernetesUpgrade(cluster.Status.Workers.Nodes, desired)
+
+	if len(cpPending) == 0 && len(workerPending) == 0 {
+		if allNodesAtKubernetesVersion(cluster, desired) {
+			r.completeKubernetesUpgrade(cluster, desired)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	tc := r.loadTalosClients(ctx, cluster)
+	if tc.configGen == nil {
+		logger.V(1).Info("skipping Kubernetes upgrade (no Talos credentials)")
+		return ctrl.Result{}, nil
+	}
+
+	// Never start an upgrade step on a degraded cluster; healing runs first.
+	if cluster.Status.ControlPlanes.Ready < cluster.Spec.ControlPlanes.Count ||
+		cluster.Status.Workers.Ready < desiredWorkerCount(cluster) {
+		logger.Info("waiting for all nodes to be healthy before continuing Kubernetes upgrade",
+			"controlPlanesReady", cluster.Status.ControlPlanes.Ready,
+			"workersReady", cluster.Status.Workers.Ready,
+		)
+		setKubernetesUpgradeCondition(cluster, metav1.ConditionFalse, "WaitingForHealthyNodes",
+			fmt.Sprintf("Upgrade to Kubernetes %s paused until all nodes are healthy", desired))
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	if err := r.kubernetesUpgradePreflight(ctx, cluster, desired); err != nil {
+		logger.Info("Kubernetes upgrade blocked by pre-flight checks", "version", desired, "reason", err.Error())
+		prev := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionKubernetesUpgrade)
+		if prev == nil || prev.Reason != "PreflightFailed" || prev.Message != err.Error() {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonK8sUpgradeBlocked,
+				"Upgrade to Kubernetes %s blocked: %v", desired, err)
+		}
+		setKubernetesUpgradeCondition(cluster, metav1.ConditionFalse, "PreflightFailed", err.Error())
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	if !r.disruptionAllowed(ctx, cluster, maintenanceActionKubernetesUpgrade, "",
+		fmt.Sprintf("%d nodes need an upgrade to Kubernetes %s", len(cpPending)+len(workerPending), desired)) {
+		setKubernetesUpgradeCondition(cluster, metav1.ConditionFalse, "WaitingForMaintenanceWindow",
+			fmt.Sprintf("Upgrade to Kubernetes %s waits for the next maintenance window", desired))
+		return ctrl.Result{}, nil
+	}
+
+	if len(cpPending) > 0 {
+		return r.upgradeKubernetesControlPlane(ctx, cluster, tc, cpPending[0], desired)
+	}
+
+	return r.upgradeKubernetesWorkers(ctx, cluster, tc, workerPending, desired)
+}
+
+// kubernetesUpgradePreflight verifies the upgrade is safe to start or continue.
+func (r *ClusterReconciler) kubernetesUpgradePreflight(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, desired string) error {
+	if err := checkKubernetesVersionSkew(cluster, desired); err != nil {
+		return err
+	}
+
+	if r.deprecatedAPIChecker == nil {
+		return nil
+	}
+
+	apis, err := r.deprecatedAPIChecker(ctx, desired)
+	if err != nil {
+		return fmt.Errorf("failed to check deprecated API usage: %w", err)
+	}
+	if len(apis) > 0 {
+		return fmt.Errorf("APIs removed in Kubernetes %s are still in use: %s", desired, strings.Join(apis, ", "))
+	}
+
+	return nil
+}
+
+// upgradeKubernetesControlPlane upgrades a single control plane's Kubernetes components.
+func (r *ClusterReconciler) upgradeKubernetesControlPlane(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, node k8znerv1alpha1.NodeStatus, desired string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if err := r.takePreOperationSnapshot(ctx, cluster, tc, snapshotOpK8sUpgrade, node.Name); err != nil {
+		setKubernetesUpgradeCondition(cluster, metav1.ConditionFalse, "SnapshotFailed", err.Error())
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	setKubernetesUpgradeCondition(cluster, metav1.ConditionFalse, "UpgradeInProgress",
+		fmt.Sprintf("Upgrading control plane %s to Kubernetes %s", node.Name, desired))
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonK8sUpgrading,
+		"Upgrading control plane %s from Kubernetes %s to %s", node.Name, node.KubernetesVersion, desired)
+
+	r.markKubernetesUpgrading(ctx, cluster, "control-plane", node, desired)
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status before control plane upgrade")
+	}
+
+	err := r.upgradeKubernetesNode(ctx, cluster, tc, "control-plane", node, desired)
+	if persistErr := r.persistClusterStatus(ctx, cluster); persistErr != nil {
+		logger.Error(persistErr, "failed to persist status after control plane upgrade")
+	}
+	if err != nil {
+		setKubernetesUpgradeCondition(cluster, metav1.ConditionFalse, "UpgradeFailed", err.Error())
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// upgradeKubernetesWorkers upgrades up to spec.talos.upgradeBatchSize workers in parallel.
+func (r *ClusterReconciler) upgradeKubernetesWorkers(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, pending []k8znerv1alpha1.NodeStatus, desired string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	batch := pending[:min(upgradeBatchSize(cluster), len(pending))]
+
+	names := make([]string, 0, len(batch))
+	for _, node := range batch {
+		names = append(names, node.Name)
+		r.markKubernetesUpgrading(ctx, cluster, "worker", node, desired)
+	}
+
+	setKubernetesUpgradeCondition(cluster, metav1.ConditionFalse, "UpgradeInProgress",
+		fmt.Sprintf("Upgrading workers %v to Kubernetes %s (%d remaining)", names, desired, len(pending)))
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonK8sUpgrading,
+		"Upgrading workers %v to Kubernetes %s", names, desired)
+
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status before worker upgrade")
+	}
+
+	failed, lastErr := upgradeNodesInParallel(batch, func(node k8znerv1alpha1.NodeStatus) error {
+		return r.upgradeKubernetesNode(ctx, cluster, tc, "worker", node, desired)
+	})
+
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status after worker upgrade")
+	}
+
+	if failed > 0 {
+		setKubernetesUpgradeCondition(cluster, metav1.ConditionFalse, "UpgradeFailed",
+			fmt.Sprintf("%d/%d workers failed to upgrade: %v", failed, len(batch), lastErr))
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// upgradeKubernetesNode patches one node's machine config to the desired Kubernetes
+// version and waits for its kubelet to report that version.
+// Safe for concurrent use from parallel upgrade goroutines.
+func (r *ClusterReconciler) upgradeKubernetesNode(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, role string, node k8znerv1alpha1.NodeStatus, desired string) error {
+	logger := log.FromContext(ctx)
+
+	fail := func(err error) error {
+		logger.Error(err, "Kubernetes upgrade failed", "node", node.Name, "role", role)
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonK8sUpgradeFailed,
+			"Failed to upgrade %s %s to Kubernetes %s: %v", role, node.Name, desired, err)
+		r.updateNodePhase(ctx, cluster, role, nodeStatusUpdate{
+			Name:   node.Name,
+			Phase:  k8znerv1alpha1.NodePhaseUnhealthy,
+			Reason: fmt.Sprintf("Kubernetes upgrade to %s failed: %v", desired, err),
+		})
+		return fmt.Errorf("failed to upgrade %s: %w", node.Name, err)
+	}
+
+	logger.Info("upgrading Kubernetes on node",
+		"node", node.Name,
+		"role", role,
+		"from", node.KubernetesVersion,
+		"to", desired,
+	)
+
+	if err := tc.configGen.UpgradeKubernetes(ctx, talosEndpointForNode(node), desired); err != nil {
+		return fail(err)
+	}
+
+	if err := r.kubeletVersionWaiter(ctx, node.Name, desired, kubeletUpgradeTimeout); err != nil {
+		return fail(err)
+	}
+
+	r.updateNodePhase(ctx, cluster, role, nodeStatusUpdate{
+		Name:              node.Name,
+		Phase:             k8znerv1alpha1.NodePhaseReady,
+		Reason:            fmt.Sprintf("Upgraded to Kubernetes %s", desired),
+		KubernetesVersion: desired,
+	})
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonK8sUpgraded,
+		"Upgraded %s %s to Kubernetes %s", role, node.Name, desired)
+
+	return nil
+}
+
+// markKubernetesUpgrading moves a node into the Upgrading phase.
+func (r *ClusterReconciler) markKubernetesUpgrading(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, role string, node k8znerv1alpha1.NodeStatus, desired string) {
+	r.updateNodePhase(ctx, cluster, role, nodeStatusUpdate{
+		Name:   node.Name,
+		Phase:  k8znerv1alpha1.NodePhaseUpgrading,
+		Reason: fmt.Sprintf("Upgrading Kubernetes %s -> %s", node.KubernetesVersion, desired),
+	})
+}
+
+// completeKubernetesUpgrade marks the Kubernetes upgrade condition as done once every node runs the desired version.
+func (r *ClusterReconciler) completeKubernetesUpgrade(cluster *k8znerv1alpha1.K8znerCluster, desired string) {
+	prev := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionKubernetesUpgrade)
+	if prev != nil && prev.Status == metav1.ConditionFalse {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonK8sUpgraded,
+			"All nodes upgraded to Kubernetes %s", desired)
+	}
+	setKubernetesUpgradeCondition(cluster, metav1.ConditionTrue, "UpToDate",
+		fmt.Sprintf("All nodes run Kubernetes %s", desired))
+}
+
+// checkKubernetesVersionSkew rejects downgrades and upgrades that skip a minor version,
+// neither of which Kubernetes supports.
+func checkKubernetesVersionSkew(cluster *k8znerv1alpha1.K8znerCluster, desired string) error {
+	for _, nodes := range [][]k8znerv1alpha1.NodeStatus{cluster.Status.ControlPlanes.Nodes, cluster.Status.Workers.Nodes} {
+		for _, node := range nodes {
+			if node.KubernetesVersion == "" {
+				continue
+			}
+			if err := k8sversion.CheckUpgrade(node.KubernetesVersion, desired); err != nil {
+				return fmt.Errorf("node %s: %w", node.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// nodesNeedingKubernetesUpgrade returns healthy nodes whose kubelet version differs from desired,
+// sorted by name so upgrades proceed in a stable order.
+func nodesNeedingKubernetesUpgrade(nodes []k8znerv1alpha1.NodeStatus, desired string) []k8znerv1alpha1.NodeStatus {
+	var pending []k8znerv1alpha1.NodeStatus
+	for _, node := range nodes {
+		if node.Healthy && node.KubernetesVersion != "" && node.KubernetesVersion != desired {
+			pending = append(pending, node)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Name < pending[j].Name })
+	return pending
+}
+
+// allNodesAtKubernetesVersion reports whether every tracked node is known to run the desired version.
+func allNodesAtKubernetesVersion(cluster *k8znerv1alpha1.K8znerCluster, desired string) bool {
+	for _, nodes := range [][]k8znerv1alpha1.NodeStatus{cluster.Status.ControlPlanes.Nodes, cluster.Status.Workers.Nodes} {
+		for _, node := range nodes {
+			if node.KubernetesVersion != desired {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// setKubernetesUpgradeCondition sets the KubernetesUpgrade condition.
+func setKubernetesUpgradeCondition(cluster *k8znerv1alpha1.K8znerCluster, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:    k8znerv1alpha1.ConditionKubernetesUpgrade,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
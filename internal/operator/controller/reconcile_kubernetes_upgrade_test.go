@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+)
+
+func newK8sUpgradeTestCluster(cpVersions, workerVersions []string) *k8znerv1alpha1.K8znerCluster {
+	cluster := &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			ControlPlanes: k8znerv1alpha1.ControlPlaneSpec{Count: len(cpVersions)},
+			Workers:       k8znerv1alpha1.WorkerSpec{Count: len(workerVersions)},
+			Kubernetes:    k8znerv1alpha1.KubernetesSpec{Version: "1.32.2"},
+		},
+	}
+	for i, v := range cpVersions {
+		cluster.Status.ControlPlanes.Nodes = append(cluster.Status.ControlPlanes.Nodes, k8znerv1alpha1.NodeStatus{
+			Name: fmt.Sprintf("cp-%d", i+1), PrivateIP: fmt.Sprintf("10.0.1.%d", i+1), Healthy: true, KubernetesVersion: v,
+		})
+	}
+	for i, v := range workerVersions {
+		cluster.Status.Workers.Nodes = append(cluster.Status.Workers.Nodes, k8znerv1alpha1.NodeStatus{
+			Name: fmt.Sprintf("worker-%d", i+1), PrivateIP: fmt.Sprintf("10.0.2.%d", i+1), Healthy: true, KubernetesVersion: v,
+		})
+	}
+	cluster.Status.ControlPlanes.Ready = len(cpVersions)
+	cluster.Status.Workers.Ready = len(workerVersions)
+	return cluster
+}
+
+func TestReconcileKubernetesUpgrade(t *testing.T) {
+	t.Parallel()
+
+	t.Run("upgrades one control plane before any worker", func(t *testing.T) {
+		t.Parallel()
+		cluster := newK8sUpgradeTestCluster([]string{"1.31.4", "1.31.4", "1.31.4"}, []string{"1.31.4"})
+		gen := &MockTalosConfigGenerator{}
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileKubernetesUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+
+		require.Len(t, gen.UpgradeKubernetesCalls, 1)
+		assert.Equal(t, UpgradeKubernetesCall{Endpoint: "10.0.1.1", TargetVersion: "1.32.2"}, gen.UpgradeKubernetesCalls[0])
+
+		cp1 := findNodeStatus(cluster.Status.ControlPlanes.Nodes, "cp-1")
+		require.NotNil(t, cp1)
+		assert.Equal(t, "1.32.2", cp1.KubernetesVersion)
+		assert.Equal(t, k8znerv1alpha1.NodePhaseReady, cp1.Phase)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionKubernetesUpgrade)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, "UpgradeInProgress", cond.Reason)
+	})
+
+	t.Run("upgrades workers in batches once control planes are done", func(t *testing.T) {
+		t.Parallel()
+		cluster := newK8sUpgradeTestCluster([]string{"1.32.2"}, []string{"1.31.4", "1.31.4", "1.31.4"})
+		cluster.Spec.Talos.UpgradeBatchSize = 2
+		gen := &MockTalosConfigGenerator{}
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileKubernetesUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+
+		require.Len(t, gen.UpgradeKubernetesCalls, 2)
+		endpoints := []string{gen.UpgradeKubernetesCalls[0].Endpoint, gen.UpgradeKubernetesCalls[1].Endpoint}
+		assert.ElementsMatch(t, []string{"10.0.2.1", "10.0.2.2"}, endpoints)
+		assert.Equal(t, "1.31.4", findNodeStatus(cluster.Status.Workers.Nodes, "worker-3").KubernetesVersion)
+	})
+
+	t.Run("marks upgrade complete when all nodes run desired version", func(t *testing.T) {
+		t.Parallel()
+		cluster := newK8sUpgradeTestCluster([]string{"1.32.2"}, []string{"1.32.2"})
+		gen := &MockTalosConfigGenerator{}
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileKubernetesUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Empty(t, gen.UpgradeKubernetesCalls)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionKubernetesUpgrade)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	})
+
+	t.Run("waits for a running Talos upgrade", func(t *testing.T) {
+		t.Parallel()
+		cluster := newK8sUpgradeTestCluster([]string{"1.31.4"}, nil)
+		setTalosUpgradeCondition(cluster, metav1.ConditionFalse, "UpgradeInProgress", "upgrading")
+		gen := &MockTalosConfigGenerator{}
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		_, err := r.reconcileKubernetesUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Empty(t, gen.UpgradeKubernetesCalls)
+	})
+
+	t.Run("blocks on deprecated API usage", func(t *testing.T) {
+		t.Parallel()
+		cluster := newK8sUpgradeTestCluster([]string{"1.31.4"}, nil)
+		gen := &MockTalosConfigGenerator{}
+		r := newTestReconciler(t, []client.Object{cluster},
+			WithTalosConfigGenerator(gen),
+			WithDeprecatedAPIChecker(func(_ context.Context, _ string) ([]string, error) {
+				return []string{"flowcontrol.apiserver.k8s.io/v1beta3 flowschemas"}, nil
+			}),
+		)
+
+		result, err := r.reconcileKubernetesUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Empty(t, gen.UpgradeKubernetesCalls)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionKubernetesUpgrade)
+		require.NotNil(t, cond)
+		assert.Equal(t, "PreflightFailed", cond.Reason)
+		assert.Contains(t, cond.Message, "flowschemas")
+	})
+
+	t.Run("failed upgrade marks node unhealthy and backs off", func(t *testing.T) {
+		t.Parallel()
+		cluster := newK8sUpgradeTestCluster([]string{"1.31.4"}, nil)
+		gen := &MockTalosConfigGenerator{}
+		r := newTestReconciler(t, []client.Object{cluster},
+			WithTalosConfigGenerator(gen),
+			WithKubeletVersionWaiter(func(_ context.Context, _, _ string, _ time.Duration) error {
+				return fmt.Errorf("timeout")
+			}),
+		)
+
+		result, err := r.reconcileKubernetesUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+
+		cp1 := findNodeStatus(cluster.Status.ControlPlanes.Nodes, "cp-1")
+		require.NotNil(t, cp1)
+		assert.Equal(t, k8znerv1alpha1.NodePhaseUnhealthy, cp1.Phase)
+		assert.Equal(t, "1.31.4", cp1.KubernetesVersion)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionKubernetesUpgrade)
+		require.NotNil(t, cond)
+		assert.Equal(t, "UpgradeFailed", cond.Reason)
+	})
+}
+
+func TestCheckKubernetesVersionSkew(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		wantErr string
+	}{
+		{name: "patch upgrade", current: "1.32.0", desired: "1.32.2"},
+		{name: "next minor", current: "1.31.4", desired: "1.32.2"},
+		{name: "already at target", current: "1.32.2", desired: "1.32.2"},
+		{name: "skips a minor", current: "1.30.1", desired: "1.32.2", wantErr: "one minor version at a time"},
+		{name: "minor downgrade", current: "1.32.2", desired: "1.31.4", wantErr: "not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cluster := newK8sUpgradeTestCluster([]string{tt.current}, nil)
+
+			err := checkKubernetesVersionSkew(cluster, tt.desired)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+)
+
+// desiredNodeConfig is the machine config a node should run and its hash.
+type desiredNodeConfig struct {
+	data []byte
+	hash string
+}
+
+// reconcileMachineConfig detects nodes whose machine config drifted from the one the
+// operator would generate for them today and re-applies it in place using
+// spec.talos.configApplyMode. Nodes report the hash of their config through the
+// k8zner.io/config-hash node annotation. Control planes are reconfigured one at a
+// time after an etcd quorum check, then workers in batches of spec.talos.upgradeBatchSize.
+// Each call performs at most one step and requeues.
+func (r *ClusterReconciler) reconcileMachineConfig(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Version upgrades change the generated config as well; let them finish first.
+	for _, condType := range []string{k8znerv1alpha1.ConditionTalosUpgrade, k8znerv1alpha1.ConditionKubernetesUpgrade} {
+		if cond := meta.FindStatusCondition(cluster.Status.Conditions, condType); cond != nil && cond.Status == metav1.ConditionFalse {
+			return ctrl.Result{}, nil
+		}
+	}
+
+	tc := r.loadTalosClients(ctx, cluster)
+	if tc.configGen == nil {
+		logger.V(1).Info("skipping machine config drift check (no Talos credentials)")
+		return ctrl.Result{}, nil
+	}
+
+	cpDesired, err := desiredMachineConfigs(cluster, tc, "control-plane")
+	if err != nil {
+		return r.machineConfigGenerateFailed(ctx, cluster, err)
+	}
+	workerDesired, err := desiredMachineConfigs(cluster, tc, "worker")
+	if err != nil {
+		return r.machineConfigGenerateFailed(ctx, cluster, err)
+	}
+
+	cpPending := nodesWithConfigDrift(cluster.Status.ControlPlanes.Nodes, cpDesired)
+	workerPending := nodesWithConfigDrift(cluster.Status.Workers.Nodes, workerDesired)
+
+	if len(cpPending) == 0 && len(workerPending) == 0 {
+		if machineConfigsInSync(cluster.Status.ControlPlanes.Nodes, cpDesired) &&
+			machineConfigsInSync(cluster.Status.Workers.Nodes, workerDesired) {
+			r.completeMachineConfigSync(cluster)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Adopted clusters keep their own configs until the user opts in
+	if cluster.Annotations[k8znerv1alpha1.PauseMachineConfigSyncAnnotation] == "true" {
+		setMachineConfigCondition(cluster, metav1.ConditionFalse, "Paused",
+			fmt.Sprintf("%d nodes run a machine config k8zner did not generate; remove the %s annotation to apply it",
+				len(cpPending)+len(workerPending), k8znerv1alpha1.PauseMachineConfigSyncAnnotation))
+		return ctrl.Result{}, nil
+	}
+
+	// Never reconfigure nodes on a degraded cluster; healing runs first.
+	if cluster.Status.ControlPlanes.Ready < cluster.Spec.ControlPlanes.Count ||
+		cluster.Status.Workers.Ready < desiredWorkerCount(cluster) {
+		logger.Info("waiting for all nodes to be healthy before applying machine configs",
+			"controlPlanesReady", cluster.Status.ControlPlanes.Ready,
+			"workersReady", cluster.Status.Workers.Ready,
+		)
+		setMachineConfigCondition(cluster, metav1.ConditionFalse, "WaitingForHealthyNodes",
+			fmt.Sprintf("%d nodes run an outdated machine config; waiting until all nodes are healthy",
+				len(cpPending)+len(workerPending)))
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	reason := fmt.Sprintf("%d nodes run an outdated machine config", len(cpPending)+len(workerPending))
+	if !r.disruptionAllowed(ctx, cluster, maintenanceActionMachineConfig, "", reason) {
+		setMachineConfigCondition(cluster, metav1.ConditionFalse, "WaitingForMaintenanceWindow",
+			reason+"; waiting for the next maintenance window")
+		return ctrl.Result{}, nil
+	}
+
+	mode := machineConfigApplyMode(cluster)
+
+	if len(cpPending) > 0 {
+		node := cpPending[0]
+		return r.applyMachineConfigToControlPlane(ctx, cluster, tc, node, cpDesired[node.Name], mode)
+	}
+
+	return r.applyMachineConfigToWorkers(ctx, cluster, tc, workerPending, workerDesired, mode)
+}
+
+// machineConfigGenerateFailed reports a failure to generate the desired machine configs.
+// It does not hold up the rest of the reconcile; the check is retried next time.
+func (r *ClusterReconciler) machineConfigGenerateFailed(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, err error) (ctrl.Result, error) {
+	log.FromContext(ctx).Error(err, "failed to generate desired machine configs")
+	setMachineConfigCondition(cluster, metav1.ConditionFalse, "GenerateFailed", err.Error())
+	return ctrl.Result{}, nil
+}
+
+// applyMachineConfigToControlPlane re-applies the machine config of a single control plane.
+// Unless the apply mode can never reboot the node, etcd must tolerate losing it first.
+func (r *ClusterReconciler) applyMachineConfigToControlPlane(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, node k8znerv1alpha1.NodeStatus, desired desiredNodeConfig, mode string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if mode != talos.ApplyModeNoReboot && mode != talos.ApplyModeStaged {
+		if err := r.checkUpgradeQuorum(ctx, cluster, tc, node); err != nil {
+			logger.Error(err, "cannot reconfigure control plane - quorum would be lost", "node", node.Name)
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonQuorumLost,
+				"Cannot apply machine config to control plane %s: %v", node.Name, err)
+			setMachineConfigCondition(cluster, metav1.ConditionFalse, "QuorumAtRisk", err.Error())
+			return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+		}
+	}
+
+	setMachineConfigCondition(cluster, metav1.ConditionFalse, "Applying",
+		fmt.Sprintf("Applying machine config to control plane %s (%s mode)", node.Name, mode))
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonConfigDrift,
+		"Control plane %s runs an outdated machine config; applying it in %s mode", node.Name, mode)
+
+	r.markApplyingMachineConfig(ctx, cluster, "control-plane", node, mode)
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status before applying control plane config")
+	}
+
+	err := r.applyNodeMachineConfig(ctx, cluster, tc, "control-plane", node, desired, mode)
+	if persistErr := r.persistClusterStatus(ctx, cluster); persistErr != nil {
+		logger.Error(persistErr, "failed to persist status after applying control plane config")
+	}
+	if err != nil {
+		setMachineConfigCondition(cluster, metav1.ConditionFalse, "ApplyFailed", err.Error())
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// applyMachineConfigToWorkers re-applies the machine config of up to
+// spec.talos.upgradeBatchSize workers in parallel.
+func (r *ClusterReconciler) applyMachineConfigToWorkers(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, pending []k8znerv1alpha1.NodeStatus, desired map[string]desiredNodeConfig, mode string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	batch := pending[:min(upgradeBatchSize(cluster), len(pending))]
+
+	names := make([]string, 0, len(batch))
+	for _, node := range batch {
+		names = append(names, node.Name)
+		r.markApplyingMachineConfig(ctx, cluster, "worker", node, mode)
+	}
+
+	setMachineConfigCondition(cluster, metav1.ConditionFalse, "Applying",
+		fmt.Sprintf("Applying machine config to workers %v (%s mode, %d remaining)", names, mode, len(pending)))
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonConfigDrift,
+		"Workers %v run an outdated machine config; applying it in %s mode", names, mode)
+
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status before applying worker configs")
+	}
+
+	failed, lastErr := upgradeNodesInParallel(batch, func(node k8znerv1alpha1.NodeStatus) error {
+		return r.applyNodeMachineConfig(ctx, cluster, tc, "worker", node, desired[node.Name], mode)
+	})
+
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status after applying worker configs")
+	}
+
+	if failed > 0 {
+		setMachineConfigCondition(cluster, metav1.ConditionFalse, "ApplyFailed",
+			fmt.Sprintf("%d/%d workers failed to apply machine config: %v", failed, len(batch), lastErr))
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// applyNodeMachineConfig applies the desired machine config to one node and waits for
+// the node to report it. Staged configs only take effect on the next reboot, so they
+// are recorded as staged instead.
+// Safe for concurrent use from parallel goroutines.
+func (r *ClusterReconciler) applyNodeMachineConfig(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, role string, node k8znerv1alpha1.NodeStatus, desired desiredNodeConfig, mode string) error {
+	logger := log.FromContext(ctx)
+
+	fail := func(err error, phase k8znerv1alpha1.NodePhase) error {
+		logger.Error(err, "machine config apply failed", "node", node.Name, "role", role)
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonConfigApplyError,
+			"Failed to apply machine config to %s %s: %v", role, node.Name, err)
+		r.updateNodePhase(ctx, cluster, role, nodeStatusUpdate{
+			Name:   node.Name,
+			Phase:  phase,
+			Reason: fmt.Sprintf("Machine config apply failed: %v", err),
+		})
+		return fmt.Errorf("failed to apply machine config to %s: %w", node.Name, err)
+	}
+
+	logger.Info("applying machine config to node",
+		"node", node.Name,
+		"role", role,
+		"mode", mode,
+		"from", shortConfigHash(node.ConfigHash),
+		"to", shortConfigHash(desired.hash),
+	)
+
+	applied, err := tc.configGen.ApplyMachineConfig(ctx, talosEndpointForNode(node), desired.data, mode)
+	if err != nil {
+		// Talos rejected the config, so the node still runs the old one
+		return fail(err, k8znerv1alpha1.NodePhaseReady)
+	}
+
+	if applied == talos.ApplyModeStaged {
+		r.updateNodePhase(ctx, cluster, role, nodeStatusUpdate{
+			Name:             node.Name,
+			Phase:            k8znerv1alpha1.NodePhaseReady,
+			Reason:           "Machine config staged for the next reboot",
+			StagedConfigHash: desired.hash,
+		})
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonConfigApplied,
+			"Staged machine config on %s %s; it takes effect on the next reboot", role, node.Name)
+		return nil
+	}
+
+	if err := r.configHashWaiter(ctx, node.Name, desired.hash, machineConfigApplyTimeout); err != nil {
+		return fail(fmt.Errorf("node did not report the new config: %w", err), k8znerv1alpha1.NodePhaseUnhealthy)
+	}
+
+	r.updateNodePhase(ctx, cluster, role, nodeStatusUpdate{
+		Name:       node.Name,
+		Phase:      k8znerv1alpha1.NodePhaseReady,
+		Reason:     fmt.Sprintf("Applied machine config %s (%s)", shortConfigHash(desired.hash), applied),
+		ConfigHash: desired.hash,
+	})
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonConfigApplied,
+		"Applied machine config to %s %s (%s)", role, node.Name, applied)
+
+	return nil
+}
+
+// markApplyingMachineConfig moves a node into the Upgrading phase while its config is applied.
+func (r *ClusterReconciler) markApplyingMachineConfig(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, role string, node k8znerv1alpha1.NodeStatus, mode string) {
+	r.updateNodePhase(ctx, cluster, role, nodeStatusUpdate{
+		Name:   node.Name,
+		Phase:  k8znerv1alpha1.NodePhaseUpgrading,
+		Reason: fmt.Sprintf("Applying machine config (%s mode)", mode),
+	})
+}
+
+// completeMachineConfigSync marks the machine config condition as done once every node runs the desired config.
+func (r *ClusterReconciler) completeMachineConfigSync(cluster *k8znerv1alpha1.K8znerCluster) {
+	prev := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionMachineConfigSynced)
+	if prev != nil && prev.Status == metav1.ConditionFalse {
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, EventReasonConfigSynced,
+			"All nodes run the desired machine config")
+	}
+	setMachineConfigCondition(cluster, metav1.ConditionTrue, "InSync", "All nodes run the desired machine config")
+}
+
+// desiredMachineConfigs generates the machine config each node of the given role should
+// run, keyed by node name. Nodes without a known server ID are skipped since their
+// config cannot be reproduced.
+func desiredMachineConfigs(cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, role string) (map[string]desiredNodeConfig, error) {
+	nodes := cluster.Status.Workers.Nodes
+	var sans []string
+	if role == "control-plane" {
+		nodes = cluster.Status.ControlPlanes.Nodes
+		sans = buildClusterSANs(cluster)
+	}
+
+	desired := make(map[string]desiredNodeConfig, len(nodes))
+	for _, node := range nodes {
+		if node.ServerID == 0 {
+			continue
+		}
+
+		var data []byte
+		var err error
+		if role == "control-plane" {
+			// Mirrors configureCPNode, which adds the node's own public IP
+			nodeSANs := append(append([]string{}, sans...), node.PublicIP)
+			data, err = tc.configGen.GenerateControlPlaneConfig(nodeSANs, node.Name, node.ServerID)
+		} else {
+			data, err = tc.configGen.GenerateWorkerConfig(node.Name, node.ServerID, workerPoolConfig(cluster, &node))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate config for %s: %w", node.Name, err)
+		}
+
+		hash, err := talos.ConfigHash(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash config for %s: %w", node.Name, err)
+		}
+		desired[node.Name] = desiredNodeConfig{data: data, hash: hash}
+	}
+
+	return desired, nil
+}
+
+// nodesWithConfigDrift returns healthy nodes that neither run nor have staged their
+// desired config, sorted by name so nodes are reconfigured in a stable order.
+func nodesWithConfigDrift(nodes []k8znerv1alpha1.NodeStatus, desired map[string]desiredNodeConfig) []k8znerv1alpha1.NodeStatus {
+	var pending []k8znerv1alpha1.NodeStatus
+	for _, node := range nodes {
+		if node.Healthy && !runsDesiredConfig(node, desired) {
+			pending = append(pending, node)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Name < pending[j].Name })
+	return pending
+}
+
+// machineConfigsInSync reports whether every node with a known desired config runs or has staged it.
+func machineConfigsInSync(nodes []k8znerv1alpha1.NodeStatus, desired map[string]desiredNodeConfig) bool {
+	for _, node := range nodes {
+		if !runsDesiredConfig(node, desired) {
+			return false
+		}
+	}
+	return true
+}
+
+// runsDesiredConfig reports whether a node runs or has staged its desired config.
+// Nodes without a desired config are treated as in sync.
+func runsDesiredConfig(node k8znerv1alpha1.NodeStatus, desired map[string]desiredNodeConfig) bool {
+	want, ok := desired[node.Name]
+	if !ok {
+		return true
+	}
+	return node.ConfigHash == want.hash || node.StagedConfigHash == want.hash
+}
+
+// machineConfigApplyMode returns the configured apply mode, defaulting to auto.
+func machineConfigApplyMode(cluster *k8znerv1alpha1.K8znerCluster) string {
+	if cluster.Spec.Talos.ConfigApplyMode == "" {
+		return talos.ApplyModeAuto
+	}
+	return cluster.Spec.Talos.ConfigApplyMode
+}
+
+// shortConfigHash shortens a config hash for logs and messages.
+func shortConfigHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// setMachineConfigCondition sets the MachineConfigSynced condition.
+func setMachineConfigCondition(cluster *k8znerv1alpha1.K8znerCluster, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:    k8znerv1alpha1.ConditionMachineConfigSynced,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
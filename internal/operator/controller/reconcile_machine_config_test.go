@@ -0,0 +1,319 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+)
+
+// newConfigDriftTestGen returns a generator whose configs depend on the node and a revision,
+// so changing the revision simulates a change in machine config options.
+func newConfigDriftTestGen(revision string) *MockTalosConfigGenerator {
+	return &MockTalosConfigGenerator{
+		GenerateControlPlaneConfigFunc: func(_ []string, hostname string, serverID int64) ([]byte, error) {
+			return fmt.Appendf(nil, "machine:\n  type: controlplane\n  network:\n    hostname: %s\n  nodeLabels:\n    nodeid: \"%d\"\n  sysctls:\n    revision: %q\n", hostname, serverID, revision), nil
+		},
+		GenerateWorkerConfigFunc: func(hostname string, serverID int64) ([]byte, error) {
+			return fmt.Appendf(nil, "machine:\n  type: worker\n  network:\n    hostname: %s\n  nodeLabels:\n    nodeid: \"%d\"\n  sysctls:\n    revision: %q\n", hostname, serverID, revision), nil
+		},
+	}
+}
+
+// configHashFor returns the hash the test generator produces for a node.
+func configHashFor(t *testing.T, gen *MockTalosConfigGenerator, role, name string, serverID int64) string {
+	t.Helper()
+	var data []byte
+	var err error
+	if role == "control-plane" {
+		data, err = gen.GenerateControlPlaneConfigFunc(nil, name, serverID)
+	} else {
+		data, err = gen.GenerateWorkerConfigFunc(name, serverID)
+	}
+	require.NoError(t, err)
+	hash, err := talos.ConfigHash(data)
+	require.NoError(t, err)
+	return hash
+}
+
+// newConfigDriftTestCluster builds a healthy cluster whose nodes report the hash
+// of the given generator's configs.
+func newConfigDriftTestCluster(t *testing.T, gen *MockTalosConfigGenerator, cps, workers int) *k8znerv1alpha1.K8znerCluster {
+	t.Helper()
+	cluster := &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			ControlPlanes: k8znerv1alpha1.ControlPlaneSpec{Count: cps},
+			Workers:       k8znerv1alpha1.WorkerSpec{Count: workers},
+		},
+	}
+	for i := range cps {
+		name := fmt.Sprintf("cp-%d", i+1)
+		serverID := int64(100 + i)
+		cluster.Status.ControlPlanes.Nodes = append(cluster.Status.ControlPlanes.Nodes, k8znerv1alpha1.NodeStatus{
+			Name: name, ServerID: serverID, PrivateIP: fmt.Sprintf("10.0.1.%d", i+1), Healthy: true,
+			ConfigHash: configHashFor(t, gen, "control-plane", name, serverID),
+		})
+	}
+	for i := range workers {
+		name := fmt.Sprintf("worker-%d", i+1)
+		serverID := int64(200 + i)
+		cluster.Status.Workers.Nodes = append(cluster.Status.Workers.Nodes, k8znerv1alpha1.NodeStatus{
+			Name: name, ServerID: serverID, PrivateIP: fmt.Sprintf("10.0.2.%d", i+1), Healthy: true,
+			ConfigHash: configHashFor(t, gen, "worker", name, serverID),
+		})
+	}
+	cluster.Status.ControlPlanes.Ready = cps
+	cluster.Status.Workers.Ready = workers
+	return cluster
+}
+
+func TestReconcileMachineConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("marks configs in sync when every node reports its desired hash", func(t *testing.T) {
+		t.Parallel()
+		gen := newConfigDriftTestGen("1")
+		cluster := newConfigDriftTestCluster(t, gen, 1, 2)
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Empty(t, gen.ApplyMachineConfigCalls)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionMachineConfigSynced)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	})
+
+	t.Run("reconfigures one control plane before any worker", func(t *testing.T) {
+		t.Parallel()
+		cluster := newConfigDriftTestCluster(t, newConfigDriftTestGen("1"), 3, 1)
+		gen := newConfigDriftTestGen("2")
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+
+		require.Len(t, gen.ApplyMachineConfigCalls, 1)
+		call := gen.ApplyMachineConfigCalls[0]
+		assert.Equal(t, "10.0.1.1", call.Endpoint)
+		assert.Equal(t, talos.ApplyModeAuto, call.Mode)
+		assert.Contains(t, string(call.Data), "hostname: cp-1")
+
+		cp1 := findNodeStatus(cluster.Status.ControlPlanes.Nodes, "cp-1")
+		require.NotNil(t, cp1)
+		assert.Equal(t, configHashFor(t, gen, "control-plane", "cp-1", 100), cp1.ConfigHash)
+		assert.Equal(t, k8znerv1alpha1.NodePhaseReady, cp1.Phase)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionMachineConfigSynced)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, "Applying", cond.Reason)
+	})
+
+	t.Run("reconfigures workers in batches with the configured mode", func(t *testing.T) {
+		t.Parallel()
+		cluster := newConfigDriftTestCluster(t, newConfigDriftTestGen("1"), 1, 3)
+		gen := newConfigDriftTestGen("1")
+		cluster.Spec.Talos.UpgradeBatchSize = 2
+		cluster.Spec.Talos.ConfigApplyMode = talos.ApplyModeNoReboot
+		for i := range cluster.Status.Workers.Nodes {
+			cluster.Status.Workers.Nodes[i].ConfigHash = ""
+		}
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+
+		require.Len(t, gen.ApplyMachineConfigCalls, 2)
+		endpoints := []string{gen.ApplyMachineConfigCalls[0].Endpoint, gen.ApplyMachineConfigCalls[1].Endpoint}
+		assert.ElementsMatch(t, []string{"10.0.2.1", "10.0.2.2"}, endpoints)
+		for _, call := range gen.ApplyMachineConfigCalls {
+			assert.Equal(t, talos.ApplyModeNoReboot, call.Mode)
+		}
+		assert.Empty(t, findNodeStatus(cluster.Status.Workers.Nodes, "worker-3").ConfigHash)
+	})
+
+	t.Run("records staged configs without waiting for the node", func(t *testing.T) {
+		t.Parallel()
+		cluster := newConfigDriftTestCluster(t, newConfigDriftTestGen("1"), 0, 1)
+		gen := newConfigDriftTestGen("2")
+		cluster.Spec.Talos.ConfigApplyMode = talos.ApplyModeStaged
+		waited := false
+		r := newTestReconciler(t, []client.Object{cluster},
+			WithTalosConfigGenerator(gen),
+			WithConfigHashWaiter(func(_ context.Context, _, _ string, _ time.Duration) error {
+				waited = true
+				return nil
+			}),
+		)
+
+		_, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		require.Len(t, gen.ApplyMachineConfigCalls, 1)
+		assert.False(t, waited)
+
+		worker := findNodeStatus(cluster.Status.Workers.Nodes, "worker-1")
+		require.NotNil(t, worker)
+		want := configHashFor(t, gen, "worker", "worker-1", 200)
+		assert.Equal(t, want, worker.StagedConfigHash)
+		assert.NotEqual(t, want, worker.ConfigHash)
+
+		// A staged config is not applied again
+		result, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Len(t, gen.ApplyMachineConfigCalls, 1)
+	})
+
+	t.Run("waits for version upgrades to finish", func(t *testing.T) {
+		t.Parallel()
+		cluster := newConfigDriftTestCluster(t, newConfigDriftTestGen("1"), 1, 1)
+		setKubernetesUpgradeCondition(cluster, metav1.ConditionFalse, "UpgradeInProgress", "upgrading")
+		gen := newConfigDriftTestGen("2")
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Empty(t, gen.ApplyMachineConfigCalls)
+		assert.Empty(t, gen.GenerateWorkerConfigCalls)
+	})
+
+	t.Run("waits for healthy nodes", func(t *testing.T) {
+		t.Parallel()
+		cluster := newConfigDriftTestCluster(t, newConfigDriftTestGen("1"), 1, 2)
+		cluster.Status.Workers.Nodes[1].Healthy = false
+		cluster.Status.Workers.Ready = 1
+		gen := newConfigDriftTestGen("2")
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Empty(t, gen.ApplyMachineConfigCalls)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionMachineConfigSynced)
+		require.NotNil(t, cond)
+		assert.Equal(t, "WaitingForHealthyNodes", cond.Reason)
+	})
+
+	t.Run("leaves adopted configs alone while paused", func(t *testing.T) {
+		t.Parallel()
+		cluster := newConfigDriftTestCluster(t, newConfigDriftTestGen("1"), 1, 1)
+		for _, nodes := range []*[]k8znerv1alpha1.NodeStatus{&cluster.Status.ControlPlanes.Nodes, &cluster.Status.Workers.Nodes} {
+			for i := range *nodes {
+				(*nodes)[i].ConfigHash = "" // imported nodes report no hash
+			}
+		}
+		cluster.Annotations = map[string]string{k8znerv1alpha1.PauseMachineConfigSyncAnnotation: "true"}
+		gen := newConfigDriftTestGen("1")
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Empty(t, gen.ApplyMachineConfigCalls)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionMachineConfigSynced)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, "Paused", cond.Reason)
+		assert.Contains(t, cond.Message, "2 nodes")
+	})
+
+	t.Run("skips nodes without a server ID", func(t *testing.T) {
+		t.Parallel()
+		cluster := newConfigDriftTestCluster(t, newConfigDriftTestGen("1"), 0, 1)
+		cluster.Status.Workers.Nodes[0].ServerID = 0
+		gen := newConfigDriftTestGen("2")
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		_, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Empty(t, gen.GenerateWorkerConfigCalls)
+		assert.Empty(t, gen.ApplyMachineConfigCalls)
+	})
+
+	t.Run("rejected config leaves the node ready and backs off", func(t *testing.T) {
+		t.Parallel()
+		cluster := newConfigDriftTestCluster(t, newConfigDriftTestGen("1"), 1, 0)
+		gen := newConfigDriftTestGen("2")
+		gen.ApplyMachineConfigFunc = func(_ context.Context, _ string, _ []byte, _ string) (string, error) {
+			return "", fmt.Errorf("config change requires a reboot")
+		}
+		cluster.Spec.Talos.ConfigApplyMode = talos.ApplyModeNoReboot
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+
+		cp1 := findNodeStatus(cluster.Status.ControlPlanes.Nodes, "cp-1")
+		require.NotNil(t, cp1)
+		assert.Equal(t, k8znerv1alpha1.NodePhaseReady, cp1.Phase)
+		assert.True(t, cp1.Healthy)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionMachineConfigSynced)
+		require.NotNil(t, cond)
+		assert.Equal(t, "ApplyFailed", cond.Reason)
+	})
+
+	t.Run("node that does not report the new config is marked unhealthy", func(t *testing.T) {
+		t.Parallel()
+		cluster := newConfigDriftTestCluster(t, newConfigDriftTestGen("1"), 0, 1)
+		gen := newConfigDriftTestGen("2")
+		r := newTestReconciler(t, []client.Object{cluster},
+			WithTalosConfigGenerator(gen),
+			WithConfigHashWaiter(func(_ context.Context, _, _ string, _ time.Duration) error {
+				return fmt.Errorf("timeout")
+			}),
+		)
+
+		result, err := r.reconcileMachineConfig(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Equal(t, k8znerv1alpha1.NodePhaseUnhealthy, findNodeStatus(cluster.Status.Workers.Nodes, "worker-1").Phase)
+	})
+}
+
+func TestNodesWithConfigDrift(t *testing.T) {
+	t.Parallel()
+
+	desired := map[string]desiredNodeConfig{
+		"a": {hash: "new"},
+		"b": {hash: "new"},
+		"c": {hash: "new"},
+		"d": {hash: "new"},
+	}
+	nodes := []k8znerv1alpha1.NodeStatus{
+		{Name: "d", Healthy: true, ConfigHash: "old"},
+		{Name: "a", Healthy: true, ConfigHash: "new"},
+		{Name: "b", Healthy: true, ConfigHash: "old", StagedConfigHash: "new"},
+		{Name: "c", Healthy: false, ConfigHash: "old"},
+		{Name: "e", Healthy: true},
+		{Name: "f", Healthy: true, ConfigHash: ""},
+	}
+	desired["f"] = desiredNodeConfig{hash: "new"}
+
+	pending := nodesWithConfigDrift(nodes, desired)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "d", pending[0].Name)
+	assert.Equal(t, "f", pending[1].Name)
+
+	assert.False(t, machineConfigsInSync(nodes, desired), "unhealthy node c still drifts")
+	assert.True(t, machineConfigsInSync(nodes[1:3], desired))
+}
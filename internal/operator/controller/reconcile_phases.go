@@ -300,6 +300,11 @@ func (r *ClusterReconciler) reconcileRunningPhase(ctx context.Context, cluster *
 		return ctrl.Result{}, fmt.Errorf("health check failed: %w", err)
 	}
 
+	// Disruptive steps below are deferred outside maintenance windows
+	previousPending := r.startMaintenancePass(ctx, cluster, time.Now())
+	complete := false
+	defer func() { r.finishMaintenancePass(cluster, previousPending, complete) }()
+
 	// Handle scaling first — health probes have network timeouts that slow the reconcile loop
 	if result, err := r.reconcileControlPlanes(ctx, cluster); err != nil || result.RequeueAfter > 0 {
 		return result, err
@@ -309,11 +314,35 @@ func (r *ClusterReconciler) reconcileRunningPhase(ctx context.Context, cluster *
 		return result, err
 	}
 
+	// Server size changes, version upgrades and machine config changes only run once node counts have converged
+	if result, err := r.reconcileServerTypeRollout(ctx, cluster); err != nil || result.RequeueAfter > 0 {
+		return result, err
+	}
+
+	if result, err := r.reconcileTalosUpgrade(ctx, cluster); err != nil || result.RequeueAfter > 0 {
+		return result, err
+	}
+
+	if result, err := r.reconcileKubernetesUpgrade(ctx, cluster); err != nil || result.RequeueAfter > 0 {
+		return result, err
+	}
+
+	if result, err := r.reconcileCARotation(ctx, cluster); err != nil || result.RequeueAfter > 0 {
+		return result, err
+	}
+
+	if result, err := r.reconcileMachineConfig(ctx, cluster); err != nil || result.RequeueAfter > 0 {
+		return result, err
+	}
+
 	// Non-fatal health probes: only run when cluster is stable (no scaling in progress)
 	r.reconcileInfraHealth(ctx, cluster)
 	r.reconcileAddonHealth(ctx, cluster)
 	r.reconcileConnectivityHealth(ctx, cluster)
+	r.reconcileBackupRetention(ctx, cluster)
+	r.reconcileBackupVerification(ctx, cluster)
 
+	complete = true
 	return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
 }
 
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/util/labels"
+)
+
+// reconcileServerTypeRollout replaces nodes whose Hetzner server type no longer matches
+// spec.controlPlanes.size or the size of their worker pool. Replacement is surge-style: new
+// nodes are created and become Ready before outdated ones are drained and deleted.
+// Control planes go first, one at a time; workers follow pool by pool within
+// spec.rollingUpdate limits.
+func (r *ClusterReconciler) reconcileServerTypeRollout(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if r.hcloudClient == nil {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.refreshServerTypes(ctx, cluster); err != nil {
+		logger.Error(err, "failed to read server types from HCloud")
+		return ctrl.Result{}, nil
+	}
+
+	cpOutdated := nodesWithOutdatedServerType(cluster.Status.ControlPlanes.Nodes, cluster.Spec.ControlPlanes.Size)
+	var workerPool k8znerv1alpha1.WorkerPoolSpec
+	var workerOutdated []k8znerv1alpha1.NodeStatus
+	for _, pool := range workerPools(cluster) {
+		var nodes []k8znerv1alpha1.NodeStatus
+		for _, node := range workerPoolNodes(cluster, pool.Name) {
+			nodes = append(nodes, *node)
+		}
+		if outdated := nodesWithOutdatedServerType(nodes, pool.Size); len(outdated) > 0 {
+			workerPool, workerOutdated = pool, outdated
+			break
+		}
+	}
+	if len(cpOutdated) == 0 && len(workerOutdated) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	// Never start a replacement step on a degraded cluster; healing runs first.
+	if cluster.Status.ControlPlanes.Ready < cluster.Spec.ControlPlanes.Count ||
+		cluster.Status.Workers.Ready < desiredWorkerCount(cluster) {
+		logger.Info("waiting for all nodes to be healthy before replacing outdated servers",
+			"controlPlanesReady", cluster.Status.ControlPlanes.Ready,
+			"workersReady", cluster.Status.Workers.Ready,
+		)
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	target, size, outdated := workerPool.Name, workerPool.Size, len(workerOutdated)
+	if len(cpOutdated) > 0 {
+		target, size, outdated = "control-plane", cluster.Spec.ControlPlanes.Size, len(cpOutdated)
+	}
+	if !r.disruptionAllowed(ctx, cluster, maintenanceActionServerTypeRollout, target,
+		fmt.Sprintf("%d nodes need replacing with server type %s", outdated, normalizedServerType(size))) {
+		return ctrl.Result{}, nil
+	}
+
+	cluster.Status.Phase = k8znerv1alpha1.ClusterPhaseHealing
+
+	if len(cpOutdated) > 0 {
+		return r.rollControlPlane(ctx, cluster, cpOutdated[0])
+	}
+
+	return r.rollWorkers(ctx, cluster, workerPool, workerOutdated)
+}
+
+// rollControlPlane adds one control plane of the desired size, then removes the outdated
+// one from etcd and deletes it. The new member joins before the old one leaves, so etcd
+// quorum is never reduced.
+func (r *ClusterReconciler) rollControlPlane(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, old k8znerv1alpha1.NodeStatus) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	desired := normalizedServerType(cluster.Spec.ControlPlanes.Size)
+
+	tc := r.loadTalosClients(ctx, cluster)
+	if err := r.takePreOperationSnapshot(ctx, cluster, tc, snapshotOpCPResize, old.Name); err != nil {
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	logger.Info("replacing control plane with outdated server type",
+		"node", old.Name, "from", old.ServerType, "to", desired)
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonNodeReplacing,
+		"Replacing control plane %s: server type %s -> %s", old.Name, old.ServerType, desired)
+
+	startTime := time.Now()
+	if err := r.scaleUpControlPlanes(ctx, cluster, 1); err != nil {
+		// The old node stays until a replacement is Ready.
+		logger.Error(err, "failed to create replacement control plane", "node", old.Name)
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	r.cordonNode(ctx, old.Name)
+	if err := r.drainNode(ctx, old.Name); err != nil {
+		logger.Error(err, "failed to drain node", "node", old.Name)
+	}
+	r.removeFromEtcd(ctx, cluster, tc, &old)
+	if err := r.deleteNodeAndServer(ctx, cluster, &old, "control-plane"); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to delete outdated control plane %s: %w", old.Name, err)
+	}
+	r.removeNodeFromStatus(cluster, "control-plane", old.Name)
+
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status after control plane replacement")
+	}
+
+	r.recordNodeReplacement(cluster.Name, "control-plane", "server-type-change")
+	r.recordNodeReplacementDuration(cluster.Name, "control-plane", time.Since(startTime).Seconds())
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonNodeReplaced,
+		"Replaced control plane %s with a %s server", old.Name, desired)
+
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// rollWorkers replaces one batch of outdated workers in pool. Up to maxUnavailable workers
+// are removed up front; up to maxSurge more are removed only after all replacements are Ready.
+func (r *ClusterReconciler) rollWorkers(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, pool k8znerv1alpha1.WorkerPoolSpec, outdated []k8znerv1alpha1.NodeStatus) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	desired := normalizedServerType(pool.Size)
+
+	surge, unavailable := rollingUpdateLimits(cluster)
+	surge = min(surge, len(outdated))
+	unavailable = min(unavailable, len(outdated)-surge)
+	removeFirst := outdated[:unavailable]
+	removeAfter := outdated[unavailable : unavailable+surge]
+
+	names := make([]string, 0, surge+unavailable)
+	for _, node := range outdated[:surge+unavailable] {
+		names = append(names, node.Name)
+	}
+	logger.Info("replacing workers with outdated server type",
+		"pool", pool.Name, "nodes", names, "to", desired, "maxSurge", surge, "maxUnavailable", unavailable, "remaining", len(outdated))
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonNodeReplacing,
+		"Replacing workers %v with %s servers (%d outdated)", names, desired, len(outdated))
+
+	startTime := time.Now()
+	for _, node := range removeFirst {
+		r.retireWorker(ctx, cluster, node)
+	}
+
+	if err := r.scaleUpWorkers(ctx, cluster, pool, surge+unavailable); err != nil {
+		// Keep the surge nodes; any extra workers are scaled down on the next reconcile,
+		// which prefers outdated servers.
+		logger.Error(err, "failed to create replacement workers")
+		if persistErr := r.persistClusterStatus(ctx, cluster); persistErr != nil {
+			logger.Error(persistErr, "failed to persist status after worker replacement")
+		}
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	for _, node := range removeAfter {
+		r.retireWorker(ctx, cluster, node)
+	}
+
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status after worker replacement")
+	}
+
+	r.recordNodeReplacementDuration(cluster.Name, "worker", time.Since(startTime).Seconds())
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonNodeReplaced,
+		"Replaced workers %v with %s servers", names, desired)
+
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// retireWorker drains and deletes an outdated worker and drops it from status.
+func (r *ClusterReconciler) retireWorker(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, node k8znerv1alpha1.NodeStatus) {
+	logger := log.FromContext(ctx)
+
+	if err := r.drainAndDeleteWorker(ctx, cluster, &node); err != nil {
+		logger.Error(err, "failed to delete outdated worker", "node", node.Name)
+		return
+	}
+	r.removeNodeFromStatus(cluster, "worker", node.Name)
+	r.recordNodeReplacement(cluster.Name, "worker", "server-type-change")
+}
+
+// cordonNode marks a Kubernetes node unschedulable. Failures are logged, not returned,
+// because the node is about to be drained and deleted anyway.
+func (r *ClusterReconciler) cordonNode(ctx context.Context, nodeName string) {
+	logger := log.FromContext(ctx)
+
+	k8sNode := &corev1.Node{}
+	if err := r.Get(ctx, types.NamespacedName{Name: nodeName}, k8sNode); err != nil || k8sNode.Spec.Unschedulable {
+		return
+	}
+	k8sNode.Spec.Unschedulable = true
+	if err := r.Update(ctx, k8sNode); err != nil {
+		logger.Error(err, "failed to cordon node", "node", nodeName)
+		return
+	}
+	logger.Info("cordoned node", "node", nodeName)
+}
+
+// refreshServerTypes records the Hetzner server type of every tracked node.
+func (r *ClusterReconciler) refreshServerTypes(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) error {
+	servers, err := r.hcloudClient.GetServersByLabel(ctx, map[string]string{labels.KeyCluster: cluster.Name})
+	if err != nil {
+		return err
+	}
+
+	serverTypes := make(map[string]string, len(servers))
+	for _, server := range servers {
+		if server != nil && server.ServerType != nil {
+			serverTypes[server.Name] = server.ServerType.Name
+		}
+	}
+
+	for _, nodes := range []*[]k8znerv1alpha1.NodeStatus{&cluster.Status.ControlPlanes.Nodes, &cluster.Status.Workers.Nodes} {
+		for i := range *nodes {
+			if serverType, ok := serverTypes[(*nodes)[i].Name]; ok {
+				(*nodes)[i].ServerType = serverType
+			}
+		}
+	}
+
+	return nil
+}
+
+// nodesWithOutdatedServerType returns nodes whose observed server type differs from size,
+// sorted by name so replacements proceed in a stable order. Legacy type names are
+// normalized first, so a cx22 server is not replaced just because the spec says cx23.
+func nodesWithOutdatedServerType(nodes []k8znerv1alpha1.NodeStatus, size string) []k8znerv1alpha1.NodeStatus {
+	desired := normalizedServerType(size)
+	if desired == "" {
+		return nil
+	}
+
+	var outdated []k8znerv1alpha1.NodeStatus
+	for _, node := range nodes {
+		if node.ServerType != "" && normalizedServerType(node.ServerType) != desired {
+			outdated = append(outdated, node)
+		}
+	}
+	sort.Slice(outdated, func(i, j int) bool { return outdated[i].Name < outdated[j].Name })
+	return outdated
+}
+
+// rollingUpdateLimits returns the worker maxSurge and maxUnavailable, applying defaults.
+// At least one of them is always positive so the rollout can make progress.
+func rollingUpdateLimits(cluster *k8znerv1alpha1.K8znerCluster) (surge, unavailable int) {
+	surge = 1
+	if ru := cluster.Spec.RollingUpdate; ru != nil {
+		if ru.MaxSurge != nil {
+			surge = max(*ru.MaxSurge, 0)
+		}
+		unavailable = max(ru.MaxUnavailable, 0)
+	}
+	if surge == 0 && unavailable == 0 {
+		surge = 1
+	}
+	return surge, unavailable
+}
+
+// normalizedServerType maps a size to its current Hetzner server type name.
+func normalizedServerType(size string) string {
+	return string(config.ServerSize(size).Normalize())
+}
@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	hcloudgo "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+)
+
+// newRolloutTestCluster builds a healthy cluster whose nodes run on the given server types.
+func newRolloutTestCluster(cpTypes, workerTypes []string) *k8znerv1alpha1.K8znerCluster {
+	cluster := &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			Region:        "nbg1",
+			ControlPlanes: k8znerv1alpha1.ControlPlaneSpec{Count: len(cpTypes), Size: "cx23"},
+			Workers:       k8znerv1alpha1.WorkerSpec{Count: len(workerTypes), Size: "cx33"},
+		},
+		Status: k8znerv1alpha1.K8znerClusterStatus{
+			Infrastructure: k8znerv1alpha1.InfrastructureStatus{NetworkID: 123},
+		},
+	}
+	for i := range cpTypes {
+		cluster.Status.ControlPlanes.Nodes = append(cluster.Status.ControlPlanes.Nodes, k8znerv1alpha1.NodeStatus{
+			Name: fmt.Sprintf("cp-%d", i+1), PrivateIP: fmt.Sprintf("10.0.1.%d", i+1), Healthy: true,
+		})
+	}
+	for i := range workerTypes {
+		cluster.Status.Workers.Nodes = append(cluster.Status.Workers.Nodes, k8znerv1alpha1.NodeStatus{
+			Name: fmt.Sprintf("worker-%d", i+1), PrivateIP: fmt.Sprintf("10.0.2.%d", i+1), Healthy: true,
+		})
+	}
+	cluster.Status.ControlPlanes.Ready = len(cpTypes)
+	cluster.Status.Workers.Ready = len(workerTypes)
+	return cluster
+}
+
+// rolloutHCloud returns an HCloud mock that reports the given server types by node name.
+func rolloutHCloud(cpTypes, workerTypes []string) *MockHCloudClient {
+	var servers []*hcloudgo.Server
+	for i, serverType := range cpTypes {
+		servers = append(servers, &hcloudgo.Server{Name: fmt.Sprintf("cp-%d", i+1), ServerType: &hcloudgo.ServerType{Name: serverType}})
+	}
+	for i, serverType := range workerTypes {
+		servers = append(servers, &hcloudgo.Server{Name: fmt.Sprintf("worker-%d", i+1), ServerType: &hcloudgo.ServerType{Name: serverType}})
+	}
+	return &MockHCloudClient{
+		GetServersByLabelFunc: func(_ context.Context, _ map[string]string) ([]*hcloudgo.Server, error) {
+			return servers, nil
+		},
+	}
+}
+
+func TestReconcileServerTypeRollout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no-op when all servers match the spec", func(t *testing.T) {
+		t.Parallel()
+		cpTypes, workerTypes := []string{"cx22"}, []string{"cx33", "cx33"}
+		cluster := newRolloutTestCluster(cpTypes, workerTypes)
+		hcloud := rolloutHCloud(cpTypes, workerTypes)
+		r := newTestReconciler(t, []client.Object{cluster}, WithHCloudClient(hcloud))
+
+		result, err := r.reconcileServerTypeRollout(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Empty(t, hcloud.CreateServerCalls)
+		assert.Equal(t, "cx22", cluster.Status.ControlPlanes.Nodes[0].ServerType)
+	})
+
+	t.Run("surges one worker before deleting the outdated one", func(t *testing.T) {
+		t.Parallel()
+		cpTypes, workerTypes := []string{"cx23"}, []string{"cx23", "cx23"}
+		cluster := newRolloutTestCluster(cpTypes, workerTypes)
+		hcloud := rolloutHCloud(cpTypes, workerTypes)
+		r := newTestReconciler(t, []client.Object{cluster}, WithHCloudClient(hcloud))
+
+		result, err := r.reconcileServerTypeRollout(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+
+		require.Len(t, hcloud.CreateServerCalls, 1)
+		assert.Equal(t, "cx33", hcloud.CreateServerCalls[0].ServerType)
+		assert.Equal(t, []string{"worker-1"}, hcloud.DeleteServerCalls)
+
+		assert.Nil(t, findNodeStatus(cluster.Status.Workers.Nodes, "worker-1"))
+		assert.NotNil(t, findNodeStatus(cluster.Status.Workers.Nodes, "worker-2"))
+		assert.Len(t, cluster.Status.Workers.Nodes, 2)
+	})
+
+	t.Run("respects maxSurge and maxUnavailable", func(t *testing.T) {
+		t.Parallel()
+		cpTypes, workerTypes := []string{"cx23"}, []string{"cx23", "cx23", "cx23", "cx23"}
+		cluster := newRolloutTestCluster(cpTypes, workerTypes)
+		surge := 2
+		cluster.Spec.RollingUpdate = &k8znerv1alpha1.RollingUpdateSpec{MaxSurge: &surge, MaxUnavailable: 1}
+		hcloud := rolloutHCloud(cpTypes, workerTypes)
+		r := newTestReconciler(t, []client.Object{cluster}, WithHCloudClient(hcloud))
+
+		_, err := r.reconcileServerTypeRollout(context.Background(), cluster)
+		require.NoError(t, err)
+
+		assert.Len(t, hcloud.CreateServerCalls, 3)
+		assert.Equal(t, []string{"worker-1", "worker-2", "worker-3"}, hcloud.DeleteServerCalls)
+		assert.NotNil(t, findNodeStatus(cluster.Status.Workers.Nodes, "worker-4"))
+	})
+
+	t.Run("keeps outdated worker when replacement fails", func(t *testing.T) {
+		t.Parallel()
+		cpTypes, workerTypes := []string{"cx23"}, []string{"cx23"}
+		cluster := newRolloutTestCluster(cpTypes, workerTypes)
+		hcloud := rolloutHCloud(cpTypes, workerTypes)
+		r := newTestReconciler(t, []client.Object{cluster},
+			WithHCloudClient(hcloud),
+			WithTalosClient(&MockTalosClient{
+				ApplyConfigFunc: func(_ context.Context, _ string, _ []byte) error { return fmt.Errorf("boom") },
+			}),
+		)
+
+		result, err := r.reconcileServerTypeRollout(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+
+		for _, name := range hcloud.DeleteServerCalls {
+			assert.NotEqual(t, "worker-1", name)
+		}
+		assert.NotNil(t, findNodeStatus(cluster.Status.Workers.Nodes, "worker-1"))
+	})
+
+	t.Run("replaces control planes one at a time before workers", func(t *testing.T) {
+		t.Parallel()
+		cpTypes, workerTypes := []string{"cx33", "cx33", "cx33"}, []string{"cx23"}
+		cluster := newRolloutTestCluster(cpTypes, workerTypes)
+		talos := &MockTalosClient{}
+		hcloud := rolloutHCloud(cpTypes, workerTypes)
+		r := newTestReconciler(t, []client.Object{cluster}, WithHCloudClient(hcloud), WithTalosClient(talos))
+
+		result, err := r.reconcileServerTypeRollout(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+
+		require.Len(t, hcloud.CreateServerCalls, 1)
+		assert.Equal(t, "cx23", hcloud.CreateServerCalls[0].ServerType)
+		assert.True(t, strings.HasPrefix(hcloud.CreateServerCalls[0].Name, "test-cluster-cp-"),
+			"expected control plane name, got %s", hcloud.CreateServerCalls[0].Name)
+		assert.Equal(t, []string{"cp-1"}, hcloud.DeleteServerCalls)
+
+		require.Len(t, talos.RemoveEtcdMemberCalls, 1)
+		assert.Equal(t, "1", talos.RemoveEtcdMemberCalls[0].MemberID)
+		assert.NotEqual(t, "10.0.1.1", talos.RemoveEtcdMemberCalls[0].NodeIP)
+		assert.Nil(t, findNodeStatus(cluster.Status.ControlPlanes.Nodes, "cp-1"))
+	})
+
+	t.Run("waits while cluster is degraded", func(t *testing.T) {
+		t.Parallel()
+		cpTypes, workerTypes := []string{"cx23"}, []string{"cx23", "cx23"}
+		cluster := newRolloutTestCluster(cpTypes, workerTypes)
+		cluster.Status.Workers.Ready = 1
+		hcloud := rolloutHCloud(cpTypes, workerTypes)
+		r := newTestReconciler(t, []client.Object{cluster}, WithHCloudClient(hcloud))
+
+		result, err := r.reconcileServerTypeRollout(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Empty(t, hcloud.CreateServerCalls)
+	})
+}
+
+func TestNodesWithOutdatedServerType(t *testing.T) {
+	t.Parallel()
+
+	nodes := []k8znerv1alpha1.NodeStatus{
+		{Name: "worker-3", ServerType: "cx23"},
+		{Name: "worker-1", ServerType: "cx22"},
+		{Name: "worker-2", ServerType: "cpx32"},
+		{Name: "worker-4"},
+	}
+
+	outdated := nodesWithOutdatedServerType(nodes, "cx33")
+	require.Len(t, outdated, 3)
+	assert.Equal(t, "worker-1", outdated[0].Name)
+
+	// Legacy names normalize to their successor and unknown types are skipped
+	outdated = nodesWithOutdatedServerType(nodes, "cx23")
+	require.Len(t, outdated, 1)
+	assert.Equal(t, "worker-2", outdated[0].Name)
+
+	assert.Empty(t, nodesWithOutdatedServerType(nodes, ""))
+}
+
+func TestRollingUpdateLimits(t *testing.T) {
+	t.Parallel()
+
+	intPtr := func(i int) *int { return &i }
+
+	tests := []struct {
+		name            string
+		spec            *k8znerv1alpha1.RollingUpdateSpec
+		wantSurge       int
+		wantUnavailable int
+	}{
+		{name: "defaults", spec: nil, wantSurge: 1, wantUnavailable: 0},
+		{name: "explicit surge", spec: &k8znerv1alpha1.RollingUpdateSpec{MaxSurge: intPtr(3)}, wantSurge: 3},
+		{name: "replace in place", spec: &k8znerv1alpha1.RollingUpdateSpec{MaxSurge: intPtr(0), MaxUnavailable: 2}, wantUnavailable: 2},
+		{name: "both zero falls back to surge", spec: &k8znerv1alpha1.RollingUpdateSpec{MaxSurge: intPtr(0)}, wantSurge: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cluster := &k8znerv1alpha1.K8znerCluster{Spec: k8znerv1alpha1.K8znerClusterSpec{RollingUpdate: tt.spec}}
+			surge, unavailable := rollingUpdateLimits(cluster)
+			assert.Equal(t, tt.wantSurge, surge)
+			assert.Equal(t, tt.wantUnavailable, unavailable)
+		})
+	}
+}
+
+func TestSelectWorkersForRemoval_PrefersOutdatedServerType(t *testing.T) {
+	t.Parallel()
+	cluster := &k8znerv1alpha1.K8znerCluster{
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			Workers: k8znerv1alpha1.WorkerSpec{Size: "cx33"},
+		},
+		Status: k8znerv1alpha1.K8znerClusterStatus{
+			Workers: k8znerv1alpha1.NodeGroupStatus{
+				Nodes: []k8znerv1alpha1.NodeStatus{
+					{Name: "worker-1", Healthy: true, ServerType: "cx23"},
+					{Name: "worker-2", Healthy: true, ServerType: "cx33"},
+					{Name: "worker-3", Healthy: true, ServerType: "cx33"},
+				},
+			},
+		},
+	}
+
+	r := &ClusterReconciler{}
+	selected := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 1)
+	require.Len(t, selected, 1)
+	assert.Equal(t, "worker-1", selected[0].Name)
+}
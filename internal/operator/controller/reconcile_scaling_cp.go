@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -39,6 +40,10 @@ func (r *ClusterReconciler) reconcileControlPlanes(ctx context.Context, cluster
 		return r.handleCPScaleUp(ctx, cluster, currentCount, desiredCount)
 	}
 
+	if currentCount > desiredCount && desiredCount > 0 {
+		return r.handleCPScaleDown(ctx, cluster, currentCount, desiredCount)
+	}
+
 	// Skip health-based replacement if single CP (no HA replacement possible)
 	if cluster.Spec.ControlPlanes.Count == 1 {
 		return ctrl.Result{}, nil
@@ -50,6 +55,15 @@ func (r *ClusterReconciler) reconcileControlPlanes(ctx context.Context, cluster
 // handleCPScaleUp triggers control plane scale-up when current < desired.
 func (r *ClusterReconciler) handleCPScaleUp(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, currentCount, desiredCount int) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
+
+	// A new member joining etcd is the riskiest moment for an existing cluster
+	if currentCount > 0 && r.hcloudClient != nil {
+		tc := r.loadTalosClients(ctx, cluster)
+		if err := r.takePreOperationSnapshot(ctx, cluster, tc, snapshotOpCPScaleUp, ""); err != nil {
+			return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+		}
+	}
+
 	logger.Info("scaling up control planes", "current", currentCount, "desired", desiredCount)
 	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonScalingUp,
 		"Scaling up control planes: %d -> %d", currentCount, desiredCount)
@@ -64,6 +78,207 @@ func (r *ClusterReconciler) handleCPScaleUp(ctx context.Context, cluster *k8zner
 	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
 }
 
+// handleCPScaleDown removes one control plane per reconcile when current > desired.
+// Only one etcd member is removed at a time, and never the current leader or a member
+// whose removal would leave the remaining cluster without quorum.
+func (r *ClusterReconciler) handleCPScaleDown(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, currentCount, desiredCount int) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if r.hcloudClient == nil {
+		return ctrl.Result{}, nil
+	}
+
+	if !r.disruptionAllowed(ctx, cluster, maintenanceActionScaleDown, "control-plane",
+		fmt.Sprintf("scale control planes %d -> %d", currentCount, desiredCount)) {
+		return ctrl.Result{}, nil
+	}
+
+	tc := r.loadTalosClients(ctx, cluster)
+	if tc.client == nil {
+		logger.Info("skipping control plane scale-down (no Talos credentials to remove etcd member)")
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	healthyIP := r.findHealthyControlPlaneIP(cluster)
+	if healthyIP == "" {
+		logger.Info("skipping control plane scale-down (no healthy control plane to reach etcd)")
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	members, err := tc.client.GetEtcdMembers(ctx, healthyIP)
+	if err != nil {
+		logger.Error(err, "failed to list etcd members, skipping control plane scale-down")
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	candidate, member, err := selectControlPlaneForRemoval(cluster.Status.ControlPlanes.Nodes, members, cluster.Spec.ControlPlanes.Size)
+	if err != nil {
+		logger.Info("cannot scale down control planes", "reason", err.Error())
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonQuorumLost,
+			"Cannot scale down control planes %d -> %d: %v", currentCount, desiredCount, err)
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	if err := r.takePreOperationSnapshot(ctx, cluster, tc, snapshotOpCPScaleDown, candidate.Name); err != nil {
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	logger.Info("scaling down control planes",
+		"current", currentCount,
+		"desired", desiredCount,
+		"node", candidate.Name,
+	)
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonScalingDown,
+		"Scaling down control planes: %d -> %d, removing %s", currentCount, desiredCount, candidate.Name)
+
+	cluster.Status.Phase = k8znerv1alpha1.ClusterPhaseHealing
+
+	if err := r.decommissionControlPlane(ctx, cluster, tc, candidate, member); err != nil {
+		logger.Error(err, "failed to remove control plane", "node", candidate.Name)
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonScalingDown,
+			"Failed to remove control plane %s: %v", candidate.Name, err)
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// decommissionControlPlane drains a control plane, removes its etcd member through a
+// peer, then deletes the node and server. The server is kept if etcd removal fails,
+// since deleting it would leave a dead member counting against quorum.
+func (r *ClusterReconciler) decommissionControlPlane(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, node k8znerv1alpha1.NodeStatus, member *etcdMember) error {
+	logger := log.FromContext(ctx)
+	startTime := time.Now()
+
+	r.cordonNode(ctx, node.Name)
+	if err := r.drainNode(ctx, node.Name); err != nil {
+		logger.Error(err, "failed to drain node", "node", node.Name)
+	}
+
+	if member != nil {
+		peerIP := findPeerControlPlaneIP(cluster, node.Name)
+		if peerIP == "" {
+			return fmt.Errorf("no healthy peer to remove etcd member %s through", member.Name)
+		}
+
+		r.updateNodePhase(ctx, cluster, "control-plane", nodeStatusUpdate{
+			Name: node.Name, Phase: k8znerv1alpha1.NodePhaseRemovingFromEtcd,
+			Reason: "Removing etcd member before scale-down",
+		})
+		if err := tc.client.RemoveEtcdMember(ctx, peerIP, member.ID); err != nil {
+			return fmt.Errorf("failed to remove etcd member %s: %w", member.Name, err)
+		}
+		logger.Info("removed etcd member", "member", member.Name, "via", peerIP)
+	}
+
+	if err := r.deleteNodeAndServer(ctx, cluster, &node, "control-plane"); err != nil {
+		return err
+	}
+	r.removeNodeFromStatus(cluster, "control-plane", node.Name)
+
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status after control plane scale-down")
+	}
+
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonScalingDown,
+		"Successfully removed control plane %s", node.Name)
+	r.recordNodeReplacement(cluster.Name, "control-plane", "scale-down")
+	r.recordNodeReplacementDuration(cluster.Name, "control-plane", time.Since(startTime).Seconds())
+
+	return nil
+}
+
+// selectControlPlaneForRemoval picks the control plane to remove during scale-down and
+// its etcd member (nil if it never joined etcd). Like selectWorkersForRemoval it prefers
+// unhealthy nodes, then nodes on a server type other than size, then the newest healthy
+// node; the etcd leader is never chosen. An error is returned when no node can be
+// removed without losing quorum.
+func selectControlPlaneForRemoval(nodes []k8znerv1alpha1.NodeStatus, members []etcdMember, size string) (k8znerv1alpha1.NodeStatus, *etcdMember, error) {
+	leaderKnown := false
+	for _, m := range members {
+		if m.IsLeader {
+			leaderKnown = true
+			break
+		}
+	}
+	if !leaderKnown {
+		return k8znerv1alpha1.NodeStatus{}, nil, fmt.Errorf("etcd leader is unknown")
+	}
+
+	healthy := 0
+	for _, node := range nodes {
+		if node.Healthy {
+			healthy++
+		}
+	}
+
+	// Unhealthy first, then outdated, then newest (last in status) healthy
+	desiredType := normalizedServerType(size)
+	var unhealthy, outdated, current []k8znerv1alpha1.NodeStatus
+	for _, node := range nodes {
+		switch {
+		case !node.Healthy:
+			unhealthy = append(unhealthy, node)
+		case desiredType != "" && node.ServerType != "" && normalizedServerType(node.ServerType) != desiredType:
+			outdated = append(outdated, node)
+		default:
+			current = append(current, node)
+		}
+	}
+	slices.Reverse(current)
+	candidates := slices.Concat(unhealthy, outdated, current)
+
+	// etcd must have quorum now to accept the membership change at all
+	if currentQuorum := (len(nodes) / 2) + 1; healthy < currentQuorum {
+		return k8znerv1alpha1.NodeStatus{}, nil, fmt.Errorf(
+			"only %d/%d control planes healthy, need %d for quorum", healthy, len(nodes), currentQuorum)
+	}
+
+	remaining := len(nodes) - 1
+	quorumNeeded := (remaining / 2) + 1
+
+	for _, node := range candidates {
+		member := findEtcdMember(members, node)
+		if member != nil && member.IsLeader {
+			continue
+		}
+
+		healthyAfter := healthy
+		if node.Healthy {
+			healthyAfter--
+		}
+		if healthyAfter < quorumNeeded {
+			return k8znerv1alpha1.NodeStatus{}, nil, fmt.Errorf(
+				"removing %s would leave %d/%d healthy control planes, need %d for quorum",
+				node.Name, healthyAfter, remaining, quorumNeeded)
+		}
+
+		return node, member, nil
+	}
+
+	return k8znerv1alpha1.NodeStatus{}, nil, fmt.Errorf("no control plane other than the etcd leader can be removed")
+}
+
+// findEtcdMember returns the etcd member belonging to a control plane node.
+func findEtcdMember(members []etcdMember, node k8znerv1alpha1.NodeStatus) *etcdMember {
+	for i := range members {
+		if members[i].Name == node.Name || (node.PrivateIP != "" && members[i].Endpoint == node.PrivateIP) {
+			return &members[i]
+		}
+	}
+	return nil
+}
+
+// findPeerControlPlaneIP returns the private IP of a healthy control plane other than exclude.
+func findPeerControlPlaneIP(cluster *k8znerv1alpha1.K8znerCluster, exclude string) string {
+	for _, node := range cluster.Status.ControlPlanes.Nodes {
+		if node.Name != exclude && node.Healthy && node.PrivateIP != "" {
+			return node.PrivateIP
+		}
+	}
+	return ""
+}
+
 // replaceUnhealthyCPIfNeeded finds an unhealthy CP past the threshold and replaces it.
 func (r *ClusterReconciler) replaceUnhealthyCPIfNeeded(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -97,6 +312,10 @@ func (r *ClusterReconciler) replaceUnhealthyCPIfNeeded(ctx context.Context, clus
 		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
 	}
 
+	if !r.healingAllowed(ctx, cluster, unhealthyCP) {
+		return ctrl.Result{}, nil
+	}
+
 	// Replace the unhealthy control plane
 	logger.Info("replacing unhealthy control plane",
 		"node", unhealthyCP.Name,
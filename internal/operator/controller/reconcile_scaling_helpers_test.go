@@ -164,7 +164,7 @@ func TestSelectWorkersForRemoval(t *testing.T) {
 		recorder := record.NewFakeRecorder(10)
 		r := NewClusterReconciler(client, scheme, recorder)
 
-		result := r.selectWorkersForRemoval(cluster, 0)
+		result := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 0)
 		assert.Nil(t, result)
 	})
 
@@ -182,7 +182,7 @@ func TestSelectWorkersForRemoval(t *testing.T) {
 		recorder := record.NewFakeRecorder(10)
 		r := NewClusterReconciler(client, scheme, recorder)
 
-		result := r.selectWorkersForRemoval(cluster, 1)
+		result := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 1)
 		assert.Nil(t, result)
 	})
 
@@ -205,7 +205,7 @@ func TestSelectWorkersForRemoval(t *testing.T) {
 		recorder := record.NewFakeRecorder(10)
 		r := NewClusterReconciler(client, scheme, recorder)
 
-		result := r.selectWorkersForRemoval(cluster, 2)
+		result := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 2)
 		require.Len(t, result, 2)
 		assert.Equal(t, "worker-2", result[0].Name)
 		assert.Equal(t, "worker-4", result[1].Name)
@@ -229,7 +229,7 @@ func TestSelectWorkersForRemoval(t *testing.T) {
 		recorder := record.NewFakeRecorder(10)
 		r := NewClusterReconciler(client, scheme, recorder)
 
-		result := r.selectWorkersForRemoval(cluster, 1)
+		result := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 1)
 		require.Len(t, result, 1)
 		assert.Equal(t, "worker-3", result[0].Name) // Last in list = "newest"
 	})
@@ -253,7 +253,7 @@ func TestSelectWorkersForRemoval(t *testing.T) {
 		r := NewClusterReconciler(client, scheme, recorder)
 
 		// Requesting 2: should get 1 unhealthy + 1 newest healthy
-		result := r.selectWorkersForRemoval(cluster, 2)
+		result := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 2)
 		require.Len(t, result, 2)
 		assert.Equal(t, "worker-2", result[0].Name) // Unhealthy first
 		assert.Equal(t, "worker-3", result[1].Name) // Then newest healthy
@@ -275,7 +275,7 @@ func TestSelectWorkersForRemoval(t *testing.T) {
 		recorder := record.NewFakeRecorder(10)
 		r := NewClusterReconciler(client, scheme, recorder)
 
-		result := r.selectWorkersForRemoval(cluster, 5)
+		result := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 5)
 		require.Len(t, result, 1)
 		assert.Equal(t, "worker-1", result[0].Name)
 	})
@@ -553,7 +553,7 @@ func TestScaleDownWorkers(t *testing.T) {
 			WithMetrics(false),
 		)
 
-		err := r.scaleDownWorkers(context.Background(), cluster, 1)
+		err := r.scaleDownWorkers(context.Background(), cluster, workerPools(cluster)[0], 1)
 		require.NoError(t, err)
 		assert.Empty(t, mockHCloud.DeleteServerCalls)
 	})
@@ -590,7 +590,7 @@ func TestScaleDownWorkers(t *testing.T) {
 			WithMetrics(false),
 		)
 
-		err := r.scaleDownWorkers(context.Background(), cluster, 1)
+		err := r.scaleDownWorkers(context.Background(), cluster, workerPools(cluster)[0], 1)
 		require.NoError(t, err)
 
 		// Should have removed the unhealthy worker
@@ -634,7 +634,7 @@ func TestScaleDownWorkers(t *testing.T) {
 			WithMetrics(false),
 		)
 
-		err := r.scaleDownWorkers(context.Background(), cluster, 3)
+		err := r.scaleDownWorkers(context.Background(), cluster, workerPools(cluster)[0], 3)
 		// Should return partial error since only 1 out of 3 could be removed
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "only removed 1 of 3")
@@ -490,7 +490,7 @@ func TestScaleUpWorkers(t *testing.T) {
 			}),
 		)
 
-		err := r.scaleUpWorkers(context.Background(), cluster, 2)
+		err := r.scaleUpWorkers(context.Background(), cluster, workerPools(cluster)[0], 2)
 		require.NoError(t, err)
 
 		assert.Len(t, mockHCloud.CreateServerCalls, 2)
@@ -560,7 +560,7 @@ func TestScaleUpWorkers(t *testing.T) {
 			WithMetrics(false),
 		)
 
-		err := r.scaleUpWorkers(context.Background(), cluster, 5)
+		err := r.scaleUpWorkers(context.Background(), cluster, workerPools(cluster)[0], 5)
 		// All 5 servers are created in parallel (no maxConcurrentHeals limit for scale-up)
 		require.NoError(t, err)
 
@@ -604,7 +604,7 @@ func TestScaleUpWorkers(t *testing.T) {
 			WithMetrics(false),
 		)
 
-		err := r.scaleUpWorkers(context.Background(), cluster, 1)
+		err := r.scaleUpWorkers(context.Background(), cluster, workerPools(cluster)[0], 1)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "no Talos snapshot found")
 
@@ -662,7 +662,7 @@ func TestScaleUpWorkers(t *testing.T) {
 			WithMetrics(false),
 		)
 
-		err := r.scaleUpWorkers(context.Background(), cluster, 2)
+		err := r.scaleUpWorkers(context.Background(), cluster, workerPools(cluster)[0], 2)
 		require.NoError(t, err)
 
 		assert.Len(t, mockHCloud.CreateServerCalls, 2)
@@ -931,7 +931,7 @@ func TestSelectWorkersForRemoval_ZeroCount(t *testing.T) {
 		},
 	}
 
-	result := r.selectWorkersForRemoval(cluster, 0)
+	result := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 0)
 	assert.Nil(t, result)
 }
 
@@ -950,7 +950,7 @@ func TestSelectWorkersForRemoval_EmptyNodes(t *testing.T) {
 		},
 	}
 
-	result := r.selectWorkersForRemoval(cluster, 1)
+	result := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 1)
 	assert.Nil(t, result)
 }
 
@@ -973,7 +973,7 @@ func TestSelectWorkersForRemoval_PrefersUnhealthy(t *testing.T) {
 		},
 	}
 
-	result := r.selectWorkersForRemoval(cluster, 1)
+	result := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 1)
 	require.Len(t, result, 1)
 	assert.Equal(t, "w-2", result[0].Name, "should select unhealthy worker first")
 }
@@ -997,7 +997,7 @@ func TestSelectWorkersForRemoval_FallsBackToNewest(t *testing.T) {
 		},
 	}
 
-	result := r.selectWorkersForRemoval(cluster, 2)
+	result := r.selectWorkersForRemoval(cluster, workerPools(cluster)[0], 2)
 	require.Len(t, result, 2)
 	// Should select from the end (newest first)
 	assert.Equal(t, "w-3", result[0].Name)
@@ -1142,7 +1142,7 @@ func TestScaleDownWorkers_NoWorkersToRemove(t *testing.T) {
 		},
 	}
 
-	err := r.scaleDownWorkers(context.Background(), cluster, 1)
+	err := r.scaleDownWorkers(context.Background(), cluster, workerPools(cluster)[0], 1)
 	require.NoError(t, err)
 }
 
@@ -1171,7 +1171,7 @@ func TestScaleDownWorkers_RespectsConcurrencyLimit(t *testing.T) {
 		},
 	}
 
-	err := r.scaleDownWorkers(context.Background(), cluster, 3)
+	err := r.scaleDownWorkers(context.Background(), cluster, workerPools(cluster)[0], 3)
 	// maxConcurrentHeals=1, so only 1 will be removed, hence error "only removed 1 of 3"
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "only removed 1 of 3")
@@ -1224,3 +1224,233 @@ func TestCountWorkersInEarlyProvisioning_MixedPhases(t *testing.T) {
 }
 
 // --- determineNodePhaseFromState: more branch coverage ---
+
+// --- Control plane scale-down ---
+
+func newCPScaleDownCluster(desired int, healthy ...bool) *k8znerv1alpha1.K8znerCluster {
+	cluster := &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			ControlPlanes: k8znerv1alpha1.ControlPlaneSpec{Count: desired},
+		},
+	}
+	for i, h := range healthy {
+		cluster.Status.ControlPlanes.Nodes = append(cluster.Status.ControlPlanes.Nodes, k8znerv1alpha1.NodeStatus{
+			Name: fmt.Sprintf("cp-%d", i+1), PrivateIP: fmt.Sprintf("10.0.0.%d", i+1), Healthy: h,
+		})
+		if h {
+			cluster.Status.ControlPlanes.Ready++
+		}
+	}
+	return cluster
+}
+
+func TestReconcileControlPlanes_ScaleDown(t *testing.T) {
+	t.Parallel()
+	scheme := setupTestScheme(t)
+
+	newReconciler := func(cluster *k8znerv1alpha1.K8znerCluster, hcloud *MockHCloudClient, talos *MockTalosClient) *ClusterReconciler {
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(cluster).
+			WithStatusSubresource(cluster).
+			Build()
+		return NewClusterReconciler(c, scheme, record.NewFakeRecorder(20),
+			WithHCloudClient(hcloud),
+			WithTalosClient(talos),
+			WithMetrics(false),
+		)
+	}
+
+	t.Run("removes newest non-leader control plane", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(1, true, true, true)
+		mockHCloud := &MockHCloudClient{}
+		mockTalos := &MockTalosClient{}
+		r := newReconciler(cluster, mockHCloud, mockTalos)
+
+		result, err := r.reconcileControlPlanes(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+
+		// Only one member per reconcile
+		require.Len(t, mockTalos.RemoveEtcdMemberCalls, 1)
+		assert.Equal(t, RemoveEtcdMemberCall{NodeIP: "10.0.0.1", MemberID: "3"}, mockTalos.RemoveEtcdMemberCalls[0])
+		assert.Equal(t, []string{"cp-3"}, mockHCloud.DeleteServerCalls)
+		assert.Len(t, cluster.Status.ControlPlanes.Nodes, 2)
+		assert.Nil(t, findNodeStatus(cluster.Status.ControlPlanes.Nodes, "cp-3"))
+	})
+
+	t.Run("removes the control plane on an outdated server type", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(3, true, true, true, true)
+		cluster.Spec.ControlPlanes.Size = "cx23"
+		for i := range cluster.Status.ControlPlanes.Nodes {
+			cluster.Status.ControlPlanes.Nodes[i].ServerType = "cx23"
+		}
+		cluster.Status.ControlPlanes.Nodes[1].ServerType = "cx33"
+		mockHCloud := &MockHCloudClient{}
+		mockTalos := &MockTalosClient{
+			GetEtcdMembersFunc: func(_ context.Context, _ string) ([]etcdMember, error) {
+				return []etcdMember{
+					{ID: "1", Name: "cp-1", IsLeader: true},
+					{ID: "2", Name: "cp-2"},
+					{ID: "3", Name: "cp-3"},
+					{ID: "4", Name: "cp-4"},
+				}, nil
+			},
+		}
+		r := newReconciler(cluster, mockHCloud, mockTalos)
+
+		_, err := r.reconcileControlPlanes(context.Background(), cluster)
+		require.NoError(t, err)
+
+		require.Len(t, mockTalos.RemoveEtcdMemberCalls, 1)
+		assert.Equal(t, "2", mockTalos.RemoveEtcdMemberCalls[0].MemberID)
+		assert.Equal(t, []string{"cp-2"}, mockHCloud.DeleteServerCalls, "the outdated node goes before the newest one")
+		assert.Len(t, cluster.Status.ControlPlanes.Nodes, 3)
+	})
+
+	t.Run("skips the etcd leader", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(1, true, true)
+		mockHCloud := &MockHCloudClient{}
+		mockTalos := &MockTalosClient{
+			GetEtcdMembersFunc: func(_ context.Context, _ string) ([]etcdMember, error) {
+				return []etcdMember{
+					{ID: "1", Name: "cp-1"},
+					{ID: "2", Name: "cp-2", IsLeader: true},
+				}, nil
+			},
+		}
+		r := newReconciler(cluster, mockHCloud, mockTalos)
+
+		_, err := r.reconcileControlPlanes(context.Background(), cluster)
+		require.NoError(t, err)
+
+		require.Len(t, mockTalos.RemoveEtcdMemberCalls, 1)
+		assert.Equal(t, RemoveEtcdMemberCall{NodeIP: "10.0.0.2", MemberID: "1"}, mockTalos.RemoveEtcdMemberCalls[0])
+		assert.Equal(t, []string{"cp-1"}, mockHCloud.DeleteServerCalls)
+	})
+
+	t.Run("refuses when quorum would be lost", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(1, true, false, false)
+		mockHCloud := &MockHCloudClient{}
+		mockTalos := &MockTalosClient{}
+		r := newReconciler(cluster, mockHCloud, mockTalos)
+
+		result, err := r.reconcileControlPlanes(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Empty(t, mockTalos.RemoveEtcdMemberCalls)
+		assert.Empty(t, mockHCloud.DeleteServerCalls)
+	})
+
+	t.Run("keeps server when etcd removal fails", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(1, true, true, true)
+		mockHCloud := &MockHCloudClient{}
+		mockTalos := &MockTalosClient{
+			RemoveEtcdMemberFunc: func(_ context.Context, _ string, _ string) error {
+				return fmt.Errorf("etcd unavailable")
+			},
+		}
+		r := newReconciler(cluster, mockHCloud, mockTalos)
+
+		result, err := r.reconcileControlPlanes(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Empty(t, mockHCloud.DeleteServerCalls)
+		assert.Len(t, cluster.Status.ControlPlanes.Nodes, 3)
+	})
+
+	t.Run("waits without Talos credentials", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(1, true, true, true)
+		mockHCloud := &MockHCloudClient{}
+		c := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(cluster).
+			WithStatusSubresource(cluster).
+			Build()
+		r := NewClusterReconciler(c, scheme, record.NewFakeRecorder(20),
+			WithHCloudClient(mockHCloud),
+			WithMetrics(false),
+		)
+
+		result, err := r.reconcileControlPlanes(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Empty(t, mockHCloud.DeleteServerCalls)
+	})
+}
+
+func TestSelectControlPlaneForRemoval(t *testing.T) {
+	t.Parallel()
+
+	members := []etcdMember{
+		{ID: "1", Name: "cp-1", IsLeader: true},
+		{ID: "2", Name: "cp-2"},
+		{ID: "3", Name: "cp-3"},
+	}
+
+	t.Run("prefers unhealthy node", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(1, true, false, true)
+		node, member, err := selectControlPlaneForRemoval(cluster.Status.ControlPlanes.Nodes, members, "")
+		require.NoError(t, err)
+		assert.Equal(t, "cp-2", node.Name)
+		require.NotNil(t, member)
+		assert.Equal(t, "2", member.ID)
+	})
+
+	t.Run("node without etcd member", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(1, true, true, true, true)
+		node, member, err := selectControlPlaneForRemoval(cluster.Status.ControlPlanes.Nodes, members, "")
+		require.NoError(t, err)
+		assert.Equal(t, "cp-4", node.Name)
+		assert.Nil(t, member)
+	})
+
+	t.Run("prefers outdated server type over newest node", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(3, true, true, true)
+		cluster.Status.ControlPlanes.Nodes[0].ServerType = "cx33"
+		cluster.Status.ControlPlanes.Nodes[1].ServerType = "cx33"
+		cluster.Status.ControlPlanes.Nodes[2].ServerType = "cx23"
+		node, member, err := selectControlPlaneForRemoval(cluster.Status.ControlPlanes.Nodes, members, "cx23")
+		require.NoError(t, err)
+		assert.Equal(t, "cp-2", node.Name, "the outdated leader cp-1 is skipped")
+		require.NotNil(t, member)
+		assert.Equal(t, "2", member.ID)
+	})
+
+	t.Run("unhealthy before outdated", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(1, true, true, false)
+		cluster.Status.ControlPlanes.Nodes[1].ServerType = "cx33"
+		node, _, err := selectControlPlaneForRemoval(cluster.Status.ControlPlanes.Nodes, members, "cx23")
+		require.NoError(t, err)
+		assert.Equal(t, "cp-3", node.Name)
+	})
+
+	t.Run("unknown leader", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(1, true, true)
+		_, _, err := selectControlPlaneForRemoval(cluster.Status.ControlPlanes.Nodes, []etcdMember{
+			{ID: "1", Name: "cp-1"}, {ID: "2", Name: "cp-2"},
+		}, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "leader")
+	})
+
+	t.Run("unhealthy leader cannot be removed and healthy removal loses quorum", func(t *testing.T) {
+		t.Parallel()
+		cluster := newCPScaleDownCluster(1, false, true, true)
+		_, _, err := selectControlPlaneForRemoval(cluster.Status.ControlPlanes.Nodes, members, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "would leave 1/2 healthy")
+	})
+}
@@ -3,6 +3,8 @@ package controller
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -13,6 +15,7 @@ import (
 
 	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
 	"github.com/milankappen/k8zner/internal/config"
+	operatorprov "github.com/milankappen/k8zner/internal/operator/provisioning"
 	"github.com/milankappen/k8zner/internal/util/labels"
 	"github.com/milankappen/k8zner/internal/util/naming"
 )
@@ -24,7 +27,8 @@ func (r *ClusterReconciler) reconcileWorkers(ctx context.Context, cluster *k8zne
 	unhealthyWorkers := findUnhealthyNodes(cluster.Status.Workers.Nodes, threshold)
 	replaced := r.replaceUnhealthyWorkers(ctx, cluster, unhealthyWorkers)
 
-	// Then handle scaling
+	// Let the autoscaler adjust the targets of autoscaled pools, then handle scaling
+	r.autoscaleWorkerPools(ctx, cluster)
 	result, err := r.scaleWorkers(ctx, cluster)
 	if err != nil || result.RequeueAfter > 0 {
 		return result, err
@@ -46,6 +50,9 @@ func (r *ClusterReconciler) replaceUnhealthyWorkers(ctx context.Context, cluster
 		if replaced >= r.maxConcurrentHeals {
 			break
 		}
+		if !r.healingAllowed(ctx, cluster, worker) {
+			continue
+		}
 
 		logger.Info("replacing unhealthy worker",
 			"node", worker.Name,
@@ -76,59 +83,93 @@ func (r *ClusterReconciler) replaceUnhealthyWorkers(ctx context.Context, cluster
 	return replaced
 }
 
-// scaleWorkers handles worker scaling up and down.
+// scaleWorkers scales each worker pool to its desired count. Pools removed from
+// the spec are scaled to zero once all remaining pools have converged.
 func (r *ClusterReconciler) scaleWorkers(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
-	currentCount := len(cluster.Status.Workers.Nodes)
 	provisioningCount := countWorkersInEarlyProvisioning(cluster.Status.Workers.Nodes)
-	desiredCount := cluster.Spec.Workers.Count
 
 	// Skip scaling if workers are already provisioning to prevent duplicate server creation
 	if provisioningCount > 0 {
 		logger.Info("workers currently provisioning, skipping scaling check",
 			"provisioning", provisioningCount,
-			"current", currentCount,
-			"desired", desiredCount,
+			"current", len(cluster.Status.Workers.Nodes),
+			"desired", desiredWorkerCount(cluster),
 		)
 		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
 	}
 
+	var result ctrl.Result
+	for _, pool := range workerPools(cluster) {
+		result = shorterRequeue(result, r.scaleWorkerPool(ctx, cluster, pool))
+	}
+	if result.RequeueAfter > 0 {
+		return result, nil
+	}
+
+	for _, name := range orphanedWorkerPools(cluster) {
+		result = shorterRequeue(result, r.scaleWorkerPool(ctx, cluster, k8znerv1alpha1.WorkerPoolSpec{Name: name}))
+	}
+
+	return result, nil
+}
+
+// scaleWorkerPool scales a single worker pool up or down to its desired count.
+func (r *ClusterReconciler) scaleWorkerPool(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, pool k8znerv1alpha1.WorkerPoolSpec) ctrl.Result {
+	logger := log.FromContext(ctx)
+
+	currentCount := len(workerPoolNodes(cluster, pool.Name))
+	desiredCount := workerPoolDesiredCount(cluster, pool)
+
 	if currentCount < desiredCount {
-		logger.Info("scaling up workers", "current", currentCount, "desired", desiredCount)
+		logger.Info("scaling up workers", "pool", pool.Name, "current", currentCount, "desired", desiredCount)
 		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonScalingUp,
-			"Scaling up workers: %d -> %d", currentCount, desiredCount)
+			"Scaling up workers: %d -> %d (pool %s)", currentCount, desiredCount, pool.Name)
 
 		if r.hcloudClient != nil {
 			cluster.Status.Phase = k8znerv1alpha1.ClusterPhaseHealing
 			toCreate := desiredCount - currentCount
-			if err := r.scaleUpWorkers(ctx, cluster, toCreate); err != nil {
-				logger.Error(err, "failed to scale up workers")
+			if err := r.scaleUpWorkers(ctx, cluster, pool, toCreate); err != nil {
+				logger.Error(err, "failed to scale up workers", "pool", pool.Name)
 			}
 		}
-		return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+		return ctrl.Result{RequeueAfter: fastRequeueAfter}
 	} else if currentCount > desiredCount {
-		logger.Info("scaling down workers", "current", currentCount, "desired", desiredCount)
+		if !r.disruptionAllowed(ctx, cluster, maintenanceActionScaleDown, pool.Name,
+			fmt.Sprintf("scale pool %s %d -> %d", pool.Name, currentCount, desiredCount)) {
+			return ctrl.Result{}
+		}
+
+		logger.Info("scaling down workers", "pool", pool.Name, "current", currentCount, "desired", desiredCount)
 		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonScalingDown,
-			"Scaling down workers: %d -> %d", currentCount, desiredCount)
+			"Scaling down workers: %d -> %d (pool %s)", currentCount, desiredCount, pool.Name)
 
 		if r.hcloudClient != nil {
 			cluster.Status.Phase = k8znerv1alpha1.ClusterPhaseHealing
 			toRemove := currentCount - desiredCount
-			if err := r.scaleDownWorkers(ctx, cluster, toRemove); err != nil {
-				logger.Error(err, "failed to scale down workers")
+			if err := r.scaleDownWorkers(ctx, cluster, pool, toRemove); err != nil {
+				logger.Error(err, "failed to scale down workers", "pool", pool.Name)
 			}
 		}
-		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{}
 }
 
-// scaleUpWorkers creates new worker nodes to reach the desired count.
+// shorterRequeue returns whichever result requeues sooner. A zero RequeueAfter means no requeue.
+func shorterRequeue(a, b ctrl.Result) ctrl.Result {
+	if a.RequeueAfter == 0 || (b.RequeueAfter > 0 && b.RequeueAfter < a.RequeueAfter) {
+		return b
+	}
+	return a
+}
+
+// scaleUpWorkers creates count new worker nodes in pool.
 // Both server creation and Talos configuration are done in parallel for workers,
 // since workers don't have etcd constraints (unlike control planes).
-func (r *ClusterReconciler) scaleUpWorkers(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, count int) error {
+func (r *ClusterReconciler) scaleUpWorkers(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, pool k8znerv1alpha1.WorkerPoolSpec, count int) error {
 	logger := log.FromContext(ctx)
 
 	prereqs, cleanup, err := r.prepareForProvisioning(ctx, cluster, "worker")
@@ -137,10 +178,11 @@ func (r *ClusterReconciler) scaleUpWorkers(ctx context.Context, cluster *k8znerv
 	}
 	defer cleanup()
 
-	serverType := string(config.ServerSize(cluster.Spec.Workers.Size).Normalize())
+	serverType := string(config.ServerSize(pool.Size).Normalize())
+	location := workerPoolLocation(cluster, pool)
 	serverLabels := labels.NewLabelBuilder(cluster.Name).
 		WithRole("worker").
-		WithPool("workers").
+		WithPool(pool.Name).
 		WithManagedBy(labels.ManagedByOperator).
 		Build()
 
@@ -159,11 +201,12 @@ func (r *ClusterReconciler) scaleUpWorkers(ctx context.Context, cluster *k8znerv
 				Name:       name,
 				SnapshotID: prereqs.SnapshotID,
 				ServerType: serverType,
-				Region:     cluster.Spec.Region,
+				Region:     location,
 				SSHKeyName: prereqs.SSHKeyName,
 				Labels:     serverLabels,
 				NetworkID:  prereqs.ClusterState.NetworkID,
 				Role:       "worker",
+				Pool:       pool.Name,
 			})
 			resultCh <- serverResult{name: name, result: result, err: err}
 		}()
@@ -211,7 +254,7 @@ func (r *ClusterReconciler) scaleUpWorkers(ctx context.Context, cluster *k8znerv
 				Reason:    fmt.Sprintf("Waiting for Talos API on %s:50000", srv.result.TalosIP),
 			})
 
-			err := r.configureWorkerNode(ctx, cluster, prereqs.TC, srv.result)
+			err := r.configureWorkerNode(ctx, cluster, prereqs.TC, pool, srv.result)
 			configCh <- configResult{name: srv.name, err: err}
 		}()
 	}
@@ -243,9 +286,9 @@ func (r *ClusterReconciler) scaleUpWorkers(ctx context.Context, cluster *k8znerv
 	return nil
 }
 
-// configureWorkerNode generates and applies Talos config to a worker node, then waits for readiness.
-// Used by both scale-up and healing paths.
-func (r *ClusterReconciler) configureWorkerNode(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, result *serverProvisionResult) error {
+// configureWorkerNode generates and applies Talos config to a worker node of pool, then waits
+// for readiness. Used by both scale-up and healing paths.
+func (r *ClusterReconciler) configureWorkerNode(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, pool k8znerv1alpha1.WorkerPoolSpec, result *serverProvisionResult) error {
 	logger := log.FromContext(ctx)
 
 	if tc.configGen == nil || tc.client == nil {
@@ -262,7 +305,8 @@ func (r *ClusterReconciler) configureWorkerNode(ctx context.Context, cluster *k8
 		Reason: "Generating and applying Talos machine configuration",
 	})
 
-	machineConfig, err := tc.configGen.GenerateWorkerConfig(result.Name, result.ServerID)
+	poolConfig := operatorprov.WorkerNodePool(&cluster.Spec, pool)
+	machineConfig, err := tc.configGen.GenerateWorkerConfig(result.Name, result.ServerID, &poolConfig)
 	if err != nil {
 		logger.Error(err, "failed to generate worker config", "name", result.Name)
 		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonConfigApplyError,
@@ -317,17 +361,17 @@ func (r *ClusterReconciler) waitForWorkerReady(ctx context.Context, cluster *k8z
 	return nil
 }
 
-// scaleDownWorkers removes excess worker nodes to reach the desired count.
-func (r *ClusterReconciler) scaleDownWorkers(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, count int) error {
+// scaleDownWorkers removes count workers from pool.
+func (r *ClusterReconciler) scaleDownWorkers(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, pool k8znerv1alpha1.WorkerPoolSpec, count int) error {
 	logger := log.FromContext(ctx)
 
-	workersToRemove := r.selectWorkersForRemoval(cluster, count)
+	workersToRemove := r.selectWorkersForRemoval(cluster, pool, count)
 	if len(workersToRemove) == 0 {
 		logger.Info("no workers to remove")
 		return nil
 	}
 
-	logger.Info("removing workers", "count", len(workersToRemove))
+	logger.Info("removing workers", "pool", pool.Name, "count", len(workersToRemove))
 
 	removed := 0
 	for _, worker := range workersToRemove {
@@ -405,28 +449,43 @@ func (r *ClusterReconciler) decommissionWorker(ctx context.Context, cluster *k8z
 	return nil
 }
 
-// selectWorkersForRemoval selects workers to remove during scale-down.
-// Priority: 1. Unhealthy workers, 2. Newest workers (by name, assuming newer names sort last)
-func (r *ClusterReconciler) selectWorkersForRemoval(cluster *k8znerv1alpha1.K8znerCluster, count int) []*k8znerv1alpha1.NodeStatus {
-	if count <= 0 || len(cluster.Status.Workers.Nodes) == 0 {
+// selectWorkersForRemoval selects workers of pool to remove during scale-down.
+// Priority: 1. Unhealthy workers, 2. Workers on an outdated server type,
+// 3. Workers the autoscaler found underutilized (longest first),
+// 4. Newest workers (by name, assuming newer names sort last)
+func (r *ClusterReconciler) selectWorkersForRemoval(cluster *k8znerv1alpha1.K8znerCluster, pool k8znerv1alpha1.WorkerPoolSpec, count int) []*k8znerv1alpha1.NodeStatus {
+	nodes := workerPoolNodes(cluster, pool.Name)
+	if count <= 0 || len(nodes) == 0 {
 		return nil
 	}
 
+	desiredType := normalizedServerType(pool.Size)
+
 	var unhealthy []*k8znerv1alpha1.NodeStatus
+	var outdated []*k8znerv1alpha1.NodeStatus
+	var underutilized []*k8znerv1alpha1.NodeStatus
 	var healthy []*k8znerv1alpha1.NodeStatus
 
-	for i := range cluster.Status.Workers.Nodes {
-		node := &cluster.Status.Workers.Nodes[i]
-		if !node.Healthy {
+	for _, node := range nodes {
+		switch {
+		case !node.Healthy:
 			unhealthy = append(unhealthy, node)
-		} else {
+		case desiredType != "" && node.ServerType != "" && normalizedServerType(node.ServerType) != desiredType:
+			outdated = append(outdated, node)
+		case node.UnderutilizedSince != nil:
+			underutilized = append(underutilized, node)
+		default:
 			healthy = append(healthy, node)
 		}
 	}
 
+	sort.SliceStable(underutilized, func(i, j int) bool {
+		return underutilized[i].UnderutilizedSince.Before(underutilized[j].UnderutilizedSince)
+	})
+
 	var selected []*k8znerv1alpha1.NodeStatus
 
-	for _, node := range unhealthy {
+	for _, node := range slices.Concat(unhealthy, outdated, underutilized) {
 		if len(selected) >= count {
 			break
 		}
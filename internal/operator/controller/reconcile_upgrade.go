@@ -0,0 +1,376 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/platform/talos"
+	"github.com/milankappen/k8zner/internal/provisioning"
+)
+
+// reconcileTalosUpgrade rolls nodes forward to spec.talos.version.
+// Control planes are upgraded one at a time after an etcd quorum check, then workers
+// are upgraded in batches of spec.talos.upgradeBatchSize. Each call performs at most
+// one step and requeues, so health checks and healing run between steps.
+func (r *ClusterReconciler) reconcileTalosUpgrade(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	desired := cluster.Spec.Talos.Version
+	if desired == "" {
+		return ctrl.Result{}, nil
+	}
+
+	tc := r.loadTalosClients(ctx, cluster)
+	if tc.configGen == nil {
+		logger.V(1).Info("skipping Talos upgrade check (no Talos credentials)")
+		return ctrl.Result{}, nil
+	}
+
+	r.refreshTalosVersions(ctx, cluster, tc)
+
+	cpPending := nodesNeedingTalosUpgrade(cluster.Status.ControlPlanes.Nodes, desired)
+	workerPending := nodesNeedingTalosUpgrade(cluster.Status.Workers.Nodes, desired)
+
+	if len(cpPending) == 0 && len(workerPending) == 0 {
+		if !allNodesAtTalosVersion(cluster, desired) {
+			// Some versions could not be determined yet; check again next reconcile.
+			return ctrl.Result{}, nil
+		}
+		r.completeTalosUpgrade(cluster, desired)
+		return ctrl.Result{}, nil
+	}
+
+	// Never start an upgrade step on a degraded cluster; healing runs first.
+	if cluster.Status.ControlPlanes.Ready < cluster.Spec.ControlPlanes.Count ||
+		cluster.Status.Workers.Ready < desiredWorkerCount(cluster) {
+		logger.Info("waiting for all nodes to be healthy before continuing Talos upgrade",
+			"controlPlanesReady", cluster.Status.ControlPlanes.Ready,
+			"workersReady", cluster.Status.Workers.Ready,
+		)
+		setTalosUpgradeCondition(cluster, metav1.ConditionFalse, "WaitingForHealthyNodes",
+			fmt.Sprintf("Upgrade to Talos %s paused until all nodes are healthy", desired))
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	if !r.disruptionAllowed(ctx, cluster, maintenanceActionTalosUpgrade, "",
+		fmt.Sprintf("%d nodes need an upgrade to Talos %s", len(cpPending)+len(workerPending), desired)) {
+		setTalosUpgradeCondition(cluster, metav1.ConditionFalse, "WaitingForMaintenanceWindow",
+			fmt.Sprintf("Upgrade to Talos %s waits for the next maintenance window", desired))
+		return ctrl.Result{}, nil
+	}
+
+	image := talos.InstallerImageURL(cluster.Spec.Talos.SchematicID, desired)
+
+	if len(cpPending) > 0 {
+		return r.upgradeNextControlPlane(ctx, cluster, tc, cpPending[0], image)
+	}
+
+	return r.upgradeWorkerBatch(ctx, cluster, tc, workerPending, image)
+}
+
+// upgradeNextControlPlane upgrades a single control plane if etcd can tolerate losing it.
+func (r *ClusterReconciler) upgradeNextControlPlane(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, node k8znerv1alpha1.NodeStatus, image string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	desired := cluster.Spec.Talos.Version
+
+	if err := r.checkUpgradeQuorum(ctx, cluster, tc, node); err != nil {
+		logger.Error(err, "cannot upgrade control plane - quorum would be lost", "node", node.Name)
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonQuorumLost,
+			"Cannot upgrade control plane %s: %v", node.Name, err)
+		setTalosUpgradeCondition(cluster, metav1.ConditionFalse, "QuorumAtRisk", err.Error())
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	if err := r.takePreOperationSnapshot(ctx, cluster, tc, snapshotOpTalosUpgrade, node.Name); err != nil {
+		setTalosUpgradeCondition(cluster, metav1.ConditionFalse, "SnapshotFailed", err.Error())
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	setTalosUpgradeCondition(cluster, metav1.ConditionFalse, "UpgradeInProgress",
+		fmt.Sprintf("Upgrading control plane %s to Talos %s", node.Name, desired))
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonTalosUpgrading,
+		"Upgrading control plane %s from Talos %s to %s", node.Name, node.TalosVersion, desired)
+
+	r.markUpgrading(ctx, cluster, "control-plane", node, desired)
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status before control plane upgrade")
+	}
+
+	err := r.upgradeTalosNode(ctx, cluster, tc, "control-plane", node, image)
+	if persistErr := r.persistClusterStatus(ctx, cluster); persistErr != nil {
+		logger.Error(persistErr, "failed to persist status after control plane upgrade")
+	}
+	if err != nil {
+		setTalosUpgradeCondition(cluster, metav1.ConditionFalse, "UpgradeFailed", err.Error())
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// upgradeWorkerBatch upgrades up to spec.talos.upgradeBatchSize workers in parallel.
+func (r *ClusterReconciler) upgradeWorkerBatch(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, pending []k8znerv1alpha1.NodeStatus, image string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	desired := cluster.Spec.Talos.Version
+
+	batch := pending[:min(upgradeBatchSize(cluster), len(pending))]
+
+	names := make([]string, 0, len(batch))
+	for _, node := range batch {
+		names = append(names, node.Name)
+		r.markUpgrading(ctx, cluster, "worker", node, desired)
+	}
+
+	setTalosUpgradeCondition(cluster, metav1.ConditionFalse, "UpgradeInProgress",
+		fmt.Sprintf("Upgrading workers %v to Talos %s (%d remaining)", names, desired, len(pending)))
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonTalosUpgrading,
+		"Upgrading workers %v to Talos %s", names, desired)
+
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status before worker upgrade")
+	}
+
+	failed, lastErr := upgradeNodesInParallel(batch, func(node k8znerv1alpha1.NodeStatus) error {
+		return r.upgradeTalosNode(ctx, cluster, tc, "worker", node, image)
+	})
+
+	if err := r.persistClusterStatus(ctx, cluster); err != nil {
+		logger.Error(err, "failed to persist status after worker upgrade")
+	}
+
+	if failed > 0 {
+		setTalosUpgradeCondition(cluster, metav1.ConditionFalse, "UpgradeFailed",
+			fmt.Sprintf("%d/%d workers failed to upgrade: %v", failed, len(batch), lastErr))
+		return ctrl.Result{RequeueAfter: defaultRequeueAfter}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: fastRequeueAfter}, nil
+}
+
+// upgradeTalosNode upgrades one node and waits until it rejoins the cluster on the new version.
+// Talos cordons and drains the node itself as part of the upgrade sequence.
+// Safe for concurrent use from parallel upgrade goroutines.
+func (r *ClusterReconciler) upgradeTalosNode(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, role string, node k8znerv1alpha1.NodeStatus, image string) error {
+	logger := log.FromContext(ctx)
+	desired := cluster.Spec.Talos.Version
+	nodeIP := talosEndpointForNode(node)
+
+	fail := func(err error) error {
+		logger.Error(err, "Talos upgrade failed", "node", node.Name, "role", role)
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReasonTalosUpgradeFailed,
+			"Failed to upgrade %s %s to Talos %s: %v", role, node.Name, desired, err)
+		r.recordNodeUpgrade(cluster.Name, role, "failed")
+		r.updateNodePhase(ctx, cluster, role, nodeStatusUpdate{
+			Name:   node.Name,
+			Phase:  k8znerv1alpha1.NodePhaseUnhealthy,
+			Reason: fmt.Sprintf("Talos upgrade to %s failed: %v", desired, err),
+		})
+		return fmt.Errorf("failed to upgrade %s: %w", node.Name, err)
+	}
+
+	logger.Info("upgrading Talos on node",
+		"node", node.Name,
+		"role", role,
+		"from", node.TalosVersion,
+		"to", desired,
+		"image", image,
+	)
+
+	if err := tc.configGen.UpgradeNode(ctx, nodeIP, image, provisioning.UpgradeOptions{}); err != nil {
+		return fail(err)
+	}
+
+	if err := tc.configGen.WaitForNodeReady(ctx, nodeIP, talosUpgradeTimeout); err != nil {
+		return fail(fmt.Errorf("node did not come back after upgrade: %w", err))
+	}
+
+	if err := r.nodeReadyWaiter(ctx, node.Name, nodeReadyTimeout); err != nil {
+		return fail(fmt.Errorf("node did not become ready after upgrade: %w", err))
+	}
+
+	version, err := tc.configGen.GetNodeVersion(ctx, nodeIP)
+	if err != nil {
+		return fail(fmt.Errorf("failed to verify version after upgrade: %w", err))
+	}
+	if version != desired {
+		return fail(fmt.Errorf("node reports Talos %s after upgrade, expected %s", version, desired))
+	}
+
+	r.updateNodePhase(ctx, cluster, role, nodeStatusUpdate{
+		Name:         node.Name,
+		Phase:        k8znerv1alpha1.NodePhaseReady,
+		Reason:       fmt.Sprintf("Upgraded to Talos %s", version),
+		TalosVersion: version,
+	})
+	r.recordNodeUpgrade(cluster.Name, role, "success")
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonTalosUpgraded,
+		"Upgraded %s %s to Talos %s", role, node.Name, version)
+
+	return nil
+}
+
+// markUpgrading moves a node into the Upgrading phase.
+func (r *ClusterReconciler) markUpgrading(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, role string, node k8znerv1alpha1.NodeStatus, desired string) {
+	r.updateNodePhase(ctx, cluster, role, nodeStatusUpdate{
+		Name:   node.Name,
+		Phase:  k8znerv1alpha1.NodePhaseUpgrading,
+		Reason: fmt.Sprintf("Upgrading Talos %s -> %s", node.TalosVersion, desired),
+	})
+}
+
+// checkUpgradeQuorum verifies that etcd keeps quorum while the given control plane reboots.
+// Single control plane clusters have no quorum to protect and are always allowed.
+func (r *ClusterReconciler) checkUpgradeQuorum(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients, node k8znerv1alpha1.NodeStatus) error {
+	totalCPs := cluster.Spec.ControlPlanes.Count
+	if totalCPs <= 1 {
+		return nil
+	}
+
+	quorumNeeded := (totalCPs / 2) + 1
+	healthyOthers := 0
+	peerIP := ""
+	for _, cp := range cluster.Status.ControlPlanes.Nodes {
+		if cp.Name == node.Name || !cp.Healthy {
+			continue
+		}
+		healthyOthers++
+		if peerIP == "" && cp.PrivateIP != "" {
+			peerIP = cp.PrivateIP
+		}
+	}
+
+	if healthyOthers < quorumNeeded {
+		return fmt.Errorf("only %d other control planes healthy, need %d for quorum", healthyOthers, quorumNeeded)
+	}
+
+	if tc.client == nil || peerIP == "" {
+		return nil
+	}
+
+	members, err := tc.client.GetEtcdMembers(ctx, peerIP)
+	if err != nil {
+		return fmt.Errorf("failed to list etcd members: %w", err)
+	}
+	if len(members) < totalCPs {
+		return fmt.Errorf("etcd has %d members, expected %d", len(members), totalCPs)
+	}
+
+	return nil
+}
+
+// refreshTalosVersions queries the running Talos version of healthy nodes
+// that are not yet known to be at the desired version.
+func (r *ClusterReconciler) refreshTalosVersions(ctx context.Context, cluster *k8znerv1alpha1.K8znerCluster, tc talosClients) {
+	logger := log.FromContext(ctx)
+	desired := cluster.Spec.Talos.Version
+
+	refresh := func(nodes []k8znerv1alpha1.NodeStatus) {
+		for i := range nodes {
+			node := &nodes[i]
+			endpoint := talosEndpointForNode(*node)
+			if !node.Healthy || endpoint == "" || node.TalosVersion == desired {
+				continue
+			}
+			version, err := tc.configGen.GetNodeVersion(ctx, endpoint)
+			if err != nil {
+				logger.V(1).Info("failed to get Talos version", "node", node.Name, "error", err)
+				continue
+			}
+			node.TalosVersion = version
+		}
+	}
+
+	refresh(cluster.Status.ControlPlanes.Nodes)
+	refresh(cluster.Status.Workers.Nodes)
+}
+
+// completeTalosUpgrade marks the Talos upgrade condition as done once every node runs the desired version.
+func (r *ClusterReconciler) completeTalosUpgrade(cluster *k8znerv1alpha1.K8znerCluster, desired string) {
+	prev := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionTalosUpgrade)
+	if prev != nil && prev.Status == metav1.ConditionFalse {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventReasonTalosUpgraded,
+			"All nodes upgraded to Talos %s", desired)
+	}
+	setTalosUpgradeCondition(cluster, metav1.ConditionTrue, "UpToDate",
+		fmt.Sprintf("All nodes run Talos %s", desired))
+}
+
+// nodesNeedingTalosUpgrade returns healthy nodes whose observed version differs from desired,
+// sorted by name so upgrades proceed in a stable order.
+func nodesNeedingTalosUpgrade(nodes []k8znerv1alpha1.NodeStatus, desired string) []k8znerv1alpha1.NodeStatus {
+	var pending []k8znerv1alpha1.NodeStatus
+	for _, node := range nodes {
+		if node.Healthy && node.TalosVersion != "" && node.TalosVersion != desired {
+			pending = append(pending, node)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Name < pending[j].Name })
+	return pending
+}
+
+// allNodesAtTalosVersion reports whether every tracked node is known to run the desired version.
+func allNodesAtTalosVersion(cluster *k8znerv1alpha1.K8znerCluster, desired string) bool {
+	for _, nodes := range [][]k8znerv1alpha1.NodeStatus{cluster.Status.ControlPlanes.Nodes, cluster.Status.Workers.Nodes} {
+		for _, node := range nodes {
+			if node.TalosVersion != desired {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// upgradeBatchSize returns the number of workers to upgrade in parallel.
+func upgradeBatchSize(cluster *k8znerv1alpha1.K8znerCluster) int {
+	if cluster.Spec.Talos.UpgradeBatchSize < 1 {
+		return 1
+	}
+	return cluster.Spec.Talos.UpgradeBatchSize
+}
+
+// upgradeNodesInParallel runs upgrade for every node concurrently and returns
+// the number of failures along with the last error seen.
+func upgradeNodesInParallel(nodes []k8znerv1alpha1.NodeStatus, upgrade func(k8znerv1alpha1.NodeStatus) error) (int, error) {
+	errCh := make(chan error, len(nodes))
+	for _, node := range nodes {
+		go func() {
+			errCh <- upgrade(node)
+		}()
+	}
+
+	var failed int
+	var lastErr error
+	for range nodes {
+		if err := <-errCh; err != nil {
+			failed++
+			lastErr = err
+		}
+	}
+	return failed, lastErr
+}
+
+// talosEndpointForNode returns the address used to reach a node's Talos API.
+func talosEndpointForNode(node k8znerv1alpha1.NodeStatus) string {
+	if node.PrivateIP != "" {
+		return node.PrivateIP
+	}
+	return node.PublicIP
+}
+
+// setTalosUpgradeCondition sets the TalosUpgrade condition.
+func setTalosUpgradeCondition(cluster *k8znerv1alpha1.K8znerCluster, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:    k8znerv1alpha1.ConditionTalosUpgrade,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/provisioning"
+)
+
+// fakeTalosVersions simulates node versions that change when UpgradeNode is called.
+type fakeTalosVersions struct {
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+func (f *fakeTalosVersions) generator() *MockTalosConfigGenerator {
+	return &MockTalosConfigGenerator{
+		GetNodeVersionFunc: func(_ context.Context, endpoint string) (string, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return f.versions[endpoint], nil
+		},
+		UpgradeNodeFunc: func(_ context.Context, endpoint, imageURL string, _ provisioning.UpgradeOptions) error {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.versions[endpoint] = imageURL[strings.LastIndex(imageURL, ":")+1:]
+			return nil
+		},
+	}
+}
+
+func newUpgradeTestCluster(cpVersions, workerVersions []string) *k8znerv1alpha1.K8znerCluster {
+	cluster := &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			ControlPlanes: k8znerv1alpha1.ControlPlaneSpec{Count: len(cpVersions)},
+			Workers:       k8znerv1alpha1.WorkerSpec{Count: len(workerVersions)},
+			Talos:         k8znerv1alpha1.TalosSpec{Version: "v1.10.0"},
+		},
+	}
+	for i, v := range cpVersions {
+		cluster.Status.ControlPlanes.Nodes = append(cluster.Status.ControlPlanes.Nodes, k8znerv1alpha1.NodeStatus{
+			Name: fmt.Sprintf("cp-%d", i+1), PrivateIP: fmt.Sprintf("10.0.1.%d", i+1), Healthy: true, TalosVersion: v,
+		})
+	}
+	for i, v := range workerVersions {
+		cluster.Status.Workers.Nodes = append(cluster.Status.Workers.Nodes, k8znerv1alpha1.NodeStatus{
+			Name: fmt.Sprintf("worker-%d", i+1), PrivateIP: fmt.Sprintf("10.0.2.%d", i+1), Healthy: true, TalosVersion: v,
+		})
+	}
+	cluster.Status.ControlPlanes.Ready = len(cpVersions)
+	cluster.Status.Workers.Ready = len(workerVersions)
+	return cluster
+}
+
+func TestReconcileTalosUpgrade(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no-op when talos version is not set", func(t *testing.T) {
+		t.Parallel()
+		cluster := newUpgradeTestCluster([]string{"v1.9.0"}, nil)
+		cluster.Spec.Talos.Version = ""
+		gen := &MockTalosConfigGenerator{}
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileTalosUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Empty(t, gen.UpgradeNodeCalls)
+	})
+
+	t.Run("upgrades one control plane before any worker", func(t *testing.T) {
+		t.Parallel()
+		cluster := newUpgradeTestCluster([]string{"v1.9.0", "v1.9.0", "v1.9.0"}, []string{"v1.9.0"})
+		versions := &fakeTalosVersions{versions: map[string]string{
+			"10.0.1.1": "v1.9.0", "10.0.1.2": "v1.9.0", "10.0.1.3": "v1.9.0", "10.0.2.1": "v1.9.0",
+		}}
+		gen := versions.generator()
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileTalosUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+
+		require.Len(t, gen.UpgradeNodeCalls, 1)
+		assert.Equal(t, "10.0.1.1", gen.UpgradeNodeCalls[0].Endpoint)
+		assert.Equal(t, "ghcr.io/siderolabs/installer:v1.10.0", gen.UpgradeNodeCalls[0].ImageURL)
+
+		cp1 := findNodeStatus(cluster.Status.ControlPlanes.Nodes, "cp-1")
+		require.NotNil(t, cp1)
+		assert.Equal(t, "v1.10.0", cp1.TalosVersion)
+		assert.Equal(t, k8znerv1alpha1.NodePhaseReady, cp1.Phase)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionTalosUpgrade)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, "UpgradeInProgress", cond.Reason)
+	})
+
+	t.Run("upgrades workers in batches once control planes are done", func(t *testing.T) {
+		t.Parallel()
+		cluster := newUpgradeTestCluster([]string{"v1.10.0"}, []string{"v1.9.0", "v1.9.0", "v1.9.0"})
+		cluster.Spec.Talos.UpgradeBatchSize = 2
+		cluster.Spec.Talos.SchematicID = "abc123"
+		versions := &fakeTalosVersions{versions: map[string]string{
+			"10.0.1.1": "v1.10.0", "10.0.2.1": "v1.9.0", "10.0.2.2": "v1.9.0", "10.0.2.3": "v1.9.0",
+		}}
+		gen := versions.generator()
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileTalosUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+
+		require.Len(t, gen.UpgradeNodeCalls, 2)
+		endpoints := []string{gen.UpgradeNodeCalls[0].Endpoint, gen.UpgradeNodeCalls[1].Endpoint}
+		assert.ElementsMatch(t, []string{"10.0.2.1", "10.0.2.2"}, endpoints)
+		assert.Equal(t, "factory.talos.dev/installer/abc123:v1.10.0", gen.UpgradeNodeCalls[0].ImageURL)
+		assert.Equal(t, "v1.9.0", findNodeStatus(cluster.Status.Workers.Nodes, "worker-3").TalosVersion)
+	})
+
+	t.Run("marks upgrade complete when all nodes run desired version", func(t *testing.T) {
+		t.Parallel()
+		cluster := newUpgradeTestCluster([]string{"v1.10.0"}, []string{""})
+		gen := &MockTalosConfigGenerator{
+			GetNodeVersionFunc: func(_ context.Context, _ string) (string, error) { return "v1.10.0", nil },
+		}
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileTalosUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Zero(t, result.RequeueAfter)
+		assert.Empty(t, gen.UpgradeNodeCalls)
+		assert.Equal(t, []string{"10.0.2.1"}, gen.GetNodeVersionCalls)
+
+		cond := meta.FindStatusCondition(cluster.Status.Conditions, k8znerv1alpha1.ConditionTalosUpgrade)
+		require.NotNil(t, cond)
+		assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	})
+
+	t.Run("waits while cluster is degraded", func(t *testing.T) {
+		t.Parallel()
+		cluster := newUpgradeTestCluster([]string{"v1.9.0", "v1.9.0", "v1.9.0"}, nil)
+		cluster.Status.ControlPlanes.Ready = 2
+		gen := &MockTalosConfigGenerator{
+			GetNodeVersionFunc: func(_ context.Context, _ string) (string, error) { return "v1.9.0", nil },
+		}
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileTalosUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Empty(t, gen.UpgradeNodeCalls)
+	})
+
+	t.Run("failed upgrade marks node unhealthy and backs off", func(t *testing.T) {
+		t.Parallel()
+		cluster := newUpgradeTestCluster([]string{"v1.9.0"}, nil)
+		gen := &MockTalosConfigGenerator{
+			GetNodeVersionFunc: func(_ context.Context, _ string) (string, error) { return "v1.9.0", nil },
+		}
+		r := newTestReconciler(t, []client.Object{cluster}, WithTalosConfigGenerator(gen))
+
+		result, err := r.reconcileTalosUpgrade(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		require.Len(t, gen.UpgradeNodeCalls, 1)
+
+		cp1 := findNodeStatus(cluster.Status.ControlPlanes.Nodes, "cp-1")
+		require.NotNil(t, cp1)
+		assert.Equal(t, k8znerv1alpha1.NodePhaseUnhealthy, cp1.Phase)
+		assert.Equal(t, "v1.9.0", cp1.TalosVersion)
+	})
+}
+
+func TestCheckUpgradeQuorum(t *testing.T) {
+	t.Parallel()
+
+	t.Run("single control plane is always allowed", func(t *testing.T) {
+		t.Parallel()
+		cluster := newUpgradeTestCluster([]string{"v1.9.0"}, nil)
+		r := newTestReconciler(t, []client.Object{cluster})
+
+		err := r.checkUpgradeQuorum(context.Background(), cluster, talosClients{}, cluster.Status.ControlPlanes.Nodes[0])
+		assert.NoError(t, err)
+	})
+
+	t.Run("blocks when peers cannot keep quorum", func(t *testing.T) {
+		t.Parallel()
+		cluster := newUpgradeTestCluster([]string{"v1.9.0", "v1.9.0", "v1.9.0"}, nil)
+		cluster.Status.ControlPlanes.Nodes[1].Healthy = false
+		r := newTestReconciler(t, []client.Object{cluster})
+
+		err := r.checkUpgradeQuorum(context.Background(), cluster, talosClients{}, cluster.Status.ControlPlanes.Nodes[0])
+		assert.ErrorContains(t, err, "need 2 for quorum")
+	})
+
+	t.Run("blocks when etcd is missing members", func(t *testing.T) {
+		t.Parallel()
+		cluster := newUpgradeTestCluster([]string{"v1.9.0", "v1.9.0", "v1.9.0"}, nil)
+		r := newTestReconciler(t, []client.Object{cluster})
+		tc := talosClients{client: &MockTalosClient{
+			GetEtcdMembersFunc: func(_ context.Context, _ string) ([]etcdMember, error) {
+				return []etcdMember{{ID: "1"}, {ID: "2"}}, nil
+			},
+		}}
+
+		err := r.checkUpgradeQuorum(context.Background(), cluster, tc, cluster.Status.ControlPlanes.Nodes[0])
+		assert.ErrorContains(t, err, "etcd has 2 members")
+	})
+
+	t.Run("allows upgrade with full healthy membership", func(t *testing.T) {
+		t.Parallel()
+		cluster := newUpgradeTestCluster([]string{"v1.9.0", "v1.9.0", "v1.9.0"}, nil)
+		r := newTestReconciler(t, []client.Object{cluster})
+		mockTalos := &MockTalosClient{}
+
+		err := r.checkUpgradeQuorum(context.Background(), cluster, talosClients{client: mockTalos}, cluster.Status.ControlPlanes.Nodes[0])
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.1.2"}, mockTalos.GetEtcdMembersCalls)
+	})
+}
+
+func TestNodesNeedingTalosUpgrade(t *testing.T) {
+	t.Parallel()
+	nodes := []k8znerv1alpha1.NodeStatus{
+		{Name: "b", Healthy: true, TalosVersion: "v1.9.0"},
+		{Name: "a", Healthy: true, TalosVersion: "v1.9.0"},
+		{Name: "c", Healthy: true, TalosVersion: "v1.10.0"},
+		{Name: "d", Healthy: false, TalosVersion: "v1.9.0"},
+		{Name: "e", Healthy: true},
+	}
+
+	pending := nodesNeedingTalosUpgrade(nodes, "v1.10.0")
+	require.Len(t, pending, 2)
+	assert.Equal(t, "a", pending[0].Name)
+	assert.Equal(t, "b", pending[1].Name)
+}
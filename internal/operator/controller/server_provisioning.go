@@ -205,6 +205,7 @@ type serverCreateOpts struct {
 	Labels     map[string]string
 	NetworkID  int64
 	Role       string // "control-plane" or "worker" - for phase tracking
+	Pool       string // worker pool name, recorded in node status; empty for control planes
 }
 
 // serverProvisionResult holds the results of server creation.
@@ -226,6 +227,7 @@ func (r *ClusterReconciler) provisionServer(ctx context.Context, cluster *k8zner
 		Name:   opts.Name,
 		Phase:  k8znerv1alpha1.NodePhaseCreatingServer,
 		Reason: fmt.Sprintf("Creating HCloud server with snapshot %d", opts.SnapshotID),
+		Pool:   opts.Pool,
 	})
 
 	startTime := time.Now()
@@ -307,7 +309,8 @@ type configureNodeFunc func(serverName string, result *serverProvisionResult) er
 type nodeProvisionParams struct {
 	Name          string
 	Role          string // "control-plane" or "worker"
-	Pool          string // "control-plane" or "workers"
+	Pool          string // "control-plane" or the worker pool name
+	Location      string // Hetzner location; empty for spec.region
 	ServerType    string
 	SnapshotID    int64
 	SSHKeyName    string
@@ -332,15 +335,26 @@ func (r *ClusterReconciler) provisionAndConfigureNode(ctx context.Context, clust
 
 	startTime := time.Now()
 
+	location := params.Location
+	if location == "" {
+		location = cluster.Spec.Region
+	}
+	// Only workers record their pool in status; control planes use a fixed pool label.
+	statusPool := ""
+	if params.Role == "worker" {
+		statusPool = params.Pool
+	}
+
 	result, err := r.provisionServer(ctx, cluster, serverCreateOpts{
 		Name:       params.Name,
 		SnapshotID: params.SnapshotID,
 		ServerType: params.ServerType,
-		Region:     cluster.Spec.Region,
+		Region:     location,
 		SSHKeyName: params.SSHKeyName,
 		Labels:     serverLabels,
 		NetworkID:  params.NetworkID,
 		Role:       params.Role,
+		Pool:       statusPool,
 	})
 	if err != nil {
 		logger.Error(err, "failed to provision server", "name", params.Name, "role", params.Role)
@@ -15,6 +15,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
 	"github.com/milankappen/k8zner/internal/platform/hcloud"
 	corev1 "k8s.io/api/core/v1"
 )
@@ -715,7 +716,7 @@ func TestConfigureWorkerNode_NoCredentials(t *testing.T) {
 		TalosIP:  "10.0.0.1",
 	}
 
-	err := r.configureWorkerNode(context.Background(), cluster, tc, result)
+	err := r.configureWorkerNode(context.Background(), cluster, tc, k8znerv1alpha1.WorkerPoolSpec{Name: k8znerv1alpha1.DefaultWorkerPoolName}, result)
 	require.NoError(t, err)
 
 	// Node should be in WaitingForK8s phase
@@ -761,7 +762,7 @@ func TestConfigureWorkerNode_ConfigGenerationFailure(t *testing.T) {
 		TalosIP:  "10.0.0.1",
 	}
 
-	err := r.configureWorkerNode(context.Background(), cluster, tc, result)
+	err := r.configureWorkerNode(context.Background(), cluster, tc, k8znerv1alpha1.WorkerPoolSpec{Name: k8znerv1alpha1.DefaultWorkerPoolName}, result)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "worker config generation error")
 }
@@ -803,7 +804,7 @@ func TestConfigureWorkerNode_ApplyConfigFailure(t *testing.T) {
 		TalosIP:  "10.0.0.1",
 	}
 
-	err := r.configureWorkerNode(context.Background(), cluster, tc, result)
+	err := r.configureWorkerNode(context.Background(), cluster, tc, k8znerv1alpha1.WorkerPoolSpec{Name: k8znerv1alpha1.DefaultWorkerPoolName}, result)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "worker apply failed")
 }
@@ -1009,13 +1010,25 @@ func TestConfigureWorkerNode_SuccessFlow(t *testing.T) {
 		TalosIP:  "10.0.0.1",
 	}
 
-	err := r.configureWorkerNode(context.Background(), cluster, tc, result)
+	pool := k8znerv1alpha1.WorkerPoolSpec{
+		Name:   "gpu",
+		Size:   "cx33",
+		Labels: map[string]string{"accelerator": "gpu"},
+		Taints: []corev1.Taint{{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}},
+	}
+	err := r.configureWorkerNode(context.Background(), cluster, tc, pool, result)
 	require.NoError(t, err)
 
 	// Verify Talos config was generated and applied
 	require.Len(t, mockTalosGen.GenerateWorkerConfigCalls, 1)
 	assert.Equal(t, "w-new", mockTalosGen.GenerateWorkerConfigCalls[0].Hostname)
 	assert.Equal(t, int64(12345), mockTalosGen.GenerateWorkerConfigCalls[0].ServerID)
+	// The node registers with the pool's labels and taints
+	poolConfig := mockTalosGen.GenerateWorkerConfigCalls[0].Pool
+	require.NotNil(t, poolConfig)
+	assert.Equal(t, "gpu", poolConfig.Name)
+	assert.Equal(t, map[string]string{"accelerator": "gpu"}, poolConfig.NodeLabels)
+	assert.Equal(t, []config.Taint{{Key: "gpu", Value: "true", Effect: "NoSchedule"}}, poolConfig.NodeTaints)
 
 	require.Len(t, mockTalos.ApplyConfigCalls, 1)
 	assert.Equal(t, "10.0.0.1", mockTalos.ApplyConfigCalls[0].NodeIP)
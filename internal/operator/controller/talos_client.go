@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -108,6 +109,17 @@ func (c *realTalosClient) GetEtcdMembers(ctx context.Context, nodeIP string) ([]
 		return nil, fmt.Errorf("failed to list etcd members: %w", err)
 	}
 
+	// The member list does not carry leadership; ask the node's etcd for the current leader.
+	// Best effort: if the status call fails, no member is reported as leader.
+	var leaderID uint64
+	if status, err := talosClient.EtcdStatus(ctx, &machine.EtcdStatusRequest{}); err == nil {
+		for _, msg := range status.GetMessages() {
+			if leader := msg.GetMemberStatus().GetLeader(); leader != 0 {
+				leaderID = leader
+			}
+		}
+	}
+
 	var members []etcdMember
 	for _, msg := range resp.Messages {
 		for _, member := range msg.Members {
@@ -115,7 +127,7 @@ func (c *realTalosClient) GetEtcdMembers(ctx context.Context, nodeIP string) ([]
 				ID:       fmt.Sprintf("%d", member.Id),
 				Name:     member.Hostname,
 				Endpoint: member.Hostname,
-				IsLeader: member.IsLearner, // Note: this maps learner, not leader
+				IsLeader: leaderID != 0 && member.Id == leaderID,
 			})
 		}
 	}
@@ -150,6 +162,48 @@ func (c *realTalosClient) RemoveEtcdMember(ctx context.Context, nodeIP string, m
 	return nil
 }
 
+// EtcdSnapshot streams an etcd snapshot from a control plane node into memory.
+func (c *realTalosClient) EtcdSnapshot(ctx context.Context, nodeIP string) ([]byte, error) {
+	talosClient, err := client.New(ctx,
+		client.WithConfig(c.talosConfig),
+		client.WithEndpoints(nodeIP),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create talos client: %w", err)
+	}
+	defer func() { _ = talosClient.Close() }()
+
+	r, err := talosClient.EtcdSnapshot(ctx, &machine.EtcdSnapshotRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start etcd snapshot: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read etcd snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// Kubeconfig issues an admin kubeconfig from a control plane node.
+func (c *realTalosClient) Kubeconfig(ctx context.Context, nodeIP string) ([]byte, error) {
+	talosClient, err := client.New(ctx,
+		client.WithConfig(c.talosConfig),
+		client.WithEndpoints(nodeIP),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create talos client: %w", err)
+	}
+	defer func() { _ = talosClient.Close() }()
+
+	kubeconfig, err := talosClient.Kubeconfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve kubeconfig: %w", err)
+	}
+	return kubeconfig, nil
+}
+
 // WaitForNodeReady waits for a node to become ready after configuration.
 func (c *realTalosClient) WaitForNodeReady(ctx context.Context, nodeIP string, timeoutSec int) error {
 	// Initial wait for reboot to begin
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+	operatorprov "github.com/milankappen/k8zner/internal/operator/provisioning"
+	"github.com/milankappen/k8zner/internal/util/labels"
+)
+
+// workerPools returns the desired worker pools. Clusters without spec.workerPools
+// get a single pool built from spec.workers.
+func workerPools(cluster *k8znerv1alpha1.K8znerCluster) []k8znerv1alpha1.WorkerPoolSpec {
+	if len(cluster.Spec.WorkerPools) > 0 {
+		return cluster.Spec.WorkerPools
+	}
+	return []k8znerv1alpha1.WorkerPoolSpec{{
+		Name:  k8znerv1alpha1.DefaultWorkerPoolName,
+		Count: cluster.Spec.Workers.Count,
+		Size:  cluster.Spec.Workers.Size,
+	}}
+}
+
+// desiredWorkerCount returns the desired number of workers across all pools.
+func desiredWorkerCount(cluster *k8znerv1alpha1.K8znerCluster) int {
+	total := 0
+	for _, pool := range workerPools(cluster) {
+		total += workerPoolDesiredCount(cluster, pool)
+	}
+	return total
+}
+
+// workerPoolDesiredCount returns the number of nodes pool should have. Autoscaled
+// pools use the autoscaler's target from status, starting at pool.Count, and are
+// always kept between MinCount and MaxCount.
+func workerPoolDesiredCount(cluster *k8znerv1alpha1.K8znerCluster, pool k8znerv1alpha1.WorkerPoolSpec) int {
+	as := pool.Autoscaling
+	if as == nil {
+		return pool.Count
+	}
+
+	desired := pool.Count
+	if status := findWorkerPoolStatus(cluster, pool.Name); status != nil {
+		desired = status.Desired
+	}
+	return max(as.MinCount, min(desired, as.MaxCount))
+}
+
+// findWorkerPoolStatus returns the status entry of the named pool, or nil if absent.
+func findWorkerPoolStatus(cluster *k8znerv1alpha1.K8znerCluster, name string) *k8znerv1alpha1.WorkerPoolStatus {
+	for i := range cluster.Status.WorkerPools {
+		if cluster.Status.WorkerPools[i].Name == name {
+			return &cluster.Status.WorkerPools[i]
+		}
+	}
+	return nil
+}
+
+// findWorkerPool returns the desired pool with the given name, or nil if the
+// pool is not (or no longer) in the spec.
+func findWorkerPool(cluster *k8znerv1alpha1.K8znerCluster, name string) *k8znerv1alpha1.WorkerPoolSpec {
+	pools := workerPools(cluster)
+	for i := range pools {
+		if pools[i].Name == name {
+			return &pools[i]
+		}
+	}
+	return nil
+}
+
+// workerPoolConfig returns the pool config the machine config of a worker is
+// generated with, or nil if its pool is no longer in the spec.
+func workerPoolConfig(cluster *k8znerv1alpha1.K8znerCluster, node *k8znerv1alpha1.NodeStatus) *config.WorkerNodePool {
+	pool := findWorkerPool(cluster, workerPoolName(cluster, node))
+	if pool == nil {
+		return nil
+	}
+	poolConfig := operatorprov.WorkerNodePool(&cluster.Spec, *pool)
+	return &poolConfig
+}
+
+// workerPoolName returns the pool a worker belongs to. Workers without a recorded
+// pool predate worker pools and are adopted by the first pool.
+func workerPoolName(cluster *k8znerv1alpha1.K8znerCluster, node *k8znerv1alpha1.NodeStatus) string {
+	if node.Pool != "" {
+		return node.Pool
+	}
+	return workerPools(cluster)[0].Name
+}
+
+// workerPoolNodes returns pointers to the workers in status that belong to pool.
+func workerPoolNodes(cluster *k8znerv1alpha1.K8znerCluster, pool string) []*k8znerv1alpha1.NodeStatus {
+	var nodes []*k8znerv1alpha1.NodeStatus
+	for i := range cluster.Status.Workers.Nodes {
+		node := &cluster.Status.Workers.Nodes[i]
+		if workerPoolName(cluster, node) == pool {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// orphanedWorkerPools returns the names of pools that still have workers but were
+// removed from the spec, sorted for a stable scale-down order.
+func orphanedWorkerPools(cluster *k8znerv1alpha1.K8znerCluster) []string {
+	seen := make(map[string]bool)
+	var orphans []string
+	for i := range cluster.Status.Workers.Nodes {
+		name := workerPoolName(cluster, &cluster.Status.Workers.Nodes[i])
+		if seen[name] || findWorkerPool(cluster, name) != nil {
+			continue
+		}
+		seen[name] = true
+		orphans = append(orphans, name)
+	}
+	sort.Strings(orphans)
+	return orphans
+}
+
+// workerPoolLocation returns the Hetzner location for a pool, defaulting to spec.region.
+func workerPoolLocation(cluster *k8znerv1alpha1.K8znerCluster, pool k8znerv1alpha1.WorkerPoolSpec) string {
+	if pool.Location != "" {
+		return pool.Location
+	}
+	return cluster.Spec.Region
+}
+
+// buildWorkerPoolStatus summarizes worker status per pool. Pools that were removed
+// from the spec are reported with a desired count of zero until their nodes are gone.
+// Autoscaler timestamps are carried over from the previous status.
+func buildWorkerPoolStatus(cluster *k8znerv1alpha1.K8znerCluster) []k8znerv1alpha1.WorkerPoolStatus {
+	pools := workerPools(cluster)
+	statuses := make([]k8znerv1alpha1.WorkerPoolStatus, 0, len(pools))
+	for _, pool := range pools {
+		status := k8znerv1alpha1.WorkerPoolStatus{Name: pool.Name, Desired: workerPoolDesiredCount(cluster, pool)}
+		if prev := findWorkerPoolStatus(cluster, pool.Name); prev != nil && pool.Autoscaling != nil {
+			status.LastScaleUpTime = prev.LastScaleUpTime
+			status.LastScaleDownTime = prev.LastScaleDownTime
+		}
+		statuses = append(statuses, status)
+	}
+	for _, name := range orphanedWorkerPools(cluster) {
+		statuses = append(statuses, k8znerv1alpha1.WorkerPoolStatus{Name: name})
+	}
+
+	index := make(map[string]int, len(statuses))
+	for i, status := range statuses {
+		index[status.Name] = i
+	}
+	for i := range cluster.Status.Workers.Nodes {
+		node := &cluster.Status.Workers.Nodes[i]
+		status := &statuses[index[workerPoolName(cluster, node)]]
+		if node.Healthy {
+			status.Ready++
+		} else {
+			status.Unhealthy++
+		}
+	}
+
+	return statuses
+}
+
+// syncWorkerPoolNodeMetadata applies the pool label, the pool's Kubernetes labels and
+// its taints to a worker node. Labels and taints are only added or updated, never
+// removed, so anything set by other controllers or by hand is left alone.
+func (r *ClusterReconciler) syncWorkerPoolNodeMetadata(ctx context.Context, node *corev1.Node, pool *k8znerv1alpha1.WorkerPoolSpec) {
+	logger := log.FromContext(ctx)
+
+	changed := false
+	desiredLabels := map[string]string{labels.KeyPool: pool.Name}
+	for key, value := range pool.Labels {
+		desiredLabels[key] = value
+	}
+	for key, value := range desiredLabels {
+		if current, ok := node.Labels[key]; ok && current == value {
+			continue
+		}
+		if node.Labels == nil {
+			node.Labels = make(map[string]string)
+		}
+		node.Labels[key] = value
+		changed = true
+	}
+
+	for _, taint := range pool.Taints {
+		if mergeTaint(&node.Spec.Taints, taint) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return
+	}
+	if err := r.Update(ctx, node); err != nil {
+		logger.Error(err, "failed to apply worker pool labels and taints", "node", node.Name, "pool", pool.Name)
+		return
+	}
+	logger.Info("applied worker pool labels and taints", "node", node.Name, "pool", pool.Name)
+}
+
+// mergeTaint adds taint to taints, or updates the value of an existing taint with the
+// same key and effect. Returns true if taints changed.
+func mergeTaint(taints *[]corev1.Taint, taint corev1.Taint) bool {
+	for i := range *taints {
+		existing := &(*taints)[i]
+		if existing.Key != taint.Key || existing.Effect != taint.Effect {
+			continue
+		}
+		if existing.Value == taint.Value {
+			return false
+		}
+		existing.Value = taint.Value
+		return true
+	}
+	*taints = append(*taints, corev1.Taint{Key: taint.Key, Value: taint.Value, Effect: taint.Effect})
+	return true
+}
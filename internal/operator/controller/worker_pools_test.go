@@ -0,0 +1,325 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hcloudgo "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/util/labels"
+)
+
+func newWorkerPoolCluster(pools []k8znerv1alpha1.WorkerPoolSpec, nodes ...k8znerv1alpha1.NodeStatus) *k8znerv1alpha1.K8znerCluster {
+	return &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			Region:      "nbg1",
+			Workers:     k8znerv1alpha1.WorkerSpec{Count: 1, Size: "cx23"},
+			WorkerPools: pools,
+		},
+		Status: k8znerv1alpha1.K8znerClusterStatus{
+			Workers: k8znerv1alpha1.NodeGroupStatus{Nodes: nodes},
+		},
+	}
+}
+
+func TestWorkerPools(t *testing.T) {
+	t.Parallel()
+
+	t.Run("falls back to spec.workers", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster(nil)
+		cluster.Spec.Workers.Count = 3
+
+		pools := workerPools(cluster)
+		require.Len(t, pools, 1)
+		assert.Equal(t, k8znerv1alpha1.DefaultWorkerPoolName, pools[0].Name)
+		assert.Equal(t, 3, pools[0].Count)
+		assert.Equal(t, "cx23", pools[0].Size)
+		assert.Equal(t, 3, desiredWorkerCount(cluster))
+	})
+
+	t.Run("spec.workerPools replaces spec.workers", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{
+			{Name: "general", Count: 2, Size: "cx23"},
+			{Name: "gpu", Count: 1, Size: "cx53", Location: "fsn1"},
+		})
+
+		assert.Equal(t, 3, desiredWorkerCount(cluster))
+		assert.Nil(t, findWorkerPool(cluster, k8znerv1alpha1.DefaultWorkerPoolName))
+		assert.Equal(t, "nbg1", workerPoolLocation(cluster, *findWorkerPool(cluster, "general")))
+		assert.Equal(t, "fsn1", workerPoolLocation(cluster, *findWorkerPool(cluster, "gpu")))
+	})
+
+	t.Run("workers without a pool belong to the first pool", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{
+			{Name: "general", Count: 2},
+			{Name: "gpu", Count: 1},
+		},
+			k8znerv1alpha1.NodeStatus{Name: "w-1"},
+			k8znerv1alpha1.NodeStatus{Name: "w-2", Pool: "gpu"},
+		)
+
+		assert.Len(t, workerPoolNodes(cluster, "general"), 1)
+		assert.Equal(t, "w-2", workerPoolNodes(cluster, "gpu")[0].Name)
+		assert.Empty(t, orphanedWorkerPools(cluster))
+	})
+
+	t.Run("reports pools removed from the spec", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{{Name: "general", Count: 1}},
+			k8znerv1alpha1.NodeStatus{Name: "w-1", Pool: "general"},
+			k8znerv1alpha1.NodeStatus{Name: "w-2", Pool: "old"},
+			k8znerv1alpha1.NodeStatus{Name: "w-3", Pool: "old"},
+			k8znerv1alpha1.NodeStatus{Name: "w-4", Pool: "legacy"},
+		)
+
+		assert.Equal(t, []string{"legacy", "old"}, orphanedWorkerPools(cluster))
+	})
+}
+
+func TestBuildWorkerPoolStatus(t *testing.T) {
+	t.Parallel()
+
+	cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{
+		{Name: "general", Count: 2},
+		{Name: "gpu", Count: 1},
+	},
+		k8znerv1alpha1.NodeStatus{Name: "w-1", Pool: "general", Healthy: true},
+		k8znerv1alpha1.NodeStatus{Name: "w-2", Pool: "general"},
+		k8znerv1alpha1.NodeStatus{Name: "w-3", Pool: "old", Healthy: true},
+	)
+
+	assert.Equal(t, []k8znerv1alpha1.WorkerPoolStatus{
+		{Name: "general", Desired: 2, Ready: 1, Unhealthy: 1},
+		{Name: "gpu", Desired: 1},
+		{Name: "old", Ready: 1},
+	}, buildWorkerPoolStatus(cluster))
+}
+
+func TestMergeTaint(t *testing.T) {
+	t.Parallel()
+
+	taints := []corev1.Taint{{Key: "gpu", Value: "a", Effect: corev1.TaintEffectNoSchedule}}
+
+	assert.False(t, mergeTaint(&taints, corev1.Taint{Key: "gpu", Value: "a", Effect: corev1.TaintEffectNoSchedule}))
+	assert.True(t, mergeTaint(&taints, corev1.Taint{Key: "gpu", Value: "b", Effect: corev1.TaintEffectNoSchedule}))
+	assert.True(t, mergeTaint(&taints, corev1.Taint{Key: "gpu", Effect: corev1.TaintEffectNoExecute}))
+
+	assert.Equal(t, []corev1.Taint{
+		{Key: "gpu", Value: "b", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "gpu", Effect: corev1.TaintEffectNoExecute},
+	}, taints)
+}
+
+func TestSyncWorkerPoolNodeMetadata(t *testing.T) {
+	t.Parallel()
+	scheme := setupTestScheme(t)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "w-1",
+			Labels: map[string]string{"existing": "kept", "tier": "old"},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{Key: "manual", Effect: corev1.TaintEffectPreferNoSchedule}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node).Build()
+	r := NewClusterReconciler(fakeClient, scheme, record.NewFakeRecorder(10), WithMetrics(false))
+
+	pool := &k8znerv1alpha1.WorkerPoolSpec{
+		Name:   "gpu",
+		Labels: map[string]string{"tier": "gpu"},
+		Taints: []corev1.Taint{{Key: "nvidia.com/gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}},
+	}
+
+	current := &corev1.Node{}
+	require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "w-1"}, current))
+	r.syncWorkerPoolNodeMetadata(context.Background(), current, pool)
+
+	updated := &corev1.Node{}
+	require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "w-1"}, updated))
+	assert.Equal(t, "gpu", updated.Labels[labels.KeyPool])
+	assert.Equal(t, "gpu", updated.Labels["tier"])
+	assert.Equal(t, "kept", updated.Labels["existing"])
+	assert.ElementsMatch(t, []corev1.Taint{
+		{Key: "manual", Effect: corev1.TaintEffectPreferNoSchedule},
+		{Key: "nvidia.com/gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule},
+	}, updated.Spec.Taints)
+}
+
+func TestReconcileHealthCheck_WorkerPools(t *testing.T) {
+	t.Parallel()
+	scheme := setupTestScheme(t)
+
+	readyNode := func(name string, nodeLabels map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{
+		{Name: "general", Count: 1},
+		{Name: "gpu", Count: 2, Labels: map[string]string{"tier": "gpu"}},
+	},
+		// Recorded when the operator created the server
+		k8znerv1alpha1.NodeStatus{Name: "w-gpu", Pool: "gpu"},
+	)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		readyNode("w-general", map[string]string{labels.KeyPool: "general"}),
+		readyNode("w-gpu", nil),
+	).Build()
+	r := NewClusterReconciler(fakeClient, scheme, record.NewFakeRecorder(10), WithMetrics(false))
+
+	require.NoError(t, r.reconcileHealthCheck(context.Background(), cluster))
+
+	assert.Equal(t, 3, cluster.Status.Workers.Desired)
+	assert.Equal(t, 2, cluster.Status.Workers.Ready)
+	assert.Equal(t, []k8znerv1alpha1.WorkerPoolStatus{
+		{Name: "general", Desired: 1, Ready: 1},
+		{Name: "gpu", Desired: 2, Ready: 1},
+	}, cluster.Status.WorkerPools)
+
+	gpuNode := &corev1.Node{}
+	require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Name: "w-gpu"}, gpuNode))
+	assert.Equal(t, "gpu", gpuNode.Labels[labels.KeyPool])
+	assert.Equal(t, "gpu", gpuNode.Labels["tier"])
+}
+
+func TestScaleWorkers_WorkerPools(t *testing.T) {
+	t.Parallel()
+
+	newReconciler := func(t *testing.T, cluster *k8znerv1alpha1.K8znerCluster) (*ClusterReconciler, *MockHCloudClient) {
+		t.Helper()
+		scheme := setupTestScheme(t)
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(cluster).
+			WithStatusSubresource(cluster).
+			Build()
+		mockHCloud := &MockHCloudClient{
+			GetNetworkFunc: func(ctx context.Context, name string) (*hcloudgo.Network, error) {
+				return &hcloudgo.Network{ID: 123}, nil
+			},
+		}
+		r := NewClusterReconciler(fakeClient, scheme, record.NewFakeRecorder(20),
+			WithHCloudClient(mockHCloud),
+			WithTalosClient(&MockTalosClient{}),
+			WithTalosConfigGenerator(&MockTalosConfigGenerator{}),
+			WithMetrics(false),
+			WithNodeReadyWaiter(func(ctx context.Context, nodeName string, timeout time.Duration) error {
+				return nil
+			}),
+		)
+		return r, mockHCloud
+	}
+
+	t.Run("scales up only the pool below its count", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{
+			{Name: "general", Count: 1, Size: "cx23"},
+			{Name: "gpu", Count: 1, Size: "cx53", Location: "fsn1"},
+		},
+			k8znerv1alpha1.NodeStatus{Name: "w-1", Pool: "general", Healthy: true, Phase: k8znerv1alpha1.NodePhaseReady},
+		)
+		r, mockHCloud := newReconciler(t, cluster)
+
+		result, err := r.scaleWorkers(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, fastRequeueAfter, result.RequeueAfter)
+
+		require.Len(t, mockHCloud.CreateServerCalls, 1)
+		created := mockHCloud.CreateServerCalls[0]
+		assert.Equal(t, "cx53", created.ServerType)
+		assert.Equal(t, "fsn1", created.Location)
+		assert.Equal(t, "gpu", created.Labels[labels.KeyPool])
+
+		assert.Len(t, workerPoolNodes(cluster, "gpu"), 1)
+		assert.Empty(t, mockHCloud.DeleteServerCalls)
+	})
+
+	t.Run("scales down only the pool above its count", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{
+			{Name: "general", Count: 1},
+			{Name: "gpu", Count: 1},
+		},
+			k8znerv1alpha1.NodeStatus{Name: "w-1", Pool: "general", Healthy: true},
+			k8znerv1alpha1.NodeStatus{Name: "w-2", Pool: "general", Healthy: true},
+			k8znerv1alpha1.NodeStatus{Name: "w-3", Pool: "gpu", Healthy: true},
+		)
+		r, mockHCloud := newReconciler(t, cluster)
+
+		result, err := r.scaleWorkers(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Equal(t, []string{"w-2"}, mockHCloud.DeleteServerCalls)
+		assert.Empty(t, mockHCloud.CreateServerCalls)
+	})
+
+	t.Run("drains removed pools after the others converge", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{{Name: "general", Count: 1}},
+			k8znerv1alpha1.NodeStatus{Name: "w-1", Pool: "general", Healthy: true},
+			k8znerv1alpha1.NodeStatus{Name: "w-2", Pool: "old", Healthy: true},
+		)
+		r, mockHCloud := newReconciler(t, cluster)
+
+		result, err := r.scaleWorkers(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Equal(t, defaultRequeueAfter, result.RequeueAfter)
+		assert.Equal(t, []string{"w-2"}, mockHCloud.DeleteServerCalls)
+	})
+
+	t.Run("keeps removed pools while another pool scales up", func(t *testing.T) {
+		t.Parallel()
+		cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{{Name: "general", Count: 2}},
+			k8znerv1alpha1.NodeStatus{Name: "w-1", Pool: "general", Healthy: true, Phase: k8znerv1alpha1.NodePhaseReady},
+			k8znerv1alpha1.NodeStatus{Name: "w-2", Pool: "old", Healthy: true, Phase: k8znerv1alpha1.NodePhaseReady},
+		)
+		r, mockHCloud := newReconciler(t, cluster)
+
+		_, err := r.scaleWorkers(context.Background(), cluster)
+		require.NoError(t, err)
+		assert.Len(t, mockHCloud.CreateServerCalls, 1)
+		assert.Empty(t, mockHCloud.DeleteServerCalls)
+	})
+}
+
+func TestReplaceWorker_RemovedPool(t *testing.T) {
+	t.Parallel()
+	scheme := setupTestScheme(t)
+
+	cluster := newWorkerPoolCluster([]k8znerv1alpha1.WorkerPoolSpec{{Name: "general", Count: 1}},
+		k8znerv1alpha1.NodeStatus{Name: "w-1", Pool: "general", Healthy: true},
+		k8znerv1alpha1.NodeStatus{Name: "w-2", Pool: "old"},
+	)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	mockHCloud := &MockHCloudClient{}
+	r := NewClusterReconciler(fakeClient, scheme, record.NewFakeRecorder(10),
+		WithHCloudClient(mockHCloud),
+		WithMetrics(false),
+	)
+
+	require.NoError(t, r.replaceWorker(context.Background(), cluster, &cluster.Status.Workers.Nodes[1]))
+	assert.Equal(t, []string{"w-2"}, mockHCloud.DeleteServerCalls)
+	assert.Empty(t, mockHCloud.CreateServerCalls, "workers of a removed pool are not replaced")
+	require.Len(t, cluster.Status.Workers.Nodes, 1)
+	assert.Equal(t, "w-1", cluster.Status.Workers.Nodes[0].Name)
+}
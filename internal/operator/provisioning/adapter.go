@@ -32,6 +32,11 @@ type Credentials struct {
 	TalosConfig        []byte
 	CloudflareAPIToken string // Optional, for DNS/TLS integration
 
+	// CA rotation state: the bundle whose CAs nodes accept next to their own
+	// while a rotation is in progress, and the admin kubeconfig it re-issued
+	TalosAcceptedSecrets []byte
+	Kubeconfig           []byte
+
 	// Backup S3 credentials (loaded from S3SecretRef if specified)
 	BackupS3AccessKey string
 	BackupS3SecretKey string
@@ -108,6 +113,14 @@ func extractCredentials(secret *corev1.Secret) (*Credentials, error) {
 		creds.TalosConfig = cfg
 	}
 
+	if accepted, ok := secret.Data[k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets]; ok {
+		creds.TalosAcceptedSecrets = accepted
+	}
+
+	if kubeconfig, ok := secret.Data[k8znerv1alpha1.CredentialsKeyKubeconfig]; ok {
+		creds.Kubeconfig = kubeconfig
+	}
+
 	if cfToken, ok := secret.Data[k8znerv1alpha1.CredentialsKeyCloudflareAPIToken]; ok {
 		creds.CloudflareAPIToken = string(cfToken)
 	}
@@ -312,6 +325,7 @@ func populateStateFromCRD(pCtx *provisioning.Context, k8sCluster *k8znerv1alpha1
 		if node.Name != "" && node.PublicIP != "" {
 			pCtx.State.WorkerIPs[node.Name] = node.PublicIP
 			pCtx.State.WorkerServerIDs[node.Name] = node.ServerID
+			pCtx.State.WorkerPools[node.Name] = node.Pool
 		}
 	}
 
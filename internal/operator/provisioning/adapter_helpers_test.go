@@ -40,6 +40,22 @@ func TestExtractCredentials_AllFields(t *testing.T) {
 	assert.Equal(t, "cf-token-456", creds.CloudflareAPIToken)
 }
 
+func TestExtractCredentials_CARotation(t *testing.T) {
+	t.Parallel()
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			k8znerv1alpha1.CredentialsKeyHCloudToken:          []byte("token"),
+			k8znerv1alpha1.CredentialsKeyTalosAcceptedSecrets: []byte("accepted-secrets-yaml"),
+			k8znerv1alpha1.CredentialsKeyKubeconfig:           []byte("kubeconfig-yaml"),
+		},
+	}
+
+	creds, err := extractCredentials(secret)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("accepted-secrets-yaml"), creds.TalosAcceptedSecrets)
+	assert.Equal(t, []byte("kubeconfig-yaml"), creds.Kubeconfig)
+}
+
 func TestExtractCredentials_OnlyRequiredField(t *testing.T) {
 	t.Parallel()
 	secret := &corev1.Secret{
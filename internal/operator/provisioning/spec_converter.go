@@ -68,14 +68,7 @@ func SpecToConfig(k8sCluster *k8znerv1alpha1.K8znerCluster, creds *Credentials)
 		// Worker configuration
 		// IMPORTANT: Workers are created by the reconciliation loop (scaleUpWorkers),
 		// NOT by the compute provisioner. Set Count=0 here to avoid duplicate workers.
-		Workers: []config.WorkerNodePool{
-			{
-				Name:       "workers",
-				Location:   spec.Region,
-				ServerType: string(config.ServerSize(spec.Workers.Size).Normalize()),
-				Count:      0, // Workers created by reconcileWorkers, not compute provisioner
-			},
-		},
+		Workers: buildWorkerPools(spec),
 
 		// Enable essential addons
 		Addons: buildAddonsConfig(spec),
@@ -92,6 +85,53 @@ func SpecToConfig(k8sCluster *k8znerv1alpha1.K8znerCluster, creds *Credentials)
 	return cfg, nil
 }
 
+// buildWorkerPools creates one worker pool per spec.workerPools entry, or a single
+// "workers" pool from spec.workers. Counts are always 0: workers are created by
+// reconcileWorkers, not the compute provisioner.
+func buildWorkerPools(spec *k8znerv1alpha1.K8znerClusterSpec) []config.WorkerNodePool {
+	if len(spec.WorkerPools) == 0 {
+		return []config.WorkerNodePool{
+			{
+				Name:       k8znerv1alpha1.DefaultWorkerPoolName,
+				Location:   spec.Region,
+				ServerType: string(config.ServerSize(spec.Workers.Size).Normalize()),
+				Count:      0,
+			},
+		}
+	}
+
+	pools := make([]config.WorkerNodePool, 0, len(spec.WorkerPools))
+	for _, pool := range spec.WorkerPools {
+		pools = append(pools, WorkerNodePool(spec, pool))
+	}
+	return pools
+}
+
+// WorkerNodePool converts a pool of spec.workerPools into the worker pool config
+// the Talos config generator registers its nodes with.
+func WorkerNodePool(spec *k8znerv1alpha1.K8znerClusterSpec, pool k8znerv1alpha1.WorkerPoolSpec) config.WorkerNodePool {
+	location := pool.Location
+	if location == "" {
+		location = spec.Region
+	}
+	var taints []config.Taint
+	for _, taint := range pool.Taints {
+		taints = append(taints, config.Taint{
+			Key:    taint.Key,
+			Value:  taint.Value,
+			Effect: string(taint.Effect),
+		})
+	}
+	return config.WorkerNodePool{
+		Name:       pool.Name,
+		Location:   location,
+		ServerType: string(config.ServerSize(pool.Size).Normalize()),
+		Count:      0,
+		NodeLabels: pool.Labels,
+		NodeTaints: taints,
+	}
+}
+
 // buildAddonsConfig creates the addons configuration from the CRD spec.
 func buildAddonsConfig(spec *k8znerv1alpha1.K8znerClusterSpec) config.AddonsConfig {
 	return config.AddonsConfig{
@@ -121,11 +161,19 @@ func buildAddonsConfig(spec *k8znerv1alpha1.K8znerClusterSpec) config.AddonsConf
 
 // configureBackup maps backup configuration from spec.Backup to cfg.Addons.TalosBackup.
 func configureBackup(cfg *config.Config, spec *k8znerv1alpha1.K8znerClusterSpec, creds *Credentials) {
+	if backup, ok := BackupConfig(spec, creds); ok {
+		cfg.Addons.TalosBackup = backup
+	}
+}
+
+// BackupConfig returns the talos-backup configuration for spec.Backup, and
+// false if backups are disabled or have no S3 credentials.
+func BackupConfig(spec *k8znerv1alpha1.K8znerClusterSpec, creds *Credentials) (config.TalosBackupConfig, bool) {
 	if spec.Backup == nil || !spec.Backup.Enabled {
-		return
+		return config.TalosBackupConfig{}, false
 	}
 	if creds.BackupS3AccessKey == "" || creds.BackupS3SecretKey == "" {
-		return
+		return config.TalosBackupConfig{}, false
 	}
 
 	backup := config.DefaultTalosBackup()
@@ -136,7 +184,7 @@ func configureBackup(cfg *config.Config, spec *k8znerv1alpha1.K8znerClusterSpec,
 	backup.S3Endpoint = creds.BackupS3Endpoint
 	backup.S3Bucket = creds.BackupS3Bucket
 	backup.S3Region = creds.BackupS3Region
-	cfg.Addons.TalosBackup = backup
+	return backup, true
 }
 
 // configureCloudflare enables Cloudflare integration when ExternalDNS is active.
@@ -271,6 +319,14 @@ func (a *PhaseAdapter) CreateTalosGenerator(
 
 	generator.SetMachineConfigOptions(buildMachineConfigOptions(k8sCluster))
 
+	if len(creds.TalosAcceptedSecrets) > 0 {
+		accepted, err := parseSecretsFromBytes(creds.TalosAcceptedSecrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse accepted talos secrets: %w", err)
+		}
+		generator.AcceptCAs(accepted)
+	}
+
 	return generator, nil
 }
 
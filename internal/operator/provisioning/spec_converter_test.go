@@ -67,6 +67,32 @@ func TestSpecToConfig_WorkerCountZero(t *testing.T) {
 	assert.Equal(t, "workers", cfg.Workers[0].Name)
 }
 
+func TestSpecToConfig_WorkerPools(t *testing.T) {
+	t.Parallel()
+	cluster := newTestCluster("test", "", &k8znerv1alpha1.AddonSpec{})
+	cluster.Spec.WorkerPools = []k8znerv1alpha1.WorkerPoolSpec{
+		{Name: "general", Count: 2, Size: "cx23"},
+		{Name: "gpu", Count: 1, Size: "cx52", Location: "hel1",
+			Labels: map[string]string{"accelerator": "nvidia"},
+			Taints: []corev1.Taint{{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}}},
+	}
+
+	cfg, err := SpecToConfig(cluster, baseCreds())
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Workers, 2)
+	assert.Equal(t, "general", cfg.Workers[0].Name)
+	assert.Equal(t, cluster.Spec.Region, cfg.Workers[0].Location)
+	assert.Equal(t, "gpu", cfg.Workers[1].Name)
+	assert.Equal(t, "cx53", cfg.Workers[1].ServerType)
+	assert.Equal(t, "hel1", cfg.Workers[1].Location)
+	assert.Equal(t, map[string]string{"accelerator": "nvidia"}, cfg.Workers[1].NodeLabels)
+	assert.Equal(t, []config.Taint{{Key: "gpu", Value: "true", Effect: "NoSchedule"}}, cfg.Workers[1].NodeTaints)
+	for _, pool := range cfg.Workers {
+		assert.Equal(t, 0, pool.Count, "worker count must be 0 — reconciler creates workers")
+	}
+}
+
 func TestSpecToConfig_TalosConfig(t *testing.T) {
 	t.Parallel()
 	cluster := newTestCluster("test", "", &k8znerv1alpha1.AddonSpec{})
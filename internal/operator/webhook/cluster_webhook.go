@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+)
+
+// +kubebuilder:webhook:path=/mutate-k8zner-io-v1alpha1-k8znercluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=k8zner.io,resources=k8znerclusters,verbs=create;update,versions=v1alpha1,name=mk8znercluster.k8zner.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-k8zner-io-v1alpha1-k8znercluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=k8zner.io,resources=k8znerclusters,verbs=create;update,versions=v1alpha1,name=vk8znercluster.k8zner.io,admissionReviewVersions=v1
+
+// ClusterWebhook defaults and validates K8znerCluster resources.
+type ClusterWebhook struct{}
+
+var (
+	_ admission.Defaulter[*k8znerv1alpha1.K8znerCluster] = &ClusterWebhook{}
+	_ admission.Validator[*k8znerv1alpha1.K8znerCluster] = &ClusterWebhook{}
+)
+
+// SetupWithManager registers the mutating and validating webhooks with the manager's webhook server.
+func (w *ClusterWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &k8znerv1alpha1.K8znerCluster{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default fills in unset fields of the cluster spec.
+func (w *ClusterWebhook) Default(_ context.Context, cluster *k8znerv1alpha1.K8znerCluster) error {
+	applyDefaults(&cluster.Spec)
+	return nil
+}
+
+// ValidateCreate validates a new cluster.
+func (w *ClusterWebhook) ValidateCreate(_ context.Context, cluster *k8znerv1alpha1.K8znerCluster) (admission.Warnings, error) {
+	cluster = defaulted(cluster)
+	warnings, errs := validateSpec(&cluster.Spec)
+	return warnings, invalid(cluster, errs)
+}
+
+// ValidateUpdate validates a cluster change, including whether the operator can
+// move the running cluster from the old spec to the new one.
+func (w *ClusterWebhook) ValidateUpdate(_ context.Context, oldCluster, newCluster *k8znerv1alpha1.K8znerCluster) (admission.Warnings, error) {
+	// Teardown must never be blocked by validation
+	if !newCluster.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	oldCluster, newCluster = defaulted(oldCluster), defaulted(newCluster)
+	// Metadata updates such as finalizers must pass even if the stored spec
+	// predates the webhook and would no longer be accepted
+	if equality.Semantic.DeepEqual(oldCluster.Spec, newCluster.Spec) {
+		return nil, nil
+	}
+
+	warnings, errs := validateSpec(&newCluster.Spec)
+	errs = append(errs, validateTransition(oldCluster, newCluster)...)
+	return warnings, invalid(newCluster, errs)
+}
+
+// ValidateDelete allows every deletion; the deletion policy decides what happens to the cloud resources.
+func (w *ClusterWebhook) ValidateDelete(_ context.Context, _ *k8znerv1alpha1.K8znerCluster) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// defaulted returns a copy of cluster with defaults applied, so validation sees
+// the effective values even for objects stored before the webhook existed.
+func defaulted(cluster *k8znerv1alpha1.K8znerCluster) *k8znerv1alpha1.K8znerCluster {
+	cluster = cluster.DeepCopy()
+	applyDefaults(&cluster.Spec)
+	return cluster
+}
+
+// invalid wraps field errors in the Invalid status error the API server returns to clients.
+func invalid(cluster *k8znerv1alpha1.K8znerCluster, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	gk := schema.GroupKind{Group: k8znerv1alpha1.GroupVersion.Group, Kind: "K8znerCluster"}
+	return apierrors.NewInvalid(gk, cluster.Name, errs)
+}
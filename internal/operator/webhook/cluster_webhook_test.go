@@ -0,0 +1,233 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+)
+
+// newTestCluster returns a minimal valid cluster with the pinned versions.
+func newTestCluster() *k8znerv1alpha1.K8znerCluster {
+	matrix := config.DefaultVersionMatrix()
+	return &k8znerv1alpha1.K8znerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: k8znerv1alpha1.K8znerClusterSpec{
+			Region:         "fsn1",
+			ControlPlanes:  k8znerv1alpha1.ControlPlaneSpec{Count: 3},
+			Workers:        k8znerv1alpha1.WorkerSpec{Count: 2},
+			Kubernetes:     k8znerv1alpha1.KubernetesSpec{Version: matrix.Kubernetes},
+			Talos:          k8znerv1alpha1.TalosSpec{Version: matrix.Talos},
+			CredentialsRef: corev1.LocalObjectReference{Name: "test-credentials"},
+		},
+	}
+}
+
+// newRunningTestCluster returns a cluster whose status reports ready control planes.
+func newRunningTestCluster(ready int) *k8znerv1alpha1.K8znerCluster {
+	cluster := newTestCluster()
+	cluster.Status.ControlPlanes = k8znerv1alpha1.NodeGroupStatus{
+		Desired: 3,
+		Ready:   ready,
+		Nodes:   []k8znerv1alpha1.NodeStatus{{Name: "cp-1"}, {Name: "cp-2"}, {Name: "cp-3"}},
+	}
+	return cluster
+}
+
+func TestDefault(t *testing.T) {
+	t.Parallel()
+	cluster := newTestCluster()
+	cluster.Spec.Workers.Size = "CX32"
+	cluster.Spec.WorkerPools = []k8znerv1alpha1.WorkerPoolSpec{{
+		Name:        "batch",
+		Count:       1,
+		Autoscaling: &k8znerv1alpha1.WorkerPoolAutoscalingSpec{MinCount: 1, MaxCount: 3},
+	}}
+	cluster.Spec.Maintenance = &k8znerv1alpha1.MaintenanceSpec{}
+	cluster.Spec.Backup = &k8znerv1alpha1.BackupSpec{Enabled: true, Verification: &k8znerv1alpha1.BackupVerificationSpec{Enabled: true}}
+
+	require.NoError(t, (&ClusterWebhook{}).Default(context.Background(), cluster))
+
+	spec := cluster.Spec
+	assert.Equal(t, k8znerv1alpha1.DeletionPolicyRetain, spec.DeletionPolicy)
+	assert.Equal(t, "cx23", spec.ControlPlanes.Size)
+	assert.Equal(t, "cx33", spec.Workers.Size, "legacy sizes are normalized")
+	assert.Equal(t, "cx23", spec.WorkerPools[0].Size)
+	assert.Equal(t, 50, spec.WorkerPools[0].Autoscaling.ScaleDownUtilizationThreshold)
+	assert.Equal(t, "10m", spec.WorkerPools[0].Autoscaling.ScaleDownDelay)
+	assert.Equal(t, k8znerv1alpha1.NetworkSpec{
+		IPv4CIDR:     config.NetworkCIDR,
+		NodeIPv4CIDR: config.NodeCIDR,
+		PodCIDR:      config.PodCIDR,
+		ServiceCIDR:  config.ServiceCIDR,
+	}, spec.Network)
+	assert.Equal(t, 1, spec.Talos.UpgradeBatchSize)
+	assert.Equal(t, "auto", spec.Talos.ConfigApplyMode)
+	assert.Equal(t, "UTC", spec.Maintenance.Timezone)
+	assert.Equal(t, "0 * * * *", spec.Backup.Schedule)
+	assert.Equal(t, "168h", spec.Backup.Retention)
+	assert.Equal(t, 3, spec.Backup.KeepLast)
+	assert.Equal(t, "0 4 * * *", spec.Backup.Verification.Schedule)
+	assert.Nil(t, spec.HealthCheck, "optional sections are not created")
+}
+
+func TestDefault_KeepsExplicitValues(t *testing.T) {
+	t.Parallel()
+	cluster := newTestCluster()
+	cluster.Spec.DeletionPolicy = k8znerv1alpha1.DeletionPolicyDelete
+	cluster.Spec.Network.PodCIDR = "10.244.0.0/16"
+	cluster.Spec.Talos.UpgradeBatchSize = 3
+
+	require.NoError(t, (&ClusterWebhook{}).Default(context.Background(), cluster))
+
+	assert.Equal(t, k8znerv1alpha1.DeletionPolicyDelete, cluster.Spec.DeletionPolicy)
+	assert.Equal(t, "10.244.0.0/16", cluster.Spec.Network.PodCIDR)
+	assert.Equal(t, 3, cluster.Spec.Talos.UpgradeBatchSize)
+}
+
+func TestValidateCreate(t *testing.T) {
+	t.Parallel()
+	w := &ClusterWebhook{}
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+		warnings, err := w.ValidateCreate(context.Background(), newTestCluster())
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		cluster := newTestCluster()
+		cluster.Spec.ControlPlanes.Size = "cx99"
+		cluster.Spec.Network.PodCIDR = "10.0.0.0/16"
+
+		_, err := w.ValidateCreate(context.Background(), cluster)
+		require.Error(t, err)
+		assert.True(t, apierrors.IsInvalid(err))
+		assert.Contains(t, err.Error(), "spec.controlPlanes.size")
+		assert.Contains(t, err.Error(), "spec.network.podCIDR")
+	})
+
+	t.Run("untested versions warn", func(t *testing.T) {
+		t.Parallel()
+		cluster := newTestCluster()
+		cluster.Spec.Talos.Version = "v1.10.2"
+		cluster.Spec.Kubernetes.Version = "1.33.1"
+
+		warnings, err := w.ValidateCreate(context.Background(), cluster)
+		require.NoError(t, err)
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0], "has not been tested")
+	})
+}
+
+func TestValidateUpdate(t *testing.T) {
+	t.Parallel()
+	w := &ClusterWebhook{}
+
+	tests := []struct {
+		name      string
+		oldStatus int
+		mutate    func(*k8znerv1alpha1.K8znerCluster)
+		wantErr   string
+	}{
+		{
+			name:      "scale workers",
+			oldStatus: 3,
+			mutate:    func(c *k8znerv1alpha1.K8znerCluster) { c.Spec.Workers.Count = 5 },
+		},
+		{
+			name:      "scale control planes down with quorum",
+			oldStatus: 2,
+			mutate:    func(c *k8znerv1alpha1.K8znerCluster) { c.Spec.ControlPlanes.Count = 1 },
+		},
+		{
+			name:      "scale control planes down without quorum",
+			oldStatus: 1,
+			mutate:    func(c *k8znerv1alpha1.K8znerCluster) { c.Spec.ControlPlanes.Count = 1 },
+			wantErr:   "etcd needs 2 for quorum",
+		},
+		{
+			name:      "even control plane count",
+			oldStatus: 3,
+			mutate:    func(c *k8znerv1alpha1.K8znerCluster) { c.Spec.ControlPlanes.Count = 2 },
+			wantErr:   "spec.controlPlanes.count",
+		},
+		{
+			name:      "change region",
+			oldStatus: 3,
+			mutate:    func(c *k8znerv1alpha1.K8znerCluster) { c.Spec.Region = "nbg1" },
+			wantErr:   "spec.region",
+		},
+		{
+			name:      "change network CIDR",
+			oldStatus: 3,
+			mutate:    func(c *k8znerv1alpha1.K8znerCluster) { c.Spec.Network.ServiceCIDR = "10.112.0.0/12" },
+			wantErr:   "spec.network.serviceCIDR",
+		},
+		{
+			name:      "set network CIDR to its default",
+			oldStatus: 3,
+			mutate: func(c *k8znerv1alpha1.K8znerCluster) {
+				c.Spec.Network.ServiceCIDR = config.ServiceCIDR
+				c.Spec.Workers.Count = 3
+			},
+		},
+		{
+			name:      "skip a Kubernetes minor version",
+			oldStatus: 3,
+			mutate:    func(c *k8znerv1alpha1.K8znerCluster) { c.Spec.Kubernetes.Version = "1.34.0" },
+			wantErr:   "one minor version at a time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			oldCluster := newRunningTestCluster(tt.oldStatus)
+			newCluster := oldCluster.DeepCopy()
+			tt.mutate(newCluster)
+
+			_, err := w.ValidateUpdate(context.Background(), oldCluster, newCluster)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, apierrors.IsInvalid(err))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateUpdate_UnchangedSpec(t *testing.T) {
+	t.Parallel()
+	// A spec stored before the webhook existed must not block metadata updates
+	oldCluster := newTestCluster()
+	oldCluster.Spec.Workers.Size = "cx99"
+	newCluster := oldCluster.DeepCopy()
+	newCluster.Finalizers = []string{k8znerv1alpha1.ClusterFinalizer}
+
+	_, err := (&ClusterWebhook{}).ValidateUpdate(context.Background(), oldCluster, newCluster)
+	require.NoError(t, err)
+}
+
+func TestValidateUpdate_Deleting(t *testing.T) {
+	t.Parallel()
+	oldCluster := newTestCluster()
+	newCluster := oldCluster.DeepCopy()
+	now := metav1.Now()
+	newCluster.DeletionTimestamp = &now
+	newCluster.Spec.Region = "nbg1"
+
+	_, err := (&ClusterWebhook{}).ValidateUpdate(context.Background(), oldCluster, newCluster)
+	require.NoError(t, err)
+}
@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+)
+
+// Defaults applied by the mutating webhook. They match the kubebuilder defaults
+// of the CRD and the values the operator falls back to for empty fields.
+const (
+	defaultServerSize                    = config.SizeCX23
+	defaultUpgradeBatchSize              = 1
+	defaultConfigApplyMode               = "auto"
+	defaultScaleDownUtilizationThreshold = 50
+	defaultScaleDownDelay                = "10m"
+	defaultMaintenanceTimezone           = "UTC"
+	defaultBackupSchedule                = "0 * * * *"
+	defaultBackupRetention               = "168h"
+	defaultBackupKeepLast                = 3
+	defaultBackupVerifySchedule          = "0 4 * * *"
+	defaultNodeNotReadyThreshold         = "3m"
+	defaultEtcdUnhealthyThreshold        = "2m"
+)
+
+// applyDefaults fills in empty fields of spec and normalizes server sizes to
+// their current Hetzner names.
+func applyDefaults(spec *k8znerv1alpha1.K8znerClusterSpec) {
+	if spec.DeletionPolicy == "" {
+		spec.DeletionPolicy = k8znerv1alpha1.DeletionPolicyRetain
+	}
+
+	spec.ControlPlanes.Size = defaultSize(spec.ControlPlanes.Size)
+	spec.Workers.Size = defaultSize(spec.Workers.Size)
+	for i := range spec.WorkerPools {
+		pool := &spec.WorkerPools[i]
+		pool.Size = defaultSize(pool.Size)
+		if as := pool.Autoscaling; as != nil {
+			if as.ScaleDownUtilizationThreshold == 0 {
+				as.ScaleDownUtilizationThreshold = defaultScaleDownUtilizationThreshold
+			}
+			if as.ScaleDownDelay == "" {
+				as.ScaleDownDelay = defaultScaleDownDelay
+			}
+		}
+	}
+
+	applyNetworkDefaults(&spec.Network)
+
+	if spec.Talos.UpgradeBatchSize == 0 {
+		spec.Talos.UpgradeBatchSize = defaultUpgradeBatchSize
+	}
+	if spec.Talos.ConfigApplyMode == "" {
+		spec.Talos.ConfigApplyMode = defaultConfigApplyMode
+	}
+
+	if m := spec.Maintenance; m != nil && m.Timezone == "" {
+		m.Timezone = defaultMaintenanceTimezone
+	}
+
+	if b := spec.Backup; b != nil {
+		if b.Schedule == "" {
+			b.Schedule = defaultBackupSchedule
+		}
+		if b.Retention == "" {
+			b.Retention = defaultBackupRetention
+		}
+		if b.KeepLast == 0 {
+			b.KeepLast = defaultBackupKeepLast
+		}
+		if v := b.Verification; v != nil && v.Schedule == "" {
+			v.Schedule = defaultBackupVerifySchedule
+		}
+	}
+
+	if hc := spec.HealthCheck; hc != nil {
+		if hc.NodeNotReadyThreshold == "" {
+			hc.NodeNotReadyThreshold = defaultNodeNotReadyThreshold
+		}
+		if hc.EtcdUnhealthyThreshold == "" {
+			hc.EtcdUnhealthyThreshold = defaultEtcdUnhealthyThreshold
+		}
+	}
+}
+
+// applyNetworkDefaults fills in the network ranges the operator uses when they are unset.
+func applyNetworkDefaults(network *k8znerv1alpha1.NetworkSpec) {
+	if network.IPv4CIDR == "" {
+		network.IPv4CIDR = config.NetworkCIDR
+	}
+	if network.NodeIPv4CIDR == "" {
+		network.NodeIPv4CIDR = config.NodeCIDR
+	}
+	if network.PodCIDR == "" {
+		network.PodCIDR = config.PodCIDR
+	}
+	if network.ServiceCIDR == "" {
+		network.ServiceCIDR = config.ServiceCIDR
+	}
+}
+
+// defaultSize returns the normalized server size, or the default size if empty.
+func defaultSize(size string) string {
+	if size == "" {
+		return string(defaultServerSize)
+	}
+	return string(config.ServerSize(size).Normalize())
+}
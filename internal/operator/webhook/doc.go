@@ -0,0 +1,12 @@
+// Package webhook implements the admission webhooks for K8znerCluster resources.
+//
+// The mutating webhook fills in the same defaults the operator and the CLI fall
+// back to, so the stored spec shows what is actually deployed. The validating
+// webhook reuses the validators in internal/config to reject invalid server
+// sizes, overlapping network ranges and unsupported Talos and Kubernetes
+// version pairs, and rejects spec changes the operator cannot carry out safely,
+// such as changing network ranges after creation or removing control planes
+// while etcd lacks quorum.
+//
+// The webhooks are served by the operator when it runs with --enable-webhooks.
+package webhook
@@ -0,0 +1,275 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/util/cron"
+	"github.com/milankappen/k8zner/internal/util/k8sversion"
+)
+
+// validControlPlaneCounts are the control plane counts that keep an odd number of etcd members.
+var validControlPlaneCounts = []int{1, 3, 5}
+
+// validateSpec checks a defaulted spec on its own. Version pairs outside the
+// pinned version matrix are allowed but returned as warnings.
+func validateSpec(spec *k8znerv1alpha1.K8znerClusterSpec) ([]string, field.ErrorList) {
+	root := field.NewPath("spec")
+	var errs field.ErrorList
+
+	if !config.Region(spec.Region).IsValid() {
+		errs = append(errs, field.NotSupported(root.Child("region"), spec.Region, config.ValidRegions()))
+	}
+
+	cpPath := root.Child("controlPlanes")
+	if !isValidControlPlaneCount(spec.ControlPlanes.Count) {
+		errs = append(errs, field.Invalid(cpPath.Child("count"), spec.ControlPlanes.Count,
+			fmt.Sprintf("must be one of %v so etcd keeps an odd number of members", validControlPlaneCounts)))
+	}
+	errs = append(errs, validateServerSize(cpPath.Child("size"), spec.ControlPlanes.Size)...)
+
+	if len(spec.WorkerPools) == 0 {
+		errs = append(errs, validateServerSize(root.Child("workers", "size"), spec.Workers.Size)...)
+	}
+	for i := range spec.WorkerPools {
+		errs = append(errs, validateWorkerPool(root.Child("workerPools").Index(i), &spec.WorkerPools[i])...)
+	}
+
+	networkErrs := validateNetwork(root.Child("network"), &spec.Network)
+	errs = append(errs, networkErrs...)
+
+	warnings, versionErrs := validateVersions(root, spec)
+	errs = append(errs, versionErrs...)
+
+	// Pods outside the private network are not routable by Cilium's native routing
+	if len(networkErrs) == 0 {
+		if inside, _ := config.CIDRContains(spec.Network.IPv4CIDR, spec.Network.PodCIDR); !inside {
+			warnings = append(warnings, fmt.Sprintf("spec.network.podCIDR %s is outside spec.network.ipv4CIDR %s; Cilium native routing needs pod addresses within the private network",
+				spec.Network.PodCIDR, spec.Network.IPv4CIDR))
+		}
+	}
+
+	if spec.Maintenance != nil {
+		errs = append(errs, validateMaintenance(root.Child("maintenance"), spec.Maintenance)...)
+	}
+	if spec.Backup != nil {
+		errs = append(errs, validateBackup(root.Child("backup"), spec.Backup)...)
+	}
+	if hc := spec.HealthCheck; hc != nil {
+		hcPath := root.Child("healthCheck")
+		errs = append(errs, validateDuration(hcPath.Child("nodeNotReadyThreshold"), hc.NodeNotReadyThreshold)...)
+		errs = append(errs, validateDuration(hcPath.Child("etcdUnhealthyThreshold"), hc.EtcdUnhealthyThreshold)...)
+	}
+
+	return warnings, errs
+}
+
+// validateTransition checks the changes from a defaulted old spec to a defaulted
+// new spec against what the operator can carry out on a running cluster.
+func validateTransition(oldCluster, newCluster *k8znerv1alpha1.K8znerCluster) field.ErrorList {
+	root := field.NewPath("spec")
+	oldSpec, newSpec := &oldCluster.Spec, &newCluster.Spec
+	var errs field.ErrorList
+
+	// Servers, network and load balancers are tied to the region they were created in
+	errs = append(errs, apivalidation.ValidateImmutableField(newSpec.Region, oldSpec.Region, root.Child("region"))...)
+
+	// Nodes keep their addresses and Cilium its routing, so the ranges cannot move
+	netPath := root.Child("network")
+	errs = append(errs, apivalidation.ValidateImmutableField(newSpec.Network.IPv4CIDR, oldSpec.Network.IPv4CIDR, netPath.Child("ipv4CIDR"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(newSpec.Network.NodeIPv4CIDR, oldSpec.Network.NodeIPv4CIDR, netPath.Child("nodeIPv4CIDR"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(newSpec.Network.PodCIDR, oldSpec.Network.PodCIDR, netPath.Child("podCIDR"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(newSpec.Network.ServiceCIDR, oldSpec.Network.ServiceCIDR, netPath.Child("serviceCIDR"))...)
+
+	errs = append(errs, validateKubernetesUpgrade(root.Child("kubernetes", "version"),
+		oldSpec.Kubernetes.Version, newSpec.Kubernetes.Version)...)
+
+	if newSpec.ControlPlanes.Count < oldSpec.ControlPlanes.Count {
+		errs = append(errs, validateControlPlaneScaleDown(root.Child("controlPlanes", "count"), oldCluster, newSpec.ControlPlanes.Count)...)
+	}
+
+	return errs
+}
+
+// validateServerSize checks a server size against the Hetzner server types k8zner supports.
+func validateServerSize(path *field.Path, size string) field.ErrorList {
+	if config.ServerSize(size).IsValid() {
+		return nil
+	}
+	return field.ErrorList{field.NotSupported(path, size, config.ValidServerSizes())}
+}
+
+// validateWorkerPool checks a worker pool's size and autoscaling bounds.
+func validateWorkerPool(path *field.Path, pool *k8znerv1alpha1.WorkerPoolSpec) field.ErrorList {
+	errs := validateServerSize(path.Child("size"), pool.Size)
+	if pool.Location != "" && !config.Region(pool.Location).IsValid() {
+		errs = append(errs, field.NotSupported(path.Child("location"), pool.Location, config.ValidRegions()))
+	}
+
+	as := pool.Autoscaling
+	if as == nil {
+		return errs
+	}
+	asPath := path.Child("autoscaling")
+	if as.MinCount > as.MaxCount {
+		errs = append(errs, field.Invalid(asPath.Child("minCount"), as.MinCount, "must not exceed maxCount"))
+	} else if pool.Count < as.MinCount || pool.Count > as.MaxCount {
+		errs = append(errs, field.Invalid(path.Child("count"), pool.Count,
+			fmt.Sprintf("must be between autoscaling.minCount (%d) and maxCount (%d)", as.MinCount, as.MaxCount)))
+	}
+	errs = append(errs, validateDuration(asPath.Child("scaleDownDelay"), as.ScaleDownDelay)...)
+
+	return errs
+}
+
+// validateNetwork checks that the network ranges are valid IPv4 CIDRs, that
+// nodes live inside the private network and that nodes, pods and services do
+// not share addresses.
+func validateNetwork(path *field.Path, network *k8znerv1alpha1.NetworkSpec) field.ErrorList {
+	ranges := []struct {
+		name string
+		cidr string
+	}{
+		{"ipv4CIDR", network.IPv4CIDR},
+		{"nodeIPv4CIDR", network.NodeIPv4CIDR},
+		{"podCIDR", network.PodCIDR},
+		{"serviceCIDR", network.ServiceCIDR},
+	}
+
+	var errs field.ErrorList
+	for _, r := range ranges {
+		if err := config.ValidateCIDR(r.cidr); err != nil {
+			errs = append(errs, field.Invalid(path.Child(r.name), r.cidr, err.Error()))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if inside, _ := config.CIDRContains(network.IPv4CIDR, network.NodeIPv4CIDR); !inside {
+		errs = append(errs, field.Invalid(path.Child("nodeIPv4CIDR"), network.NodeIPv4CIDR,
+			fmt.Sprintf("must be within ipv4CIDR %s", network.IPv4CIDR)))
+	}
+	for i, a := range ranges[1:] {
+		for _, b := range ranges[i+2:] {
+			if overlaps, _ := config.CIDROverlaps(a.cidr, b.cidr); overlaps {
+				errs = append(errs, field.Invalid(path.Child(b.name), b.cidr,
+					fmt.Sprintf("must not overlap %s %s", a.name, a.cidr)))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateVersions rejects Talos and Kubernetes versions that do not work
+// together and warns about pairs the pinned version matrix was not tested with.
+func validateVersions(root *field.Path, spec *k8znerv1alpha1.K8znerClusterSpec) ([]string, field.ErrorList) {
+	talos, kubernetes := spec.Talos.Version, spec.Kubernetes.Version
+	if err := config.CheckVersionCompatibility(talos, kubernetes); err != nil {
+		return nil, field.ErrorList{field.Invalid(root.Child("kubernetes", "version"), kubernetes, err.Error())}
+	}
+
+	matrix := config.DefaultVersionMatrix()
+	if !matrix.IsTested(talos, kubernetes) {
+		return []string{fmt.Sprintf("Talos %s with Kubernetes %s has not been tested with this k8zner release (tested: Talos %s, Kubernetes %s)",
+			talos, kubernetes, matrix.Talos, matrix.Kubernetes)}, nil
+	}
+	return nil, nil
+}
+
+// validateMaintenance checks the maintenance windows with the CLI's validator.
+func validateMaintenance(path *field.Path, m *k8znerv1alpha1.MaintenanceSpec) field.ErrorList {
+	spec := config.MaintenanceSpec{
+		Timezone:              m.Timezone,
+		AllowEmergencyHealing: m.AllowEmergencyHealing,
+	}
+	for _, w := range m.Windows {
+		spec.Windows = append(spec.Windows, config.MaintenanceWindow{Schedule: w.Schedule, Duration: w.Duration})
+	}
+
+	var errs field.ErrorList
+	for _, err := range spec.Validate() {
+		errs = append(errs, field.Invalid(path, field.OmitValueType{}, err.Error()))
+	}
+	return errs
+}
+
+// validateBackup checks the backup schedule, retention and verification.
+func validateBackup(path *field.Path, b *k8znerv1alpha1.BackupSpec) field.ErrorList {
+	var errs field.ErrorList
+	if _, err := cron.Parse(b.Schedule); err != nil {
+		errs = append(errs, field.Invalid(path.Child("schedule"), b.Schedule, err.Error()))
+	}
+	errs = append(errs, validateDuration(path.Child("retention"), b.Retention)...)
+	if b.KeepLast < 1 {
+		errs = append(errs, field.Invalid(path.Child("keepLast"), b.KeepLast, "must keep at least one snapshot"))
+	}
+
+	if v := b.Verification; v != nil && v.Enabled {
+		vPath := path.Child("verification")
+		if _, err := cron.Parse(v.Schedule); err != nil {
+			errs = append(errs, field.Invalid(vPath.Child("schedule"), v.Schedule, err.Error()))
+		}
+		if b.S3SecretRef == nil || b.S3SecretRef.Name == "" {
+			errs = append(errs, field.Required(path.Child("s3SecretRef"), "verification reads the snapshots from the backup bucket"))
+		}
+	}
+	return errs
+}
+
+// validateDuration checks that value is a positive Go duration such as "10m".
+func validateDuration(path *field.Path, value string) field.ErrorList {
+	if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+		return field.ErrorList{field.Invalid(path, value, `must be a positive duration such as "10m"`)}
+	}
+	return nil
+}
+
+// validateKubernetesUpgrade rejects Kubernetes downgrades and upgrades that skip a minor version.
+func validateKubernetesUpgrade(path *field.Path, oldVersion, newVersion string) field.ErrorList {
+	if oldVersion == newVersion {
+		return nil
+	}
+	if err := k8sversion.CheckUpgrade(oldVersion, newVersion); err != nil {
+		if errors.Is(err, k8sversion.ErrInvalidVersion) {
+			return field.ErrorList{field.Invalid(path, newVersion, err.Error())}
+		}
+		return field.ErrorList{field.Forbidden(path, err.Error())}
+	}
+	return nil
+}
+
+// validateControlPlaneScaleDown rejects removing control planes while etcd lacks
+// quorum, since etcd cannot accept the membership change. Before the first
+// health check the status has no nodes and the scale-down is left to the operator.
+func validateControlPlaneScaleDown(path *field.Path, cluster *k8znerv1alpha1.K8znerCluster, desired int) field.ErrorList {
+	status := cluster.Status.ControlPlanes
+	if len(status.Nodes) == 0 {
+		return nil
+	}
+
+	quorum := len(status.Nodes)/2 + 1
+	if status.Ready < quorum {
+		return field.ErrorList{field.Forbidden(path,
+			fmt.Sprintf("cannot scale control planes down to %d: only %d/%d are ready, etcd needs %d for quorum",
+				desired, status.Ready, len(status.Nodes), quorum))}
+	}
+	return nil
+}
+
+// isValidControlPlaneCount reports whether count is a supported control plane count.
+func isValidControlPlaneCount(count int) bool {
+	for _, valid := range validControlPlaneCounts {
+		if count == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	k8znerv1alpha1 "github.com/milankappen/k8zner/api/v1alpha1"
+)
+
+// errorFields returns the field paths of errs.
+func errorFields(errs field.ErrorList) []string {
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	return fields
+}
+
+func TestValidateNetwork(t *testing.T) {
+	t.Parallel()
+	path := field.NewPath("spec", "network")
+
+	tests := []struct {
+		name       string
+		mutate     func(*k8znerv1alpha1.NetworkSpec)
+		wantFields []string
+	}{
+		{
+			name:   "defaults",
+			mutate: func(*k8znerv1alpha1.NetworkSpec) {},
+		},
+		{
+			name:       "invalid CIDR",
+			mutate:     func(n *k8znerv1alpha1.NetworkSpec) { n.PodCIDR = "10.0.128.0" },
+			wantFields: []string{"spec.network.podCIDR"},
+		},
+		{
+			name:       "IPv6 CIDR",
+			mutate:     func(n *k8znerv1alpha1.NetworkSpec) { n.ServiceCIDR = "fd00::/108" },
+			wantFields: []string{"spec.network.serviceCIDR"},
+		},
+		{
+			name:       "nodes outside the network",
+			mutate:     func(n *k8znerv1alpha1.NetworkSpec) { n.NodeIPv4CIDR = "10.1.0.0/17" },
+			wantFields: []string{"spec.network.nodeIPv4CIDR"},
+		},
+		{
+			name:       "pods overlap nodes",
+			mutate:     func(n *k8znerv1alpha1.NetworkSpec) { n.PodCIDR = "10.0.64.0/18" },
+			wantFields: []string{"spec.network.podCIDR"},
+		},
+		{
+			name:       "services overlap pods",
+			mutate:     func(n *k8znerv1alpha1.NetworkSpec) { n.ServiceCIDR = "10.0.192.0/18" },
+			wantFields: []string{"spec.network.serviceCIDR"},
+		},
+		{
+			name: "custom non-overlapping ranges",
+			mutate: func(n *k8znerv1alpha1.NetworkSpec) {
+				n.PodCIDR = "10.244.0.0/16"
+				n.ServiceCIDR = "10.96.0.0/16"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			network := k8znerv1alpha1.NetworkSpec{}
+			applyNetworkDefaults(&network)
+			tt.mutate(&network)
+
+			errs := validateNetwork(path, &network)
+			assert.ElementsMatch(t, tt.wantFields, errorFields(errs))
+		})
+	}
+}
+
+func TestValidateWorkerPool(t *testing.T) {
+	t.Parallel()
+	path := field.NewPath("spec", "workerPools").Index(0)
+
+	tests := []struct {
+		name       string
+		pool       k8znerv1alpha1.WorkerPoolSpec
+		wantFields []string
+	}{
+		{
+			name: "valid",
+			pool: k8znerv1alpha1.WorkerPoolSpec{Name: "default", Count: 2, Size: "cpx32"},
+		},
+		{
+			name:       "unknown size",
+			pool:       k8znerv1alpha1.WorkerPoolSpec{Name: "default", Count: 2, Size: "ccx13"},
+			wantFields: []string{"spec.workerPools[0].size"},
+		},
+		{
+			name: "min above max",
+			pool: k8znerv1alpha1.WorkerPoolSpec{Name: "batch", Count: 2, Size: "cx23",
+				Autoscaling: &k8znerv1alpha1.WorkerPoolAutoscalingSpec{MinCount: 4, MaxCount: 3, ScaleDownDelay: "10m"}},
+			wantFields: []string{"spec.workerPools[0].autoscaling.minCount"},
+		},
+		{
+			name: "count outside bounds",
+			pool: k8znerv1alpha1.WorkerPoolSpec{Name: "batch", Count: 5, Size: "cx23",
+				Autoscaling: &k8znerv1alpha1.WorkerPoolAutoscalingSpec{MinCount: 1, MaxCount: 3, ScaleDownDelay: "10m"}},
+			wantFields: []string{"spec.workerPools[0].count"},
+		},
+		{
+			name: "invalid scale-down delay",
+			pool: k8znerv1alpha1.WorkerPoolSpec{Name: "batch", Count: 1, Size: "cx23",
+				Autoscaling: &k8znerv1alpha1.WorkerPoolAutoscalingSpec{MinCount: 1, MaxCount: 3, ScaleDownDelay: "ten minutes"}},
+			wantFields: []string{"spec.workerPools[0].autoscaling.scaleDownDelay"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			errs := validateWorkerPool(path, &tt.pool)
+			assert.ElementsMatch(t, tt.wantFields, errorFields(errs))
+		})
+	}
+}
+
+func TestValidateVersions(t *testing.T) {
+	t.Parallel()
+	root := field.NewPath("spec")
+
+	tests := []struct {
+		name         string
+		talos        string
+		kubernetes   string
+		wantErr      bool
+		wantWarnings int
+	}{
+		{"pinned", "v1.9.0", "1.32.0", false, 0},
+		{"patch release", "v1.9.3", "1.32.2", false, 0},
+		{"supported but untested", "v1.10.2", "1.33.1", false, 1},
+		{"unsupported", "v1.9.0", "1.34.0", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			spec := &k8znerv1alpha1.K8znerClusterSpec{
+				Talos:      k8znerv1alpha1.TalosSpec{Version: tt.talos},
+				Kubernetes: k8znerv1alpha1.KubernetesSpec{Version: tt.kubernetes},
+			}
+			warnings, errs := validateVersions(root, spec)
+			assert.Equal(t, tt.wantErr, len(errs) > 0, "errors: %v", errs)
+			assert.Len(t, warnings, tt.wantWarnings)
+		})
+	}
+}
+
+func TestValidateSpec_MaintenanceAndBackup(t *testing.T) {
+	t.Parallel()
+	cluster := defaulted(newTestCluster())
+	cluster.Spec.Maintenance = &k8znerv1alpha1.MaintenanceSpec{
+		Windows:  []k8znerv1alpha1.MaintenanceWindow{{Schedule: "0 2 * * someday", Duration: "4h"}},
+		Timezone: "Mars/Olympus_Mons",
+	}
+	cluster.Spec.Backup = &k8znerv1alpha1.BackupSpec{Enabled: true, Schedule: "hourly", Retention: "7d", KeepLast: -1}
+
+	_, errs := validateSpec(&cluster.Spec)
+	assert.ElementsMatch(t, []string{
+		"spec.maintenance",
+		"spec.maintenance",
+		"spec.backup.schedule",
+		"spec.backup.retention",
+		"spec.backup.keepLast",
+	}, errorFields(errs))
+}
+
+func TestValidateSpec_BackupVerification(t *testing.T) {
+	t.Parallel()
+	cluster := defaulted(newTestCluster())
+	cluster.Spec.Backup = &k8znerv1alpha1.BackupSpec{
+		Enabled:      true,
+		Schedule:     "0 * * * *",
+		Retention:    "168h",
+		KeepLast:     3,
+		Verification: &k8znerv1alpha1.BackupVerificationSpec{Enabled: true, Schedule: "nightly"},
+	}
+
+	_, errs := validateSpec(&cluster.Spec)
+	assert.ElementsMatch(t, []string{
+		"spec.backup.verification.schedule",
+		"spec.backup.s3SecretRef",
+	}, errorFields(errs))
+
+	cluster.Spec.Backup.S3SecretRef = &k8znerv1alpha1.SecretReference{Name: "backup-s3"}
+	cluster.Spec.Backup.Verification.Schedule = "0 4 * * *"
+	_, errs = validateSpec(&cluster.Spec)
+	assert.Empty(t, errs)
+}
+
+func TestValidateSpec_PodCIDROutsideNetworkWarns(t *testing.T) {
+	t.Parallel()
+	cluster := newTestCluster()
+	cluster.Spec.Network.PodCIDR = "10.244.0.0/16"
+	cluster = defaulted(cluster)
+
+	warnings, errs := validateSpec(&cluster.Spec)
+	assert.Empty(t, errs)
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "spec.network.podCIDR")
+}
+
+func TestValidateKubernetesUpgrade(t *testing.T) {
+	t.Parallel()
+	path := field.NewPath("spec", "kubernetes", "version")
+
+	tests := []struct {
+		from, to string
+		wantErr  bool
+	}{
+		{"1.32.0", "1.32.0", false},
+		{"1.32.0", "1.32.3", false},
+		{"1.32.0", "1.33.0", false},
+		{"1.32.0", "1.34.0", true},
+		{"1.32.3", "1.31.5", true},
+		{"1.32.3", "1.32.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			t.Parallel()
+			errs := validateKubernetesUpgrade(path, tt.from, tt.to)
+			assert.Equal(t, tt.wantErr, len(errs) > 0, "errors: %v", errs)
+		})
+	}
+}
+
+func TestValidateControlPlaneScaleDown_BeforeFirstHealthCheck(t *testing.T) {
+	t.Parallel()
+	// Without node status the operator decides once it has checked etcd
+	cluster := newTestCluster()
+	errs := validateControlPlaneScaleDown(field.NewPath("spec", "controlPlanes", "count"), cluster, 1)
+	assert.Empty(t, errs)
+}
@@ -37,6 +37,7 @@ type InfrastructureManager interface {
 	GetServerID(ctx context.Context, name string) (string, error)
 	GetServerByName(ctx context.Context, name string) (*hcloud.Server, error)
 	AttachServerToNetwork(ctx context.Context, serverName string, networkID int64, privateIP string) error
+	UpdateServerLabels(ctx context.Context, serverID int64, labels map[string]string) error
 
 	// Snapshot operations
 	CreateSnapshot(ctx context.Context, serverID, snapshotDescription string, labels map[string]string) (string, error)
@@ -53,11 +54,13 @@ type InfrastructureManager interface {
 	EnsureSubnet(ctx context.Context, network *hcloud.Network, ipRange, networkZone string, subnetType hcloud.NetworkSubnetType) error
 	DeleteNetwork(ctx context.Context, name string) error
 	GetNetwork(ctx context.Context, name string) (*hcloud.Network, error)
+	UpdateNetwork(ctx context.Context, id int64, name string, labels map[string]string) (*hcloud.Network, error)
 
 	// Firewall operations
 	EnsureFirewall(ctx context.Context, name string, rules []hcloud.FirewallRule, labels map[string]string, applyToLabelSelector string) (*hcloud.Firewall, error)
 	DeleteFirewall(ctx context.Context, name string) error
 	GetFirewall(ctx context.Context, name string) (*hcloud.Firewall, error)
+	UpdateFirewall(ctx context.Context, id int64, name string, labels map[string]string, applyToLabelSelector string) (*hcloud.Firewall, error)
 
 	// Load balancer operations
 	EnsureLoadBalancer(ctx context.Context, name, location, lbType string, algorithm hcloud.LoadBalancerAlgorithmType, labels map[string]string) (*hcloud.LoadBalancer, error)
@@ -66,6 +69,7 @@ type InfrastructureManager interface {
 	AttachToNetwork(ctx context.Context, lb *hcloud.LoadBalancer, network *hcloud.Network, ip net.IP) error
 	DeleteLoadBalancer(ctx context.Context, name string) error
 	GetLoadBalancer(ctx context.Context, name string) (*hcloud.LoadBalancer, error)
+	UpdateLoadBalancer(ctx context.Context, id int64, name string, labels map[string]string) (*hcloud.LoadBalancer, error)
 
 	// Placement group operations
 	EnsurePlacementGroup(ctx context.Context, name, pgType string, labels map[string]string) (*hcloud.PlacementGroup, error)
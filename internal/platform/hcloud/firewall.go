@@ -116,3 +116,18 @@ func (c *RealClient) GetFirewall(ctx context.Context, name string) (*hcloud.Fire
 	fw, _, err := c.client.Firewall.Get(ctx, name)
 	return fw, err
 }
+
+// UpdateFirewall renames the firewall with the given ID, replaces its labels and
+// applies it to the label selector in addition to the resources it already covers.
+func (c *RealClient) UpdateFirewall(ctx context.Context, id int64, name string, labels map[string]string, applyToLabelSelector string) (*hcloud.Firewall, error) {
+	fw, _, err := c.client.Firewall.Update(ctx, &hcloud.Firewall{ID: id}, hcloud.FirewallUpdateOpts{Name: name, Labels: labels})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update firewall %d: %w", id, err)
+	}
+	if applyToLabelSelector != "" {
+		if err := c.ensureFirewallAppliedTo(ctx, fw, applyToLabelSelector); err != nil {
+			return nil, fmt.Errorf("failed to apply firewall to resources: %w", err)
+		}
+	}
+	return fw, nil
+}
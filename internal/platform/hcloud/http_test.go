@@ -2706,3 +2706,74 @@ func TestRealClient_AttachServerToNetwork_PowerOnError(t *testing.T) {
 		t.Fatal("expected error for power on failure")
 	}
 }
+
+func TestRealClient_UpdateServerLabels_WithHTTPMock(t *testing.T) {
+	ts := newTestServer()
+	defer ts.close()
+
+	var got schema.ServerUpdateRequest
+	ts.handleFunc("/servers/130", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		jsonResponse(w, http.StatusOK, schema.ServerUpdateResponse{
+			Server: schema.Server{ID: 130, Name: "worker-1", Labels: *got.Labels},
+		})
+	})
+
+	client := ts.realClient()
+	labels := map[string]string{"cluster": "prod", "role": "worker"}
+	if err := client.UpdateServerLabels(context.Background(), 130, labels); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Labels == nil || (*got.Labels)["cluster"] != "prod" {
+		t.Errorf("expected labels to be sent, got %v", got.Labels)
+	}
+	if got.Name != "" {
+		t.Errorf("expected the server name to be left alone, got %q", got.Name)
+	}
+}
+
+func TestRealClient_UpdateFirewall_WithHTTPMock(t *testing.T) {
+	ts := newTestServer()
+	defer ts.close()
+
+	var got schema.FirewallUpdateRequest
+	ts.handleFunc("/firewalls/301", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		jsonResponse(w, http.StatusOK, schema.FirewallUpdateResponse{
+			Firewall: schema.Firewall{ID: 301, Name: *got.Name, AppliedTo: []schema.FirewallResource{}},
+		})
+	})
+
+	applied := false
+	ts.handleFunc("/firewalls/301/actions/apply_to_resources", func(w http.ResponseWriter, _ *http.Request) {
+		applied = true
+		jsonResponse(w, http.StatusCreated, schema.FirewallActionApplyToResourcesResponse{
+			Actions: []schema.Action{{ID: 5, Status: "success"}},
+		})
+	})
+	ts.handleFunc("/actions/5", func(w http.ResponseWriter, _ *http.Request) {
+		jsonResponse(w, http.StatusOK, schema.ActionGetResponse{
+			Action: schema.Action{ID: 5, Status: "success", Progress: 100},
+		})
+	})
+
+	client := ts.realClient()
+	fw, err := client.UpdateFirewall(context.Background(), 301, "prod", map[string]string{"cluster": "prod"}, "cluster=prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fw.Name != "prod" {
+		t.Errorf("expected firewall to be renamed to prod, got %q", fw.Name)
+	}
+	if !applied {
+		t.Error("expected the firewall to be applied to the label selector")
+	}
+}
@@ -159,3 +159,12 @@ func (c *RealClient) GetLoadBalancer(ctx context.Context, name string) (*hcloud.
 	lb, _, err := c.client.LoadBalancer.Get(ctx, name)
 	return lb, err
 }
+
+// UpdateLoadBalancer renames the load balancer with the given ID and replaces its labels.
+func (c *RealClient) UpdateLoadBalancer(ctx context.Context, id int64, name string, labels map[string]string) (*hcloud.LoadBalancer, error) {
+	lb, _, err := c.client.LoadBalancer.Update(ctx, &hcloud.LoadBalancer{ID: id}, hcloud.LoadBalancerUpdateOpts{Name: name, Labels: labels})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update load balancer %d: %w", id, err)
+	}
+	return lb, nil
+}
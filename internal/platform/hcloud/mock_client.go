@@ -19,6 +19,7 @@ type MockClient struct {
 	ResetServerFunc           func(ctx context.Context, serverID string) error
 	PoweroffServerFunc        func(ctx context.Context, serverID string) error
 	AttachServerToNetworkFunc func(ctx context.Context, serverName string, networkID int64, privateIP string) error
+	UpdateServerLabelsFunc    func(ctx context.Context, serverID int64, labels map[string]string) error
 
 	CreateSnapshotFunc      func(ctx context.Context, serverID, snapshotDescription string, labels map[string]string) (string, error)
 	DeleteImageFunc         func(ctx context.Context, imageID string) error
@@ -32,11 +33,13 @@ type MockClient struct {
 	EnsureSubnetFunc  func(ctx context.Context, network *hcloud.Network, ipRange, networkZone string, subnetType hcloud.NetworkSubnetType) error
 	DeleteNetworkFunc func(ctx context.Context, name string) error
 	GetNetworkFunc    func(ctx context.Context, name string) (*hcloud.Network, error)
+	UpdateNetworkFunc func(ctx context.Context, id int64, name string, labels map[string]string) (*hcloud.Network, error)
 
 	// Firewall
 	EnsureFirewallFunc func(ctx context.Context, name string, rules []hcloud.FirewallRule, labels map[string]string, applyToLabelSelector string) (*hcloud.Firewall, error)
 	DeleteFirewallFunc func(ctx context.Context, name string) error
 	GetFirewallFunc    func(ctx context.Context, name string) (*hcloud.Firewall, error)
+	UpdateFirewallFunc func(ctx context.Context, id int64, name string, labels map[string]string, applyToLabelSelector string) (*hcloud.Firewall, error)
 
 	// LoadBalancer
 	EnsureLoadBalancerFunc func(ctx context.Context, name, location, lbType string, algorithm hcloud.LoadBalancerAlgorithmType, labels map[string]string) (*hcloud.LoadBalancer, error)
@@ -45,6 +48,7 @@ type MockClient struct {
 	AddTargetFunc          func(ctx context.Context, lb *hcloud.LoadBalancer, targetType hcloud.LoadBalancerTargetType, labelSelector string) error
 	DeleteLoadBalancerFunc func(ctx context.Context, name string) error
 	GetLoadBalancerFunc    func(ctx context.Context, name string) (*hcloud.LoadBalancer, error)
+	UpdateLoadBalancerFunc func(ctx context.Context, id int64, name string, labels map[string]string) (*hcloud.LoadBalancer, error)
 
 	// PlacementGroup
 	EnsurePlacementGroupFunc func(ctx context.Context, name, pgType string, labels map[string]string) (*hcloud.PlacementGroup, error)
@@ -146,6 +150,14 @@ func (m *MockClient) AttachServerToNetwork(ctx context.Context, serverName strin
 	return nil
 }
 
+// UpdateServerLabels mocks replacing server labels.
+func (m *MockClient) UpdateServerLabels(ctx context.Context, serverID int64, labels map[string]string) error {
+	if m.UpdateServerLabelsFunc != nil {
+		return m.UpdateServerLabelsFunc(ctx, serverID, labels)
+	}
+	return nil
+}
+
 // CreateSnapshot mocks snapshot creation.
 func (m *MockClient) CreateSnapshot(ctx context.Context, serverID, snapshotDescription string, labels map[string]string) (string, error) {
 	if m.CreateSnapshotFunc != nil {
@@ -218,6 +230,14 @@ func (m *MockClient) GetNetwork(ctx context.Context, name string) (*hcloud.Netwo
 	return nil, nil
 }
 
+// UpdateNetwork mocks renaming and relabeling a network.
+func (m *MockClient) UpdateNetwork(ctx context.Context, id int64, name string, labels map[string]string) (*hcloud.Network, error) {
+	if m.UpdateNetworkFunc != nil {
+		return m.UpdateNetworkFunc(ctx, id, name, labels)
+	}
+	return &hcloud.Network{ID: id, Name: name, Labels: labels}, nil
+}
+
 // EnsureFirewall mocks firewall creation.
 func (m *MockClient) EnsureFirewall(ctx context.Context, name string, rules []hcloud.FirewallRule, labels map[string]string, applyToLabelSelector string) (*hcloud.Firewall, error) {
 	if m.EnsureFirewallFunc != nil {
@@ -242,6 +262,14 @@ func (m *MockClient) GetFirewall(ctx context.Context, name string) (*hcloud.Fire
 	return nil, nil
 }
 
+// UpdateFirewall mocks renaming and relabeling a firewall.
+func (m *MockClient) UpdateFirewall(ctx context.Context, id int64, name string, labels map[string]string, applyToLabelSelector string) (*hcloud.Firewall, error) {
+	if m.UpdateFirewallFunc != nil {
+		return m.UpdateFirewallFunc(ctx, id, name, labels, applyToLabelSelector)
+	}
+	return &hcloud.Firewall{ID: id, Name: name, Labels: labels}, nil
+}
+
 // EnsureLoadBalancer mocks load balancer creation.
 func (m *MockClient) EnsureLoadBalancer(ctx context.Context, name, location, lbType string, algorithm hcloud.LoadBalancerAlgorithmType, labels map[string]string) (*hcloud.LoadBalancer, error) {
 	if m.EnsureLoadBalancerFunc != nil {
@@ -290,6 +318,14 @@ func (m *MockClient) GetLoadBalancer(ctx context.Context, name string) (*hcloud.
 	return nil, nil
 }
 
+// UpdateLoadBalancer mocks renaming and relabeling a load balancer.
+func (m *MockClient) UpdateLoadBalancer(ctx context.Context, id int64, name string, labels map[string]string) (*hcloud.LoadBalancer, error) {
+	if m.UpdateLoadBalancerFunc != nil {
+		return m.UpdateLoadBalancerFunc(ctx, id, name, labels)
+	}
+	return &hcloud.LoadBalancer{ID: id, Name: name, Labels: labels}, nil
+}
+
 // EnsurePlacementGroup mocks placement group creation.
 func (m *MockClient) EnsurePlacementGroup(ctx context.Context, name, pgType string, labels map[string]string) (*hcloud.PlacementGroup, error) {
 	if m.EnsurePlacementGroupFunc != nil {
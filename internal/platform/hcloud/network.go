@@ -83,3 +83,12 @@ func (c *RealClient) GetNetwork(ctx context.Context, name string) (*hcloud.Netwo
 	network, _, err := c.client.Network.Get(ctx, name)
 	return network, err
 }
+
+// UpdateNetwork renames the network with the given ID and replaces its labels.
+func (c *RealClient) UpdateNetwork(ctx context.Context, id int64, name string, labels map[string]string) (*hcloud.Network, error) {
+	network, _, err := c.client.Network.Update(ctx, &hcloud.Network{ID: id}, hcloud.NetworkUpdateOpts{Name: name, Labels: labels})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update network %d: %w", id, err)
+	}
+	return network, nil
+}
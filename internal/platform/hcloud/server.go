@@ -267,6 +267,14 @@ func (c *RealClient) GetServersByLabel(ctx context.Context, labels map[string]st
 	return servers, nil
 }
 
+// UpdateServerLabels replaces the labels of the server with the given ID.
+func (c *RealClient) UpdateServerLabels(ctx context.Context, serverID int64, labels map[string]string) error {
+	if _, _, err := c.client.Server.Update(ctx, &hcloud.Server{ID: serverID}, hcloud.ServerUpdateOpts{Labels: labels}); err != nil {
+		return fmt.Errorf("failed to update labels of server %d: %w", serverID, err)
+	}
+	return nil
+}
+
 // AttachServerToNetwork attaches an existing server to a network.
 // If the server is already attached to the network, this is a no-op.
 // The server will be powered on after successful attachment.
@@ -22,6 +22,13 @@ const (
 	MetadataFileName = "k8zner_metadata.json"
 )
 
+// ErrObjectExists is returned by [Client.PutObjectIfAbsent] when the key is taken.
+var ErrObjectExists = errors.New("object already exists")
+
+// ErrObjectChanged is returned by [Client.PutObjectIfMatch] when the object
+// was replaced or removed since it was read.
+var ErrObjectChanged = errors.New("object changed")
+
 // BucketMetadata contains metadata about a k8zner-managed S3 bucket.
 // This file is written to each bucket to verify ownership during cleanup.
 type BucketMetadata struct {
@@ -161,6 +168,11 @@ func isBucketAlreadyOwnedByYou(err error) bool {
 	return false
 }
 
+// IsNotFound reports whether err means that the requested object or bucket does not exist.
+func IsNotFound(err error) bool {
+	return isNotFoundError(err)
+}
+
 // isNotFoundError checks if the error is a not found error.
 func isNotFoundError(err error) bool {
 	if err == nil {
@@ -178,11 +190,16 @@ func isNotFoundError(err error) bool {
 		return true
 	}
 
+	var nsk *types.NoSuchKey
+	if errors.As(err, &nsk) {
+		return true
+	}
+
 	// Fall back to API error code checking for S3-compatible services
 	var apiErr smithy.APIError
 	if errors.As(err, &apiErr) {
 		code := apiErr.ErrorCode()
-		return code == "NotFound" || code == "NoSuchBucket" || code == "404"
+		return code == "NotFound" || code == "NoSuchBucket" || code == "NoSuchKey" || code == "404"
 	}
 
 	return false
@@ -211,6 +228,45 @@ func (c *Client) ListObjects(ctx context.Context, bucketName, prefix string) ([]
 	return keys, nil
 }
 
+// ObjectInfo describes an object in a bucket.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// ListObjectInfo lists objects in a bucket with their size and modification time.
+// Unlike ListObjects it follows pagination, so buckets with more than 1000 objects
+// are listed completely.
+func (c *Client) ListObjectInfo(ctx context.Context, bucketName, prefix string) ([]ObjectInfo, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+	}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+
+	var objects []ObjectInfo
+	paginator := s3.NewListObjectsV2Paginator(c.s3, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects in bucket %s: %w", bucketName, err)
+		}
+		for _, obj := range page.Contents {
+			if obj.Key == nil {
+				continue
+			}
+			objects = append(objects, ObjectInfo{
+				Key:          *obj.Key,
+				Size:         aws.ToInt64(obj.Size),
+				LastModified: aws.ToTime(obj.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
 // PutObject uploads an object to a bucket.
 func (c *Client) PutObject(ctx context.Context, bucketName, key string, data []byte) error {
 	_, err := c.s3.PutObject(ctx, &s3.PutObjectInput{
@@ -225,14 +281,70 @@ func (c *Client) PutObject(ctx context.Context, bucketName, key string, data []b
 	return nil
 }
 
+// PutObjectIfAbsent uploads an object only if key does not exist yet, using a
+// conditional write. It returns [ErrObjectExists] if another writer was first.
+func (c *Client) PutObjectIfAbsent(ctx context.Context, bucketName, key string, data []byte) error {
+	_, err := c.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(bucketName),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+		IfNoneMatch:   aws.String("*"),
+	})
+	if isPreconditionFailed(err) {
+		return fmt.Errorf("failed to put object %s in bucket %s: %w", key, bucketName, ErrObjectExists)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to put object %s in bucket %s: %w", key, bucketName, err)
+	}
+	return nil
+}
+
+// PutObjectIfMatch replaces an object only if it still has etag, as returned
+// by [Client.GetObjectWithETag]. It returns [ErrObjectChanged] if another
+// writer replaced or removed the object in between.
+func (c *Client) PutObjectIfMatch(ctx context.Context, bucketName, key string, data []byte, etag string) error {
+	_, err := c.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(bucketName),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+		IfMatch:       aws.String(etag),
+	})
+	if isPreconditionFailed(err) || isNotFoundError(err) {
+		return fmt.Errorf("failed to put object %s in bucket %s: %w", key, bucketName, ErrObjectChanged)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to put object %s in bucket %s: %w", key, bucketName, err)
+	}
+	return nil
+}
+
+// isPreconditionFailed checks if a conditional write lost against an existing object.
+func isPreconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		return code == "PreconditionFailed" || code == "ConditionalRequestConflict"
+	}
+	return false
+}
+
 // GetObject downloads an object from a bucket.
 func (c *Client) GetObject(ctx context.Context, bucketName, key string) ([]byte, error) {
+	data, _, err := c.GetObjectWithETag(ctx, bucketName, key)
+	return data, err
+}
+
+// GetObjectWithETag downloads an object from a bucket along with its ETag,
+// for a later [Client.PutObjectIfMatch].
+func (c *Client) GetObjectWithETag(ctx context.Context, bucketName, key string) ([]byte, string, error) {
 	result, err := c.s3.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(bucketName),
 		Key:    aws.String(key),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get object %s from bucket %s: %w", key, bucketName, err)
+		return nil, "", fmt.Errorf("failed to get object %s from bucket %s: %w", key, bucketName, err)
 	}
 	defer func() {
 		_ = result.Body.Close()
@@ -240,10 +352,10 @@ func (c *Client) GetObject(ctx context.Context, bucketName, key string) ([]byte,
 
 	var buf bytes.Buffer
 	if _, err := buf.ReadFrom(result.Body); err != nil {
-		return nil, fmt.Errorf("failed to read object body: %w", err)
+		return nil, "", fmt.Errorf("failed to read object body: %w", err)
 	}
 
-	return buf.Bytes(), nil
+	return buf.Bytes(), aws.ToString(result.ETag), nil
 }
 
 // DeleteObject deletes an object from a bucket.
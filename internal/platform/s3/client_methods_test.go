@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
@@ -286,6 +288,134 @@ func TestPutObject_Error(t *testing.T) {
 	}
 }
 
+func TestPutObjectIfAbsent(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-None-Match") != "*" {
+			w.WriteHeader(400)
+			return
+		}
+		if _, ok := objects[r.URL.Path]; ok {
+			xmlResponse(w, 412, `<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>PreconditionFailed</Code>
+  <Message>At least one of the pre-conditions you specified did not hold</Message>
+</Error>`)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		objects[r.URL.Path] = body
+		w.WriteHeader(200)
+	})
+
+	client, server := testClient(t, handler)
+	defer server.Close()
+
+	if err := client.PutObjectIfAbsent(context.Background(), "test-bucket", "lock", []byte("first")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := client.PutObjectIfAbsent(context.Background(), "test-bucket", "lock", []byte("second"))
+	if !errors.Is(err, ErrObjectExists) {
+		t.Fatalf("expected ErrObjectExists, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := string(objects["/test-bucket/lock"]); got != "first" {
+		t.Errorf("expected the first write to win, got %q", got)
+	}
+}
+
+func TestPutObjectIfMatch(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	objects := map[string][]byte{"/test-bucket/lock": []byte("first")}
+	etags := map[string]string{"/test-bucket/lock": `"1"`}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case "GET":
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				xmlResponse(w, 404, `<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>NoSuchKey</Code>
+  <Message>The specified key does not exist.</Message>
+</Error>`)
+				return
+			}
+			w.Header().Set("ETag", etags[r.URL.Path])
+			w.WriteHeader(200)
+			_, _ = w.Write(data)
+		case "PUT":
+			if r.Header.Get("If-Match") != etags[r.URL.Path] {
+				xmlResponse(w, 412, `<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>PreconditionFailed</Code>
+  <Message>At least one of the pre-conditions you specified did not hold</Message>
+</Error>`)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = body
+			etags[r.URL.Path] = fmt.Sprintf(`"%d"`, len(etags)+1)
+			w.WriteHeader(200)
+		}
+	})
+
+	client, server := testClient(t, handler)
+	defer server.Close()
+
+	data, etag, err := client.GetObjectWithETag(context.Background(), "test-bucket", "lock")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "first" || etag != `"1"` {
+		t.Fatalf("expected first with ETag \"1\", got %q with %s", data, etag)
+	}
+
+	if err := client.PutObjectIfMatch(context.Background(), "test-bucket", "lock", []byte("second"), etag); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = client.PutObjectIfMatch(context.Background(), "test-bucket", "lock", []byte("third"), etag)
+	if !errors.Is(err, ErrObjectChanged) {
+		t.Fatalf("expected ErrObjectChanged, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := string(objects["/test-bucket/lock"]); got != "second" {
+		t.Errorf("expected the write with the current ETag to win, got %q", got)
+	}
+}
+
+func TestGetObject_NotFound(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		xmlResponse(w, 404, `<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>NoSuchKey</Code>
+  <Message>The specified key does not exist.</Message>
+</Error>`)
+	})
+
+	client, server := testClient(t, handler)
+	defer server.Close()
+
+	_, err := client.GetObject(context.Background(), "test-bucket", "missing-key")
+	if !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
 func TestGetObject_Success(t *testing.T) {
 	t.Parallel()
 
@@ -568,6 +698,95 @@ func TestListObjects_Error(t *testing.T) {
 	}
 }
 
+func TestListObjectInfo_Paginated(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var tokens []string
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("continuation-token")
+		mu.Lock()
+		tokens = append(tokens, token)
+		mu.Unlock()
+
+		if token == "" {
+			xmlResponse(w, 200, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>test-bucket</Name>
+  <KeyCount>1</KeyCount>
+  <MaxKeys>1</MaxKeys>
+  <IsTruncated>true</IsTruncated>
+  <NextContinuationToken>page-2</NextContinuationToken>
+  <Contents>
+    <Key>etcd-backups/a.snap</Key>
+    <Size>100</Size>
+    <LastModified>2026-01-02T03:00:00.000Z</LastModified>
+  </Contents>
+</ListBucketResult>`)
+			return
+		}
+		xmlResponse(w, 200, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>test-bucket</Name>
+  <KeyCount>1</KeyCount>
+  <MaxKeys>1</MaxKeys>
+  <IsTruncated>false</IsTruncated>
+  <Contents>
+    <Key>etcd-backups/b.snap</Key>
+    <Size>200</Size>
+    <LastModified>2026-01-02T04:00:00.000Z</LastModified>
+  </Contents>
+</ListBucketResult>`)
+	})
+
+	client, server := testClient(t, handler)
+	defer server.Close()
+
+	objects, err := client.ListObjectInfo(context.Background(), "test-bucket", "etcd-backups/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+	if objects[1].Key != "etcd-backups/b.snap" || objects[1].Size != 200 {
+		t.Errorf("unexpected object: %+v", objects[1])
+	}
+	if got := objects[1].LastModified.Format(time.RFC3339); got != "2026-01-02T04:00:00Z" {
+		t.Errorf("expected LastModified 2026-01-02T04:00:00Z, got %s", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(tokens) != 2 || tokens[1] != "page-2" {
+		t.Errorf("expected two requests with continuation token page-2, got %v", tokens)
+	}
+}
+
+func TestListObjectInfo_Error(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		xmlResponse(w, 404, `<?xml version="1.0" encoding="UTF-8"?>
+<Error>
+  <Code>NoSuchBucket</Code>
+  <Message>The specified bucket does not exist</Message>
+</Error>`)
+	})
+
+	client, server := testClient(t, handler)
+	defer server.Close()
+
+	_, err := client.ListObjectInfo(context.Background(), "nonexistent-bucket", "")
+	if err == nil {
+		t.Fatal("expected error but got nil")
+	}
+	if !strings.Contains(err.Error(), "failed to list objects in bucket nonexistent-bucket") {
+		t.Errorf("unexpected error message: %v", err)
+	}
+}
+
 func TestWriteMetadata_Success(t *testing.T) {
 	t.Parallel()
 
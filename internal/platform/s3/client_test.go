@@ -113,6 +113,16 @@ func TestIsNotFoundError(t *testing.T) {
 			err:  &mockAPIError{code: "404", message: "not found"},
 			want: true,
 		},
+		{
+			name: "NoSuchKey typed error",
+			err:  &types.NoSuchKey{},
+			want: true,
+		},
+		{
+			name: "NoSuchKey API error code",
+			err:  &mockAPIError{code: "NoSuchKey", message: "key not found"},
+			want: true,
+		},
 		{
 			name: "other API error code",
 			err:  &mockAPIError{code: "AccessDenied", message: "access denied"},
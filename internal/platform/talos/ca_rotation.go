@@ -0,0 +1,65 @@
+package talos
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+)
+
+// RotateCAs returns a copy of sb with a new Talos API CA and a new Kubernetes
+// CA. All other secrets, including the etcd CA, the cluster ID and the
+// bootstrap tokens, are kept so the nodes stay members of the same cluster.
+func RotateCAs(sb *secrets.Bundle, talosVersion string) (*secrets.Bundle, error) {
+	if sb == nil || sb.Certs == nil {
+		return nil, fmt.Errorf("secrets bundle has no certificates")
+	}
+
+	fresh, err := NewSecrets(talosVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := *sb.Certs
+	certs.OS = fresh.Certs.OS
+	certs.K8s = fresh.Certs.K8s
+
+	rotated := *sb
+	rotated.Certs = &certs
+	return &rotated, nil
+}
+
+// AcceptCAs makes the generator trust the Talos API and Kubernetes CAs of sb
+// next to its own: generated configs list them in machine.acceptedCAs and
+// cluster.acceptedCAs, and the client config trusts servers they issued.
+// A CA rotation uses this for the new CAs before nodes switch to them and for
+// the old CAs afterwards. A nil sb removes the accepted CAs again.
+func (g *Generator) AcceptCAs(sb *secrets.Bundle) {
+	g.acceptedCAs = sb
+}
+
+// addAcceptedCAs adds the accepted CAs to a machine config patch.
+func (g *Generator) addAcceptedCAs(patch map[string]any) {
+	if g.acceptedCAs == nil || g.acceptedCAs.Certs == nil {
+		return
+	}
+
+	if ca := g.acceptedCAs.Certs.OS; ca != nil {
+		patch["machine"].(map[string]any)["acceptedCAs"] = []any{
+			map[string]any{"crt": base64.StdEncoding.EncodeToString(ca.Crt)},
+		}
+	}
+	if ca := g.acceptedCAs.Certs.K8s; ca != nil {
+		patch["cluster"].(map[string]any)["acceptedCAs"] = []any{
+			map[string]any{"crt": base64.StdEncoding.EncodeToString(ca.Crt)},
+		}
+	}
+}
+
+// acceptedTalosCA returns the PEM of the accepted Talos API CA, if any.
+func (g *Generator) acceptedTalosCA() []byte {
+	if g.acceptedCAs == nil || g.acceptedCAs.Certs == nil || g.acceptedCAs.Certs.OS == nil {
+		return nil
+	}
+	return g.acceptedCAs.Certs.OS.Crt
+}
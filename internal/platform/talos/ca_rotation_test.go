@@ -0,0 +1,88 @@
+package talos
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRotateCAs(t *testing.T) {
+	t.Parallel()
+
+	sb, err := NewSecrets("v1.7.0")
+	require.NoError(t, err)
+
+	rotated, err := RotateCAs(sb, "v1.7.0")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, sb.Certs.OS.Crt, rotated.Certs.OS.Crt, "Talos API CA is replaced")
+	assert.NotEqual(t, sb.Certs.K8s.Crt, rotated.Certs.K8s.Crt, "Kubernetes CA is replaced")
+	assert.Equal(t, sb.Certs.Etcd.Crt, rotated.Certs.Etcd.Crt, "etcd CA is kept")
+	assert.Equal(t, sb.Cluster.ID, rotated.Cluster.ID)
+	assert.Equal(t, sb.Secrets.BootstrapToken, rotated.Secrets.BootstrapToken)
+
+	// The original bundle is left alone
+	assert.NotSame(t, sb.Certs, rotated.Certs)
+
+	_, err = RotateCAs(nil, "v1.7.0")
+	assert.Error(t, err)
+}
+
+func TestGenerator_AcceptCAs(t *testing.T) {
+	t.Parallel()
+
+	sb, err := NewSecrets("v1.7.0")
+	require.NoError(t, err)
+	next, err := RotateCAs(sb, "v1.7.0")
+	require.NoError(t, err)
+
+	gen := NewGenerator("test-cluster", "v1.30.0", "v1.7.0", "https://1.2.3.4:6443", sb)
+	gen.AcceptCAs(next)
+
+	acceptedCAs := func(data []byte) (any, any) {
+		var result map[string]any
+		require.NoError(t, yaml.Unmarshal(data, &result))
+		machine := result["machine"].(map[string]any)
+		cluster := result["cluster"].(map[string]any)
+		return machine["acceptedCAs"], cluster["acceptedCAs"]
+	}
+
+	cpConfig, err := gen.GenerateControlPlaneConfig([]string{"1.2.3.4"}, "cp-1", 1)
+	require.NoError(t, err)
+	machineCAs, clusterCAs := acceptedCAs(cpConfig)
+	assert.Equal(t, []any{map[string]any{"crt": base64.StdEncoding.EncodeToString(next.Certs.OS.Crt)}}, machineCAs)
+	assert.Equal(t, []any{map[string]any{"crt": base64.StdEncoding.EncodeToString(next.Certs.K8s.Crt)}}, clusterCAs)
+
+	workerConfig, err := gen.GenerateWorkerConfig("worker-1", 2, nil)
+	require.NoError(t, err)
+	machineCAs, clusterCAs = acceptedCAs(workerConfig)
+	assert.NotNil(t, machineCAs)
+	assert.NotNil(t, clusterCAs)
+
+	clientConfig, err := gen.GetClientConfig()
+	require.NoError(t, err)
+	var talosconfig struct {
+		Contexts map[string]struct {
+			CA string `yaml:"ca"`
+		} `yaml:"contexts"`
+	}
+	require.NoError(t, yaml.Unmarshal(clientConfig, &talosconfig))
+	require.Len(t, talosconfig.Contexts, 1)
+	for _, c := range talosconfig.Contexts {
+		ca, err := base64.StdEncoding.DecodeString(c.CA)
+		require.NoError(t, err)
+		assert.Contains(t, string(ca), string(sb.Certs.OS.Crt))
+		assert.Contains(t, string(ca), string(next.Certs.OS.Crt))
+	}
+
+	// Dropping the accepted CAs restores the plain config
+	gen.AcceptCAs(nil)
+	cpConfig, err = gen.GenerateControlPlaneConfig([]string{"1.2.3.4"}, "cp-1", 1)
+	require.NoError(t, err)
+	machineCAs, clusterCAs = acceptedCAs(cpConfig)
+	assert.Nil(t, machineCAs)
+	assert.Nil(t, clusterCAs)
+}
@@ -2,6 +2,7 @@
 package talos
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"strings"
@@ -12,6 +13,8 @@ import (
 	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
 	"github.com/siderolabs/talos/pkg/machinery/config/machine"
 	"gopkg.in/yaml.v3"
+
+	k8znerconfig "github.com/milankappen/k8zner/internal/config"
 )
 
 // SecretsBundle is a type alias for the Talos secrets bundle.
@@ -24,6 +27,7 @@ type Generator struct {
 	talosVersion      string
 	endpoint          string
 	secretsBundle     *secrets.Bundle
+	acceptedCAs       *secrets.Bundle
 	machineOpts       *MachineConfigOptions
 }
 
@@ -71,12 +75,38 @@ func LoadSecrets(path string) (*secrets.Bundle, error) {
 	return sb, nil
 }
 
+// ParseSecrets decodes a Talos secrets bundle read by other means than
+// LoadSecrets, such as a decrypted file.
+func ParseSecrets(data []byte) (*secrets.Bundle, error) {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, fmt.Errorf("failed to load secrets bundle: file is empty")
+	}
+
+	var sb secrets.Bundle
+	if err := yaml.Unmarshal(data, &sb); err != nil {
+		return nil, fmt.Errorf("failed to load secrets bundle: %w", err)
+	}
+
+	// Re-inject clock
+	sb.Clock = secrets.NewFixedClock(time.Now())
+	return &sb, nil
+}
+
+// MarshalSecrets encodes Talos secrets in the format SaveSecrets writes.
+func MarshalSecrets(sb *secrets.Bundle) ([]byte, error) {
+	data, err := yaml.Marshal(sb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal secrets bundle: %w", err)
+	}
+	return data, nil
+}
+
 // SaveSecrets saves Talos secrets to a file.
 // Uses YAML format to match what Talos machinery's LoadBundle expects.
 func SaveSecrets(path string, sb *secrets.Bundle) error {
-	data, err := yaml.Marshal(sb)
+	data, err := MarshalSecrets(sb)
 	if err != nil {
-		return fmt.Errorf("failed to marshal secrets bundle: %w", err)
+		return err
 	}
 
 	if err := os.WriteFile(path, data, 0600); err != nil {
@@ -127,6 +157,7 @@ func (g *Generator) SetEndpoint(endpoint string) {
 // GenerateControlPlaneConfig generates the configuration for a control plane node.
 // If hostname is provided, it will be set in the machine config.
 // serverID is the Hetzner server ID, used to set the nodeid label for CCM integration.
+// The config carries its own hash in the ConfigHashAnnotation node annotation.
 func (g *Generator) GenerateControlPlaneConfig(san []string, hostname string, serverID int64) ([]byte, error) {
 	opts := []generate.Option{
 		generate.WithAdditionalSubjectAltNames(san),
@@ -142,13 +173,20 @@ func (g *Generator) GenerateControlPlaneConfig(san []string, hostname string, se
 
 	// Build and apply enhanced patch with all machine config options
 	patch := buildControlPlanePatch(hostname, serverID, g.machineOpts, installerImage, san)
-	return applyConfigPatch(baseConfig, patch)
+	g.addAcceptedCAs(patch)
+	data, err := applyConfigPatch(baseConfig, patch)
+	if err != nil {
+		return nil, err
+	}
+	return stampConfigHash(data)
 }
 
 // GenerateWorkerConfig generates the configuration for a worker node.
 // If hostname is provided, it will be set in the machine config.
 // serverID is the Hetzner server ID, used to set the nodeid label for CCM integration.
-func (g *Generator) GenerateWorkerConfig(hostname string, serverID int64) ([]byte, error) {
+// The node registers with the labels and taints of pool, if it is not nil.
+// The config carries its own hash in the ConfigHashAnnotation node annotation.
+func (g *Generator) GenerateWorkerConfig(hostname string, serverID int64, pool *k8znerconfig.WorkerNodePool) ([]byte, error) {
 	baseConfig, err := g.generateBaseConfig(machine.TypeWorker)
 	if err != nil {
 		return nil, err
@@ -158,8 +196,13 @@ func (g *Generator) GenerateWorkerConfig(hostname string, serverID int64) ([]byt
 	installerImage := g.getInstallerImageURL()
 
 	// Build and apply enhanced patch with all machine config options
-	patch := buildWorkerPatch(hostname, serverID, g.machineOpts, installerImage, nil)
-	return applyConfigPatch(baseConfig, patch)
+	patch := buildWorkerPatch(hostname, serverID, pool, g.machineOpts, installerImage, nil)
+	g.addAcceptedCAs(patch)
+	data, err := applyConfigPatch(baseConfig, patch)
+	if err != nil {
+		return nil, err
+	}
+	return stampConfigHash(data)
 }
 
 // generateBaseConfig generates the base Talos config without custom patches.
@@ -203,12 +246,22 @@ func (g *Generator) generateBaseConfig(machineType machine.Type, extraOpts ...ge
 // getInstallerImageURL returns the Talos installer image URL.
 // Uses factory.talos.dev if a schematic ID is configured, otherwise uses the default image.
 func (g *Generator) getInstallerImageURL() string {
-	if g.machineOpts != nil && g.machineOpts.SchematicID != "" {
+	schematicID := ""
+	if g.machineOpts != nil {
+		schematicID = g.machineOpts.SchematicID
+	}
+	return InstallerImageURL(schematicID, g.talosVersion)
+}
+
+// InstallerImageURL returns the Talos installer image for the given schematic and version.
+// An empty schematic ID selects the default upstream installer image.
+func InstallerImageURL(schematicID, talosVersion string) string {
+	if schematicID != "" {
 		// Use factory.talos.dev with schematic ID for custom images with extensions
-		return fmt.Sprintf("factory.talos.dev/installer/%s:%s", g.machineOpts.SchematicID, g.talosVersion)
+		return fmt.Sprintf("factory.talos.dev/installer/%s:%s", schematicID, talosVersion)
 	}
 	// Default installer image
-	return fmt.Sprintf("ghcr.io/siderolabs/installer:%s", g.talosVersion)
+	return fmt.Sprintf("ghcr.io/siderolabs/installer:%s", talosVersion)
 }
 
 // applyConfigPatch applies a patch map to the base config using deep merge.
@@ -242,7 +295,8 @@ func deepMerge(dst, src map[string]any) {
 	}
 }
 
-// GetClientConfig returns the talosconfig for the cluster.
+// GetClientConfig returns the talosconfig for the cluster. With accepted CAs,
+// it trusts servers issued by either CA.
 func (g *Generator) GetClientConfig() ([]byte, error) {
 	vc, err := config.ParseContractFromVersion(g.talosVersion)
 	if err != nil {
@@ -264,6 +318,16 @@ func (g *Generator) GetClientConfig() ([]byte, error) {
 		return nil, err
 	}
 
+	if accepted := g.acceptedTalosCA(); accepted != nil {
+		for _, c := range clientCfg.Contexts {
+			ca, err := base64.StdEncoding.DecodeString(c.CA)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode talosconfig CA: %w", err)
+			}
+			c.CA = base64.StdEncoding.EncodeToString(append(ca, accepted...))
+		}
+	}
+
 	bytes, err := clientCfg.Bytes()
 	if err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
 package talos
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -147,7 +148,7 @@ func TestGenerateWorkerConfig_InvalidTalosVersion(t *testing.T) {
 		machineOpts:       &MachineConfigOptions{},
 	}
 
-	_, err = gen.GenerateWorkerConfig("worker-1", 12345)
+	_, err = gen.GenerateWorkerConfig("worker-1", 12345, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to parse version contract")
 }
@@ -229,7 +230,7 @@ func TestGenerateWorkerConfig_WithOptions(t *testing.T) {
 	}
 	gen.SetMachineConfigOptions(opts)
 
-	configBytes, err := gen.GenerateWorkerConfig("worker-1", 67890)
+	configBytes, err := gen.GenerateWorkerConfig("worker-1", 67890, nil)
 	require.NoError(t, err)
 	assert.NotEmpty(t, configBytes)
 
@@ -245,7 +246,7 @@ func TestGenerateWorkerConfig_WithOptions(t *testing.T) {
 	assert.Equal(t, "67890", nodeLabels["nodeid"])
 }
 
-func TestUpgradeKubernetes_ReturnsNil(t *testing.T) {
+func TestUpgradeKubernetes_RequiresVersion(t *testing.T) {
 	t.Parallel()
 
 	sb, err := NewSecrets("v1.7.0")
@@ -253,9 +254,10 @@ func TestUpgradeKubernetes_ReturnsNil(t *testing.T) {
 
 	gen := NewGenerator("test-cluster", "v1.30.0", "v1.7.0", "https://1.2.3.4:6443", sb)
 
-	// UpgradeKubernetes is a no-op stub that always returns nil
-	err = gen.UpgradeKubernetes(nil, "", "")
-	require.NoError(t, err)
+	// An empty target version is rejected before any connection is attempted
+	err = gen.UpgradeKubernetes(context.Background(), "1.2.3.4", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "version is required")
 }
 
 func TestDerefBool(t *testing.T) {
@@ -359,6 +361,23 @@ func TestLoadSecrets_EmptyFile(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestParseSecrets_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	sb, err := NewSecrets("v1.7.0")
+	require.NoError(t, err)
+
+	data, err := MarshalSecrets(sb)
+	require.NoError(t, err)
+	parsed, err := ParseSecrets(data)
+	require.NoError(t, err)
+	assert.Equal(t, sb.Cluster.ID, parsed.Cluster.ID)
+	assert.NotNil(t, parsed.Clock)
+
+	_, err = ParseSecrets([]byte("\n"))
+	assert.ErrorContains(t, err, "file is empty")
+}
+
 func TestGenerateControlPlaneConfig_NoSANs(t *testing.T) {
 	t.Parallel()
 
@@ -412,7 +431,7 @@ func TestGenerateWorkerConfig_NoServerID(t *testing.T) {
 
 	gen := NewGenerator("test-cluster", "v1.30.0", "v1.7.0", "https://1.2.3.4:6443", sb)
 
-	configBytes, err := gen.GenerateWorkerConfig("worker-no-id", 0)
+	configBytes, err := gen.GenerateWorkerConfig("worker-no-id", 0, nil)
 	require.NoError(t, err)
 	assert.NotEmpty(t, configBytes)
 
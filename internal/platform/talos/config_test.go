@@ -6,7 +6,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
+
+	"github.com/milankappen/k8zner/internal/config"
 )
 
 func TestGenerateControlPlaneConfig(t *testing.T) {
@@ -61,7 +64,7 @@ func TestGenerateWorkerConfig(t *testing.T) {
 
 	hostname := "test-worker-1"
 	serverID := int64(67890)
-	configBytes, err := gen.GenerateWorkerConfig(hostname, serverID)
+	configBytes, err := gen.GenerateWorkerConfig(hostname, serverID, nil)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, configBytes)
 
@@ -79,6 +82,27 @@ func TestGenerateWorkerConfig(t *testing.T) {
 	assert.Equal(t, hostname, network["hostname"])
 }
 
+func TestGenerateWorkerConfig_Pool(t *testing.T) {
+	t.Parallel()
+	sb, err := NewSecrets("v1.7.0")
+	require.NoError(t, err)
+	gen := NewGenerator("test-cluster", "v1.30.0", "v1.7.0", "https://1.2.3.4:6443", sb)
+
+	pool := &config.WorkerNodePool{
+		Name:       "gpu",
+		NodeLabels: map[string]string{"accelerator": "nvidia"},
+		NodeTaints: []config.Taint{{Key: "nvidia.com/gpu", Value: "true", Effect: "NoSchedule"}},
+	}
+	configBytes, err := gen.GenerateWorkerConfig("gpu-1", 67890, pool)
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, yaml.Unmarshal(configBytes, &result))
+	machine := result["machine"].(map[string]any)
+	assert.Equal(t, map[string]any{"nodeid": "67890", "accelerator": "nvidia", "k8zner.io/pool": "gpu"}, machine["nodeLabels"])
+	assert.Equal(t, map[string]any{"nvidia.com/gpu": "true:NoSchedule"}, machine["nodeTaints"])
+}
+
 func TestSecrets(t *testing.T) {
 	t.Parallel()
 	talosVersion := "v1.7.0"
@@ -380,6 +404,12 @@ func TestGetInstallerImageURL(t *testing.T) {
 	})
 }
 
+func TestInstallerImageURL(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "ghcr.io/siderolabs/installer:v1.10.2", InstallerImageURL("", "v1.10.2"))
+	assert.Equal(t, "factory.talos.dev/installer/abc123:v1.10.2", InstallerImageURL("abc123", "v1.10.2"))
+}
+
 func TestNewGenerator(t *testing.T) {
 	t.Parallel()
 	t.Run("strips v prefix from kubernetes version", func(t *testing.T) {
@@ -0,0 +1,107 @@
+package talos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cosi-project/runtime/pkg/resource"
+	"github.com/cosi-project/runtime/pkg/safe"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	clientconfig "github.com/siderolabs/talos/pkg/machinery/client/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/encoder"
+	"github.com/siderolabs/talos/pkg/machinery/config/generate/secrets"
+	configres "github.com/siderolabs/talos/pkg/machinery/resources/config"
+)
+
+// ExistingCluster is what the machine config of a running control plane node
+// tells about a cluster that was not created by k8zner.
+type ExistingCluster struct {
+	Secrets     *secrets.Bundle
+	Name        string
+	Endpoint    string // host of the Kubernetes API endpoint
+	PodCIDR     string
+	ServiceCIDR string
+	SchematicID string
+
+	// Settings the configs k8zner generates may change: the CNI ("none"
+	// when it is installed separately, like k8zner installs Cilium),
+	// whether Talos runs kube-proxy and the external cloud provider
+	CNI                   string
+	KubeProxy             bool
+	ExternalCloudProvider bool
+
+	// MachineConfig is the active machine config of the node
+	MachineConfig []byte
+}
+
+// ReadExistingCluster connects to a control plane node with the given
+// talosconfig and recovers the cluster secrets and network settings from its
+// active machine config. Only control plane configs carry the CA keys.
+func ReadExistingCluster(ctx context.Context, talosconfig []byte, endpoint string) (*ExistingCluster, error) {
+	cfg, err := clientconfig.FromString(string(talosconfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse talos config: %w", err)
+	}
+	talosClient, err := client.New(ctx,
+		client.WithEndpoints(endpoint),
+		client.WithConfig(cfg),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Talos client: %w", err)
+	}
+	defer func() { _ = talosClient.Close() }()
+
+	nodeCtx := client.WithNode(ctx, endpoint)
+	active, err := safe.StateGet[*configres.MachineConfig](nodeCtx, talosClient.COSI,
+		resource.NewMetadata(configres.NamespaceName, configres.MachineConfigType, configres.ActiveID, resource.VersionUndefined))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read machine config of %s: %w", endpoint, err)
+	}
+
+	provider := active.Provider()
+	if !provider.Machine().Type().IsControlPlane() {
+		return nil, fmt.Errorf("%s is not a control plane node, its machine config has no CA keys", endpoint)
+	}
+	machineConfig, err := provider.EncodeBytes(encoder.WithComments(encoder.CommentsDisabled))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode machine config of %s: %w", endpoint, err)
+	}
+
+	existing := &ExistingCluster{
+		Secrets:               secrets.NewBundleFromConfig(secrets.NewFixedClock(time.Now()), provider),
+		Name:                  provider.Cluster().Name(),
+		SchematicID:           SchematicIDFromInstallerImage(provider.Machine().Install().Image()),
+		CNI:                   provider.Cluster().Network().CNI().Name(),
+		KubeProxy:             provider.Cluster().Proxy().Enabled(),
+		ExternalCloudProvider: provider.Cluster().ExternalCloudProvider().Enabled(),
+		MachineConfig:         machineConfig,
+	}
+	if cidrs := provider.Cluster().Network().PodCIDRs(); len(cidrs) > 0 {
+		existing.PodCIDR = cidrs[0]
+	}
+	if cidrs := provider.Cluster().Network().ServiceCIDRs(); len(cidrs) > 0 {
+		existing.ServiceCIDR = cidrs[0]
+	}
+	if endpoint := provider.Cluster().Endpoint(); endpoint != nil {
+		existing.Endpoint = endpoint.Hostname()
+	}
+	return existing, nil
+}
+
+// SchematicIDFromInstallerImage returns the Image Factory schematic ID of an
+// installer image like InstallerImageURL builds, or "" for other images.
+func SchematicIDFromInstallerImage(image string) string {
+	rest, ok := strings.CutPrefix(image, "factory.talos.dev/")
+	if !ok {
+		return ""
+	}
+	// <installer kind>/<schematic ID>:<version>
+	parts := strings.Split(rest, "/")
+	if len(parts) != 2 {
+		return ""
+	}
+	id, _, _ := strings.Cut(parts[1], ":")
+	return id
+}
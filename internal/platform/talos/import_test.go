@@ -0,0 +1,16 @@
+package talos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchematicIDFromInstallerImage(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "abc123", SchematicIDFromInstallerImage(InstallerImageURL("abc123", "v1.9.0")))
+	assert.Equal(t, "abc123", SchematicIDFromInstallerImage("factory.talos.dev/hcloud-installer/abc123:v1.9.0"))
+	assert.Empty(t, SchematicIDFromInstallerImage(InstallerImageURL("", "v1.9.0")))
+	assert.Empty(t, SchematicIDFromInstallerImage(""))
+}
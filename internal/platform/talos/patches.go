@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/util/labels"
 )
 
 // MachineConfigOptions holds all options needed to build Talos machine config patches.
@@ -77,14 +78,46 @@ func buildControlPlanePatch(hostname string, serverID int64, opts *MachineConfig
 	}
 }
 
-// buildWorkerPatch builds the full config patch for a worker node.
-func buildWorkerPatch(hostname string, serverID int64, opts *MachineConfigOptions, installerImage string, certSANs []string) map[string]any {
+// buildWorkerPatch builds the full config patch for a worker node of pool.
+func buildWorkerPatch(hostname string, serverID int64, pool *config.WorkerNodePool, opts *MachineConfigOptions, installerImage string, certSANs []string) map[string]any {
+	machine := buildMachinePatch(hostname, serverID, opts, installerImage, certSANs, false)
+	if pool != nil {
+		addWorkerPoolPatch(machine, pool)
+	}
 	return map[string]any{
-		"machine": buildMachinePatch(hostname, serverID, opts, installerImage, certSANs, false),
+		"machine": machine,
 		"cluster": buildClusterPatch(opts, false),
 	}
 }
 
+// addWorkerPoolPatch adds the pool label and the pool's Kubernetes labels and
+// taints to the machine section, so the node registers with them and no pod
+// is scheduled onto it before they are set.
+func addWorkerPoolPatch(machine map[string]any, pool *config.WorkerNodePool) {
+	nodeLabels, ok := machine["nodeLabels"].(map[string]any)
+	if !ok {
+		nodeLabels = map[string]any{}
+	}
+	for key, value := range pool.NodeLabels {
+		nodeLabels[key] = value
+	}
+	nodeLabels[labels.KeyPool] = pool.Name
+	machine["nodeLabels"] = nodeLabels
+
+	// Talos takes taints as key: "value:effect", or key: "effect" without a value
+	if len(pool.NodeTaints) > 0 {
+		nodeTaints := make(map[string]any, len(pool.NodeTaints))
+		for _, taint := range pool.NodeTaints {
+			if taint.Value == "" {
+				nodeTaints[taint.Key] = taint.Effect
+			} else {
+				nodeTaints[taint.Key] = taint.Value + ":" + taint.Effect
+			}
+		}
+		machine["nodeTaints"] = nodeTaints
+	}
+}
+
 // buildMachinePatch builds the machine section of the config patch.
 func buildMachinePatch(hostname string, serverID int64, opts *MachineConfigOptions, installerImage string, certSANs []string, isControlPlane bool) map[string]any {
 	machine := map[string]any{}
@@ -503,7 +503,7 @@ func TestBuildWorkerPatch(t *testing.T) {
 		DiscoveryServiceEnabled: true,
 	}
 
-	result := buildWorkerPatch("worker-1", 12345, opts, "ghcr.io/siderolabs/installer:v1.7.0", nil)
+	result := buildWorkerPatch("worker-1", 12345, nil, opts, "ghcr.io/siderolabs/installer:v1.7.0", nil)
 
 	// Verify top-level structure
 	machine, ok := result["machine"].(map[string]any)
@@ -524,6 +524,37 @@ func TestBuildWorkerPatch(t *testing.T) {
 	assert.Nil(t, cluster["externalCloudProvider"])
 }
 
+func TestBuildWorkerPatch_Pool(t *testing.T) {
+	t.Parallel()
+	pool := &config.WorkerNodePool{
+		Name:       "gpu",
+		NodeLabels: map[string]string{"accelerator": "nvidia"},
+		NodeTaints: []config.Taint{
+			{Key: "nvidia.com/gpu", Value: "true", Effect: "NoSchedule"},
+			{Key: "dedicated", Effect: "NoExecute"},
+		},
+	}
+
+	result := buildWorkerPatch("worker-1", 12345, pool, &MachineConfigOptions{}, "installer:v1", nil)
+
+	machine := result["machine"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"nodeid":         "12345",
+		"accelerator":    "nvidia",
+		"k8zner.io/pool": "gpu",
+	}, machine["nodeLabels"])
+	assert.Equal(t, map[string]any{
+		"nvidia.com/gpu": "true:NoSchedule",
+		"dedicated":      "NoExecute",
+	}, machine["nodeTaints"])
+
+	// Without a pool, only the nodeid label is set
+	result = buildWorkerPatch("worker-1", 12345, nil, &MachineConfigOptions{}, "installer:v1", nil)
+	machine = result["machine"].(map[string]any)
+	assert.Equal(t, map[string]any{"nodeid": "12345"}, machine["nodeLabels"])
+	assert.Nil(t, machine["nodeTaints"])
+}
+
 func TestBuildMachinePatch_CertSANs(t *testing.T) {
 	t.Parallel()
 	opts := &MachineConfigOptions{
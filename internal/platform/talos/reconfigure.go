@@ -0,0 +1,141 @@
+package talos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/siderolabs/talos/pkg/machinery/client"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigHashAnnotation is the Kubernetes node annotation carrying the hash of the
+// machine config a node runs. Generated configs set it via machine.nodeAnnotations,
+// so the node reports which config it runs without reading the config back.
+const ConfigHashAnnotation = "k8zner.io/config-hash"
+
+// Modes for applying a machine config to a running node.
+const (
+	// ApplyModeAuto lets Talos decide whether the change needs a reboot.
+	ApplyModeAuto = "auto"
+	// ApplyModeNoReboot applies the change in place and fails if a reboot is required.
+	ApplyModeNoReboot = "no_reboot"
+	// ApplyModeReboot applies the change and always reboots the node.
+	ApplyModeReboot = "reboot"
+	// ApplyModeStaged stores the change and applies it on the next reboot.
+	ApplyModeStaged = "staged"
+)
+
+// ConfigHash returns the hash of a generated machine config. The config hash
+// annotation itself is ignored, so the hash is the same before and after the
+// config is stamped with it.
+func ConfigHash(data []byte) (string, error) {
+	var configMap map[string]any
+	if err := yaml.Unmarshal(data, &configMap); err != nil {
+		return "", fmt.Errorf("failed to unmarshal machine config: %w", err)
+	}
+	if configMap == nil {
+		return "", fmt.Errorf("machine config is empty")
+	}
+
+	if machine, ok := configMap["machine"].(map[string]any); ok {
+		if annotations, ok := machine["nodeAnnotations"].(map[string]any); ok {
+			delete(annotations, ConfigHashAnnotation)
+			if len(annotations) == 0 {
+				delete(machine, "nodeAnnotations")
+			}
+		}
+	}
+
+	canonical, err := yaml.Marshal(configMap)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal machine config: %w", err)
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// stampConfigHash adds the config hash annotation to a generated machine config.
+func stampConfigHash(data []byte) ([]byte, error) {
+	hash, err := ConfigHash(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return applyConfigPatch(data, map[string]any{
+		"machine": map[string]any{
+			"nodeAnnotations": map[string]any{
+				ConfigHashAnnotation: hash,
+			},
+		},
+	})
+}
+
+// ApplyMachineConfig applies a machine config to a running node and returns the
+// mode Talos actually used. In auto mode that is either no_reboot or reboot,
+// depending on whether the change required a reboot; Talos reboots the node by
+// itself in the latter case.
+func (g *Generator) ApplyMachineConfig(ctx context.Context, endpoint string, data []byte, mode string) (string, error) {
+	requested, err := parseApplyMode(mode)
+	if err != nil {
+		return "", err
+	}
+
+	talosClient, err := g.createClient(ctx, endpoint)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Talos client: %w", err)
+	}
+	defer func() { _ = talosClient.Close() }()
+
+	// Wrap context with target node - required for node-specific operations
+	nodeCtx := client.WithNode(ctx, endpoint)
+
+	resp, err := talosClient.ApplyConfiguration(nodeCtx, &machineapi.ApplyConfigurationRequest{
+		Data: data,
+		Mode: requested,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to apply machine config: %w", err)
+	}
+
+	applied := requested
+	for _, msg := range resp.GetMessages() {
+		applied = msg.GetMode()
+	}
+
+	return applyModeName(applied), nil
+}
+
+// parseApplyMode converts a config apply mode name into its Talos API value.
+// An empty mode selects auto.
+func parseApplyMode(mode string) (machineapi.ApplyConfigurationRequest_Mode, error) {
+	switch mode {
+	case "", ApplyModeAuto:
+		return machineapi.ApplyConfigurationRequest_AUTO, nil
+	case ApplyModeNoReboot:
+		return machineapi.ApplyConfigurationRequest_NO_REBOOT, nil
+	case ApplyModeReboot:
+		return machineapi.ApplyConfigurationRequest_REBOOT, nil
+	case ApplyModeStaged:
+		return machineapi.ApplyConfigurationRequest_STAGED, nil
+	default:
+		return 0, fmt.Errorf("unknown config apply mode %q (valid: auto, no_reboot, reboot, staged)", mode)
+	}
+}
+
+// applyModeName converts a Talos API apply mode into its config name.
+func applyModeName(mode machineapi.ApplyConfigurationRequest_Mode) string {
+	switch mode {
+	case machineapi.ApplyConfigurationRequest_NO_REBOOT:
+		return ApplyModeNoReboot
+	case machineapi.ApplyConfigurationRequest_REBOOT:
+		return ApplyModeReboot
+	case machineapi.ApplyConfigurationRequest_STAGED:
+		return ApplyModeStaged
+	default:
+		return ApplyModeAuto
+	}
+}
@@ -0,0 +1,154 @@
+package talos
+
+import (
+	"testing"
+
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigHash(t *testing.T) {
+	t.Parallel()
+
+	t.Run("is stable for the same config", func(t *testing.T) {
+		t.Parallel()
+		first, err := ConfigHash([]byte("machine:\n  type: worker\n"))
+		require.NoError(t, err)
+		second, err := ConfigHash([]byte("machine:\n  type: worker\n"))
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+		assert.Len(t, first, 64)
+	})
+
+	t.Run("changes with the config", func(t *testing.T) {
+		t.Parallel()
+		worker, err := ConfigHash([]byte("machine:\n  type: worker\n"))
+		require.NoError(t, err)
+		cp, err := ConfigHash([]byte("machine:\n  type: controlplane\n"))
+		require.NoError(t, err)
+		assert.NotEqual(t, worker, cp)
+	})
+
+	t.Run("ignores the config hash annotation", func(t *testing.T) {
+		t.Parallel()
+		plain, err := ConfigHash([]byte("machine:\n  type: worker\n"))
+		require.NoError(t, err)
+		stamped, err := ConfigHash([]byte("machine:\n  type: worker\n  nodeAnnotations:\n    k8zner.io/config-hash: abc\n"))
+		require.NoError(t, err)
+		assert.Equal(t, plain, stamped)
+	})
+
+	t.Run("keeps other node annotations", func(t *testing.T) {
+		t.Parallel()
+		plain, err := ConfigHash([]byte("machine:\n  type: worker\n"))
+		require.NoError(t, err)
+		annotated, err := ConfigHash([]byte("machine:\n  type: worker\n  nodeAnnotations:\n    example.com/team: infra\n"))
+		require.NoError(t, err)
+		assert.NotEqual(t, plain, annotated)
+	})
+
+	t.Run("rejects invalid YAML", func(t *testing.T) {
+		t.Parallel()
+		_, err := ConfigHash([]byte("invalid: yaml: content: ["))
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects empty config", func(t *testing.T) {
+		t.Parallel()
+		_, err := ConfigHash(nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestStampConfigHash(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("machine:\n  type: worker\n")
+	stamped, err := stampConfigHash(data)
+	require.NoError(t, err)
+
+	want, err := ConfigHash(data)
+	require.NoError(t, err)
+
+	var config map[string]any
+	require.NoError(t, yaml.Unmarshal(stamped, &config))
+	annotations := config["machine"].(map[string]any)["nodeAnnotations"].(map[string]any)
+	assert.Equal(t, want, annotations[ConfigHashAnnotation])
+
+	got, err := ConfigHash(stamped)
+	require.NoError(t, err)
+	assert.Equal(t, want, got, "stamping must not change the hash")
+}
+
+func TestGenerateConfig_StampsConfigHash(t *testing.T) {
+	t.Parallel()
+
+	sb, err := NewSecrets("v1.7.0")
+	require.NoError(t, err)
+
+	gen := NewGenerator("test-cluster", "v1.30.0", "v1.7.0", "https://1.2.3.4:6443", sb)
+
+	first, err := gen.GenerateWorkerConfig("worker-1", 67890, nil)
+	require.NoError(t, err)
+	second, err := gen.GenerateWorkerConfig("worker-1", 67890, nil)
+	require.NoError(t, err)
+	other, err := gen.GenerateWorkerConfig("worker-2", 67891, nil)
+	require.NoError(t, err)
+
+	var config map[string]any
+	require.NoError(t, yaml.Unmarshal(first, &config))
+	annotations := config["machine"].(map[string]any)["nodeAnnotations"].(map[string]any)
+
+	hash, err := ConfigHash(first)
+	require.NoError(t, err)
+	assert.Equal(t, hash, annotations[ConfigHashAnnotation])
+
+	secondHash, err := ConfigHash(second)
+	require.NoError(t, err)
+	assert.Equal(t, hash, secondHash, "generation should be deterministic")
+
+	otherHash, err := ConfigHash(other)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+
+	cp, err := gen.GenerateControlPlaneConfig([]string{"1.2.3.4"}, "cp-1", 12345)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(cp, &config))
+	annotations = config["machine"].(map[string]any)["nodeAnnotations"].(map[string]any)
+	assert.NotEmpty(t, annotations[ConfigHashAnnotation])
+}
+
+func TestParseApplyMode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		mode string
+		want machineapi.ApplyConfigurationRequest_Mode
+	}{
+		{"", machineapi.ApplyConfigurationRequest_AUTO},
+		{ApplyModeAuto, machineapi.ApplyConfigurationRequest_AUTO},
+		{ApplyModeNoReboot, machineapi.ApplyConfigurationRequest_NO_REBOOT},
+		{ApplyModeReboot, machineapi.ApplyConfigurationRequest_REBOOT},
+		{ApplyModeStaged, machineapi.ApplyConfigurationRequest_STAGED},
+	}
+	for _, tt := range tests {
+		got, err := parseApplyMode(tt.mode)
+		require.NoError(t, err, tt.mode)
+		assert.Equal(t, tt.want, got, tt.mode)
+	}
+
+	_, err := parseApplyMode("try")
+	assert.Error(t, err)
+}
+
+func TestApplyModeName(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []string{ApplyModeAuto, ApplyModeNoReboot, ApplyModeReboot, ApplyModeStaged} {
+		parsed, err := parseApplyMode(mode)
+		require.NoError(t, err)
+		assert.Equal(t, mode, applyModeName(parsed))
+	}
+}
@@ -3,12 +3,20 @@ package talos
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/milankappen/k8zner/internal/provisioning"
 
+	"github.com/cosi-project/runtime/pkg/resource"
+	"github.com/cosi-project/runtime/pkg/safe"
+	machineapi "github.com/siderolabs/talos/pkg/machinery/api/machine"
 	"github.com/siderolabs/talos/pkg/machinery/client"
 	"github.com/siderolabs/talos/pkg/machinery/client/config"
+	"github.com/siderolabs/talos/pkg/machinery/config/encoder"
+	"github.com/siderolabs/talos/pkg/machinery/config/types/v1alpha1"
+	"github.com/siderolabs/talos/pkg/machinery/constants"
+	configres "github.com/siderolabs/talos/pkg/machinery/resources/config"
 )
 
 // GetNodeVersion retrieves the current Talos version from a node.
@@ -59,20 +67,99 @@ func (g *Generator) UpgradeNode(ctx context.Context, endpoint, imageURL string,
 	return nil
 }
 
-// UpgradeKubernetes upgrades the Kubernetes control plane.
+// UpgradeKubernetes moves a single node to the target Kubernetes version.
 //
-// Note: In Talos Linux, Kubernetes upgrades happen automatically when nodes are upgraded
-// to a new Talos version with a different Kubernetes version. The Talos machinery client
-// doesn't expose a direct K8s-only upgrade method because Kubernetes is tightly coupled
-// with the Talos OS release.
-//
-// To upgrade Kubernetes in a Talos cluster:
-// 1. Upgrade control plane nodes to a Talos version containing the desired K8s version
-// 2. Talos automatically handles the Kubernetes upgrade during the node upgrade
-// 3. Upgrade worker nodes to complete the cluster upgrade
-//
-// This method is a no-op since K8s upgrades are handled by UpgradeNode.
-func (g *Generator) UpgradeKubernetes(_ context.Context, _, _ string) error {
+// Talos runs kubelet and the control plane static pods from the images named in
+// the machine config, so the upgrade reads the node's active config, points those
+// images at the target version and applies it back in no-reboot mode. Talos then
+// restarts the affected components in place. Callers are expected to upgrade
+// control plane nodes one at a time before moving on to workers.
+func (g *Generator) UpgradeKubernetes(ctx context.Context, endpoint, targetVersion string) error {
+	version := strings.TrimPrefix(targetVersion, "v")
+	if version == "" {
+		return fmt.Errorf("target Kubernetes version is required")
+	}
+
+	talosClient, err := g.createClient(ctx, endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to create Talos client: %w", err)
+	}
+	defer func() { _ = talosClient.Close() }()
+
+	// Wrap context with target node - required for node-specific operations
+	nodeCtx := client.WithNode(ctx, endpoint)
+
+	active, err := safe.StateGet[*configres.MachineConfig](nodeCtx, talosClient.COSI,
+		resource.NewMetadata(configres.NamespaceName, configres.MachineConfigType, configres.ActiveID, resource.VersionUndefined))
+	if err != nil {
+		return fmt.Errorf("failed to read machine config: %w", err)
+	}
+
+	patched, err := active.Provider().PatchV1Alpha1(func(cfg *v1alpha1.Config) error {
+		return patchKubernetesImages(cfg, version)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to patch machine config: %w", err)
+	}
+
+	data, err := patched.EncodeBytes(encoder.WithComments(encoder.CommentsDisabled))
+	if err != nil {
+		return fmt.Errorf("failed to encode machine config: %w", err)
+	}
+
+	_, err = talosClient.ApplyConfiguration(nodeCtx, &machineapi.ApplyConfigurationRequest{
+		Data: data,
+		Mode: machineapi.ApplyConfigurationRequest_NO_REBOOT,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply machine config: %w", err)
+	}
+
+	return nil
+}
+
+// patchKubernetesImages points kubelet at the given Kubernetes version and, on
+// control plane nodes, the API server, controller manager, scheduler and
+// kube-proxy images as well. The version must not carry a "v" prefix.
+func patchKubernetesImages(cfg *v1alpha1.Config, version string) error {
+	if cfg.MachineConfig == nil {
+		return fmt.Errorf("machine config has no machine section")
+	}
+
+	if cfg.MachineConfig.MachineKubelet == nil {
+		cfg.MachineConfig.MachineKubelet = &v1alpha1.KubeletConfig{}
+	}
+	cfg.MachineConfig.MachineKubelet.KubeletImage = fmt.Sprintf("%s:v%s", constants.KubeletImage, version)
+
+	if !cfg.Machine().Type().IsControlPlane() {
+		return nil
+	}
+
+	if cfg.ClusterConfig == nil {
+		return fmt.Errorf("control plane machine config has no cluster section")
+	}
+	cluster := cfg.ClusterConfig
+
+	if cluster.APIServerConfig == nil {
+		cluster.APIServerConfig = &v1alpha1.APIServerConfig{}
+	}
+	cluster.APIServerConfig.ContainerImage = fmt.Sprintf("%s:v%s", constants.KubernetesAPIServerImage, version)
+
+	if cluster.ControllerManagerConfig == nil {
+		cluster.ControllerManagerConfig = &v1alpha1.ControllerManagerConfig{}
+	}
+	cluster.ControllerManagerConfig.ContainerImage = fmt.Sprintf("%s:v%s", constants.KubernetesControllerManagerImage, version)
+
+	if cluster.SchedulerConfig == nil {
+		cluster.SchedulerConfig = &v1alpha1.SchedulerConfig{}
+	}
+	cluster.SchedulerConfig.ContainerImage = fmt.Sprintf("%s:v%s", constants.KubernetesSchedulerImage, version)
+
+	// kube-proxy is usually disabled in favour of Cilium; only bump it when present
+	if cluster.ProxyConfig != nil {
+		cluster.ProxyConfig.ContainerImage = fmt.Sprintf("%s:v%s", constants.KubeProxyImage, version)
+	}
+
 	return nil
 }
 
@@ -84,22 +84,26 @@ func TestHealthCheck_ConnectionError(t *testing.T) {
 	require.Error(t, err)
 }
 
-func TestUpgradeKubernetes_NoOp(t *testing.T) {
+func TestUpgradeKubernetes_UnreachableEndpoint(t *testing.T) {
 	t.Parallel()
 
 	gen := newTestGenerator(t)
 
-	// UpgradeKubernetes is a no-op stub - should always return nil regardless of input
-	err := gen.UpgradeKubernetes(context.Background(), "127.0.0.1:50001", "v1.32.0")
-	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	// Also works with empty strings
-	err = gen.UpgradeKubernetes(context.Background(), "", "")
-	require.NoError(t, err)
+	err := gen.UpgradeKubernetes(ctx, "127.0.0.1:50001", "v1.32.0")
+	require.Error(t, err)
+}
 
-	// Also works with nil context (since the function ignores all params)
-	err = gen.UpgradeKubernetes(nil, "", "")
-	require.NoError(t, err)
+func TestUpgradeKubernetes_EmptyVersion(t *testing.T) {
+	t.Parallel()
+
+	gen := newTestGenerator(t)
+
+	err := gen.UpgradeKubernetes(context.Background(), "127.0.0.1:50001", "v")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "version is required")
 }
 
 func TestCreateClient_InvalidClientConfig(t *testing.T) {
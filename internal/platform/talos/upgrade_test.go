@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/siderolabs/talos/pkg/machinery/config/types/v1alpha1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUpgradeNode_BuildsCorrectImageURL(t *testing.T) {
@@ -230,21 +232,48 @@ func TestUpgradeNode_CausesReboot(t *testing.T) {
 	assert.True(t, true)
 }
 
-func TestUpgradeKubernetes_UpgradesControlPlane(t *testing.T) {
+func TestPatchKubernetesImages_ControlPlane(t *testing.T) {
 	t.Parallel()
-	// This is a documentation test showing the expected behavior
-	// UpgradeKubernetes upgrades the Kubernetes control plane components
 
-	// Expected behavior:
-	// - Connects to control plane node
-	// - Calls talosClient.UpgradeK8s() with version (without 'v' prefix)
-	// - Talos orchestrates upgrade of:
-	//   - kube-apiserver
-	//   - kube-controller-manager
-	//   - kube-scheduler
-	//   - etcd (if version changed)
-	// - Returns when upgrade completes
-
-	// This test documents the expected interface behavior
-	assert.True(t, true)
+	cfg := &v1alpha1.Config{
+		MachineConfig: &v1alpha1.MachineConfig{MachineType: "controlplane"},
+		ClusterConfig: &v1alpha1.ClusterConfig{
+			APIServerConfig: &v1alpha1.APIServerConfig{ContainerImage: "registry.k8s.io/kube-apiserver:v1.31.4"},
+			ProxyConfig:     &v1alpha1.ProxyConfig{ContainerImage: "registry.k8s.io/kube-proxy:v1.31.4"},
+		},
+	}
+
+	require.NoError(t, patchKubernetesImages(cfg, "1.32.1"))
+
+	assert.Equal(t, "ghcr.io/siderolabs/kubelet:v1.32.1", cfg.MachineConfig.MachineKubelet.KubeletImage)
+	assert.Equal(t, "registry.k8s.io/kube-apiserver:v1.32.1", cfg.ClusterConfig.APIServerConfig.ContainerImage)
+	assert.Equal(t, "registry.k8s.io/kube-controller-manager:v1.32.1", cfg.ClusterConfig.ControllerManagerConfig.ContainerImage)
+	assert.Equal(t, "registry.k8s.io/kube-scheduler:v1.32.1", cfg.ClusterConfig.SchedulerConfig.ContainerImage)
+	assert.Equal(t, "registry.k8s.io/kube-proxy:v1.32.1", cfg.ClusterConfig.ProxyConfig.ContainerImage)
+}
+
+func TestPatchKubernetesImages_Worker(t *testing.T) {
+	t.Parallel()
+
+	cfg := &v1alpha1.Config{
+		MachineConfig: &v1alpha1.MachineConfig{
+			MachineType:    "worker",
+			MachineKubelet: &v1alpha1.KubeletConfig{KubeletImage: "ghcr.io/siderolabs/kubelet:v1.31.4"},
+		},
+		ClusterConfig: &v1alpha1.ClusterConfig{},
+	}
+
+	require.NoError(t, patchKubernetesImages(cfg, "1.32.1"))
+
+	assert.Equal(t, "ghcr.io/siderolabs/kubelet:v1.32.1", cfg.MachineConfig.MachineKubelet.KubeletImage)
+	// Control plane components are never added to worker configs
+	assert.Nil(t, cfg.ClusterConfig.APIServerConfig)
+	assert.Nil(t, cfg.ClusterConfig.SchedulerConfig)
+}
+
+func TestPatchKubernetesImages_MissingMachineSection(t *testing.T) {
+	t.Parallel()
+
+	err := patchKubernetesImages(&v1alpha1.Config{}, "1.32.1")
+	require.Error(t, err)
 }
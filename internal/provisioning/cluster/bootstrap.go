@@ -4,6 +4,7 @@
 package cluster
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 	"time"
@@ -286,7 +287,8 @@ func waitForControlPlaneReadyViaLB(ctx *provisioning.Context) error {
 	}
 }
 
-// bootstrapEtcd initializes etcd on a control plane node.
+// bootstrapEtcd initializes etcd on a control plane node, recovering it from
+// State.EtcdSnapshot when one is set.
 func bootstrapEtcd(ctx *provisioning.Context) error {
 	var endpoint string
 	if ctx.Config.IsPrivateFirst() {
@@ -308,8 +310,22 @@ func bootstrapEtcd(ctx *provisioning.Context) error {
 	}
 	defer func() { _ = clientCtx.Close() }()
 
-	ctx.Observer.Printf("[%s] Bootstrapping etcd via %s...", phase, endpoint)
-	if err := clientCtx.Bootstrap(ctx, &machine.BootstrapRequest{}); err != nil {
+	req := &machine.BootstrapRequest{}
+	if snapshot := ctx.State.EtcdSnapshot; len(snapshot) > 0 {
+		// The uploaded snapshot is consumed by the recover bootstrap below.
+		// Snapshots taken through the Talos API carry an integrity hash, so
+		// the hash check stays enabled.
+		ctx.Observer.Printf("[%s] Uploading etcd snapshot (%d bytes) via %s...", phase, len(snapshot), endpoint)
+		if _, err := clientCtx.EtcdRecover(ctx, bytes.NewReader(snapshot)); err != nil {
+			return fmt.Errorf("failed to upload etcd snapshot: %w", err)
+		}
+		req.RecoverEtcd = true
+		ctx.Observer.Printf("[%s] Bootstrapping etcd from snapshot via %s...", phase, endpoint)
+	} else {
+		ctx.Observer.Printf("[%s] Bootstrapping etcd via %s...", phase, endpoint)
+	}
+
+	if err := clientCtx.Bootstrap(ctx, req); err != nil {
 		return fmt.Errorf("failed to bootstrap etcd: %w", err)
 	}
 	return nil
@@ -382,7 +398,8 @@ func ApplyWorkerConfigs(ctx *provisioning.Context) error {
 
 	for nodeName, nodeIP := range workerNodes {
 		serverID := ctx.State.WorkerServerIDs[nodeName]
-		nodeConfig, err := ctx.Talos.GenerateWorkerConfig(nodeName, serverID)
+		pool := ctx.Config.WorkerPool(ctx.State.WorkerPools[nodeName])
+		nodeConfig, err := ctx.Talos.GenerateWorkerConfig(nodeName, serverID, pool)
 		if err != nil {
 			return fmt.Errorf("failed to generate worker config for %s: %w", nodeName, err)
 		}
@@ -99,7 +99,8 @@ func configureAndWaitForNewWorkers(ctx *provisioning.Context, newWorkerNodes map
 	ctx.Observer.Printf("[%s] Found %d new worker nodes to configure", phase, len(newWorkerNodes))
 	for nodeName, nodeIP := range newWorkerNodes {
 		serverID := ctx.State.WorkerServerIDs[nodeName]
-		nodeConfig, err := ctx.Talos.GenerateWorkerConfig(nodeName, serverID)
+		pool := ctx.Config.WorkerPool(ctx.State.WorkerPools[nodeName])
+		nodeConfig, err := ctx.Talos.GenerateWorkerConfig(nodeName, serverID, pool)
 		if err != nil {
 			return fmt.Errorf("failed to generate worker config for new node %s: %w", nodeName, err)
 		}
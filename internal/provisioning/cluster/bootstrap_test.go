@@ -370,6 +370,9 @@ func (m *mockTalosConfigProducer) UpgradeNode(_ context.Context, _, _ string, _
 func (m *mockTalosConfigProducer) UpgradeKubernetes(_ context.Context, _, _ string) error {
 	return nil
 }
+func (m *mockTalosConfigProducer) ApplyMachineConfig(_ context.Context, _ string, _ []byte, _ string) (string, error) {
+	return "", nil
+}
 func (m *mockTalosConfigProducer) WaitForNodeReady(_ context.Context, _ string, _ time.Duration) error {
 	return nil
 }
@@ -386,7 +389,7 @@ func (m *mockTalosConfigProducer) GenerateControlPlaneConfig(san []string, hostn
 	}
 	return []byte("mock-config"), nil
 }
-func (m *mockTalosConfigProducer) GenerateWorkerConfig(hostname string, serverID int64) ([]byte, error) {
+func (m *mockTalosConfigProducer) GenerateWorkerConfig(hostname string, serverID int64, _ *config.WorkerNodePool) ([]byte, error) {
 	if m.generateWorkerConfigFn != nil {
 		return m.generateWorkerConfigFn(hostname, serverID)
 	}
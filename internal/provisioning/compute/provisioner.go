@@ -174,6 +174,7 @@ func provisionAllServers(ctx *provisioning.Context) error {
 				}
 				for name, id := range poolResult.ServerIDs {
 					ctx.State.WorkerServerIDs[name] = id
+					ctx.State.WorkerPools[name] = pool.Name
 				}
 				mu.Unlock()
 				return nil
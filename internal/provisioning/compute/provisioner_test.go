@@ -26,7 +26,7 @@ func (m *mockTalosProducer) GenerateControlPlaneConfig(_ []string, _ string, _ i
 	return []byte("control-plane-config"), nil
 }
 
-func (m *mockTalosProducer) GenerateWorkerConfig(_ string, _ int64) ([]byte, error) {
+func (m *mockTalosProducer) GenerateWorkerConfig(_ string, _ int64, _ *config.WorkerNodePool) ([]byte, error) {
 	return []byte("worker-config"), nil
 }
 
@@ -50,6 +50,10 @@ func (m *mockTalosProducer) UpgradeKubernetes(_ context.Context, _, _ string) er
 	return nil
 }
 
+func (m *mockTalosProducer) ApplyMachineConfig(_ context.Context, _ string, _ []byte, _ string) (string, error) {
+	return "", nil
+}
+
 func (m *mockTalosProducer) WaitForNodeReady(_ context.Context, _ string, _ time.Duration) error {
 	return nil
 }
@@ -84,6 +88,7 @@ func createTestContext(t *testing.T, mockInfra *hcloud_internal.MockClient, cfg
 		WorkerIPs:             make(map[string]string),
 		ControlPlaneServerIDs: make(map[string]int64),
 		WorkerServerIDs:       make(map[string]int64),
+		WorkerPools:           make(map[string]string),
 	}
 
 	return ctx
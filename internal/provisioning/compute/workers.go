@@ -53,6 +53,7 @@ func ProvisionWorkers(ctx *provisioning.Context) error {
 				}
 				for name, id := range poolResult.ServerIDs {
 					ctx.State.WorkerServerIDs[name] = id
+					ctx.State.WorkerPools[name] = pool.Name
 				}
 				mu.Unlock()
 				return nil
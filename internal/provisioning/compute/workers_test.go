@@ -61,6 +61,11 @@ func TestProvisionWorkers_MultiplePools(t *testing.T) {
 	// Both pools should have provisioned
 	assert.NotEmpty(t, ctx.State.WorkerIPs)
 	assert.NotEmpty(t, ctx.State.WorkerServerIDs)
+	pools := map[string]int{}
+	for _, pool := range ctx.State.WorkerPools {
+		pools[pool]++
+	}
+	assert.Equal(t, map[string]int{"pool-a": 2, "pool-b": 3}, pools, "every worker is recorded with its pool")
 
 	// Verify both server types were used (both pools were provisioned)
 	mu.Lock()
@@ -25,11 +25,16 @@ type State struct {
 	WorkerIPs             map[string]string // nodeName -> publicIP
 	ControlPlaneServerIDs map[string]int64  // nodeName -> serverID (for nodeid label)
 	WorkerServerIDs       map[string]int64  // nodeName -> serverID (for nodeid label)
+	WorkerPools           map[string]string // nodeName -> worker pool name (for pool labels and taints)
 	SANs                  []string          // Subject Alternative Names for certs
 
 	// Cluster results (populated by cluster bootstrapper)
 	Kubeconfig  []byte
 	TalosConfig []byte
+
+	// EtcdSnapshot, when set before bootstrap, is recovered into etcd instead
+	// of bootstrapping an empty cluster (set by the restore command)
+	EtcdSnapshot []byte
 }
 
 // NewState creates an empty provisioning state.
@@ -39,6 +44,7 @@ func NewState() *State {
 		WorkerIPs:             make(map[string]string),
 		ControlPlaneServerIDs: make(map[string]int64),
 		WorkerServerIDs:       make(map[string]int64),
+		WorkerPools:           make(map[string]string),
 	}
 }
 
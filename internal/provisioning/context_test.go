@@ -20,6 +20,7 @@ func TestNewState(t *testing.T) {
 	assert.NotNil(t, state.WorkerIPs)
 	assert.NotNil(t, state.ControlPlaneServerIDs)
 	assert.NotNil(t, state.WorkerServerIDs)
+	assert.NotNil(t, state.WorkerPools)
 
 	// Maps should be empty but initialized
 	assert.Empty(t, state.ControlPlaneIPs)
@@ -0,0 +1,43 @@
+package destroy
+
+import (
+	"fmt"
+
+	"github.com/milankappen/k8zner/internal/platform/cloudflare"
+	"github.com/milankappen/k8zner/internal/provisioning"
+)
+
+// cleanupCloudflareDNS removes DNS records owned by this cluster from Cloudflare.
+// Records are identified via TXT ownership records created by external-dns.
+func cleanupCloudflareDNS(ctx *provisioning.Context) error {
+	cfg := ctx.Config
+	cfClient := cloudflare.NewClient(cfg.Addons.Cloudflare.APIToken)
+
+	zoneID := cfg.Addons.Cloudflare.ZoneID
+	if zoneID == "" {
+		var err error
+		zoneID, err = cfClient.GetZoneID(ctx, cfg.Addons.Cloudflare.Domain)
+		if err != nil {
+			return fmt.Errorf("failed to get zone ID for %s: %w", cfg.Addons.Cloudflare.Domain, err)
+		}
+	}
+
+	// Use TXT owner ID if configured, otherwise default to cluster name
+	ownerID := cfg.Addons.ExternalDNS.TXTOwnerID
+	if ownerID == "" {
+		ownerID = cfg.ClusterName
+	}
+
+	count, err := cfClient.CleanupClusterRecords(ctx, zoneID, ownerID)
+	if err != nil {
+		return fmt.Errorf("failed to clean up DNS records: %w", err)
+	}
+
+	if count > 0 {
+		ctx.Observer.Printf("[Destroy] Deleted %d Cloudflare DNS records owned by cluster %s", count, ownerID)
+	} else {
+		ctx.Observer.Printf("[Destroy] No Cloudflare DNS records found for this cluster")
+	}
+
+	return nil
+}
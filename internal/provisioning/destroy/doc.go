@@ -3,5 +3,9 @@
 // It removes all Hetzner Cloud resources associated with a cluster by
 // querying resources with the cluster label. Resources are deleted in
 // dependency order: servers first, then load balancers, firewalls,
-// networks, snapshots, SSH keys, and certificates.
+// networks, snapshots, SSH keys, and certificates. Cloudflare DNS records and
+// backup S3 buckets owned by the cluster are removed afterwards.
+//
+// The CLI destroy command and the operator's finalizer-based teardown both use
+// [Destroy].
 package destroy
@@ -9,6 +9,10 @@ import (
 )
 
 // Destroy destroys the cluster and all associated resources.
+//
+// Hetzner Cloud resources are deleted first, then the Cloudflare DNS records
+// and backup S3 buckets owned by the cluster. Failures in the external cleanup
+// are logged as warnings and do not fail the teardown.
 func Destroy(ctx *provisioning.Context) error {
 	ctx.Observer.Printf("[Destroy] Starting cluster destruction for: %s", ctx.Config.ClusterName)
 
@@ -34,6 +38,23 @@ func Destroy(ctx *provisioning.Context) error {
 		return fmt.Errorf("failed to cleanup cluster resources: %w", err)
 	}
 
+	// Clean up Cloudflare DNS records owned by this cluster
+	cf := ctx.Config.Addons.Cloudflare
+	if cf.Enabled && cf.APIToken != "" && cf.Domain != "" {
+		ctx.Observer.Printf("[Destroy] Cleaning up Cloudflare DNS records...")
+		if err := cleanupCloudflareDNS(ctx); err != nil {
+			ctx.Observer.Printf("[Destroy] Warning: Cloudflare DNS cleanup failed: %v", err)
+		}
+	}
+
+	// Clean up S3 buckets if talos-backup was configured
+	if ctx.Config.Addons.TalosBackup.Enabled {
+		ctx.Observer.Printf("[Destroy] Cleaning up S3 buckets...")
+		if err := cleanupS3Buckets(ctx); err != nil {
+			ctx.Observer.Printf("[Destroy] Warning: S3 cleanup failed: %v", err)
+		}
+	}
+
 	ctx.Observer.Printf("[Destroy] Cluster %s destroyed successfully", ctx.Config.ClusterName)
 
 	return nil
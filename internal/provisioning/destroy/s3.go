@@ -0,0 +1,180 @@
+package destroy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/milankappen/k8zner/internal/provisioning"
+)
+
+const (
+	// s3MetadataFile is the name of the metadata file used to verify bucket ownership.
+	s3MetadataFile = "k8zner_metadata.json"
+)
+
+// cleanupS3Buckets removes S3 buckets matching the cluster naming convention.
+func cleanupS3Buckets(ctx *provisioning.Context) error {
+	clusterName := ctx.Config.ClusterName
+	backupCfg := ctx.Config.Addons.TalosBackup
+
+	endpoint := backupCfg.S3Endpoint
+	accessKey := backupCfg.S3AccessKey
+	secretKey := backupCfg.S3SecretKey
+	region := backupCfg.S3Region
+
+	if endpoint == "" || accessKey == "" || secretKey == "" {
+		ctx.Observer.Printf("[Destroy] S3 credentials not configured, skipping bucket cleanup")
+		return nil
+	}
+
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx,
+		awsconfig.WithRegion(region),
+		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 config: %w", err)
+	}
+
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+	})
+
+	result, err := s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return fmt.Errorf("failed to list S3 buckets: %w", err)
+	}
+
+	prefix := clusterName + "-"
+	for _, bucket := range result.Buckets {
+		if bucket.Name == nil || !strings.HasPrefix(*bucket.Name, prefix) {
+			continue
+		}
+
+		bucketName := *bucket.Name
+
+		owned, err := verifyBucketOwnership(ctx, s3Client, bucketName, clusterName)
+		if err != nil {
+			ctx.Observer.Printf("[Destroy] Warning: failed to verify ownership of bucket %s: %v", bucketName, err)
+			continue
+		}
+
+		if !owned {
+			ctx.Observer.Printf("[Destroy] Skipping bucket %s: ownership not verified (no valid metadata)", bucketName)
+			continue
+		}
+
+		ctx.Observer.Printf("[Destroy] Deleting S3 bucket: %s", bucketName)
+		if err := deleteS3Bucket(ctx, s3Client, bucketName); err != nil {
+			ctx.Observer.Printf("[Destroy] Warning: failed to delete bucket %s: %v", bucketName, err)
+		}
+	}
+
+	return nil
+}
+
+// s3BucketMetadata represents the metadata stored in k8zner-managed buckets.
+type s3BucketMetadata struct {
+	ClusterName string `json:"clusterName"`
+	ManagedBy   string `json:"managedBy"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+}
+
+// verifyBucketOwnership checks if a bucket is owned by the specified cluster.
+func verifyBucketOwnership(ctx *provisioning.Context, s3Client *s3.Client, bucketName, expectedCluster string) (bool, error) {
+	result, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(s3MetadataFile),
+	})
+	if err != nil {
+		errStr := err.Error()
+		if strings.Contains(errStr, "NoSuchKey") || strings.Contains(errStr, "not found") || strings.Contains(errStr, "404") {
+			if isKnownBackupBucket(bucketName, expectedCluster) {
+				ctx.Observer.Printf("[Destroy] Bucket %s has no metadata file but matches known k8zner pattern, allowing deletion", bucketName)
+				return true, nil
+			}
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get metadata: %w", err)
+	}
+	defer func() { _ = result.Body.Close() }()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	return checkBucketMetadata(ctx, data, bucketName, expectedCluster)
+}
+
+// isKnownBackupBucket reports whether bucketName is one of the bucket names
+// k8zner creates for a cluster.
+func isKnownBackupBucket(bucketName, clusterName string) bool {
+	return bucketName == clusterName+"-etcd-backup" || bucketName == clusterName+"-talos-backup"
+}
+
+// checkBucketMetadata reports whether the bucket metadata marks the bucket as
+// managed by k8zner for the expected cluster.
+func checkBucketMetadata(ctx *provisioning.Context, data []byte, bucketName, expectedCluster string) (bool, error) {
+	var metadata s3BucketMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return false, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+
+	if metadata.ClusterName != expectedCluster {
+		ctx.Observer.Printf("[Destroy] Bucket %s belongs to cluster %s, not %s", bucketName, metadata.ClusterName, expectedCluster)
+		return false, nil
+	}
+
+	if metadata.ManagedBy != "k8zner" {
+		ctx.Observer.Printf("[Destroy] Bucket %s is not managed by k8zner (managedBy: %s)", bucketName, metadata.ManagedBy)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// deleteS3Bucket empties and deletes an S3 bucket.
+func deleteS3Bucket(ctx *provisioning.Context, client *s3.Client, bucketName string) error {
+	listInput := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucketName),
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(client, listInput)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			_, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(bucketName),
+				Key:    obj.Key,
+			})
+			if err != nil {
+				ctx.Observer.Printf("[Destroy] Warning: failed to delete object %s: %v", *obj.Key, err)
+			}
+		}
+	}
+
+	_, err := client.DeleteBucket(ctx, &s3.DeleteBucketInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete bucket: %w", err)
+	}
+
+	return nil
+}
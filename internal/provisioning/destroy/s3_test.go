@@ -0,0 +1,75 @@
+package destroy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/hcloud"
+	"github.com/milankappen/k8zner/internal/provisioning"
+)
+
+func TestIsKnownBackupBucket(t *testing.T) {
+	t.Parallel()
+	assert.True(t, isKnownBackupBucket("prod-etcd-backup", "prod"))
+	assert.True(t, isKnownBackupBucket("prod-talos-backup", "prod"))
+	assert.False(t, isKnownBackupBucket("prod-assets", "prod"))
+	assert.False(t, isKnownBackupBucket("prod-2-etcd-backup", "prod"))
+}
+
+func TestCheckBucketMetadata(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		data      string
+		wantOwned bool
+		wantErr   bool
+	}{
+		{
+			name:      "owned by cluster",
+			data:      `{"clusterName":"prod","managedBy":"k8zner"}`,
+			wantOwned: true,
+		},
+		{
+			name: "other cluster",
+			data: `{"clusterName":"staging","managedBy":"k8zner"}`,
+		},
+		{
+			name: "not managed by k8zner",
+			data: `{"clusterName":"prod","managedBy":"terraform"}`,
+		},
+		{
+			name:    "invalid json",
+			data:    `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			pCtx := provisioning.NewContext(context.Background(), &config.Config{ClusterName: "prod"}, &hcloud.MockClient{}, nil)
+
+			owned, err := checkBucketMetadata(pCtx, []byte(tt.data), "prod-etcd-backup", "prod")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOwned, owned)
+		})
+	}
+}
+
+func TestCleanupS3Buckets_NoCredentials(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{ClusterName: "prod"}
+	cfg.Addons.TalosBackup.Enabled = true
+	pCtx := provisioning.NewContext(context.Background(), cfg, &hcloud.MockClient{}, nil)
+
+	// Without an endpoint and keys the cleanup is skipped rather than failing
+	require.NoError(t, cleanupS3Buckets(pCtx))
+}
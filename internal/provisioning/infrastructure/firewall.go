@@ -19,8 +19,28 @@ const phase = "infrastructure"
 func ProvisionFirewall(ctx *provisioning.Context) error {
 	ctx.Observer.Printf("[%s] Reconciling firewall %s...", phase, ctx.Config.ClusterName)
 
-	publicIP := ctx.State.PublicIP
-	fw := &ctx.Config.Firewall
+	rules := FirewallRules(ctx.Config, ctx.State.PublicIP)
+
+	firewallLabels := labels.NewLabelBuilder(ctx.Config.ClusterName).
+		WithTestIDIfSet(ctx.Config.TestID).
+		Build()
+
+	// Apply firewall to all servers in this cluster using label selector
+	applyToLabelSelector := fmt.Sprintf("cluster=%s", ctx.Config.ClusterName)
+
+	result, err := ctx.Infra.EnsureFirewall(ctx, ctx.Config.ClusterName, rules, firewallLabels, applyToLabelSelector)
+	if err != nil {
+		return fmt.Errorf("failed to ensure firewall: %w", err)
+	}
+	ctx.State.Firewall = result
+	ctx.Observer.Printf("[%s] Firewall %s applied to servers with label selector: %s", phase, ctx.Config.ClusterName, applyToLabelSelector)
+	return nil
+}
+
+// FirewallRules returns the rules of the cluster firewall for cfg. publicIP is
+// added to the API sources when the config allows the current IPv4 address.
+func FirewallRules(cfg *config.Config, publicIP string) []hcloud.FirewallRule {
+	fw := &cfg.Firewall
 
 	// Collect API sources using helpers
 	kubeAPISources := collectAPISources(fw.KubeAPISource, fw.APISource, publicIP, fw.UseCurrentIPv4)
@@ -55,21 +75,7 @@ func ProvisionFirewall(ctx *provisioning.Context) error {
 	for _, rule := range fw.ExtraRules {
 		rules = append(rules, buildFirewallRule(rule))
 	}
-
-	firewallLabels := labels.NewLabelBuilder(ctx.Config.ClusterName).
-		WithTestIDIfSet(ctx.Config.TestID).
-		Build()
-
-	// Apply firewall to all servers in this cluster using label selector
-	applyToLabelSelector := fmt.Sprintf("cluster=%s", ctx.Config.ClusterName)
-
-	result, err := ctx.Infra.EnsureFirewall(ctx, ctx.Config.ClusterName, rules, firewallLabels, applyToLabelSelector)
-	if err != nil {
-		return fmt.Errorf("failed to ensure firewall: %w", err)
-	}
-	ctx.State.Firewall = result
-	ctx.Observer.Printf("[%s] Firewall %s applied to servers with label selector: %s", phase, ctx.Config.ClusterName, applyToLabelSelector)
-	return nil
+	return rules
 }
 
 // collectAPISources collects IP sources with fallback and current IP logic.
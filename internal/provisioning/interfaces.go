@@ -12,6 +12,8 @@ package provisioning
 import (
 	"context"
 	"time"
+
+	"github.com/milankappen/k8zner/internal/config"
 )
 
 // UpgradeOptions configures the behavior of node upgrades.
@@ -37,7 +39,8 @@ type TalosConfigProducer interface {
 
 	// GenerateWorkerConfig generates machine configuration for a worker node.
 	// serverID is the Hetzner server ID, used to set the nodeid label for CCM integration.
-	GenerateWorkerConfig(hostname string, serverID int64) ([]byte, error)
+	// The node registers with the labels and taints of pool, if it is not nil.
+	GenerateWorkerConfig(hostname string, serverID int64, pool *config.WorkerNodePool) ([]byte, error)
 
 	// GetClientConfig returns the Talos client configuration for cluster access.
 	GetClientConfig() ([]byte, error)
@@ -55,6 +58,10 @@ type TalosConfigProducer interface {
 	// UpgradeKubernetes upgrades the Kubernetes control plane to the target version.
 	UpgradeKubernetes(ctx context.Context, endpoint, targetVersion string) error
 
+	// ApplyMachineConfig applies a machine config to a running node using the given
+	// apply mode and returns the mode Talos actually used.
+	ApplyMachineConfig(ctx context.Context, endpoint string, data []byte, mode string) (string, error)
+
 	// WaitForNodeReady waits for a node to become ready after reboot.
 	WaitForNodeReady(ctx context.Context, endpoint string, timeout time.Duration) error
 
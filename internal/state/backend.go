@@ -0,0 +1,106 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"filippo.io/age"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+// IdentityEnv is the environment variable naming the age identity file the
+// remote state is decrypted with when it is sealed for recipients.
+const IdentityEnv = "K8ZNER_STATE_IDENTITY"
+
+// Backend stores the state of one cluster and serializes the commands that
+// change it. A backend holds at most one lock at a time.
+type Backend interface {
+	// Load returns the stored state, or nil if none has been saved yet.
+	Load(ctx context.Context) (*Bundle, error)
+
+	// Save replaces the stored state with b if lockID still holds the lock,
+	// and otherwise returns an error wrapping [ErrLockLost].
+	Save(ctx context.Context, b *Bundle, lockID string) error
+
+	// Lock takes the lock for info, renews it if info.ID already holds it,
+	// or takes it over once the holder's lease has expired. Otherwise it
+	// returns a [*LockedError].
+	Lock(ctx context.Context, info LockInfo) error
+
+	// Unlock removes the lock if id holds it.
+	Unlock(ctx context.Context, id string) error
+
+	// ForceUnlock removes the lock regardless of its holder.
+	ForceUnlock(ctx context.Context) error
+
+	// ReadLock returns the current lock, or nil if the state is unlocked.
+	ReadLock(ctx context.Context) (*LockInfo, error)
+
+	// Remote reports whether the state is kept away from the working
+	// directory, so commands load it before they run and save it afterwards.
+	Remote() bool
+}
+
+// NewBackend creates the backend selected in cfg. The local backend keeps its
+// lock in dir; the s3 backend encrypts for the configured recipients, or the
+// passphrase in K8ZNER_STATE_PASSPHRASE, and decrypts with the identity file
+// in K8ZNER_STATE_IDENTITY and that passphrase.
+func NewBackend(cfg config.StateConfig, cluster, dir string) (Backend, error) {
+	switch cfg.Backend {
+	case "", config.StateBackendLocal:
+		return NewLocalBackend(dir), nil
+	case config.StateBackendS3:
+	default:
+		return nil, fmt.Errorf("unknown state backend %q", cfg.Backend)
+	}
+
+	if cfg.S3Bucket == "" || cfg.S3Endpoint == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+		return nil, fmt.Errorf("state bucket is not configured: HETZNER_S3_ACCESS_KEY and HETZNER_S3_SECRET_KEY are required")
+	}
+
+	recipients, identities, err := backendKeys(cfg.Recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s3.NewClient(cfg.S3Endpoint, cfg.S3Region, cfg.S3AccessKey, cfg.S3SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return NewS3Backend(client, cfg.S3Bucket, cluster, recipients, identities), nil
+}
+
+// backendKeys returns the recipients the remote state is sealed for and the
+// identities it is opened with.
+func backendKeys(keys []string) ([]age.Recipient, []age.Identity, error) {
+	identities, err := EnvIdentities()
+	if err != nil {
+		return nil, nil, err
+	}
+	recipients, err := Recipients(keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return recipients, identities, nil
+}
+
+// Recipients returns the recipients the remote state is sealed for: keys,
+// the state.recipients of k8zner.yaml, or else the passphrase in
+// K8ZNER_STATE_PASSPHRASE.
+func Recipients(keys []string) ([]age.Recipient, error) {
+	if len(keys) > 0 {
+		return ParseRecipients(keys)
+	}
+	passphrase := os.Getenv(PassphraseEnv)
+	if passphrase == "" {
+		return nil, fmt.Errorf("remote state must be encrypted: set state.recipients or %s", PassphraseEnv)
+	}
+	r, err := PassphraseRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return []age.Recipient{r}, nil
+}
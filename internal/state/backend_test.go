@@ -0,0 +1,427 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+// lockStore is an in-memory bucket with conditional writes. The ETag of an
+// object is the number of writes to the bucket when it was written.
+type lockStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	etags   map[string]string
+	writes  int
+	buckets []string
+}
+
+func newLockStore() *lockStore {
+	return &lockStore{objects: make(map[string][]byte), etags: make(map[string]string)}
+}
+
+func (s *lockStore) put(key string, data []byte) {
+	s.writes++
+	s.objects[key] = data
+	s.etags[key] = strconv.Itoa(s.writes)
+}
+
+func (s *lockStore) CreateBucket(_ context.Context, bucketName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets = append(s.buckets, bucketName)
+	return nil
+}
+
+func (s *lockStore) GetObject(ctx context.Context, bucketName, key string) ([]byte, error) {
+	data, _, err := s.GetObjectWithETag(ctx, bucketName, key)
+	return data, err
+}
+
+func (s *lockStore) GetObjectWithETag(_ context.Context, _, key string) ([]byte, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, "", &types.NoSuchKey{}
+	}
+	return data, s.etags[key], nil
+}
+
+func (s *lockStore) PutObject(_ context.Context, _, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.put(key, data)
+	return nil
+}
+
+func (s *lockStore) PutObjectIfAbsent(_ context.Context, _, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[key]; ok {
+		return s3.ErrObjectExists
+	}
+	s.put(key, data)
+	return nil
+}
+
+func (s *lockStore) PutObjectIfMatch(_ context.Context, _, key string, data []byte, etag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, ok := s.etags[key]; !ok || current != etag {
+		return s3.ErrObjectChanged
+	}
+	s.put(key, data)
+	return nil
+}
+
+func (s *lockStore) DeleteObject(_ context.Context, _, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	delete(s.etags, key)
+	return nil
+}
+
+func testBackends(t *testing.T) map[string]Backend {
+	t.Helper()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return map[string]Backend{
+		"local": NewLocalBackend(t.TempDir()),
+		"s3":    NewS3Backend(newLockStore(), "k8zner-state-prod", "prod", []age.Recipient{id.Recipient()}, []age.Identity{id}),
+	}
+}
+
+func TestBackend_Lock(t *testing.T) {
+	t.Parallel()
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			held, err := backend.ReadLock(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, held)
+
+			first := NewLockInfo("apply", time.Hour)
+			require.NoError(t, backend.Lock(ctx, first))
+
+			second := NewLockInfo("destroy", time.Hour)
+			err = backend.Lock(ctx, second)
+			var locked *LockedError
+			require.ErrorAs(t, err, &locked)
+			assert.Equal(t, first.ID, locked.Lock.ID)
+			assert.Contains(t, err.Error(), "k8zner state unlock --force")
+
+			// Renewing keeps the lock with the same holder
+			first.Expires = first.Expires.Add(time.Hour)
+			require.NoError(t, backend.Lock(ctx, first))
+			held, err = backend.ReadLock(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "apply", held.Operation)
+			assert.WithinDuration(t, first.Expires, held.Expires, time.Second)
+
+			assert.ErrorAs(t, backend.Unlock(ctx, second.ID), &locked, "only the holder unlocks")
+			require.NoError(t, backend.Unlock(ctx, first.ID))
+			require.NoError(t, backend.Lock(ctx, second))
+		})
+	}
+}
+
+func TestBackend_TakesOverExpiredLock(t *testing.T) {
+	t.Parallel()
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			stale := NewLockInfo("apply", time.Hour)
+			stale.Expires = time.Now().Add(-time.Minute)
+			require.NoError(t, backend.Lock(ctx, stale))
+
+			fresh := NewLockInfo("apply", time.Hour)
+			require.NoError(t, backend.Lock(ctx, fresh))
+			held, err := backend.ReadLock(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, fresh.ID, held.ID)
+		})
+	}
+}
+
+func TestBackend_ConcurrentTakeover(t *testing.T) {
+	t.Parallel()
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			stale := NewLockInfo("apply", time.Hour)
+			stale.Expires = time.Now().Add(-time.Minute)
+			require.NoError(t, backend.Lock(ctx, stale))
+
+			// Of several runs taking over the expired lock, only one wins
+			const runs = 8
+			var wg sync.WaitGroup
+			errs := make([]error, runs)
+			for i := range runs {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs[i] = backend.Lock(ctx, NewLockInfo("apply", time.Hour))
+				}()
+			}
+			wg.Wait()
+
+			won := 0
+			for _, err := range errs {
+				var locked *LockedError
+				if err == nil {
+					won++
+				} else {
+					assert.ErrorAs(t, err, &locked)
+				}
+			}
+			assert.Equal(t, 1, won)
+		})
+	}
+}
+
+func TestS3Backend_TakeoverLostToAnotherRun(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := &interleavingStore{lockStore: newLockStore()}
+	backend := NewS3Backend(store, "k8zner-state-prod", "prod", nil, nil)
+
+	stale := NewLockInfo("apply", time.Hour)
+	stale.Expires = time.Now().Add(-time.Minute)
+	require.NoError(t, backend.Lock(ctx, stale))
+
+	// Another run takes over the expired lock after this one read it
+	other := NewLockInfo("destroy", time.Hour)
+	store.afterRead = func() {
+		require.NoError(t, NewS3Backend(store.lockStore, "k8zner-state-prod", "prod", nil, nil).Lock(ctx, other))
+	}
+	err := backend.Lock(ctx, NewLockInfo("apply", time.Hour))
+	var locked *LockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, other.ID, locked.Lock.ID)
+
+	held, err := backend.ReadLock(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, held.ID)
+}
+
+// interleavingStore runs afterRead once, after the first read of an object.
+type interleavingStore struct {
+	*lockStore
+	afterRead func()
+}
+
+func (s *interleavingStore) GetObjectWithETag(ctx context.Context, bucketName, key string) ([]byte, string, error) {
+	data, etag, err := s.lockStore.GetObjectWithETag(ctx, bucketName, key)
+	if s.afterRead != nil {
+		afterRead := s.afterRead
+		s.afterRead = nil
+		afterRead()
+	}
+	return data, etag, err
+}
+
+func TestBackend_ForceUnlock(t *testing.T) {
+	t.Parallel()
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+
+			require.NoError(t, backend.Lock(ctx, NewLockInfo("destroy", time.Hour)))
+			require.NoError(t, backend.ForceUnlock(ctx))
+			held, err := backend.ReadLock(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, held)
+			require.NoError(t, backend.ForceUnlock(ctx), "unlocking twice is fine")
+		})
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	backend := NewLocalBackend(t.TempDir())
+
+	lease, err := Acquire(ctx, backend, "apply", 30*time.Millisecond)
+	require.NoError(t, err)
+	assert.NotEmpty(t, lease.Info().Who)
+
+	// The lease is renewed past its original expiry
+	time.Sleep(60 * time.Millisecond)
+	_, err = Acquire(ctx, backend, "destroy", time.Minute)
+	var locked *LockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, lease.Info().ID, locked.Lock.ID)
+
+	require.NoError(t, lease.Release(ctx))
+	held, err := backend.ReadLock(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, held)
+}
+
+func TestAcquire_LockTakenOver(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := newLockStore()
+	backend := NewS3Backend(store, "k8zner-state-prod", "prod", nil, nil)
+
+	lease, err := Acquire(ctx, backend, "apply", 30*time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, lease.Err())
+
+	// Another run takes the lock over while this one is suspended
+	other := NewLockInfo("destroy", time.Hour)
+	data, err := json.Marshal(other)
+	require.NoError(t, err)
+	require.NoError(t, store.PutObject(ctx, backend.Bucket(), backend.LockKey(), data))
+
+	select {
+	case <-lease.Context().Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("lease context was not canceled")
+	}
+	require.ErrorIs(t, lease.Err(), ErrLockLost)
+	assert.ErrorContains(t, lease.Err(), "destroy")
+	assert.Equal(t, lease.Err(), context.Cause(lease.Context()))
+
+	require.NoError(t, lease.Release(ctx))
+	held, err := backend.ReadLock(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, held.ID, "the lock of the other run is kept")
+}
+
+func TestS3Backend_SaveRequiresLock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	store := newLockStore()
+	backend := NewS3Backend(store, "k8zner-state-prod", "prod", []age.Recipient{id.Recipient()}, []age.Identity{id})
+	ours := NewLockInfo("apply", time.Hour)
+
+	err = backend.Save(ctx, newTestBundle(), ours.ID)
+	require.ErrorIs(t, err, ErrLockLost, "not locked")
+
+	other := NewLockInfo("destroy", time.Hour)
+	require.NoError(t, backend.Lock(ctx, other))
+	err = backend.Save(ctx, newTestBundle(), ours.ID)
+	require.ErrorIs(t, err, ErrLockLost, "locked by another run")
+	assert.ErrorContains(t, err, other.ID)
+	assert.NotContains(t, store.objects, backend.StateKey())
+}
+
+func TestLocalBackend_LoadAndSave(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	backend := NewLocalBackend(dir)
+
+	require.NoError(t, backend.Save(ctx, newTestBundle(), ""))
+	bundle, err := backend.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, bundle, "local state stays in the working directory")
+
+	require.NoError(t, backend.Lock(ctx, NewLockInfo("apply", time.Hour)))
+	_, err = os.Stat(filepath.Join(dir, LockFileName))
+	assert.NoError(t, err)
+}
+
+func TestS3Backend_LoadAndSave(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	store := newLockStore()
+	backend := NewS3Backend(store, "k8zner-state-prod", "prod", []age.Recipient{id.Recipient()}, []age.Identity{id})
+
+	bundle, err := backend.Load(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, bundle, "nothing saved yet")
+
+	lock := NewLockInfo("apply", time.Hour)
+	require.NoError(t, backend.Lock(ctx, lock))
+	require.NoError(t, backend.Save(ctx, newTestBundle(), lock.ID))
+	assert.Equal(t, []string{"k8zner-state-prod"}, store.buckets)
+	assert.NotContains(t, string(store.objects["prod/state.age"]), "cluster:")
+
+	bundle, err = backend.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, newTestBundle().Files, bundle.Files)
+
+	readOnly := NewS3Backend(store, "k8zner-state-prod", "prod", nil, nil)
+	_, err = readOnly.Load(ctx)
+	assert.ErrorContains(t, err, IdentityEnv)
+}
+
+func TestS3Backend_StoreErrors(t *testing.T) {
+	t.Parallel()
+	backend := NewS3Backend(&failingStore{lockStore: newLockStore()}, "k8zner-state-prod", "prod", nil, nil)
+
+	_, err := backend.Load(context.Background())
+	assert.ErrorContains(t, err, "failed to download state")
+	_, err = backend.ReadLock(context.Background())
+	assert.ErrorContains(t, err, "failed to read state lock")
+}
+
+// failingStore fails every download with an error other than not found.
+type failingStore struct {
+	*lockStore
+}
+
+func (f *failingStore) GetObject(_ context.Context, _, _ string) ([]byte, error) {
+	return nil, errors.New("connection reset")
+}
+
+func (f *failingStore) GetObjectWithETag(_ context.Context, _, _ string) ([]byte, string, error) {
+	return nil, "", errors.New("connection reset")
+}
+
+func TestNewBackend(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	t.Setenv(IdentityEnv, "")
+
+	backend, err := NewBackend(config.StateConfig{}, "prod", ".")
+	require.NoError(t, err)
+	assert.IsType(t, &LocalBackend{}, backend)
+
+	s3Config := config.StateConfig{
+		Backend:     config.StateBackendS3,
+		S3Bucket:    "k8zner-state-prod",
+		S3Region:    "fsn1",
+		S3Endpoint:  "https://fsn1.your-objectstorage.com",
+		S3AccessKey: "access",
+		S3SecretKey: "secret",
+	}
+	_, err = NewBackend(s3Config, "prod", ".")
+	assert.ErrorContains(t, err, "must be encrypted")
+
+	t.Setenv(PassphraseEnv, "test passphrase")
+	backend, err = NewBackend(s3Config, "prod", ".")
+	require.NoError(t, err)
+	assert.Equal(t, "k8zner-state-prod", backend.(*S3Backend).Bucket())
+
+	_, err = NewBackend(config.StateConfig{Backend: "consul"}, "prod", ".")
+	assert.ErrorContains(t, err, "unknown state backend")
+}
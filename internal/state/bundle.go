@@ -0,0 +1,185 @@
+package state
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Files a bundle can hold. The first three keep the names they have in the
+// working directory; the others are Kubernetes manifests ready for kubectl apply.
+const (
+	FileTalosSecrets = "secrets.yaml"
+	FileTalosConfig  = "talosconfig"
+	FileKubeconfig   = "kubeconfig"
+	FileCredentials  = "k8zner-credentials.yaml"
+	FileCluster      = "k8znercluster.yaml"
+)
+
+const (
+	// manifestName is the archive entry describing the bundle.
+	manifestName = "manifest.json"
+
+	// bundleVersion is the archive layout written by [Bundle.Marshal].
+	bundleVersion = 1
+
+	// maxFileSize bounds each archive entry, far above any real credential file.
+	maxFileSize = 16 << 20
+)
+
+// Bundle is the decrypted content of a state bundle.
+type Bundle struct {
+	Cluster   string
+	CreatedAt time.Time
+	// Files maps file names to their content.
+	Files map[string][]byte
+}
+
+// manifest is the first entry of the archive.
+type manifest struct {
+	Version   int       `json:"version"`
+	Cluster   string    `json:"cluster"`
+	CreatedAt time.Time `json:"createdAt"`
+	Files     []string  `json:"files"`
+}
+
+// Names returns the names of the files in the bundle, sorted.
+func (b *Bundle) Names() []string {
+	names := make([]string, 0, len(b.Files))
+	for name := range b.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Marshal writes the bundle as a gzip-compressed tar archive.
+func (b *Bundle) Marshal() ([]byte, error) {
+	names := b.Names()
+	for _, name := range names {
+		if err := validateName(name); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := json.Marshal(manifest{
+		Version:   bundleVersion,
+		Cluster:   b.Cluster,
+		CreatedAt: b.CreatedAt.UTC(),
+		Files:     names,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, manifestName, m, b.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if err := writeEntry(tw, name, b.Files[name], b.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal reads a bundle written by [Bundle.Marshal].
+func Unmarshal(data []byte) (*Bundle, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("state bundle is not a gzip archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	var m *manifest
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read state bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("state bundle entry %q is not a regular file", hdr.Name)
+		}
+
+		content, err := io.ReadAll(io.LimitReader(tr, maxFileSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from state bundle: %w", hdr.Name, err)
+		}
+		if len(content) > maxFileSize {
+			return nil, fmt.Errorf("state bundle entry %q is larger than %d bytes", hdr.Name, maxFileSize)
+		}
+
+		if hdr.Name == manifestName {
+			m = &manifest{}
+			if err := json.Unmarshal(content, m); err != nil {
+				return nil, fmt.Errorf("failed to decode state bundle manifest: %w", err)
+			}
+			continue
+		}
+		if err := validateName(hdr.Name); err != nil {
+			return nil, err
+		}
+		files[hdr.Name] = content
+	}
+
+	if m == nil {
+		return nil, fmt.Errorf("state bundle has no %s", manifestName)
+	}
+	if m.Version != bundleVersion {
+		return nil, fmt.Errorf("state bundle version %d is not supported, upgrade k8zner", m.Version)
+	}
+	for _, name := range m.Files {
+		if _, ok := files[name]; !ok {
+			return nil, fmt.Errorf("state bundle is incomplete: %s is missing", name)
+		}
+	}
+
+	return &Bundle{Cluster: m.Cluster, CreatedAt: m.CreatedAt, Files: files}, nil
+}
+
+// validateName rejects names that would escape the directory a bundle is extracted to.
+func validateName(name string) error {
+	if name == "" || name == "." || name == ".." || name == manifestName || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid file name %q in state bundle", name)
+	}
+	return nil
+}
+
+// writeEntry adds a private regular file to the archive.
+func writeEntry(tw *tar.Writer, name string, content []byte, modTime time.Time) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o600,
+		Size:     int64(len(content)),
+		ModTime:  modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package state
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBundle() *Bundle {
+	return &Bundle{
+		Cluster:   "prod",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Files: map[string][]byte{
+			FileTalosSecrets: []byte("cluster:\n  id: abc\n"),
+			FileTalosConfig:  []byte("context: prod\n"),
+			FileKubeconfig:   []byte("apiVersion: v1\nkind: Config\n"),
+		},
+	}
+}
+
+// archive builds a tar.gz with the given entries, bypassing Marshal's checks.
+func archive(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range entries {
+		require.NoError(t, writeEntry(tw, name, []byte(content), time.Now()))
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestBundle_MarshalRoundTrip(t *testing.T) {
+	t.Parallel()
+	b := newTestBundle()
+
+	data, err := b.Marshal()
+	require.NoError(t, err)
+
+	got, err := Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, b.Cluster, got.Cluster)
+	assert.True(t, b.CreatedAt.Equal(got.CreatedAt))
+	assert.Equal(t, b.Files, got.Files)
+	assert.Equal(t, []string{FileKubeconfig, FileTalosSecrets, FileTalosConfig}, got.Names())
+}
+
+func TestBundle_MarshalRejectsPaths(t *testing.T) {
+	t.Parallel()
+	b := newTestBundle()
+	b.Files["../secrets.yaml"] = []byte("x")
+
+	_, err := b.Marshal()
+	assert.ErrorContains(t, err, "invalid file name")
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	t.Parallel()
+	manifest := `{"version":1,"cluster":"prod","files":["secrets.yaml"]}`
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"not gzip", []byte("plain text"), "not a gzip archive"},
+		{"no manifest", archive(t, map[string]string{"secrets.yaml": "x"}), "has no manifest.json"},
+		{"newer version", archive(t, map[string]string{manifestName: `{"version":2}`}), "version 2 is not supported"},
+		{"missing file", archive(t, map[string]string{manifestName: manifest}), "secrets.yaml is missing"},
+		{"path traversal", archive(t, map[string]string{manifestName: manifest, "../../etc/passwd": "x"}), "invalid file name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Unmarshal(tt.data)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Package state packages the credentials needed to regain control of a
+// cluster into an encrypted disaster-recovery bundle, and keeps them in a
+// shared backend that serializes the commands changing the cluster.
+//
+// A [Bundle] holds the Talos secrets bundle, talosconfig and kubeconfig from
+// the working directory together with the k8zner-credentials Secret and the
+// K8znerCluster resource. [Seal] archives and age-encrypts it for public keys
+// or a passphrase, and [Open] reverses that. [Remote] stores sealed bundles
+// under [RemotePrefix] in the cluster's backup bucket.
+//
+// A [Backend] holds the current state and a lock. [LocalBackend] leaves the
+// files in the working directory and locks with a lock file; [S3Backend]
+// keeps a sealed bundle in a bucket of its own and locks with an object
+// created by a conditional write. [Acquire] takes the lock with a lease that
+// is renewed while the command runs, so a crashed run frees it on expiry.
+//
+// [LocalFiles] keeps the credential files in a directory, optionally
+// age-encrypted at rest as <name>.age and decrypted in memory when read.
+// [Workspaces] give each cluster a directory of its own under K8ZNER_HOME,
+// used instead of the working directory when a cluster is selected.
+package state
@@ -0,0 +1,182 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// EncryptedSuffix is appended to the name of an encrypted credential file.
+const EncryptedSuffix = ".age"
+
+// LocalFiles reads and writes the credential files in a directory. With
+// recipients, files are written ASCII-armored and age-encrypted as
+// <name>.age, which is safe to commit, and the plaintext is removed. Reads
+// decrypt encrypted files in memory, whatever the mode.
+type LocalFiles struct {
+	dir        string
+	recipients []age.Recipient
+	identities []age.Identity
+}
+
+// NewLocalFiles creates access to the files in dir, encrypted for recipients
+// if there are any. Without identities, encrypted files are opened with the
+// identity file in K8ZNER_STATE_IDENTITY or the passphrase in
+// K8ZNER_STATE_PASSPHRASE.
+func NewLocalFiles(dir string, recipients []age.Recipient, identities ...age.Identity) *LocalFiles {
+	return &LocalFiles{dir: dir, recipients: recipients, identities: identities}
+}
+
+// Encrypted reports whether files are written encrypted.
+func (f *LocalFiles) Encrypted() bool {
+	return len(f.recipients) > 0
+}
+
+// Path returns where name is stored on disk: the encrypted file if it
+// exists or files are written encrypted, the plaintext file otherwise.
+func (f *LocalFiles) Path(name string) string {
+	plain := filepath.Join(f.dir, name)
+	encrypted := plain + EncryptedSuffix
+	if f.Encrypted() || (!exists(plain) && exists(encrypted)) {
+		return encrypted
+	}
+	return plain
+}
+
+// Exists reports whether name exists in plaintext or encrypted form.
+func (f *LocalFiles) Exists(name string) bool {
+	return exists(filepath.Join(f.dir, name)) || exists(filepath.Join(f.dir, name+EncryptedSuffix))
+}
+
+// IsEncrypted reports whether name is stored encrypted.
+func (f *LocalFiles) IsEncrypted(name string) bool {
+	path := f.Path(name)
+	return filepath.Ext(path) == EncryptedSuffix && exists(path)
+}
+
+// ReadFile returns the content of name, decrypting it if it is stored
+// encrypted. A missing file yields an error matching [os.ErrNotExist].
+func (f *LocalFiles) ReadFile(name string) ([]byte, error) {
+	path := f.Path(name)
+	if filepath.Ext(path) != EncryptedSuffix || !exists(path) {
+		return os.ReadFile(filepath.Join(f.dir, name)) //nolint:gosec // names are fixed credential files
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // names are fixed credential files
+	if err != nil {
+		return nil, err
+	}
+	identities := f.identities
+	if len(identities) == 0 {
+		identities, err = EnvIdentities()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("%s is encrypted: set %s to an age identity file", path, IdentityEnv)
+	}
+	plain, err := Decrypt(data, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return plain, nil
+}
+
+// WriteFile stores data as name, encrypted if files are written encrypted,
+// and removes the copy in the other form so reads cannot pick up stale data.
+func (f *LocalFiles) WriteFile(name string, data []byte) error {
+	plain := filepath.Join(f.dir, name)
+	encrypted := plain + EncryptedSuffix
+
+	target, stale := plain, encrypted
+	if f.Encrypted() {
+		sealed, err := Encrypt(data, f.recipients...)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", name, err)
+		}
+		data, target, stale = sealed, encrypted, plain
+	}
+
+	if err := os.WriteFile(target, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Remove(stale); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", stale, err)
+	}
+	return nil
+}
+
+// Encrypt encrypts data for recipients as an ASCII-armored age file.
+func Encrypt(data []byte, recipients ...age.Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decrypt decrypts an age file, ASCII-armored or binary, with identities.
+func Decrypt(data []byte, identities ...age.Identity) ([]byte, error) {
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// EnvIdentities returns the identities in the file named by K8ZNER_STATE_IDENTITY
+// and the passphrase in K8ZNER_STATE_PASSPHRASE, if set.
+func EnvIdentities() ([]age.Identity, error) {
+	var identities []age.Identity
+
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		id, err := PassphraseIdentity(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, id)
+	}
+
+	if path := os.Getenv(IdentityEnv); path != "" {
+		f, err := os.Open(path) //nolint:gosec // path is supplied by the user on purpose
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", IdentityEnv, err)
+		}
+		defer func() { _ = f.Close() }()
+		ids, err := age.ParseIdentities(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse age identities in %s: %w", path, err)
+		}
+		identities = append(identities, ids...)
+	}
+
+	return identities, nil
+}
+
+// exists reports whether path exists.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
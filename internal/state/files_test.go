@@ -0,0 +1,101 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalFiles_Plaintext(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := NewLocalFiles(dir, nil)
+
+	assert.False(t, files.Exists(FileKubeconfig))
+	_, err := files.ReadFile(FileKubeconfig)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, files.WriteFile(FileKubeconfig, []byte("apiVersion: v1\n")))
+	assert.True(t, files.Exists(FileKubeconfig))
+	assert.False(t, files.IsEncrypted(FileKubeconfig))
+	assert.Equal(t, filepath.Join(dir, FileKubeconfig), files.Path(FileKubeconfig))
+
+	data, err := files.ReadFile(FileKubeconfig)
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\n", string(data))
+}
+
+func TestLocalFiles_Encrypted(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	// A plaintext file from before encryption was turned on is replaced
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FileTalosSecrets), []byte("old"), 0600))
+	files := NewLocalFiles(dir, []age.Recipient{id.Recipient()}, id)
+	require.NoError(t, files.WriteFile(FileTalosSecrets, []byte("cluster:\n  id: abc\n")))
+
+	_, err = os.Stat(filepath.Join(dir, FileTalosSecrets))
+	assert.ErrorIs(t, err, os.ErrNotExist, "plaintext is removed")
+	raw, err := os.ReadFile(filepath.Join(dir, FileTalosSecrets+EncryptedSuffix))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(raw), armor.Header), "encrypted files are ASCII-armored")
+	assert.NotContains(t, string(raw), "cluster:")
+	assert.True(t, files.IsEncrypted(FileTalosSecrets))
+
+	data, err := files.ReadFile(FileTalosSecrets)
+	require.NoError(t, err)
+	assert.Equal(t, "cluster:\n  id: abc\n", string(data))
+
+	// Reads stay transparent with encryption turned off again
+	plain := NewLocalFiles(dir, nil, id)
+	data, err = plain.ReadFile(FileTalosSecrets)
+	require.NoError(t, err)
+	assert.Equal(t, "cluster:\n  id: abc\n", string(data))
+
+	require.NoError(t, plain.WriteFile(FileTalosSecrets, []byte("plain")))
+	assert.False(t, plain.IsEncrypted(FileTalosSecrets))
+	_, err = os.Stat(filepath.Join(dir, FileTalosSecrets+EncryptedSuffix))
+	assert.ErrorIs(t, err, os.ErrNotExist, "stale encrypted copy is removed")
+}
+
+func TestLocalFiles_MissingIdentity(t *testing.T) {
+	t.Setenv(IdentityEnv, "")
+	t.Setenv(PassphraseEnv, "")
+	dir := t.TempDir()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, NewLocalFiles(dir, []age.Recipient{id.Recipient()}).WriteFile(FileKubeconfig, []byte("secret")))
+
+	files := NewLocalFiles(dir, nil)
+	_, err = files.ReadFile(FileKubeconfig)
+	assert.ErrorContains(t, err, IdentityEnv)
+
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	require.NoError(t, os.WriteFile(keyFile, []byte(id.String()+"\n"), 0600))
+	t.Setenv(IdentityEnv, keyFile)
+	data, err := files.ReadFile(FileKubeconfig)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(data))
+}
+
+func TestDecrypt_Binary(t *testing.T) {
+	t.Parallel()
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	sealed, err := Seal(newTestBundle(), id.Recipient())
+	require.NoError(t, err)
+	plain, err := Decrypt(sealed, id)
+	require.NoError(t, err)
+	bundle, err := Unmarshal(plain)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", bundle.Cluster)
+}
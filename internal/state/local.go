@@ -0,0 +1,177 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LockFileName is the lock file the local backend creates in its directory.
+const LockFileName = ".k8zner.lock"
+
+// LocalBackend keeps the state in the working directory, where the commands
+// read and write it directly, and locks it with a lock file.
+type LocalBackend struct {
+	dir string
+}
+
+// NewLocalBackend creates a backend for the state in dir.
+func NewLocalBackend(dir string) *LocalBackend {
+	return &LocalBackend{dir: dir}
+}
+
+// Load returns nil: the files are already in place.
+func (l *LocalBackend) Load(_ context.Context) (*Bundle, error) {
+	return nil, nil
+}
+
+// Save does nothing: the files are already in place.
+func (l *LocalBackend) Save(_ context.Context, _ *Bundle, _ string) error {
+	return nil
+}
+
+// Remote returns false: the commands read and write the files in place.
+func (l *LocalBackend) Remote() bool {
+	return false
+}
+
+func (l *LocalBackend) path() string {
+	return filepath.Join(l.dir, LockFileName)
+}
+
+// Lock creates the lock file, or rewrites it to renew or take over the lock.
+// The lock is written to a temporary file first and linked or renamed into
+// place, so other runs never read a partly written lock.
+func (l *LocalBackend) Lock(ctx context.Context, info LockInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to encode state lock: %w", err)
+	}
+	tmp, err := l.writeTemp(data)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp) }()
+
+	// A second attempt follows removing an expired lock
+	for range 2 {
+		err := os.Link(tmp, l.path())
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create state lock: %w", err)
+		}
+
+		held, err := l.ReadLock(ctx)
+		if err != nil {
+			return err
+		}
+		if held == nil {
+			continue
+		}
+		if !takeable(held, info) {
+			return &LockedError{Lock: *held}
+		}
+		if held.ID == info.ID {
+			if err := os.Rename(tmp, l.path()); err != nil {
+				return fmt.Errorf("failed to renew state lock: %w", err)
+			}
+			return nil
+		}
+		if err := l.removeExpired(held.ID, info.ID); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to take state lock %s: it keeps changing", l.path())
+}
+
+// removeExpired removes the expired lock with ID expired. The lock file is
+// renamed aside first, which only one of several runs taking over can do,
+// and put back if another run replaced the expired lock in the meantime.
+func (l *LocalBackend) removeExpired(expired, id string) error {
+	aside := l.path() + "." + id
+	if err := os.Rename(l.path(), aside); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove expired state lock: %w", err)
+	}
+	defer func() { _ = os.Remove(aside) }()
+
+	data, err := os.ReadFile(aside)
+	if err != nil {
+		return fmt.Errorf("failed to read state lock: %w", err)
+	}
+	moved, err := decodeLock(data)
+	if err != nil {
+		return err
+	}
+	if moved.ID != expired {
+		if err := os.Link(aside, l.path()); err != nil && !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to restore state lock: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeTemp writes data to a temporary file next to the lock file.
+func (l *LocalBackend) writeTemp(data []byte) (string, error) {
+	f, err := os.CreateTemp(l.dir, LockFileName+".*")
+	if err != nil {
+		return "", fmt.Errorf("failed to write state lock: %w", err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write state lock: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// Unlock removes the lock file if id holds it.
+func (l *LocalBackend) Unlock(ctx context.Context, id string) error {
+	held, err := l.ReadLock(ctx)
+	if err != nil || held == nil {
+		return err
+	}
+	if held.ID != id {
+		return &LockedError{Lock: *held}
+	}
+	return l.ForceUnlock(ctx)
+}
+
+// ForceUnlock removes the lock file.
+func (l *LocalBackend) ForceUnlock(_ context.Context) error {
+	if err := os.Remove(l.path()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove state lock: %w", err)
+	}
+	return nil
+}
+
+// ReadLock reads the lock file.
+func (l *LocalBackend) ReadLock(_ context.Context) (*LockInfo, error) {
+	data, err := os.ReadFile(l.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state lock: %w", err)
+	}
+	return decodeLock(data)
+}
+
+// decodeLock parses a stored lock.
+func decodeLock(data []byte) (*LockInfo, error) {
+	info := &LockInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("failed to decode state lock: %w", err)
+	}
+	return info, nil
+}
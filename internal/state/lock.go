@@ -0,0 +1,178 @@
+package state
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+)
+
+// LockInfo describes who holds the state lock and until when.
+type LockInfo struct {
+	ID        string    `json:"id"`
+	Operation string    `json:"operation"`
+	Who       string    `json:"who"`
+	Created   time.Time `json:"created"`
+	Expires   time.Time `json:"expires"`
+}
+
+// NewLockInfo returns a lock for operation that expires after ttl.
+func NewLockInfo(operation string, ttl time.Duration) LockInfo {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	now := time.Now().UTC()
+	return LockInfo{
+		ID:        hex.EncodeToString(id),
+		Operation: operation,
+		Who:       whoami(),
+		Created:   now,
+		Expires:   now.Add(ttl),
+	}
+}
+
+// Expired reports whether the lease of the lock ran out at now.
+func (l *LockInfo) Expired(now time.Time) bool {
+	return now.After(l.Expires)
+}
+
+// LockedError is returned when another run holds the state lock.
+type LockedError struct {
+	Lock LockInfo
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("state is locked by %s (%s since %s, lease expires %s, lock ID %s); "+
+		"if that run is gone, remove the lock with: k8zner state unlock --force",
+		e.Lock.Who, e.Lock.Operation,
+		e.Lock.Created.UTC().Format(time.RFC3339), e.Lock.Expires.UTC().Format(time.RFC3339), e.Lock.ID)
+}
+
+// ErrLockLost is returned when a run no longer holds the state lock it took,
+// because another run took it over after its lease ran out or removed it.
+var ErrLockLost = errors.New("state lock was lost")
+
+// lockLostError describes the lock held instead of the one that was lost,
+// nil if there is none.
+func lockLostError(held *LockInfo) error {
+	if held == nil {
+		return fmt.Errorf("%w: it was removed", ErrLockLost)
+	}
+	return fmt.Errorf("%w: it is now held by %s (%s since %s, lock ID %s)", ErrLockLost,
+		held.Who, held.Operation, held.Created.UTC().Format(time.RFC3339), held.ID)
+}
+
+// takeable reports whether info may replace the held lock: it is the same
+// lock being renewed, or the lease of the holder has run out.
+func takeable(held *LockInfo, info LockInfo) bool {
+	return held.ID == info.ID || held.Expired(time.Now())
+}
+
+// Lease is a state lock that is renewed in the background until released.
+type Lease struct {
+	backend Backend
+	info    LockInfo
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	ctx  context.Context
+	lose context.CancelCauseFunc
+	mu   sync.Mutex
+	err  error
+}
+
+// Acquire takes the state lock for operation with a lease of ttl and renews
+// it every third of ttl, so a crashed run frees the lock after at most ttl.
+func Acquire(ctx context.Context, backend Backend, operation string, ttl time.Duration) (*Lease, error) {
+	info := NewLockInfo(operation, ttl)
+	if err := backend.Lock(ctx, info); err != nil {
+		return nil, err
+	}
+
+	renewCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	leaseCtx, lose := context.WithCancelCause(ctx)
+	l := &Lease{backend: backend, info: info, cancel: cancel, ctx: leaseCtx, lose: lose}
+	l.wg.Add(1)
+	go l.renew(renewCtx, ttl)
+	return l, nil
+}
+
+// Info returns the lock held by the lease.
+func (l *Lease) Info() LockInfo {
+	return l.info
+}
+
+// Context returns a context derived from the one passed to [Acquire] that is
+// canceled once the lease is lost, with the [Lease.Err] as its cause.
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// Err returns an error wrapping [ErrLockLost] once another run has taken
+// over or removed the lock, and nil while the lease holds it.
+func (l *Lease) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// renew extends the lease until ctx is canceled or the lock is lost. Other
+// failures are retried, since the lease outlives a few missed renewals.
+func (l *Lease) renew(ctx context.Context, ttl time.Duration) {
+	defer l.wg.Done()
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+
+	info := l.info
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info.Expires = time.Now().UTC().Add(ttl)
+			err := l.backend.Lock(ctx, info)
+			if err == nil || ctx.Err() != nil {
+				continue
+			}
+			var locked *LockedError
+			if errors.As(err, &locked) {
+				l.mu.Lock()
+				l.err = lockLostError(&locked.Lock)
+				l.mu.Unlock()
+				l.lose(l.err)
+				return
+			}
+			log.Printf("Warning: failed to renew state lock %s: %v", info.ID, err)
+		}
+	}
+}
+
+// Release stops renewing the lease and removes the lock. A lost lock now
+// belongs to another run and is left alone.
+func (l *Lease) Release(ctx context.Context) error {
+	l.cancel()
+	l.wg.Wait()
+	l.lose(context.Canceled)
+	if l.Err() != nil {
+		return nil
+	}
+	return l.backend.Unlock(ctx, l.info.ID)
+}
+
+// whoami identifies the current user as user@host.
+func whoami() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return name + "@" + host
+}
@@ -0,0 +1,128 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/milankappen/k8zner/internal/config"
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+const (
+	// RemotePrefix is the key prefix sealed bundles are stored under. It is
+	// separate from the etcd snapshots so backup retention never touches them.
+	RemotePrefix = "k8zner-state"
+
+	// Latest selects the most recent bundle.
+	Latest = "latest"
+
+	// remoteTimeFormat is the UTC timestamp in bundle keys, without colons.
+	remoteTimeFormat = "2006-01-02T15-04-05Z"
+)
+
+// ObjectStore is the subset of the S3 client needed to store and find bundles.
+type ObjectStore interface {
+	ListObjectInfo(ctx context.Context, bucketName, prefix string) ([]s3.ObjectInfo, error)
+	GetObject(ctx context.Context, bucketName, key string) ([]byte, error)
+	PutObject(ctx context.Context, bucketName, key string, data []byte) error
+}
+
+// Object is a sealed bundle stored in the bucket.
+type Object struct {
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Remote stores the sealed bundles of one cluster in an S3 bucket.
+type Remote struct {
+	store   ObjectStore
+	bucket  string
+	cluster string
+}
+
+// NewRemote creates a remote for the bundles of cluster in bucket.
+func NewRemote(store ObjectStore, bucket, cluster string) *Remote {
+	return &Remote{store: store, bucket: bucket, cluster: cluster}
+}
+
+// NewRemoteFromConfig creates a remote in the bucket configured for talos-backup.
+func NewRemoteFromConfig(cfg config.TalosBackupConfig, cluster string) (*Remote, error) {
+	if cfg.S3Bucket == "" || cfg.S3Endpoint == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+		return nil, fmt.Errorf("backup storage is not configured: s3_bucket, s3_endpoint, s3_access_key and s3_secret_key are required")
+	}
+
+	client, err := s3.NewClient(cfg.S3Endpoint, cfg.S3Region, cfg.S3AccessKey, cfg.S3SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return NewRemote(client, cfg.S3Bucket, cluster), nil
+}
+
+// Bucket returns the name of the bucket holding the bundles.
+func (r *Remote) Bucket() string {
+	return r.bucket
+}
+
+// prefix returns the key prefix of the cluster's bundles.
+func (r *Remote) prefix() string {
+	return RemotePrefix + "/" + r.cluster + "/"
+}
+
+// Push uploads a sealed bundle created at t and returns its key.
+func (r *Remote) Push(ctx context.Context, sealed []byte, t time.Time) (string, error) {
+	key := r.prefix() + t.UTC().Format(remoteTimeFormat) + ".age"
+	if err := r.store.PutObject(ctx, r.bucket, key, sealed); err != nil {
+		return "", fmt.Errorf("failed to upload state bundle %s: %w", key, err)
+	}
+	return key, nil
+}
+
+// List returns the cluster's bundles, newest first.
+func (r *Remote) List(ctx context.Context) ([]Object, error) {
+	infos, err := r.store.ListObjectInfo(ctx, r.bucket, r.prefix())
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]Object, 0, len(infos))
+	for _, info := range infos {
+		if strings.HasSuffix(info.Key, "/") {
+			continue
+		}
+		objects = append(objects, Object{Key: info.Key, Size: info.Size, CreatedAt: info.LastModified})
+	}
+
+	// Keys contain the creation time, so they order like the bundles
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key > objects[j].Key })
+	return objects, nil
+}
+
+// Pull downloads the bundle referenced by ref, which is either [Latest] or a
+// key, and returns it still sealed along with its key.
+func (r *Remote) Pull(ctx context.Context, ref string) (string, []byte, error) {
+	if ref == "" {
+		return "", nil, fmt.Errorf("state bundle reference must not be empty")
+	}
+
+	key := ref
+	if ref == Latest {
+		objects, err := r.List(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(objects) == 0 {
+			return "", nil, fmt.Errorf("no state bundles for cluster %s in bucket %s", r.cluster, r.bucket)
+		}
+		key = objects[0].Key
+	}
+
+	data, err := r.store.GetObject(ctx, r.bucket, key)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to download state bundle %s: %w", key, err)
+	}
+	return key, data, nil
+}
@@ -0,0 +1,83 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+// fakeStore is an in-memory bucket.
+type fakeStore struct {
+	objects map[string][]byte
+}
+
+func (f *fakeStore) ListObjectInfo(_ context.Context, _, prefix string) ([]s3.ObjectInfo, error) {
+	var infos []s3.ObjectInfo
+	for key, data := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, s3.ObjectInfo{Key: key, Size: int64(len(data))})
+		}
+	}
+	return infos, nil
+}
+
+func (f *fakeStore) GetObject(_ context.Context, _, key string) ([]byte, error) {
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, errors.New("NoSuchKey")
+	}
+	return data, nil
+}
+
+func (f *fakeStore) PutObject(_ context.Context, _, key string, data []byte) error {
+	if f.objects == nil {
+		f.objects = make(map[string][]byte)
+	}
+	f.objects[key] = data
+	return nil
+}
+
+func TestRemote_PushAndPull(t *testing.T) {
+	t.Parallel()
+	store := &fakeStore{}
+	remote := NewRemote(store, "prod-etcd-backups", "prod")
+	ctx := context.Background()
+	first := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	key, err := remote.Push(ctx, []byte("older"), first)
+	require.NoError(t, err)
+	assert.Equal(t, "k8zner-state/prod/2026-01-02T03-00-00Z.age", key)
+	_, err = remote.Push(ctx, []byte("newer"), first.Add(time.Hour))
+	require.NoError(t, err)
+	// Another cluster sharing the bucket
+	require.NoError(t, store.PutObject(ctx, "", "k8zner-state/staging/2027-01-01T00-00-00Z.age", []byte("other")))
+
+	objects, err := remote.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "k8zner-state/prod/2026-01-02T04-00-00Z.age", objects[0].Key)
+
+	key, data, err := remote.Pull(ctx, Latest)
+	require.NoError(t, err)
+	assert.Equal(t, objects[0].Key, key)
+	assert.Equal(t, []byte("newer"), data)
+
+	_, data, err = remote.Pull(ctx, "k8zner-state/prod/2026-01-02T03-00-00Z.age")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("older"), data)
+}
+
+func TestRemote_PullEmpty(t *testing.T) {
+	t.Parallel()
+	remote := NewRemote(&fakeStore{}, "prod-etcd-backups", "prod")
+
+	_, _, err := remote.Pull(context.Background(), Latest)
+	assert.ErrorContains(t, err, "no state bundles for cluster prod")
+}
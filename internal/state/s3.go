@@ -0,0 +1,194 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"filippo.io/age"
+
+	"github.com/milankappen/k8zner/internal/platform/s3"
+)
+
+// BackendStore is the subset of the S3 client the s3 backend needs.
+type BackendStore interface {
+	CreateBucket(ctx context.Context, bucketName string) error
+	GetObject(ctx context.Context, bucketName, key string) ([]byte, error)
+	GetObjectWithETag(ctx context.Context, bucketName, key string) ([]byte, string, error)
+	PutObject(ctx context.Context, bucketName, key string, data []byte) error
+	PutObjectIfAbsent(ctx context.Context, bucketName, key string, data []byte) error
+	PutObjectIfMatch(ctx context.Context, bucketName, key string, data []byte, etag string) error
+	DeleteObject(ctx context.Context, bucketName, key string) error
+}
+
+// S3Backend keeps the state sealed with age in an S3 bucket and locks it with
+// an object created by a conditional write, so only one writer can win.
+type S3Backend struct {
+	store      BackendStore
+	bucket     string
+	cluster    string
+	recipients []age.Recipient
+	identities []age.Identity
+	bucketOK   bool
+}
+
+// NewS3Backend creates a backend for the state of cluster in bucket, sealed
+// for recipients and opened with identities.
+func NewS3Backend(store BackendStore, bucket, cluster string, recipients []age.Recipient, identities []age.Identity) *S3Backend {
+	return &S3Backend{store: store, bucket: bucket, cluster: cluster, recipients: recipients, identities: identities}
+}
+
+// Bucket returns the name of the bucket holding the state.
+func (b *S3Backend) Bucket() string {
+	return b.bucket
+}
+
+// Remote returns true: the state is kept in the bucket.
+func (b *S3Backend) Remote() bool {
+	return true
+}
+
+// StateKey returns the key of the sealed state.
+func (b *S3Backend) StateKey() string {
+	return b.cluster + "/state.age"
+}
+
+// LockKey returns the key of the lock object.
+func (b *S3Backend) LockKey() string {
+	return b.cluster + "/lock.json"
+}
+
+// Load downloads and opens the state.
+func (b *S3Backend) Load(ctx context.Context) (*Bundle, error) {
+	data, err := b.store.GetObject(ctx, b.bucket, b.StateKey())
+	if s3.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download state: %w", err)
+	}
+	if len(b.identities) == 0 {
+		return nil, fmt.Errorf("remote state is encrypted: set %s to an age identity file or %s", IdentityEnv, PassphraseEnv)
+	}
+	return Open(data, b.identities...)
+}
+
+// Save seals and uploads the state. The lock is checked right before the
+// upload, so a run whose lease ran out while it was suspended cannot
+// overwrite the state of the run that took over.
+func (b *S3Backend) Save(ctx context.Context, bundle *Bundle, lockID string) error {
+	sealed, err := Seal(bundle, b.recipients...)
+	if err != nil {
+		return err
+	}
+	if err := b.ensureBucket(ctx); err != nil {
+		return err
+	}
+	held, err := b.ReadLock(ctx)
+	if err != nil {
+		return err
+	}
+	if held == nil || held.ID != lockID {
+		return lockLostError(held)
+	}
+	if err := b.store.PutObject(ctx, b.bucket, b.StateKey(), sealed); err != nil {
+		return fmt.Errorf("failed to upload state: %w", err)
+	}
+	return nil
+}
+
+// Lock creates the lock object, or rewrites it to renew or take over the lock.
+func (b *S3Backend) Lock(ctx context.Context, info LockInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to encode state lock: %w", err)
+	}
+	if err := b.ensureBucket(ctx); err != nil {
+		return err
+	}
+
+	// A second attempt follows losing a renewal or takeover to another run
+	for range 2 {
+		err := b.store.PutObjectIfAbsent(ctx, b.bucket, b.LockKey(), data)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, s3.ErrObjectExists) {
+			return fmt.Errorf("failed to create state lock: %w", err)
+		}
+
+		held, etag, err := b.readLock(ctx)
+		if err != nil {
+			return err
+		}
+		if held == nil {
+			continue
+		}
+		if !takeable(held, info) {
+			return &LockedError{Lock: *held}
+		}
+		// Only replace the lock that was read: of two runs taking over an
+		// expired lock, the conditional write lets just one win
+		err = b.store.PutObjectIfMatch(ctx, b.bucket, b.LockKey(), data, etag)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, s3.ErrObjectChanged) {
+			return fmt.Errorf("failed to take over state lock: %w", err)
+		}
+	}
+	return fmt.Errorf("failed to take state lock s3://%s/%s: it keeps changing", b.bucket, b.LockKey())
+}
+
+// Unlock removes the lock object if id holds it.
+func (b *S3Backend) Unlock(ctx context.Context, id string) error {
+	held, err := b.ReadLock(ctx)
+	if err != nil || held == nil {
+		return err
+	}
+	if held.ID != id {
+		return &LockedError{Lock: *held}
+	}
+	return b.ForceUnlock(ctx)
+}
+
+// ForceUnlock removes the lock object.
+func (b *S3Backend) ForceUnlock(ctx context.Context) error {
+	if err := b.store.DeleteObject(ctx, b.bucket, b.LockKey()); err != nil && !s3.IsNotFound(err) {
+		return fmt.Errorf("failed to remove state lock: %w", err)
+	}
+	return nil
+}
+
+// ReadLock downloads the lock object.
+func (b *S3Backend) ReadLock(ctx context.Context) (*LockInfo, error) {
+	info, _, err := b.readLock(ctx)
+	return info, err
+}
+
+// readLock downloads the lock object along with its ETag.
+func (b *S3Backend) readLock(ctx context.Context) (*LockInfo, string, error) {
+	data, etag, err := b.store.GetObjectWithETag(ctx, b.bucket, b.LockKey())
+	if s3.IsNotFound(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read state lock: %w", err)
+	}
+	info, err := decodeLock(data)
+	return info, etag, err
+}
+
+// ensureBucket creates the bucket on first use. Its name does not start with
+// the cluster name, so destroy leaves it and the state survives the cluster.
+func (b *S3Backend) ensureBucket(ctx context.Context) error {
+	if b.bucketOK {
+		return nil
+	}
+	if err := b.store.CreateBucket(ctx, b.bucket); err != nil {
+		return fmt.Errorf("failed to create state bucket: %w", err)
+	}
+	b.bucketOK = true
+	return nil
+}
@@ -0,0 +1,96 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// PassphraseEnv is the environment variable a passphrase for sealing and
+// opening bundles is read from, so it never appears in shell history.
+const PassphraseEnv = "K8ZNER_STATE_PASSPHRASE" //nolint:gosec // name of the variable, not a secret
+
+// ageHeader starts every binary age file.
+var ageHeader = []byte("age-encryption.org/v1")
+
+// ErrNotEncrypted is returned by [Open] for data that is not an age file.
+var ErrNotEncrypted = errors.New("not an encrypted k8zner state bundle")
+
+// Seal archives b and encrypts it for recipients.
+func Seal(b *Bundle, recipients ...age.Recipient) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("state bundle needs at least one recipient or a passphrase")
+	}
+
+	plain, err := b.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt state bundle: %w", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		return nil, fmt.Errorf("failed to encrypt state bundle: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt state bundle: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Open decrypts data with identities and unpacks the bundle.
+func Open(data []byte, identities ...age.Identity) (*Bundle, error) {
+	if !bytes.HasPrefix(data, ageHeader) {
+		return nil, ErrNotEncrypted
+	}
+	if len(identities) == 0 {
+		return nil, errors.New("state bundle is encrypted: an age identity or a passphrase is required")
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(data), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt state bundle: %w", err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt state bundle: %w", err)
+	}
+	return Unmarshal(plain)
+}
+
+// ParseRecipients parses age public keys ("age1...").
+func ParseRecipients(keys []string) ([]age.Recipient, error) {
+	recipients := make([]age.Recipient, 0, len(keys))
+	for _, key := range keys {
+		r, err := age.ParseX25519Recipient(strings.TrimSpace(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", key, err)
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, nil
+}
+
+// PassphraseRecipient returns a recipient that encrypts with passphrase.
+// age does not allow it to be combined with other recipients.
+func PassphraseRecipient(passphrase string) (age.Recipient, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase must not be empty")
+	}
+	return age.NewScryptRecipient(passphrase)
+}
+
+// PassphraseIdentity returns an identity that decrypts bundles sealed with passphrase.
+func PassphraseIdentity(passphrase string) (age.Identity, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase must not be empty")
+	}
+	return age.NewScryptIdentity(passphrase)
+}
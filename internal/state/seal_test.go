@@ -0,0 +1,80 @@
+package state
+
+import (
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeal_Recipients(t *testing.T) {
+	t.Parallel()
+	first, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	second, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	recipients, err := ParseRecipients([]string{first.Recipient().String(), " " + second.Recipient().String() + "\n"})
+	require.NoError(t, err)
+
+	sealed, err := Seal(newTestBundle(), recipients...)
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "cluster:", "bundle content is encrypted")
+
+	for _, id := range []age.Identity{first, second} {
+		b, err := Open(sealed, id)
+		require.NoError(t, err)
+		assert.Equal(t, newTestBundle().Files, b.Files)
+	}
+
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	_, err = Open(sealed, other)
+	assert.ErrorContains(t, err, "failed to decrypt")
+
+	_, err = Open(sealed)
+	assert.ErrorContains(t, err, "identity or a passphrase is required")
+}
+
+func TestSeal_Passphrase(t *testing.T) {
+	t.Parallel()
+	recipient, err := PassphraseRecipient("correct horse battery staple")
+	require.NoError(t, err)
+	sealed, err := Seal(newTestBundle(), recipient)
+	require.NoError(t, err)
+
+	id, err := PassphraseIdentity("correct horse battery staple")
+	require.NoError(t, err)
+	b, err := Open(sealed, id)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", b.Cluster)
+
+	wrong, err := PassphraseIdentity("wrong")
+	require.NoError(t, err)
+	_, err = Open(sealed, wrong)
+	assert.Error(t, err)
+
+	_, err = PassphraseRecipient("")
+	assert.Error(t, err)
+}
+
+func TestSeal_NoRecipients(t *testing.T) {
+	t.Parallel()
+	_, err := Seal(newTestBundle())
+	assert.Error(t, err)
+}
+
+func TestOpen_NotEncrypted(t *testing.T) {
+	t.Parallel()
+	plain, err := newTestBundle().Marshal()
+	require.NoError(t, err)
+
+	_, err = Open(plain)
+	assert.ErrorIs(t, err, ErrNotEncrypted)
+}
+
+func TestParseRecipients_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := ParseRecipients([]string{"not-a-key"})
+	assert.ErrorContains(t, err, "invalid age recipient")
+}
@@ -0,0 +1,136 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HomeEnv names the directory that holds the cluster workspaces. It
+// defaults to ~/.k8zner.
+const HomeEnv = "K8ZNER_HOME"
+
+const (
+	workspacesDir = "clusters"
+	currentFile   = "current"
+)
+
+// Workspaces are per-cluster directories under a home directory, so several
+// clusters can be managed without changing directory:
+//
+//	<home>/clusters/<cluster>/   k8zner.yaml and the credential files
+//	<home>/current               the cluster selected with k8zner use
+type Workspaces struct {
+	home string
+}
+
+// NewWorkspaces creates access to the workspaces under home.
+func NewWorkspaces(home string) *Workspaces {
+	return &Workspaces{home: home}
+}
+
+// DefaultWorkspaces returns the workspaces under K8ZNER_HOME, or ~/.k8zner
+// when it is unset.
+func DefaultWorkspaces() (*Workspaces, error) {
+	if home := os.Getenv(HomeEnv); home != "" {
+		return NewWorkspaces(home), nil
+	}
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the home directory, set %s: %w", HomeEnv, err)
+	}
+	return NewWorkspaces(filepath.Join(userHome, ".k8zner")), nil
+}
+
+// Home returns the directory holding the workspaces.
+func (w *Workspaces) Home() string {
+	return w.home
+}
+
+// Dir returns the workspace directory of cluster.
+func (w *Workspaces) Dir(cluster string) string {
+	return filepath.Join(w.home, workspacesDir, cluster)
+}
+
+// Exists reports whether cluster has a workspace.
+func (w *Workspaces) Exists(cluster string) bool {
+	info, err := os.Stat(w.Dir(cluster))
+	return err == nil && info.IsDir()
+}
+
+// Create creates the workspace of cluster if it does not exist yet and
+// returns its directory.
+func (w *Workspaces) Create(cluster string) (string, error) {
+	if err := validateWorkspace(cluster); err != nil {
+		return "", err
+	}
+	dir := w.Dir(cluster)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create workspace %s: %w", cluster, err)
+	}
+	return dir, nil
+}
+
+// List returns the clusters that have a workspace, sorted by name.
+func (w *Workspaces) List() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(w.home, workspacesDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	var clusters []string
+	for _, entry := range entries {
+		if entry.IsDir() && validateWorkspace(entry.Name()) == nil {
+			clusters = append(clusters, entry.Name())
+		}
+	}
+	sort.Strings(clusters)
+	return clusters, nil
+}
+
+// Current returns the cluster selected with SetCurrent, or "" when none is.
+func (w *Workspaces) Current() (string, error) {
+	data, err := os.ReadFile(filepath.Join(w.home, currentFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the current cluster: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SetCurrent selects the workspace of cluster, which must exist, for later
+// commands. An empty cluster clears the selection.
+func (w *Workspaces) SetCurrent(cluster string) error {
+	path := filepath.Join(w.home, currentFile)
+	if cluster == "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to clear the current cluster: %w", err)
+		}
+		return nil
+	}
+	if err := validateWorkspace(cluster); err != nil {
+		return err
+	}
+	if !w.Exists(cluster) {
+		return fmt.Errorf("cluster %s has no workspace in %s", cluster, w.home)
+	}
+	if err := os.WriteFile(path, []byte(cluster+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to select cluster %s: %w", cluster, err)
+	}
+	return nil
+}
+
+// validateWorkspace rejects cluster names that would escape the workspaces directory.
+func validateWorkspace(cluster string) error {
+	if cluster == "" || strings.HasPrefix(cluster, ".") || strings.ContainsAny(cluster, `/\`) {
+		return fmt.Errorf("invalid workspace name %q", cluster)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaces(t *testing.T) {
+	t.Parallel()
+	home := t.TempDir()
+	w := NewWorkspaces(home)
+
+	clusters, err := w.List()
+	require.NoError(t, err)
+	assert.Empty(t, clusters)
+	current, err := w.Current()
+	require.NoError(t, err)
+	assert.Empty(t, current)
+
+	for _, name := range []string{"prod", "dev"} {
+		dir, err := w.Create(name)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(home, "clusters", name), dir)
+		assert.True(t, w.Exists(name))
+	}
+	// Creating an existing workspace keeps its files
+	require.NoError(t, os.WriteFile(filepath.Join(w.Dir("dev"), FileKubeconfig), []byte("x"), 0600))
+	_, err = w.Create("dev")
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(w.Dir("dev"), FileKubeconfig))
+
+	require.NoError(t, os.WriteFile(filepath.Join(home, "clusters", "notes.txt"), nil, 0600))
+	clusters, err = w.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev", "prod"}, clusters, "files next to the workspaces are ignored")
+
+	require.NoError(t, w.SetCurrent("prod"))
+	current, err = w.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "prod", current)
+
+	err = w.SetCurrent("staging")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no workspace")
+
+	require.NoError(t, w.SetCurrent(""))
+	current, err = w.Current()
+	require.NoError(t, err)
+	assert.Empty(t, current)
+	require.NoError(t, w.SetCurrent(""), "clearing twice is fine")
+}
+
+func TestWorkspaces_InvalidName(t *testing.T) {
+	t.Parallel()
+	w := NewWorkspaces(t.TempDir())
+
+	for _, name := range []string{"", ".", "..", ".hidden", "a/b", `a\b`} {
+		_, err := w.Create(name)
+		assert.Error(t, err, name)
+	}
+}
+
+// Serial: sets K8ZNER_HOME.
+func TestDefaultWorkspaces(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(HomeEnv, home)
+
+	w, err := DefaultWorkspaces()
+	require.NoError(t, err)
+	assert.Equal(t, home, w.Home())
+
+	t.Setenv(HomeEnv, "")
+	t.Setenv("HOME", home)
+	w, err = DefaultWorkspaces()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".k8zner"), w.Home())
+}
@@ -0,0 +1,144 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"empty", ""},
+		{"too few fields", "0 2 * *"},
+		{"too many fields", "0 2 * * * *"},
+		{"minute out of range", "60 2 * * *"},
+		{"hour out of range", "0 24 * * *"},
+		{"day of month zero", "0 2 0 * *"},
+		{"month out of range", "0 2 * 13 *"},
+		{"day of week out of range", "0 2 * * 8"},
+		{"reversed range", "0 5-2 * * *"},
+		{"zero step", "*/0 * * * *"},
+		{"bad step", "*/x * * * *"},
+		{"unknown name", "0 2 * * someday"},
+		{"garbage", "a b c d e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := Parse(tt.expr); err == nil {
+				t.Errorf("Parse(%q) succeeded, want error", tt.expr)
+			}
+		})
+	}
+}
+
+func TestSchedule_Matches(t *testing.T) {
+	t.Parallel()
+	// 2026-03-07 is a Saturday
+	sat0230 := time.Date(2026, 3, 7, 2, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"* * * * *", true},
+		{"30 2 * * *", true},
+		{"0 2 * * *", false},
+		{"*/15 * * * *", true},
+		{"*/20 * * * *", false},
+		{"0-45/15 1-3 * * *", true},
+		{"30 2 * * 6", true},
+		{"30 2 * * sat", true},
+		{"30 2 * * mon-fri", false},
+		{"30 2 * mar *", true},
+		{"30 2 * 4 *", false},
+		{"30 2 1,7 * *", true},
+		// Both day fields restricted: either one matching is enough
+		{"30 2 1 * 6", true},
+		{"30 2 1 * 0", false},
+		// Day of week 7 is Sunday
+		{"30 2 8 * 7", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			if got := s.Matches(sat0230); got != tt.want {
+				t.Errorf("Parse(%q).Matches(%v) = %v, want %v", tt.expr, sat0230, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	t.Parallel()
+	// 2026-03-04 is a Wednesday
+	wed1015 := time.Date(2026, 3, 4, 10, 15, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 4, 10, 16, 0, 0, time.UTC)},
+		{"15 10 * * *", time.Date(2026, 3, 5, 10, 15, 0, 0, time.UTC)},
+		{"0 2 * * 6", time.Date(2026, 3, 7, 2, 0, 0, 0, time.UTC)},
+		{"0 2 * * sun", time.Date(2026, 3, 8, 2, 0, 0, 0, time.UTC)},
+		{"0 22 * * 1-5", time.Date(2026, 3, 4, 22, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			if got := s.Next(wed1015); !got.Equal(tt.want) {
+				t.Errorf("Parse(%q).Next(%v) = %v, want %v", tt.expr, wed1015, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_NextNeverMatches(t *testing.T) {
+	t.Parallel()
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := s.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("Next() = %v, want zero time", got)
+	}
+}
+
+func TestSchedule_NextInLocation(t *testing.T) {
+	t.Parallel()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	s, err := Parse("0 2 * * *")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := s.Next(time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC).In(berlin))
+	want := time.Date(2026, 1, 11, 1, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+
+	// 02:00 does not exist on the spring-forward day in Berlin
+	got = s.Next(time.Date(2026, 3, 28, 12, 0, 0, 0, berlin))
+	if got.IsZero() || got.Before(time.Date(2026, 3, 29, 0, 0, 0, 0, berlin)) {
+		t.Errorf("Next() across DST = %v, want a time on or after 2026-03-29", got)
+	}
+}
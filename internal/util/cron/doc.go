@@ -0,0 +1,11 @@
+// Package cron parses standard five-field cron expressions.
+//
+// Expressions have the fields minute, hour, day of month, month and day of
+// week, each accepting "*", single values, ranges ("1-5"), steps ("*/15",
+// "0-30/10") and comma-separated lists. Months and weekdays also accept
+// three-letter names ("jan", "sat"). As in cron(8), a time matches when the
+// day of month or the day of week matches if both fields are restricted.
+//
+// [Schedule.Next] is used by the operator to find the start of the next
+// maintenance window in the window's time zone.
+package cron
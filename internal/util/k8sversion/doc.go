@@ -0,0 +1,8 @@
+// Package k8sversion holds the Kubernetes version skew rule shared by the
+// admission webhook and the operator.
+//
+// [CheckUpgrade] rejects minor downgrades and upgrades that skip a minor
+// version, neither of which Kubernetes supports. The webhook applies it to the
+// old and new spec.kubernetes.version, the operator to the version each node
+// runs before it starts an upgrade.
+package k8sversion
@@ -0,0 +1,35 @@
+package k8sversion
+
+import (
+	"errors"
+	"fmt"
+
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+)
+
+// ErrInvalidVersion is returned when the target version cannot be parsed.
+var ErrInvalidVersion = errors.New("invalid Kubernetes version")
+
+// CheckUpgrade returns an error if moving from Kubernetes version current to
+// target is a minor downgrade or skips a minor version. Patch releases may
+// change in either direction. A current version that cannot be parsed gives
+// nothing to compare against and is accepted.
+func CheckUpgrade(current, target string) error {
+	to, err := utilversion.ParseGeneric(target)
+	if err != nil {
+		return fmt.Errorf("%w %q: %v", ErrInvalidVersion, target, err)
+	}
+	from, err := utilversion.ParseGeneric(current)
+	if err != nil {
+		return nil
+	}
+
+	if to.Major() != from.Major() || to.Minor() < from.Minor() {
+		return fmt.Errorf("downgrading Kubernetes from %s to %s is not supported", current, target)
+	}
+	if to.Minor() > from.Minor()+1 {
+		return fmt.Errorf("upgrade one minor version at a time (from %s the next is %d.%d)",
+			current, from.Major(), from.Minor()+1)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package k8sversion
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckUpgrade(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		current string
+		target  string
+		wantErr string
+	}{
+		{name: "same version", current: "1.32.0", target: "1.32.0"},
+		{name: "patch upgrade", current: "1.32.0", target: "1.32.3"},
+		{name: "patch downgrade", current: "1.32.3", target: "1.32.1"},
+		{name: "next minor", current: "1.32.0", target: "1.33.0"},
+		{name: "with v prefix", current: "v1.32.0", target: "v1.33.0"},
+		{name: "unparseable current", current: "unknown", target: "1.33.0"},
+		{name: "skips a minor", current: "1.32.0", target: "1.34.0", wantErr: "from 1.32.0 the next is 1.33"},
+		{name: "minor downgrade", current: "1.32.3", target: "1.31.5", wantErr: "not supported"},
+		{name: "major change", current: "1.32.0", target: "2.0.0", wantErr: "not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := CheckUpgrade(tt.current, tt.target)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("CheckUpgrade(%q, %q) = %v, want nil", tt.current, tt.target, err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("CheckUpgrade(%q, %q) = %v, want error containing %q", tt.current, tt.target, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckUpgrade_InvalidTarget(t *testing.T) {
+	t.Parallel()
+	if err := CheckUpgrade("1.32.0", "latest"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("CheckUpgrade with target latest = %v, want ErrInvalidVersion", err)
+	}
+}